@@ -1,11 +1,11 @@
 import { e2e } from '../utils';
 
-describe('Create a public dashboard with template variables shows a template variable warning', () => {
+describe('Create a public dashboard with supported template variables', () => {
   beforeEach(() => {
     e2e.flows.login(Cypress.env('USERNAME'), Cypress.env('PASSWORD'));
   });
 
-  it('Create a public dashboard with template variables shows a template variable warning', () => {
+  it('Create a public dashboard with custom template variables does not show a template variable warning', () => {
     // Opening a dashboard with template variables
     e2e.flows.openDashboard({ uid: 'HYaGDGIMk' });
 
@@ -15,10 +15,10 @@ describe('Create a public dashboard with template variables shows a template var
     // Select public dashboards tab
     e2e.pages.ShareDashboardModal.PublicDashboard.Tab().click();
 
-    // Warning Alert dashboard cannot be made public because it has template variables
-    e2e.pages.ShareDashboardModal.PublicDashboard.TemplateVariablesWarningAlert().should('be.visible');
+    // Custom template variables are supported by public dashboards
+    e2e.pages.ShareDashboardModal.PublicDashboard.TemplateVariablesWarningAlert().should('not.exist');
 
-    // Configuration elements for public dashboards should not exist
+    // Configuration elements for public dashboards should exist
     e2e.pages.ShareDashboardModal.PublicDashboard.WillBePublicCheckbox().should('exist');
     e2e.pages.ShareDashboardModal.PublicDashboard.LimitedDSCheckbox().should('exist');
     e2e.pages.ShareDashboardModal.PublicDashboard.CostIncreaseCheckbox().should('exist');
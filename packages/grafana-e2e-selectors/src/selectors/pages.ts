@@ -222,6 +222,7 @@ export const Pages = {
       CopyUrlButton: 'data-testid public dashboard copy url button',
       SettingsDropdown: 'data-testid public dashboard settings dropdown',
       TemplateVariablesWarningAlert: 'data-testid public dashboard disabled template variables alert',
+      TemplateVariablesConfiguration: 'data-testid public dashboard template variables configuration',
       UnsupportedDataSourcesWarningAlert: 'data-testid public dashboard unsupported data sources alert',
       NoUpsertPermissionsWarningAlert: 'data-testid public dashboard no upsert permissions alert',
       EnableTimeRangeSwitch: 'data-testid public dashboard on off switch for time range',
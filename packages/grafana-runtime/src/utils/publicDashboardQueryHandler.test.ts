@@ -0,0 +1,16 @@
+import { TypedVariableModel } from '@grafana/data';
+
+import { getSelectedVariableValues } from './publicDashboardQueryHandler';
+
+describe('getSelectedVariableValues', () => {
+  it('returns the selected values of the custom variables', () => {
+    const variables = [
+      { type: 'custom', name: 'region', current: { text: 'eu', value: 'eu', selected: true } },
+      { type: 'custom', name: 'host', current: { text: ['a', 'b'], value: ['a', 'b'], selected: true } },
+      { type: 'custom', name: 'empty', current: {} },
+      { type: 'constant', name: 'env', current: { text: 'prod', value: 'prod', selected: true } },
+    ] as unknown as TypedVariableModel[];
+
+    expect(getSelectedVariableValues(variables)).toEqual({ region: ['eu'], host: ['a', 'b'] });
+  });
+});
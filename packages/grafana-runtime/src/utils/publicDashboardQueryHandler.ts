@@ -1,9 +1,10 @@
 import { catchError, Observable, of, switchMap } from 'rxjs';
 
-import { DataQuery, DataQueryRequest, DataQueryResponse } from '@grafana/data';
+import { DataQuery, DataQueryRequest, DataQueryResponse, TypedVariableModel } from '@grafana/data';
 
 import { config } from '../config';
 import { getBackendSrv } from '../services/backendSrv';
+import { getTemplateSrv } from '../services/templateSrv';
 
 import { BackendDataSourceResponse, toDataQueryResponse } from './queryResponse';
 
@@ -30,6 +31,7 @@ export function publicDashboardQueryHandler(request: DataQueryRequest<DataQuery>
       to: toRange.valueOf().toString(),
       timezone: request.timezone,
     },
+    variables: getSelectedVariableValues(getTemplateSrv().getVariables()),
   };
 
   return getBackendSrv()
@@ -48,3 +50,19 @@ export function publicDashboardQueryHandler(request: DataQueryRequest<DataQuery>
       })
     );
 }
+
+/**
+ * Returns the values selected by the viewer for the variables of the public dashboard. The variables with allowed
+ * values are custom variables offering only these values, and they are interpolated in the queries by the server.
+ */
+export function getSelectedVariableValues(variables: TypedVariableModel[]): Record<string, string[]> {
+  const selected: Record<string, string[]> = {};
+  for (const variable of variables) {
+    if (variable.type !== 'custom' || variable.current.value === undefined) {
+      continue;
+    }
+    const value = variable.current.value;
+    selected[variable.name] = Array.isArray(value) ? value : [value];
+  }
+  return selected;
+}
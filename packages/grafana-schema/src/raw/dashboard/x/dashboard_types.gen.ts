@@ -798,6 +798,10 @@ export interface LibraryPanelRef {
    * Library panel uid
    */
   uid: string;
+  /**
+   * Version of the library panel the dashboard is pinned to. The latest version is used when not set.
+   */
+  version?: number;
 }
 
 /**
@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/auth"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/user"
@@ -269,6 +270,12 @@ func (hs *HTTPServer) AdminDeleteUser(c *contextmodel.ReqContext) response.Respo
 		}
 		return nil
 	})
+	g.Go(func() error {
+		if err := hs.dashboardsnapshotsService.DeleteOwnedSnapshots(ctx, &dashboardsnapshots.DeleteOwnedSnapshotsCommand{UserID: cmd.UserID}); err != nil {
+			return err
+		}
+		return nil
+	})
 	if err := g.Wait(); err != nil {
 		return response.Error(500, "Failed to delete user", err)
 	}
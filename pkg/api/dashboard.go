@@ -27,6 +27,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/folder"
 	"github.com/grafana/grafana/pkg/services/guardian"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
 	"github.com/grafana/grafana/pkg/services/org"
 	pref "github.com/grafana/grafana/pkg/services/preference"
 	publicdashboardModels "github.com/grafana/grafana/pkg/services/publicdashboards/models"
@@ -448,6 +449,24 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 
 	dashboard, err := hs.DashboardService.SaveDashboard(alerting.WithUAEnabled(ctx, hs.Cfg.UnifiedAlerting.IsEnabled()), dashItem, allowUiUpdate)
 
+	if cmd.Merge && errors.Is(err, dashboards.ErrDashboardVersionMismatch) {
+		result, mergeErr := hs.mergeDashboard(ctx, dash)
+		if mergeErr != nil {
+			hs.log.Warn("Failed to merge dashboard changes", "uid", dash.UID, "error", mergeErr)
+		} else if len(result.Conflicts) > 0 {
+			return response.JSON(http.StatusPreconditionFailed, util.DynMap{
+				"status":    dashboards.ErrDashboardVersionMismatch.Status,
+				"message":   dashboards.ErrDashboardVersionMismatch.Error(),
+				"conflicts": result.Conflicts,
+			})
+		} else {
+			cmd.Dashboard = result.Dashboard
+			dash = cmd.GetDashboardModel()
+			dashItem.Dashboard = dash
+			dashboard, err = hs.DashboardService.SaveDashboard(alerting.WithUAEnabled(ctx, hs.Cfg.UnifiedAlerting.IsEnabled()), dashItem, allowUiUpdate)
+		}
+	}
+
 	if hs.Live != nil {
 		// Tell everyone listening that the dashboard changed
 		if dashboard == nil {
@@ -490,6 +509,9 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	// connect library panels for this dashboard after the dashboard is stored and has an ID
 	err = hs.LibraryPanelService.ConnectLibraryPanelsForDashboard(ctx, c.SignedInUser, dashboard)
 	if err != nil {
+		if errors.Is(err, model.ErrLibraryElementVersionNotFound) {
+			return response.Error(http.StatusBadRequest, "Library panel version not found", err)
+		}
 		return response.Error(http.StatusInternalServerError, "Error while connecting library panels", err)
 	}
 
@@ -505,6 +527,34 @@ func (hs *HTTPServer) postDashboard(c *contextmodel.ReqContext, cmd dashboards.S
 	})
 }
 
+// mergeDashboard merges the changes of a dashboard saved from an outdated
+// version with the ones saved since. The version the dashboard was loaded from
+// is the base of the merge.
+func (hs *HTTPServer) mergeDashboard(ctx context.Context, dash *dashboards.Dashboard) (*dashdiffs.MergeResult, error) {
+	existing, err := hs.DashboardService.GetDashboard(ctx, &dashboards.GetDashboardQuery{ID: dash.ID, UID: dash.UID, OrgID: dash.OrgID})
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := hs.dashboardVersionService.Get(ctx, &dashver.GetDashboardVersionQuery{
+		DashboardID: existing.ID,
+		OrgID:       dash.OrgID,
+		Version:     dash.Version,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := dashdiffs.Merge(base.Data, existing.Data, dash.Data)
+	if err != nil {
+		return nil, err
+	}
+	if result.Dashboard != nil {
+		result.Dashboard.Set("version", existing.Version)
+	}
+	return result, nil
+}
+
 // swagger:route GET /dashboards/home dashboards getHomeDashboard
 //
 // Get home dashboard.
@@ -713,6 +763,8 @@ func (hs *HTTPServer) GetDashboardVersions(c *contextmodel.ReqContext) response.
 			Created:       version.Created,
 			Message:       msg,
 			CreatedBy:     creator,
+			Pinned:        version.Pinned,
+			PinMessage:    version.PinMessage,
 		})
 	}
 
@@ -801,11 +853,88 @@ func (hs *HTTPServer) GetDashboardVersion(c *contextmodel.ReqContext) response.R
 		Created:       res.Created,
 		Message:       res.Message,
 		CreatedBy:     creator,
+		Pinned:        res.Pinned,
+		PinMessage:    res.PinMessage,
 	}
 
 	return response.JSON(http.StatusOK, dashVersionMeta)
 }
 
+// swagger:route POST /dashboards/uid/{uid}/versions/{DashboardVersionID}/pin dashboard_versions pinDashboardVersionByUID
+//
+// Pin a dashboard version.
+//
+// Pinned versions are never deleted by the cleanup of old versions.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) PinDashboardVersion(c *contextmodel.ReqContext) response.Response {
+	apiCmd := dtos.PinDashboardVersionCommand{}
+	if err := web.Bind(c.Req, &apiCmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+	return hs.pinDashboardVersion(c, true, apiCmd.Message)
+}
+
+// swagger:route DELETE /dashboards/uid/{uid}/versions/{DashboardVersionID}/pin dashboard_versions unpinDashboardVersionByUID
+//
+// Unpin a dashboard version.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) UnpinDashboardVersion(c *contextmodel.ReqContext) response.Response {
+	return hs.pinDashboardVersion(c, false, "")
+}
+
+func (hs *HTTPServer) pinDashboardVersion(c *contextmodel.ReqContext, pinned bool, message string) response.Response {
+	dash, rsp := hs.getDashboardHelper(c.Req.Context(), c.SignedInUser.GetOrgID(), 0, web.Params(c.Req)[":uid"])
+	if rsp != nil {
+		return rsp
+	}
+
+	guardian, err := guardian.NewByDashboard(c.Req.Context(), dash, c.SignedInUser.GetOrgID(), c.SignedInUser)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	if canSave, err := guardian.CanSave(); err != nil || !canSave {
+		return dashboardGuardianResponse(err)
+	}
+
+	version, err := strconv.ParseInt(web.Params(c.Req)[":id"], 10, 32)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	cmd := dashver.PinDashboardVersionCommand{
+		OrgID:        c.SignedInUser.GetOrgID(),
+		DashboardID:  dash.ID,
+		DashboardUID: dash.UID,
+		Version:      int(version),
+		Pinned:       pinned,
+		Message:      message,
+	}
+	if err := hs.dashboardVersionService.Pin(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, dashver.ErrDashboardVersionNotFound) {
+			return response.Error(http.StatusNotFound, "Dashboard version not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to pin dashboard version", err)
+	}
+
+	if pinned {
+		return response.Success("Dashboard version pinned")
+	}
+	return response.Success("Dashboard version unpinned")
+}
+
 // swagger:route POST /dashboards/validate dashboards alpha validateDashboard
 //
 // Validates a dashboard JSON against the schema.
@@ -965,7 +1094,7 @@ func (hs *HTTPServer) CalculateDashboardDiff(c *contextmodel.ReqContext) respons
 		return response.Error(http.StatusInternalServerError, "Unable to compute diff", err)
 	}
 
-	if options.DiffType == dashdiffs.DiffDelta {
+	if options.DiffType == dashdiffs.DiffDelta || options.DiffType == dashdiffs.DiffSemantic {
 		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
 	}
 
@@ -1138,6 +1267,26 @@ type RestoreDashboardVersionByUIDParams struct {
 	UID string `json:"uid"`
 }
 
+// swagger:parameters pinDashboardVersionByUID
+type PinDashboardVersionByUIDParams struct {
+	// in:body
+	Body dtos.PinDashboardVersionCommand
+	// in:path
+	DashboardVersionID int64
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
+// swagger:parameters unpinDashboardVersionByUID
+type UnpinDashboardVersionByUIDParams struct {
+	// in:path
+	DashboardVersionID int64
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+}
+
 // swagger:parameters getDashboardVersionByID
 type GetDashboardVersionByIDParams struct {
 	// in:path
@@ -1202,7 +1351,8 @@ type CalcDashboardDiffParams struct {
 		// Description:
 		// * `basic`
 		// * `json`
-		// Enum: basic,json
+		// * `semantic`: the changes of panels, targets, variables and annotations matched by their key
+		// Enum: basic,json,semantic
 		DiffType string `json:"diffType" binding:"Required"`
 	}
 }
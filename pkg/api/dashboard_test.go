@@ -6,7 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -130,7 +132,7 @@ func newTestLive(t *testing.T, store db.DB) *live.GrafanaLive {
 		nil,
 		&usagestats.UsageStatsMock{T: t},
 		nil,
-		features, acimpl.ProvideAccessControl(cfg), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil)
+		features, acimpl.ProvideAccessControl(cfg), &dashboards.FakeDashboardService{}, annotationstest.NewFakeAnnotationsRepo(), nil, nil)
 	require.NoError(t, err)
 	return gLive
 }
@@ -274,7 +276,7 @@ func TestHTTPServer_DeleteDashboardByUID_AccessControl(t *testing.T) {
 
 			pubDashService := publicdashboards.NewFakePublicDashboardService(t)
 			pubDashService.On("DeleteByDashboard", mock.Anything, mock.Anything).Return(nil).Maybe()
-			hs.PublicDashboardsApi = api.ProvideApi(pubDashService, nil, hs.AccessControl, featuremgmt.WithFeatures())
+			hs.PublicDashboardsApi = api.ProvideApi(pubDashService, nil, hs.AccessControl, featuremgmt.WithFeatures(), setting.NewCfg())
 
 			guardian.InitAccessControlGuardian(hs.Cfg, hs.AccessControl, hs.DashboardService)
 		})
@@ -375,6 +377,73 @@ func TestHTTPServer_GetDashboardVersions_AccessControl(t *testing.T) {
 	})
 }
 
+func TestHTTPServer_PinDashboardVersion_AccessControl(t *testing.T) {
+	setup := func(versionErr error) *webtest.Server {
+		return SetupAPITestServer(t, func(hs *HTTPServer) {
+			dash := dashboards.NewDashboard("some dash")
+			dash.ID = 1
+			dash.UID = "1"
+
+			dashSvc := dashboards.NewFakeDashboardService(t)
+			dashSvc.On("GetDashboard", mock.Anything, mock.Anything).Return(dash, nil).Maybe()
+			hs.DashboardService = dashSvc
+
+			hs.Cfg = setting.NewCfg()
+			hs.AccessControl = acimpl.ProvideAccessControl(hs.Cfg)
+			hs.dashboardVersionService = &dashvertest.FakeDashboardVersionService{ExpectedError: versionErr}
+
+			guardian.InitAccessControlGuardian(hs.Cfg, hs.AccessControl, hs.DashboardService)
+		})
+	}
+
+	pin := func(server *webtest.Server, permissions []accesscontrol.Permission) (*http.Response, error) {
+		req := server.NewPostRequest("/api/dashboards/uid/1/versions/1/pin", strings.NewReader(`{"message":"release"}`))
+		req.Header.Set("Content-Type", "application/json")
+		return server.Send(webtest.RequestWithSignedInUser(req, userWithPermissions(1, permissions)))
+	}
+
+	unpin := func(server *webtest.Server, permissions []accesscontrol.Permission) (*http.Response, error) {
+		return server.Send(webtest.RequestWithSignedInUser(server.NewRequest(http.MethodDelete, "/api/dashboards/uid/1/versions/1/pin", nil), userWithPermissions(1, permissions)))
+	}
+
+	writePermissions := []accesscontrol.Permission{
+		{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:1"},
+		{Action: dashboards.ActionDashboardsWrite, Scope: "dashboards:uid:1"},
+	}
+
+	t.Run("Should not be able to pin dashboard versions without write permission", func(t *testing.T) {
+		server := setup(nil)
+
+		res, err := pin(server, []accesscontrol.Permission{{Action: dashboards.ActionDashboardsRead, Scope: "dashboards:uid:1"}})
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("Should be able to pin and unpin dashboard versions with write permission", func(t *testing.T) {
+		server := setup(nil)
+
+		res, err := pin(server, writePermissions)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+
+		res, err = unpin(server, writePermissions)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+
+	t.Run("Should return not found when the version does not exist", func(t *testing.T) {
+		server := setup(dashver.ErrDashboardVersionNotFound)
+
+		res, err := pin(server, writePermissions)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		require.NoError(t, res.Body.Close())
+	})
+}
+
 func TestDashboardAPIEndpoint(t *testing.T) {
 	t.Run("Given two dashboards with the same title in different folders", func(t *testing.T) {
 		dashOne := dashboards.NewDashboard("dash")
@@ -535,6 +604,87 @@ func TestDashboardAPIEndpoint(t *testing.T) {
 		})
 	})
 
+	t.Run("Given a request to merge the changes of an outdated dashboard", func(t *testing.T) {
+		base := simplejson.NewFromAny(map[string]any{
+			"id": 2, "uid": "uid", "title": "Dash", "version": 1,
+			"panels": []any{map[string]any{"id": 1, "title": "Requests"}},
+		})
+		theirs := simplejson.NewFromAny(map[string]any{
+			"id": 2, "uid": "uid", "title": "Dash", "version": 2,
+			"panels": []any{map[string]any{"id": 1, "title": "Requests per second"}},
+		})
+
+		mergeScenario := func(t *testing.T, ours *simplejson.Json, merged bool, fn func(resp *httptest.ResponseRecorder, dashboardService *dashboards.FakeDashboardService)) {
+			dashboardService := dashboards.NewFakeDashboardService(t)
+			dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).
+				Return(nil, dashboards.ErrDashboardVersionMismatch).Once()
+			if merged {
+				dashboardService.On("SaveDashboard", mock.Anything, mock.AnythingOfType("*dashboards.SaveDashboardDTO"), mock.AnythingOfType("bool")).
+					Return(&dashboards.Dashboard{ID: 2, UID: "uid", Title: "Dash (merged)", Slug: "dash-merged", Version: 3}, nil).Once()
+			}
+			dashboardService.On("GetDashboard", mock.Anything, mock.AnythingOfType("*dashboards.GetDashboardQuery")).
+				Return(&dashboards.Dashboard{ID: 2, UID: "uid", Version: 2, Data: theirs}, nil)
+
+			hs := HTTPServer{
+				Cfg:                          setting.NewCfg(),
+				ProvisioningService:          provisioning.NewProvisioningServiceMock(context.Background()),
+				QuotaService:                 quotatest.New(false, nil),
+				pluginStore:                  &pluginstore.FakePluginStore{},
+				LibraryPanelService:          &mockLibraryPanelService{},
+				DashboardService:             dashboardService,
+				dashboardProvisioningService: mockDashboardProvisioningService{},
+				dashboardVersionService:      &dashvertest.FakeDashboardVersionService{ExpectedDashboardVersion: &dashver.DashboardVersionDTO{Version: 1, Data: base}},
+				accesscontrolService:         actest.FakeService{},
+				log:                          log.New("test-logger"),
+			}
+
+			sc := setupScenarioContext(t, "/api/dashboards")
+			sc.defaultHandler = routing.Wrap(func(c *contextmodel.ReqContext) response.Response {
+				c.Req.Body = mockRequestBody(dashboards.SaveDashboardCommand{Dashboard: ours, Merge: true})
+				c.Req.Header.Add("Content-Type", "application/json")
+				sc.context = c
+				sc.context.SignedInUser = &user.SignedInUser{OrgID: 1, UserID: 5}
+				return hs.PostDashboard(c)
+			})
+			sc.m.Post("/api/dashboards", sc.defaultHandler)
+			callPostDashboard(sc)
+			fn(sc.resp, dashboardService)
+		}
+
+		t.Run("non conflicting changes are saved", func(t *testing.T) {
+			ours := simplejson.NewFromAny(map[string]any{
+				"id": 2, "uid": "uid", "title": "Dash (merged)", "version": 1,
+				"panels": []any{map[string]any{"id": 1, "title": "Requests"}},
+			})
+			var saved *dashboards.SaveDashboardDTO
+
+			mergeScenario(t, ours, true, func(resp *httptest.ResponseRecorder, dashboardService *dashboards.FakeDashboardService) {
+				require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+				saved = dashboardService.Calls[len(dashboardService.Calls)-1].Arguments.Get(1).(*dashboards.SaveDashboardDTO)
+			})
+
+			require.Equal(t, 2, saved.Dashboard.Version)
+			require.Equal(t, "Dash (merged)", saved.Dashboard.Title)
+			require.Equal(t, "Requests per second", saved.Dashboard.Data.Get("panels").GetIndex(0).Get("title").MustString())
+		})
+
+		t.Run("conflicting changes are returned", func(t *testing.T) {
+			ours := simplejson.NewFromAny(map[string]any{
+				"id": 2, "uid": "uid", "title": "Dash", "version": 1,
+				"panels": []any{map[string]any{"id": 1, "title": "Request rate"}},
+			})
+
+			mergeScenario(t, ours, false, func(resp *httptest.ResponseRecorder, dashboardService *dashboards.FakeDashboardService) {
+				require.Equal(t, http.StatusPreconditionFailed, resp.Code)
+				result, err := simplejson.NewJson(resp.Body.Bytes())
+				require.NoError(t, err)
+				require.Equal(t, "version-mismatch", result.Get("status").MustString())
+				require.Equal(t, "panels[id=1].title", result.Get("conflicts").GetIndex(0).Get("path").MustString())
+				require.Equal(t, "Request rate", result.Get("conflicts").GetIndex(0).Get("ours").MustString())
+			})
+		})
+	})
+
 	t.Run("Given a dashboard to validate", func(t *testing.T) {
 		sqlmock := dbtest.NewFakeDB()
 
@@ -1187,6 +1337,11 @@ func (l *mockLibraryElementService) ConnectElementsToDashboard(c context.Context
 	return nil
 }
 
+// ConnectElementVersionsToDashboard connects elements to a specific dashboard, pinning them to the given version.
+func (l *mockLibraryElementService) ConnectElementVersionsToDashboard(c context.Context, signedInUser identity.Requester, elementVersions map[string]int64, dashboardID int64) error {
+	return nil
+}
+
 // DisconnectElementsFromDashboard disconnects elements from a specific dashboard.
 func (l *mockLibraryElementService) DisconnectElementsFromDashboard(c context.Context, dashboardID int64) error {
 	return nil
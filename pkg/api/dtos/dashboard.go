@@ -70,3 +70,8 @@ type CalculateDiffTarget struct {
 type RestoreDashboardVersionCommand struct {
 	Version int `json:"version" binding:"Required"`
 }
+
+type PinDashboardVersionCommand struct {
+	// Message explaining why the version is pinned, for example "approved release"
+	Message string `json:"message"`
+}
@@ -1,10 +1,28 @@
 package dtos
 
+import "time"
+
 type ShortURL struct {
-	UID string `json:"uid"`
-	URL string `json:"url"`
+	UID        string     `json:"uid"`
+	URL        string     `json:"url"`
+	Path       string     `json:"path,omitempty"`
+	CreatedAt  *time.Time `json:"createdAt,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+	VisitCount int64      `json:"visitCount"`
 }
 
 type CreateShortURLCmd struct {
 	Path string `json:"path"`
+	// Slug is used instead of a random uid when set. It must be unique in the organization.
+	Slug string `json:"slug"`
+	// ExpiresAt is the time the short URL expires at. It never expires when not set.
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type SearchShortURLsResult struct {
+	TotalCount int64      `json:"totalCount"`
+	ShortURLs  []ShortURL `json:"shortUrls"`
+	Page       int        `json:"page"`
+	PerPage    int        `json:"perPage"`
 }
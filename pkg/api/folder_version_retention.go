@@ -0,0 +1,128 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/grafana/grafana/pkg/api/apierrors"
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
+	"github.com/grafana/grafana/pkg/services/folder"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// swagger:route GET /folders/{folder_uid}/version-retention folders getFolderVersionRetention
+//
+// Gets the dashboard version retention policy of the folder with the given `uid`.
+//
+// The policy applies to the dashboards of the folder and of its subfolders that do not have a policy of their own.
+//
+// Responses:
+// 200: getFolderVersionRetentionResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetFolderVersionRetention(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if _, err := hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{OrgID: c.SignedInUser.GetOrgID(), UID: &uid, SignedInUser: c.SignedInUser}); err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	policy, err := hs.dashboardVersionService.GetRetentionPolicy(c.Req.Context(), &dashver.GetRetentionPolicyQuery{OrgID: c.SignedInUser.GetOrgID(), FolderUID: uid})
+	if err != nil {
+		if errors.Is(err, dashver.ErrRetentionPolicyNotFound) {
+			return response.Error(http.StatusNotFound, "Retention policy not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to get retention policy", err)
+	}
+
+	return response.JSON(http.StatusOK, policy)
+}
+
+// swagger:route PUT /folders/{folder_uid}/version-retention folders setFolderVersionRetention
+//
+// Sets the dashboard version retention policy of the folder with the given `uid`.
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) SetFolderVersionRetention(c *contextmodel.ReqContext) response.Response {
+	cmd := dashver.SetRetentionPolicyCommand{}
+	if err := web.Bind(c.Req, &cmd); err != nil {
+		return response.Error(http.StatusBadRequest, "bad request data", err)
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if _, err := hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{OrgID: c.SignedInUser.GetOrgID(), UID: &uid, SignedInUser: c.SignedInUser}); err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	cmd.OrgID = c.SignedInUser.GetOrgID()
+	cmd.FolderUID = uid
+	if err := hs.dashboardVersionService.SetRetentionPolicy(c.Req.Context(), &cmd); err != nil {
+		if errors.Is(err, dashver.ErrInvalidVersionsToKeep) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to set retention policy", err)
+	}
+
+	return response.Success("Retention policy updated")
+}
+
+// swagger:route DELETE /folders/{folder_uid}/version-retention folders deleteFolderVersionRetention
+//
+// Deletes the dashboard version retention policy of the folder with the given `uid`.
+//
+// The dashboards of the folder then follow the policy of the parent folders, or the global retention.
+//
+// Responses:
+// 200: okResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) DeleteFolderVersionRetention(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if _, err := hs.folderService.Get(c.Req.Context(), &folder.GetFolderQuery{OrgID: c.SignedInUser.GetOrgID(), UID: &uid, SignedInUser: c.SignedInUser}); err != nil {
+		return apierrors.ToFolderErrorResponse(err)
+	}
+
+	err := hs.dashboardVersionService.DeleteRetentionPolicy(c.Req.Context(), &dashver.DeleteRetentionPolicyCommand{OrgID: c.SignedInUser.GetOrgID(), FolderUID: uid})
+	if err != nil {
+		if errors.Is(err, dashver.ErrRetentionPolicyNotFound) {
+			return response.Error(http.StatusNotFound, "Retention policy not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to delete retention policy", err)
+	}
+
+	return response.Success("Retention policy deleted")
+}
+
+// swagger:parameters getFolderVersionRetention deleteFolderVersionRetention
+type FolderVersionRetentionParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+}
+
+// swagger:parameters setFolderVersionRetention
+type SetFolderVersionRetentionParams struct {
+	// in:path
+	// required:true
+	FolderUID string `json:"folder_uid"`
+	// in:body
+	// required:true
+	Body dashver.SetRetentionPolicyCommand
+}
+
+// swagger:response getFolderVersionRetentionResponse
+type GetFolderVersionRetentionResponse struct {
+	// in: body
+	Body *dashver.RetentionPolicy `json:"body"`
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/services/auth/identity"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/web"
@@ -298,6 +299,12 @@ func (hs *HTTPServer) DeleteOrgByID(c *contextmodel.ReqContext) response.Respons
 		return response.Error(http.StatusBadRequest, "Can not delete org for current user", nil)
 	}
 
+	// the snapshots are deleted first, so that a failure to delete their
+	// dashboards from the snapshot storage can be retried
+	if err := hs.dashboardsnapshotsService.DeleteOwnedSnapshots(c.Req.Context(), &dashboardsnapshots.DeleteOwnedSnapshotsCommand{OrgID: orgID}); err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to delete the snapshots of the organization", err)
+	}
+
 	if err := hs.orgService.Delete(c.Req.Context(), &org.DeleteOrgCommand{ID: orgID}); err != nil {
 		if errors.Is(err, org.ErrOrgNotFound) {
 			return response.Error(http.StatusNotFound, "Failed to delete organization. ID not found", nil)
@@ -26,6 +26,7 @@ type playlistAPIHandler struct {
 	SearchPlaylists  []web.Handler
 	GetPlaylist      []web.Handler
 	GetPlaylistItems []web.Handler
+	GetCurrentItem   []web.Handler
 	DeletePlaylist   []web.Handler
 	UpdatePlaylist   []web.Handler
 	CreatePlaylist   []web.Handler
@@ -40,6 +41,7 @@ func (hs *HTTPServer) registerPlaylistAPI(apiRoute routing.RouteRegister) {
 		SearchPlaylists:  chainHandlers(routing.Wrap(hs.SearchPlaylists)),
 		GetPlaylist:      chainHandlers(hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylist)),
 		GetPlaylistItems: chainHandlers(hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylistItems)),
+		GetCurrentItem:   chainHandlers(hs.validateOrgPlaylist, routing.Wrap(hs.GetPlaylistCurrentItem)),
 		DeletePlaylist:   chainHandlers(middleware.ReqEditorRole, hs.validateOrgPlaylist, routing.Wrap(hs.DeletePlaylist)),
 		UpdatePlaylist:   chainHandlers(middleware.ReqEditorRole, hs.validateOrgPlaylist, routing.Wrap(hs.UpdatePlaylist)),
 		CreatePlaylist:   chainHandlers(middleware.ReqEditorRole, routing.Wrap(hs.CreatePlaylist)),
@@ -134,6 +136,7 @@ func (hs *HTTPServer) registerPlaylistAPI(apiRoute routing.RouteRegister) {
 		playlistRoute.Get("/", handler.SearchPlaylists...)
 		playlistRoute.Get("/:uid", handler.GetPlaylist...)
 		playlistRoute.Get("/:uid/items", handler.GetPlaylistItems...)
+		playlistRoute.Get("/:uid/current", handler.GetCurrentItem...)
 		playlistRoute.Delete("/:uid", handler.DeletePlaylist...)
 		playlistRoute.Put("/:uid", handler.UpdatePlaylist...)
 		playlistRoute.Post("/", handler.CreatePlaylist...)
@@ -274,6 +277,9 @@ func (hs *HTTPServer) CreatePlaylist(c *contextmodel.ReqContext) response.Respon
 
 	p, err := hs.playlistService.Create(c.Req.Context(), &cmd)
 	if err != nil {
+		if isInvalidPlaylistError(err) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
 		return response.Error(500, "Failed to create playlist", err)
 	}
 
@@ -300,6 +306,9 @@ func (hs *HTTPServer) UpdatePlaylist(c *contextmodel.ReqContext) response.Respon
 
 	_, err := hs.playlistService.Update(c.Req.Context(), &cmd)
 	if err != nil {
+		if isInvalidPlaylistError(err) {
+			return response.Error(http.StatusBadRequest, err.Error(), err)
+		}
 		return response.Error(500, "Failed to save playlist", err)
 	}
 
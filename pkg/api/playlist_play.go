@@ -0,0 +1,161 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
+	"github.com/grafana/grafana/pkg/services/playlist"
+	"github.com/grafana/grafana/pkg/services/search"
+	"github.com/grafana/grafana/pkg/services/search/model"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+// playlistDashboardsLimit limits the dashboards of a dashboard_by_tag item
+const playlistDashboardsLimit = 1000
+
+type playlistDashboard struct {
+	hit  *model.Hit
+	item playlist.PlaylistItemDTO
+}
+
+// swagger:route GET /playlists/{uid}/current playlists getPlaylistCurrentItem
+//
+// Get the dashboard of the playlist to display now.
+//
+// The dashboard is computed from the time, so that every screen playing the
+// playlist as users who can view the same dashboards displays the same
+// dashboard. The dashboards the user cannot view are not played. The
+// playlist scheduled by the playlist at this time of day is played instead
+// when there is one. The offset skips dashboards from the current one.
+//
+// Responses:
+// 200: getPlaylistCurrentItemResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (hs *HTTPServer) GetPlaylistCurrentItem(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	now := time.Now()
+
+	scheduled, err := hs.playlistService.GetScheduled(c.Req.Context(), &playlist.GetScheduledPlaylistQuery{
+		UID:   uid,
+		OrgId: c.SignedInUser.GetOrgID(),
+		Now:   now,
+	})
+	if err != nil {
+		if errors.Is(err, playlist.ErrPlaylistNotFound) {
+			return response.Error(http.StatusNotFound, "Playlist not found", err)
+		}
+		return response.Error(http.StatusInternalServerError, "Failed to load playlist", err)
+	}
+
+	dashboards, err := hs.loadPlaylistDashboards(c, scheduled.Playlist, c.SignedInUser.GetOrgID())
+	if err != nil {
+		return response.Error(http.StatusInternalServerError, "Failed to load playlist dashboards", err)
+	}
+	if len(dashboards) == 0 {
+		return response.Error(http.StatusNotFound, "Playlist has no dashboards", playlist.ErrPlaylistEmpty)
+	}
+
+	durations := make([]time.Duration, len(dashboards))
+	for i, dashboard := range dashboards {
+		durations[i] = playlist.ItemInterval(scheduled.Playlist.Interval, dashboard.item)
+	}
+	index, startedAt := playlist.CurrentIndex(durations, scheduled.StartedAt, now)
+	index, startedAt = playlist.OffsetIndex(durations, index, startedAt, c.QueryInt("offset"))
+	current := dashboards[index]
+
+	dashboardURL := current.hit.URL
+	if len(current.item.Variables) > 0 {
+		params := url.Values{}
+		for name, values := range current.item.Variables {
+			params["var-"+name] = values
+		}
+		dashboardURL += "?" + params.Encode()
+	}
+
+	return response.JSON(http.StatusOK, playlist.CurrentPlaylistItem{
+		PlaylistUID:  scheduled.Playlist.Uid,
+		Index:        index,
+		Count:        len(dashboards),
+		DashboardUID: current.hit.UID,
+		Title:        current.hit.Title,
+		URL:          dashboardURL,
+		Variables:    current.item.Variables,
+		StartedAt:    startedAt.UnixMilli(),
+		EndsAt:       startedAt.Add(durations[index]).UnixMilli(),
+	})
+}
+
+// loadPlaylistDashboards returns the dashboards of the playlist, in the order
+// they are played. The dashboards of dashboard_by_tag items are searched when
+// the playlist is played. Only the dashboards the user can view are returned.
+func (hs *HTTPServer) loadPlaylistDashboards(c *contextmodel.ReqContext, dto *playlist.PlaylistDTO, orgID int64) ([]playlistDashboard, error) {
+	items := make([]playlistDashboard, 0, len(dto.Items))
+	for _, item := range dto.Items {
+		query := search.Query{
+			SignedInUser: c.SignedInUser,
+			OrgId:        orgID,
+			Type:         string(model.DashHitDB),
+			Limit:        playlistDashboardsLimit,
+		}
+
+		switch item.Type {
+		case "dashboard_by_uid":
+			query.DashboardUIDs = []string{item.Value}
+		case "dashboard_by_id":
+			id, err := strconv.ParseInt(item.Value, 10, 64)
+			if err != nil {
+				continue
+			}
+			query.DashboardIds = []int64{id}
+		case "dashboard_by_tag":
+			query.Tags = []string{item.Value}
+			for _, option := range hs.SearchService.SortOptions() {
+				if option.Name == item.Sort {
+					query.Sort = item.Sort
+				}
+			}
+		default:
+			continue
+		}
+
+		hits, err := hs.SearchService.SearchHandler(c.Req.Context(), &query)
+		if err != nil {
+			return nil, err
+		}
+		for _, hit := range hits {
+			items = append(items, playlistDashboard{hit: hit, item: item})
+		}
+	}
+
+	return items, nil
+}
+
+func isInvalidPlaylistError(err error) bool {
+	return errors.Is(err, playlist.ErrInvalidItemInterval) || errors.Is(err, playlist.ErrInvalidSchedule)
+}
+
+// swagger:parameters getPlaylistCurrentItem
+type GetPlaylistCurrentItemParams struct {
+	// in:path
+	// required:true
+	UID string `json:"uid"`
+	// Number of dashboards to skip from the current one, backwards when negative.
+	// in:query
+	// required:false
+	Offset int `json:"offset"`
+}
+
+// swagger:response getPlaylistCurrentItemResponse
+type GetPlaylistCurrentItemResponse struct {
+	// The response message
+	// in: body
+	Body *playlist.CurrentPlaylistItem `json:"body"`
+}
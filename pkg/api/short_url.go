@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/api/response"
@@ -20,23 +21,76 @@ func (hs *HTTPServer) createShortURL(c *contextmodel.ReqContext) response.Respon
 	if err := web.Bind(c.Req, &cmd); err != nil {
 		return response.Err(shorturls.ErrShortURLBadRequest.Errorf("bad request data: %w", err))
 	}
-	hs.log.Debug("Received request to create short URL", "path", cmd.Path)
-	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, cmd.Path)
+	hs.log.Debug("Received request to create short URL", "path", cmd.Path, "slug", cmd.Slug)
+	shortURL, err := hs.ShortURLService.CreateShortURL(c.Req.Context(), c.SignedInUser, &shorturls.CreateShortURLCommand{
+		Path:      cmd.Path,
+		Slug:      cmd.Slug,
+		ExpiresAt: cmd.ExpiresAt,
+	})
 	if err != nil {
 		return response.Err(err)
 	}
 
-	url := fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(setting.AppUrl, "/"), shortURL.Uid, c.SignedInUser.GetOrgID())
-	c.Logger.Debug("Created short URL", "url", url)
+	dto := shortURLToDTO(shortURL)
+	c.Logger.Debug("Created short URL", "url", dto.URL)
 
-	dto := dtos.ShortURL{
-		UID: shortURL.Uid,
-		URL: url,
+	return response.JSON(http.StatusOK, dto)
+}
+
+// searchShortURLs handles requests to list the short URLs created by the user.
+func (hs *HTTPServer) searchShortURLs(c *contextmodel.ReqContext) response.Response {
+	result, err := hs.ShortURLService.SearchShortURLs(c.Req.Context(), c.SignedInUser, &shorturls.SearchShortURLsQuery{
+		Query:   c.Query("query"),
+		Page:    c.QueryInt("page"),
+		PerPage: c.QueryInt("perpage"),
+	})
+	if err != nil {
+		return response.Err(err)
+	}
+
+	dto := dtos.SearchShortURLsResult{
+		TotalCount: result.TotalCount,
+		ShortURLs:  make([]dtos.ShortURL, 0, len(result.ShortURLs)),
+		Page:       result.Page,
+		PerPage:    result.PerPage,
+	}
+	for _, shortURL := range result.ShortURLs {
+		dto.ShortURLs = append(dto.ShortURLs, shortURLToDTO(shortURL))
 	}
 
 	return response.JSON(http.StatusOK, dto)
 }
 
+// deleteShortURL handles requests to delete short URLs.
+func (hs *HTTPServer) deleteShortURL(c *contextmodel.ReqContext) response.Response {
+	uid := web.Params(c.Req)[":uid"]
+	if err := hs.ShortURLService.DeleteShortURL(c.Req.Context(), c.SignedInUser, uid); err != nil {
+		return response.Err(err)
+	}
+
+	return response.Success("Short URL deleted")
+}
+
+func shortURLToDTO(shortURL *shorturls.ShortUrl) dtos.ShortURL {
+	return dtos.ShortURL{
+		UID:        shortURL.Uid,
+		URL:        fmt.Sprintf("%s/goto/%s?orgId=%d", strings.TrimSuffix(setting.AppUrl, "/"), shortURL.Uid, shortURL.OrgId),
+		Path:       shortURL.Path,
+		CreatedAt:  unixTimePtr(shortURL.CreatedAt),
+		ExpiresAt:  unixTimePtr(shortURL.ExpiresAt),
+		LastSeenAt: unixTimePtr(shortURL.LastSeenAt),
+		VisitCount: shortURL.VisitCount,
+	}
+}
+
+func unixTimePtr(sec int64) *time.Time {
+	if sec == 0 {
+		return nil
+	}
+	t := time.Unix(sec, 0)
+	return &t
+}
+
 func (hs *HTTPServer) redirectFromShortURL(c *contextmodel.ReqContext) {
 	shortURLUID := web.Params(c.Req)[":uid"]
 
@@ -46,8 +100,8 @@ func (hs *HTTPServer) redirectFromShortURL(c *contextmodel.ReqContext) {
 
 	shortURL, err := hs.ShortURLService.GetShortURLByUID(c.Req.Context(), c.SignedInUser, shortURLUID)
 	if err != nil {
-		if shorturls.ErrShortURLNotFound.Is(err) {
-			hs.log.Debug("Not redirecting short URL since not found")
+		if shorturls.ErrShortURLNotFound.Is(err) || shorturls.ErrShortURLExpired.Is(err) {
+			hs.log.Debug("Not redirecting short URL since not found or expired")
 			return
 		}
 
@@ -31,7 +31,7 @@ func TestShortURLAPIEndpoint(t *testing.T) {
 			Path:  cmd.Path,
 		}
 		service := &fakeShortURLService{
-			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, path string) (*shorturls.ShortUrl, error) {
+			createShortURLFunc: func(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
 				return createResp, nil
 			},
 		}
@@ -78,16 +78,16 @@ func createShortURLScenario(t *testing.T, desc string, url string, routePattern
 }
 
 type fakeShortURLService struct {
-	createShortURLFunc func(ctx context.Context, user *user.SignedInUser, path string) (*shorturls.ShortUrl, error)
+	createShortURLFunc func(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error)
 }
 
 func (s *fakeShortURLService) GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
 	return nil, nil
 }
 
-func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, path string) (*shorturls.ShortUrl, error) {
+func (s *fakeShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
 	if s.createShortURLFunc != nil {
-		return s.createShortURLFunc(ctx, user, path)
+		return s.createShortURLFunc(ctx, user, cmd)
 	}
 
 	return nil, nil
@@ -100,3 +100,19 @@ func (s *fakeShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *sh
 func (s *fakeShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return nil
 }
+
+func (s *fakeShortURLService) SearchShortURLs(ctx context.Context, user *user.SignedInUser, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error) {
+	return &shorturls.SearchShortURLsResult{}, nil
+}
+
+func (s *fakeShortURLService) DeleteShortURL(ctx context.Context, user *user.SignedInUser, uid string) error {
+	return nil
+}
+
+func (s *fakeShortURLService) DeleteExpiredShortURLs(ctx context.Context, cmd *shorturls.DeleteExpiredShortURLsCommand) error {
+	return nil
+}
+
+func (s *fakeShortURLService) RewriteDashboardUID(ctx context.Context, cmd *shorturls.RewriteDashboardUIDCommand) error {
+	return nil
+}
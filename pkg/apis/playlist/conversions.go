@@ -40,6 +40,13 @@ func UnstructuredToLegacyPlaylistDTO(item unstructured.Unstructured) *playlist.P
 			_ = json.Unmarshal(b, &dto.Items)
 		}
 	}
+	schedule := spec["schedule"]
+	if schedule != nil {
+		b, err := json.Marshal(schedule)
+		if err == nil {
+			_ = json.Unmarshal(b, &dto.Schedule)
+		}
+	}
 	return dto
 }
 
@@ -50,10 +57,28 @@ func convertToK8sResource(v *playlist.PlaylistDTO, namespacer request.NamespaceM
 	}
 	for _, item := range v.Items {
 		spec.Items = append(spec.Items, Item{
-			Type:  ItemType(item.Type),
-			Value: item.Value,
+			Type:      ItemType(item.Type),
+			Value:     item.Value,
+			Interval:  item.Interval,
+			Variables: item.Variables,
+			Sort:      item.Sort,
 		})
 	}
+	if v.Schedule != nil {
+		spec.Schedule = &Schedule{Timezone: v.Schedule.Timezone}
+		for _, entry := range v.Schedule.Entries {
+			var weekdays []int
+			for _, weekday := range entry.Weekdays {
+				weekdays = append(weekdays, int(weekday))
+			}
+			spec.Schedule.Entries = append(spec.Schedule.Entries, ScheduleEntry{
+				Start:       entry.Start,
+				End:         entry.End,
+				Weekdays:    weekdays,
+				PlaylistUID: entry.PlaylistUID,
+			})
+		}
+	}
 
 	meta := kinds.GrafanaResourceMetadata{}
 	meta.SetUpdatedTimestampMillis(v.UpdatedAt)
@@ -89,10 +114,28 @@ func convertToLegacyUpdateCommand(p *Playlist, orgId int64) (*playlist.UpdatePla
 			return nil, fmt.Errorf("unsupported item type: %s", item.Type)
 		}
 		cmd.Items = append(cmd.Items, playlist.PlaylistItem{
-			Type:  string(item.Type),
-			Value: item.Value,
+			Type:      string(item.Type),
+			Value:     item.Value,
+			Interval:  item.Interval,
+			Variables: item.Variables,
+			Sort:      item.Sort,
 		})
 	}
+	if spec.Schedule != nil {
+		cmd.Schedule = &playlist.PlaylistSchedule{Timezone: spec.Schedule.Timezone}
+		for _, entry := range spec.Schedule.Entries {
+			var weekdays []time.Weekday
+			for _, weekday := range entry.Weekdays {
+				weekdays = append(weekdays, time.Weekday(weekday))
+			}
+			cmd.Schedule.Entries = append(cmd.Schedule.Entries, playlist.PlaylistScheduleEntry{
+				Start:       entry.Start,
+				End:         entry.End,
+				Weekdays:    weekdays,
+				PlaylistUID: entry.PlaylistUID,
+			})
+		}
+	}
 	return cmd, nil
 }
 
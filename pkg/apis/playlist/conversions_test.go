@@ -3,6 +3,7 @@ package playlist
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -67,3 +68,36 @@ func TestPlaylistConversion(t *testing.T) {
 		}
 	  }`, string(out))
 }
+
+func TestPlaylistConversionItemSettingsAndSchedule(t *testing.T) {
+	src := &playlist.PlaylistDTO{
+		OrgID:    1,
+		Uid:      "day",
+		Name:     "Day shift",
+		Interval: "5m",
+		Items: []playlist.PlaylistItemDTO{
+			{Type: "dashboard_by_uid", Value: "UID0", Interval: "30s", Variables: map[string][]string{"host": {"a", "b"}}},
+			{Type: "dashboard_by_tag", Value: "tagA", Sort: "alpha-desc"},
+		},
+		Schedule: &playlist.PlaylistSchedule{
+			Timezone: "Europe/Paris",
+			Entries: []playlist.PlaylistScheduleEntry{
+				{Start: "20:00", End: "08:00", Weekdays: []time.Weekday{time.Monday, time.Friday}, PlaylistUID: "night"},
+			},
+		},
+	}
+
+	dst := convertToK8sResource(src, request.GetNamespaceMapper(nil))
+	require.Equal(t, "30s", dst.Spec.Items[0].Interval)
+	require.Equal(t, map[string][]string{"host": {"a", "b"}}, dst.Spec.Items[0].Variables)
+	require.Equal(t, "alpha-desc", dst.Spec.Items[1].Sort)
+	require.Equal(t, []int{1, 5}, dst.Spec.Schedule.Entries[0].Weekdays)
+
+	cmd, err := convertToLegacyUpdateCommand(dst, 1)
+	require.NoError(t, err)
+	require.Equal(t, []playlist.PlaylistItem{
+		{Type: "dashboard_by_uid", Value: "UID0", Interval: "30s", Variables: map[string][]string{"host": {"a", "b"}}},
+		{Type: "dashboard_by_tag", Value: "tagA", Sort: "alpha-desc"},
+	}, cmd.Items)
+	require.Equal(t, src.Schedule, cmd.Schedule)
+}
@@ -138,6 +138,7 @@ func (s *legacyStorage) Create(ctx context.Context,
 		Name:     cmd.Name,
 		Interval: cmd.Interval,
 		Items:    cmd.Items,
+		Schedule: cmd.Schedule,
 		OrgId:    cmd.OrgId,
 	})
 	if err != nil {
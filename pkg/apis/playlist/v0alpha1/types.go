@@ -34,6 +34,9 @@ type Spec struct {
 
 	// The ordered list of items that the playlist will iterate over.
 	Items []Item `json:"items,omitempty"`
+
+	// Schedule plays other playlists at given times of day.
+	Schedule *Schedule `json:"schedule,omitempty"`
 }
 
 // Defines values for ItemType.
@@ -59,7 +62,40 @@ type Item struct {
 	//  dashboards behind the tag will be added to the playlist.
 	//  - dashboard_by_uid: The value is the dashboard UID
 	Value string `json:"value"`
+
+	// Interval overrides the interval of the playlist for the dashboards of the item.
+	Interval string `json:"interval,omitempty"`
+
+	// Variables overrides the template variables of the dashboards of the item, by variable name.
+	Variables map[string][]string `json:"variables,omitempty"`
+
+	// Sort orders the dashboards of dashboard_by_tag items, using the sort options of the search.
+	Sort string `json:"sort,omitempty"`
 }
 
 // Type of the item.
 type ItemType string
+
+// Schedule plays other playlists at given times of day. The playlist itself is played outside of the entries.
+type Schedule struct {
+	// Timezone of the entries, as an IANA time zone name. UTC by default.
+	Timezone string `json:"timezone,omitempty"`
+
+	// Entries of the schedule. The first entry matching is played.
+	Entries []ScheduleEntry `json:"entries"`
+}
+
+// ScheduleEntry defines model for ScheduleEntry.
+type ScheduleEntry struct {
+	// Start time of day, formatted as HH:MM.
+	Start string `json:"start"`
+
+	// End time of day, formatted as HH:MM. Entries ending before they start span midnight.
+	End string `json:"end"`
+
+	// Weekdays the entry starts on, 0 being Sunday. Every day by default.
+	Weekdays []int `json:"weekdays,omitempty"`
+
+	// PlaylistUID is the playlist played during the entry.
+	PlaylistUID string `json:"playlistUid"`
+}
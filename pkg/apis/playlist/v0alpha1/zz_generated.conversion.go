@@ -62,6 +62,26 @@ func RegisterConversions(s *runtime.Scheme) error {
 	}); err != nil {
 		return err
 	}
+	if err := s.AddGeneratedConversionFunc((*Schedule)(nil), (*playlist.Schedule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v0alpha1_Schedule_To_playlist_Schedule(a.(*Schedule), b.(*playlist.Schedule), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*playlist.Schedule)(nil), (*Schedule)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_playlist_Schedule_To_v0alpha1_Schedule(a.(*playlist.Schedule), b.(*Schedule), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*ScheduleEntry)(nil), (*playlist.ScheduleEntry)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_v0alpha1_ScheduleEntry_To_playlist_ScheduleEntry(a.(*ScheduleEntry), b.(*playlist.ScheduleEntry), scope)
+	}); err != nil {
+		return err
+	}
+	if err := s.AddGeneratedConversionFunc((*playlist.ScheduleEntry)(nil), (*ScheduleEntry)(nil), func(a, b interface{}, scope conversion.Scope) error {
+		return Convert_playlist_ScheduleEntry_To_v0alpha1_ScheduleEntry(a.(*playlist.ScheduleEntry), b.(*ScheduleEntry), scope)
+	}); err != nil {
+		return err
+	}
 	if err := s.AddGeneratedConversionFunc((*Spec)(nil), (*playlist.Spec)(nil), func(a, b interface{}, scope conversion.Scope) error {
 		return Convert_v0alpha1_Spec_To_playlist_Spec(a.(*Spec), b.(*playlist.Spec), scope)
 	}); err != nil {
@@ -78,6 +98,9 @@ func RegisterConversions(s *runtime.Scheme) error {
 func autoConvert_v0alpha1_Item_To_playlist_Item(in *Item, out *playlist.Item, s conversion.Scope) error {
 	out.Type = playlist.ItemType(in.Type)
 	out.Value = in.Value
+	out.Interval = in.Interval
+	out.Variables = *(*map[string][]string)(unsafe.Pointer(&in.Variables))
+	out.Sort = in.Sort
 	return nil
 }
 
@@ -89,6 +112,9 @@ func Convert_v0alpha1_Item_To_playlist_Item(in *Item, out *playlist.Item, s conv
 func autoConvert_playlist_Item_To_v0alpha1_Item(in *playlist.Item, out *Item, s conversion.Scope) error {
 	out.Type = ItemType(in.Type)
 	out.Value = in.Value
+	out.Interval = in.Interval
+	out.Variables = *(*map[string][]string)(unsafe.Pointer(&in.Variables))
+	out.Sort = in.Sort
 	return nil
 }
 
@@ -145,10 +171,59 @@ func Convert_playlist_PlaylistList_To_v0alpha1_PlaylistList(in *playlist.Playlis
 	return autoConvert_playlist_PlaylistList_To_v0alpha1_PlaylistList(in, out, s)
 }
 
+func autoConvert_v0alpha1_Schedule_To_playlist_Schedule(in *Schedule, out *playlist.Schedule, s conversion.Scope) error {
+	out.Timezone = in.Timezone
+	out.Entries = *(*[]playlist.ScheduleEntry)(unsafe.Pointer(&in.Entries))
+	return nil
+}
+
+// Convert_v0alpha1_Schedule_To_playlist_Schedule is an autogenerated conversion function.
+func Convert_v0alpha1_Schedule_To_playlist_Schedule(in *Schedule, out *playlist.Schedule, s conversion.Scope) error {
+	return autoConvert_v0alpha1_Schedule_To_playlist_Schedule(in, out, s)
+}
+
+func autoConvert_playlist_Schedule_To_v0alpha1_Schedule(in *playlist.Schedule, out *Schedule, s conversion.Scope) error {
+	out.Timezone = in.Timezone
+	out.Entries = *(*[]ScheduleEntry)(unsafe.Pointer(&in.Entries))
+	return nil
+}
+
+// Convert_playlist_Schedule_To_v0alpha1_Schedule is an autogenerated conversion function.
+func Convert_playlist_Schedule_To_v0alpha1_Schedule(in *playlist.Schedule, out *Schedule, s conversion.Scope) error {
+	return autoConvert_playlist_Schedule_To_v0alpha1_Schedule(in, out, s)
+}
+
+func autoConvert_v0alpha1_ScheduleEntry_To_playlist_ScheduleEntry(in *ScheduleEntry, out *playlist.ScheduleEntry, s conversion.Scope) error {
+	out.Start = in.Start
+	out.End = in.End
+	out.Weekdays = *(*[]int)(unsafe.Pointer(&in.Weekdays))
+	out.PlaylistUID = in.PlaylistUID
+	return nil
+}
+
+// Convert_v0alpha1_ScheduleEntry_To_playlist_ScheduleEntry is an autogenerated conversion function.
+func Convert_v0alpha1_ScheduleEntry_To_playlist_ScheduleEntry(in *ScheduleEntry, out *playlist.ScheduleEntry, s conversion.Scope) error {
+	return autoConvert_v0alpha1_ScheduleEntry_To_playlist_ScheduleEntry(in, out, s)
+}
+
+func autoConvert_playlist_ScheduleEntry_To_v0alpha1_ScheduleEntry(in *playlist.ScheduleEntry, out *ScheduleEntry, s conversion.Scope) error {
+	out.Start = in.Start
+	out.End = in.End
+	out.Weekdays = *(*[]int)(unsafe.Pointer(&in.Weekdays))
+	out.PlaylistUID = in.PlaylistUID
+	return nil
+}
+
+// Convert_playlist_ScheduleEntry_To_v0alpha1_ScheduleEntry is an autogenerated conversion function.
+func Convert_playlist_ScheduleEntry_To_v0alpha1_ScheduleEntry(in *playlist.ScheduleEntry, out *ScheduleEntry, s conversion.Scope) error {
+	return autoConvert_playlist_ScheduleEntry_To_v0alpha1_ScheduleEntry(in, out, s)
+}
+
 func autoConvert_v0alpha1_Spec_To_playlist_Spec(in *Spec, out *playlist.Spec, s conversion.Scope) error {
 	out.Title = in.Title
 	out.Interval = in.Interval
 	out.Items = *(*[]playlist.Item)(unsafe.Pointer(&in.Items))
+	out.Schedule = (*playlist.Schedule)(unsafe.Pointer(in.Schedule))
 	return nil
 }
 
@@ -161,6 +236,7 @@ func autoConvert_playlist_Spec_To_v0alpha1_Spec(in *playlist.Spec, out *Spec, s
 	out.Title = in.Title
 	out.Interval = in.Interval
 	out.Items = *(*[]Item)(unsafe.Pointer(&in.Items))
+	out.Schedule = (*Schedule)(unsafe.Pointer(in.Schedule))
 	return nil
 }
 
@@ -16,10 +16,12 @@ import (
 
 func getOpenAPIDefinitions(ref common.ReferenceCallback) map[string]common.OpenAPIDefinition {
 	return map[string]common.OpenAPIDefinition{
-		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Item":         schema_pkg_apis_playlist_v0alpha1_Item(ref),
-		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Playlist":     schema_pkg_apis_playlist_v0alpha1_Playlist(ref),
-		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.PlaylistList": schema_pkg_apis_playlist_v0alpha1_PlaylistList(ref),
-		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Spec":         schema_pkg_apis_playlist_v0alpha1_Spec(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Item":          schema_pkg_apis_playlist_v0alpha1_Item(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Playlist":      schema_pkg_apis_playlist_v0alpha1_Playlist(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.PlaylistList":  schema_pkg_apis_playlist_v0alpha1_PlaylistList(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Schedule":      schema_pkg_apis_playlist_v0alpha1_Schedule(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.ScheduleEntry": schema_pkg_apis_playlist_v0alpha1_ScheduleEntry(ref),
+		"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Spec":          schema_pkg_apis_playlist_v0alpha1_Spec(ref),
 	}
 }
 
@@ -46,6 +48,43 @@ func schema_pkg_apis_playlist_v0alpha1_Item(ref common.ReferenceCallback) common
 							Format:      "",
 						},
 					},
+					"interval": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Interval overrides the interval of the playlist for the dashboards of the item.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"variables": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Variables overrides the template variables of the dashboards of the item, by variable name.",
+							Type:        []string{"object"},
+							AdditionalProperties: &spec.SchemaOrBool{
+								Allows: true,
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Type: []string{"array"},
+										Items: &spec.SchemaOrArray{
+											Schema: &spec.Schema{
+												SchemaProps: spec.SchemaProps{
+													Default: "",
+													Type:    []string{"string"},
+													Format:  "",
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					"sort": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Sort orders the dashboards of dashboard_by_tag items, using the sort options of the search.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
 				},
 				Required: []string{"type", "value"},
 			},
@@ -141,6 +180,96 @@ func schema_pkg_apis_playlist_v0alpha1_PlaylistList(ref common.ReferenceCallback
 	}
 }
 
+func schema_pkg_apis_playlist_v0alpha1_Schedule(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "Schedule plays other playlists at given times of day. The playlist itself is played outside of the entries.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"timezone": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Timezone of the entries, as an IANA time zone name. UTC by default.",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"entries": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Entries of the schedule. The first entry matching is played.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: map[string]interface{}{},
+										Ref:     ref("github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.ScheduleEntry"),
+									},
+								},
+							},
+						},
+					},
+				},
+				Required: []string{"entries"},
+			},
+		},
+		Dependencies: []string{
+			"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.ScheduleEntry"},
+	}
+}
+
+func schema_pkg_apis_playlist_v0alpha1_ScheduleEntry(ref common.ReferenceCallback) common.OpenAPIDefinition {
+	return common.OpenAPIDefinition{
+		Schema: spec.Schema{
+			SchemaProps: spec.SchemaProps{
+				Description: "ScheduleEntry defines model for ScheduleEntry.",
+				Type:        []string{"object"},
+				Properties: map[string]spec.Schema{
+					"start": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Start time of day, formatted as HH:MM.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"end": {
+						SchemaProps: spec.SchemaProps{
+							Description: "End time of day, formatted as HH:MM. Entries ending before they start span midnight.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+					"weekdays": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Weekdays the entry starts on, 0 being Sunday. Every day by default.",
+							Type:        []string{"array"},
+							Items: &spec.SchemaOrArray{
+								Schema: &spec.Schema{
+									SchemaProps: spec.SchemaProps{
+										Default: 0,
+										Type:    []string{"integer"},
+										Format:  "int32",
+									},
+								},
+							},
+						},
+					},
+					"playlistUid": {
+						SchemaProps: spec.SchemaProps{
+							Description: "PlaylistUID is the playlist played during the entry.",
+							Default:     "",
+							Type:        []string{"string"},
+							Format:      "",
+						},
+					},
+				},
+				Required: []string{"start", "end", "playlistUid"},
+			},
+		},
+	}
+}
+
 func schema_pkg_apis_playlist_v0alpha1_Spec(ref common.ReferenceCallback) common.OpenAPIDefinition {
 	return common.OpenAPIDefinition{
 		Schema: spec.Schema{
@@ -178,11 +307,17 @@ func schema_pkg_apis_playlist_v0alpha1_Spec(ref common.ReferenceCallback) common
 							},
 						},
 					},
+					"schedule": {
+						SchemaProps: spec.SchemaProps{
+							Description: "Schedule plays other playlists at given times of day.",
+							Ref:         ref("github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Schedule"),
+						},
+					},
 				},
 				Required: []string{"title", "interval"},
 			},
 		},
 		Dependencies: []string{
-			"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Item"},
+			"github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Item", "github.com/grafana/grafana/pkg/apis/playlist/v0alpha1.Schedule"},
 	}
 }
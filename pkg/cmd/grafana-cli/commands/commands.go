@@ -8,6 +8,8 @@ import (
 
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/datamigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/secretsmigrations"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/shorturlmigrations"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/snapshotmigrations"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -143,6 +145,23 @@ var adminCommands = []*cli.Command{
 				Usage:  "Migrates passwords from unsecured fields to secure_json_data field. Return ok unless there is an error. Safe to execute multiple times.",
 				Action: runDbCommand(datamigrations.EncryptDatasourcePasswords),
 			},
+			{
+				Name:   "move-snapshots-to-storage",
+				Usage:  "Moves the dashboards of snapshots from the database to the snapshot storage configured by storage_url in the [snapshots] section. Returns ok unless there is an error. Safe to execute multiple times.",
+				Action: runRunnerCommand(snapshotmigrations.MoveSnapshotsToStorage),
+			},
+			{
+				Name:   "rewrite-short-urls",
+				Usage:  "rewrite-short-urls <old dashboard uid> <new dashboard uid>. Points the short URLs of a dashboard and of its panels to the new UID of the dashboard. Returns ok unless there is an error. Safe to execute multiple times.",
+				Action: runRunnerCommand(shorturlmigrations.RewriteDashboardUID),
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "org-id",
+						Usage: "The ID of the organization of the dashboard",
+						Value: 1,
+					},
+				},
+			},
 		},
 	},
 	{
@@ -0,0 +1,37 @@
+package shorturlmigrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/shorturls"
+	"github.com/grafana/grafana/pkg/services/shorturls/shorturlimpl"
+)
+
+// RewriteDashboardUID points the short URLs of a dashboard, and of its
+// panels, to the new UID of the dashboard.
+func RewriteDashboardUID(c utils.CommandLine, runner server.Runner) error {
+	oldUID := c.Args().First()
+	newUID := c.Args().Get(1)
+	if oldUID == "" || newUID == "" {
+		return fmt.Errorf("missing arguments: rewrite-short-urls <old dashboard uid> <new dashboard uid>")
+	}
+
+	cmd := shorturls.RewriteDashboardUIDCommand{
+		OrgID:  int64(c.Int("org-id")),
+		OldUID: oldUID,
+		NewUID: newUID,
+	}
+	if err := shorturlimpl.ProvideService(runner.SQLStore, acimpl.ProvideAccessControl(runner.Cfg)).RewriteDashboardUID(context.Background(), &cmd); err != nil {
+		return err
+	}
+
+	logger.Infof("%s Rewrote %d short URLs\n", color.GreenString("✔"), cmd.NumRewritten)
+	return nil
+}
@@ -0,0 +1,38 @@
+package snapshotmigrations
+
+import (
+	"context"
+
+	"github.com/fatih/color"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/server"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	dashsnapstore "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
+	dashsnapsvc "github.com/grafana/grafana/pkg/services/dashboardsnapshots/service"
+)
+
+// MoveSnapshotsToStorage moves the dashboards of the snapshots stored in the
+// database to the snapshot storage configured by storage_url.
+func MoveSnapshotsToStorage(_ utils.CommandLine, runner server.Runner) error {
+	store := dashsnapstore.ProvideStore(runner.SQLStore, runner.Cfg)
+	service, err := dashsnapsvc.ProvideService(store, runner.SecretsService, runner.Cfg)
+	if err != nil {
+		return err
+	}
+
+	cmd := dashboardsnapshots.MoveSnapshotsToStorageCommand{}
+	err = service.MoveSnapshotsToStorage(context.Background(), &cmd)
+	if cmd.MovedSnapshots > 0 {
+		logger.Infof("%s Moved %d snapshots to the snapshot storage\n", color.GreenString("✔"), cmd.MovedSnapshots)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cmd.MovedSnapshots == 0 {
+		logger.Infof("%s All snapshots are already in the snapshot storage\n", color.GreenString("✔"))
+	}
+	return nil
+}
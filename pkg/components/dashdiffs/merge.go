@@ -0,0 +1,196 @@
+package dashdiffs
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+// Conflict is a value of a dashboard changed differently by two concurrent
+// saves. A missing value means the element was removed, or not yet added.
+type Conflict struct {
+	Path   string `json:"path"`
+	Base   any    `json:"base,omitempty"`
+	Theirs any    `json:"theirs,omitempty"`
+	Ours   any    `json:"ours,omitempty"`
+}
+
+// MergeResult is the result of a three-way merge. Dashboard is only set when
+// there are no conflicts.
+type MergeResult struct {
+	Dashboard *simplejson.Json
+	Conflicts []Conflict
+}
+
+// missingValue stands for a value that is absent from one of the merged
+// dashboards.
+type missingValue struct{}
+
+var missing any = missingValue{}
+
+// Merge merges the changes of two dashboards saved concurrently from the same
+// base version: theirs, the version already saved, and ours, the version being
+// saved. Values changed on one side only are taken from that side and values
+// changed the same way on both sides are kept. Panels, targets, variables and
+// annotations are matched the same way as in SemanticDiff, so adding or
+// changing different elements of the same list does not conflict. Panels added
+// on both sides with the same id are both kept, the one of ours getting a new
+// id. The version of the merged dashboard is not set.
+func Merge(baseData, theirsData, oursData *simplejson.Json) (*MergeResult, error) {
+	base, err := normalizeDashboard(baseData)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := normalizeDashboard(theirsData)
+	if err != nil {
+		return nil, err
+	}
+	ours, err := normalizeDashboard(oursData)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &merger{}
+	merged := m.merge("", "", base, theirs, ours)
+	if len(m.conflicts) > 0 {
+		return &MergeResult{Conflicts: m.conflicts}, nil
+	}
+
+	m.renumberPanels(merged)
+	return &MergeResult{Dashboard: simplejson.NewFromAny(denormalize(merged))}, nil
+}
+
+type merger struct {
+	conflicts []Conflict
+	// panels added on both sides with the same id, which need a new id
+	renumber []map[string]any
+}
+
+func (m *merger) merge(path, field string, base, theirs, ours any) any {
+	switch {
+	case reflect.DeepEqual(theirs, ours), reflect.DeepEqual(base, theirs):
+		return ours
+	case reflect.DeepEqual(base, ours):
+		return theirs
+	}
+
+	tm, theirsIsMap := theirs.(map[string]any)
+	om, oursIsMap := ours.(map[string]any)
+	if theirsIsMap && oursIsMap {
+		bm, baseIsMap := base.(map[string]any)
+		if baseIsMap || base == missing {
+			return m.mergeObject(path, bm, tm, om)
+		}
+	}
+
+	tl, theirsIsList := theirs.(*keyedList)
+	ol, oursIsList := ours.(*keyedList)
+	if theirsIsList && oursIsList {
+		bl, baseIsList := base.(*keyedList)
+		if baseIsList || base == missing {
+			return m.mergeList(path, field, bl, tl, ol)
+		}
+	}
+
+	m.conflicts = append(m.conflicts, Conflict{
+		Path:   path,
+		Base:   conflictValue(base),
+		Theirs: conflictValue(theirs),
+		Ours:   conflictValue(ours),
+	})
+	return theirs
+}
+
+func (m *merger) mergeObject(path string, base, theirs, ours map[string]any) map[string]any {
+	res := map[string]any{}
+	for _, key := range unionKeys(base, theirs, ours) {
+		v := m.merge(joinPath(path, key), key, mapValue(base, key), mapValue(theirs, key), mapValue(ours, key))
+		if v != missing {
+			res[key] = v
+		}
+	}
+	return res
+}
+
+func (m *merger) mergeList(path, field string, base, theirs, ours *keyedList) *keyedList {
+	res := &keyedList{items: map[string]any{}}
+	for _, key := range ours.union(theirs, base) {
+		b, t, o := listValue(base, key), listValue(theirs, key), listValue(ours, key)
+		itemPath := path + "[" + key + "]"
+
+		// panels added on both sides get the same id when it is the next free one
+		if field == "panels" && b == missing && t != missing && o != missing && !reflect.DeepEqual(t, o) {
+			if panel, ok := o.(map[string]any); ok {
+				res.add(key, t)
+				res.add(key+"#ours", panel)
+				m.renumber = append(m.renumber, panel)
+				continue
+			}
+		}
+
+		if v := m.merge(itemPath, field, b, t, o); v != missing {
+			res.add(key, v)
+		}
+	}
+	return res
+}
+
+// renumberPanels gives the panels added on both sides with the same id the
+// next free ids of the merged dashboard.
+func (m *merger) renumberPanels(dash any) {
+	if len(m.renumber) == 0 {
+		return
+	}
+	next := maxPanelID(mapValue(dash.(map[string]any), "panels")) + 1
+	for _, panel := range m.renumber {
+		panel["id"] = json.Number(strconv.FormatInt(next, 10))
+		next++
+	}
+}
+
+func maxPanelID(panels any) int64 {
+	list, ok := panels.(*keyedList)
+	if !ok {
+		return 0
+	}
+	var maxID int64
+	for _, p := range list.items {
+		panel, ok := p.(map[string]any)
+		if !ok {
+			continue
+		}
+		if id, ok := panel["id"].(json.Number); ok {
+			if v, err := id.Int64(); err == nil && v > maxID {
+				maxID = v
+			}
+		}
+		// collapsed rows hold their panels
+		if v := maxPanelID(panel["panels"]); v > maxID {
+			maxID = v
+		}
+	}
+	return maxID
+}
+
+func mapValue(m map[string]any, key string) any {
+	if v, ok := m[key]; ok {
+		return v
+	}
+	return missing
+}
+
+func listValue(l *keyedList, key string) any {
+	if v, ok := l.get(key); ok {
+		return v
+	}
+	return missing
+}
+
+func conflictValue(v any) any {
+	if v == missing {
+		return nil
+	}
+	return denormalize(v)
+}
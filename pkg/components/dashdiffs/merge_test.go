@@ -0,0 +1,89 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge(t *testing.T) {
+	t.Run("non conflicting changes are merged", func(t *testing.T) {
+		theirs := `{
+			"title": "Service",
+			"version": 4,
+			"panels": [
+				{"id": 1, "title": "Requests", "gridPos": {"x": 0, "y": 0}, "targets": [{"refId": "A", "expr": "rate(requests[1m])"}]},
+				{"id": 2, "title": "Errors", "gridPos": {"x": 12, "y": 0}, "targets": [{"refId": "A", "expr": "rate(errors[5m])"}]},
+				{"id": 3, "title": "Latency", "gridPos": {"x": 0, "y": 8}}
+			],
+			"templating": {"list": [{"name": "job", "query": "label_values(job)"}]},
+			"annotations": {"list": [{"name": "Deployments", "enable": true}]}
+		}`
+		ours := `{
+			"title": "Service overview",
+			"version": 3,
+			"panels": [
+				{"id": 1, "title": "Requests", "gridPos": {"x": 0, "y": 0}, "targets": [{"refId": "A", "expr": "rate(requests[5m])"}]},
+				{"id": 2, "title": "Errors", "gridPos": {"x": 12, "y": 0}, "targets": [{"refId": "A", "expr": "rate(errors[5m])"}]},
+				{"id": 3, "title": "Saturation", "gridPos": {"x": 12, "y": 8}}
+			],
+			"templating": {"list": [{"name": "job", "query": "label_values(job)"}, {"name": "instance", "query": "label_values(instance)"}]},
+			"annotations": {"list": [{"name": "Deployments", "enable": false}]}
+		}`
+
+		result, err := Merge(mustJSON(t, baseDashboardJSON), mustJSON(t, theirs), mustJSON(t, ours))
+		require.NoError(t, err)
+		require.Empty(t, result.Conflicts)
+
+		dash := result.Dashboard
+		require.Equal(t, "Service overview", dash.Get("title").MustString())
+		_, hasVersion := dash.CheckGet("version")
+		require.False(t, hasVersion)
+
+		panels := dash.Get("panels").MustArray()
+		require.Len(t, panels, 4)
+		require.Equal(t, "rate(requests[1m])", dash.Get("panels").GetIndex(0).Get("targets").GetIndex(0).Get("expr").MustString())
+		// both sides added a panel with id 3, ours gets the next free id
+		require.Equal(t, "Latency", dash.Get("panels").GetIndex(2).Get("title").MustString())
+		require.Equal(t, "Saturation", dash.Get("panels").GetIndex(3).Get("title").MustString())
+		require.Equal(t, int64(4), dash.Get("panels").GetIndex(3).Get("id").MustInt64())
+
+		require.Len(t, dash.GetPath("templating", "list").MustArray(), 2)
+		require.False(t, dash.GetPath("annotations", "list").GetIndex(0).Get("enable").MustBool(true))
+	})
+
+	t.Run("conflicting changes are returned", func(t *testing.T) {
+		theirs := `{
+			"title": "Service",
+			"panels": [
+				{"id": 1, "title": "Requests", "gridPos": {"x": 0, "y": 0}, "targets": [{"refId": "A", "expr": "rate(requests[1m])"}]}
+			],
+			"templating": {"list": [{"name": "job", "query": "label_values(job)"}]},
+			"annotations": {"list": [{"name": "Deployments", "enable": true}]}
+		}`
+		ours := `{
+			"title": "Service",
+			"panels": [
+				{"id": 1, "title": "Requests", "gridPos": {"x": 0, "y": 0}, "targets": [{"refId": "A", "expr": "rate(requests[10m])"}]},
+				{"id": 2, "title": "Errors (total)", "gridPos": {"x": 12, "y": 0}, "targets": [{"refId": "A", "expr": "rate(errors[5m])"}]}
+			],
+			"templating": {"list": [{"name": "job", "query": "label_values(job)"}]},
+			"annotations": {"list": [{"name": "Deployments", "enable": true}]}
+		}`
+
+		result, err := Merge(mustJSON(t, baseDashboardJSON), mustJSON(t, theirs), mustJSON(t, ours))
+		require.NoError(t, err)
+		require.Nil(t, result.Dashboard)
+		require.Len(t, result.Conflicts, 2)
+
+		require.Equal(t, "panels[id=1].targets[refId=A].expr", result.Conflicts[0].Path)
+		require.Equal(t, "rate(requests[5m])", result.Conflicts[0].Base)
+		require.Equal(t, "rate(requests[1m])", result.Conflicts[0].Theirs)
+		require.Equal(t, "rate(requests[10m])", result.Conflicts[0].Ours)
+
+		// the panel was removed by theirs and changed by ours
+		require.Equal(t, "panels[id=2]", result.Conflicts[1].Path)
+		require.Nil(t, result.Conflicts[1].Theirs)
+		require.NotNil(t, result.Conflicts[1].Ours)
+	})
+}
@@ -0,0 +1,345 @@
+package dashdiffs
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type SemanticChangeType string
+
+const (
+	SemanticChangeAdded    SemanticChangeType = "added"
+	SemanticChangeRemoved  SemanticChangeType = "removed"
+	SemanticChangeModified SemanticChangeType = "modified"
+)
+
+// SemanticChange is a change of a dashboard element found by the semantic
+// diff. Kind is the element the change belongs to: dashboard, panel, target,
+// variable or annotation. Path identifies the changed value, with panels,
+// targets, variables and annotations referenced by their key rather than their
+// index, for example `panels[id=2].targets[refId=A].expr`.
+type SemanticChange struct {
+	Type   SemanticChangeType `json:"type"`
+	Kind   string             `json:"kind"`
+	Path   string             `json:"path"`
+	Title  string             `json:"title,omitempty"`
+	Before any                `json:"before,omitempty"`
+	After  any                `json:"after,omitempty"`
+}
+
+// SemanticDiff computes the changes between two dashboard versions. Unlike the
+// JSON diff, panels are matched by id, library panel UID or grid position,
+// targets by refId and variables and annotations by name, so reordering them
+// or adding one in the middle of a list is not reported as a change of every
+// following item.
+func SemanticDiff(baseData, newData *simplejson.Json) ([]SemanticChange, error) {
+	base, err := normalizeDashboard(baseData)
+	if err != nil {
+		return nil, err
+	}
+	newDash, err := normalizeDashboard(newData)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []SemanticChange{}
+	diffObject("dashboard", "", base, newDash, &changes)
+	return changes, nil
+}
+
+func diffObject(kind, path string, base, newObj map[string]any, changes *[]SemanticChange) {
+	for _, key := range unionKeys(base, newObj) {
+		b, inBase := base[key]
+		n, inNew := newObj[key]
+		if inBase && inNew && reflect.DeepEqual(b, n) {
+			continue
+		}
+		keyPath := joinPath(path, key)
+
+		bl, baseIsList := b.(*keyedList)
+		nl, newIsList := n.(*keyedList)
+		if (baseIsList || !inBase) && (newIsList || !inNew) {
+			diffList(listKind(path, key), keyPath, bl, nl, changes)
+			continue
+		}
+
+		bm, baseIsMap := b.(map[string]any)
+		nm, newIsMap := n.(map[string]any)
+		if kind == "dashboard" && (key == "templating" || key == "annotations") && baseIsMap && newIsMap {
+			diffObject(kind, keyPath, bm, nm, changes)
+			continue
+		}
+
+		*changes = append(*changes, SemanticChange{
+			Type:   changeType(inBase, inNew),
+			Kind:   kind,
+			Path:   keyPath,
+			Before: denormalize(b),
+			After:  denormalize(n),
+		})
+	}
+}
+
+func diffList(kind, path string, base, newList *keyedList, changes *[]SemanticChange) {
+	for _, key := range base.union(newList) {
+		b, inBase := base.get(key)
+		n, inNew := newList.get(key)
+		if inBase && inNew && reflect.DeepEqual(b, n) {
+			continue
+		}
+		itemPath := path + "[" + key + "]"
+
+		bm, baseIsMap := b.(map[string]any)
+		nm, newIsMap := n.(map[string]any)
+		if baseIsMap && newIsMap {
+			diffObject(kind, itemPath, bm, nm, changes)
+			continue
+		}
+
+		change := SemanticChange{Type: changeType(inBase, inNew), Kind: kind, Path: itemPath}
+		if inBase {
+			change.Title = itemTitle(b)
+			change.Before = denormalize(b)
+		}
+		if inNew {
+			change.Title = itemTitle(n)
+			change.After = denormalize(n)
+		}
+		*changes = append(*changes, change)
+	}
+}
+
+func changeType(inBase, inNew bool) SemanticChangeType {
+	switch {
+	case !inBase:
+		return SemanticChangeAdded
+	case !inNew:
+		return SemanticChangeRemoved
+	}
+	return SemanticChangeModified
+}
+
+// listKind returns the kind of the items of a keyed list
+func listKind(parent, field string) string {
+	switch {
+	case field == "panels":
+		return "panel"
+	case field == "targets":
+		return "target"
+	case parent == "templating":
+		return "variable"
+	case parent == "annotations":
+		return "annotation"
+	}
+	return field
+}
+
+func itemTitle(item any) string {
+	m, ok := item.(map[string]any)
+	if !ok {
+		return ""
+	}
+	for _, field := range []string{"title", "name", "refId"} {
+		if s, ok := m[field].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func unionKeys(maps ...map[string]any) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// keyedList is an array of dashboard elements identified by a key rather than
+// their index, such as the panels by their id.
+type keyedList struct {
+	keys  []string
+	items map[string]any
+}
+
+func (l *keyedList) get(key string) (any, bool) {
+	if l == nil {
+		return nil, false
+	}
+	v, ok := l.items[key]
+	return v, ok
+}
+
+func (l *keyedList) add(key string, item any) {
+	if _, ok := l.items[key]; !ok {
+		l.keys = append(l.keys, key)
+	}
+	l.items[key] = item
+}
+
+// union returns the keys of both lists, in the order of l followed by the ones
+// only found in the other lists.
+func (l *keyedList) union(others ...*keyedList) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, list := range append([]*keyedList{l}, others...) {
+		if list == nil {
+			continue
+		}
+		for _, k := range list.keys {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	return keys
+}
+
+// normalizeDashboard decodes a dashboard, converting the arrays of panels,
+// targets, variables and annotations to keyed lists.
+func normalizeDashboard(data *simplejson.Json) (map[string]any, error) {
+	if data == nil {
+		return map[string]any{}, nil
+	}
+	b, err := data.Encode()
+	if err != nil {
+		return nil, err
+	}
+
+	// numbers are decoded the same way whether the dashboard was read from the
+	// database or from a request
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var dash map[string]any
+	if err := dec.Decode(&dash); err != nil {
+		return nil, err
+	}
+	if dash == nil {
+		return map[string]any{}, nil
+	}
+	// the version changes with every save and is not part of the content
+	delete(dash, "version")
+	return normalize(dash, "", "").(map[string]any), nil
+}
+
+func normalize(v any, parent, field string) any {
+	switch val := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(val))
+		for k, item := range val {
+			res[k] = normalize(item, field, k)
+		}
+		return res
+	case []any:
+		keyFn := itemKeyFunc(parent, field)
+		if keyFn == nil {
+			res := make([]any, len(val))
+			for i, item := range val {
+				res[i] = normalize(item, parent, field)
+			}
+			return res
+		}
+		list := &keyedList{items: make(map[string]any, len(val))}
+		for i, item := range val {
+			key := keyFn(item, i)
+			for dup := 2; ; dup++ {
+				if _, exists := list.items[key]; !exists {
+					break
+				}
+				key = fmt.Sprintf("%s#%d", keyFn(item, i), dup)
+			}
+			list.add(key, normalize(item, parent, field))
+		}
+		return list
+	}
+	return v
+}
+
+func itemKeyFunc(parent, field string) func(item any, index int) string {
+	switch {
+	case field == "panels":
+		return panelKey
+	case field == "targets" && parent == "panels":
+		return fieldKey("refId")
+	case field == "list" && (parent == "templating" || parent == "annotations"):
+		return fieldKey("name")
+	}
+	return nil
+}
+
+// panelKey identifies a panel by its id, falling back to the UID of its
+// library panel and its position in the grid.
+func panelKey(item any, index int) string {
+	panel, ok := item.(map[string]any)
+	if !ok {
+		return fmt.Sprintf("index=%d", index)
+	}
+	if id, ok := panel["id"].(json.Number); ok && id.String() != "0" {
+		return "id=" + id.String()
+	}
+	if lib, ok := panel["libraryPanel"].(map[string]any); ok {
+		if uid, ok := lib["uid"].(string); ok && uid != "" {
+			return "libraryPanel=" + uid
+		}
+	}
+	if pos, ok := panel["gridPos"].(map[string]any); ok {
+		return fmt.Sprintf("gridPos=%v,%v", pos["x"], pos["y"])
+	}
+	return fmt.Sprintf("index=%d", index)
+}
+
+func fieldKey(name string) func(item any, index int) string {
+	return func(item any, index int) string {
+		if m, ok := item.(map[string]any); ok {
+			if s, ok := m[name].(string); ok && s != "" {
+				return name + "=" + s
+			}
+		}
+		return fmt.Sprintf("index=%d", index)
+	}
+}
+
+// denormalize converts keyed lists back to arrays
+func denormalize(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		res := make(map[string]any, len(val))
+		for k, item := range val {
+			res[k] = denormalize(item)
+		}
+		return res
+	case []any:
+		res := make([]any, len(val))
+		for i, item := range val {
+			res[i] = denormalize(item)
+		}
+		return res
+	case *keyedList:
+		res := make([]any, 0, len(val.keys))
+		for _, k := range val.keys {
+			res = append(res, denormalize(val.items[k]))
+		}
+		return res
+	}
+	return v
+}
@@ -0,0 +1,72 @@
+package dashdiffs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const baseDashboardJSON = `{
+	"title": "Service",
+	"version": 3,
+	"panels": [
+		{"id": 1, "title": "Requests", "gridPos": {"x": 0, "y": 0}, "targets": [{"refId": "A", "expr": "rate(requests[5m])"}]},
+		{"id": 2, "title": "Errors", "gridPos": {"x": 12, "y": 0}, "targets": [{"refId": "A", "expr": "rate(errors[5m])"}]}
+	],
+	"templating": {"list": [{"name": "job", "query": "label_values(job)"}]},
+	"annotations": {"list": [{"name": "Deployments", "enable": true}]}
+}`
+
+func mustJSON(t *testing.T, s string) *simplejson.Json {
+	t.Helper()
+	js, err := simplejson.NewJson([]byte(s))
+	require.NoError(t, err)
+	return js
+}
+
+func TestSemanticDiff(t *testing.T) {
+	newDashboard := `{
+		"title": "Service",
+		"version": 4,
+		"panels": [
+			{"id": 3, "title": "Latency", "gridPos": {"x": 0, "y": 8}},
+			{"id": 2, "title": "Errors", "gridPos": {"x": 12, "y": 0}, "targets": [{"refId": "A", "expr": "rate(errors[5m])"}, {"refId": "B", "expr": "rate(panics[5m])"}]},
+			{"id": 1, "title": "Requests", "gridPos": {"x": 0, "y": 0}, "targets": [{"refId": "A", "expr": "rate(requests[1m])"}]}
+		],
+		"templating": {"list": [{"name": "job", "query": "label_values(job)"}, {"name": "instance", "query": "label_values(instance)"}]},
+		"annotations": {"list": []}
+	}`
+
+	changes, err := SemanticDiff(mustJSON(t, baseDashboardJSON), mustJSON(t, newDashboard))
+	require.NoError(t, err)
+
+	paths := map[string]SemanticChangeType{}
+	kinds := map[string]string{}
+	for _, c := range changes {
+		paths[c.Path] = c.Type
+		kinds[c.Path] = c.Kind
+	}
+	require.Equal(t, map[string]SemanticChangeType{
+		"panels[id=1].targets[refId=A].expr": SemanticChangeModified,
+		"panels[id=2].targets[refId=B]":      SemanticChangeAdded,
+		"panels[id=3]":                       SemanticChangeAdded,
+		"templating.list[name=instance]":     SemanticChangeAdded,
+		"annotations.list[name=Deployments]": SemanticChangeRemoved,
+	}, paths)
+	require.Equal(t, "target", kinds["panels[id=1].targets[refId=A].expr"])
+	require.Equal(t, "panel", kinds["panels[id=3]"])
+	require.Equal(t, "variable", kinds["templating.list[name=instance]"])
+	require.Equal(t, "annotation", kinds["annotations.list[name=Deployments]"])
+
+	t.Run("panels without id are matched by library panel UID and grid position", func(t *testing.T) {
+		base := `{"panels": [{"libraryPanel": {"uid": "lib"}, "gridPos": {"x": 0, "y": 0}}, {"title": "Text", "gridPos": {"x": 0, "y": 8}}]}`
+		newDashboard := `{"panels": [{"title": "Text", "gridPos": {"x": 0, "y": 8}}, {"libraryPanel": {"uid": "lib"}, "gridPos": {"x": 0, "y": 16}}]}`
+		changes, err := SemanticDiff(mustJSON(t, base), mustJSON(t, newDashboard))
+		require.NoError(t, err)
+		require.Len(t, changes, 1)
+		require.Equal(t, "panels[libraryPanel=lib].gridPos", changes[0].Path)
+		require.Equal(t, "panel", changes[0].Kind)
+	})
+}
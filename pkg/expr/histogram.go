@@ -0,0 +1,222 @@
+package expr
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/util/converter"
+)
+
+type histogramBucket struct {
+	lower float64
+	upper float64
+	count float64
+}
+
+// parseHistogramQuantiles parses the histogramQuantiles option of a query,
+// which is a list of quantiles between 0 and 1
+func parseHistogramQuantiles(raw any) ([]float64, error) {
+	list, ok := raw.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a list of quantiles, got type %T", raw)
+	}
+	quantiles := make([]float64, 0, len(list))
+	for _, v := range list {
+		q, ok := v.(float64)
+		if !ok {
+			return nil, fmt.Errorf("expected quantile to be a float64, got type %T", v)
+		}
+		if q < 0 || q > 1 {
+			return nil, fmt.Errorf("quantile %v is not between 0 and 1", q)
+		}
+		quantiles = append(quantiles, q)
+	}
+	return quantiles, nil
+}
+
+// convertDataFramesToResults converts the response frames of the node. When
+// the node has histogram quantiles, native histograms are converted to the
+// series of their quantiles.
+func (dn *DSNode) convertDataFramesToResults(ctx context.Context, frames data.Frames, s *Service, logger log.Logger) (string, mathexp.Results, error) {
+	if len(dn.histogramQuantiles) == 0 {
+		return convertDataFramesToResults(ctx, frames, dn.datasource.Type, s, logger)
+	}
+	histograms, others := splitNativeHistogramFrames(frames)
+	if len(histograms) == 0 {
+		return convertDataFramesToResults(ctx, frames, dn.datasource.Type, s, logger)
+	}
+
+	vals, err := nativeHistogramFramesToValues(histograms, dn.histogramQuantiles)
+	if err != nil {
+		return "", mathexp.Results{}, fmt.Errorf("failed to read native histograms: %w", err)
+	}
+	if len(others) > 0 {
+		_, res, err := convertDataFramesToResults(ctx, others, dn.datasource.Type, s, logger)
+		if err != nil {
+			return "", mathexp.Results{}, err
+		}
+		for _, v := range res.Values {
+			if v.Type() != parse.TypeNoData {
+				vals = append(vals, v)
+			}
+		}
+	}
+	return "native histogram", mathexp.Results{Values: vals}, nil
+}
+
+// splitNativeHistogramFrames returns the frames of native histograms and the other frames
+func splitNativeHistogramFrames(frames data.Frames) (data.Frames, data.Frames) {
+	var histograms, others data.Frames
+	for _, frame := range frames {
+		if converter.IsNativeHistogramFrame(frame) {
+			histograms = append(histograms, frame)
+		} else {
+			others = append(others, frame)
+		}
+	}
+	return histograms, others
+}
+
+// nativeHistogramFramesToValues converts native histograms to the series of
+// the given quantiles, so they can be reduced and compared to thresholds. Like
+// the series of summaries, the series of each quantile has a quantile label.
+// Histograms of instant queries are converted to numbers.
+func nativeHistogramFramesToValues(frames data.Frames, quantiles []float64) (mathexp.Values, error) {
+	vals := make(mathexp.Values, 0, len(frames)*len(quantiles))
+	for _, frame := range frames {
+		times, buckets, labels, err := readNativeHistogramFrame(frame)
+		if err != nil {
+			return nil, err
+		}
+		vector := isVectorFrame(frame)
+
+		for _, q := range quantiles {
+			l := labels.Copy()
+			l["quantile"] = strconv.FormatFloat(q, 'f', -1, 64)
+
+			if vector {
+				n := mathexp.NewNumber(frame.Name, l)
+				if len(times) > 0 {
+					v := histogramQuantile(q, buckets[len(times)-1])
+					n.SetValue(&v)
+				}
+				vals = append(vals, n)
+				continue
+			}
+
+			s := mathexp.NewSeries(frame.Name, l, len(times))
+			for i, t := range times {
+				v := histogramQuantile(q, buckets[i])
+				s.SetPoint(i, t, &v)
+			}
+			vals = append(vals, s)
+		}
+	}
+	return vals, nil
+}
+
+func isVectorFrame(frame *data.Frame) bool {
+	if frame.Meta == nil {
+		return false
+	}
+	custom, ok := frame.Meta.Custom.(map[string]string)
+	return ok && custom["resultType"] == "vector"
+}
+
+// readNativeHistogramFrame returns the times of a native histogram frame and
+// the buckets of the histogram at each time
+func readNativeHistogramFrame(frame *data.Frame) ([]time.Time, [][]histogramBucket, data.Labels, error) {
+	fields := map[string]*data.Field{}
+	for _, name := range []string{"xMax", "yMin", "yMax", "count"} {
+		field, idx := frame.FieldByName(name)
+		if idx < 0 {
+			return nil, nil, nil, fmt.Errorf("invalid native histogram frame %s: missing field %s", frame.Name, name)
+		}
+		fields[name] = field
+	}
+	labels := fields["yMin"].Labels
+	if labels == nil {
+		labels = data.Labels{}
+	}
+
+	var times []time.Time
+	var buckets [][]histogramBucket
+	for i := 0; i < frame.Rows(); i++ {
+		t, ok := fields["xMax"].ConcreteAt(i)
+		if !ok {
+			continue
+		}
+		ts, ok := t.(time.Time)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("invalid native histogram frame %s: xMax is not a time field", frame.Name)
+		}
+		lower, err := fields["yMin"].FloatAt(i)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		upper, err := fields["yMax"].FloatAt(i)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		count, err := fields["count"].FloatAt(i)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		// the buckets of a histogram are in consecutive rows
+		if len(times) == 0 || !times[len(times)-1].Equal(ts) {
+			times = append(times, ts)
+			buckets = append(buckets, nil)
+		}
+		buckets[len(buckets)-1] = append(buckets[len(buckets)-1], histogramBucket{lower: lower, upper: upper, count: count})
+	}
+	return times, buckets, labels, nil
+}
+
+// histogramQuantile estimates the q-quantile of a histogram, interpolating
+// linearly within the bucket of the quantile like histogram_quantile does for
+// classic histograms.
+func histogramQuantile(q float64, buckets []histogramBucket) float64 {
+	if math.IsNaN(q) {
+		return math.NaN()
+	}
+	if q < 0 {
+		return math.Inf(-1)
+	}
+	if q > 1 {
+		return math.Inf(1)
+	}
+
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].lower < buckets[j].lower
+	})
+	total := 0.0
+	for _, b := range buckets {
+		total += b.count
+	}
+	if total <= 0 {
+		return math.NaN()
+	}
+
+	rank := q * total
+	cumulative := 0.0
+	for _, b := range buckets {
+		if b.count <= 0 {
+			continue
+		}
+		if cumulative+b.count >= rank {
+			return b.lower + (b.upper-b.lower)*(rank-cumulative)/b.count
+		}
+		cumulative += b.count
+	}
+	return buckets[len(buckets)-1].upper
+}
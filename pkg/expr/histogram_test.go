@@ -0,0 +1,140 @@
+package expr
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp"
+	"github.com/grafana/grafana/pkg/infra/log/logtest"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util/converter"
+)
+
+func TestHistogramQuantile(t *testing.T) {
+	buckets := []histogramBucket{
+		{lower: 2, upper: 4, count: 2},
+		{lower: 0, upper: 1, count: 0},
+		{lower: 1, upper: 2, count: 2},
+	}
+	require.Equal(t, 1.5, histogramQuantile(0.25, buckets))
+	require.Equal(t, 2.0, histogramQuantile(0.5, buckets))
+	require.Equal(t, 3.0, histogramQuantile(0.75, buckets))
+	require.Equal(t, 4.0, histogramQuantile(1, buckets))
+	require.True(t, math.IsInf(histogramQuantile(2, buckets), 1))
+	require.True(t, math.IsNaN(histogramQuantile(0.5, []histogramBucket{{lower: 0, upper: 1}})))
+}
+
+func newNativeHistogramFrame(resultType string, times ...time.Time) *data.Frame {
+	frame := data.NewFrame("latency",
+		data.NewField("xMax", nil, []time.Time{}),
+		data.NewField("yMin", data.Labels{"job": "api"}, []float64{}),
+		data.NewField("yMax", nil, []float64{}),
+		data.NewField("count", nil, []float64{}),
+		data.NewField("yLayout", nil, []int8{}),
+	)
+	for i, t := range times {
+		frame.AppendRow(t, 0.0, 1.0, float64(i+1), int8(0))
+		frame.AppendRow(t, 1.0, 2.0, float64(i+1), int8(0))
+	}
+	frame.Meta = &data.FrameMeta{
+		Type:   converter.NativeHistogramFrameType,
+		Custom: map[string]string{"resultType": resultType},
+	}
+	return frame
+}
+
+func TestConvertNativeHistogramFrames(t *testing.T) {
+	s := &Service{
+		cfg:      setting.NewCfg(),
+		features: &featuremgmt.FeatureManager{},
+		tracer:   tracing.InitializeTracerForTest(),
+		metrics:  newMetrics(nil),
+	}
+	t1 := time.Unix(60, 0)
+	t2 := time.Unix(120, 0)
+	quantiles := []float64{0.5, 0.9, 0.95, 0.99}
+	dn := &DSNode{
+		datasource:         &datasources.DataSource{Type: datasources.DS_PROMETHEUS},
+		histogramQuantiles: quantiles,
+	}
+
+	t.Run("histograms of range queries are converted to quantile series", func(t *testing.T) {
+		frames := data.Frames{newNativeHistogramFrame("matrix", t1, t2)}
+		resultType, res, err := dn.convertDataFramesToResults(context.Background(), frames, s, &logtest.Fake{})
+		require.NoError(t, err)
+		require.Equal(t, "native histogram", resultType)
+		require.Len(t, res.Values, len(quantiles))
+
+		series, ok := res.Values[0].(mathexp.Series)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"job": "api", "quantile": "0.5"}, series.GetLabels())
+		require.Equal(t, 2, series.Len())
+		require.Equal(t, t2, series.GetTime(1))
+		require.Equal(t, 1.0, *series.GetValue(1))
+
+		// the quantile series can be reduced like any series
+		n, err := series.Reduce("B", "max", nil)
+		require.NoError(t, err)
+		require.Equal(t, 1.0, *n.GetFloat64Value())
+	})
+
+	t.Run("histograms of instant queries are converted to quantile numbers", func(t *testing.T) {
+		frames := data.Frames{newNativeHistogramFrame("vector", t1)}
+		_, res, err := dn.convertDataFramesToResults(context.Background(), frames, s, &logtest.Fake{})
+		require.NoError(t, err)
+		require.Len(t, res.Values, len(quantiles))
+
+		n, ok := res.Values[3].(mathexp.Number)
+		require.True(t, ok)
+		require.Equal(t, data.Labels{"job": "api", "quantile": "0.99"}, n.GetLabels())
+		require.InDelta(t, 1.98, *n.GetFloat64Value(), 0.0001)
+	})
+
+	t.Run("other frames are converted with the histograms", func(t *testing.T) {
+		frames := data.Frames{
+			newNativeHistogramFrame("matrix", t1),
+			data.NewFrame("",
+				data.NewField("time", nil, []time.Time{t1}),
+				data.NewField("value", data.Labels{"job": "web"}, []*float64{fp(2)})),
+		}
+		_, res, err := dn.convertDataFramesToResults(context.Background(), frames, s, &logtest.Fake{})
+		require.NoError(t, err)
+		require.Len(t, res.Values, len(quantiles)+1)
+		require.Equal(t, data.Labels{"job": "web"}, res.Values[len(quantiles)].GetLabels())
+	})
+
+	t.Run("histograms are not converted without quantiles", func(t *testing.T) {
+		frames := data.Frames{newNativeHistogramFrame("matrix", t1, t2)}
+		node := &DSNode{datasource: &datasources.DataSource{Type: datasources.DS_PROMETHEUS}}
+		resultType, _, _ := node.convertDataFramesToResults(context.Background(), frames, s, &logtest.Fake{})
+		require.NotEqual(t, "native histogram", resultType)
+	})
+
+	t.Run("invalid histogram frame", func(t *testing.T) {
+		frame := newNativeHistogramFrame("matrix", t1)
+		frame.Fields = frame.Fields[:2]
+		_, _, err := dn.convertDataFramesToResults(context.Background(), data.Frames{frame}, s, &logtest.Fake{})
+		require.Error(t, err)
+	})
+}
+
+func TestParseHistogramQuantiles(t *testing.T) {
+	quantiles, err := parseHistogramQuantiles([]any{0.5, 0.99})
+	require.NoError(t, err)
+	require.Equal(t, []float64{0.5, 0.99}, quantiles)
+
+	_, err = parseHistogramQuantiles([]any{1.5})
+	require.Error(t, err)
+	_, err = parseHistogramQuantiles([]any{"0.5"})
+	require.Error(t, err)
+	_, err = parseHistogramQuantiles(0.5)
+	require.Error(t, err)
+}
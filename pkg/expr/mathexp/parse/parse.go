@@ -173,7 +173,7 @@ M -> E {( "*" | "/" ) F}
 E -> F {( "**" ) F}
 F -> v | "(" O ")" | "!" O | "-" O
 v -> number | func(..) | queryVar
-Func -> name "(" param {"," param} ")"
+Func -> name "(" [param {"," param}] ")"
 param -> number | "string" | queryVar
 */
 
@@ -314,22 +314,40 @@ func (t *Tree) Func() (f *FuncNode) {
 	}
 	f = newFunc(token.pos, token.val, funcv)
 	t.expect(itemLeftParen, "func")
+	// the parameters are separated by commas
+	expectParam := true
 	for {
 		switch token = t.next(); token.typ {
 		default:
+			if !expectParam {
+				t.unexpected(token, "func")
+			}
 			t.backup()
 			node := t.O()
 			f.append(node)
 			if len(f.Args) == 1 && f.F.VariantReturn {
 				f.F.Return = node.Return()
 			}
+			expectParam = false
 		case itemString:
+			if !expectParam {
+				t.unexpected(token, "func")
+			}
 			s, err := strconv.Unquote(token.val)
 			if err != nil {
 				t.errorf("Unquoting error: %s", err)
 			}
 			f.append(newString(token.pos, token.val, s))
+			expectParam = false
+		case itemComma:
+			if expectParam {
+				t.unexpected(token, "func")
+			}
+			expectParam = true
 		case itemRightParen:
+			if expectParam && len(f.Args) > 0 {
+				t.unexpected(token, "func")
+			}
 			return
 		}
 	}
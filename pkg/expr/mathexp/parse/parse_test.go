@@ -0,0 +1,25 @@
+package parse
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFuncParams(t *testing.T) {
+	funcs := map[string]Func{
+		"one": {Args: []ReturnType{TypeVariantSet}, Return: TypeScalar},
+		"two": {Args: []ReturnType{TypeVariantSet, TypeVariantSet}, Return: TypeScalar},
+	}
+
+	for _, text := range []string{"one($A)", "two($A, 1)", "two(one($A), $B + 1)"} {
+		tree, err := Parse(text, funcs)
+		require.NoError(t, err, text)
+		require.Equal(t, text, tree.Root.String())
+	}
+
+	for _, text := range []string{"two($A 1)", "two($A,)", "two(, $A)", "two($A,, 1)", "one(1, 2)"} {
+		_, err := Parse(text, funcs)
+		require.Error(t, err, text)
+	}
+}
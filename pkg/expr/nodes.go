@@ -148,6 +148,10 @@ type DSNode struct {
 	intervalMS int64
 	maxDP      int64
 	request    Request
+
+	// quantiles the native histograms of the response are converted to,
+	// native histograms are not converted when empty
+	histogramQuantiles []float64
 }
 
 // NodeType returns the data pipeline node type.
@@ -200,6 +204,13 @@ func (s *Service) buildDSNode(dp *simple.DirectedGraph, rn *rawNode, req *Reques
 		dsNode.maxDP = int64(floatMaxDP)
 	}
 
+	if rawQuantiles, ok := rn.Query["histogramQuantiles"]; ok {
+		dsNode.histogramQuantiles, err = parseHistogramQuantiles(rawQuantiles)
+		if err != nil {
+			return nil, fmt.Errorf("invalid histogramQuantiles for refId %v: %w", rn.RefID, err)
+		}
+	}
+
 	return dsNode, nil
 }
 
@@ -289,7 +300,7 @@ func executeDSNodesGrouped(ctx context.Context, now time.Time, vars mathexp.Vars
 				}
 
 				var result mathexp.Results
-				responseType, result, err := convertDataFramesToResults(ctx, dataFrames, dn.datasource.Type, s, logger)
+				responseType, result, err := dn.convertDataFramesToResults(ctx, dataFrames, s, logger)
 				if err != nil {
 					result.Error = makeConversionError(dn.RefID(), err)
 				}
@@ -357,7 +368,7 @@ func (dn *DSNode) Execute(ctx context.Context, now time.Time, _ mathexp.Vars, s
 	}
 
 	var result mathexp.Results
-	responseType, result, err = convertDataFramesToResults(ctx, dataFrames, dn.datasource.Type, s, logger)
+	responseType, result, err = dn.convertDataFramesToResults(ctx, dataFrames, s, logger)
 	if err != nil {
 		err = makeConversionError(dn.refID, err)
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -18,6 +19,8 @@ var (
 	ErrPathEndsWithDelimiter = errors.New("path can not end with delimiter")
 	ErrPathPartTooLong       = errors.New("path part is too long")
 	ErrEmptyPathPart         = errors.New("path can not have empty parts")
+	ErrPathNotAllowed        = errors.New("path is not allowed")
+	ErrStreamingNotSupported = errors.New("file storage does not support streaming")
 	Delimiter                = "/"
 	DirectoryMimeType        = "directory"
 	multipleDelimiters       = regexp.MustCompile(`/+`)
@@ -187,3 +190,11 @@ type FileStorage interface {
 
 	close() error
 }
+
+// StreamingFileStorage is implemented by the file storages able to read and
+// write the contents of a file without loading them in memory. The contents
+// written are only stored once the writer is closed.
+type StreamingFileStorage interface {
+	OpenReader(ctx context.Context, path string) (io.ReadCloser, bool, error)
+	OpenWriter(ctx context.Context, path string, mimeType string) (io.WriteCloser, error)
+}
@@ -143,6 +143,24 @@ func (c cdkBlobStorage) Upsert(ctx context.Context, command *UpsertFileCommand)
 	})
 }
 
+func (c cdkBlobStorage) OpenReader(ctx context.Context, path string) (io.ReadCloser, bool, error) {
+	reader, err := c.bucket.NewReader(ctx, strings.ToLower(path), nil)
+	if err != nil {
+		if gcerrors.Code(err) == gcerrors.NotFound {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return reader, true, nil
+}
+
+func (c cdkBlobStorage) OpenWriter(ctx context.Context, path string, mimeType string) (io.WriteCloser, error) {
+	return c.bucket.NewWriter(ctx, strings.ToLower(path), &blob.WriterOptions{
+		ContentType: mimeType,
+		Metadata:    map[string]string{originalPathAttributeKey: path},
+	})
+}
+
 func (c cdkBlobStorage) convertFolderPathToPrefix(path string) string {
 	if path != "" && !strings.HasSuffix(path, Delimiter) {
 		return path + Delimiter
@@ -3,6 +3,7 @@ package filestorage
 import (
 	"context"
 	"fmt"
+	"io"
 	"mime"
 	"path/filepath"
 	"strings"
@@ -68,7 +69,8 @@ func newWrapper(log log.Logger, wrapped FileStorage, pathFilter PathFilter, root
 }
 
 var (
-	_ FileStorage = (*wrapper)(nil) // wrapper implements FileStorage
+	_ FileStorage          = (*wrapper)(nil) // wrapper implements FileStorage
+	_ StreamingFileStorage = (*wrapper)(nil) // wrapper implements StreamingFileStorage
 )
 
 func getParentFolderPath(path string) string {
@@ -197,6 +199,54 @@ func (b wrapper) Upsert(ctx context.Context, file *UpsertFileCommand) error {
 	})
 }
 
+// OpenReader streams the contents of the file, it fails when the wrapped
+// storage doesn't support streaming.
+func (b wrapper) OpenReader(ctx context.Context, path string) (io.ReadCloser, bool, error) {
+	streaming, ok := b.wrapped.(StreamingFileStorage)
+	if !ok {
+		return nil, false, ErrStreamingNotSupported
+	}
+
+	if err := b.validatePath(path); err != nil {
+		return nil, false, err
+	}
+
+	rootedPath := b.addRoot(path)
+	if !b.filter.IsAllowed(rootedPath) {
+		return nil, false, nil
+	}
+
+	return streaming.OpenReader(ctx, rootedPath)
+}
+
+// OpenWriter streams the contents of the file, it fails when the wrapped
+// storage doesn't support streaming.
+func (b wrapper) OpenWriter(ctx context.Context, path string, mimeType string) (io.WriteCloser, error) {
+	streaming, ok := b.wrapped.(StreamingFileStorage)
+	if !ok {
+		return nil, ErrStreamingNotSupported
+	}
+
+	if err := b.validatePath(path); err != nil {
+		return nil, err
+	}
+
+	rootedPath := b.addRoot(path)
+	if !b.filter.IsAllowed(rootedPath) {
+		return nil, ErrPathNotAllowed
+	}
+
+	if err := b.CreateFolder(ctx, getParentFolderPath(path)); err != nil {
+		return nil, err
+	}
+
+	if mimeType == "" {
+		mimeType = detectContentType(path, "")
+	}
+
+	return streaming.OpenWriter(ctx, rootedPath, mimeType)
+}
+
 func (b wrapper) pagingOptionsWithDefaults(paging *Paging) *Paging {
 	if paging == nil {
 		return &Paging{
@@ -1,10 +1,15 @@
 package filestorage
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob"
+
+	"github.com/grafana/grafana/pkg/infra/log"
 )
 
 func TestFilestorage_getParentFolderPath(t *testing.T) {
@@ -40,3 +45,37 @@ func TestFilestorage_getParentFolderPath(t *testing.T) {
 		})
 	}
 }
+
+func TestFilestorage_streaming(t *testing.T) {
+	ctx := context.Background()
+	bucket, err := blob.OpenBucket(ctx, "mem://")
+	require.NoError(t, err)
+	storage := NewCdkBlobStorage(log.New("test.filestorage"), bucket, "root/", NewPathFilter([]string{"/allowed/"}, nil, nil, nil)).(StreamingFileStorage)
+
+	w, err := storage.OpenWriter(ctx, "/allowed/Folder/file.json", "")
+	require.NoError(t, err)
+	_, err = io.WriteString(w, `{"a":1}`)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	r, found, err := storage.OpenReader(ctx, "/allowed/Folder/file.json")
+	require.NoError(t, err)
+	require.True(t, found)
+	contents, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, `{"a":1}`, string(contents))
+
+	file, found, err := storage.(FileStorage).Get(ctx, "/allowed/Folder/file.json", nil)
+	require.NoError(t, err)
+	require.True(t, found)
+	require.Equal(t, "/allowed/Folder/file.json", file.FullPath)
+	require.Equal(t, "application/json", file.MimeType)
+
+	_, found, err = storage.OpenReader(ctx, "/allowed/missing.json")
+	require.NoError(t, err)
+	require.False(t, found)
+
+	_, err = storage.OpenWriter(ctx, "/denied/file.json", "")
+	require.ErrorIs(t, err, ErrPathNotAllowed)
+}
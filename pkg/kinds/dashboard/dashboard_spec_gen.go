@@ -502,6 +502,9 @@ type LibraryPanelRef struct {
 
 	// Library panel uid
 	Uid string `json:"uid"`
+
+	// Version of the library panel the dashboard is pinned to. The latest version is used when not set.
+	Version *int64 `json:"version,omitempty"`
 }
 
 // Supported value mapping types
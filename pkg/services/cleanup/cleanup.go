@@ -101,6 +101,7 @@ func (srv *CleanUpService) clean(ctx context.Context) {
 		{"cleanup old annotations", srv.cleanUpOldAnnotations},
 		{"expire old user invites", srv.expireOldUserInvites},
 		{"delete stale short URLs", srv.deleteStaleShortURLs},
+		{"delete expired short URLs", srv.deleteExpiredShortURLs},
 		{"delete stale query history", srv.deleteStaleQueryHistory},
 	}
 
@@ -249,6 +250,18 @@ func (srv *CleanUpService) deleteStaleShortURLs(ctx context.Context) {
 	}
 }
 
+func (srv *CleanUpService) deleteExpiredShortURLs(ctx context.Context) {
+	logger := srv.log.FromContext(ctx)
+	cmd := shorturls.DeleteExpiredShortURLsCommand{
+		Now: time.Now(),
+	}
+	if err := srv.ShortURLService.DeleteExpiredShortURLs(ctx, &cmd); err != nil {
+		logger.Error("Problem deleting expired short urls", "error", err.Error())
+	} else {
+		logger.Debug("Deleted expired short urls", "rows affected", cmd.NumDeleted)
+	}
+}
+
 func (srv *CleanUpService) deleteStaleQueryHistory(ctx context.Context) {
 	logger := srv.log.FromContext(ctx)
 	// Delete query history from 14+ days ago with exception of starred queries
@@ -725,6 +725,11 @@ func (d *dashboardStore) deleteDashboard(cmd *dashboards.DeleteDashboardCommand,
 		if err := deleteFolderAlertRules(sess, dashboard, cmd.ForceDeleteFolderRules); err != nil {
 			return err
 		}
+
+		// remove the version retention policy of the folder
+		if _, err := sess.Exec("DELETE FROM dashboard_version_retention WHERE org_id = ? AND folder_uid = ?", dashboard.OrgID, dashboard.UID); err != nil {
+			return err
+		}
 	} else {
 		if err := d.deleteResourcePermissions(sess, dashboard.OrgID, ac.GetResourceScopeUID("dashboards", dashboard.UID)); err != nil {
 			return err
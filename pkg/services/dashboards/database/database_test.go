@@ -255,9 +255,24 @@ func TestIntegrationDashboardDataAccess(t *testing.T) {
 	t.Run("Should be able to delete empty folder", func(t *testing.T) {
 		setup()
 		emptyFolder := insertTestDashboard(t, dashboardStore, "2 test dash folder", 1, 0, "", true, "prod", "webapp")
+		err := sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			_, err := sess.Exec("INSERT INTO dashboard_version_retention (org_id, folder_uid, versions_to_keep, created, updated) VALUES (?, ?, ?, ?, ?)",
+				emptyFolder.OrgID, emptyFolder.UID, 3, time.Now(), time.Now())
+			return err
+		})
+		require.NoError(t, err)
 
 		deleteCmd := &dashboards.DeleteDashboardCommand{ID: emptyFolder.ID}
-		err := dashboardStore.DeleteDashboard(context.Background(), deleteCmd)
+		err = dashboardStore.DeleteDashboard(context.Background(), deleteCmd)
+		require.NoError(t, err)
+
+		// the version retention policy of the folder is deleted with it
+		err = sqlStore.WithDbSession(context.Background(), func(sess *db.Session) error {
+			exists, err := sess.Table("dashboard_version_retention").Where("org_id = ? AND folder_uid = ?", emptyFolder.OrgID, emptyFolder.UID).Exist()
+			require.NoError(t, err)
+			require.False(t, exists)
+			return nil
+		})
 		require.NoError(t, err)
 	})
 
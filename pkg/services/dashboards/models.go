@@ -241,13 +241,17 @@ type ValidateDashboardBeforeSaveResult struct {
 //
 
 type SaveDashboardCommand struct {
-	Dashboard    *simplejson.Json `json:"dashboard" binding:"Required"`
-	UserID       int64            `json:"userId" xorm:"user_id"`
-	Overwrite    bool             `json:"overwrite"`
-	Message      string           `json:"message"`
-	OrgID        int64            `json:"-" xorm:"org_id"`
-	RestoredFrom int              `json:"-"`
-	PluginID     string           `json:"-" xorm:"plugin_id"`
+	Dashboard *simplejson.Json `json:"dashboard" binding:"Required"`
+	UserID    int64            `json:"userId" xorm:"user_id"`
+	Overwrite bool             `json:"overwrite"`
+	// Merge merges the changes of the dashboard with the ones saved since its version was loaded,
+	// instead of failing with a version mismatch. Conflicting changes are returned with the
+	// version mismatch error.
+	Merge        bool   `json:"merge"`
+	Message      string `json:"message"`
+	OrgID        int64  `json:"-" xorm:"org_id"`
+	RestoredFrom int    `json:"-"`
+	PluginID     string `json:"-" xorm:"plugin_id"`
 	// Deprecated: use FolderUID instead
 	FolderID  int64  `json:"folderId" xorm:"folder_id"`
 	FolderUID string `json:"folderUid" xorm:"folder_uid"`
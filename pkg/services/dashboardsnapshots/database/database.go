@@ -26,7 +26,8 @@ func ProvideStore(db db.DB, cfg *setting.Cfg) *DashboardSnapshotStore {
 	return &DashboardSnapshotStore{store: db, log: log.New("dashboardsnapshot.store"), cfg: cfg}
 }
 
-// DeleteExpiredSnapshots removes snapshots with old expiry dates.
+// DeleteExpiredSnapshots removes snapshots with old expiry dates, restricted to
+// the snapshots with the given ids when set.
 // SnapShotRemoveExpired is deprecated and should be removed in the future.
 // Snapshot expiry is decided by the user when they share the snapshot.
 func (d *DashboardSnapshotStore) DeleteExpiredSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteExpiredSnapshotsCommand) error {
@@ -36,6 +37,12 @@ func (d *DashboardSnapshotStore) DeleteExpiredSnapshots(ctx context.Context, cmd
 			return nil
 		}
 
+		if len(cmd.IDs) > 0 {
+			deleted, err := sess.Where("expires < ?", time.Now()).In("id", cmd.IDs).Delete(&dashboardsnapshots.DashboardSnapshot{})
+			cmd.DeletedRows = deleted
+			return err
+		}
+
 		deleteExpiredSQL := "DELETE FROM dashboard_snapshot WHERE expires < ?"
 		expiredResponse, err := sess.Exec(deleteExpiredSQL, time.Now())
 		if err != nil {
@@ -47,6 +54,75 @@ func (d *DashboardSnapshotStore) DeleteExpiredSnapshots(ctx context.Context, cmd
 	})
 }
 
+func (d *DashboardSnapshotStore) GetExpiredSnapshots(ctx context.Context, query *dashboardsnapshots.GetExpiredSnapshotsQuery) ([]*dashboardsnapshots.DashboardSnapshot, error) {
+	snapshots := make([]*dashboardsnapshots.DashboardSnapshot, 0)
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Table("dashboard_snapshot").Cols("id", "key", "blob_key").Where("expires < ?", time.Now()).Asc("expires")
+		if query.Limit > 0 {
+			sess.Limit(query.Limit)
+		}
+		return sess.Find(&snapshots)
+	})
+	return snapshots, err
+}
+
+func (d *DashboardSnapshotStore) GetSnapshotsStoredInDB(ctx context.Context, query *dashboardsnapshots.GetSnapshotsStoredInDBQuery) ([]*dashboardsnapshots.DashboardSnapshot, error) {
+	snapshots := make([]*dashboardsnapshots.DashboardSnapshot, 0)
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Table("dashboard_snapshot").
+			Where("(blob_key IS NULL OR blob_key = '') AND external = ? AND id > ?", d.store.GetDialect().BooleanStr(false), query.AfterID).
+			Asc("id")
+		if query.Limit > 0 {
+			sess.Limit(query.Limit)
+		}
+		return sess.Find(&snapshots)
+	})
+	return snapshots, err
+}
+
+func (d *DashboardSnapshotStore) GetOwnedSnapshots(ctx context.Context, query *dashboardsnapshots.GetOwnedSnapshotsQuery) ([]*dashboardsnapshots.DashboardSnapshot, error) {
+	snapshots := make([]*dashboardsnapshots.DashboardSnapshot, 0)
+	err := d.store.WithDbSession(ctx, func(sess *db.Session) error {
+		sess.Table("dashboard_snapshot").Cols("id", "key", "blob_key").Asc("id")
+		if query.OrgID > 0 {
+			sess.Where("org_id = ?", query.OrgID)
+		}
+		if query.UserID > 0 {
+			sess.Where("user_id = ?", query.UserID)
+		}
+		if query.Limit > 0 {
+			sess.Limit(query.Limit)
+		}
+		return sess.Find(&snapshots)
+	})
+	return snapshots, err
+}
+
+func (d *DashboardSnapshotStore) DeleteSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteSnapshotsCommand) error {
+	if len(cmd.IDs) == 0 {
+		return nil
+	}
+	return d.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		deleted, err := sess.In("id", cmd.IDs).Delete(&dashboardsnapshots.DashboardSnapshot{})
+		cmd.DeletedRows = deleted
+		return err
+	})
+}
+
+func (d *DashboardSnapshotStore) SetSnapshotBlobKey(ctx context.Context, cmd *dashboardsnapshots.SetSnapshotBlobKeyCommand) error {
+	return d.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		rawSQL := "UPDATE dashboard_snapshot SET blob_key = ?, dashboard = ?, dashboard_encrypted = NULL, updated = ? WHERE id = ?"
+		res, err := sess.Exec(rawSQL, cmd.BlobKey, "{}", time.Now(), cmd.ID)
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+			return dashboardsnapshots.ErrBaseNotFound.Errorf("dashboard snapshot not found")
+		}
+		return nil
+	})
+}
+
 func (d *DashboardSnapshotStore) CreateDashboardSnapshot(ctx context.Context, cmd *dashboardsnapshots.CreateDashboardSnapshotCommand) (*dashboardsnapshots.DashboardSnapshot, error) {
 	var result *dashboardsnapshots.DashboardSnapshot
 	err := d.store.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
@@ -66,6 +142,7 @@ func (d *DashboardSnapshotStore) CreateDashboardSnapshot(ctx context.Context, cm
 			ExternalDeleteURL:  cmd.ExternalDeleteURL,
 			Dashboard:          simplejson.New(),
 			DashboardEncrypted: cmd.DashboardEncrypted,
+			BlobKey:            cmd.BlobKey,
 			Expires:            expires,
 			Created:            time.Now(),
 			Updated:            time.Now(),
@@ -191,6 +191,61 @@ func TestIntegrationDeleteExpiredSnapshots(t *testing.T) {
 	})
 }
 
+func TestIntegrationSnapshotsStoredInStorage(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	sqlstore := db.InitTestDB(t)
+	dashStore := ProvideStore(sqlstore, setting.NewCfg())
+	dashStore.cfg.SnapShotRemoveExpired = true
+	ctx := context.Background()
+
+	nonExpiredSnapshot := createTestSnapshot(t, dashStore, "key1", 48000)
+	expiredSnapshot := createTestSnapshot(t, dashStore, "key2", -1200)
+	otherExpiredSnapshot := createTestSnapshot(t, dashStore, "key3", -1200)
+
+	t.Run("Should list the snapshots stored in the database", func(t *testing.T) {
+		snapshots, err := dashStore.GetSnapshotsStoredInDB(ctx, &dashboardsnapshots.GetSnapshotsStoredInDBQuery{AfterID: nonExpiredSnapshot.ID, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, snapshots, 2)
+		require.Equal(t, expiredSnapshot.ID, snapshots[0].ID)
+		require.Equal(t, otherExpiredSnapshot.ID, snapshots[1].ID)
+	})
+
+	t.Run("Should not list the snapshots moved to the storage", func(t *testing.T) {
+		err := dashStore.SetSnapshotBlobKey(ctx, &dashboardsnapshots.SetSnapshotBlobKeyCommand{ID: nonExpiredSnapshot.ID, BlobKey: "blob1"})
+		require.NoError(t, err)
+
+		snapshots, err := dashStore.GetSnapshotsStoredInDB(ctx, &dashboardsnapshots.GetSnapshotsStoredInDBQuery{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, snapshots, 2)
+
+		snapshot, err := dashStore.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: nonExpiredSnapshot.Key})
+		require.NoError(t, err)
+		require.Equal(t, "blob1", snapshot.BlobKey)
+		require.Empty(t, snapshot.DashboardEncrypted)
+
+		err = dashStore.SetSnapshotBlobKey(ctx, &dashboardsnapshots.SetSnapshotBlobKeyCommand{ID: 1000, BlobKey: "blob1"})
+		require.ErrorIs(t, err, dashboardsnapshots.ErrBaseNotFound)
+	})
+
+	t.Run("Should delete only the given expired snapshots", func(t *testing.T) {
+		expired, err := dashStore.GetExpiredSnapshots(ctx, &dashboardsnapshots.GetExpiredSnapshotsQuery{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, expired, 2)
+
+		cmd := dashboardsnapshots.DeleteExpiredSnapshotsCommand{IDs: []int64{nonExpiredSnapshot.ID, expiredSnapshot.ID}}
+		err = dashStore.DeleteExpiredSnapshots(ctx, &cmd)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), cmd.DeletedRows)
+
+		expired, err = dashStore.GetExpiredSnapshots(ctx, &dashboardsnapshots.GetExpiredSnapshotsQuery{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, expired, 1)
+		require.Equal(t, otherExpiredSnapshot.ID, expired[0].ID)
+	})
+}
+
 func createTestSnapshot(t *testing.T, dashStore *DashboardSnapshotStore, key string, expires int64) *dashboardsnapshots.DashboardSnapshot {
 	cmd := dashboardsnapshots.CreateDashboardSnapshotCommand{
 		Key:       key,
@@ -25,6 +25,9 @@ type DashboardSnapshot struct {
 
 	Dashboard          *simplejson.Json
 	DashboardEncrypted []byte
+	// BlobKey is the key of the encrypted dashboard in the snapshot storage.
+	// It is empty when the dashboard is stored in the database.
+	BlobKey string `xorm:"blob_key"`
 }
 
 // DashboardSnapshotDTO without dashboard map
@@ -77,6 +80,7 @@ type CreateDashboardSnapshotCommand struct {
 	UserID int64 `json:"-"`
 
 	DashboardEncrypted []byte `json:"-"`
+	BlobKey            string `json:"-"`
 }
 
 type DeleteDashboardSnapshotCommand struct {
@@ -84,9 +88,57 @@ type DeleteDashboardSnapshotCommand struct {
 }
 
 type DeleteExpiredSnapshotsCommand struct {
+	// IDs restricts the deletion to the expired snapshots with these ids
+	IDs         []int64
 	DeletedRows int64
 }
 
+// GetExpiredSnapshotsQuery returns the id and blob key of expired snapshots,
+// oldest first.
+type GetExpiredSnapshotsQuery struct {
+	Limit int
+}
+
+// DeleteOwnedSnapshotsCommand deletes the snapshots of the organization OrgID,
+// or of the user UserID, with their dashboards.
+type DeleteOwnedSnapshotsCommand struct {
+	OrgID       int64
+	UserID      int64
+	DeletedRows int64
+}
+
+// GetOwnedSnapshotsQuery returns the id and blob key of the snapshots of the
+// organization OrgID, or of the user UserID, by ascending id.
+type GetOwnedSnapshotsQuery struct {
+	OrgID  int64
+	UserID int64
+	Limit  int
+}
+
+// DeleteSnapshotsCommand deletes the snapshots with the given ids.
+type DeleteSnapshotsCommand struct {
+	IDs         []int64
+	DeletedRows int64
+}
+
+// GetSnapshotsStoredInDBQuery returns the snapshots whose dashboard is stored in
+// the database, external snapshots excluded, by ascending id.
+type GetSnapshotsStoredInDBQuery struct {
+	AfterID int64
+	Limit   int
+}
+
+// SetSnapshotBlobKeyCommand records that the dashboard of a snapshot is stored
+// in the snapshot storage, removing it from the database.
+type SetSnapshotBlobKeyCommand struct {
+	ID      int64
+	BlobKey string
+}
+
+type MoveSnapshotsToStorageCommand struct {
+	MovedSnapshots int64
+}
+
 type GetDashboardSnapshotQuery struct {
 	Key       string
 	DeleteKey string
@@ -11,4 +11,6 @@ type Service interface {
 	DeleteExpiredSnapshots(context.Context, *DeleteExpiredSnapshotsCommand) error
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) (*DashboardSnapshot, error)
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error)
+	MoveSnapshotsToStorage(context.Context, *MoveSnapshotsToStorageCommand) error
+	DeleteOwnedSnapshots(context.Context, *DeleteOwnedSnapshotsCommand) error
 }
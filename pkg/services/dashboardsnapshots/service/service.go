@@ -2,43 +2,85 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	"github.com/grafana/grafana/pkg/services/secrets"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+const (
+	// snapshots are deleted and moved in batches, moved ones holding their dashboard
+	expiredSnapshotsBatchSize = 100
+	ownedSnapshotsBatchSize   = 100
+	moveSnapshotsBatchSize    = 20
 )
 
 type ServiceImpl struct {
 	store          dashboardsnapshots.Store
 	secretsService secrets.Service
+	cfg            *setting.Cfg
+	log            log.Logger
+	// storage holds the dashboards of the snapshots when an object storage is
+	// configured. They are stored in the database otherwise.
+	storage snapshotStorage
 }
 
 // ServiceImpl implements the dashboardsnapshots Service interface
 var _ dashboardsnapshots.Service = (*ServiceImpl)(nil)
 
-func ProvideService(store dashboardsnapshots.Store, secretsService secrets.Service) *ServiceImpl {
+func ProvideService(store dashboardsnapshots.Store, secretsService secrets.Service, cfg *setting.Cfg) (*ServiceImpl, error) {
 	s := &ServiceImpl{
 		store:          store,
 		secretsService: secretsService,
+		cfg:            cfg,
+		log:            log.New("dashboardsnapshots"),
+	}
+
+	if cfg.SnapshotStorageURL != "" {
+		storage, err := openSnapshotStorage(s.log, cfg.SnapshotStorageURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open snapshot storage: %w", err)
+		}
+		s.storage = storage
 	}
 
-	return s
+	return s, nil
 }
 
 func (s *ServiceImpl) CreateDashboardSnapshot(ctx context.Context, cmd *dashboardsnapshots.CreateDashboardSnapshotCommand) (*dashboardsnapshots.DashboardSnapshot, error) {
-	marshalledData, err := cmd.Dashboard.Encode()
-	if err != nil {
-		return nil, err
+	if s.storage == nil {
+		marshalledData, err := cmd.Dashboard.Encode()
+		if err != nil {
+			return nil, err
+		}
+
+		encryptedDashboard, err := s.secretsService.Encrypt(ctx, marshalledData, secrets.WithoutScope())
+		if err != nil {
+			return nil, err
+		}
+
+		cmd.DashboardEncrypted = encryptedDashboard
+		return s.store.CreateDashboardSnapshot(ctx, cmd)
+	}
+
+	cmd.BlobKey = util.GenerateShortUID()
+	if err := s.writeDashboardBlob(ctx, cmd.BlobKey, cmd.Dashboard); err != nil {
+		return nil, fmt.Errorf("failed to store snapshot: %w", err)
 	}
 
-	encryptedDashboard, err := s.secretsService.Encrypt(ctx, marshalledData, secrets.WithoutScope())
+	result, err := s.store.CreateDashboardSnapshot(ctx, cmd)
 	if err != nil {
+		if err := s.deleteBlob(ctx, &dashboardsnapshots.DashboardSnapshot{BlobKey: cmd.BlobKey}); err != nil {
+			s.log.Warn("Failed to delete dashboard of snapshot not created", "blobKey", cmd.BlobKey, "error", err)
+		}
 		return nil, err
 	}
-
-	cmd.DashboardEncrypted = encryptedDashboard
-
-	return s.store.CreateDashboardSnapshot(ctx, cmd)
+	return result, nil
 }
 
 func (s *ServiceImpl) GetDashboardSnapshot(ctx context.Context, query *dashboardsnapshots.GetDashboardSnapshotQuery) (*dashboardsnapshots.DashboardSnapshot, error) {
@@ -47,6 +89,20 @@ func (s *ServiceImpl) GetDashboardSnapshot(ctx context.Context, query *dashboard
 		return nil, err
 	}
 
+	if queryResult.BlobKey != "" {
+		dashboard, err := s.readDashboardBlob(ctx, queryResult.BlobKey)
+		// the snapshot is returned without its dashboard, so that it can still be deleted
+		if errors.Is(err, dashboardsnapshots.ErrBaseNotFound) {
+			s.log.Warn("Dashboard of snapshot not found in snapshot storage", "key", queryResult.Key, "blobKey", queryResult.BlobKey)
+			return queryResult, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		queryResult.Dashboard = dashboard
+		return queryResult, nil
+	}
+
 	if queryResult.DashboardEncrypted != nil {
 		decryptedDashboard, err := s.secretsService.Decrypt(ctx, queryResult.DashboardEncrypted)
 		if err != nil {
@@ -64,7 +120,17 @@ func (s *ServiceImpl) GetDashboardSnapshot(ctx context.Context, query *dashboard
 	return queryResult, err
 }
 
+// DeleteDashboardSnapshot deletes the dashboard of the snapshot from the
+// snapshot storage before deleting the snapshot, so that a failure can be
+// retried.
 func (s *ServiceImpl) DeleteDashboardSnapshot(ctx context.Context, cmd *dashboardsnapshots.DeleteDashboardSnapshotCommand) error {
+	snapshot, err := s.store.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{DeleteKey: cmd.DeleteKey})
+	if err == nil {
+		if err := s.deleteBlob(ctx, snapshot); err != nil {
+			return err
+		}
+	}
+
 	return s.store.DeleteDashboardSnapshot(ctx, cmd)
 }
 
@@ -72,6 +138,139 @@ func (s *ServiceImpl) SearchDashboardSnapshots(ctx context.Context, query *dashb
 	return s.store.SearchDashboardSnapshots(ctx, query)
 }
 
+// DeleteExpiredSnapshots deletes the expired snapshots in batches, deleting
+// their dashboards from the snapshot storage first. Snapshots whose dashboard
+// could not be deleted are kept until the next cleanup.
 func (s *ServiceImpl) DeleteExpiredSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteExpiredSnapshotsCommand) error {
-	return s.store.DeleteExpiredSnapshots(ctx, cmd)
+	if !s.cfg.SnapShotRemoveExpired {
+		s.log.Warn("[Deprecated] The snapshot_remove_expired setting is outdated. Please remove from your config.")
+		return nil
+	}
+
+	for {
+		expired, err := s.store.GetExpiredSnapshots(ctx, &dashboardsnapshots.GetExpiredSnapshotsQuery{Limit: expiredSnapshotsBatchSize})
+		if err != nil {
+			return err
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, 0, len(expired))
+		var blobErr error
+		for _, snapshot := range expired {
+			if err := s.deleteBlob(ctx, snapshot); err != nil {
+				s.log.Warn("Failed to delete dashboard of expired snapshot", "key", snapshot.Key, "error", err)
+				blobErr = err
+				continue
+			}
+			ids = append(ids, snapshot.ID)
+		}
+
+		if len(ids) > 0 {
+			deleteCmd := dashboardsnapshots.DeleteExpiredSnapshotsCommand{IDs: ids}
+			if err := s.store.DeleteExpiredSnapshots(ctx, &deleteCmd); err != nil {
+				return err
+			}
+			cmd.DeletedRows += deleteCmd.DeletedRows
+		}
+
+		// the snapshots kept would be returned again by the next batch
+		if blobErr != nil {
+			return fmt.Errorf("failed to delete the dashboard of expired snapshots: %w", blobErr)
+		}
+		if len(expired) < expiredSnapshotsBatchSize {
+			return nil
+		}
+	}
+}
+
+// DeleteOwnedSnapshots deletes the snapshots of an organization or of a user in
+// batches, deleting their dashboards from the snapshot storage first.
+func (s *ServiceImpl) DeleteOwnedSnapshots(ctx context.Context, cmd *dashboardsnapshots.DeleteOwnedSnapshotsCommand) error {
+	if cmd.OrgID == 0 && cmd.UserID == 0 {
+		return errors.New("the organization or the user of the snapshots to delete is required")
+	}
+
+	for {
+		owned, err := s.store.GetOwnedSnapshots(ctx, &dashboardsnapshots.GetOwnedSnapshotsQuery{OrgID: cmd.OrgID, UserID: cmd.UserID, Limit: ownedSnapshotsBatchSize})
+		if err != nil {
+			return err
+		}
+		if len(owned) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, 0, len(owned))
+		for _, snapshot := range owned {
+			if err := s.deleteBlob(ctx, snapshot); err != nil {
+				return fmt.Errorf("failed to delete the dashboard of snapshot %d: %w", snapshot.ID, err)
+			}
+			ids = append(ids, snapshot.ID)
+		}
+
+		deleteCmd := dashboardsnapshots.DeleteSnapshotsCommand{IDs: ids}
+		if err := s.store.DeleteSnapshots(ctx, &deleteCmd); err != nil {
+			return err
+		}
+		cmd.DeletedRows += deleteCmd.DeletedRows
+
+		if len(owned) < ownedSnapshotsBatchSize {
+			return nil
+		}
+	}
+}
+
+// MoveSnapshotsToStorage moves the dashboards of the snapshots stored in the
+// database to the snapshot storage. It can be interrupted and run again.
+func (s *ServiceImpl) MoveSnapshotsToStorage(ctx context.Context, cmd *dashboardsnapshots.MoveSnapshotsToStorageCommand) error {
+	if s.storage == nil {
+		return fmt.Errorf("%w, set storage_url in the [snapshots] section", errStorageNotConfigured)
+	}
+
+	var afterID int64
+	for {
+		snapshots, err := s.store.GetSnapshotsStoredInDB(ctx, &dashboardsnapshots.GetSnapshotsStoredInDBQuery{AfterID: afterID, Limit: moveSnapshotsBatchSize})
+		if err != nil {
+			return err
+		}
+
+		for _, snapshot := range snapshots {
+			afterID = snapshot.ID
+			if err := s.moveSnapshotToStorage(ctx, snapshot); err != nil {
+				return fmt.Errorf("failed to move snapshot %d: %w", snapshot.ID, err)
+			}
+			cmd.MovedSnapshots++
+		}
+
+		if len(snapshots) < moveSnapshotsBatchSize {
+			return nil
+		}
+	}
+}
+
+func (s *ServiceImpl) moveSnapshotToStorage(ctx context.Context, snapshot *dashboardsnapshots.DashboardSnapshot) error {
+	blobKey := util.GenerateShortUID()
+	if snapshot.DashboardEncrypted != nil {
+		if err := s.writeEncryptedBlob(ctx, blobKey, snapshot.DashboardEncrypted); err != nil {
+			return err
+		}
+	} else {
+		// snapshots created before the dashboards were encrypted
+		dashboard := snapshot.Dashboard
+		if dashboard == nil {
+			dashboard = simplejson.New()
+		}
+		if err := s.writeDashboardBlob(ctx, blobKey, dashboard); err != nil {
+			return err
+		}
+	}
+
+	if err := s.store.SetSnapshotBlobKey(ctx, &dashboardsnapshots.SetSnapshotBlobKeyCommand{ID: snapshot.ID, BlobKey: blobKey}); err != nil {
+		if err := s.deleteBlob(ctx, &dashboardsnapshots.DashboardSnapshot{BlobKey: blobKey}); err != nil {
+			s.log.Warn("Failed to delete dashboard of snapshot not moved", "blobKey", blobKey, "error", err)
+		}
+		return err
+	}
+	return nil
 }
@@ -2,12 +2,15 @@ package service
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
 	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
 	dashsnapdb "github.com/grafana/grafana/pkg/services/dashboardsnapshots/database"
 	"github.com/grafana/grafana/pkg/services/secrets/database"
@@ -19,7 +22,8 @@ func TestDashboardSnapshotsService(t *testing.T) {
 	sqlStore := db.InitTestDB(t)
 	dsStore := dashsnapdb.ProvideStore(sqlStore, setting.NewCfg())
 	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
-	s := ProvideService(dsStore, secretsService)
+	s, err := ProvideService(dsStore, secretsService, setting.NewCfg())
+	require.NoError(t, err)
 
 	origSecret := setting.SecretKey
 	setting.SecretKey = "dashboard_snapshot_service_test"
@@ -68,3 +72,134 @@ func TestDashboardSnapshotsService(t *testing.T) {
 		require.Equal(t, rawDashboard, decrypted)
 	})
 }
+
+func TestDashboardSnapshotsServiceWithStorage(t *testing.T) {
+	sqlStore := db.InitTestDB(t)
+	cfg := setting.NewCfg()
+	cfg.SnapShotRemoveExpired = true
+	dsStore := dashsnapdb.ProvideStore(sqlStore, cfg)
+	secretsService := secretsManager.SetupTestService(t, database.ProvideSecretsStore(sqlStore))
+	ctx := context.Background()
+
+	rawDashboard := []byte(`{"id":123}`)
+	dashboard, err := simplejson.NewJson(rawDashboard)
+	require.NoError(t, err)
+
+	// a snapshot created before the storage was configured
+	dbService, err := ProvideService(dsStore, secretsService, cfg)
+	require.NoError(t, err)
+	_, err = dbService.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{Key: "db", DeleteKey: "deletedb", Dashboard: dashboard})
+	require.NoError(t, err)
+
+	cfg.SnapshotStorageURL = "mem://"
+	s, err := ProvideService(dsStore, secretsService, cfg)
+	require.NoError(t, err)
+
+	blobExists := func(t *testing.T, key string) bool {
+		t.Helper()
+		_, found, err := s.storage.Get(ctx, blobPath(key), &filestorage.GetFileOptions{WithContents: false})
+		require.NoError(t, err)
+		return found
+	}
+
+	t.Run("create dashboard snapshot should store the encrypted dashboard in the storage", func(t *testing.T) {
+		result, err := s.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{Key: "blob", DeleteKey: "deleteblob", Dashboard: dashboard})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.BlobKey)
+		require.Empty(t, result.DashboardEncrypted)
+
+		file, found, err := s.storage.Get(ctx, blobPath(result.BlobKey), &filestorage.GetFileOptions{WithContents: true})
+		require.NoError(t, err)
+		require.True(t, found)
+		require.NotContains(t, string(file.Contents), string(rawDashboard))
+
+		queryResult, err := s.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "blob"})
+		require.NoError(t, err)
+		got, err := queryResult.Dashboard.Encode()
+		require.NoError(t, err)
+		require.Equal(t, rawDashboard, got)
+	})
+
+	t.Run("dashboards larger than a chunk should be stored in several encrypted chunks", func(t *testing.T) {
+		large := simplejson.New()
+		large.Set("description", strings.Repeat("a", 2*blobChunkSize+1))
+		result, err := s.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{Key: "large", DeleteKey: "deletelarge", Dashboard: large})
+		require.NoError(t, err)
+
+		queryResult, err := s.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "large"})
+		require.NoError(t, err)
+		require.Equal(t, large.Get("description").MustString(), queryResult.Dashboard.Get("description").MustString())
+
+		require.NoError(t, s.DeleteDashboardSnapshot(ctx, &dashboardsnapshots.DeleteDashboardSnapshotCommand{DeleteKey: "deletelarge"}))
+		require.False(t, blobExists(t, result.BlobKey))
+	})
+
+	t.Run("delete dashboard snapshot should delete the dashboard from the storage", func(t *testing.T) {
+		snapshot, err := s.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "blob"})
+		require.NoError(t, err)
+		require.True(t, blobExists(t, snapshot.BlobKey))
+
+		err = s.DeleteDashboardSnapshot(ctx, &dashboardsnapshots.DeleteDashboardSnapshotCommand{DeleteKey: "deleteblob"})
+		require.NoError(t, err)
+		require.False(t, blobExists(t, snapshot.BlobKey))
+
+		_, err = s.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "blob"})
+		require.ErrorIs(t, err, dashboardsnapshots.ErrBaseNotFound)
+	})
+
+	t.Run("move snapshots to storage should move the dashboards stored in the database", func(t *testing.T) {
+		cmd := dashboardsnapshots.MoveSnapshotsToStorageCommand{}
+		err := s.MoveSnapshotsToStorage(ctx, &cmd)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), cmd.MovedSnapshots)
+
+		queryResult, err := s.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "db"})
+		require.NoError(t, err)
+		require.NotEmpty(t, queryResult.BlobKey)
+		got, err := queryResult.Dashboard.Encode()
+		require.NoError(t, err)
+		require.Equal(t, rawDashboard, got)
+
+		cmd = dashboardsnapshots.MoveSnapshotsToStorageCommand{}
+		require.NoError(t, s.MoveSnapshotsToStorage(ctx, &cmd))
+		require.Zero(t, cmd.MovedSnapshots)
+	})
+
+	t.Run("delete expired snapshots should delete their dashboards from the storage", func(t *testing.T) {
+		snapshot, err := s.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "db"})
+		require.NoError(t, err)
+		err = sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+			_, err := sess.Exec("UPDATE dashboard_snapshot SET expires = ? WHERE id = ?", time.Now().Add(-time.Hour), snapshot.ID)
+			return err
+		})
+		require.NoError(t, err)
+
+		cmd := dashboardsnapshots.DeleteExpiredSnapshotsCommand{}
+		err = s.DeleteExpiredSnapshots(ctx, &cmd)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), cmd.DeletedRows)
+		require.False(t, blobExists(t, snapshot.BlobKey))
+	})
+
+	t.Run("delete owned snapshots should delete the snapshots of the user or organization with their dashboards", func(t *testing.T) {
+		ofUser, err := s.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{Key: "user", DeleteKey: "deleteuser", Dashboard: dashboard, OrgID: 1, UserID: 10})
+		require.NoError(t, err)
+		ofOrg, err := s.CreateDashboardSnapshot(ctx, &dashboardsnapshots.CreateDashboardSnapshotCommand{Key: "org", DeleteKey: "deleteorg", Dashboard: dashboard, OrgID: 2, UserID: 20})
+		require.NoError(t, err)
+
+		cmd := dashboardsnapshots.DeleteOwnedSnapshotsCommand{UserID: 10}
+		require.NoError(t, s.DeleteOwnedSnapshots(ctx, &cmd))
+		require.Equal(t, int64(1), cmd.DeletedRows)
+		require.False(t, blobExists(t, ofUser.BlobKey))
+		require.True(t, blobExists(t, ofOrg.BlobKey))
+
+		cmd = dashboardsnapshots.DeleteOwnedSnapshotsCommand{OrgID: 2}
+		require.NoError(t, s.DeleteOwnedSnapshots(ctx, &cmd))
+		require.Equal(t, int64(1), cmd.DeletedRows)
+		require.False(t, blobExists(t, ofOrg.BlobKey))
+		_, err = s.GetDashboardSnapshot(ctx, &dashboardsnapshots.GetDashboardSnapshotQuery{Key: "org"})
+		require.ErrorIs(t, err, dashboardsnapshots.ErrBaseNotFound)
+
+		require.Error(t, s.DeleteOwnedSnapshots(ctx, &dashboardsnapshots.DeleteOwnedSnapshotsCommand{}))
+	})
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+
+	"gocloud.dev/blob"
+	_ "gocloud.dev/blob/azureblob"
+	_ "gocloud.dev/blob/gcsblob"
+	_ "gocloud.dev/blob/s3blob"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/filestorage"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboardsnapshots"
+	"github.com/grafana/grafana/pkg/services/secrets"
+)
+
+const (
+	snapshotsFolder = "/snapshots"
+	// the dashboards are encrypted in chunks of this size, so that the
+	// encrypted dashboard is never held in memory as a whole
+	blobChunkSize = 1 << 20
+)
+
+var errStorageNotConfigured = errors.New("snapshot storage is not configured")
+
+// snapshotStorage stores the encrypted dashboards of snapshots outside the
+// database, keyed by the blob key of the snapshot. A blob is a sequence of
+// encrypted chunks of the dashboard JSON, each prefixed by its size.
+type snapshotStorage interface {
+	filestorage.FileStorage
+	filestorage.StreamingFileStorage
+}
+
+func openSnapshotStorage(logger log.Logger, storageURL string) (snapshotStorage, error) {
+	bucket, err := blob.OpenBucket(context.Background(), storageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	storage, ok := filestorage.NewCdkBlobStorage(logger, bucket, "", nil).(snapshotStorage)
+	if !ok {
+		return nil, errors.New("snapshot storage does not support streaming")
+	}
+	return storage, nil
+}
+
+func blobPath(key string) string {
+	return filestorage.Join(snapshotsFolder, key)
+}
+
+// writeDashboardBlob encrypts the dashboard of a snapshot and streams it to the
+// snapshot storage.
+func (s *ServiceImpl) writeDashboardBlob(ctx context.Context, key string, dashboard *simplejson.Json) error {
+	return s.writeBlob(ctx, key, func(w io.Writer) error {
+		ew := &encryptingWriter{ctx: ctx, secretsService: s.secretsService, w: w}
+		if err := json.NewEncoder(ew).Encode(dashboard); err != nil {
+			return err
+		}
+		return ew.flush()
+	})
+}
+
+// writeEncryptedBlob writes a dashboard encrypted at once, as stored in the
+// database, to the snapshot storage as a single chunk.
+func (s *ServiceImpl) writeEncryptedBlob(ctx context.Context, key string, encryptedDashboard []byte) error {
+	return s.writeBlob(ctx, key, func(w io.Writer) error {
+		return writeChunk(w, encryptedDashboard)
+	})
+}
+
+// writeBlob stores the contents written by write, nothing is stored when
+// write fails.
+func (s *ServiceImpl) writeBlob(ctx context.Context, key string, write func(w io.Writer) error) error {
+	if s.storage == nil {
+		return errStorageNotConfigured
+	}
+
+	// the blob is discarded when its writer is closed after ctx is canceled
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w, err := s.storage.OpenWriter(ctx, blobPath(key), "application/octet-stream")
+	if err != nil {
+		return err
+	}
+	if err := write(w); err != nil {
+		cancel()
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// readDashboardBlob streams the dashboard of a snapshot from the snapshot
+// storage and decrypts it.
+func (s *ServiceImpl) readDashboardBlob(ctx context.Context, key string) (*simplejson.Json, error) {
+	if s.storage == nil {
+		return nil, errStorageNotConfigured
+	}
+
+	r, found, err := s.storage.OpenReader(ctx, blobPath(key))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, dashboardsnapshots.ErrBaseNotFound.Errorf("dashboard of snapshot not found in snapshot storage")
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			s.log.Warn("Failed to close snapshot reader", "key", key, "error", err)
+		}
+	}()
+
+	return simplejson.NewFromReader(&decryptingReader{ctx: ctx, secretsService: s.secretsService, r: r})
+}
+
+// deleteBlob deletes the dashboard of a snapshot from the snapshot storage. It
+// does nothing for snapshots stored in the database or already deleted.
+func (s *ServiceImpl) deleteBlob(ctx context.Context, snapshot *dashboardsnapshots.DashboardSnapshot) error {
+	if snapshot.BlobKey == "" {
+		return nil
+	}
+	if s.storage == nil {
+		return errStorageNotConfigured
+	}
+	return s.storage.Delete(ctx, blobPath(snapshot.BlobKey))
+}
+
+// encryptingWriter encrypts the contents written in chunks of blobChunkSize.
+// The last chunk is written by flush.
+type encryptingWriter struct {
+	ctx            context.Context
+	secretsService secrets.Service
+	w              io.Writer
+	buf            []byte
+}
+
+func (e *encryptingWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(blobChunkSize-len(e.buf), len(p))
+		e.buf = append(e.buf, p[:n]...)
+		p = p[n:]
+		written += n
+		if len(e.buf) == blobChunkSize {
+			if err := e.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (e *encryptingWriter) flush() error {
+	if len(e.buf) == 0 {
+		return nil
+	}
+	encrypted, err := e.secretsService.Encrypt(e.ctx, e.buf, secrets.WithoutScope())
+	if err != nil {
+		return err
+	}
+	e.buf = e.buf[:0]
+	return writeChunk(e.w, encrypted)
+}
+
+func writeChunk(w io.Writer, chunk []byte) error {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(chunk)))
+	if _, err := w.Write(size[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(chunk)
+	return err
+}
+
+// decryptingReader decrypts the chunks written by encryptingWriter one at a
+// time.
+type decryptingReader struct {
+	ctx            context.Context
+	secretsService secrets.Service
+	r              io.Reader
+	buf            []byte
+}
+
+func (d *decryptingReader) Read(p []byte) (int, error) {
+	for len(d.buf) == 0 {
+		var size [4]byte
+		if _, err := io.ReadFull(d.r, size[:]); err != nil {
+			return 0, err
+		}
+		encrypted := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(d.r, encrypted); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		decrypted, err := d.secretsService.Decrypt(d.ctx, encrypted)
+		if err != nil {
+			return 0, err
+		}
+		d.buf = decrypted
+	}
+	n := copy(p, d.buf)
+	d.buf = d.buf[n:]
+	return n, nil
+}
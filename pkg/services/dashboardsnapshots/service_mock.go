@@ -64,6 +64,20 @@ func (_m *MockService) DeleteExpiredSnapshots(_a0 context.Context, _a1 *DeleteEx
 	return r0
 }
 
+// DeleteOwnedSnapshots provides a mock function with given fields: _a0, _a1
+func (_m *MockService) DeleteOwnedSnapshots(_a0 context.Context, _a1 *DeleteOwnedSnapshotsCommand) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *DeleteOwnedSnapshotsCommand) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetDashboardSnapshot provides a mock function with given fields: _a0, _a1
 func (_m *MockService) GetDashboardSnapshot(_a0 context.Context, _a1 *GetDashboardSnapshotQuery) (*DashboardSnapshot, error) {
 	ret := _m.Called(_a0, _a1)
@@ -87,6 +101,20 @@ func (_m *MockService) GetDashboardSnapshot(_a0 context.Context, _a1 *GetDashboa
 	return r0, r1
 }
 
+// MoveSnapshotsToStorage provides a mock function with given fields: _a0, _a1
+func (_m *MockService) MoveSnapshotsToStorage(_a0 context.Context, _a1 *MoveSnapshotsToStorageCommand) error {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *MoveSnapshotsToStorageCommand) error); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SearchDashboardSnapshots provides a mock function with given fields: _a0, _a1
 func (_m *MockService) SearchDashboardSnapshots(_a0 context.Context, _a1 *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error) {
 	ret := _m.Called(_a0, _a1)
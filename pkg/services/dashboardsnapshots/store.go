@@ -10,4 +10,9 @@ type Store interface {
 	DeleteExpiredSnapshots(context.Context, *DeleteExpiredSnapshotsCommand) error
 	GetDashboardSnapshot(context.Context, *GetDashboardSnapshotQuery) (*DashboardSnapshot, error)
 	SearchDashboardSnapshots(context.Context, *GetDashboardSnapshotsQuery) (DashboardSnapshotsList, error)
+	GetExpiredSnapshots(context.Context, *GetExpiredSnapshotsQuery) ([]*DashboardSnapshot, error)
+	GetSnapshotsStoredInDB(context.Context, *GetSnapshotsStoredInDBQuery) ([]*DashboardSnapshot, error)
+	SetSnapshotBlobKey(context.Context, *SetSnapshotBlobKeyCommand) error
+	GetOwnedSnapshots(context.Context, *GetOwnedSnapshotsQuery) ([]*DashboardSnapshot, error)
+	DeleteSnapshots(context.Context, *DeleteSnapshotsCommand) error
 }
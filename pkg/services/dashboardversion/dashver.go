@@ -8,4 +8,8 @@ type Service interface {
 	Get(context.Context, *GetDashboardVersionQuery) (*DashboardVersionDTO, error)
 	DeleteExpired(context.Context, *DeleteExpiredVersionsCommand) error
 	List(context.Context, *ListDashboardVersionsQuery) ([]*DashboardVersionDTO, error)
+	Pin(context.Context, *PinDashboardVersionCommand) error
+	GetRetentionPolicy(context.Context, *GetRetentionPolicyQuery) (*RetentionPolicy, error)
+	SetRetentionPolicy(context.Context, *SetRetentionPolicyCommand) error
+	DeleteRetentionPolicy(context.Context, *DeleteRetentionPolicyCommand) error
 }
@@ -3,6 +3,7 @@ package dashverimpl
 import (
 	"context"
 	"errors"
+	"sort"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -14,6 +15,9 @@ import (
 const (
 	maxVersionsToDeletePerBatch = 100
 	maxVersionDeletionBatches   = 50
+	// maxIDsPerQuery is the max number of dashboard IDs or folder UIDs that
+	// are query parameters, under the limits of SQLite (999) and MSSQL (2100)
+	maxIDsPerQuery = 500
 )
 
 type Service struct {
@@ -62,14 +66,72 @@ func (s *Service) Get(ctx context.Context, query *dashver.GetDashboardVersionQue
 	return version.ToDTO(query.DashboardUID), nil
 }
 
+// DeleteExpired deletes the old versions of the dashboards, keeping the number
+// of versions set by the retention policy of their folder, inherited from the
+// parent folders, or by the versions_to_keep setting. Pinned versions are
+// never deleted.
 func (s *Service) DeleteExpired(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand) error {
 	versionsToKeep := setting.DashboardVersionsToKeep
 	if versionsToKeep < 1 {
 		versionsToKeep = 1
 	}
 
+	policies, err := s.store.ListRetentionPolicies(ctx, &dashver.ListRetentionPoliciesQuery{})
+	if err != nil {
+		return err
+	}
+
+	// the number of versions to keep of the dashboards with a retention policy
+	withPolicy := map[int64]int{}
+	for orgID, orgPolicies := range policiesByOrg(policies) {
+		dashboardVersionsToKeep, err := s.dashboardVersionsToKeep(ctx, orgID, orgPolicies)
+		if err != nil {
+			return err
+		}
+		for dashboardID, keep := range dashboardVersionsToKeep {
+			withPolicy[dashboardID] = keep
+		}
+	}
+
+	// dashboards are cleaned up in batches of maxIDsPerQuery dashboards
+	var afterID int64
+	for {
+		dashboardIDs, err := s.store.GetVersionedDashboardIDs(ctx, afterID, maxIDsPerQuery)
+		if err != nil {
+			return err
+		}
+		if len(dashboardIDs) == 0 {
+			return nil
+		}
+		afterID = dashboardIDs[len(dashboardIDs)-1]
+
+		// dashboards by the number of versions to keep, all versions are kept when 0
+		dashboardsByVersionsToKeep := map[int][]int64{}
+		for _, dashboardID := range dashboardIDs {
+			keep, ok := withPolicy[dashboardID]
+			if !ok {
+				keep = versionsToKeep
+			}
+			if keep > 0 {
+				dashboardsByVersionsToKeep[keep] = append(dashboardsByVersionsToKeep[keep], dashboardID)
+			}
+		}
+		for _, keep := range sortedKeys(dashboardsByVersionsToKeep) {
+			retention := versionRetention{versionsToKeep: keep, dashboardIDs: dashboardsByVersionsToKeep[keep]}
+			if err := s.deleteExpired(ctx, cmd, retention); err != nil {
+				return err
+			}
+		}
+
+		if len(dashboardIDs) < maxIDsPerQuery {
+			return nil
+		}
+	}
+}
+
+func (s *Service) deleteExpired(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, retention versionRetention) error {
 	for batch := 0; batch < maxVersionDeletionBatches; batch++ {
-		versionIdsToDelete, batchErr := s.store.GetBatch(ctx, cmd, maxVersionsToDeletePerBatch, versionsToKeep)
+		versionIdsToDelete, batchErr := s.store.GetBatch(ctx, cmd, maxVersionsToDeletePerBatch, retention)
 		if batchErr != nil {
 			return batchErr
 		}
@@ -92,6 +154,78 @@ func (s *Service) DeleteExpired(ctx context.Context, cmd *dashver.DeleteExpiredV
 	return nil
 }
 
+// dashboardVersionsToKeep returns the number of versions to keep of the
+// dashboards of an organization whose folder, or one of its parents, has a
+// retention policy.
+func (s *Service) dashboardVersionsToKeep(ctx context.Context, orgID int64, policies []*dashver.RetentionPolicy) (map[int64]int, error) {
+	parents, err := s.store.GetFolderParents(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	folderPolicies := make(map[string]int, len(policies))
+	for _, p := range policies {
+		folderPolicies[p.FolderUID] = p.VersionsToKeep
+	}
+
+	// the policy of a folder is the one of its closest parent with a policy
+	folderVersionsToKeep := map[string]int{}
+	folderUIDs := []string{}
+	for folderUID := range folderPolicies {
+		folderUIDs = append(folderUIDs, folderUID)
+	}
+	for folderUID := range parents {
+		if _, ok := folderPolicies[folderUID]; !ok {
+			folderUIDs = append(folderUIDs, folderUID)
+		}
+	}
+	for _, folderUID := range folderUIDs {
+		visited := map[string]bool{}
+		for uid := folderUID; uid != "" && !visited[uid]; uid = parents[uid] {
+			visited[uid] = true
+			if keep, ok := folderPolicies[uid]; ok {
+				folderVersionsToKeep[folderUID] = keep
+				break
+			}
+		}
+	}
+
+	withPolicy := make([]string, 0, len(folderVersionsToKeep))
+	for folderUID := range folderVersionsToKeep {
+		withPolicy = append(withPolicy, folderUID)
+	}
+
+	res := map[int64]int{}
+	for start := 0; start < len(withPolicy); start += maxIDsPerQuery {
+		end := min(start+maxIDsPerQuery, len(withPolicy))
+		dashboardFolders, err := s.store.GetDashboardFolders(ctx, orgID, withPolicy[start:end])
+		if err != nil {
+			return nil, err
+		}
+		for dashboardID, folderUID := range dashboardFolders {
+			res[dashboardID] = folderVersionsToKeep[folderUID]
+		}
+	}
+	return res, nil
+}
+
+func sortedKeys(m map[int][]int64) []int {
+	keys := make([]int, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+func policiesByOrg(policies []*dashver.RetentionPolicy) map[int64][]*dashver.RetentionPolicy {
+	res := map[int64][]*dashver.RetentionPolicy{}
+	for _, p := range policies {
+		res[p.OrgID] = append(res[p.OrgID], p)
+	}
+	return res
+}
+
 // List all dashboard versions for the given dashboard ID.
 func (s *Service) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error) {
 	// Get the DashboardUID if not populated
@@ -127,6 +261,34 @@ func (s *Service) List(ctx context.Context, query *dashver.ListDashboardVersions
 	return dtos, nil
 }
 
+// Pin pins or unpins a dashboard version, so that it is not deleted by the
+// cleanup of old versions.
+func (s *Service) Pin(ctx context.Context, cmd *dashver.PinDashboardVersionCommand) error {
+	if cmd.DashboardID == 0 {
+		id, err := s.getDashIDMaybeEmpty(ctx, cmd.DashboardUID)
+		if err != nil {
+			return err
+		}
+		cmd.DashboardID = id
+	}
+	return s.store.Pin(ctx, cmd)
+}
+
+func (s *Service) GetRetentionPolicy(ctx context.Context, query *dashver.GetRetentionPolicyQuery) (*dashver.RetentionPolicy, error) {
+	return s.store.GetRetentionPolicy(ctx, query)
+}
+
+func (s *Service) SetRetentionPolicy(ctx context.Context, cmd *dashver.SetRetentionPolicyCommand) error {
+	if cmd.VersionsToKeep < 0 {
+		return dashver.ErrInvalidVersionsToKeep
+	}
+	return s.store.SetRetentionPolicy(ctx, cmd)
+}
+
+func (s *Service) DeleteRetentionPolicy(ctx context.Context, cmd *dashver.DeleteRetentionPolicyCommand) error {
+	return s.store.DeleteRetentionPolicy(ctx, cmd)
+}
+
 // getDashUIDMaybeEmpty is a helper function which takes a dashboardID and
 // returns the UID. If the dashboard is not found, it will return an empty
 // string.
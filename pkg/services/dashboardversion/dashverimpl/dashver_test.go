@@ -3,6 +3,7 @@ package dashverimpl
 import (
 	"context"
 	"errors"
+	"slices"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
@@ -48,12 +49,47 @@ func TestDeleteExpiredVersions(t *testing.T) {
 	})
 
 	t.Run("Clean up old dashboard versions successfully", func(t *testing.T) {
+		dashboardVersionStore.ExpectedDashboardIDs = []int64{1}
 		dashboardVersionStore.ExptectedDeletedVersions = 4
 		dashboardVersionStore.ExpectedVersions = []any{1, 2, 3, 4}
 		err := dashboardVersionService.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{DeletedRows: 4})
 		require.Nil(t, err)
 	})
 
+	t.Run("Clean up old dashboard versions with folder retention policies", func(t *testing.T) {
+		store := newDashboardVersionStoreFake()
+		store.ExpectedPolicies = []*dashver.RetentionPolicy{
+			{OrgID: 1, FolderUID: "keep-all", VersionsToKeep: 0},
+			{OrgID: 1, FolderUID: "scratch", VersionsToKeep: 2},
+		}
+		store.ExpectedFolderParents = map[string]string{"keep-all": "", "scratch": "", "nested": "scratch", "other": ""}
+		store.ExpectedDashboardFolders = map[int64]string{1: "keep-all", 2: "nested", 3: "other"}
+		store.ExpectedDashboardIDs = []int64{1, 2, 3, 4}
+		service := Service{store: store, dashSvc: dashboardService}
+
+		err := service.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{})
+		require.NoError(t, err)
+		require.Equal(t, []versionRetention{
+			// the nested folder inherits the policy of its parent
+			{versionsToKeep: 2, dashboardIDs: []int64{2}},
+			{versionsToKeep: versionsToKeep, dashboardIDs: []int64{3, 4}},
+		}, store.retentions)
+	})
+
+	t.Run("Clean up old dashboard versions in batches of dashboards", func(t *testing.T) {
+		store := newDashboardVersionStoreFake()
+		for id := int64(1); id <= maxIDsPerQuery+1; id++ {
+			store.ExpectedDashboardIDs = append(store.ExpectedDashboardIDs, id)
+		}
+		service := Service{store: store, dashSvc: dashboardService}
+
+		err := service.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{})
+		require.NoError(t, err)
+		require.Len(t, store.retentions, 2)
+		require.Len(t, store.retentions[0].dashboardIDs, maxIDsPerQuery)
+		require.Equal(t, []int64{maxIDsPerQuery + 1}, store.retentions[1].dashboardIDs)
+	})
+
 	t.Run("Clean up old dashboard versions with error", func(t *testing.T) {
 		dashboardVersionStore.ExpectedError = errors.New("some error")
 		err := dashboardVersionService.DeleteExpired(context.Background(), &dashver.DeleteExpiredVersionsCommand{DeletedRows: 4})
@@ -149,7 +185,13 @@ type FakeDashboardVersionStore struct {
 	ExptectedDeletedVersions int64
 	ExpectedVersions         []any
 	ExpectedListVersions     []*dashver.DashboardVersion
+	ExpectedPolicies         []*dashver.RetentionPolicy
+	ExpectedFolderParents    map[string]string
+	ExpectedDashboardFolders map[int64]string
+	ExpectedDashboardIDs     []int64
 	ExpectedError            error
+
+	retentions []versionRetention
 }
 
 func newDashboardVersionStoreFake() *FakeDashboardVersionStore {
@@ -160,7 +202,8 @@ func (f *FakeDashboardVersionStore) Get(ctx context.Context, query *dashver.GetD
 	return f.ExpectedDashboardVersion, f.ExpectedError
 }
 
-func (f *FakeDashboardVersionStore) GetBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, perBatch int, versionsToKeep int) ([]any, error) {
+func (f *FakeDashboardVersionStore) GetBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, perBatch int, retention versionRetention) ([]any, error) {
+	f.retentions = append(f.retentions, retention)
 	return f.ExpectedVersions, f.ExpectedError
 }
 
@@ -171,3 +214,52 @@ func (f *FakeDashboardVersionStore) DeleteBatch(ctx context.Context, cmd *dashve
 func (f *FakeDashboardVersionStore) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersion, error) {
 	return f.ExpectedListVersions, f.ExpectedError
 }
+
+func (f *FakeDashboardVersionStore) Pin(ctx context.Context, cmd *dashver.PinDashboardVersionCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeDashboardVersionStore) GetRetentionPolicy(ctx context.Context, query *dashver.GetRetentionPolicyQuery) (*dashver.RetentionPolicy, error) {
+	for _, p := range f.ExpectedPolicies {
+		if p.OrgID == query.OrgID && p.FolderUID == query.FolderUID {
+			return p, f.ExpectedError
+		}
+	}
+	return nil, dashver.ErrRetentionPolicyNotFound
+}
+
+func (f *FakeDashboardVersionStore) ListRetentionPolicies(ctx context.Context, query *dashver.ListRetentionPoliciesQuery) ([]*dashver.RetentionPolicy, error) {
+	return f.ExpectedPolicies, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionStore) SetRetentionPolicy(ctx context.Context, cmd *dashver.SetRetentionPolicyCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeDashboardVersionStore) DeleteRetentionPolicy(ctx context.Context, cmd *dashver.DeleteRetentionPolicyCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeDashboardVersionStore) GetFolderParents(ctx context.Context, orgID int64) (map[string]string, error) {
+	return f.ExpectedFolderParents, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionStore) GetDashboardFolders(ctx context.Context, orgID int64, folderUIDs []string) (map[int64]string, error) {
+	res := map[int64]string{}
+	for id, uid := range f.ExpectedDashboardFolders {
+		if slices.Contains(folderUIDs, uid) {
+			res[id] = uid
+		}
+	}
+	return res, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionStore) GetVersionedDashboardIDs(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	var res []int64
+	for _, id := range f.ExpectedDashboardIDs {
+		if id > afterID && len(res) < limit {
+			res = append(res, id)
+		}
+	}
+	return res, f.ExpectedError
+}
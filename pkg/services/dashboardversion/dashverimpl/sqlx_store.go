@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"errors"
 	"strings"
+	"time"
 
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 	"github.com/grafana/grafana/pkg/services/sqlstore/session"
@@ -28,19 +29,17 @@ func (ss *sqlxStore) Get(ctx context.Context, query *dashver.GetDashboardVersion
 	return &version, err
 }
 
-func (ss *sqlxStore) GetBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, perBatch int, versionsToKeep int) ([]any, error) {
-	var versionIds []any
-	versionIdsToDeleteQuery := `SELECT id
-	FROM dashboard_version, (
-		SELECT dashboard_id, count(version) as count, min(version) as min
-		FROM dashboard_version
-		GROUP BY dashboard_id
-	) AS vtd
-	WHERE dashboard_version.dashboard_id=vtd.dashboard_id
-	AND version < vtd.min + vtd.count - ?
-	LIMIT ?`
-	err := ss.sess.Get(ctx, &versionIds, versionIdsToDeleteQuery, versionsToKeep, perBatch)
-	return versionIds, err
+func (ss *sqlxStore) GetBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, perBatch int, retention versionRetention) ([]any, error) {
+	var ids []int64
+	versionIdsToDeleteQuery, args := retention.expiredVersionsSQL(perBatch)
+	if err := ss.sess.Select(ctx, &ids, versionIdsToDeleteQuery, args...); err != nil {
+		return nil, err
+	}
+	versionIds := make([]any, len(ids))
+	for i, id := range ids {
+		versionIds[i] = id
+	}
+	return versionIds, nil
 }
 
 // This service is used by cleanup which need to belong to the same transaction
@@ -68,7 +67,9 @@ func (ss *sqlxStore) List(ctx context.Context, query *dashver.ListDashboardVersi
 				dashboard_version.version,
 				dashboard_version.created,
 				dashboard_version.created_by,
-				dashboard_version.message
+				dashboard_version.message,
+				dashboard_version.pinned,
+				dashboard_version.pin_message
 			FROM dashboard_version
 			LEFT JOIN dashboard ON dashboard.id = dashboard_version.dashboard_id
 			WHERE dashboard_version.dashboard_id=? AND dashboard.org_id=?
@@ -84,3 +85,108 @@ func (ss *sqlxStore) List(ctx context.Context, query *dashver.ListDashboardVersi
 	}
 	return dashboardVersion, nil
 }
+
+func (ss *sqlxStore) Pin(ctx context.Context, cmd *dashver.PinDashboardVersionCommand) error {
+	message := cmd.Message
+	if !cmd.Pinned {
+		message = ""
+	}
+	res, err := ss.sess.Exec(ctx, `UPDATE dashboard_version SET pinned = ?, pin_message = ?
+		WHERE dashboard_id = ? AND version = ? AND dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ?)`,
+		cmd.Pinned, message, cmd.DashboardID, cmd.Version, cmd.OrgID)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return dashver.ErrDashboardVersionNotFound
+	}
+	return nil
+}
+
+func (ss *sqlxStore) GetRetentionPolicy(ctx context.Context, query *dashver.GetRetentionPolicyQuery) (*dashver.RetentionPolicy, error) {
+	var policy dashver.RetentionPolicy
+	err := ss.sess.Get(ctx, &policy, "SELECT * FROM dashboard_version_retention WHERE org_id = ? AND folder_uid = ?", query.OrgID, query.FolderUID)
+	if err != nil && errors.Is(err, sql.ErrNoRows) {
+		return nil, dashver.ErrRetentionPolicyNotFound
+	}
+	return &policy, err
+}
+
+func (ss *sqlxStore) ListRetentionPolicies(ctx context.Context, query *dashver.ListRetentionPoliciesQuery) ([]*dashver.RetentionPolicy, error) {
+	policies := make([]*dashver.RetentionPolicy, 0)
+	var err error
+	if query.OrgID > 0 {
+		err = ss.sess.Select(ctx, &policies, "SELECT * FROM dashboard_version_retention WHERE org_id = ? ORDER BY org_id, folder_uid", query.OrgID)
+	} else {
+		err = ss.sess.Select(ctx, &policies, "SELECT * FROM dashboard_version_retention ORDER BY org_id, folder_uid")
+	}
+	return policies, err
+}
+
+func (ss *sqlxStore) SetRetentionPolicy(ctx context.Context, cmd *dashver.SetRetentionPolicyCommand) error {
+	return ss.sess.WithTransaction(ctx, func(tx *session.SessionTx) error {
+		now := time.Now()
+		res, err := tx.Exec(ctx, "UPDATE dashboard_version_retention SET versions_to_keep = ?, updated = ? WHERE org_id = ? AND folder_uid = ?",
+			cmd.VersionsToKeep, now, cmd.OrgID, cmd.FolderUID)
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil || affected > 0 {
+			return err
+		}
+		_, err = tx.Exec(ctx, "INSERT INTO dashboard_version_retention (org_id, folder_uid, versions_to_keep, created, updated) VALUES (?, ?, ?, ?, ?)",
+			cmd.OrgID, cmd.FolderUID, cmd.VersionsToKeep, now, now)
+		return err
+	})
+}
+
+func (ss *sqlxStore) DeleteRetentionPolicy(ctx context.Context, cmd *dashver.DeleteRetentionPolicyCommand) error {
+	res, err := ss.sess.Exec(ctx, "DELETE FROM dashboard_version_retention WHERE org_id = ? AND folder_uid = ?", cmd.OrgID, cmd.FolderUID)
+	if err != nil {
+		return err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return err
+	} else if affected == 0 {
+		return dashver.ErrRetentionPolicyNotFound
+	}
+	return nil
+}
+
+func (ss *sqlxStore) GetFolderParents(ctx context.Context, orgID int64) (map[string]string, error) {
+	var folders []folderParent
+	err := ss.sess.Select(ctx, &folders, "SELECT uid, COALESCE(parent_uid, '') AS parent_uid FROM folder WHERE org_id = ?", orgID)
+	if err != nil {
+		return nil, err
+	}
+	parents := make(map[string]string, len(folders))
+	for _, f := range folders {
+		parents[f.UID] = f.ParentUID
+	}
+	return parents, nil
+}
+
+func (ss *sqlxStore) GetDashboardFolders(ctx context.Context, orgID int64, folderUIDs []string) (map[int64]string, error) {
+	res := map[int64]string{}
+	if len(folderUIDs) == 0 {
+		return res, nil
+	}
+
+	var dashboards []dashboardFolder
+	rawSQL, args := dashboardFoldersSQL(orgID, folderUIDs)
+	if err := ss.sess.Select(ctx, &dashboards, rawSQL, args...); err != nil {
+		return nil, err
+	}
+	for _, d := range dashboards {
+		res[d.ID] = d.FolderUID
+	}
+	return res, nil
+}
+
+func (ss *sqlxStore) GetVersionedDashboardIDs(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	ids := make([]int64, 0)
+	err := ss.sess.Select(ctx, &ids, versionedDashboardIDsSQL, afterID, limit)
+	return ids, err
+}
@@ -2,13 +2,87 @@ package dashverimpl
 
 import (
 	"context"
+	"strings"
 
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
 )
 
 type store interface {
 	Get(context.Context, *dashver.GetDashboardVersionQuery) (*dashver.DashboardVersion, error)
-	GetBatch(context.Context, *dashver.DeleteExpiredVersionsCommand, int, int) ([]any, error)
+	GetBatch(context.Context, *dashver.DeleteExpiredVersionsCommand, int, versionRetention) ([]any, error)
 	DeleteBatch(context.Context, *dashver.DeleteExpiredVersionsCommand, []any) (int64, error)
 	List(context.Context, *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersion, error)
+	Pin(context.Context, *dashver.PinDashboardVersionCommand) error
+	GetRetentionPolicy(context.Context, *dashver.GetRetentionPolicyQuery) (*dashver.RetentionPolicy, error)
+	ListRetentionPolicies(context.Context, *dashver.ListRetentionPoliciesQuery) ([]*dashver.RetentionPolicy, error)
+	SetRetentionPolicy(context.Context, *dashver.SetRetentionPolicyCommand) error
+	DeleteRetentionPolicy(context.Context, *dashver.DeleteRetentionPolicyCommand) error
+	// GetFolderParents returns the parent of each folder of an organization,
+	// by folder UID. Top level folders have an empty parent.
+	GetFolderParents(ctx context.Context, orgID int64) (map[string]string, error)
+	// GetDashboardFolders returns the folder of each dashboard of the given
+	// folders, by dashboard ID.
+	GetDashboardFolders(ctx context.Context, orgID int64, folderUIDs []string) (map[int64]string, error)
+	// GetVersionedDashboardIDs returns the IDs of at most limit dashboards
+	// with versions whose ID is greater than afterID, in ascending order.
+	GetVersionedDashboardIDs(ctx context.Context, afterID int64, limit int) ([]int64, error)
+}
+
+// versionRetention selects the versions of dashboardIDs deleted by the
+// cleanup: all but the versionsToKeep latest ones of each dashboard. Pinned
+// versions are neither deleted nor counted. There are at most
+// maxIDsPerQuery dashboardIDs, so that the query stays within the limits of
+// parameters of the databases.
+type versionRetention struct {
+	versionsToKeep int
+	dashboardIDs   []int64
+}
+
+// expiredVersionsSQL returns the query selecting the IDs of a batch of expired
+// versions. Versions are assumed to be consecutive, so with pinned versions in
+// between a few more versions than versionsToKeep can be kept.
+func (r versionRetention) expiredVersionsSQL(perBatch int) (string, []any) {
+	args := []any{false}
+	for _, id := range r.dashboardIDs {
+		args = append(args, id)
+	}
+
+	rawSQL := `SELECT id
+	FROM dashboard_version, (
+		SELECT dashboard_id, count(version) as count, min(version) as min
+		FROM dashboard_version
+		WHERE pinned = ? AND dashboard_id IN (?` + strings.Repeat(",?", len(r.dashboardIDs)-1) + `)
+		GROUP BY dashboard_id
+	) AS vtd
+	WHERE dashboard_version.dashboard_id=vtd.dashboard_id
+	AND dashboard_version.pinned = ?
+	AND version < vtd.min + vtd.count - ?
+	LIMIT ?`
+	return rawSQL, append(args, false, r.versionsToKeep, perBatch)
+}
+
+type folderParent struct {
+	UID       string `xorm:"uid" db:"uid"`
+	ParentUID string `xorm:"parent_uid" db:"parent_uid"`
+}
+
+const versionedDashboardIDsSQL = `SELECT DISTINCT dashboard_id FROM dashboard_version
+	WHERE dashboard_id > ?
+	ORDER BY dashboard_id
+	LIMIT ?`
+
+type dashboardFolder struct {
+	ID        int64  `xorm:"id" db:"id"`
+	FolderUID string `xorm:"folder_uid" db:"folder_uid"`
+}
+
+// dashboardFoldersSQL returns the query selecting the dashboards of folderUIDs,
+// of which there are at most maxIDsPerQuery.
+func dashboardFoldersSQL(orgID int64, folderUIDs []string) (string, []any) {
+	args := []any{orgID, false}
+	for _, uid := range folderUIDs {
+		args = append(args, uid)
+	}
+	return `SELECT id, folder_uid FROM dashboard
+		WHERE org_id = ? AND is_folder = ? AND folder_uid IN (?` + strings.Repeat(",?", len(folderUIDs)-1) + `)`, args
 }
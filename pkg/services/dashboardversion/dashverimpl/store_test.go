@@ -2,6 +2,7 @@ package dashverimpl
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"testing"
 	"time"
@@ -111,6 +112,87 @@ func testIntegrationGetDashboardVersion(t *testing.T, fn getStore) {
 		require.Nil(t, err)
 		assert.Equal(t, 2, len(res))
 	})
+
+	t.Run("Pinned versions are not expired", func(t *testing.T) {
+		dash := insertTestDashboard(t, ss, "test dash pinned", 1, 0, "", false, "pinned")
+		for i := 0; i < 3; i++ {
+			updateTestDashboard(t, ss, dash, map[string]any{"tags": "pinned"})
+		}
+
+		err := dashVerStore.Pin(context.Background(), &dashver.PinDashboardVersionCommand{
+			DashboardID: dash.ID, OrgID: 1, Version: 1, Pinned: true, Message: "approved release",
+		})
+		require.NoError(t, err)
+
+		err = dashVerStore.Pin(context.Background(), &dashver.PinDashboardVersionCommand{DashboardID: dash.ID, OrgID: 2, Version: 1, Pinned: true})
+		require.ErrorIs(t, err, dashver.ErrDashboardVersionNotFound)
+
+		versions, err := dashVerStore.List(context.Background(), &dashver.ListDashboardVersionsQuery{DashboardID: dash.ID, OrgID: 1, Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, versions, 4)
+		idsByVersion := map[int]int64{}
+		for _, v := range versions {
+			idsByVersion[v.Version] = v.ID
+			assert.Equal(t, v.Version == 1, v.Pinned)
+		}
+		assert.Equal(t, "approved release", versions[3].PinMessage)
+
+		ids, err := dashVerStore.GetBatch(context.Background(), &dashver.DeleteExpiredVersionsCommand{}, 100,
+			versionRetention{versionsToKeep: 1, dashboardIDs: []int64{dash.ID}})
+		require.NoError(t, err)
+		expired := make([]int64, 0, len(ids))
+		for _, id := range ids {
+			v, err := strconv.ParseInt(fmt.Sprint(id), 10, 64)
+			require.NoError(t, err)
+			expired = append(expired, v)
+		}
+		assert.ElementsMatch(t, []int64{idsByVersion[2], idsByVersion[3]}, expired)
+
+		dashboardIDs, err := dashVerStore.GetVersionedDashboardIDs(context.Background(), dash.ID-1, 1)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{dash.ID}, dashboardIDs)
+		dashboardIDs, err = dashVerStore.GetVersionedDashboardIDs(context.Background(), dash.ID, 1)
+		require.NoError(t, err)
+		assert.Empty(t, dashboardIDs)
+	})
+
+	t.Run("Set, get, list and delete retention policies", func(t *testing.T) {
+		ctx := context.Background()
+		_, err := dashVerStore.GetRetentionPolicy(ctx, &dashver.GetRetentionPolicyQuery{OrgID: 1, FolderUID: "folder"})
+		require.ErrorIs(t, err, dashver.ErrRetentionPolicyNotFound)
+
+		require.NoError(t, dashVerStore.SetRetentionPolicy(ctx, &dashver.SetRetentionPolicyCommand{OrgID: 1, FolderUID: "folder", VersionsToKeep: 3}))
+		require.NoError(t, dashVerStore.SetRetentionPolicy(ctx, &dashver.SetRetentionPolicyCommand{OrgID: 1, FolderUID: "folder", VersionsToKeep: 0}))
+		require.NoError(t, dashVerStore.SetRetentionPolicy(ctx, &dashver.SetRetentionPolicyCommand{OrgID: 2, FolderUID: "folder", VersionsToKeep: 5}))
+
+		policy, err := dashVerStore.GetRetentionPolicy(ctx, &dashver.GetRetentionPolicyQuery{OrgID: 1, FolderUID: "folder"})
+		require.NoError(t, err)
+		assert.Equal(t, 0, policy.VersionsToKeep)
+
+		policies, err := dashVerStore.ListRetentionPolicies(ctx, &dashver.ListRetentionPoliciesQuery{})
+		require.NoError(t, err)
+		assert.Len(t, policies, 2)
+		policies, err = dashVerStore.ListRetentionPolicies(ctx, &dashver.ListRetentionPoliciesQuery{OrgID: 2})
+		require.NoError(t, err)
+		require.Len(t, policies, 1)
+		assert.Equal(t, 5, policies[0].VersionsToKeep)
+
+		require.NoError(t, dashVerStore.DeleteRetentionPolicy(ctx, &dashver.DeleteRetentionPolicyCommand{OrgID: 1, FolderUID: "folder"}))
+		err = dashVerStore.DeleteRetentionPolicy(ctx, &dashver.DeleteRetentionPolicyCommand{OrgID: 1, FolderUID: "folder"})
+		require.ErrorIs(t, err, dashver.ErrRetentionPolicyNotFound)
+	})
+
+	t.Run("Get the dashboards of folders", func(t *testing.T) {
+		inFolder := insertTestDashboard(t, ss, "test dash in folder", 1, 0, "folder-a", false, "folders")
+		insertTestDashboard(t, ss, "test dash in other folder", 1, 0, "folder-b", false, "folders")
+
+		res, err := dashVerStore.GetDashboardFolders(context.Background(), 1, []string{"folder-a"})
+		require.NoError(t, err)
+		assert.Equal(t, map[int64]string{inFolder.ID: "folder-a"}, res)
+
+		_, err = dashVerStore.GetFolderParents(context.Background(), 1)
+		require.NoError(t, err)
+	})
 }
 
 func getDashboard(t *testing.T, sqlStore db.DB, dashboard *dashboards.Dashboard) error {
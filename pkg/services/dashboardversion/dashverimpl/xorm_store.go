@@ -3,6 +3,7 @@ package dashverimpl
 import (
 	"context"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	dashver "github.com/grafana/grafana/pkg/services/dashboardversion"
@@ -36,20 +37,11 @@ func (ss *sqlStore) Get(ctx context.Context, query *dashver.GetDashboardVersionQ
 	return &version, nil
 }
 
-func (ss *sqlStore) GetBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, perBatch int, versionsToKeep int) ([]any, error) {
+func (ss *sqlStore) GetBatch(ctx context.Context, cmd *dashver.DeleteExpiredVersionsCommand, perBatch int, retention versionRetention) ([]any, error) {
 	var versionIds []any
 	err := ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
-		versionIdsToDeleteQuery := `SELECT id
-			FROM dashboard_version, (
-				SELECT dashboard_id, count(version) as count, min(version) as min
-				FROM dashboard_version
-				GROUP BY dashboard_id
-			) AS vtd
-			WHERE dashboard_version.dashboard_id=vtd.dashboard_id
-			AND version < vtd.min + vtd.count - ?
-			LIMIT ?`
-
-		err := sess.SQL(versionIdsToDeleteQuery, versionsToKeep, perBatch).Find(&versionIds)
+		versionIdsToDeleteQuery, args := retention.expiredVersionsSQL(perBatch)
+		err := sess.SQL(versionIdsToDeleteQuery, args...).Find(&versionIds)
 		return err
 	})
 	return versionIds, err
@@ -83,7 +75,9 @@ func (ss *sqlStore) List(ctx context.Context, query *dashver.ListDashboardVersio
 				dashboard_version.created,
 				dashboard_version.created_by,
 				dashboard_version.message,
-				dashboard_version.data`).
+				dashboard_version.data,
+				dashboard_version.pinned,
+				dashboard_version.pin_message`).
 			Join("LEFT", "dashboard", `dashboard.id = dashboard_version.dashboard_id`).
 			Where("dashboard_version.dashboard_id=? AND dashboard.org_id=?", query.DashboardID, query.OrgID).
 			OrderBy("dashboard_version.version DESC").
@@ -103,3 +97,138 @@ func (ss *sqlStore) List(ctx context.Context, query *dashver.ListDashboardVersio
 	}
 	return dashboardVersion, nil
 }
+
+func (ss *sqlStore) Pin(ctx context.Context, cmd *dashver.PinDashboardVersionCommand) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		message := cmd.Message
+		if !cmd.Pinned {
+			message = ""
+		}
+		res, err := sess.Exec(`UPDATE dashboard_version SET pinned = ?, pin_message = ?
+			WHERE dashboard_id = ? AND version = ? AND dashboard_id IN (SELECT id FROM dashboard WHERE org_id = ?)`,
+			cmd.Pinned, message, cmd.DashboardID, cmd.Version, cmd.OrgID)
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return dashver.ErrDashboardVersionNotFound
+		}
+		return nil
+	})
+}
+
+func (ss *sqlStore) GetRetentionPolicy(ctx context.Context, query *dashver.GetRetentionPolicyQuery) (*dashver.RetentionPolicy, error) {
+	var policy dashver.RetentionPolicy
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		has, err := sess.Where("org_id = ? AND folder_uid = ?", query.OrgID, query.FolderUID).Get(&policy)
+		if err != nil {
+			return err
+		}
+		if !has {
+			return dashver.ErrRetentionPolicyNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (ss *sqlStore) ListRetentionPolicies(ctx context.Context, query *dashver.ListRetentionPoliciesQuery) ([]*dashver.RetentionPolicy, error) {
+	policies := make([]*dashver.RetentionPolicy, 0)
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		if query.OrgID > 0 {
+			sess.Where("org_id = ?", query.OrgID)
+		}
+		return sess.Asc("org_id", "folder_uid").Find(&policies)
+	})
+	return policies, err
+}
+
+func (ss *sqlStore) SetRetentionPolicy(ctx context.Context, cmd *dashver.SetRetentionPolicyCommand) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		var existing dashver.RetentionPolicy
+		has, err := sess.Where("org_id = ? AND folder_uid = ?", cmd.OrgID, cmd.FolderUID).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if has {
+			existing.VersionsToKeep = cmd.VersionsToKeep
+			existing.Updated = now
+			_, err = sess.ID(existing.ID).Cols("versions_to_keep", "updated").Update(&existing)
+			return err
+		}
+
+		_, err = sess.Insert(&dashver.RetentionPolicy{
+			OrgID:          cmd.OrgID,
+			FolderUID:      cmd.FolderUID,
+			VersionsToKeep: cmd.VersionsToKeep,
+			Created:        now,
+			Updated:        now,
+		})
+		return err
+	})
+}
+
+func (ss *sqlStore) DeleteRetentionPolicy(ctx context.Context, cmd *dashver.DeleteRetentionPolicyCommand) error {
+	return ss.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		res, err := sess.Exec("DELETE FROM dashboard_version_retention WHERE org_id = ? AND folder_uid = ?", cmd.OrgID, cmd.FolderUID)
+		if err != nil {
+			return err
+		}
+		if affected, err := res.RowsAffected(); err != nil {
+			return err
+		} else if affected == 0 {
+			return dashver.ErrRetentionPolicyNotFound
+		}
+		return nil
+	})
+}
+
+func (ss *sqlStore) GetFolderParents(ctx context.Context, orgID int64) (map[string]string, error) {
+	var folders []folderParent
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL("SELECT uid, COALESCE(parent_uid, '') AS parent_uid FROM folder WHERE org_id = ?", orgID).Find(&folders)
+	})
+	if err != nil {
+		return nil, err
+	}
+	parents := make(map[string]string, len(folders))
+	for _, f := range folders {
+		parents[f.UID] = f.ParentUID
+	}
+	return parents, nil
+}
+
+func (ss *sqlStore) GetDashboardFolders(ctx context.Context, orgID int64, folderUIDs []string) (map[int64]string, error) {
+	res := map[int64]string{}
+	if len(folderUIDs) == 0 {
+		return res, nil
+	}
+
+	var dashboards []dashboardFolder
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		rawSQL, args := dashboardFoldersSQL(orgID, folderUIDs)
+		return sess.SQL(rawSQL, args...).Find(&dashboards)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range dashboards {
+		res[d.ID] = d.FolderUID
+	}
+	return res, nil
+}
+
+func (ss *sqlStore) GetVersionedDashboardIDs(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	ids := make([]int64, 0)
+	err := ss.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.SQL(versionedDashboardIDsSQL, afterID, limit).Find(&ids)
+	})
+	return ids, err
+}
@@ -10,6 +10,7 @@ type FakeDashboardVersionService struct {
 	ExpectedDashboardVersion     *dashver.DashboardVersionDTO
 	ExpectedDashboardVersions    []*dashver.DashboardVersionDTO
 	ExpectedListDashboarVersions []*dashver.DashboardVersionDTO
+	ExpectedRetentionPolicy      *dashver.RetentionPolicy
 	counter                      int
 	ExpectedError                error
 }
@@ -33,3 +34,19 @@ func (f *FakeDashboardVersionService) DeleteExpired(ctx context.Context, cmd *da
 func (f *FakeDashboardVersionService) List(ctx context.Context, query *dashver.ListDashboardVersionsQuery) ([]*dashver.DashboardVersionDTO, error) {
 	return f.ExpectedListDashboarVersions, f.ExpectedError
 }
+
+func (f *FakeDashboardVersionService) Pin(ctx context.Context, cmd *dashver.PinDashboardVersionCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeDashboardVersionService) GetRetentionPolicy(ctx context.Context, query *dashver.GetRetentionPolicyQuery) (*dashver.RetentionPolicy, error) {
+	return f.ExpectedRetentionPolicy, f.ExpectedError
+}
+
+func (f *FakeDashboardVersionService) SetRetentionPolicy(ctx context.Context, cmd *dashver.SetRetentionPolicyCommand) error {
+	return f.ExpectedError
+}
+
+func (f *FakeDashboardVersionService) DeleteRetentionPolicy(ctx context.Context, cmd *dashver.DeleteRetentionPolicyCommand) error {
+	return f.ExpectedError
+}
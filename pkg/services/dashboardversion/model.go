@@ -10,6 +10,8 @@ import (
 var (
 	ErrDashboardVersionNotFound = errors.New("dashboard version not found")
 	ErrNoVersionsForDashboardID = errors.New("no dashboard versions found for the given DashboardId")
+	ErrRetentionPolicyNotFound  = errors.New("dashboard version retention policy not found")
+	ErrInvalidVersionsToKeep    = errors.New("versions to keep cannot be negative")
 )
 
 // DashboardVersion represents a dashboard version in the database. Ideally this
@@ -28,6 +30,10 @@ type DashboardVersion struct {
 
 	Message string           `json:"message" db:"message"`
 	Data    *simplejson.Json `json:"data" db:"data"`
+
+	// Pinned versions are never deleted by the cleanup of old versions.
+	Pinned     bool   `json:"pinned" db:"pinned"`
+	PinMessage string `json:"pinMessage" db:"pin_message"`
 }
 
 // ToDTO converts a DashboardVersion to a DashboardVersionDTO.
@@ -43,6 +49,8 @@ func (v *DashboardVersion) ToDTO(dashUid string) *DashboardVersionDTO {
 		CreatedBy:     v.CreatedBy,
 		Message:       v.Message,
 		Data:          v.Data,
+		Pinned:        v.Pinned,
+		PinMessage:    v.PinMessage,
 	}
 }
 
@@ -59,6 +67,60 @@ type DeleteExpiredVersionsCommand struct {
 	DeletedRows int64
 }
 
+// PinDashboardVersionCommand pins or unpins a dashboard version. Only one of
+// DashboardID and DashboardUID are required.
+type PinDashboardVersionCommand struct {
+	DashboardID  int64
+	DashboardUID string
+	OrgID        int64
+	Version      int
+	Pinned       bool
+	Message      string
+}
+
+// RetentionPolicy sets how many versions are kept for the dashboards of a
+// folder and of its subfolders, unless a subfolder has a policy of its own.
+// Dashboards of folders without a policy keep the number of versions set by
+// the versions_to_keep setting.
+type RetentionPolicy struct {
+	ID        int64  `json:"-" xorm:"pk autoincr 'id'" db:"id"`
+	OrgID     int64  `json:"orgId" xorm:"org_id" db:"org_id"`
+	FolderUID string `json:"folderUid" xorm:"folder_uid" db:"folder_uid"`
+	// VersionsToKeep is the number of versions kept, pinned versions not
+	// included. 0 keeps every version.
+	VersionsToKeep int       `json:"versionsToKeep" xorm:"versions_to_keep" db:"versions_to_keep"`
+	Created        time.Time `json:"created" db:"created"`
+	Updated        time.Time `json:"updated" db:"updated"`
+}
+
+func (p RetentionPolicy) TableName() string {
+	return "dashboard_version_retention"
+}
+
+type SetRetentionPolicyCommand struct {
+	OrgID          int64  `json:"-"`
+	FolderUID      string `json:"-"`
+	VersionsToKeep int    `json:"versionsToKeep"`
+}
+
+type DeleteRetentionPolicyCommand struct {
+	OrgID     int64
+	FolderUID string
+}
+
+// GetRetentionPolicyQuery returns the policy set on a folder, not the one
+// inherited from its parents.
+type GetRetentionPolicyQuery struct {
+	OrgID     int64
+	FolderUID string
+}
+
+// ListRetentionPoliciesQuery lists the retention policies of an
+// organization, or of all organizations when OrgID is 0.
+type ListRetentionPoliciesQuery struct {
+	OrgID int64
+}
+
 type ListDashboardVersionsQuery struct {
 	DashboardID  int64
 	DashboardUID string
@@ -77,6 +139,8 @@ type DashboardVersionDTO struct {
 	CreatedBy     int64            `json:"createdBy"`
 	Message       string           `json:"message"`
 	Data          *simplejson.Json `json:"data" db:"data"`
+	Pinned        bool             `json:"pinned"`
+	PinMessage    string           `json:"pinMessage"`
 }
 
 // DashboardVersionMeta extends the DashboardVersionDTO with the names
@@ -93,4 +157,6 @@ type DashboardVersionMeta struct {
 	Message       string           `json:"message"`
 	Data          *simplejson.Json `json:"data"`
 	CreatedBy     string           `json:"createdBy"`
+	Pinned        bool             `json:"pinned"`
+	PinMessage    string           `json:"pinMessage"`
 }
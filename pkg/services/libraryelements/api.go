@@ -3,9 +3,11 @@ package libraryelements
 import (
 	"errors"
 	"net/http"
+	"strconv"
 
 	"github.com/grafana/grafana/pkg/api/response"
 	"github.com/grafana/grafana/pkg/api/routing"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
 	ac "github.com/grafana/grafana/pkg/services/accesscontrol"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/dashboards"
@@ -27,6 +29,10 @@ func (l *LibraryElementService) registerAPIEndpoints() {
 			entities.Get("/", authorize(ac.EvalPermission(ActionLibraryPanelsRead)), routing.Wrap(l.getAllHandler))
 			entities.Get("/:uid", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getHandler))
 			entities.Get("/:uid/connections/", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getConnectionsHandler))
+			entities.Get("/:uid/affected-dashboards", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getAffectedDashboardsHandler))
+			entities.Get("/:uid/versions", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getVersionsHandler))
+			entities.Get("/:uid/versions/:version", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.getVersionHandler))
+			entities.Get("/:uid/diff", authorize(ac.EvalPermission(ActionLibraryPanelsRead, uidScope)), routing.Wrap(l.diffVersionsHandler))
 			entities.Get("/name/:name", routing.Wrap(l.getByNameHandler))
 			entities.Patch("/:uid", authorize(ac.EvalPermission(ActionLibraryPanelsWrite, uidScope)), routing.Wrap(l.patchHandler))
 		} else {
@@ -35,6 +41,10 @@ func (l *LibraryElementService) registerAPIEndpoints() {
 			entities.Get("/", routing.Wrap(l.getAllHandler))
 			entities.Get("/:uid", routing.Wrap(l.getHandler))
 			entities.Get("/:uid/connections/", routing.Wrap(l.getConnectionsHandler))
+			entities.Get("/:uid/affected-dashboards", routing.Wrap(l.getAffectedDashboardsHandler))
+			entities.Get("/:uid/versions", routing.Wrap(l.getVersionsHandler))
+			entities.Get("/:uid/versions/:version", routing.Wrap(l.getVersionHandler))
+			entities.Get("/:uid/diff", routing.Wrap(l.diffVersionsHandler))
 			entities.Get("/name/:name", routing.Wrap(l.getByNameHandler))
 			entities.Patch("/:uid", routing.Wrap(l.patchHandler))
 		}
@@ -257,6 +267,118 @@ func (l *LibraryElementService) getConnectionsHandler(c *contextmodel.ReqContext
 	return response.JSON(http.StatusOK, model.LibraryElementConnectionsResponse{Result: connections})
 }
 
+// swagger:route GET /library-elements/{library_element_uid}/affected-dashboards library_elements getLibraryElementAffectedDashboards
+//
+// Get the dashboards affected by a change of a library element.
+//
+// Returns the dashboards connected to the library element, to preview which dashboards display the changes before saving them.
+// Dashboards pinned to a version of the library element are not affected.
+//
+// Responses:
+// 200: getLibraryElementAffectedDashboardsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) getAffectedDashboardsHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := l.getAffectedDashboards(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"])
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get affected dashboards")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementAffectedDashboardsResponse{Result: result})
+}
+
+// swagger:route GET /library-elements/{library_element_uid}/versions library_elements getLibraryElementVersions
+//
+// Get library element versions.
+//
+// Returns the versions of a library element, the most recent first.
+// Use the `perPage` query parameter to control the maximum number of versions returned; the default limit is `100`.
+//
+// Responses:
+// 200: getLibraryElementVersionsResponse
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) getVersionsHandler(c *contextmodel.ReqContext) response.Response {
+	result, err := l.getLibraryElementVersions(c.Req.Context(), c.SignedInUser, model.GetLibraryElementVersionsQuery{
+		UID:     web.Params(c.Req)[":uid"],
+		PerPage: c.QueryInt("perPage"),
+		Page:    c.QueryInt("page"),
+	})
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element versions")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementVersionsResponse{Result: result})
+}
+
+// swagger:route GET /library-elements/{library_element_uid}/versions/{version} library_elements getLibraryElementVersion
+//
+// Get library element version.
+//
+// Returns a version of a library element, including its model.
+//
+// Responses:
+// 200: getLibraryElementVersionResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) getVersionHandler(c *contextmodel.ReqContext) response.Response {
+	version, err := strconv.ParseInt(web.Params(c.Req)[":version"], 10, 64)
+	if err != nil {
+		return response.Error(http.StatusBadRequest, "version is invalid", err)
+	}
+
+	result, err := l.getLibraryElementVersion(c.Req.Context(), c.SignedInUser, web.Params(c.Req)[":uid"], version)
+	if err != nil {
+		return toLibraryElementError(err, "Failed to get library element version")
+	}
+
+	return response.JSON(http.StatusOK, model.LibraryElementVersionResponse{Result: result})
+}
+
+// swagger:route GET /library-elements/{library_element_uid}/diff library_elements diffLibraryElementVersions
+//
+// Diff library element versions.
+//
+// Computes the diff between the models of two versions of a library element.
+// The `json` and `basic` diff types return HTML, the `delta` diff type returns JSON.
+//
+// Responses:
+// 200: diffLibraryElementVersionsResponse
+// 400: badRequestError
+// 401: unauthorisedError
+// 403: forbiddenError
+// 404: notFoundError
+// 500: internalServerError
+func (l *LibraryElementService) diffVersionsHandler(c *contextmodel.ReqContext) response.Response {
+	query := model.DiffLibraryElementVersionsQuery{
+		UID:         web.Params(c.Req)[":uid"],
+		BaseVersion: c.QueryInt64("base"),
+		NewVersion:  c.QueryInt64("new"),
+		DiffType:    c.Query("diffType"),
+	}
+	if query.BaseVersion <= 0 || query.NewVersion <= 0 {
+		return response.Error(http.StatusBadRequest, "base and new versions are required", nil)
+	}
+
+	result, err := l.diffLibraryElementVersions(c.Req.Context(), c.SignedInUser, query)
+	if err != nil {
+		return toLibraryElementError(err, "Unable to compute diff")
+	}
+
+	if dashdiffs.ParseDiffType(query.DiffType) == dashdiffs.DiffDelta {
+		return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "application/json")
+	}
+
+	return response.Respond(http.StatusOK, result.Delta).SetHeader("Content-Type", "text/html")
+}
+
 // swagger:route GET /library-elements/name/{library_element_name} library_elements getLibraryElementByName
 //
 // Get library element by name.
@@ -329,10 +451,19 @@ func toLibraryElementError(err error, message string) response.Response {
 	if errors.Is(err, model.ErrLibraryElementUIDTooLong) {
 		return response.Error(400, model.ErrLibraryElementUIDTooLong.Error(), err)
 	}
+	if errors.Is(err, model.ErrLibraryElementVersionNotFound) {
+		return response.Error(404, model.ErrLibraryElementVersionNotFound.Error(), err)
+	}
+	if errors.Is(err, model.ErrLibraryElementVersionsIdentical) {
+		return response.Error(400, model.ErrLibraryElementVersionsIdentical.Error(), err)
+	}
+	if errors.Is(err, dashdiffs.ErrUnsupportedDiffType) {
+		return response.Error(400, dashdiffs.ErrUnsupportedDiffType.Error(), err)
+	}
 	return response.ErrOrFallback(http.StatusInternalServerError, message, err)
 }
 
-// swagger:parameters getLibraryElementByUID getLibraryElementConnections
+// swagger:parameters getLibraryElementByUID getLibraryElementConnections getLibraryElementAffectedDashboards
 type LibraryElementByUID struct {
 	// in:path
 	// required:true
@@ -447,3 +578,74 @@ type GetLibraryElementConnectionsResponse struct {
 	// in: body
 	Body model.LibraryElementConnectionsResponse `json:"body"`
 }
+
+// swagger:parameters getLibraryElementVersions
+type GetLibraryElementVersionsParams struct {
+	// in:path
+	// required:true
+	UID string `json:"library_element_uid"`
+	// The number of results per page.
+	// in:query
+	// required:false
+	// default: 100
+	PerPage int `json:"perPage"`
+	// The page for a set of records, given that only perPage records are returned at a time. Numbering starts at 1.
+	// in:query
+	// required:false
+	// default: 1
+	Page int `json:"page"`
+}
+
+// swagger:parameters getLibraryElementVersion
+type GetLibraryElementVersionParams struct {
+	// in:path
+	// required:true
+	UID string `json:"library_element_uid"`
+	// in:path
+	// required:true
+	Version int64 `json:"version"`
+}
+
+// swagger:parameters diffLibraryElementVersions
+type DiffLibraryElementVersionsParams struct {
+	// in:path
+	// required:true
+	UID string `json:"library_element_uid"`
+	// Version to compare from.
+	// in:query
+	// required:true
+	Base int64 `json:"base"`
+	// Version to compare to.
+	// in:query
+	// required:true
+	New int64 `json:"new"`
+	// in:query
+	// required:false
+	// default: basic
+	// Enum: json,basic,delta
+	DiffType string `json:"diffType"`
+}
+
+// swagger:response getLibraryElementAffectedDashboardsResponse
+type GetLibraryElementAffectedDashboardsResponse struct {
+	// in: body
+	Body model.LibraryElementAffectedDashboardsResponse `json:"body"`
+}
+
+// swagger:response getLibraryElementVersionsResponse
+type GetLibraryElementVersionsResponse struct {
+	// in: body
+	Body model.LibraryElementVersionsResponse `json:"body"`
+}
+
+// swagger:response getLibraryElementVersionResponse
+type GetLibraryElementVersionResponse struct {
+	// in: body
+	Body model.LibraryElementVersionResponse `json:"body"`
+}
+
+// swagger:response diffLibraryElementVersionsResponse
+type DiffLibraryElementVersionsResponse struct {
+	// in: body
+	Body []byte `json:"body"`
+}
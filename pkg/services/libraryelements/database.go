@@ -186,7 +186,7 @@ func (l *LibraryElementService) createLibraryElement(c context.Context, signedIn
 			}
 			return err
 		}
-		return nil
+		return insertLibraryElementVersion(session, &element)
 	})
 
 	dto := model.LibraryElementDTO{
@@ -248,6 +248,9 @@ func (l *LibraryElementService) deleteLibraryElement(c context.Context, signedIn
 			return model.ErrLibraryElementHasConnections
 		}
 
+		if _, err := session.Exec("DELETE FROM "+model.LibraryElementVersionTableName+" WHERE element_id=?", element.ID); err != nil {
+			return err
+		}
 		result, err := session.Exec("DELETE FROM library_element WHERE id=?", element.ID)
 		if err != nil {
 			return err
@@ -610,6 +613,9 @@ func (l *LibraryElementService) patchLibraryElement(c context.Context, signedInU
 		} else if rowsAffected != 1 {
 			return model.ErrLibraryElementNotFound
 		}
+		if err := insertLibraryElementVersion(session, &libraryElement); err != nil {
+			return err
+		}
 
 		dto = model.LibraryElementDTO{
 			ID:          libraryElement.ID,
@@ -673,12 +679,13 @@ func (l *LibraryElementService) getConnections(c context.Context, signedInUser i
 
 		for _, connection := range libraryElementConnections {
 			connections = append(connections, model.LibraryElementConnectionDTO{
-				ID:            connection.ID,
-				Kind:          connection.Kind,
-				ElementID:     connection.ElementID,
-				ConnectionID:  connection.ConnectionID,
-				ConnectionUID: connection.ConnectionUID,
-				Created:       connection.Created,
+				ID:             connection.ID,
+				Kind:           connection.Kind,
+				ElementID:      connection.ElementID,
+				ConnectionID:   connection.ConnectionID,
+				ConnectionUID:  connection.ConnectionUID,
+				ElementVersion: connection.ElementVersion,
+				Created:        connection.Created,
 				CreatedBy: librarypanel.LibraryElementDTOMetaUser{
 					Id:        connection.CreatedBy,
 					Name:      connection.CreatedByName,
@@ -742,6 +749,27 @@ func (l *LibraryElementService) getElementsForDashboardID(c context.Context, das
 			}
 		}
 
+		// dashboards pinned to a version of an element get the model of that version
+		var pinnedVersions []model.LibraryElementVersion
+		sql = "SELECT lev.* FROM " + model.LibraryElementVersionTableName + " AS lev" +
+			" INNER JOIN " + model.LibraryElementConnectionTableName + " AS lce ON lce.element_id = lev.element_id AND lce.element_version = lev.version" +
+			" WHERE lce.kind=1 AND lce.connection_id=?"
+		if err := session.SQL(sql, dashboardID).Find(&pinnedVersions); err != nil {
+			return err
+		}
+		for _, version := range pinnedVersions {
+			for uid, element := range libraryElementMap {
+				if element.ID != version.ElementID {
+					continue
+				}
+				element.Name = version.Name
+				element.Description = version.Description
+				element.Model = version.Model
+				element.Version = version.Version
+				libraryElementMap[uid] = element
+			}
+		}
+
 		return nil
 	})
 
@@ -749,7 +777,8 @@ func (l *LibraryElementService) getElementsForDashboardID(c context.Context, das
 }
 
 // connectElementsToDashboardID adds connections for all elements Library Elements in a Dashboard.
-func (l *LibraryElementService) connectElementsToDashboardID(c context.Context, signedInUser identity.Requester, elementUIDs []string, dashboardID int64) error {
+// The connections are pinned to the version of their element, or follow the latest version when the version is 0.
+func (l *LibraryElementService) connectElementsToDashboardID(c context.Context, signedInUser identity.Requester, elementUIDs []string, elementVersions map[string]int64, dashboardID int64) error {
 	err := l.SQLStore.WithTransactionalDbSession(c, func(session *db.Session) error {
 		_, err := session.Exec("DELETE FROM "+model.LibraryElementConnectionTableName+" WHERE kind=1 AND connection_id=?", dashboardID)
 		if err != nil {
@@ -765,6 +794,17 @@ func (l *LibraryElementService) connectElementsToDashboardID(c context.Context,
 				return err
 			}
 
+			elementVersion := elementVersions[elementUID]
+			if elementVersion != 0 {
+				exists, err := session.Table(model.LibraryElementVersionTableName).Where("element_id=? AND version=?", element.ID, elementVersion).Exist()
+				if err != nil {
+					return err
+				}
+				if !exists {
+					return fmt.Errorf("%w: version %d of %s", model.ErrLibraryElementVersionNotFound, elementVersion, elementUID)
+				}
+			}
+
 			namespaceID, identifier := signedInUser.GetNamespacedID()
 			userID := int64(0)
 			switch namespaceID {
@@ -776,11 +816,12 @@ func (l *LibraryElementService) connectElementsToDashboardID(c context.Context,
 			}
 
 			connection := model.LibraryElementConnection{
-				ElementID:    element.ID,
-				Kind:         1,
-				ConnectionID: dashboardID,
-				Created:      time.Now(),
-				CreatedBy:    userID,
+				ElementID:      element.ID,
+				Kind:           1,
+				ConnectionID:   dashboardID,
+				ElementVersion: elementVersion,
+				Created:        time.Now(),
+				CreatedBy:      userID,
 			}
 			if _, err := session.Insert(&connection); err != nil {
 				if l.SQLStore.GetDialect().IsUniqueConstraintViolation(err) {
@@ -856,6 +897,10 @@ func (l *LibraryElementService) deleteLibraryElementsInFolderUID(c context.Conte
 			if err != nil {
 				return err
 			}
+			_, err = session.Exec("DELETE FROM "+model.LibraryElementVersionTableName+" WHERE element_id=?", elementID.ID)
+			if err != nil {
+				return err
+			}
 		}
 		if _, err := session.Exec("DELETE FROM library_element WHERE folder_id=? AND org_id=?", folderID, signedInUser.GetOrgID()); err != nil {
 			return err
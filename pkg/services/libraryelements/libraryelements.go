@@ -3,6 +3,7 @@ package libraryelements
 import (
 	"context"
 	"encoding/json"
+	"sort"
 
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/infra/db"
@@ -38,6 +39,7 @@ type Service interface {
 	GetElement(c context.Context, signedInUser identity.Requester, cmd model.GetLibraryElementCommand) (model.LibraryElementDTO, error)
 	GetElementsForDashboard(c context.Context, dashboardID int64) (map[string]model.LibraryElementDTO, error)
 	ConnectElementsToDashboard(c context.Context, signedInUser identity.Requester, elementUIDs []string, dashboardID int64) error
+	ConnectElementVersionsToDashboard(c context.Context, signedInUser identity.Requester, elementVersions map[string]int64, dashboardID int64) error
 	DisconnectElementsFromDashboard(c context.Context, dashboardID int64) error
 	DeleteLibraryElementsInFolder(c context.Context, signedInUser identity.Requester, folderUID string) error
 }
@@ -70,9 +72,21 @@ func (l *LibraryElementService) GetElementsForDashboard(c context.Context, dashb
 	return l.getElementsForDashboardID(c, dashboardID)
 }
 
-// ConnectElementsToDashboard connects elements to a specific dashboard.
+// ConnectElementsToDashboard connects elements to a specific dashboard, following their latest version.
 func (l *LibraryElementService) ConnectElementsToDashboard(c context.Context, signedInUser identity.Requester, elementUIDs []string, dashboardID int64) error {
-	return l.connectElementsToDashboardID(c, signedInUser, elementUIDs, dashboardID)
+	return l.connectElementsToDashboardID(c, signedInUser, elementUIDs, nil, dashboardID)
+}
+
+// ConnectElementVersionsToDashboard connects elements to a specific dashboard, pinning them to the given version.
+// Elements with version 0 follow their latest version.
+func (l *LibraryElementService) ConnectElementVersionsToDashboard(c context.Context, signedInUser identity.Requester, elementVersions map[string]int64, dashboardID int64) error {
+	elementUIDs := make([]string, 0, len(elementVersions))
+	for elementUID := range elementVersions {
+		elementUIDs = append(elementUIDs, elementUID)
+	}
+	sort.Strings(elementUIDs)
+
+	return l.connectElementsToDashboardID(c, signedInUser, elementUIDs, elementVersions, dashboardID)
 }
 
 // DisconnectElementsFromDashboard disconnects elements from a specific dashboard.
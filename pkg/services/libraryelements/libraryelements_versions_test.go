@@ -0,0 +1,229 @@
+package libraryelements
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/api/response"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/services/org"
+	"github.com/grafana/grafana/pkg/web"
+)
+
+func TestLibraryElementVersions(t *testing.T) {
+	scenarioWithPanel(t, "When an admin creates a library panel, it should store its first version",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.SignedInUser.Permissions[sc.reqContext.OrgID][dashboards.ActionFoldersRead] = []string{dashboards.ScopeFoldersAll}
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.getVersionsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			result := validateAndUnMarshalVersionsResponse(t, resp)
+			require.Equal(t, int64(1), result.Result.TotalCount)
+			require.Len(t, result.Result.Versions, 1)
+			require.Equal(t, int64(1), result.Result.Versions[0].Version)
+			require.Equal(t, "Text - Library Panel", result.Result.Versions[0].Name)
+		})
+
+	scenarioWithPanel(t, "When an admin patches a library panel, it should keep the previous versions",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.SignedInUser.Permissions[sc.reqContext.OrgID][dashboards.ActionFoldersRead] = []string{dashboards.ScopeFoldersAll}
+
+			patchLibraryPanelModel(t, sc, "Text - New title")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.getVersionsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			versions := validateAndUnMarshalVersionsResponse(t, resp)
+			require.Equal(t, int64(2), versions.Result.TotalCount)
+			require.Equal(t, int64(2), versions.Result.Versions[0].Version)
+			require.Equal(t, int64(1), versions.Result.Versions[1].Version)
+			require.Nil(t, versions.Result.Versions[0].Model)
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "1"})
+			resp = sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			version := validateAndUnMarshalVersionResponse(t, resp)
+			versionModel, err := simplejson.NewJson(version.Result.Model)
+			require.NoError(t, err)
+			require.Equal(t, "Text - Library Panel", versionModel.Get("title").MustString())
+		})
+
+	scenarioWithPanel(t, "When an admin tries to get a version that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.SignedInUser.Permissions[sc.reqContext.OrgID][dashboards.ActionFoldersRead] = []string{dashboards.ScopeFoldersAll}
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "2"})
+			resp := sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID, ":version": "latest"})
+			resp = sc.service.getVersionHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin diffs two versions of a library panel, it should return the diff",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.SignedInUser.Permissions[sc.reqContext.OrgID][dashboards.ActionFoldersRead] = []string{dashboards.ScopeFoldersAll}
+
+			patchLibraryPanelModel(t, sc, "Text - New title")
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			sc.reqContext.Req.Form = url.Values{"base": {"1"}, "new": {"2"}, "diffType": {"delta"}}
+			resp := sc.service.diffVersionsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+			require.Contains(t, string(resp.Body()), "Text - New title")
+
+			sc.reqContext.Req.Form = url.Values{"base": {"2"}, "new": {"2"}}
+			resp = sc.service.diffVersionsHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+
+			sc.reqContext.Req.Form = url.Values{"base": {"1"}}
+			resp = sc.service.diffVersionsHandler(sc.reqContext)
+			require.Equal(t, 400, resp.Status())
+
+			sc.reqContext.Req.Form = url.Values{"base": {"1"}, "new": {"3"}}
+			resp = sc.service.diffVersionsHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When a dashboard is pinned to a version of a library panel, it should get that version",
+		func(t *testing.T, sc scenarioContext) {
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dashboards.Dashboard{
+				Title: "Pinned dashboard",
+				Data:  simplejson.NewFromAny(map[string]any{"panels": []any{}}),
+			}, sc.folder.ID)
+			err := sc.service.ConnectElementVersionsToDashboard(sc.reqContext.Req.Context(), sc.reqContext.SignedInUser, map[string]int64{sc.initialResult.Result.UID: 1}, dashInDB.ID)
+			require.NoError(t, err)
+
+			patchLibraryPanelModel(t, sc, "Text - New title")
+
+			elements, err := sc.service.GetElementsForDashboard(sc.reqContext.Req.Context(), dashInDB.ID)
+			require.NoError(t, err)
+			require.Len(t, elements, 1)
+			element := elements[sc.initialResult.Result.UID]
+			require.Equal(t, int64(1), element.Version)
+			elementModel, err := simplejson.NewJson(element.Model)
+			require.NoError(t, err)
+			require.Equal(t, "Text - Library Panel", elementModel.Get("title").MustString())
+		})
+
+	scenarioWithPanel(t, "When a dashboard is pinned to a version that does not exist, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dashboards.Dashboard{
+				Title: "Pinned dashboard",
+				Data:  simplejson.NewFromAny(map[string]any{"panels": []any{}}),
+			}, sc.folder.ID)
+			err := sc.service.ConnectElementVersionsToDashboard(sc.reqContext.Req.Context(), sc.reqContext.SignedInUser, map[string]int64{sc.initialResult.Result.UID: 5}, dashInDB.ID)
+			require.ErrorIs(t, err, model.ErrLibraryElementVersionNotFound)
+		})
+
+	scenarioWithPanel(t, "When an admin gets the affected dashboards of a library panel, it should only count the dashboards following the latest version",
+		func(t *testing.T, sc scenarioContext) {
+			pinnedDash := createDashboard(t, sc.sqlStore, sc.user, &dashboards.Dashboard{
+				Title: "Pinned dashboard",
+				Data:  simplejson.NewFromAny(map[string]any{"panels": []any{}}),
+			}, sc.folder.ID)
+			err := sc.service.ConnectElementVersionsToDashboard(sc.reqContext.Req.Context(), sc.reqContext.SignedInUser, map[string]int64{sc.initialResult.Result.UID: 1}, pinnedDash.ID)
+			require.NoError(t, err)
+			latestDash := createDashboard(t, sc.sqlStore, sc.user, &dashboards.Dashboard{
+				Title: "Latest dashboard",
+				Data:  simplejson.NewFromAny(map[string]any{"panels": []any{}}),
+			}, sc.folder.ID)
+			err = sc.service.ConnectElementsToDashboard(sc.reqContext.Req.Context(), sc.reqContext.SignedInUser, []string{sc.initialResult.Result.UID}, latestDash.ID)
+			require.NoError(t, err)
+
+			sc.reqContext.SignedInUser.Permissions[sc.reqContext.OrgID][dashboards.ActionFoldersRead] = []string{dashboards.ScopeFoldersAll}
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.getAffectedDashboardsHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			var result model.LibraryElementAffectedDashboardsResponse
+			require.NoError(t, json.Unmarshal(resp.Body(), &result))
+			require.Equal(t, int64(1), result.Result.AffectedCount)
+			require.Equal(t, int64(1), result.Result.PinnedCount)
+			require.Equal(t, int64(0), result.Result.HiddenCount)
+			require.Equal(t, []model.LibraryElementAffectedDashboardDTO{
+				{
+					UID:         latestDash.UID,
+					Title:       "Latest dashboard",
+					FolderUID:   sc.folder.UID,
+					FolderTitle: sc.folder.Title,
+					Affected:    true,
+				},
+				{
+					UID:            pinnedDash.UID,
+					Title:          "Pinned dashboard",
+					FolderUID:      sc.folder.UID,
+					FolderTitle:    sc.folder.Title,
+					ElementVersion: 1,
+				},
+			}, result.Result.Dashboards)
+		})
+
+	scenarioWithPanel(t, "When a user who can't view a library panel gets its affected dashboards, it should fail",
+		func(t *testing.T, sc scenarioContext) {
+			sc.reqContext.SignedInUser.OrgRole = org.RoleViewer
+			sc.reqContext.SignedInUser.Permissions = map[int64]map[string][]string{1: {}}
+
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.getAffectedDashboardsHandler(sc.reqContext)
+			require.Equal(t, 404, resp.Status())
+		})
+
+	scenarioWithPanel(t, "When an admin deletes a library panel, it should delete its versions",
+		func(t *testing.T, sc scenarioContext) {
+			sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+			resp := sc.service.deleteHandler(sc.reqContext)
+			require.Equal(t, 200, resp.Status())
+
+			err := sc.sqlStore.WithDbSession(sc.reqContext.Req.Context(), func(session *db.Session) error {
+				count, err := session.Table(model.LibraryElementVersionTableName).Where("element_id=?", sc.initialResult.Result.ID).Count()
+				require.Equal(t, int64(0), count)
+				return err
+			})
+			require.NoError(t, err)
+		})
+}
+
+func patchLibraryPanelModel(t *testing.T, sc scenarioContext, title string) {
+	t.Helper()
+
+	cmd := model.PatchLibraryElementCommand{
+		FolderID: sc.folder.ID,
+		Model:    []byte(`{ "title": "` + title + `", "type": "text" }`),
+		Kind:     int64(model.PanelElement),
+		Version:  1,
+	}
+	sc.ctx.Req = web.SetURLParams(sc.ctx.Req, map[string]string{":uid": sc.initialResult.Result.UID})
+	sc.reqContext.Req.Body = mockRequestBody(cmd)
+	resp := sc.service.patchHandler(sc.reqContext)
+	require.Equal(t, 200, resp.Status())
+}
+
+func validateAndUnMarshalVersionsResponse(t *testing.T, resp response.Response) model.LibraryElementVersionsResponse {
+	t.Helper()
+
+	require.Equal(t, 200, resp.Status())
+	var result model.LibraryElementVersionsResponse
+	err := json.Unmarshal(resp.Body(), &result)
+	require.NoError(t, err)
+	return result
+}
+
+func validateAndUnMarshalVersionResponse(t *testing.T, resp response.Response) model.LibraryElementVersionResponse {
+	t.Helper()
+
+	require.Equal(t, 200, resp.Status())
+	var result model.LibraryElementVersionResponse
+	err := json.Unmarshal(resp.Body(), &result)
+	require.NoError(t, err)
+	return result
+}
@@ -131,14 +131,67 @@ type LibraryElementDTOMeta struct {
 	UpdatedBy librarypanel.LibraryElementDTOMetaUser `json:"updatedBy"`
 }
 
+// LibraryElementVersion is the model for the versions of library elements.
+type LibraryElementVersion struct {
+	ID          int64 `xorm:"pk autoincr 'id'"`
+	OrgID       int64 `xorm:"org_id"`
+	ElementID   int64 `xorm:"element_id"`
+	Version     int64
+	Name        string
+	Description string
+	Model       json.RawMessage
+
+	Created   time.Time
+	CreatedBy int64
+}
+
+// LibraryElementVersionWithMeta is the model used to retrieve versions with additional meta information.
+type LibraryElementVersionWithMeta struct {
+	ID          int64 `xorm:"pk autoincr 'id'"`
+	OrgID       int64 `xorm:"org_id"`
+	ElementID   int64 `xorm:"element_id"`
+	Version     int64
+	Name        string
+	Description string
+	Model       json.RawMessage
+
+	Created        time.Time
+	CreatedBy      int64
+	CreatedByName  string
+	CreatedByEmail string
+}
+
+// LibraryElementVersionDTO is the frontend DTO for the versions of library elements.
+type LibraryElementVersionDTO struct {
+	ID          int64                                  `json:"id"`
+	ElementID   int64                                  `json:"elementId"`
+	UID         string                                 `json:"uid"`
+	Version     int64                                  `json:"version"`
+	Name        string                                 `json:"name"`
+	Description string                                 `json:"description"`
+	Model       json.RawMessage                        `json:"model,omitempty"`
+	Created     time.Time                              `json:"created"`
+	CreatedBy   librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
+}
+
+// LibraryElementVersionSearchResult is the result of listing the versions of a library element.
+type LibraryElementVersionSearchResult struct {
+	TotalCount int64                      `json:"totalCount"`
+	Versions   []LibraryElementVersionDTO `json:"versions"`
+	Page       int                        `json:"page"`
+	PerPage    int                        `json:"perPage"`
+}
+
 // libraryElementConnection is the model for library element connections.
 type LibraryElementConnection struct {
 	ID           int64 `xorm:"pk autoincr 'id'"`
 	ElementID    int64 `xorm:"element_id"`
 	Kind         int64 `xorm:"kind"`
 	ConnectionID int64 `xorm:"connection_id"`
-	Created      time.Time
-	CreatedBy    int64
+	// ElementVersion is the version the connection is pinned to, or 0 when it follows the latest version.
+	ElementVersion int64 `xorm:"element_version"`
+	Created        time.Time
+	CreatedBy      int64
 }
 
 // libraryElementConnectionWithMeta is the model for library element connections with meta.
@@ -148,6 +201,7 @@ type LibraryElementConnectionWithMeta struct {
 	Kind           int64  `xorm:"kind"`
 	ConnectionID   int64  `xorm:"connection_id"`
 	ConnectionUID  string `xorm:"connection_uid"`
+	ElementVersion int64  `xorm:"element_version"`
 	Created        time.Time
 	CreatedBy      int64
 	CreatedByName  string
@@ -156,13 +210,47 @@ type LibraryElementConnectionWithMeta struct {
 
 // LibraryElementConnectionDTO is the frontend DTO for element connections.
 type LibraryElementConnectionDTO struct {
-	ID            int64                                  `json:"id"`
-	Kind          int64                                  `json:"kind"`
-	ElementID     int64                                  `json:"elementId"`
-	ConnectionID  int64                                  `json:"connectionId"`
-	ConnectionUID string                                 `json:"connectionUid"`
-	Created       time.Time                              `json:"created"`
-	CreatedBy     librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
+	ID            int64  `json:"id"`
+	Kind          int64  `json:"kind"`
+	ElementID     int64  `json:"elementId"`
+	ConnectionID  int64  `json:"connectionId"`
+	ConnectionUID string `json:"connectionUid"`
+	// ElementVersion is the version the dashboard is pinned to, or 0 when it follows the latest version.
+	ElementVersion int64                                  `json:"elementVersion"`
+	Created        time.Time                              `json:"created"`
+	CreatedBy      librarypanel.LibraryElementDTOMetaUser `json:"createdBy"`
+}
+
+// LibraryElementAffectedDashboard is a dashboard connected to a library element.
+type LibraryElementAffectedDashboard struct {
+	UID         string `xorm:"uid"`
+	Title       string
+	FolderUID   string `xorm:"folder_uid"`
+	FolderTitle string
+	// ElementVersion is the version the dashboard is pinned to, or 0 when it follows the latest version.
+	ElementVersion int64 `xorm:"element_version"`
+}
+
+// LibraryElementAffectedDashboardDTO is the frontend DTO for the dashboards affected by a change of a library element.
+type LibraryElementAffectedDashboardDTO struct {
+	UID            string `json:"uid"`
+	Title          string `json:"title"`
+	FolderUID      string `json:"folderUid"`
+	FolderTitle    string `json:"folderTitle"`
+	ElementVersion int64  `json:"elementVersion"`
+	// Affected is true when the dashboard follows the latest version and displays the changes once saved.
+	Affected bool `json:"affected"`
+}
+
+// LibraryElementAffectedDashboardsResult lists the dashboards connected to a library element.
+type LibraryElementAffectedDashboardsResult struct {
+	// AffectedCount is the number of dashboards following the latest version.
+	AffectedCount int64 `json:"affectedCount"`
+	// PinnedCount is the number of dashboards pinned to a version.
+	PinnedCount int64 `json:"pinnedCount"`
+	// HiddenCount is the number of connected dashboards the user cannot view.
+	HiddenCount int64                                `json:"hiddenCount"`
+	Dashboards  []LibraryElementAffectedDashboardDTO `json:"dashboards"`
 }
 
 var (
@@ -184,6 +272,10 @@ var (
 	ErrLibraryElementInvalidUID = errors.New("uid contains illegal characters")
 	// errLibraryElementUIDTooLong is an error for when the uid of a library element is invalid
 	ErrLibraryElementUIDTooLong = errors.New("uid too long, max 40 characters")
+	// ErrLibraryElementVersionNotFound is an error for when a version of a library element can't be found.
+	ErrLibraryElementVersionNotFound = errors.New("library element version could not be found")
+	// ErrLibraryElementVersionsIdentical is an error for when the diffed versions of a library element are identical.
+	ErrLibraryElementVersionsIdentical = errors.New("library element versions are identical")
 )
 
 // Commands
@@ -242,6 +334,22 @@ type GetLibraryElementCommand struct {
 	UID      string
 }
 
+// GetLibraryElementVersionsQuery is the query for listing the versions of a library element, the most recent first.
+type GetLibraryElementVersionsQuery struct {
+	UID     string
+	PerPage int
+	Page    int
+}
+
+// DiffLibraryElementVersionsQuery is the query for the diff between two versions of a library element.
+type DiffLibraryElementVersionsQuery struct {
+	UID         string
+	BaseVersion int64
+	NewVersion  int64
+	// DiffType is json, basic or delta.
+	DiffType string
+}
+
 // SearchLibraryElementsQuery is the query used for searching for Elements
 type SearchLibraryElementsQuery struct {
 	PerPage          int
@@ -270,6 +378,21 @@ type LibraryElementArrayResponse struct {
 	Result []LibraryElementDTO `json:"result"`
 }
 
+// LibraryElementVersionResponse is a response struct for LibraryElementVersionDTO.
+type LibraryElementVersionResponse struct {
+	Result LibraryElementVersionDTO `json:"result"`
+}
+
+// LibraryElementVersionsResponse is a response struct for LibraryElementVersionSearchResult.
+type LibraryElementVersionsResponse struct {
+	Result LibraryElementVersionSearchResult `json:"result"`
+}
+
+// LibraryElementAffectedDashboardsResponse is a response struct for LibraryElementAffectedDashboardsResult.
+type LibraryElementAffectedDashboardsResponse struct {
+	Result LibraryElementAffectedDashboardsResult `json:"result"`
+}
+
 // LibraryElementConnectionsResponse is a response struct for an array of LibraryElementConnectionDTO.
 type LibraryElementConnectionsResponse struct {
 	Result []LibraryElementConnectionDTO `json:"result"`
@@ -292,3 +415,5 @@ const (
 )
 
 const LibraryElementConnectionTableName = "library_element_connection"
+
+const LibraryElementVersionTableName = "library_element_version"
@@ -0,0 +1,245 @@
+package libraryelements
+
+import (
+	"context"
+	"errors"
+
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/components/dashdiffs"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/kinds/librarypanel"
+	"github.com/grafana/grafana/pkg/services/auth/identity"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	"github.com/grafana/grafana/pkg/services/libraryelements/model"
+	"github.com/grafana/grafana/pkg/services/org"
+)
+
+const selectLibraryElementVersionWithMeta = `
+SELECT lev.id, lev.org_id, lev.element_id, lev.version, lev.name, lev.description, lev.model, lev.created, lev.created_by
+	, u.login AS created_by_name
+	, u.email AS created_by_email`
+
+// insertLibraryElementVersion stores the current state of the library element in its version history.
+func insertLibraryElementVersion(session *db.Session, element *model.LibraryElement) error {
+	version := model.LibraryElementVersion{
+		OrgID:       element.OrgID,
+		ElementID:   element.ID,
+		Version:     element.Version,
+		Name:        element.Name,
+		Description: element.Description,
+		Model:       element.Model,
+		Created:     element.Updated,
+		CreatedBy:   element.UpdatedBy,
+	}
+	_, err := session.Insert(&version)
+	return err
+}
+
+func (l *LibraryElementService) getFromLibraryElementVersionWithMeta() string {
+	return " FROM " + model.LibraryElementVersionTableName + " AS lev" +
+		" LEFT JOIN " + l.SQLStore.GetDialect().Quote("user") + " AS u ON lev.created_by = u.id"
+}
+
+func toLibraryElementVersionDTO(uid string, version model.LibraryElementVersionWithMeta) model.LibraryElementVersionDTO {
+	return model.LibraryElementVersionDTO{
+		ID:          version.ID,
+		ElementID:   version.ElementID,
+		UID:         uid,
+		Version:     version.Version,
+		Name:        version.Name,
+		Description: version.Description,
+		Model:       version.Model,
+		Created:     version.Created,
+		CreatedBy: librarypanel.LibraryElementDTOMetaUser{
+			Id:        version.CreatedBy,
+			Name:      version.CreatedByName,
+			AvatarUrl: dtos.GetGravatarUrl(version.CreatedByEmail),
+		},
+	}
+}
+
+// getLibraryElementVersions lists the versions of a library element, the most recent first.
+func (l *LibraryElementService) getLibraryElementVersions(c context.Context, signedInUser identity.Requester, query model.GetLibraryElementVersionsQuery) (model.LibraryElementVersionSearchResult, error) {
+	if query.PerPage <= 0 {
+		query.PerPage = 100
+	}
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+
+	// getting the element checks that the user can view it
+	element, err := l.getLibraryElementByUid(c, signedInUser, model.GetLibraryElementCommand{UID: query.UID, FolderName: dashboards.RootFolderName})
+	if err != nil {
+		return model.LibraryElementVersionSearchResult{}, err
+	}
+
+	result := model.LibraryElementVersionSearchResult{
+		Versions: make([]model.LibraryElementVersionDTO, 0),
+		Page:     query.Page,
+		PerPage:  query.PerPage,
+	}
+	err = l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		count, err := session.Table(model.LibraryElementVersionTableName).Where("element_id=?", element.ID).Count()
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		var versions []model.LibraryElementVersionWithMeta
+		sql := selectLibraryElementVersionWithMeta + l.getFromLibraryElementVersionWithMeta() +
+			" WHERE lev.element_id=? ORDER BY lev.version DESC " +
+			l.SQLStore.GetDialect().LimitOffset(int64(query.PerPage), int64((query.Page-1)*query.PerPage))
+		if err := session.SQL(sql, element.ID).Find(&versions); err != nil {
+			return err
+		}
+
+		for _, version := range versions {
+			dto := toLibraryElementVersionDTO(element.UID, version)
+			// the models are only returned when getting a single version
+			dto.Model = nil
+			result.Versions = append(result.Versions, dto)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// getLibraryElementVersion gets a version of a library element.
+func (l *LibraryElementService) getLibraryElementVersion(c context.Context, signedInUser identity.Requester, uid string, version int64) (model.LibraryElementVersionDTO, error) {
+	// getting the element checks that the user can view it
+	element, err := l.getLibraryElementByUid(c, signedInUser, model.GetLibraryElementCommand{UID: uid, FolderName: dashboards.RootFolderName})
+	if err != nil {
+		return model.LibraryElementVersionDTO{}, err
+	}
+
+	var dto model.LibraryElementVersionDTO
+	err = l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		var versions []model.LibraryElementVersionWithMeta
+		sql := selectLibraryElementVersionWithMeta + l.getFromLibraryElementVersionWithMeta() +
+			" WHERE lev.element_id=? AND lev.version=?"
+		if err := session.SQL(sql, element.ID, version).Find(&versions); err != nil {
+			return err
+		}
+		if len(versions) == 0 {
+			return model.ErrLibraryElementVersionNotFound
+		}
+
+		dto = toLibraryElementVersionDTO(element.UID, versions[0])
+		if element.Kind == int64(model.PanelElement) {
+			updatedModel, err := l.addUidToLibraryPanel(dto.Model, element.UID)
+			if err != nil {
+				return err
+			}
+			dto.Model = updatedModel
+		}
+		return nil
+	})
+
+	return dto, err
+}
+
+// diffLibraryElementVersions computes the diff between the models of two versions of a library element.
+func (l *LibraryElementService) diffLibraryElementVersions(c context.Context, signedInUser identity.Requester, query model.DiffLibraryElementVersionsQuery) (*dashdiffs.Result, error) {
+	options := dashdiffs.Options{
+		OrgId:    signedInUser.GetOrgID(),
+		DiffType: dashdiffs.ParseDiffType(query.DiffType),
+	}
+	if options.DiffType == dashdiffs.DiffSemantic {
+		// the semantic diff only understands dashboards
+		return nil, dashdiffs.ErrUnsupportedDiffType
+	}
+
+	baseVersion, err := l.getLibraryElementVersion(c, signedInUser, query.UID, query.BaseVersion)
+	if err != nil {
+		return nil, err
+	}
+	newVersion, err := l.getLibraryElementVersion(c, signedInUser, query.UID, query.NewVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	baseData, err := simplejson.NewJson(baseVersion.Model)
+	if err != nil {
+		return nil, err
+	}
+	newData, err := simplejson.NewJson(newVersion.Model)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := dashdiffs.CalculateDiff(c, &options, baseData, newData)
+	if errors.Is(err, dashdiffs.ErrNilDiff) {
+		return nil, model.ErrLibraryElementVersionsIdentical
+	}
+	return result, err
+}
+
+// getAffectedDashboards lists the dashboards connected to a library element, so that users know which
+// dashboards display the changes of the library element before saving it. The dashboards pinned to a
+// version of the library element are not affected.
+func (l *LibraryElementService) getAffectedDashboards(c context.Context, signedInUser identity.Requester, uid string) (model.LibraryElementAffectedDashboardsResult, error) {
+	result := model.LibraryElementAffectedDashboardsResult{
+		Dashboards: make([]model.LibraryElementAffectedDashboardDTO, 0),
+	}
+	recursiveQueriesAreSupported, err := l.SQLStore.RecursiveQueriesAreSupported()
+	if err != nil {
+		return result, err
+	}
+
+	// getting the element checks that the user can view it
+	element, err := l.getLibraryElementByUid(c, signedInUser, model.GetLibraryElementCommand{UID: uid, FolderName: dashboards.RootFolderName})
+	if err != nil {
+		return result, err
+	}
+
+	err = l.SQLStore.WithDbSession(c, func(session *db.Session) error {
+		var total int64
+		countSQL := "SELECT COUNT(*) FROM " + model.LibraryElementConnectionTableName + " AS lec" +
+			" INNER JOIN dashboard AS dashboard ON lec.connection_id = dashboard.id" +
+			" WHERE lec.element_id=? AND lec.kind=1"
+		if _, err := session.SQL(countSQL, element.ID).Get(&total); err != nil {
+			return err
+		}
+
+		var affectedDashboards []model.LibraryElementAffectedDashboard
+		builder := db.NewSqlBuilder(l.Cfg, l.features, l.SQLStore.GetDialect(), recursiveQueriesAreSupported)
+		builder.Write("SELECT dashboard.uid, dashboard.title, lec.element_version")
+		builder.Write(", coalesce(parent_folder.uid, '') AS folder_uid")
+		builder.Write(", coalesce(parent_folder.title, ?) AS folder_title", dashboards.RootFolderName)
+		builder.Write(" FROM " + model.LibraryElementConnectionTableName + " AS lec")
+		builder.Write(" INNER JOIN dashboard AS dashboard ON lec.connection_id = dashboard.id")
+		builder.Write(" LEFT JOIN dashboard AS parent_folder ON parent_folder.id = dashboard.folder_id AND dashboard.folder_id <> 0")
+		builder.Write(" WHERE lec.element_id=? AND lec.kind=1", element.ID)
+		if signedInUser.GetOrgRole() != org.RoleAdmin {
+			builder.WriteDashboardPermissionFilter(signedInUser, dashboards.PERMISSION_VIEW, "")
+		}
+		builder.Write(" ORDER BY dashboard.title ASC")
+		if err := session.SQL(builder.GetSQLString(), builder.GetParams()...).Find(&affectedDashboards); err != nil {
+			return err
+		}
+
+		for _, dashboard := range affectedDashboards {
+			affected := dashboard.ElementVersion == 0
+			if affected {
+				result.AffectedCount++
+			} else {
+				result.PinnedCount++
+			}
+			result.Dashboards = append(result.Dashboards, model.LibraryElementAffectedDashboardDTO{
+				UID:            dashboard.UID,
+				Title:          dashboard.Title,
+				FolderUID:      dashboard.FolderUID,
+				FolderTitle:    dashboard.FolderTitle,
+				ElementVersion: dashboard.ElementVersion,
+				Affected:       affected,
+			})
+		}
+		result.HiddenCount = total - int64(len(affectedDashboards))
+
+		return nil
+	})
+
+	return result, err
+}
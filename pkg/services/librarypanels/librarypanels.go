@@ -60,27 +60,23 @@ type LibraryPanelService struct {
 var _ Service = (*LibraryPanelService)(nil)
 
 // ConnectLibraryPanelsForDashboard loops through all panels in dashboard JSON and connects any library panels to the dashboard.
+// Library panels with a version are pinned to that version, the others follow the latest version.
 func (lps *LibraryPanelService) ConnectLibraryPanelsForDashboard(c context.Context, signedInUser identity.Requester, dash *dashboards.Dashboard) error {
 	panels := dash.Data.Get("panels").MustArray()
-	libraryPanels := make(map[string]string)
+	libraryPanels := make(map[string]int64)
 	err := connectLibraryPanelsRecursively(c, panels, libraryPanels)
 	if err != nil {
 		return err
 	}
 
-	elementUIDs := make([]string, 0, len(libraryPanels))
-	for libraryPanel := range libraryPanels {
-		elementUIDs = append(elementUIDs, libraryPanel)
-	}
-
-	return lps.LibraryElementService.ConnectElementsToDashboard(c, signedInUser, elementUIDs, dash.ID)
+	return lps.LibraryElementService.ConnectElementVersionsToDashboard(c, signedInUser, libraryPanels, dash.ID)
 }
 
 func isLibraryPanelOrRow(panel *simplejson.Json, panelType string) bool {
 	return panel.Interface() != nil || panelType == "row"
 }
 
-func connectLibraryPanelsRecursively(c context.Context, panels []any, libraryPanels map[string]string) error {
+func connectLibraryPanelsRecursively(c context.Context, panels []any, libraryPanels map[string]int64) error {
 	for _, panel := range panels {
 		panelAsJSON := simplejson.NewFromAny(panel)
 		libraryPanel := panelAsJSON.Get("libraryPanel")
@@ -104,9 +100,10 @@ func connectLibraryPanelsRecursively(c context.Context, panels []any, libraryPan
 		if len(UID) == 0 {
 			return errLibraryPanelHeaderUIDMissing
 		}
-		_, exists := libraryPanels[UID]
-		if !exists {
-			libraryPanels[UID] = UID
+		// a library panel used several times follows the latest version as soon as one of its panels does
+		version := libraryPanel.Get("version").MustInt64()
+		if pinnedVersion, exists := libraryPanels[UID]; !exists || (pinnedVersion != 0 && version == 0) {
+			libraryPanels[UID] = version
 		}
 	}
 
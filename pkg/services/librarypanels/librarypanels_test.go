@@ -91,6 +91,47 @@ func TestConnectLibraryPanelsForDashboard(t *testing.T) {
 			require.Equal(t, sc.initialResult.Result.UID, elements[sc.initialResult.Result.UID].UID)
 		})
 
+	scenarioWithLibraryPanel(t, "When an admin tries to store a dashboard with a library panel pinned to a version, it should connect the two only if the version exists",
+		func(t *testing.T, sc scenarioContext) {
+			dashJSON := map[string]any{
+				"panels": []any{
+					map[string]any{
+						"id": int64(1),
+						"gridPos": map[string]any{
+							"h": 6,
+							"w": 6,
+							"x": 0,
+							"y": 0,
+						},
+						"datasource": "${DS_GDEV-TESTDATA}",
+						"libraryPanel": map[string]any{
+							"uid":     sc.initialResult.Result.UID,
+							"version": int64(2),
+						},
+						"title": "Text - Library Panel",
+						"type":  "text",
+					},
+				},
+			}
+			dash := dashboards.Dashboard{
+				Title: "Testing ConnectLibraryPanelsForDashboard",
+				Data:  simplejson.NewFromAny(dashJSON),
+			}
+			dashInDB := createDashboard(t, sc.sqlStore, sc.user, &dash, sc.folder.ID)
+
+			err := sc.service.ConnectLibraryPanelsForDashboard(sc.ctx, sc.user, dashInDB)
+			require.ErrorIs(t, err, model.ErrLibraryElementVersionNotFound)
+
+			dashInDB.Data.Get("panels").GetIndex(0).Get("libraryPanel").Set("version", int64(1))
+			err = sc.service.ConnectLibraryPanelsForDashboard(sc.ctx, sc.user, dashInDB)
+			require.NoError(t, err)
+
+			elements, err := sc.elementService.GetElementsForDashboard(sc.ctx, dashInDB.ID)
+			require.NoError(t, err)
+			require.Len(t, elements, 1)
+			require.Equal(t, int64(1), elements[sc.initialResult.Result.UID].Version)
+		})
+
 	scenarioWithLibraryPanel(t, "When an admin tries to store a dashboard with library panels inside and outside of rows, it should connect all",
 		func(t *testing.T, sc scenarioContext) {
 			cmd := model.CreateLibraryElementCommand{
@@ -0,0 +1,133 @@
+package cluster
+
+import (
+	"fmt"
+
+	"github.com/centrifugal/centrifuge"
+)
+
+// Broker is a centrifuge.Broker which delivers messages to local subscribers
+// through an in-memory broker and fans them out to all cluster peers.
+//
+// History is kept in memory of the node which published the message, so
+// channels with history and recovery are only consistent when published from
+// a single node.
+type Broker struct {
+	cluster *Cluster
+	local   *centrifuge.MemoryBroker
+	handler centrifuge.BrokerEventHandler
+}
+
+var _ centrifuge.Broker = (*Broker)(nil)
+
+// NewBroker creates Broker for the node and attaches it to the cluster.
+func NewBroker(node *centrifuge.Node, c *Cluster) (*Broker, error) {
+	local, err := centrifuge.NewMemoryBroker(node, centrifuge.MemoryBrokerConfig{})
+	if err != nil {
+		return nil, err
+	}
+	b := &Broker{cluster: c, local: local}
+	c.broker = b
+	return b, nil
+}
+
+// Run ...
+func (b *Broker) Run(h centrifuge.BrokerEventHandler) error {
+	b.handler = h
+	return b.local.Run(h)
+}
+
+// Subscribe is a no-op, peers receive all publications and drop those
+// without local subscribers.
+func (b *Broker) Subscribe(_ string) error {
+	return nil
+}
+
+// Unsubscribe ...
+func (b *Broker) Unsubscribe(_ string) error {
+	return nil
+}
+
+// Publish ...
+func (b *Broker) Publish(ch string, data []byte, opts centrifuge.PublishOptions) (centrifuge.StreamPosition, error) {
+	sp, err := b.local.Publish(ch, data, opts)
+	if err != nil {
+		return sp, err
+	}
+	b.cluster.broadcast(&message{
+		Type:     messageTypePublication,
+		Channel:  ch,
+		Data:     data,
+		Info:     opts.ClientInfo,
+		Tags:     opts.Tags,
+		Position: sp,
+	}, "")
+	return sp, nil
+}
+
+// PublishJoin ...
+func (b *Broker) PublishJoin(ch string, info *centrifuge.ClientInfo) error {
+	if err := b.local.PublishJoin(ch, info); err != nil {
+		return err
+	}
+	b.cluster.broadcast(&message{Type: messageTypeJoin, Channel: ch, Info: info}, "")
+	return nil
+}
+
+// PublishLeave ...
+func (b *Broker) PublishLeave(ch string, info *centrifuge.ClientInfo) error {
+	if err := b.local.PublishLeave(ch, info); err != nil {
+		return err
+	}
+	b.cluster.broadcast(&message{Type: messageTypeLeave, Channel: ch, Info: info}, "")
+	return nil
+}
+
+// PublishControl ...
+func (b *Broker) PublishControl(data []byte, nodeID, _ string) error {
+	if nodeID == "" || nodeID == b.cluster.nodeID {
+		// Control messages sent by the node itself are ignored by
+		// centrifuge, but the ones addressed to it must be handled.
+		if err := b.handler.HandleControl(data); err != nil {
+			return err
+		}
+		if nodeID != "" {
+			return nil
+		}
+	}
+	b.cluster.broadcast(&message{Type: messageTypeControl, Data: data}, nodeID)
+	return nil
+}
+
+// History ...
+func (b *Broker) History(ch string, opts centrifuge.HistoryOptions) ([]*centrifuge.Publication, centrifuge.StreamPosition, error) {
+	return b.local.History(ch, opts)
+}
+
+// RemoveHistory ...
+func (b *Broker) RemoveHistory(ch string) error {
+	return b.local.RemoveHistory(ch)
+}
+
+func (b *Broker) handleMessage(msg *message) error {
+	if b.handler == nil {
+		return fmt.Errorf("broker is not running")
+	}
+	switch msg.Type {
+	case messageTypePublication:
+		return b.handler.HandlePublication(msg.Channel, &centrifuge.Publication{
+			Offset: msg.Position.Offset,
+			Data:   msg.Data,
+			Info:   msg.Info,
+			Tags:   msg.Tags,
+		}, msg.Position)
+	case messageTypeJoin:
+		return b.handler.HandleJoin(msg.Channel, msg.Info)
+	case messageTypeLeave:
+		return b.handler.HandleLeave(msg.Channel, msg.Info)
+	case messageTypeControl:
+		return b.handler.HandleControl(msg.Data)
+	default:
+		return fmt.Errorf("unknown message type: %s", msg.Type)
+	}
+}
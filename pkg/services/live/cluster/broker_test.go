@@ -0,0 +1,207 @@
+package cluster
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	grpcAuth "github.com/grpc-ecosystem/go-grpc-middleware/auth"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type testEventHandler struct {
+	mu           sync.Mutex
+	publications map[string][][]byte
+	joins        map[string]int
+	controls     [][]byte
+}
+
+func newTestEventHandler() *testEventHandler {
+	return &testEventHandler{publications: map[string][][]byte{}, joins: map[string]int{}}
+}
+
+func (h *testEventHandler) HandlePublication(ch string, pub *centrifuge.Publication, _ centrifuge.StreamPosition) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.publications[ch] = append(h.publications[ch], pub.Data)
+	return nil
+}
+
+func (h *testEventHandler) HandleJoin(ch string, _ *centrifuge.ClientInfo) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.joins[ch]++
+	return nil
+}
+
+func (h *testEventHandler) HandleLeave(_ string, _ *centrifuge.ClientInfo) error {
+	return nil
+}
+
+func (h *testEventHandler) HandleControl(data []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.controls = append(h.controls, data)
+	return nil
+}
+
+func (h *testEventHandler) numPublications(ch string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.publications[ch])
+}
+
+func (h *testEventHandler) numControls() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.controls)
+}
+
+type testClusterNode struct {
+	cluster  *Cluster
+	broker   *Broker
+	presence *PresenceManager
+	handler  *testEventHandler
+	member   *Member
+}
+
+func newTestClusterNode(t *testing.T, nodeID string) *testClusterNode {
+	t.Helper()
+	node, err := centrifuge.New(centrifuge.Config{})
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	c, err := New(Config{Token: "secret", AdvertiseAddress: listener.Addr().String()}, nodeID, nil)
+	require.NoError(t, err)
+	broker, err := NewBroker(node, c)
+	require.NoError(t, err)
+	presence, err := NewPresenceManager(node, c)
+	require.NoError(t, err)
+	handler := newTestEventHandler()
+	require.NoError(t, broker.Run(handler))
+
+	// Like the Grafana gRPC server, deny requests unless the service overrides authentication.
+	server := grpc.NewServer(grpc.UnaryInterceptor(grpcAuth.UnaryServerInterceptor(func(ctx context.Context) (context.Context, error) {
+		return ctx, status.Error(codes.Unauthenticated, "token required")
+	})))
+	c.Register(server)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	return &testClusterNode{
+		cluster:  c,
+		broker:   broker,
+		presence: presence,
+		handler:  handler,
+		member:   &Member{NodeId: nodeID, Address: listener.Addr().String()},
+	}
+}
+
+func setupTestCluster(t *testing.T, nodeIDs ...string) []*testClusterNode {
+	t.Helper()
+	nodes := make([]*testClusterNode, 0, len(nodeIDs))
+	members := make([]*Member, 0, len(nodeIDs))
+	for _, id := range nodeIDs {
+		n := newTestClusterNode(t, id)
+		nodes = append(nodes, n)
+		members = append(members, n.member)
+	}
+	for _, n := range nodes {
+		n.cluster.setPeers(members)
+		c := n.cluster
+		t.Cleanup(func() { c.setPeers(nil) })
+	}
+	return nodes
+}
+
+func TestBroker_Publish(t *testing.T) {
+	nodes := setupTestCluster(t, "a", "b", "c")
+
+	_, err := nodes[0].broker.Publish("1/grafana/broadcast/test", []byte(`{"x":1}`), centrifuge.PublishOptions{})
+	require.NoError(t, err)
+
+	for _, n := range nodes {
+		require.Eventually(t, func() bool {
+			return n.handler.numPublications("1/grafana/broadcast/test") == 1
+		}, time.Second, 10*time.Millisecond, "node %s", n.cluster.nodeID)
+	}
+}
+
+func TestBroker_PublishJoin(t *testing.T) {
+	nodes := setupTestCluster(t, "a", "b")
+
+	require.NoError(t, nodes[1].broker.PublishJoin("ch", &centrifuge.ClientInfo{ClientID: "1"}))
+
+	require.Eventually(t, func() bool {
+		nodes[0].handler.mu.Lock()
+		defer nodes[0].handler.mu.Unlock()
+		return nodes[0].handler.joins["ch"] == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestBroker_PublishControl(t *testing.T) {
+	t.Run("to all nodes", func(t *testing.T) {
+		nodes := setupTestCluster(t, "a", "b", "c")
+		require.NoError(t, nodes[0].broker.PublishControl([]byte("ping"), "", ""))
+		for _, n := range nodes {
+			require.Eventually(t, func() bool {
+				return n.handler.numControls() == 1
+			}, time.Second, 10*time.Millisecond, "node %s", n.cluster.nodeID)
+		}
+	})
+
+	t.Run("to single node", func(t *testing.T) {
+		nodes := setupTestCluster(t, "a", "b", "c")
+		require.NoError(t, nodes[0].broker.PublishControl([]byte("survey"), "c", ""))
+		require.Eventually(t, func() bool {
+			return nodes[2].handler.numControls() == 1
+		}, time.Second, 10*time.Millisecond)
+		require.Equal(t, 0, nodes[0].handler.numControls())
+		require.Equal(t, 0, nodes[1].handler.numControls())
+	})
+}
+
+func TestPresenceManager_Presence(t *testing.T) {
+	nodes := setupTestCluster(t, "a", "b")
+
+	require.NoError(t, nodes[0].presence.AddPresence("ch", "c1", &centrifuge.ClientInfo{ClientID: "c1", UserID: "1"}))
+	require.NoError(t, nodes[1].presence.AddPresence("ch", "c2", &centrifuge.ClientInfo{ClientID: "c2", UserID: "1"}))
+	require.NoError(t, nodes[1].presence.AddPresence("ch", "c3", &centrifuge.ClientInfo{ClientID: "c3", UserID: "2"}))
+
+	for _, n := range nodes {
+		presence, err := n.presence.Presence("ch")
+		require.NoError(t, err)
+		require.Len(t, presence, 3)
+
+		stats, err := n.presence.PresenceStats("ch")
+		require.NoError(t, err)
+		require.Equal(t, centrifuge.PresenceStats{NumClients: 3, NumUsers: 2}, stats)
+	}
+}
+
+func TestCluster_Authentication(t *testing.T) {
+	nodes := setupTestCluster(t, "a")
+	other, err := New(Config{Token: "wrong", AdvertiseAddress: "127.0.0.1:0"}, "b", nil)
+	require.NoError(t, err)
+	p, err := newPeer(other, "a", nodes[0].member.Address)
+	require.NoError(t, err)
+	t.Cleanup(p.stop)
+	err = p.post([]*message{{Type: messageTypeControl, Data: []byte("ping")}})
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+	require.Equal(t, 0, nodes[0].handler.numControls())
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(Config{AdvertiseAddress: "127.0.0.1:10000"}, "a", nil)
+	require.ErrorIs(t, err, errNoToken)
+
+	_, err = New(Config{Token: "secret"}, "a", nil)
+	require.ErrorIs(t, err, errNoAdvertiseAddress)
+}
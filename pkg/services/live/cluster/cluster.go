@@ -0,0 +1,330 @@
+// Package cluster implements a Grafana Live HA engine which does not require
+// Redis. Live nodes discover each other through a membership table in the
+// Grafana database and exchange publications, join/leave messages, control
+// commands and presence information over a node-to-node gRPC service
+// registered on the Grafana gRPC server.
+package cluster
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+const (
+	// Max number of messages sent to a peer in one request.
+	maxBatchSize = 256
+	// Max number of messages waiting to be sent to a peer. Messages are
+	// dropped when a peer can't keep up, Live provides at most once delivery.
+	peerQueueSize = 4096
+
+	defaultHeartbeatInterval = 5 * time.Second
+	// Number of missed heartbeats after which a node is considered gone.
+	heartbeatMisses = 3
+	// Number of missed heartbeats after which a node is removed from membership.
+	staleMisses = 20
+
+	requestTimeout = 5 * time.Second
+)
+
+var (
+	logger  = log.New("live.cluster")
+	timeNow = time.Now
+
+	errNoToken            = errors.New("a shared token is required to run the Live cluster engine")
+	errNoAdvertiseAddress = errors.New("an advertise address is required to run the Live cluster engine")
+)
+
+// Config of a cluster node.
+type Config struct {
+	// AdvertiseAddress is the host:port of the gRPC server other nodes use
+	// to reach this node.
+	AdvertiseAddress string
+	// TLS enables TLS for requests to other nodes, it must be set when the
+	// gRPC server uses TLS.
+	TLS bool
+	// Token is a shared secret used to authenticate requests between nodes.
+	Token string
+	// HeartbeatInterval defines how often node refreshes its membership and
+	// the list of peers.
+	HeartbeatInterval time.Duration
+}
+
+type messageType string
+
+const (
+	messageTypePublication messageType = "publication"
+	messageTypeJoin        messageType = "join"
+	messageTypeLeave       messageType = "leave"
+	messageTypeControl     messageType = "control"
+)
+
+type message struct {
+	Type     messageType               `json:"type"`
+	Channel  string                    `json:"channel,omitempty"`
+	Data     []byte                    `json:"data,omitempty"`
+	Info     *centrifuge.ClientInfo    `json:"info,omitempty"`
+	Tags     map[string]string         `json:"tags,omitempty"`
+	Position centrifuge.StreamPosition `json:"position"`
+}
+
+// Cluster keeps track of peer nodes and transfers messages between them.
+type Cluster struct {
+	cfg             Config
+	nodeID          string
+	store           MembershipStore
+	log             log.Logger
+	broker          *Broker
+	presenceManager *PresenceManager
+
+	mu    sync.RWMutex
+	peers map[string]*peer
+}
+
+// New creates Cluster for the Live node with the provided ID. The cluster
+// refuses to start without a token, as the node-to-node service would
+// otherwise accept requests from anyone.
+func New(cfg Config, nodeID string, store MembershipStore) (*Cluster, error) {
+	if cfg.Token == "" {
+		return nil, errNoToken
+	}
+	if cfg.AdvertiseAddress == "" {
+		return nil, errNoAdvertiseAddress
+	}
+	if cfg.HeartbeatInterval <= 0 {
+		cfg.HeartbeatInterval = defaultHeartbeatInterval
+	}
+	return &Cluster{
+		cfg:    cfg,
+		nodeID: nodeID,
+		store:  store,
+		log:    logger,
+		peers:  map[string]*peer{},
+	}, nil
+}
+
+// AdvertiseAddress returns the address other nodes can use to reach a server
+// listening on listenAddress, the hostname is used when listening on all
+// interfaces.
+func AdvertiseAddress(listenAddress string) string {
+	host, port, err := net.SplitHostPort(listenAddress)
+	if err != nil {
+		return listenAddress
+	}
+	if ip := net.ParseIP(host); host != "" && (ip == nil || !ip.IsUnspecified()) {
+		return listenAddress
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return listenAddress
+	}
+	return net.JoinHostPort(hostname, port)
+}
+
+// Run registers node in cluster membership and keeps the list of peers up to
+// date until ctx is done. The node-to-node service is served by the gRPC
+// server, see Register.
+func (c *Cluster) Run(ctx context.Context) error {
+	c.heartbeat(ctx)
+	ticker := time.NewTicker(c.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			c.heartbeat(ctx)
+		case <-ctx.Done():
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := c.store.Remove(shutdownCtx, c.nodeID); err != nil {
+				c.log.Warn("Error removing node from Live cluster", "error", err)
+			}
+			c.setPeers(nil)
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *Cluster) heartbeat(ctx context.Context) {
+	if err := c.store.Heartbeat(ctx, c.nodeID, c.cfg.AdvertiseAddress); err != nil {
+		c.log.Error("Error sending Live cluster heartbeat", "error", err)
+		return
+	}
+	now := timeNow()
+	members, err := c.store.ActiveMembers(ctx, now.Add(-heartbeatMisses*c.cfg.HeartbeatInterval))
+	if err != nil {
+		c.log.Error("Error getting Live cluster members", "error", err)
+		return
+	}
+	c.setPeers(members)
+	if err := c.store.RemoveStale(ctx, now.Add(-staleMisses*c.cfg.HeartbeatInterval)); err != nil {
+		c.log.Warn("Error removing stale Live cluster members", "error", err)
+	}
+}
+
+// setPeers updates peers according to the current membership. Workers of
+// nodes which left the cluster are stopped.
+func (c *Cluster) setPeers(members []*Member) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	seen := make(map[string]struct{}, len(members))
+	for _, m := range members {
+		if m.NodeId == c.nodeID {
+			continue
+		}
+		seen[m.NodeId] = struct{}{}
+		if p, ok := c.peers[m.NodeId]; ok && p.address == m.Address {
+			continue
+		} else if ok {
+			p.stop()
+		}
+		p, err := newPeer(c, m.NodeId, m.Address)
+		if err != nil {
+			c.log.Error("Error connecting to Live cluster peer", "node", m.NodeId, "address", m.Address, "error", err)
+			delete(c.peers, m.NodeId)
+			continue
+		}
+		c.peers[m.NodeId] = p
+		go p.run()
+		c.log.Debug("Live cluster peer added", "node", m.NodeId, "address", m.Address)
+	}
+	for id, p := range c.peers {
+		if _, ok := seen[id]; !ok {
+			p.stop()
+			delete(c.peers, id)
+			c.log.Debug("Live cluster peer removed", "node", id)
+		}
+	}
+}
+
+func (c *Cluster) getPeers() []*peer {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	peers := make([]*peer, 0, len(c.peers))
+	for _, p := range c.peers {
+		peers = append(peers, p)
+	}
+	return peers
+}
+
+// broadcast sends message to all peers. When nodeID is set the message is
+// only sent to that node.
+func (c *Cluster) broadcast(msg *message, nodeID string) {
+	if nodeID != "" {
+		c.mu.RLock()
+		p, ok := c.peers[nodeID]
+		c.mu.RUnlock()
+		if ok {
+			p.send(msg)
+		}
+		return
+	}
+	for _, p := range c.getPeers() {
+		p.send(msg)
+	}
+}
+
+// peer is a remote node. Messages to a peer are sent in order by a single
+// worker goroutine.
+type peer struct {
+	cluster *Cluster
+	nodeID  string
+	address string
+	conn    *grpc.ClientConn
+	queue   chan *message
+	done    chan struct{}
+	once    sync.Once
+}
+
+func newPeer(c *Cluster, nodeID string, address string) (*peer, error) {
+	creds := insecure.NewCredentials()
+	if c.cfg.TLS {
+		creds = credentials.NewTLS(&tls.Config{MinVersion: tls.VersionTLS12})
+	}
+	// Dial does not block, the connection is established on first request.
+	conn, err := grpc.Dial(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &peer{
+		cluster: c,
+		nodeID:  nodeID,
+		address: address,
+		conn:    conn,
+		queue:   make(chan *message, peerQueueSize),
+		done:    make(chan struct{}),
+	}, nil
+}
+
+func (p *peer) send(msg *message) {
+	select {
+	case p.queue <- msg:
+	default:
+		p.cluster.log.Warn("Live cluster peer queue is full, dropping message", "node", p.nodeID, "type", msg.Type)
+	}
+}
+
+func (p *peer) stop() {
+	p.once.Do(func() {
+		close(p.done)
+		_ = p.conn.Close()
+	})
+}
+
+func (p *peer) run() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case msg := <-p.queue:
+			batch := []*message{msg}
+		drain:
+			for len(batch) < maxBatchSize {
+				select {
+				case msg := <-p.queue:
+					batch = append(batch, msg)
+				default:
+					break drain
+				}
+			}
+			if err := p.post(batch); err != nil {
+				p.cluster.log.Warn("Error sending messages to Live cluster peer", "node", p.nodeID, "count", len(batch), "error", err)
+			}
+		}
+	}
+}
+
+func (p *peer) post(batch []*message) error {
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(p.cluster.outgoingContext(context.Background()), requestTimeout)
+	defer cancel()
+	return p.conn.Invoke(ctx, fullMethodMessages, wrapperspb.Bytes(body), &emptypb.Empty{})
+}
+
+func (p *peer) presence(ctx context.Context, ch string) (map[string]*centrifuge.ClientInfo, error) {
+	resp := &wrapperspb.BytesValue{}
+	if err := p.conn.Invoke(p.cluster.outgoingContext(ctx), fullMethodPresence, wrapperspb.String(ch), resp); err != nil {
+		return nil, err
+	}
+	var presence map[string]*centrifuge.ClientInfo
+	if err := json.Unmarshal(resp.GetValue(), &presence); err != nil {
+		return nil, err
+	}
+	return presence, nil
+}
@@ -0,0 +1,126 @@
+package cluster
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// The node-to-node service is registered on the Grafana gRPC server. Messages
+// are JSON encoded and wrapped in well-known protobuf types, so no generated
+// code is needed.
+const (
+	serviceName        = "grafana.live.cluster.Cluster"
+	methodMessages     = "Messages"
+	methodPresence     = "Presence"
+	fullMethodMessages = "/" + serviceName + "/" + methodMessages
+	fullMethodPresence = "/" + serviceName + "/" + methodPresence
+
+	// tokenMetadataKey is the metadata key of the shared secret used to
+	// authenticate requests between nodes.
+	tokenMetadataKey = "x-grafana-live-cluster-token"
+)
+
+type clusterServer interface {
+	messages(ctx context.Context, req *wrapperspb.BytesValue) (*emptypb.Empty, error)
+	presence(ctx context.Context, req *wrapperspb.StringValue) (*wrapperspb.BytesValue, error)
+}
+
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*clusterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: methodMessages,
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := new(wrapperspb.BytesValue)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(clusterServer).messages(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethodMessages}
+				return interceptor(ctx, in, info, func(ctx context.Context, req any) (any, error) {
+					return srv.(clusterServer).messages(ctx, req.(*wrapperspb.BytesValue))
+				})
+			},
+		},
+		{
+			MethodName: methodPresence,
+			Handler: func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+				in := new(wrapperspb.StringValue)
+				if err := dec(in); err != nil {
+					return nil, err
+				}
+				if interceptor == nil {
+					return srv.(clusterServer).presence(ctx, in)
+				}
+				info := &grpc.UnaryServerInfo{Server: srv, FullMethod: fullMethodPresence}
+				return interceptor(ctx, in, info, func(ctx context.Context, req any) (any, error) {
+					return srv.(clusterServer).presence(ctx, req.(*wrapperspb.StringValue))
+				})
+			},
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "pkg/services/live/cluster/grpc.go",
+}
+
+// Register registers the node-to-node service on the gRPC server.
+func (c *Cluster) Register(server *grpc.Server) {
+	server.RegisterService(&serviceDesc, c)
+}
+
+// AuthFuncOverride replaces the gRPC server token authentication with a check
+// of the shared secret of the cluster.
+func (c *Cluster) AuthFuncOverride(ctx context.Context, _ string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	tokens := md.Get(tokenMetadataKey)
+	if len(tokens) != 1 || subtle.ConstantTimeCompare([]byte(tokens[0]), []byte(c.cfg.Token)) != 1 {
+		return ctx, status.Error(codes.Unauthenticated, "invalid Live cluster token")
+	}
+	return ctx, nil
+}
+
+func (c *Cluster) messages(_ context.Context, req *wrapperspb.BytesValue) (*emptypb.Empty, error) {
+	var messages []*message
+	if err := json.Unmarshal(req.GetValue(), &messages); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if c.broker == nil {
+		return nil, status.Error(codes.Unavailable, "broker not initialized")
+	}
+	for _, msg := range messages {
+		if err := c.broker.handleMessage(msg); err != nil {
+			c.log.Error("Error handling Live cluster message", "type", msg.Type, "channel", msg.Channel, "error", err)
+		}
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (c *Cluster) presence(_ context.Context, req *wrapperspb.StringValue) (*wrapperspb.BytesValue, error) {
+	if c.presenceManager == nil {
+		return nil, status.Error(codes.Unavailable, "presence manager not initialized")
+	}
+	presence, err := c.presenceManager.local.Presence(req.GetValue())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	data, err := json.Marshal(presence)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return wrapperspb.Bytes(data), nil
+}
+
+func (c *Cluster) outgoingContext(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, tokenMetadataKey, c.cfg.Token)
+}
@@ -0,0 +1,100 @@
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/centrifugal/centrifuge"
+	"golang.org/x/sync/errgroup"
+)
+
+const presenceTimeout = 2 * time.Second
+
+// PresenceManager is a centrifuge.PresenceManager which keeps presence of
+// local clients in memory and collects presence of other nodes on request.
+type PresenceManager struct {
+	cluster *Cluster
+	local   *centrifuge.MemoryPresenceManager
+}
+
+var _ centrifuge.PresenceManager = (*PresenceManager)(nil)
+
+// NewPresenceManager creates PresenceManager for the node and attaches it to the cluster.
+func NewPresenceManager(node *centrifuge.Node, c *Cluster) (*PresenceManager, error) {
+	local, err := centrifuge.NewMemoryPresenceManager(node, centrifuge.MemoryPresenceManagerConfig{})
+	if err != nil {
+		return nil, err
+	}
+	m := &PresenceManager{cluster: c, local: local}
+	c.presenceManager = m
+	return m, nil
+}
+
+// Presence returns presence of the channel across all cluster nodes. Nodes
+// which fail to respond in time are skipped.
+func (m *PresenceManager) Presence(ch string) (map[string]*centrifuge.ClientInfo, error) {
+	presence, err := m.local.Presence(ch)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*centrifuge.ClientInfo, len(presence))
+	for k, v := range presence {
+		result[k] = v
+	}
+
+	peers := m.cluster.getPeers()
+	if len(peers) == 0 {
+		return result, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), presenceTimeout)
+	defer cancel()
+
+	responses := make([]map[string]*centrifuge.ClientInfo, len(peers))
+	var g errgroup.Group
+	for i, p := range peers {
+		i, p := i, p
+		g.Go(func() error {
+			peerPresence, err := p.presence(ctx, ch)
+			if err != nil {
+				m.cluster.log.Warn("Error getting presence from Live cluster peer", "node", p.nodeID, "error", err)
+				return nil
+			}
+			responses[i] = peerPresence
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	for _, peerPresence := range responses {
+		for k, v := range peerPresence {
+			result[k] = v
+		}
+	}
+	return result, nil
+}
+
+// PresenceStats ...
+func (m *PresenceManager) PresenceStats(ch string) (centrifuge.PresenceStats, error) {
+	presence, err := m.Presence(ch)
+	if err != nil {
+		return centrifuge.PresenceStats{}, err
+	}
+	users := map[string]struct{}{}
+	for _, info := range presence {
+		users[info.UserID] = struct{}{}
+	}
+	return centrifuge.PresenceStats{
+		NumClients: len(presence),
+		NumUsers:   len(users),
+	}, nil
+}
+
+// AddPresence ...
+func (m *PresenceManager) AddPresence(ch string, clientID string, info *centrifuge.ClientInfo) error {
+	return m.local.AddPresence(ch, clientID, info)
+}
+
+// RemovePresence ...
+func (m *PresenceManager) RemovePresence(ch string, clientID string) error {
+	return m.local.RemovePresence(ch, clientID)
+}
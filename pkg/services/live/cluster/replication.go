@@ -0,0 +1,144 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/services/live/features"
+	"github.com/grafana/grafana/pkg/services/live/managedstream"
+	"github.com/grafana/grafana/pkg/services/live/model"
+)
+
+const (
+	notificationManagedStreamFrame = "live.cluster.managed_stream_frame"
+	notificationLiveMessage        = "live.cluster.live_message"
+)
+
+// Notifier sends notifications to cluster nodes, implemented by centrifuge.Node.
+type Notifier interface {
+	Notify(op string, data []byte, toNodeID string) error
+}
+
+type frameNotification struct {
+	OrgID   int64           `json:"orgId"`
+	Channel string          `json:"channel"`
+	Frame   json.RawMessage `json:"frame"`
+}
+
+// FrameCache is a managedstream.FrameCache which keeps frames in memory and
+// replicates updates to all cluster nodes, so clients subscribing to a managed
+// stream on any node get the last frame.
+type FrameCache struct {
+	*managedstream.MemoryFrameCache
+	notifier Notifier
+}
+
+var _ managedstream.FrameCache = (*FrameCache)(nil)
+
+// NewFrameCache ...
+func NewFrameCache(notifier Notifier) *FrameCache {
+	return &FrameCache{
+		MemoryFrameCache: managedstream.NewMemoryFrameCache(),
+		notifier:         notifier,
+	}
+}
+
+// Update updates the local cache and notifies other nodes.
+func (c *FrameCache) Update(ctx context.Context, orgID int64, channel string, frameJson data.FrameJSONCache) (bool, error) {
+	schemaUpdated, err := c.MemoryFrameCache.Update(ctx, orgID, channel, frameJson)
+	if err != nil {
+		return false, err
+	}
+	payload, err := json.Marshal(frameNotification{
+		OrgID:   orgID,
+		Channel: channel,
+		Frame:   frameJson.Bytes(data.IncludeAll),
+	})
+	if err != nil {
+		return false, err
+	}
+	return schemaUpdated, c.notifier.Notify(notificationManagedStreamFrame, payload, "")
+}
+
+func (c *FrameCache) handleNotification(payload []byte) error {
+	var n frameNotification
+	if err := json.Unmarshal(payload, &n); err != nil {
+		return err
+	}
+	var frame data.Frame
+	if err := json.Unmarshal(n.Frame, &frame); err != nil {
+		return err
+	}
+	frameJson, err := data.FrameToJSONCache(&frame)
+	if err != nil {
+		return err
+	}
+	_, err = c.MemoryFrameCache.Update(context.Background(), n.OrgID, n.Channel, frameJson)
+	return err
+}
+
+// LiveMessageStore is a features.LiveMessageStore which replicates saved
+// messages to all cluster nodes, so broadcast channels return the last
+// message on subscribe on any node.
+type LiveMessageStore struct {
+	store    features.LiveMessageStore
+	notifier Notifier
+}
+
+var _ features.LiveMessageStore = (*LiveMessageStore)(nil)
+
+// NewLiveMessageStore ...
+func NewLiveMessageStore(store features.LiveMessageStore, notifier Notifier) *LiveMessageStore {
+	return &LiveMessageStore{store: store, notifier: notifier}
+}
+
+// SaveLiveMessage saves message locally and notifies other nodes.
+func (s *LiveMessageStore) SaveLiveMessage(query *model.SaveLiveMessageQuery) error {
+	if err := s.store.SaveLiveMessage(query); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(query)
+	if err != nil {
+		return err
+	}
+	return s.notifier.Notify(notificationLiveMessage, payload, "")
+}
+
+// GetLiveMessage ...
+func (s *LiveMessageStore) GetLiveMessage(query *model.GetLiveMessageQuery) (model.LiveMessage, bool, error) {
+	return s.store.GetLiveMessage(query)
+}
+
+func (s *LiveMessageStore) handleNotification(payload []byte) error {
+	var query model.SaveLiveMessageQuery
+	if err := json.Unmarshal(payload, &query); err != nil {
+		return err
+	}
+	return s.store.SaveLiveMessage(&query)
+}
+
+// NotificationHandler returns centrifuge.NotificationHandler which applies
+// state replicated by other cluster nodes.
+func NotificationHandler(nodeID string, frameCache *FrameCache, messageStore *LiveMessageStore) centrifuge.NotificationHandler {
+	return func(e centrifuge.NotificationEvent) {
+		if e.FromNodeID == nodeID {
+			// Already applied locally.
+			return
+		}
+		var err error
+		switch e.Op {
+		case notificationManagedStreamFrame:
+			err = frameCache.handleNotification(e.Data)
+		case notificationLiveMessage:
+			err = messageStore.handleNotification(e.Data)
+		default:
+			return
+		}
+		if err != nil {
+			logger.Error("Error handling Live cluster notification", "op", e.Op, "node", e.FromNodeID, "error", err)
+		}
+	}
+}
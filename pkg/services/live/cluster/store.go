@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+// Member is a Grafana Live node registered in the cluster membership table.
+type Member struct {
+	Id      int64
+	NodeId  string
+	Address string
+	Updated int64
+}
+
+// TableName returns the membership table name.
+func (Member) TableName() string {
+	return "live_cluster_node"
+}
+
+// MembershipStore keeps track of running Live nodes.
+type MembershipStore interface {
+	// Heartbeat registers a node or refreshes its last seen time.
+	Heartbeat(ctx context.Context, nodeID string, address string) error
+	// ActiveMembers returns nodes which sent a heartbeat after since.
+	ActiveMembers(ctx context.Context, since time.Time) ([]*Member, error)
+	// Remove removes node from membership.
+	Remove(ctx context.Context, nodeID string) error
+	// RemoveStale removes nodes which did not send a heartbeat after olderThan.
+	RemoveStale(ctx context.Context, olderThan time.Time) error
+}
+
+type sqlMembershipStore struct {
+	db db.DB
+}
+
+// NewSQLMembershipStore returns MembershipStore backed by Grafana database.
+func NewSQLMembershipStore(db db.DB) MembershipStore {
+	return &sqlMembershipStore{db: db}
+}
+
+func (s *sqlMembershipStore) Heartbeat(ctx context.Context, nodeID string, address string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
+		member := &Member{
+			NodeId:  nodeID,
+			Address: address,
+			Updated: timeNow().Unix(),
+		}
+		affected, err := sess.Where("node_id = ?", nodeID).Cols("address", "updated").Update(member)
+		if err != nil {
+			return err
+		}
+		if affected > 0 {
+			return nil
+		}
+		_, err = sess.Insert(member)
+		return err
+	})
+}
+
+func (s *sqlMembershipStore) ActiveMembers(ctx context.Context, since time.Time) ([]*Member, error) {
+	members := make([]*Member, 0)
+	err := s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("updated >= ?", since.Unix()).Asc("node_id").Find(&members)
+	})
+	return members, err
+}
+
+func (s *sqlMembershipStore) Remove(ctx context.Context, nodeID string) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM live_cluster_node WHERE node_id = ?", nodeID)
+		return err
+	})
+}
+
+func (s *sqlMembershipStore) RemoveStale(ctx context.Context, olderThan time.Time) error {
+	return s.db.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("DELETE FROM live_cluster_node WHERE updated < ?", olderThan.Unix())
+		return err
+	})
+}
@@ -0,0 +1,46 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+)
+
+func TestIntegrationMembershipStore(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	store := NewSQLMembershipStore(db.InitTestDB(t))
+	ctx := context.Background()
+
+	origTimeNow := timeNow
+	t.Cleanup(func() { timeNow = origTimeNow })
+	now := time.Date(2023, time.October, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return now }
+
+	require.NoError(t, store.Heartbeat(ctx, "a", "10.0.0.1:3101"))
+	require.NoError(t, store.Heartbeat(ctx, "b", "10.0.0.2:3101"))
+
+	now = now.Add(time.Minute)
+	require.NoError(t, store.Heartbeat(ctx, "a", "10.0.0.3:3101"))
+
+	members, err := store.ActiveMembers(ctx, now.Add(-10*time.Second))
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+	require.Equal(t, "a", members[0].NodeId)
+	require.Equal(t, "10.0.0.3:3101", members[0].Address)
+
+	require.NoError(t, store.RemoveStale(ctx, now.Add(-10*time.Second)))
+	members, err = store.ActiveMembers(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, members, 1)
+
+	require.NoError(t, store.Remove(ctx, "a"))
+	members, err = store.ActiveMembers(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Empty(t, members)
+}
@@ -37,6 +37,8 @@ import (
 	"github.com/grafana/grafana/pkg/services/dashboards"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/grpcserver"
+	"github.com/grafana/grafana/pkg/services/live/cluster"
 	"github.com/grafana/grafana/pkg/services/live/database"
 	"github.com/grafana/grafana/pkg/services/live/features"
 	"github.com/grafana/grafana/pkg/services/live/livecontext"
@@ -78,7 +80,7 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	dataSourceCache datasources.CacheService, sqlStore db.DB, secretsService secrets.Service,
 	usageStatsService usagestats.Service, queryDataService query.Service, toggles featuremgmt.FeatureToggles,
 	accessControl accesscontrol.AccessControl, dashboardService dashboards.DashboardService, annotationsRepo annotations.Repository,
-	orgService org.Service) (*GrafanaLive, error) {
+	orgService org.Service, grpcServerProvider grpcserver.Provider) (*GrafanaLive, error) {
 	g := &GrafanaLive{
 		Cfg:                   cfg,
 		Features:              toggles,
@@ -119,7 +121,45 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	}
 	g.node = node
 
-	if g.IsHA() {
+	var clusterFrameCache *cluster.FrameCache
+	if g.isClusterHA() {
+		// Configure HA without external dependencies. In this case Centrifuge
+		// nodes discover each other through the database and are connected
+		// over a node-to-node service registered on the Grafana gRPC server.
+		// Presence is collected from all nodes on request.
+		if grpcServerProvider == nil || grpcServerProvider.IsDisabled() {
+			return nil, fmt.Errorf("live cluster HA engine requires the gRPC server, enable the %s feature toggle", featuremgmt.FlagGrpcServer)
+		}
+		if g.Cfg.GRPCServerNetwork != "tcp" {
+			return nil, fmt.Errorf("live cluster HA engine requires the gRPC server to listen on tcp network")
+		}
+		advertiseAddress := g.Cfg.LiveHAClusterAdvertiseAddress
+		if advertiseAddress == "" {
+			advertiseAddress = cluster.AdvertiseAddress(g.Cfg.GRPCServerAddress)
+		}
+		g.cluster, err = cluster.New(cluster.Config{
+			AdvertiseAddress: advertiseAddress,
+			TLS:              g.Cfg.GRPCServerTLSConfig != nil,
+			Token:            g.Cfg.LiveHAEnginePassword,
+		}, node.ID(), cluster.NewSQLMembershipStore(sqlStore))
+		if err != nil {
+			return nil, fmt.Errorf("error creating Live cluster: %w", err)
+		}
+		g.cluster.Register(grpcServerProvider.GetServer())
+
+		broker, err := cluster.NewBroker(node, g.cluster)
+		if err != nil {
+			return nil, fmt.Errorf("error creating Live cluster broker: %v", err)
+		}
+		node.SetBroker(broker)
+
+		presenceManager, err := cluster.NewPresenceManager(node, g.cluster)
+		if err != nil {
+			return nil, fmt.Errorf("error creating Live cluster presence manager: %v", err)
+		}
+		node.SetPresenceManager(presenceManager)
+		clusterFrameCache = cluster.NewFrameCache(node)
+	} else if g.IsHA() {
 		// Configure HA with Redis. In this case Centrifuge nodes
 		// will be connected over Redis PUB/SUB. Presence will work
 		// globally since kept inside Redis.
@@ -159,7 +199,13 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 	channelLocalPublisher := liveplugin.NewChannelLocalPublisher(node, nil)
 
 	var managedStreamRunner *managedstream.Runner
-	if g.IsHA() {
+	if clusterFrameCache != nil {
+		managedStreamRunner = managedstream.NewRunner(
+			g.Publish,
+			channelLocalPublisher,
+			clusterFrameCache,
+		)
+	} else if g.IsHA() {
 		redisClient := redis.NewClient(&redis.Options{
 			Addr:     g.Cfg.LiveHAEngineAddress,
 			Password: g.Cfg.LiveHAEnginePassword,
@@ -196,9 +242,15 @@ func ProvideService(plugCtxProvider *plugincontext.Provider, cfg *setting.Cfg, r
 		DashboardService: dashboardService,
 	}
 	g.storage = database.NewStorage(g.SQLStore, g.CacheService)
+	var liveMessageStore features.LiveMessageStore = g.storage
+	if clusterFrameCache != nil {
+		clusterMessageStore := cluster.NewLiveMessageStore(g.storage, node)
+		node.OnNotification(cluster.NotificationHandler(node.ID(), clusterFrameCache, clusterMessageStore))
+		liveMessageStore = clusterMessageStore
+	}
 	g.GrafanaScope.Dashboards = dash
 	g.GrafanaScope.Features["dashboard"] = dash
-	g.GrafanaScope.Features["broadcast"] = features.NewBroadcastRunner(g.storage)
+	g.GrafanaScope.Features["broadcast"] = features.NewBroadcastRunner(liveMessageStore)
 
 	g.surveyCaller = survey.NewCaller(managedStreamRunner, node)
 	err = g.surveyCaller.SetupHandlers()
@@ -386,6 +438,7 @@ type GrafanaLive struct {
 	contextGetter    *liveplugin.ContextGetter
 	runStreamManager *runstream.Manager
 	storage          *database.Storage
+	cluster          *cluster.Cluster
 
 	usageStatsService usagestats.Service
 	usageStats        usageStats
@@ -435,6 +488,12 @@ func (g *GrafanaLive) Run(ctx context.Context) error {
 		}
 	})
 
+	if g.cluster != nil {
+		eGroup.Go(func() error {
+			return g.cluster.Run(eCtx)
+		})
+	}
+
 	if g.runStreamManager != nil {
 		// Only run stream manager if GrafanaLive properly initialized.
 		eGroup.Go(func() error {
@@ -502,6 +561,10 @@ func (g *GrafanaLive) IsHA() bool {
 	return g.Cfg != nil && g.Cfg.LiveHAEngine != ""
 }
 
+func (g *GrafanaLive) isClusterHA() bool {
+	return g.Cfg != nil && g.Cfg.LiveHAEngine == "cluster"
+}
+
 func runConcurrentlyIfNeeded(ctx context.Context, semaphore chan struct{}, fn func()) error {
 	if cap(semaphore) > 1 {
 		select {
@@ -1,13 +1,18 @@
 package playlist
 
 import (
+	"encoding/json"
 	"errors"
+	"time"
 )
 
 // Typed errors
 var (
 	ErrPlaylistNotFound        = errors.New("Playlist not found")
 	ErrCommandValidationFailed = errors.New("command missing required fields")
+	ErrInvalidItemInterval     = errors.New("invalid playlist item interval")
+	ErrInvalidSchedule         = errors.New("invalid playlist schedule")
+	ErrPlaylistEmpty           = errors.New("playlist has no dashboards")
 )
 
 // Playlist model
@@ -18,6 +23,9 @@ type Playlist struct {
 	Interval string `json:"interval" db:"interval"`
 	OrgId    int64  `json:"-" db:"org_id"`
 
+	// Schedule plays other playlists at given times of day
+	Schedule *PlaylistSchedule `json:"schedule,omitempty" db:"schedule"`
+
 	// Added for kubernetes migration + synchronization
 	// Hidden from json because this is used for openapi generation
 	// Using int64 rather than time.Time to avoid database issues with time support
@@ -39,6 +47,10 @@ type PlaylistDTO struct {
 	// The ordered list of items that the playlist will iterate over.
 	Items []PlaylistItemDTO `json:"items,omitempty"`
 
+	// Schedule plays other playlists at given times of day, such as a
+	// playlist for the night shift.
+	Schedule *PlaylistSchedule `json:"schedule,omitempty"`
+
 	// Returned for k8s
 	CreatedAt int64 `json:"-"`
 
@@ -68,15 +80,96 @@ type PlaylistItemDTO struct {
 	//  dashboards behind the tag will be added to the playlist.
 	//  - dashboard_by_uid: The value is the dashboard UID
 	Value string `json:"value"`
+
+	// Interval overrides the interval of the playlist for the dashboards of
+	// the item.
+	Interval string `json:"interval,omitempty"`
+
+	// Variables overrides the template variables of the dashboards of the
+	// item, by variable name.
+	Variables map[string][]string `json:"variables,omitempty"`
+
+	// Sort orders the dashboards of dashboard_by_tag items, using the sort
+	// options of the search. They are ordered alphabetically by default.
+	Sort string `json:"sort,omitempty"`
 }
 
 type PlaylistItem struct {
-	Id         int64  `db:"id"`
-	PlaylistId int64  `db:"playlist_id"`
-	Type       string `json:"type" db:"type"`
-	Value      string `json:"value" db:"value"`
-	Order      int    `json:"order" db:"order"`
-	Title      string `json:"title" db:"title"`
+	Id         int64               `db:"id"`
+	PlaylistId int64               `db:"playlist_id"`
+	Type       string              `json:"type" db:"type"`
+	Value      string              `json:"value" db:"value"`
+	Order      int                 `json:"order" db:"order"`
+	Title      string              `json:"title" db:"title"`
+	Interval   string              `json:"interval,omitempty" db:"interval"`
+	Variables  map[string][]string `json:"variables,omitempty" db:"variables"`
+	Sort       string              `json:"sort,omitempty" db:"sort"`
+}
+
+// PlaylistSchedule plays other playlists at given times of day. The playlist
+// itself is played outside of the entries.
+type PlaylistSchedule struct {
+	// Timezone of the entries, as an IANA time zone name. UTC by default.
+	Timezone string `json:"timezone,omitempty"`
+
+	// Entries of the schedule. The first entry matching is played.
+	Entries []PlaylistScheduleEntry `json:"entries"`
+}
+
+func (s *PlaylistSchedule) FromDB(data []byte) error {
+	return json.Unmarshal(data, s)
+}
+
+func (s *PlaylistSchedule) ToDB() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+type PlaylistScheduleEntry struct {
+	// Start time of day, formatted as HH:MM.
+	Start string `json:"start"`
+
+	// End time of day, formatted as HH:MM. Entries ending before they start
+	// span midnight.
+	End string `json:"end"`
+
+	// Weekdays the entry starts on, 0 being Sunday. Every day by default.
+	Weekdays []time.Weekday `json:"weekdays,omitempty"`
+
+	// PlaylistUID is the playlist played during the entry.
+	PlaylistUID string `json:"playlistUid"`
+}
+
+// ScheduledPlaylist is the playlist played at a given time, which is the
+// requested playlist or the playlist scheduled by it.
+type ScheduledPlaylist struct {
+	Playlist *PlaylistDTO
+
+	// StartedAt is when the playlist started playing, from which the
+	// current item is computed.
+	StartedAt time.Time
+}
+
+// CurrentPlaylistItem is the dashboard every screen playing a playlist shows
+// at a given time.
+type CurrentPlaylistItem struct {
+	// PlaylistUID is the playlist played, which differs from the requested
+	// playlist when scheduled by it.
+	PlaylistUID string `json:"playlistUid"`
+
+	// Index of the dashboard among the dashboards of the playlist.
+	Index int `json:"index"`
+
+	// Count of the dashboards of the playlist.
+	Count int `json:"count"`
+
+	DashboardUID string              `json:"dashboardUid"`
+	Title        string              `json:"title"`
+	URL          string              `json:"url"`
+	Variables    map[string][]string `json:"variables,omitempty"`
+
+	// Start and end of the display of the dashboard, in milliseconds.
+	StartedAt int64 `json:"startedAt"`
+	EndsAt    int64 `json:"endsAt"`
 }
 
 type Playlists []*Playlist
@@ -86,18 +179,20 @@ type Playlists []*Playlist
 //
 
 type UpdatePlaylistCommand struct {
-	OrgId    int64          `json:"-"`
-	UID      string         `json:"uid"`
-	Name     string         `json:"name" binding:"Required"`
-	Interval string         `json:"interval"`
-	Items    []PlaylistItem `json:"items"`
+	OrgId    int64             `json:"-"`
+	UID      string            `json:"uid"`
+	Name     string            `json:"name" binding:"Required"`
+	Interval string            `json:"interval"`
+	Items    []PlaylistItem    `json:"items"`
+	Schedule *PlaylistSchedule `json:"schedule"`
 }
 
 type CreatePlaylistCommand struct {
-	Name     string         `json:"name" binding:"Required"`
-	Interval string         `json:"interval"`
-	Items    []PlaylistItem `json:"items"`
-	OrgId    int64          `json:"-"`
+	Name     string            `json:"name" binding:"Required"`
+	Interval string            `json:"interval"`
+	Items    []PlaylistItem    `json:"items"`
+	Schedule *PlaylistSchedule `json:"schedule"`
+	OrgId    int64             `json:"-"`
 	// Used to create playlists from kubectl with a known uid/name
 	UID string `json:"-"`
 }
@@ -127,3 +222,9 @@ type GetPlaylistItemsByUidQuery struct {
 	PlaylistUID string
 	OrgId       int64
 }
+
+type GetScheduledPlaylistQuery struct {
+	UID   string
+	OrgId int64
+	Now   time.Time
+}
@@ -9,6 +9,7 @@ type Service interface {
 	Update(context.Context, *UpdatePlaylistCommand) (*PlaylistDTO, error)
 	GetWithoutItems(context.Context, *GetPlaylistByUidQuery) (*Playlist, error)
 	Get(context.Context, *GetPlaylistByUidQuery) (*PlaylistDTO, error)
+	GetScheduled(context.Context, *GetScheduledPlaylistQuery) (*ScheduledPlaylist, error)
 	Search(context.Context, *GetPlaylistsQuery) (Playlists, error)
 	Delete(ctx context.Context, cmd *DeletePlaylistCommand) error
 }
@@ -2,6 +2,12 @@ package playlistimpl
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/tracing"
@@ -27,12 +33,18 @@ func ProvideService(db db.DB, tracer tracing.Tracer) playlist.Service {
 func (s *Service) Create(ctx context.Context, cmd *playlist.CreatePlaylistCommand) (*playlist.Playlist, error) {
 	ctx, span := s.tracer.Start(ctx, "playlists.Create")
 	defer span.End()
+	if err := s.validatePlaylist(ctx, cmd.OrgId, cmd.UID, cmd.Items, cmd.Schedule); err != nil {
+		return nil, err
+	}
 	return s.store.Insert(ctx, cmd)
 }
 
 func (s *Service) Update(ctx context.Context, cmd *playlist.UpdatePlaylistCommand) (*playlist.PlaylistDTO, error) {
 	ctx, span := s.tracer.Start(ctx, "playlists.Update")
 	defer span.End()
+	if err := s.validatePlaylist(ctx, cmd.OrgId, cmd.UID, cmd.Items, cmd.Schedule); err != nil {
+		return nil, err
+	}
 	return s.store.Update(ctx, cmd)
 }
 
@@ -56,10 +68,16 @@ func (s *Service) Get(ctx context.Context, q *playlist.GetPlaylistByUidQuery) (*
 	if err != nil {
 		return nil, err
 	}
+	slices.SortStableFunc(rawItems, func(a, b playlist.PlaylistItem) int {
+		return a.Order - b.Order
+	})
 	items := make([]playlist.PlaylistItemDTO, len(rawItems))
 	for i := 0; i < len(rawItems); i++ {
 		items[i].Type = rawItems[i].Type
 		items[i].Value = rawItems[i].Value
+		items[i].Interval = rawItems[i].Interval
+		items[i].Variables = rawItems[i].Variables
+		items[i].Sort = rawItems[i].Sort
 
 		// Add the unused title to the result
 		title := rawItems[i].Title
@@ -73,12 +91,54 @@ func (s *Service) Get(ctx context.Context, q *playlist.GetPlaylistByUidQuery) (*
 		Name:      v.Name,
 		Interval:  v.Interval,
 		Items:     items,
+		Schedule:  v.Schedule,
 		CreatedAt: v.CreatedAt,
 		UpdatedAt: v.UpdatedAt,
 		OrgID:     v.OrgId,
 	}, nil
 }
 
+// GetScheduled returns the playlist played at the given time, which is the
+// playlist scheduled by the requested playlist when an entry of its schedule
+// is playing.
+func (s *Service) GetScheduled(ctx context.Context, q *playlist.GetScheduledPlaylistQuery) (*playlist.ScheduledPlaylist, error) {
+	ctx, span := s.tracer.Start(ctx, "playlists.GetScheduled")
+	defer span.End()
+	dto, err := s.Get(ctx, &playlist.GetPlaylistByUidQuery{UID: q.UID, OrgId: q.OrgId})
+	if err != nil {
+		return nil, err
+	}
+
+	// screens playing the playlist start from its first dashboard when it is updated
+	scheduled := &playlist.ScheduledPlaylist{Playlist: dto, StartedAt: time.UnixMilli(dto.UpdatedAt)}
+	if dto.Schedule == nil {
+		return scheduled, nil
+	}
+
+	entry, startedAt, err := dto.Schedule.ActiveEntry(q.Now)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return scheduled, nil
+	}
+
+	// the schedules of scheduled playlists are ignored, and the playlist
+	// keeps playing when the scheduled playlist has been deleted since
+	if entry.PlaylistUID == dto.Uid {
+		return scheduled, nil
+	}
+	entryDTO, err := s.Get(ctx, &playlist.GetPlaylistByUidQuery{UID: entry.PlaylistUID, OrgId: q.OrgId})
+	if err != nil {
+		if errors.Is(err, playlist.ErrPlaylistNotFound) {
+			return scheduled, nil
+		}
+		return nil, err
+	}
+
+	return &playlist.ScheduledPlaylist{Playlist: entryDTO, StartedAt: startedAt}, nil
+}
+
 func (s *Service) Search(ctx context.Context, q *playlist.GetPlaylistsQuery) (playlist.Playlists, error) {
 	ctx, span := s.tracer.Start(ctx, "playlists.Search")
 	defer span.End()
@@ -90,3 +150,36 @@ func (s *Service) Delete(ctx context.Context, cmd *playlist.DeletePlaylistComman
 	defer span.End()
 	return s.store.Delete(ctx, cmd)
 }
+
+// validatePlaylist checks the intervals of the items and the schedule of a
+// playlist, whose entries must play other existing playlists of the org.
+func (s *Service) validatePlaylist(ctx context.Context, orgID int64, uid string, items []playlist.PlaylistItem, schedule *playlist.PlaylistSchedule) error {
+	for i, item := range items {
+		if item.Interval == "" {
+			continue
+		}
+		if d, err := gtime.ParseDuration(item.Interval); err != nil || d <= 0 {
+			return fmt.Errorf("%w: item %d has an invalid interval %q", playlist.ErrInvalidItemInterval, i, item.Interval)
+		}
+	}
+
+	if schedule == nil {
+		return nil
+	}
+	if err := schedule.Validate(); err != nil {
+		return err
+	}
+	for i, entry := range schedule.Entries {
+		if entry.PlaylistUID == uid {
+			return fmt.Errorf("%w: entry %d plays the playlist itself", playlist.ErrInvalidSchedule, i)
+		}
+		_, err := s.store.Get(ctx, &playlist.GetPlaylistByUidQuery{UID: entry.PlaylistUID, OrgId: orgID})
+		if err != nil {
+			if errors.Is(err, playlist.ErrPlaylistNotFound) {
+				return fmt.Errorf("%w: entry %d plays playlist %q which does not exist", playlist.ErrInvalidSchedule, i, entry.PlaylistUID)
+			}
+			return err
+		}
+	}
+	return nil
+}
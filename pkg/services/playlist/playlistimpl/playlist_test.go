@@ -0,0 +1,97 @@
+package playlistimpl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	"github.com/grafana/grafana/pkg/services/playlist"
+)
+
+func TestIntegrationGetScheduled(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	svc := ProvideService(db.InitTestDB(t), tracing.InitializeTracerForTest())
+	items := []playlist.PlaylistItem{{Value: "abc", Type: "dashboard_by_uid"}}
+
+	night, err := svc.Create(context.Background(), &playlist.CreatePlaylistCommand{Name: "Night", Interval: "10m", OrgId: 1, Items: items})
+	require.NoError(t, err)
+	day, err := svc.Create(context.Background(), &playlist.CreatePlaylistCommand{Name: "Day", Interval: "1m", OrgId: 1, Items: items,
+		Schedule: &playlist.PlaylistSchedule{
+			Entries: []playlist.PlaylistScheduleEntry{{Start: "20:00", End: "08:00", PlaylistUID: night.UID}},
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("plays the playlist outside of its schedule", func(t *testing.T) {
+		scheduled, err := svc.GetScheduled(context.Background(), &playlist.GetScheduledPlaylistQuery{
+			UID: day.UID, OrgId: 1, Now: time.Date(2023, 10, 18, 12, 0, 0, 0, time.UTC),
+		})
+		require.NoError(t, err)
+		require.Equal(t, day.UID, scheduled.Playlist.Uid)
+		require.Equal(t, time.UnixMilli(day.UpdatedAt), scheduled.StartedAt)
+	})
+
+	t.Run("plays the scheduled playlist from the start of the schedule entry", func(t *testing.T) {
+		scheduled, err := svc.GetScheduled(context.Background(), &playlist.GetScheduledPlaylistQuery{
+			UID: day.UID, OrgId: 1, Now: time.Date(2023, 10, 18, 22, 0, 0, 0, time.UTC),
+		})
+		require.NoError(t, err)
+		require.Equal(t, night.UID, scheduled.Playlist.Uid)
+		require.Len(t, scheduled.Playlist.Items, 1)
+		require.Equal(t, time.Date(2023, 10, 18, 20, 0, 0, 0, time.UTC), scheduled.StartedAt)
+	})
+
+	t.Run("returns an error for invalid schedules", func(t *testing.T) {
+		_, err := svc.Create(context.Background(), &playlist.CreatePlaylistCommand{Name: "Invalid", OrgId: 1,
+			Schedule: &playlist.PlaylistSchedule{Entries: []playlist.PlaylistScheduleEntry{{Start: "20:00", End: "08:00"}}},
+		})
+		require.ErrorIs(t, err, playlist.ErrInvalidSchedule)
+
+		_, err = svc.Create(context.Background(), &playlist.CreatePlaylistCommand{Name: "Invalid", OrgId: 1,
+			Items: []playlist.PlaylistItem{{Value: "abc", Type: "dashboard_by_uid", Interval: "soon"}},
+		})
+		require.ErrorIs(t, err, playlist.ErrInvalidItemInterval)
+	})
+
+	t.Run("returns an error for schedules playing missing playlists or the playlist itself", func(t *testing.T) {
+		_, err := svc.Create(context.Background(), &playlist.CreatePlaylistCommand{Name: "Invalid", OrgId: 1,
+			Schedule: &playlist.PlaylistSchedule{Entries: []playlist.PlaylistScheduleEntry{{Start: "20:00", End: "08:00", PlaylistUID: "missing"}}},
+		})
+		require.ErrorIs(t, err, playlist.ErrInvalidSchedule)
+
+		_, err = svc.Create(context.Background(), &playlist.CreatePlaylistCommand{Name: "Invalid", OrgId: 2,
+			Schedule: &playlist.PlaylistSchedule{Entries: []playlist.PlaylistScheduleEntry{{Start: "20:00", End: "08:00", PlaylistUID: night.UID}}},
+		})
+		require.ErrorIs(t, err, playlist.ErrInvalidSchedule)
+
+		_, err = svc.Update(context.Background(), &playlist.UpdatePlaylistCommand{UID: day.UID, Name: "Day", OrgId: 1, Items: items,
+			Schedule: &playlist.PlaylistSchedule{Entries: []playlist.PlaylistScheduleEntry{{Start: "20:00", End: "08:00", PlaylistUID: day.UID}}},
+		})
+		require.ErrorIs(t, err, playlist.ErrInvalidSchedule)
+	})
+
+	t.Run("plays the playlist when the scheduled playlist has been deleted", func(t *testing.T) {
+		evening, err := svc.Create(context.Background(), &playlist.CreatePlaylistCommand{Name: "Evening", OrgId: 1, Items: items})
+		require.NoError(t, err)
+		office, err := svc.Create(context.Background(), &playlist.CreatePlaylistCommand{Name: "Office", OrgId: 1, Items: items,
+			Schedule: &playlist.PlaylistSchedule{
+				Entries: []playlist.PlaylistScheduleEntry{{Start: "18:00", End: "23:00", PlaylistUID: evening.UID}},
+			},
+		})
+		require.NoError(t, err)
+		require.NoError(t, svc.Delete(context.Background(), &playlist.DeletePlaylistCommand{UID: evening.UID, OrgId: 1}))
+
+		scheduled, err := svc.GetScheduled(context.Background(), &playlist.GetScheduledPlaylistQuery{
+			UID: office.UID, OrgId: 1, Now: time.Date(2023, 10, 18, 20, 0, 0, 0, time.UTC),
+		})
+		require.NoError(t, err)
+		require.Equal(t, office.UID, scheduled.Playlist.Uid)
+	})
+}
@@ -103,6 +103,43 @@ func testIntegrationPlaylistDataAccess(t *testing.T, fn getStore) {
 		require.NoError(t, err)
 	})
 
+	t.Run("Can create playlist with item overrides and schedule", func(t *testing.T) {
+		items := []playlist.PlaylistItem{
+			{Title: "graphite", Value: "graphite", Type: "dashboard_by_tag", Sort: "alpha-desc"},
+			{Title: "Backend response times", Value: "abc", Type: "dashboard_by_uid", Interval: "30s", Variables: map[string][]string{"region": {"eu"}}},
+		}
+		schedule := &playlist.PlaylistSchedule{
+			Timezone: "Europe/Paris",
+			Entries:  []playlist.PlaylistScheduleEntry{{Start: "20:00", End: "08:00", PlaylistUID: "night"}},
+		}
+		cmd := playlist.CreatePlaylistCommand{Name: "NOC", Interval: "5m", OrgId: 1, Items: items, Schedule: schedule}
+		p, err := playlistStore.Insert(context.Background(), &cmd)
+		require.NoError(t, err)
+
+		pl, err := playlistStore.Get(context.Background(), &playlist.GetPlaylistByUidQuery{UID: p.UID, OrgId: 1})
+		require.NoError(t, err)
+		require.Equal(t, schedule, pl.Schedule)
+
+		storedPlaylistItems, err := playlistStore.GetItems(context.Background(), &playlist.GetPlaylistItemsByUidQuery{PlaylistUID: p.UID, OrgId: 1})
+		require.NoError(t, err)
+		require.Len(t, storedPlaylistItems, 2)
+		require.Equal(t, "alpha-desc", storedPlaylistItems[0].Sort)
+		require.Equal(t, "30s", storedPlaylistItems[1].Interval)
+		require.Equal(t, map[string][]string{"region": {"eu"}}, storedPlaylistItems[1].Variables)
+
+		t.Run("Can remove the schedule", func(t *testing.T) {
+			_, err := playlistStore.Update(context.Background(), &playlist.UpdatePlaylistCommand{Name: "NOC", OrgId: 1, UID: p.UID, Interval: "5m", Items: items})
+			require.NoError(t, err)
+
+			pl, err := playlistStore.Get(context.Background(), &playlist.GetPlaylistByUidQuery{UID: p.UID, OrgId: 1})
+			require.NoError(t, err)
+			require.Nil(t, pl.Schedule)
+		})
+
+		err = playlistStore.Delete(context.Background(), &playlist.DeletePlaylistCommand{UID: p.UID, OrgId: 1})
+		require.NoError(t, err)
+	})
+
 	t.Run("Search playlist", func(t *testing.T) {
 		items := []playlist.PlaylistItem{
 			{Title: "graphite", Value: "graphite", Type: "dashboard_by_tag"},
@@ -34,6 +34,7 @@ func (s *sqlStore) Insert(ctx context.Context, cmd *playlist.CreatePlaylistComma
 			Interval:  cmd.Interval,
 			OrgId:     cmd.OrgId,
 			UID:       cmd.UID,
+			Schedule:  cmd.Schedule,
 			CreatedAt: ts,
 			UpdatedAt: ts,
 		}
@@ -51,6 +52,9 @@ func (s *sqlStore) Insert(ctx context.Context, cmd *playlist.CreatePlaylistComma
 				Value:      item.Value,
 				Order:      order + 1,
 				Title:      item.Title,
+				Interval:   item.Interval,
+				Variables:  item.Variables,
+				Sort:       item.Sort,
 			})
 		}
 
@@ -69,6 +73,7 @@ func (s *sqlStore) Update(ctx context.Context, cmd *playlist.UpdatePlaylistComma
 			OrgId:    cmd.OrgId,
 			Name:     cmd.Name,
 			Interval: cmd.Interval,
+			Schedule: cmd.Schedule,
 		}
 
 		existingPlaylist := playlist.Playlist{UID: cmd.UID, OrgId: cmd.OrgId}
@@ -84,9 +89,10 @@ func (s *sqlStore) Update(ctx context.Context, cmd *playlist.UpdatePlaylistComma
 			Uid:      p.UID,
 			Name:     p.Name,
 			Interval: p.Interval,
+			Schedule: p.Schedule,
 		}
 
-		_, err = sess.Where("id=?", p.Id).Cols("name", "interval", "updated_at").Update(&p)
+		_, err = sess.Where("id=?", p.Id).Cols("name", "interval", "schedule", "updated_at").Nullable("schedule").Update(&p)
 		if err != nil {
 			return err
 		}
@@ -107,6 +113,9 @@ func (s *sqlStore) Update(ctx context.Context, cmd *playlist.UpdatePlaylistComma
 				Value:      item.Value,
 				Order:      index + 1,
 				Title:      item.Title,
+				Interval:   item.Interval,
+				Variables:  item.Variables,
+				Sort:       item.Sort,
 			})
 		}
 
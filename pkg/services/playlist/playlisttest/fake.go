@@ -11,6 +11,7 @@ type FakePlaylistService struct {
 	ExpectedPlaylistDTO   *playlist.PlaylistDTO
 	ExpectedPlaylistItems []playlist.PlaylistItem
 	ExpectedPlaylists     playlist.Playlists
+	ExpectedScheduled     *playlist.ScheduledPlaylist
 	ExpectedError         error
 }
 
@@ -34,6 +35,10 @@ func (f *FakePlaylistService) GetItems(context.Context, *playlist.GetPlaylistIte
 	return f.ExpectedPlaylistItems, f.ExpectedError
 }
 
+func (f *FakePlaylistService) GetScheduled(context.Context, *playlist.GetScheduledPlaylistQuery) (*playlist.ScheduledPlaylist, error) {
+	return f.ExpectedScheduled, f.ExpectedError
+}
+
 func (f *FakePlaylistService) Search(context.Context, *playlist.GetPlaylistsQuery) (playlist.Playlists, error) {
 	return f.ExpectedPlaylists, f.ExpectedError
 }
@@ -0,0 +1,164 @@
+package playlist
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+)
+
+// DefaultInterval is the display duration of playlists without a valid
+// interval, like in the frontend.
+const DefaultInterval = 5 * time.Minute
+
+const timeOfDayLayout = "15:04"
+
+// Validate checks the time zone, times of day and weekdays of the schedule.
+func (s *PlaylistSchedule) Validate() error {
+	if _, err := s.location(); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSchedule, err)
+	}
+
+	for i, entry := range s.Entries {
+		if entry.PlaylistUID == "" {
+			return fmt.Errorf("%w: entry %d has no playlist", ErrInvalidSchedule, i)
+		}
+		if _, _, err := parseTimeOfDay(entry.Start); err != nil {
+			return fmt.Errorf("%w: entry %d has an invalid start %q", ErrInvalidSchedule, i, entry.Start)
+		}
+		if _, _, err := parseTimeOfDay(entry.End); err != nil {
+			return fmt.Errorf("%w: entry %d has an invalid end %q", ErrInvalidSchedule, i, entry.End)
+		}
+		for _, weekday := range entry.Weekdays {
+			if weekday < time.Sunday || weekday > time.Saturday {
+				return fmt.Errorf("%w: entry %d has an invalid weekday %d", ErrInvalidSchedule, i, weekday)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ActiveEntry returns the first entry of the schedule playing at the given
+// time along with the time it started, or nil when no entry is playing.
+func (s *PlaylistSchedule) ActiveEntry(now time.Time) (*PlaylistScheduleEntry, time.Time, error) {
+	loc, err := s.location()
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	now = now.In(loc)
+	year, month, day := now.Date()
+
+	for i := range s.Entries {
+		entry := &s.Entries[i]
+		startHour, startMinute, err := parseTimeOfDay(entry.Start)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		endHour, endMinute, err := parseTimeOfDay(entry.End)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		spansMidnight := endHour*60+endMinute <= startHour*60+startMinute
+
+		// the entry playing now started today, or yesterday when spanning midnight
+		for daysAgo := 0; daysAgo <= 1; daysAgo++ {
+			startedAt := time.Date(year, month, day-daysAgo, startHour, startMinute, 0, 0, loc)
+			endsAt := time.Date(year, month, day-daysAgo, endHour, endMinute, 0, 0, loc)
+			if spansMidnight {
+				endsAt = endsAt.AddDate(0, 0, 1)
+			}
+
+			if entry.startsOn(startedAt.Weekday()) && !now.Before(startedAt) && now.Before(endsAt) {
+				return entry, startedAt, nil
+			}
+		}
+	}
+
+	return nil, time.Time{}, nil
+}
+
+func (s *PlaylistSchedule) location() (*time.Location, error) {
+	if s.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.Timezone)
+}
+
+func (e *PlaylistScheduleEntry) startsOn(weekday time.Weekday) bool {
+	if len(e.Weekdays) == 0 {
+		return true
+	}
+	for _, w := range e.Weekdays {
+		if w == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+func parseTimeOfDay(value string) (int, int, error) {
+	t, err := time.Parse(timeOfDayLayout, value)
+	if err != nil {
+		return 0, 0, err
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// ItemInterval returns the display duration of the dashboards of an item,
+// which is the interval of the item or else the interval of the playlist.
+func ItemInterval(playlistInterval string, item PlaylistItemDTO) time.Duration {
+	for _, interval := range []string{item.Interval, playlistInterval} {
+		if d, err := gtime.ParseDuration(interval); err == nil && d > 0 {
+			return d
+		}
+	}
+	return DefaultInterval
+}
+
+// CurrentIndex returns the index of the dashboard displayed at the given time
+// when the dashboards are displayed in a loop for their durations since the
+// start, along with the time its display started. Every screen playing the
+// same playlist gets the same dashboard.
+func CurrentIndex(durations []time.Duration, start time.Time, now time.Time) (int, time.Time) {
+	var cycle time.Duration
+	for _, d := range durations {
+		cycle += d
+	}
+	if cycle <= 0 || now.Before(start) {
+		return 0, start
+	}
+
+	elapsed := now.Sub(start) % cycle
+	startedAt := now.Add(-elapsed)
+	for i, d := range durations {
+		if elapsed < d {
+			return i, startedAt
+		}
+		elapsed -= d
+		startedAt = startedAt.Add(d)
+	}
+
+	return 0, startedAt
+}
+
+// OffsetIndex returns the index of the dashboard displayed offset dashboards
+// after the one at index, or before it when negative, along with the time its
+// display starts in the loop. Whole loops of the offset are ignored, so the
+// returned display is less than a loop away.
+func OffsetIndex(durations []time.Duration, index int, startedAt time.Time, offset int) (int, time.Time) {
+	n := len(durations)
+	if n == 0 {
+		return index, startedAt
+	}
+	offset %= n
+	for ; offset > 0; offset-- {
+		startedAt = startedAt.Add(durations[index])
+		index = (index + 1) % n
+	}
+	for ; offset < 0; offset++ {
+		index = (index - 1 + n) % n
+		startedAt = startedAt.Add(-durations[index])
+	}
+	return index, startedAt
+}
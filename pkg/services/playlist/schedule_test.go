@@ -0,0 +1,159 @@
+package playlist
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaylistScheduleActiveEntry(t *testing.T) {
+	schedule := &PlaylistSchedule{
+		Timezone: "Europe/Paris",
+		Entries: []PlaylistScheduleEntry{
+			{Start: "08:00", End: "12:00", Weekdays: []time.Weekday{time.Saturday, time.Sunday}, PlaylistUID: "weekend"},
+			{Start: "20:00", End: "08:00", PlaylistUID: "night"},
+		},
+	}
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		playlist  string
+		startedAt time.Time
+	}{
+		{
+			name: "no entry playing",
+			now:  time.Date(2023, 10, 18, 10, 0, 0, 0, paris),
+		},
+		{
+			name:      "entry playing on its weekdays",
+			now:       time.Date(2023, 10, 21, 10, 0, 0, 0, paris),
+			playlist:  "weekend",
+			startedAt: time.Date(2023, 10, 21, 8, 0, 0, 0, paris),
+		},
+		{
+			name:      "entry spanning midnight playing before midnight",
+			now:       time.Date(2023, 10, 18, 23, 0, 0, 0, paris),
+			playlist:  "night",
+			startedAt: time.Date(2023, 10, 18, 20, 0, 0, 0, paris),
+		},
+		{
+			name:      "entry spanning midnight playing after midnight",
+			now:       time.Date(2023, 10, 19, 7, 59, 0, 0, paris),
+			playlist:  "night",
+			startedAt: time.Date(2023, 10, 18, 20, 0, 0, 0, paris),
+		},
+		{
+			name:      "times of day in the time zone of the schedule",
+			now:       time.Date(2023, 10, 18, 18, 30, 0, 0, time.UTC),
+			playlist:  "night",
+			startedAt: time.Date(2023, 10, 18, 20, 0, 0, 0, paris),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry, startedAt, err := schedule.ActiveEntry(tt.now)
+			require.NoError(t, err)
+			if tt.playlist == "" {
+				assert.Nil(t, entry)
+				return
+			}
+			require.NotNil(t, entry)
+			assert.Equal(t, tt.playlist, entry.PlaylistUID)
+			assert.True(t, tt.startedAt.Equal(startedAt), "started at %s", startedAt)
+		})
+	}
+}
+
+func TestPlaylistScheduleValidate(t *testing.T) {
+	valid := PlaylistScheduleEntry{Start: "08:00", End: "20:00", PlaylistUID: "day"}
+
+	tests := []struct {
+		name     string
+		schedule PlaylistSchedule
+		valid    bool
+	}{
+		{name: "valid", schedule: PlaylistSchedule{Timezone: "America/New_York", Entries: []PlaylistScheduleEntry{valid}}, valid: true},
+		{name: "invalid time zone", schedule: PlaylistSchedule{Timezone: "Mars/Olympus", Entries: []PlaylistScheduleEntry{valid}}},
+		{name: "missing playlist", schedule: PlaylistSchedule{Entries: []PlaylistScheduleEntry{{Start: "08:00", End: "20:00"}}}},
+		{name: "invalid start", schedule: PlaylistSchedule{Entries: []PlaylistScheduleEntry{{Start: "8am", End: "20:00", PlaylistUID: "day"}}}},
+		{name: "invalid end", schedule: PlaylistSchedule{Entries: []PlaylistScheduleEntry{{Start: "08:00", End: "24:00", PlaylistUID: "day"}}}},
+		{name: "invalid weekday", schedule: PlaylistSchedule{Entries: []PlaylistScheduleEntry{{Start: "08:00", End: "20:00", Weekdays: []time.Weekday{7}, PlaylistUID: "day"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schedule.Validate()
+			if tt.valid {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrInvalidSchedule)
+		})
+	}
+}
+
+func TestItemInterval(t *testing.T) {
+	assert.Equal(t, 30*time.Second, ItemInterval("5m", PlaylistItemDTO{Interval: "30s"}))
+	assert.Equal(t, 5*time.Minute, ItemInterval("5m", PlaylistItemDTO{}))
+	assert.Equal(t, DefaultInterval, ItemInterval("invalid", PlaylistItemDTO{}))
+}
+
+func TestCurrentIndex(t *testing.T) {
+	start := time.Date(2023, 10, 18, 8, 0, 0, 0, time.UTC)
+	durations := []time.Duration{time.Minute, 2 * time.Minute, time.Minute}
+
+	tests := []struct {
+		name      string
+		now       time.Time
+		index     int
+		startedAt time.Time
+	}{
+		{name: "first item at the start", now: start, index: 0, startedAt: start},
+		{name: "second item", now: start.Add(90 * time.Second), index: 1, startedAt: start.Add(time.Minute)},
+		{name: "last item", now: start.Add(3 * time.Minute), index: 2, startedAt: start.Add(3 * time.Minute)},
+		{name: "next loop", now: start.Add(9 * time.Minute), index: 1, startedAt: start.Add(9 * time.Minute)},
+		{name: "before the start", now: start.Add(-time.Minute), index: 0, startedAt: start},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, startedAt := CurrentIndex(durations, start, tt.now)
+			assert.Equal(t, tt.index, index)
+			assert.Equal(t, tt.startedAt, startedAt)
+		})
+	}
+}
+
+func TestOffsetIndex(t *testing.T) {
+	start := time.Date(2023, 10, 18, 8, 0, 0, 0, time.UTC)
+	durations := []time.Duration{time.Minute, 2 * time.Minute, time.Minute}
+
+	tests := []struct {
+		name      string
+		offset    int
+		index     int
+		startedAt time.Time
+	}{
+		{name: "no offset", offset: 0, index: 1, startedAt: start},
+		{name: "next item", offset: 1, index: 2, startedAt: start.Add(2 * time.Minute)},
+		{name: "next loop", offset: 2, index: 0, startedAt: start.Add(3 * time.Minute)},
+		{name: "previous item", offset: -1, index: 0, startedAt: start.Add(-time.Minute)},
+		{name: "previous loop", offset: -2, index: 2, startedAt: start.Add(-2 * time.Minute)},
+		{name: "many loops after", offset: 3*1000000000 + 1, index: 2, startedAt: start.Add(2 * time.Minute)},
+		{name: "many loops before", offset: -3*1000000000 - 1, index: 0, startedAt: start.Add(-time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			index, startedAt := OffsetIndex(durations, 1, start, tt.offset)
+			assert.Equal(t, tt.index, index)
+			assert.Equal(t, tt.startedAt, startedAt)
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package pluginsintegration
 
 import (
+	"context"
+
 	"github.com/google/wire"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/prometheus/client_golang/prometheus"
 
+	"github.com/grafana/grafana/pkg/infra/appcontext"
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/plugins/auth"
@@ -28,7 +32,9 @@ import (
 	"github.com/grafana/grafana/pkg/plugins/manager/sources"
 	"github.com/grafana/grafana/pkg/plugins/pluginscdn"
 	"github.com/grafana/grafana/pkg/plugins/repo"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/caching"
+	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	"github.com/grafana/grafana/pkg/services/oauthtoken"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/angulardetectorsprovider"
@@ -48,7 +54,9 @@ import (
 	pluginSettings "github.com/grafana/grafana/pkg/services/pluginsintegration/pluginsettings/service"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/pluginstore"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/serviceregistration"
+	"github.com/grafana/grafana/pkg/services/queryrecording"
 	"github.com/grafana/grafana/pkg/setting"
+	testdatasource "github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource"
 )
 
 // WireSet provides a wire.ProviderSet of plugin providers.
@@ -62,6 +70,7 @@ var WireSet = wire.NewSet(
 	process.ProvideService,
 	wire.Bind(new(process.Manager), new(*process.Service)),
 	coreplugin.ProvideCoreRegistry,
+	ProvideTestDataService,
 	pluginscdn.ProvideService,
 	assetpath.ProvideService,
 
@@ -194,3 +203,40 @@ func CreateMiddlewares(cfg *setting.Cfg, oAuthTokenService oauthtoken.OAuthToken
 
 	return middlewares
 }
+
+// ProvideTestDataService returns the TestData data source with the directories
+// of the server, so that they can't be changed in the data source settings.
+func ProvideTestDataService(cfg *setting.Cfg, ac accesscontrol.AccessControl) *testdatasource.Service {
+	return testdatasource.NewService(testdatasource.Settings{
+		RecordingsPath:  queryrecording.RecordingsPath(cfg),
+		SimulationsPath: cfg.PluginSettings[coreplugin.TestData]["simulations_path"],
+		Authorizer:      &testDataAuthorizer{ac: ac},
+	})
+}
+
+// testDataAuthorizer checks the permissions of the signed in user of the
+// requests of the TestData data source.
+type testDataAuthorizer struct {
+	ac accesscontrol.AccessControl
+}
+
+func (a *testDataAuthorizer) CanQueryDatasource(ctx context.Context, pCtx backend.PluginContext, uid string) (bool, error) {
+	return a.evaluate(ctx, pCtx, accesscontrol.EvalPermission(datasources.ActionQuery, datasources.ScopeProvider.GetResourceScopeUID(uid)))
+}
+
+// CanManageSimulations allows the users who can edit the TestData data source
+// to register and delete its custom simulations.
+func (a *testDataAuthorizer) CanManageSimulations(ctx context.Context, pCtx backend.PluginContext) (bool, error) {
+	if pCtx.DataSourceInstanceSettings == nil {
+		return false, nil
+	}
+	return a.evaluate(ctx, pCtx, accesscontrol.EvalPermission(datasources.ActionWrite, datasources.ScopeProvider.GetResourceScopeUID(pCtx.DataSourceInstanceSettings.UID)))
+}
+
+func (a *testDataAuthorizer) evaluate(ctx context.Context, pCtx backend.PluginContext, evaluator accesscontrol.Evaluator) (bool, error) {
+	usr, err := appcontext.User(ctx)
+	if err != nil || usr.GetOrgID() != pCtx.OrgID {
+		return false, nil
+	}
+	return a.ac.Evaluate(ctx, usr, evaluator)
+}
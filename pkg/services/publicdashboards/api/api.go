@@ -15,6 +15,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/web"
 )
 
@@ -24,6 +25,8 @@ type Api struct {
 	AccessControl          accesscontrol.AccessControl
 	Features               *featuremgmt.FeatureManager
 	Log                    log.Logger
+	// TrustedProxies are the IPs or CIDR ranges of the proxies whose client IP headers are trusted
+	TrustedProxies []string
 }
 
 func ProvideApi(
@@ -31,6 +34,7 @@ func ProvideApi(
 	rr routing.RouteRegister,
 	ac accesscontrol.AccessControl,
 	features *featuremgmt.FeatureManager,
+	cfg *setting.Cfg,
 ) *Api {
 	api := &Api{
 		PublicDashboardService: pd,
@@ -38,6 +42,7 @@ func ProvideApi(
 		AccessControl:          ac,
 		Features:               features,
 		Log:                    log.New("publicdashboards.api"),
+		TrustedProxies:         cfg.PublicDashboardsTrustedProxies,
 	}
 
 	// attach api if PublicDashboards feature flag is enabled
@@ -55,10 +60,12 @@ func (api *Api) RegisterAPIEndpoints() {
 	// because it is deeply dependent on the HTTPServer.Index() method and would result in a
 	// circular dependency
 
-	api.RouteRegister.Get("/api/public/dashboards/:accessToken", routing.Wrap(api.ViewPublicDashboard))
-	api.RouteRegister.Get("/api/public/dashboards/:accessToken/annotations", routing.Wrap(api.GetPublicAnnotations))
+	allowedClientIP := RequiresAllowedClientIP(api.PublicDashboardService, api.TrustedProxies)
 
-	api.RouteRegister.Post("/api/public/dashboards/:accessToken/panels/:panelId/query", routing.Wrap(api.QueryPublicDashboard))
+	api.RouteRegister.Get("/api/public/dashboards/:accessToken", allowedClientIP, routing.Wrap(api.ViewPublicDashboard))
+	api.RouteRegister.Get("/api/public/dashboards/:accessToken/annotations", allowedClientIP, routing.Wrap(api.GetPublicAnnotations))
+
+	api.RouteRegister.Post("/api/public/dashboards/:accessToken/panels/:panelId/query", allowedClientIP, routing.Wrap(api.QueryPublicDashboard))
 
 	// Auth endpoints
 	auth := accesscontrol.Middleware(api.AccessControl)
@@ -86,6 +93,21 @@ func (api *Api) RegisterAPIEndpoints() {
 	api.RouteRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid",
 		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
 		routing.Wrap(api.DeletePublicDashboard))
+
+	// List access tokens of a public dashboard
+	api.RouteRegister.Get("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/access-tokens",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsRead, uidScope)),
+		routing.Wrap(api.ListPublicDashboardAccessTokens))
+
+	// Create access token for a public dashboard
+	api.RouteRegister.Post("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/access-tokens",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.CreatePublicDashboardAccessToken))
+
+	// Delete access token of a public dashboard
+	api.RouteRegister.Delete("/api/dashboards/uid/:dashboardUid/public-dashboards/:uid/access-tokens/:tokenUid",
+		auth(accesscontrol.EvalPermission(dashboards.ActionDashboardsPublicWrite, uidScope)),
+		routing.Wrap(api.DeletePublicDashboardAccessToken))
 }
 
 // swagger:route GET /dashboards/public-dashboards dashboard_public listPublicDashboards
@@ -283,6 +305,118 @@ func (api *Api) DeletePublicDashboard(c *contextmodel.ReqContext) response.Respo
 	return response.JSON(http.StatusOK, nil)
 }
 
+// swagger:route GET /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/access-tokens dashboard_public listPublicDashboardAccessTokens
+//
+//	Get the access tokens of a public dashboard along with their usage
+//
+// Responses:
+// 200: listPublicDashboardAccessTokensResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) ListPublicDashboardAccessTokens(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("ListPublicDashboardAccessTokens: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("ListPublicDashboardAccessTokens: invalid Uid %s", uid))
+	}
+
+	tokens, err := api.PublicDashboardService.FindAccessTokens(c.Req.Context(), c.SignedInUser.GetOrgID(), uid, dashboardUid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, tokens)
+}
+
+// swagger:route POST /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/access-tokens dashboard_public createPublicDashboardAccessToken
+//
+//	Create a named access token for a public dashboard
+//
+// Produces:
+// - application/json
+//
+// Responses:
+// 200: createPublicDashboardAccessTokenResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) CreatePublicDashboardAccessToken(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("CreatePublicDashboardAccessToken: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("CreatePublicDashboardAccessToken: invalid Uid %s", uid))
+	}
+
+	tokenDTO := &PublicDashboardAccessTokenDTO{}
+	if err := web.Bind(c.Req, tokenDTO); err != nil {
+		return response.Err(ErrBadRequest.Errorf("CreatePublicDashboardAccessToken: bad request data %v", err))
+	}
+
+	// Always set the orgID and userID from the session
+	dto := &SavePublicDashboardAccessTokenDTO{
+		PublicDashboardUid: uid,
+		DashboardUid:       dashboardUid,
+		OrgID:              c.SignedInUser.GetOrgID(),
+		UserId:             c.UserID,
+		AccessToken:        tokenDTO,
+	}
+
+	token, err := api.PublicDashboardService.CreateAccessToken(c.Req.Context(), dto)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, token)
+}
+
+// swagger:route DELETE /dashboards/uid/{dashboardUid}/public-dashboards/{uid}/access-tokens/{tokenUid} dashboard_public deletePublicDashboardAccessToken
+//
+//	Revoke an access token of a public dashboard
+//
+// Responses:
+// 200: okResponse
+// 400: badRequestPublicError
+// 401: unauthorisedPublicError
+// 403: forbiddenPublicError
+// 404: notFoundPublicError
+// 500: internalServerPublicError
+func (api *Api) DeletePublicDashboardAccessToken(c *contextmodel.ReqContext) response.Response {
+	dashboardUid := web.Params(c.Req)[":dashboardUid"]
+	if !validation.IsValidShortUID(dashboardUid) {
+		return response.Err(ErrInvalidUid.Errorf("DeletePublicDashboardAccessToken: invalid dashboard Uid %s", dashboardUid))
+	}
+
+	uid := web.Params(c.Req)[":uid"]
+	if !validation.IsValidShortUID(uid) {
+		return response.Err(ErrInvalidUid.Errorf("DeletePublicDashboardAccessToken: invalid Uid %s", uid))
+	}
+
+	tokenUid := web.Params(c.Req)[":tokenUid"]
+	if !validation.IsValidShortUID(tokenUid) {
+		return response.Err(ErrInvalidUid.Errorf("DeletePublicDashboardAccessToken: invalid access token Uid %s", tokenUid))
+	}
+
+	err := api.PublicDashboardService.DeleteAccessToken(c.Req.Context(), c.SignedInUser.GetOrgID(), uid, dashboardUid, tokenUid)
+	if err != nil {
+		return response.Err(err)
+	}
+
+	return response.JSON(http.StatusOK, nil)
+}
+
 // Copied from pkg/api/metrics.go
 func toJsonStreamingResponse(features *featuremgmt.FeatureManager, qdr *backend.QueryDataResponse) response.Response {
 	statusWhenError := http.StatusBadRequest
@@ -362,3 +496,51 @@ type DeletePublicDashboardParams struct {
 	// required:true
 	Uid string `json:"uid"`
 }
+
+// swagger:parameters listPublicDashboardAccessTokens
+type ListPublicDashboardAccessTokensParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+}
+
+// swagger:response listPublicDashboardAccessTokensResponse
+type ListPublicDashboardAccessTokensResponse struct {
+	// in: body
+	Body []*PublicDashboardAccessToken `json:"body"`
+}
+
+// swagger:parameters createPublicDashboardAccessToken
+type CreatePublicDashboardAccessTokenParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:body
+	// required:true
+	Body PublicDashboardAccessTokenDTO
+}
+
+// swagger:response createPublicDashboardAccessTokenResponse
+type CreatePublicDashboardAccessTokenResponse struct {
+	// in: body
+	Body PublicDashboardAccessToken `json:"body"`
+}
+
+// swagger:parameters deletePublicDashboardAccessToken
+type DeletePublicDashboardAccessTokenParams struct {
+	// in:path
+	// required:true
+	DashboardUid string `json:"dashboardUid"`
+	// in:path
+	// required:true
+	Uid string `json:"uid"`
+	// in:path
+	// required:true
+	TokenUid string `json:"tokenUid"`
+}
@@ -645,3 +645,104 @@ func TestAPIUpdatePublicDashboard(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIPublicDashboardAccessTokens(t *testing.T) {
+	dashboardUid := "abc1234"
+	publicDashboardUid := "1234asdfasdf"
+	basePath := fmt.Sprintf("/api/dashboards/uid/%s/public-dashboards/%s/access-tokens", dashboardUid, publicDashboardUid)
+	token := &PublicDashboardAccessToken{Uid: "tokenuid", PublicDashboardUid: publicDashboardUid, Name: "customer", AccessToken: "NOTAREALUUID", ViewCount: 3}
+
+	testCases := []struct {
+		Name                 string
+		User                 *user.SignedInUser
+		Method               string
+		Path                 string
+		Body                 string
+		ServiceMethod        string
+		ServiceResp          []any
+		ExpectedHttpResponse int
+	}{
+		{
+			Name:                 "User viewer can list access tokens",
+			User:                 userViewer,
+			Method:               http.MethodGet,
+			Path:                 basePath,
+			ServiceMethod:        "FindAccessTokens",
+			ServiceResp:          []any{[]*PublicDashboardAccessToken{token}, nil},
+			ExpectedHttpResponse: http.StatusOK,
+		},
+		{
+			Name:                 "User viewer cannot create access tokens",
+			User:                 userViewer,
+			Method:               http.MethodPost,
+			Path:                 basePath,
+			Body:                 `{"name": "customer"}`,
+			ExpectedHttpResponse: http.StatusForbidden,
+		},
+		{
+			Name:                 "User admin can create access tokens",
+			User:                 userAdmin,
+			Method:               http.MethodPost,
+			Path:                 basePath,
+			Body:                 `{"name": "customer", "ipAllowlist": ["10.0.0.0/8"], "variables": {"region": "eu"}}`,
+			ServiceMethod:        "CreateAccessToken",
+			ServiceResp:          []any{token, nil},
+			ExpectedHttpResponse: http.StatusOK,
+		},
+		{
+			Name:                 "Invalid access token returns the error of the service",
+			User:                 userAdmin,
+			Method:               http.MethodPost,
+			Path:                 basePath,
+			Body:                 `{"name": "customer", "ipAllowlist": ["invalid"]}`,
+			ServiceMethod:        "CreateAccessToken",
+			ServiceResp:          []any{nil, ErrInvalidIPAllowlist.Errorf("")},
+			ExpectedHttpResponse: http.StatusBadRequest,
+		},
+		{
+			Name:                 "User admin can revoke access tokens",
+			User:                 userAdmin,
+			Method:               http.MethodDelete,
+			Path:                 basePath + "/tokenuid",
+			ServiceMethod:        "DeleteAccessToken",
+			ServiceResp:          []any{nil},
+			ExpectedHttpResponse: http.StatusOK,
+		},
+		{
+			Name:                 "Revoking a missing access token returns not found",
+			User:                 userAdmin,
+			Method:               http.MethodDelete,
+			Path:                 basePath + "/tokenuid",
+			ServiceMethod:        "DeleteAccessToken",
+			ServiceResp:          []any{ErrAccessTokenNotFound.Errorf("")},
+			ExpectedHttpResponse: http.StatusNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.Name, func(t *testing.T) {
+			service := publicdashboards.NewFakePublicDashboardService(t)
+			if test.ServiceMethod != "" {
+				// the access tokens are looked up in the organization of the signed in user
+				args := []any{mock.Anything, test.User.OrgID, publicDashboardUid, dashboardUid}
+				switch test.ServiceMethod {
+				case "CreateAccessToken":
+					args = []any{mock.Anything, mock.Anything}
+				case "DeleteAccessToken":
+					args = []any{mock.Anything, test.User.OrgID, publicDashboardUid, dashboardUid, "tokenuid"}
+				}
+				service.On(test.ServiceMethod, args...).Return(test.ServiceResp...)
+			}
+
+			features := featuremgmt.WithFeatures(featuremgmt.FlagPublicDashboards)
+			testServer := setupTestServer(t, setting.NewCfg(), features, service, nil, test.User)
+
+			response := callAPI(testServer, test.Method, test.Path, strings.NewReader(test.Body), t)
+			assert.Equal(t, test.ExpectedHttpResponse, response.Code)
+
+			if test.ExpectedHttpResponse == http.StatusOK && test.Method != http.MethodDelete {
+				assert.Contains(t, response.Body.String(), `"viewCount":3`)
+			}
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package api
 
 import (
+	"errors"
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
 	"github.com/grafana/grafana/pkg/web"
 )
@@ -62,6 +66,57 @@ func RequiresExistingAccessToken(publicDashboardService publicdashboards.Service
 	}
 }
 
+// RequiresAllowedClientIP Middleware to enforce the IP allowlist of the named access token of a public dashboard.
+// Invalid access tokens are left to the handler to reject.
+func RequiresAllowedClientIP(publicDashboardService publicdashboards.Service, trustedProxies []string) func(c *contextmodel.ReqContext) {
+	return func(c *contextmodel.ReqContext) {
+		accessToken, ok := web.Params(c.Req)[":accessToken"]
+		if !ok || !validation.IsValidAccessToken(accessToken) {
+			return
+		}
+
+		err := publicDashboardService.ValidateAccessTokenClientIP(c.Req.Context(), accessToken, clientIP(c.Req, trustedProxies))
+		if errors.Is(err, ErrClientIPNotAllowed) {
+			c.JsonApiErr(http.StatusForbidden, "Public dashboard access is not allowed from this IP", nil)
+			return
+		}
+		if err != nil {
+			c.JsonApiErr(http.StatusInternalServerError, "Failed to validate client IP", nil)
+			return
+		}
+	}
+}
+
+// clientIP Gets the IP of the client of the request. The X-Forwarded-For and X-Real-IP headers can be set by any
+// client, so they are only used when the request comes from one of the trusted proxies.
+func clientIP(req *http.Request, trustedProxies []string) string {
+	ip := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	if !IPMatchesAny(ip, trustedProxies) {
+		return ip
+	}
+
+	// each proxy appends the address it received the request from, so the client is the last address which is not
+	// a trusted proxy
+	if forwardedFor := req.Header.Get("X-Forwarded-For"); forwardedFor != "" {
+		addrs := strings.Split(forwardedFor, ",")
+		for i := len(addrs) - 1; i >= 0; i-- {
+			ip = strings.TrimSpace(addrs[i])
+			if !IPMatchesAny(ip, trustedProxies) {
+				break
+			}
+		}
+		return ip
+	}
+
+	if realIP := strings.TrimSpace(req.Header.Get("X-Real-IP")); realIP != "" {
+		return realIP
+	}
+	return ip
+}
+
 func CountPublicDashboardRequest() func(c *contextmodel.ReqContext) {
 	return func(c *contextmodel.ReqContext) {
 		metrics.MPublicDashboardRequestCount.Inc()
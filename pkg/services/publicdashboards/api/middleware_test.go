@@ -10,6 +10,7 @@ import (
 
 	contextmodel "github.com/grafana/grafana/pkg/services/contexthandler/model"
 	"github.com/grafana/grafana/pkg/services/publicdashboards"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/services/publicdashboards/service"
 	"github.com/grafana/grafana/pkg/services/user"
 	"github.com/grafana/grafana/pkg/web"
@@ -144,6 +145,112 @@ func TestSetPublicDashboardOrgIdOnContext(t *testing.T) {
 	}
 }
 
+func TestRequiresAllowedClientIP(t *testing.T) {
+	tests := []struct {
+		Name                 string
+		AccessToken          string
+		ValidateErr          error
+		ExpectValidateCall   bool
+		ExpectedResponseCode int
+	}{
+		{
+			Name:                 "Continues when the client IP is allowed",
+			AccessToken:          validAccessToken,
+			ExpectValidateCall:   true,
+			ExpectedResponseCode: http.StatusOK,
+		},
+		{
+			Name:                 "Returns 403 when the client IP is not allowed",
+			AccessToken:          validAccessToken,
+			ValidateErr:          ErrClientIPNotAllowed.Errorf(""),
+			ExpectValidateCall:   true,
+			ExpectedResponseCode: http.StatusForbidden,
+		},
+		{
+			Name:                 "Leaves invalid access tokens to the handler",
+			AccessToken:          "invalidAccessToken",
+			ExpectedResponseCode: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			publicdashboardService := &publicdashboards.FakePublicDashboardService{}
+			publicdashboardService.On("ValidateAccessTokenClientIP", mock.Anything, tt.AccessToken, mock.Anything).Return(tt.ValidateErr)
+
+			params := map[string]string{":accessToken": tt.AccessToken}
+			mw := RequiresAllowedClientIP(publicdashboardService, nil)
+			_, resp := runMw(t, nil, "GET", "/api/public/dashboards/myAccesstoken", params, mw)
+			require.Equal(t, tt.ExpectedResponseCode, resp.Code)
+			if tt.ExpectValidateCall {
+				publicdashboardService.AssertNumberOfCalls(t, "ValidateAccessTokenClientIP", 1)
+			} else {
+				publicdashboardService.AssertNotCalled(t, "ValidateAccessTokenClientIP", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	trustedProxies := []string{"10.0.0.0/8"}
+	tests := []struct {
+		Name           string
+		RemoteAddr     string
+		ForwardedFor   string
+		RealIP         string
+		TrustedProxies []string
+		ExpectedIP     string
+	}{
+		{
+			Name:       "Uses the address of the connection",
+			RemoteAddr: "203.0.113.1:1234",
+			ExpectedIP: "203.0.113.1",
+		},
+		{
+			Name:         "Ignores the headers without trusted proxies",
+			RemoteAddr:   "203.0.113.1:1234",
+			ForwardedFor: "192.0.2.1",
+			RealIP:       "192.0.2.1",
+			ExpectedIP:   "203.0.113.1",
+		},
+		{
+			Name:           "Ignores the headers of requests not coming from a trusted proxy",
+			RemoteAddr:     "203.0.113.1:1234",
+			ForwardedFor:   "192.0.2.1",
+			TrustedProxies: trustedProxies,
+			ExpectedIP:     "203.0.113.1",
+		},
+		{
+			Name:           "Uses the last address forwarded which is not a trusted proxy",
+			RemoteAddr:     "10.0.0.1:1234",
+			ForwardedFor:   "192.0.2.1, 198.51.100.1, 10.0.0.2",
+			TrustedProxies: trustedProxies,
+			ExpectedIP:     "198.51.100.1",
+		},
+		{
+			Name:           "Uses the real IP header of requests coming from a trusted proxy",
+			RemoteAddr:     "10.0.0.1:1234",
+			RealIP:         "192.0.2.1",
+			TrustedProxies: trustedProxies,
+			ExpectedIP:     "192.0.2.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/public/dashboards/myAccesstoken", nil)
+			req.RemoteAddr = tt.RemoteAddr
+			if tt.ForwardedFor != "" {
+				req.Header.Set("X-Forwarded-For", tt.ForwardedFor)
+			}
+			if tt.RealIP != "" {
+				req.Header.Set("X-Real-IP", tt.RealIP)
+			}
+			assert.Equal(t, tt.ExpectedIP, clientIP(req, tt.TrustedProxies))
+		})
+	}
+}
+
 func TestSetPublicDashboardFlag(t *testing.T) {
 	t.Run("Adds context.PublicDashboardAccessToken to request", func(t *testing.T) {
 		ctx := &contextmodel.ReqContext{Context: &web.Context{Req: web.SetURLParams(&http.Request{}, map[string]string{":accessToken": "asdfasdfasdfsadfasdfsfd"})}}
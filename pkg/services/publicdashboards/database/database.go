@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -268,12 +269,18 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 			return err
 		}
 
-		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
+		templateVariablesJSON, err := json.Marshal(cmd.PublicDashboard.TemplateVariables)
+		if err != nil {
+			return err
+		}
+
+		sqlResult, err := sess.Exec("UPDATE dashboard_public SET is_enabled = ?, annotations_enabled = ?, time_selection_enabled = ?, share = ?, time_settings = ?, template_variables = ?, updated_by = ?, updated_at = ? WHERE uid = ?",
 			cmd.PublicDashboard.IsEnabled,
 			cmd.PublicDashboard.AnnotationsEnabled,
 			cmd.PublicDashboard.TimeSelectionEnabled,
 			cmd.PublicDashboard.Share,
 			string(timeSettingsJSON),
+			string(templateVariablesJSON),
 			cmd.PublicDashboard.UpdatedBy,
 			cmd.PublicDashboard.UpdatedAt.UTC().Format("2006-01-02 15:04:05"),
 			cmd.PublicDashboard.Uid)
@@ -290,20 +297,103 @@ func (d *PublicDashboardStoreImpl) Update(ctx context.Context, cmd SavePublicDas
 	return affectedRows, err
 }
 
-// Deletes a public dashboard
+// Deletes a public dashboard and its access tokens
 func (d *PublicDashboardStoreImpl) Delete(ctx context.Context, uid string) (int64, error) {
 	dashboard := &PublicDashboard{Uid: uid}
 	var affectedRows int64
-	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+	err := d.sqlStore.WithTransactionalDbSession(ctx, func(sess *db.Session) error {
 		var err error
 		affectedRows, err = sess.Delete(dashboard)
+		if err != nil {
+			return err
+		}
+
+		_, err = sess.Delete(&PublicDashboardAccessToken{PublicDashboardUid: uid})
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// CreateAccessToken Creates a named access token of a public dashboard
+func (d *PublicDashboardStoreImpl) CreateAccessToken(ctx context.Context, cmd SaveAccessTokenCommand) (int64, error) {
+	if cmd.AccessToken.PublicDashboardUid == "" {
+		return 0, ErrPublicDashboardIdentifierNotSet.Errorf("CreateAccessToken: public dashboard uid not set")
+	}
+
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affectedRows, err = sess.Insert(&cmd.AccessToken)
+		return err
+	})
+
+	return affectedRows, err
+}
+
+// FindAccessToken Returns a named access token by its access token or nil if not found
+func (d *PublicDashboardStoreImpl) FindAccessToken(ctx context.Context, accessToken string) (*PublicDashboardAccessToken, error) {
+	if accessToken == "" {
+		return nil, nil
+	}
+
+	var found bool
+	token := &PublicDashboardAccessToken{AccessToken: accessToken}
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		found, err = sess.Get(token)
+		return err
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	return token, nil
+}
 
+// FindAccessTokens Returns the named access tokens of a public dashboard
+func (d *PublicDashboardStoreImpl) FindAccessTokens(ctx context.Context, publicDashboardUid string) ([]*PublicDashboardAccessToken, error) {
+	tokens := make([]*PublicDashboardAccessToken, 0)
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		return sess.Where("public_dashboard_uid = ?", publicDashboardUid).Asc("name").Find(&tokens)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// DeleteAccessToken Deletes a named access token of a public dashboard
+func (d *PublicDashboardStoreImpl) DeleteAccessToken(ctx context.Context, publicDashboardUid string, uid string) (int64, error) {
+	token := &PublicDashboardAccessToken{Uid: uid, PublicDashboardUid: publicDashboardUid}
+	var affectedRows int64
+	err := d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		var err error
+		affectedRows, err = sess.Delete(token)
 		return err
 	})
 
 	return affectedRows, err
 }
 
+// RecordAccessTokenUsage Adds views and queries to the usage counters of a named access token
+func (d *PublicDashboardStoreImpl) RecordAccessTokenUsage(ctx context.Context, uid string, views int64, queries int64, lastUsedAt time.Time) error {
+	return d.sqlStore.WithDbSession(ctx, func(sess *db.Session) error {
+		_, err := sess.Exec("UPDATE dashboard_public_access_token SET view_count = view_count + ?, query_count = query_count + ?, last_used_at = ? WHERE uid = ?",
+			views,
+			queries,
+			lastUsedAt.UTC().Format("2006-01-02 15:04:05"),
+			uid)
+		return err
+	})
+}
+
 func (d *PublicDashboardStoreImpl) FindByDashboardFolder(ctx context.Context, dashboard *dashboards.Dashboard) ([]*PublicDashboard, error) {
 	if dashboard == nil || !dashboard.IsFolder {
 		return nil, nil
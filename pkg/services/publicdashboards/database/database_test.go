@@ -764,6 +764,110 @@ func TestIntegrationDelete(t *testing.T) {
 	})
 }
 
+func TestIntegrationAccessTokens(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+	var publicdashboardStore *PublicDashboardStoreImpl
+	var savedPublicDashboard *PublicDashboard
+
+	setup := func() {
+		sqlStore, cfg := db.InitTestDBwithCfg(t)
+		dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, cfg), quotatest.New(false, nil))
+		require.NoError(t, err)
+		publicdashboardStore = ProvideStore(sqlStore, cfg, featuremgmt.WithFeatures())
+		savedDashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, "", true)
+		savedPublicDashboard = insertPublicDashboard(t, publicdashboardStore, savedDashboard.UID, savedDashboard.OrgID, true, PublicShareType)
+	}
+
+	t.Run("CreateAccessToken and FindAccessToken round trip the access token settings", func(t *testing.T) {
+		setup()
+		expiresAt := DefaultTime.Add(time.Hour)
+		token := insertAccessToken(t, publicdashboardStore, savedPublicDashboard, "customer", &expiresAt)
+
+		found, err := publicdashboardStore.FindAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, token.Uid, found.Uid)
+		assert.Equal(t, savedPublicDashboard.Uid, found.PublicDashboardUid)
+		assert.Equal(t, savedPublicDashboard.OrgId, found.OrgId)
+		assert.Equal(t, "customer", found.Name)
+		assert.Equal(t, []string{"10.0.0.0/8"}, found.IPAllowlist)
+		assert.Equal(t, map[string]string{"region": "eu"}, found.Variables)
+		require.NotNil(t, found.ExpiresAt)
+		assert.True(t, expiresAt.Equal(*found.ExpiresAt))
+		assert.Nil(t, found.LastUsedAt)
+	})
+
+	t.Run("FindAccessToken returns nil when the access token does not exist", func(t *testing.T) {
+		setup()
+		found, err := publicdashboardStore.FindAccessToken(context.Background(), "doesnotexist")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("FindAccessTokens returns the access tokens of the public dashboard ordered by name", func(t *testing.T) {
+		setup()
+		insertAccessToken(t, publicdashboardStore, savedPublicDashboard, "b", nil)
+		insertAccessToken(t, publicdashboardStore, savedPublicDashboard, "a", nil)
+
+		tokens, err := publicdashboardStore.FindAccessTokens(context.Background(), savedPublicDashboard.Uid)
+		require.NoError(t, err)
+		require.Len(t, tokens, 2)
+		assert.Equal(t, "a", tokens[0].Name)
+		assert.Equal(t, "b", tokens[1].Name)
+
+		tokens, err = publicdashboardStore.FindAccessTokens(context.Background(), "other")
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
+	t.Run("RecordAccessTokenUsage increments the counters", func(t *testing.T) {
+		setup()
+		token := insertAccessToken(t, publicdashboardStore, savedPublicDashboard, "customer", nil)
+
+		lastUsedAt := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+		require.NoError(t, publicdashboardStore.RecordAccessTokenUsage(context.Background(), token.Uid, 1, 0, lastUsedAt.Add(-time.Hour)))
+		require.NoError(t, publicdashboardStore.RecordAccessTokenUsage(context.Background(), token.Uid, 0, 3, lastUsedAt))
+
+		found, err := publicdashboardStore.FindAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, found.ViewCount)
+		assert.EqualValues(t, 3, found.QueryCount)
+		require.NotNil(t, found.LastUsedAt)
+		assert.True(t, lastUsedAt.Equal(*found.LastUsedAt))
+	})
+
+	t.Run("DeleteAccessToken deletes only the access token of the public dashboard", func(t *testing.T) {
+		setup()
+		token := insertAccessToken(t, publicdashboardStore, savedPublicDashboard, "customer", nil)
+
+		affectedRows, err := publicdashboardStore.DeleteAccessToken(context.Background(), "other", token.Uid)
+		require.NoError(t, err)
+		assert.EqualValues(t, 0, affectedRows)
+
+		affectedRows, err = publicdashboardStore.DeleteAccessToken(context.Background(), savedPublicDashboard.Uid, token.Uid)
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, affectedRows)
+
+		found, err := publicdashboardStore.FindAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("Delete deletes the access tokens of the public dashboard", func(t *testing.T) {
+		setup()
+		token := insertAccessToken(t, publicdashboardStore, savedPublicDashboard, "customer", nil)
+
+		_, err := publicdashboardStore.Delete(context.Background(), savedPublicDashboard.Uid)
+		require.NoError(t, err)
+
+		found, err := publicdashboardStore.FindAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
+
 func TestGetDashboardByFolder(t *testing.T) {
 	t.Run("returns nil when dashboard is not a folder", func(t *testing.T) {
 		sqlStore, _ := db.InitTestDBwithCfg(t)
@@ -921,3 +1025,32 @@ func insertPublicDashboard(t *testing.T, publicdashboardStore *PublicDashboardSt
 
 	return pubdash
 }
+
+// helper function to insert a named access token of a public dashboard
+func insertAccessToken(t *testing.T, publicdashboardStore *PublicDashboardStoreImpl, pubdash *PublicDashboard, name string, expiresAt *time.Time) *PublicDashboardAccessToken {
+	t.Helper()
+
+	accessToken, err := service.GenerateAccessToken()
+	require.NoError(t, err)
+
+	cmd := SaveAccessTokenCommand{
+		AccessToken: PublicDashboardAccessToken{
+			Uid:                util.GenerateShortUID(),
+			PublicDashboardUid: pubdash.Uid,
+			OrgId:              pubdash.OrgId,
+			Name:               name,
+			AccessToken:        accessToken,
+			ExpiresAt:          expiresAt,
+			IPAllowlist:        []string{"10.0.0.0/8"},
+			Variables:          map[string]string{"region": "eu"},
+			CreatedBy:          1,
+			CreatedAt:          DefaultTime,
+		},
+	}
+
+	affectedRows, err := publicdashboardStore.CreateAccessToken(context.Background(), cmd)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, affectedRows)
+
+	return &cmd.AccessToken
+}
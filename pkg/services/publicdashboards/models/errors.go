@@ -8,6 +8,7 @@ var (
 	ErrPublicDashboardNotFound = errutil.NotFound("publicdashboards.notFound", errutil.WithPublicMessage("Public dashboard not found"))
 	ErrDashboardNotFound       = errutil.NotFound("publicdashboards.dashboardNotFound", errutil.WithPublicMessage("Dashboard not found"))
 	ErrPanelNotFound           = errutil.NotFound("publicdashboards.panelNotFound", errutil.WithPublicMessage("Public dashboard panel not found"))
+	ErrAccessTokenNotFound     = errutil.NotFound("publicdashboards.accessTokenNotFound", errutil.WithPublicMessage("Public dashboard access token not found"))
 
 	ErrBadRequest                          = errutil.BadRequest("publicdashboards.badRequest")
 	ErrPanelQueriesNotFound                = errutil.BadRequest("publicdashboards.panelQueriesNotFound", errutil.WithPublicMessage("Failed to extract queries from panel"))
@@ -23,6 +24,13 @@ var (
 	ErrDashboardIsPublic                   = errutil.BadRequest("publicdashboards.dashboardIsPublic", errutil.WithPublicMessage("Dashboard is already public"))
 	ErrPublicDashboardUidExists            = errutil.BadRequest("publicdashboards.uidExists", errutil.WithPublicMessage("Public Dashboard Uid already exists"))
 	ErrPublicDashboardAccessTokenExists    = errutil.BadRequest("publicdashboards.accessTokenExists", errutil.WithPublicMessage("Public Dashboard Access Token already exists"))
+	ErrInvalidAccessTokenName              = errutil.BadRequest("publicdashboards.invalidAccessTokenName", errutil.WithPublicMessage("Invalid access token name"))
+	ErrInvalidAccessTokenExpiry            = errutil.BadRequest("publicdashboards.invalidAccessTokenExpiry", errutil.WithPublicMessage("Access token expiry should be in the future"))
+	ErrInvalidIPAllowlist                  = errutil.BadRequest("publicdashboards.invalidIPAllowlist", errutil.WithPublicMessage("Invalid IP allowlist"))
+	ErrInvalidTemplateVariables            = errutil.BadRequest("publicdashboards.invalidTemplateVariables", errutil.WithPublicMessage("Invalid template variables"))
+	ErrTemplateVariableValueNotAllowed     = errutil.BadRequest("publicdashboards.templateVariableValueNotAllowed", errutil.WithPublicMessage("Template variable value not allowed"))
 
 	ErrPublicDashboardNotEnabled = errutil.Forbidden("publicdashboards.notEnabled", errutil.WithPublicMessage("Public dashboard paused"))
+	ErrAccessTokenExpired        = errutil.Forbidden("publicdashboards.accessTokenExpired", errutil.WithPublicMessage("Public dashboard access token expired"))
+	ErrClientIPNotAllowed        = errutil.Forbidden("publicdashboards.clientIPNotAllowed", errutil.WithPublicMessage("Public dashboard access is not allowed from this IP"))
 )
@@ -2,6 +2,7 @@ package models
 
 import (
 	"encoding/json"
+	"net"
 	"time"
 
 	"github.com/grafana/grafana/pkg/kinds/dashboard"
@@ -52,7 +53,76 @@ type PublicDashboard struct {
 	IsEnabled            bool          `json:"isEnabled" xorm:"is_enabled"`
 	AnnotationsEnabled   bool          `json:"annotationsEnabled" xorm:"annotations_enabled"`
 	Share                ShareType     `json:"share" xorm:"share"`
-	Recipients           []EmailDTO    `json:"recipients,omitempty" xorm:"-"`
+	// TemplateVariables holds the values viewers are allowed to select for the variables of the dashboard
+	TemplateVariables TemplateVariables `json:"templateVariables,omitempty" xorm:"template_variables"`
+	Recipients        []EmailDTO        `json:"recipients,omitempty" xorm:"-"`
+}
+
+// TemplateVariables maps the names of the query and custom variables of a public dashboard to their allowed values
+type TemplateVariables map[string][]string
+
+// PublicDashboardAccessToken is a named access token giving access to a public dashboard, in addition to the access
+// token of the public dashboard, so that a public dashboard can be shared with each viewer through its own link.
+type PublicDashboardAccessToken struct {
+	Uid                string            `json:"uid" xorm:"pk uid"`
+	PublicDashboardUid string            `json:"publicDashboardUid" xorm:"public_dashboard_uid"`
+	OrgId              int64             `json:"-" xorm:"org_id"` // Don't ever marshal orgId to Json
+	Name               string            `json:"name" xorm:"name"`
+	AccessToken        string            `json:"accessToken" xorm:"access_token"`
+	ExpiresAt          *time.Time        `json:"expiresAt,omitempty" xorm:"expires_at"`
+	IPAllowlist        []string          `json:"ipAllowlist" xorm:"ip_allowlist"`
+	Variables          map[string]string `json:"variables" xorm:"variables"`
+	ViewCount          int64             `json:"viewCount" xorm:"view_count"`
+	QueryCount         int64             `json:"queryCount" xorm:"query_count"`
+	LastUsedAt         *time.Time        `json:"lastUsedAt,omitempty" xorm:"last_used_at"`
+	CreatedBy          int64             `json:"createdBy" xorm:"created_by"`
+	CreatedAt          time.Time         `json:"createdAt" xorm:"created_at"`
+}
+
+func (t PublicDashboardAccessToken) TableName() string {
+	return "dashboard_public_access_token"
+}
+
+// IsExpired returns true if the access token has an expiry that has passed
+func (t *PublicDashboardAccessToken) IsExpired(now time.Time) bool {
+	return t.ExpiresAt != nil && !now.Before(*t.ExpiresAt)
+}
+
+// AllowsClientIP returns true if the access token has no IP allowlist or if the client IP matches one of its IPs or
+// CIDR ranges
+func (t *PublicDashboardAccessToken) AllowsClientIP(clientIP string) bool {
+	if len(t.IPAllowlist) == 0 {
+		return true
+	}
+	return IPMatchesAny(clientIP, t.IPAllowlist)
+}
+
+// IPMatchesAny returns true if the IP matches one of the IPs or CIDR ranges
+func IPMatchesAny(clientIP string, ipsOrCIDRs []string) bool {
+	ip := net.ParseIP(clientIP)
+	if ip == nil {
+		return false
+	}
+
+	for _, allowed := range ipsOrCIDRs {
+		if _, ipNet, err := net.ParseCIDR(allowed); err == nil {
+			if ipNet.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		if allowedIP := net.ParseIP(allowed); allowedIP != nil && allowedIP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+type PublicDashboardAccessTokenDTO struct {
+	Name        string            `json:"name"`
+	ExpiresAt   *time.Time        `json:"expiresAt"`
+	IPAllowlist []string          `json:"ipAllowlist"`
+	Variables   map[string]string `json:"variables"`
 }
 
 type PublicDashboardDTO struct {
@@ -62,6 +132,8 @@ type PublicDashboardDTO struct {
 	IsEnabled            *bool     `json:"isEnabled"`
 	AnnotationsEnabled   *bool     `json:"annotationsEnabled"`
 	Share                ShareType `json:"share"`
+	// TemplateVariables are left unchanged on update when not set
+	TemplateVariables TemplateVariables `json:"templateVariables"`
 }
 
 type EmailDTO struct {
@@ -141,6 +213,15 @@ type SavePublicDashboardDTO struct {
 	PublicDashboard *PublicDashboardDTO
 }
 
+// DTO for transforming user input in the api
+type SavePublicDashboardAccessTokenDTO struct {
+	PublicDashboardUid string
+	DashboardUid       string
+	OrgID              int64
+	UserId             int64
+	AccessToken        *PublicDashboardAccessTokenDTO
+}
+
 type TimeRangeDTO struct {
 	From     string
 	To       string
@@ -152,6 +233,8 @@ type PublicDashboardQueryDTO struct {
 	MaxDataPoints   int64
 	QueryCachingTTL int64
 	TimeRange       TimeRangeDTO
+	// Variables holds the values selected by the viewer for the template variables of the public dashboard
+	Variables map[string][]string
 }
 
 type AnnotationsQueryDTO struct {
@@ -166,3 +249,7 @@ type AnnotationsQueryDTO struct {
 type SavePublicDashboardCommand struct {
 	PublicDashboard PublicDashboard
 }
+
+type SaveAccessTokenCommand struct {
+	AccessToken PublicDashboardAccessToken
+}
@@ -49,6 +49,32 @@ func (_m *FakePublicDashboardService) Create(ctx context.Context, u *user.Signed
 	return r0, r1
 }
 
+// CreateAccessToken provides a mock function with given fields: ctx, dto
+func (_m *FakePublicDashboardService) CreateAccessToken(ctx context.Context, dto *models.SavePublicDashboardAccessTokenDTO) (*models.PublicDashboardAccessToken, error) {
+	ret := _m.Called(ctx, dto)
+
+	var r0 *models.PublicDashboardAccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SavePublicDashboardAccessTokenDTO) (*models.PublicDashboardAccessToken, error)); ok {
+		return rf(ctx, dto)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *models.SavePublicDashboardAccessTokenDTO) *models.PublicDashboardAccessToken); ok {
+		r0 = rf(ctx, dto)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.PublicDashboardAccessToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *models.SavePublicDashboardAccessTokenDTO) error); ok {
+		r1 = rf(ctx, dto)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Delete provides a mock function with given fields: ctx, uid, dashboardUid
 func (_m *FakePublicDashboardService) Delete(ctx context.Context, uid string, dashboardUid string) error {
 	ret := _m.Called(ctx, uid, dashboardUid)
@@ -63,6 +89,20 @@ func (_m *FakePublicDashboardService) Delete(ctx context.Context, uid string, da
 	return r0
 }
 
+// DeleteAccessToken provides a mock function with given fields: ctx, orgId, uid, dashboardUid, tokenUid
+func (_m *FakePublicDashboardService) DeleteAccessToken(ctx context.Context, orgId int64, uid string, dashboardUid string, tokenUid string) error {
+	ret := _m.Called(ctx, orgId, uid, dashboardUid, tokenUid)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string, string) error); ok {
+		r0 = rf(ctx, orgId, uid, dashboardUid, tokenUid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteByDashboard provides a mock function with given fields: ctx, dashboard
 func (_m *FakePublicDashboardService) DeleteByDashboard(ctx context.Context, dashboard *dashboards.Dashboard) error {
 	ret := _m.Called(ctx, dashboard)
@@ -151,6 +191,32 @@ func (_m *FakePublicDashboardService) Find(ctx context.Context, uid string) (*mo
 	return r0, r1
 }
 
+// FindAccessTokens provides a mock function with given fields: ctx, orgId, uid, dashboardUid
+func (_m *FakePublicDashboardService) FindAccessTokens(ctx context.Context, orgId int64, uid string, dashboardUid string) ([]*models.PublicDashboardAccessToken, error) {
+	ret := _m.Called(ctx, orgId, uid, dashboardUid)
+
+	var r0 []*models.PublicDashboardAccessToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) ([]*models.PublicDashboardAccessToken, error)); ok {
+		return rf(ctx, orgId, uid, dashboardUid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64, string, string) []*models.PublicDashboardAccessToken); ok {
+		r0 = rf(ctx, orgId, uid, dashboardUid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.PublicDashboardAccessToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64, string, string) error); ok {
+		r1 = rf(ctx, orgId, uid, dashboardUid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindAllWithPagination provides a mock function with given fields: ctx, query
 func (_m *FakePublicDashboardService) FindAllWithPagination(ctx context.Context, query *models.PublicDashboardListQuery) (*models.PublicDashboardListResponseWithPagination, error) {
 	ret := _m.Called(ctx, query)
@@ -525,6 +591,20 @@ func (_m *FakePublicDashboardService) Update(ctx context.Context, u *user.Signed
 	return r0, r1
 }
 
+// ValidateAccessTokenClientIP provides a mock function with given fields: ctx, accessToken, clientIP
+func (_m *FakePublicDashboardService) ValidateAccessTokenClientIP(ctx context.Context, accessToken string, clientIP string) error {
+	ret := _m.Called(ctx, accessToken, clientIP)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, accessToken, clientIP)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewFakePublicDashboardService creates a new instance of FakePublicDashboardService. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewFakePublicDashboardService(t interface {
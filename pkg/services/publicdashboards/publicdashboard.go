@@ -2,6 +2,7 @@ package publicdashboards
 
 import (
 	"context"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -37,6 +38,11 @@ type Service interface {
 
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
+
+	CreateAccessToken(ctx context.Context, dto *SavePublicDashboardAccessTokenDTO) (*PublicDashboardAccessToken, error)
+	FindAccessTokens(ctx context.Context, orgId int64, uid string, dashboardUid string) ([]*PublicDashboardAccessToken, error)
+	DeleteAccessToken(ctx context.Context, orgId int64, uid string, dashboardUid string, tokenUid string) error
+	ValidateAccessTokenClientIP(ctx context.Context, accessToken string, clientIP string) error
 }
 
 // ServiceWrapper these methods have different behavior between OSS and Enterprise. The latter would call the OSS service first
@@ -63,4 +69,10 @@ type Store interface {
 	ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error)
 	ExistsEnabledByDashboardUid(ctx context.Context, dashboardUid string) (bool, error)
 	GetMetrics(ctx context.Context) (*Metrics, error)
+
+	CreateAccessToken(ctx context.Context, cmd SaveAccessTokenCommand) (int64, error)
+	FindAccessToken(ctx context.Context, accessToken string) (*PublicDashboardAccessToken, error)
+	FindAccessTokens(ctx context.Context, publicDashboardUid string) ([]*PublicDashboardAccessToken, error)
+	DeleteAccessToken(ctx context.Context, publicDashboardUid string, uid string) (int64, error)
+	RecordAccessTokenUsage(ctx context.Context, uid string, views int64, queries int64, lastUsedAt time.Time) error
 }
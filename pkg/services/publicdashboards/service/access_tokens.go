@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/validation"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// CreateAccessToken Creates a named access token for a public dashboard
+func (pd *PublicDashboardServiceImpl) CreateAccessToken(ctx context.Context, dto *SavePublicDashboardAccessTokenDTO) (*PublicDashboardAccessToken, error) {
+	err := validation.ValidateAccessToken(dto, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	pubdash, err := pd.findPublicDashboardOfDashboard(ctx, dto.OrgID, dto.PublicDashboardUid, dto.DashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := pd.NewPublicDashboardAccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	token := PublicDashboardAccessToken{
+		Uid:                util.GenerateShortUID(),
+		PublicDashboardUid: pubdash.Uid,
+		OrgId:              pubdash.OrgId,
+		Name:               dto.AccessToken.Name,
+		AccessToken:        accessToken,
+		ExpiresAt:          dto.AccessToken.ExpiresAt,
+		IPAllowlist:        dto.AccessToken.IPAllowlist,
+		Variables:          dto.AccessToken.Variables,
+		CreatedBy:          dto.UserId,
+		CreatedAt:          time.Now(),
+	}
+
+	affectedRows, err := pd.store.CreateAccessToken(ctx, SaveAccessTokenCommand{AccessToken: token})
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("CreateAccessToken: failed to create an access token for public dashboard with Uid %s: %w", pubdash.Uid, err)
+	} else if affectedRows == 0 {
+		return nil, ErrInternalServerError.Errorf("CreateAccessToken: failed to create a database entry for an access token of public dashboard with Uid %s. 0 rows changed, no error reported.", pubdash.Uid)
+	}
+
+	pd.log.Info("Public dashboard access token created", "publicDashboardUid", pubdash.Uid, "accessTokenUid", token.Uid, "name", token.Name)
+
+	return &token, nil
+}
+
+// FindAccessTokens Gets the named access tokens of a public dashboard along with their usage
+func (pd *PublicDashboardServiceImpl) FindAccessTokens(ctx context.Context, orgId int64, uid string, dashboardUid string) ([]*PublicDashboardAccessToken, error) {
+	pubdash, err := pd.findPublicDashboardOfDashboard(ctx, orgId, uid, dashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens, err := pd.store.FindAccessTokens(ctx, pubdash.Uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("FindAccessTokens: failed to find access tokens of public dashboard with Uid %s: %w", pubdash.Uid, err)
+	}
+
+	return tokens, nil
+}
+
+// DeleteAccessToken Revokes a named access token of a public dashboard
+func (pd *PublicDashboardServiceImpl) DeleteAccessToken(ctx context.Context, orgId int64, uid string, dashboardUid string, tokenUid string) error {
+	pubdash, err := pd.findPublicDashboardOfDashboard(ctx, orgId, uid, dashboardUid)
+	if err != nil {
+		return err
+	}
+
+	affectedRows, err := pd.store.DeleteAccessToken(ctx, pubdash.Uid, tokenUid)
+	if err != nil {
+		return ErrInternalServerError.Errorf("DeleteAccessToken: failed to delete access token with Uid %s: %w", tokenUid, err)
+	}
+
+	if affectedRows == 0 {
+		return ErrAccessTokenNotFound.Errorf("DeleteAccessToken: access token not found by uid: %s", tokenUid)
+	}
+
+	pd.log.Info("Public dashboard access token deleted", "publicDashboardUid", pubdash.Uid, "accessTokenUid", tokenUid)
+
+	return nil
+}
+
+// ValidateAccessTokenClientIP Checks that the client IP is allowed by the IP allowlist of the access token. Access tokens
+// of public dashboards have no IP allowlist.
+func (pd *PublicDashboardServiceImpl) ValidateAccessTokenClientIP(ctx context.Context, accessToken string, clientIP string) error {
+	token, err := pd.store.FindAccessToken(ctx, accessToken)
+	if err != nil {
+		return ErrInternalServerError.Errorf("ValidateAccessTokenClientIP: failed to find a public dashboard access token: %w", err)
+	}
+
+	if token == nil {
+		return nil
+	}
+
+	if !token.AllowsClientIP(clientIP) {
+		return ErrClientIPNotAllowed.Errorf("ValidateAccessTokenClientIP: client IP %s not allowed for access token with Uid %s", clientIP, token.Uid)
+	}
+
+	return nil
+}
+
+// findPublicDashboardOfDashboard Gets a public dashboard of the organization by uid and validates it belongs to the
+// dashboard
+func (pd *PublicDashboardServiceImpl) findPublicDashboardOfDashboard(ctx context.Context, orgId int64, uid string, dashboardUid string) (*PublicDashboard, error) {
+	pubdash, err := pd.store.Find(ctx, uid)
+	if err != nil {
+		return nil, ErrInternalServerError.Errorf("failed to find public dashboard by uid: %s: %w", uid, err)
+	}
+
+	// public dashboards of other organizations are not found
+	if pubdash == nil || pubdash.OrgId != orgId {
+		return nil, ErrPublicDashboardNotFound.Errorf("public dashboard not found by orgId: %d and uid: %s", orgId, uid)
+	}
+
+	if pubdash.DashboardUid != dashboardUid {
+		return nil, ErrInvalidUid.Errorf("the public dashboard does not belong to the dashboard")
+	}
+
+	return pubdash, nil
+}
+
+// accessTokenUsageFlushInterval is the interval at which the usage of the access tokens is saved
+const accessTokenUsageFlushInterval = time.Minute
+
+// accessTokenUsage is the usage of an access token since its usage was last saved
+type accessTokenUsage struct {
+	views      int64
+	queries    int64
+	lastUsedAt time.Time
+}
+
+// accessTokenUsageBuffer Collects the usage of the access tokens in memory, so that views and panel queries don't
+// write to the database
+type accessTokenUsageBuffer struct {
+	mu    sync.Mutex
+	usage map[string]*accessTokenUsage
+}
+
+func (b *accessTokenUsageBuffer) add(uid string, views int64, queries int64, usedAt time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.usage == nil {
+		b.usage = map[string]*accessTokenUsage{}
+	}
+	u, ok := b.usage[uid]
+	if !ok {
+		u = &accessTokenUsage{}
+		b.usage[uid] = u
+	}
+	u.views += views
+	u.queries += queries
+	u.lastUsedAt = usedAt
+}
+
+// take Returns the collected usage and empties the buffer
+func (b *accessTokenUsageBuffer) take() map[string]*accessTokenUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	usage := b.usage
+	b.usage = nil
+	return usage
+}
+
+// recordAccessTokenUsage Adds the views and queries to the usage of the access token, which is saved by Run every
+// accessTokenUsageFlushInterval
+func (pd *PublicDashboardServiceImpl) recordAccessTokenUsage(token *PublicDashboardAccessToken, views int64, queries int64) {
+	pd.tokenUsage.add(token.Uid, views, queries, time.Now())
+}
+
+// Run Saves the usage of the access tokens periodically, and a last time when the server stops
+func (pd *PublicDashboardServiceImpl) Run(ctx context.Context) error {
+	ticker := time.NewTicker(accessTokenUsageFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// the context is done, save the remaining usage without it
+			pd.flushAccessTokenUsage(context.Background())
+			return ctx.Err()
+		case <-ticker.C:
+			pd.flushAccessTokenUsage(ctx)
+		}
+	}
+}
+
+// flushAccessTokenUsage Saves the usage collected since the last flush. Failing to save the usage of an access token
+// only loses it.
+func (pd *PublicDashboardServiceImpl) flushAccessTokenUsage(ctx context.Context) {
+	for uid, u := range pd.tokenUsage.take() {
+		if err := pd.store.RecordAccessTokenUsage(ctx, uid, u.views, u.queries, u.lastUsedAt); err != nil {
+			pd.log.Warn("Failed to record public dashboard access token usage", "accessTokenUid", uid, "error", err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/dashboards"
+	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/database"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+)
+
+func TestAccessTokens(t *testing.T) {
+	var service *PublicDashboardServiceImpl
+	var dashboard *dashboards.Dashboard
+	var pubdash *PublicDashboard
+
+	setup := func(t *testing.T) {
+		sqlStore := db.InitTestDB(t)
+		dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, sqlStore.Cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, sqlStore.Cfg), quotatest.New(false, nil))
+		require.NoError(t, err)
+		publicdashboardStore := database.ProvideStore(sqlStore, sqlStore.Cfg, featuremgmt.WithFeatures())
+		templateVars := []map[string]any{
+			{"name": "region", "type": "custom", "current": map[string]any{"text": "us", "value": "us"}},
+			{"name": "env", "type": "custom"},
+		}
+		dashboard = insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, "", true, templateVars, nil)
+
+		service = &PublicDashboardServiceImpl{
+			log:            log.New("test.logger"),
+			store:          publicdashboardStore,
+			serviceWrapper: ProvideServiceWrapper(publicdashboardStore),
+		}
+
+		isEnabled := true
+		pubdash, err = service.Create(context.Background(), SignedInUser, &SavePublicDashboardDTO{
+			DashboardUid:    dashboard.UID,
+			OrgID:           dashboard.OrgID,
+			UserId:          7,
+			PublicDashboard: &PublicDashboardDTO{IsEnabled: &isEnabled},
+		})
+		require.NoError(t, err)
+	}
+
+	createAccessToken := func(t *testing.T, dto *PublicDashboardAccessTokenDTO) *PublicDashboardAccessToken {
+		t.Helper()
+		token, err := service.CreateAccessToken(context.Background(), &SavePublicDashboardAccessTokenDTO{
+			PublicDashboardUid: pubdash.Uid,
+			DashboardUid:       dashboard.UID,
+			OrgID:              dashboard.OrgID,
+			UserId:             7,
+			AccessToken:        dto,
+		})
+		require.NoError(t, err)
+		return token
+	}
+
+	t.Run("CreateAccessToken creates an access token giving access to the public dashboard", func(t *testing.T) {
+		setup(t)
+		token := createAccessToken(t, &PublicDashboardAccessTokenDTO{Name: "customer"})
+
+		assert.Equal(t, pubdash.Uid, token.PublicDashboardUid)
+		assert.Equal(t, int64(7), token.CreatedBy)
+		assert.NotEqual(t, pubdash.AccessToken, token.AccessToken)
+
+		found, err := service.FindByAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, pubdash.Uid, found.Uid)
+
+		exists, err := service.ExistsEnabledByAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		orgId, err := service.GetOrgIdByAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, dashboard.OrgID, orgId)
+	})
+
+	t.Run("CreateAccessToken returns an error when the public dashboard does not belong to the dashboard", func(t *testing.T) {
+		setup(t)
+		_, err := service.CreateAccessToken(context.Background(), &SavePublicDashboardAccessTokenDTO{
+			PublicDashboardUid: pubdash.Uid,
+			DashboardUid:       "other",
+			OrgID:              dashboard.OrgID,
+			AccessToken:        &PublicDashboardAccessTokenDTO{Name: "customer"},
+		})
+		assert.ErrorIs(t, err, ErrInvalidUid)
+	})
+
+	t.Run("access tokens of public dashboards of other organizations are not found", func(t *testing.T) {
+		setup(t)
+		token := createAccessToken(t, &PublicDashboardAccessTokenDTO{Name: "customer"})
+		otherOrgId := dashboard.OrgID + 1
+
+		_, err := service.CreateAccessToken(context.Background(), &SavePublicDashboardAccessTokenDTO{
+			PublicDashboardUid: pubdash.Uid,
+			DashboardUid:       dashboard.UID,
+			OrgID:              otherOrgId,
+			AccessToken:        &PublicDashboardAccessTokenDTO{Name: "customer"},
+		})
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+
+		_, err = service.FindAccessTokens(context.Background(), otherOrgId, pubdash.Uid, dashboard.UID)
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+
+		err = service.DeleteAccessToken(context.Background(), otherOrgId, pubdash.Uid, dashboard.UID, token.Uid)
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+
+		tokens, err := service.FindAccessTokens(context.Background(), dashboard.OrgID, pubdash.Uid, dashboard.UID)
+		require.NoError(t, err)
+		assert.Len(t, tokens, 1)
+	})
+
+	t.Run("expired access tokens do not give access to the public dashboard", func(t *testing.T) {
+		setup(t)
+		token := createAccessToken(t, &PublicDashboardAccessTokenDTO{Name: "customer"})
+		expiresAt := time.Now().Add(-time.Minute)
+		token.ExpiresAt = &expiresAt
+		_, err := service.store.DeleteAccessToken(context.Background(), pubdash.Uid, token.Uid)
+		require.NoError(t, err)
+		_, err = service.store.CreateAccessToken(context.Background(), SaveAccessTokenCommand{AccessToken: *token})
+		require.NoError(t, err)
+
+		_, err = service.GetPublicDashboardForView(context.Background(), token.AccessToken)
+		assert.ErrorIs(t, err, ErrAccessTokenExpired)
+
+		exists, err := service.ExistsEnabledByAccessToken(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("GetPublicDashboardForView pins the variables of the access token and records the views", func(t *testing.T) {
+		setup(t)
+		token := createAccessToken(t, &PublicDashboardAccessTokenDTO{Name: "customer", Variables: map[string]string{"region": "eu"}})
+
+		dto, err := service.GetPublicDashboardForView(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+
+		variables := dto.Dashboard.Get("templating").Get("list").MustArray()
+		region := simplejson.NewFromAny(variables[0])
+		assert.Equal(t, "eu", region.GetPath("current", "value").MustString())
+		assert.Equal(t, variableHideVariable, region.Get("hide").MustInt())
+		env := simplejson.NewFromAny(variables[1])
+		_, pinned := env.CheckGet("hide")
+		assert.False(t, pinned)
+
+		// the usage is saved when it is flushed
+		tokens, err := service.FindAccessTokens(context.Background(), dashboard.OrgID, pubdash.Uid, dashboard.UID)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.EqualValues(t, 0, tokens[0].ViewCount)
+		assert.Nil(t, tokens[0].LastUsedAt)
+
+		_, err = service.GetPublicDashboardForView(context.Background(), token.AccessToken)
+		require.NoError(t, err)
+		service.flushAccessTokenUsage(context.Background())
+
+		tokens, err = service.FindAccessTokens(context.Background(), dashboard.OrgID, pubdash.Uid, dashboard.UID)
+		require.NoError(t, err)
+		require.Len(t, tokens, 1)
+		assert.EqualValues(t, 2, tokens[0].ViewCount)
+		assert.EqualValues(t, 0, tokens[0].QueryCount)
+		assert.NotNil(t, tokens[0].LastUsedAt)
+	})
+
+	t.Run("ValidateAccessTokenClientIP enforces the IP allowlist of the access token", func(t *testing.T) {
+		setup(t)
+		token := createAccessToken(t, &PublicDashboardAccessTokenDTO{Name: "customer", IPAllowlist: []string{"10.0.0.0/8", "192.168.1.1"}})
+
+		assert.NoError(t, service.ValidateAccessTokenClientIP(context.Background(), token.AccessToken, "10.1.2.3"))
+		assert.NoError(t, service.ValidateAccessTokenClientIP(context.Background(), token.AccessToken, "192.168.1.1"))
+		assert.ErrorIs(t, service.ValidateAccessTokenClientIP(context.Background(), token.AccessToken, "192.168.1.2"), ErrClientIPNotAllowed)
+		// the access token of the public dashboard has no IP allowlist
+		assert.NoError(t, service.ValidateAccessTokenClientIP(context.Background(), pubdash.AccessToken, "192.168.1.2"))
+	})
+
+	t.Run("DeleteAccessToken revokes the access token", func(t *testing.T) {
+		setup(t)
+		token := createAccessToken(t, &PublicDashboardAccessTokenDTO{Name: "customer"})
+
+		err := service.DeleteAccessToken(context.Background(), dashboard.OrgID, pubdash.Uid, dashboard.UID, token.Uid)
+		require.NoError(t, err)
+
+		_, err = service.FindByAccessToken(context.Background(), token.AccessToken)
+		assert.ErrorIs(t, err, ErrPublicDashboardNotFound)
+
+		err = service.DeleteAccessToken(context.Background(), dashboard.OrgID, pubdash.Uid, dashboard.UID, token.Uid)
+		assert.ErrorIs(t, err, ErrAccessTokenNotFound)
+	})
+}
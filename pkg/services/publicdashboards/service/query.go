@@ -124,11 +124,16 @@ func (pd *PublicDashboardServiceImpl) GetMetricRequest(ctx context.Context, dash
 
 // GetQueryDataResponse returns a query data response for the given panel and query
 func (pd *PublicDashboardServiceImpl) GetQueryDataResponse(ctx context.Context, skipDSCache bool, queryDto models.PublicDashboardQueryDTO, panelId int64, accessToken string) (*backend.QueryDataResponse, error) {
-	publicDashboard, dashboard, err := pd.FindEnabledPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
+	publicDashboard, dashboard, token, err := pd.findEnabledByAccessToken(ctx, accessToken)
 	if err != nil {
 		return nil, err
 	}
 
+	if token != nil {
+		publicDashboard = withPinnedVariables(publicDashboard, token)
+		pd.recordAccessTokenUsage(token, 0, 1)
+	}
+
 	metricReq, err := pd.GetMetricRequest(ctx, dashboard, publicDashboard, panelId, queryDto)
 	if err != nil {
 		return nil, err
@@ -164,9 +169,13 @@ func (pd *PublicDashboardServiceImpl) buildMetricRequest(dashboard *dashboards.D
 
 	ts := buildTimeSettings(dashboard, reqDTO, publicDashboard)
 
+	// the values of the template variables have been validated against the allowed values
+	variables := resolveTemplateVariables(dashboard.Data, publicDashboard.TemplateVariables, reqDTO.Variables)
+
 	// determine safe resolution to query data at
 	safeInterval, safeResolution := pd.getSafeIntervalAndMaxDataPoints(reqDTO, ts)
 	for i := range queries {
+		interpolateQuery(queries[i], variables)
 		queries[i].Set("intervalMs", safeInterval)
 		queries[i].Set("maxDataPoints", safeResolution)
 		queries[i].Set("queryCachingTTL", reqDTO.QueryCachingTTL)
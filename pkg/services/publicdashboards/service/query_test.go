@@ -1369,6 +1369,48 @@ func TestBuildMetricRequest(t *testing.T) {
 			reqDTO.Queries[0],
 		)
 	})
+
+	t.Run("metric request built with template variables interpolated", func(t *testing.T) {
+		templateVars := []map[string]interface{}{
+			{"name": "region", "type": "custom", "current": map[string]interface{}{"text": "us", "value": "us"}},
+			{"name": "host", "type": "query", "multi": true},
+		}
+		customPanels := []interface{}{
+			map[string]interface{}{
+				"id": 1,
+				"datasource": map[string]interface{}{
+					"uid": "ds1",
+				},
+				"targets": []interface{}{
+					map[string]interface{}{
+						"datasource": map[string]interface{}{
+							"type": "mysql",
+							"uid":  "ds1",
+						},
+						"rawSql": "SELECT * FROM metrics WHERE region = '$region' AND host IN (${host:sqlstring}) AND $__timeFilter(time)",
+						"refId":  "A",
+					},
+				},
+			}}
+		dashboard := insertTestDashboard(t, dashboardStore, "testDashWithTemplateVariables", 1, 0, "", true, templateVars, customPanels)
+		// the template variables are read as decoded from the database
+		dashboard, err := publicdashboardStore.FindDashboard(context.Background(), dashboard.OrgID, dashboard.UID)
+		require.NoError(t, err)
+		pubdash := &PublicDashboard{TemplateVariables: TemplateVariables{"region": {"eu", "us"}, "host": {"a", "b", "c"}}}
+
+		queryDTO := publicDashboardQueryDTO
+		queryDTO.Variables = map[string][]string{"host": {"a", "c"}}
+		reqDTO, err := service.buildMetricRequest(
+			dashboard,
+			pubdash,
+			1,
+			queryDTO,
+		)
+		require.NoError(t, err)
+
+		require.Len(t, reqDTO.Queries, 1)
+		require.Equal(t, "SELECT * FROM metrics WHERE region = 'us' AND host IN ('a','c') AND $__timeFilter(time)", reqDTO.Queries[0].Get("rawSql").MustString())
+	})
 }
 
 func TestBuildAnonymousUser(t *testing.T) {
@@ -35,6 +35,7 @@ type PublicDashboardServiceImpl struct {
 	AnnotationsRepo    annotations.Repository
 	ac                 accesscontrol.AccessControl
 	serviceWrapper     publicdashboards.ServiceWrapper
+	tokenUsage         accessTokenUsageBuffer
 }
 
 var LogPrefix = "publicdashboards.service"
@@ -66,7 +67,7 @@ func ProvideService(
 }
 
 func (pd *PublicDashboardServiceImpl) GetPublicDashboardForView(ctx context.Context, accessToken string) (*dtos.DashboardFullWithMeta, error) {
-	pubdash, dash, err := pd.FindEnabledPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
+	pubdash, dash, token, err := pd.findEnabledByAccessToken(ctx, accessToken)
 	if err != nil {
 		return nil, err
 	}
@@ -88,6 +89,13 @@ func (pd *PublicDashboardServiceImpl) GetPublicDashboardForView(ctx context.Cont
 	}
 	dash.Data.Get("timepicker").Set("hidden", !pubdash.TimeSelectionEnabled)
 
+	if token != nil {
+		applyTemplateVariables(dash.Data, withPinnedVariables(pubdash, token).TemplateVariables, token.Variables)
+		pd.recordAccessTokenUsage(token, 1, 0)
+	} else {
+		applyTemplateVariables(dash.Data, pubdash.TemplateVariables, nil)
+	}
+
 	sanitizeData(dash.Data)
 
 	return &dtos.DashboardFullWithMeta{Meta: meta, Dashboard: dash.Data}, nil
@@ -122,49 +130,88 @@ func (pd *PublicDashboardServiceImpl) FindDashboard(ctx context.Context, orgId i
 
 // FindByAccessToken Gets public dashboard by access token
 func (pd *PublicDashboardServiceImpl) FindByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, error) {
+	pubdash, _, err := pd.findByAccessToken(ctx, accessToken)
+	return pubdash, err
+}
+
+// findByAccessToken Gets public dashboard by its access token or by one of its named access tokens, which is returned
+// as well. Expired named access tokens are rejected.
+func (pd *PublicDashboardServiceImpl) findByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *PublicDashboardAccessToken, error) {
 	pubdash, err := pd.store.FindByAccessToken(ctx, accessToken)
 	if err != nil {
-		return nil, ErrInternalServerError.Errorf("FindByAccessToken: failed to find a public dashboard: %w", err)
+		return nil, nil, ErrInternalServerError.Errorf("FindByAccessToken: failed to find a public dashboard: %w", err)
+	}
+
+	if pubdash != nil {
+		return pubdash, nil, nil
+	}
+
+	token, err := pd.store.FindAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, nil, ErrInternalServerError.Errorf("FindByAccessToken: failed to find a public dashboard access token: %w", err)
+	}
+
+	if token == nil {
+		return nil, nil, ErrPublicDashboardNotFound.Errorf("FindByAccessToken: Public dashboard not found accessToken: %s", accessToken)
+	}
+
+	if token.IsExpired(time.Now()) {
+		return nil, nil, ErrAccessTokenExpired.Errorf("FindByAccessToken: Public dashboard access token expired accessToken: %s", accessToken)
+	}
+
+	pubdash, err = pd.store.Find(ctx, token.PublicDashboardUid)
+	if err != nil {
+		return nil, nil, ErrInternalServerError.Errorf("FindByAccessToken: failed to find a public dashboard: %w", err)
 	}
 
 	if pubdash == nil {
-		return nil, ErrPublicDashboardNotFound.Errorf("FindByAccessToken: Public dashboard not found accessToken: %s", accessToken)
+		return nil, nil, ErrPublicDashboardNotFound.Errorf("FindByAccessToken: Public dashboard not found accessToken: %s", accessToken)
 	}
 
-	return pubdash, nil
+	return pubdash, token, nil
 }
 
 // FindEnabledPublicDashboardAndDashboardByAccessToken Gets public dashboard and a dashboard by access token if public dashboard is enabled
 func (pd *PublicDashboardServiceImpl) FindEnabledPublicDashboardAndDashboardByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *dashboards.Dashboard, error) {
-	pubdash, dash, err := pd.FindPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
+	pubdash, dash, _, err := pd.findEnabledByAccessToken(ctx, accessToken)
+	return pubdash, dash, err
+}
+
+func (pd *PublicDashboardServiceImpl) findEnabledByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *dashboards.Dashboard, *PublicDashboardAccessToken, error) {
+	pubdash, dash, token, err := pd.findPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
 	if err != nil {
-		return pubdash, dash, err
+		return pubdash, dash, token, err
 	}
 
 	if !pubdash.IsEnabled {
-		return nil, nil, ErrPublicDashboardNotEnabled.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard is not enabled accessToken: %s", accessToken)
+		return nil, nil, nil, ErrPublicDashboardNotEnabled.Errorf("FindEnabledPublicDashboardAndDashboardByAccessToken: Public dashboard is not enabled accessToken: %s", accessToken)
 	}
 
-	return pubdash, dash, err
+	return pubdash, dash, token, err
 }
 
 // FindPublicDashboardAndDashboardByAccessToken Gets public dashboard and a dashboard by access token
 func (pd *PublicDashboardServiceImpl) FindPublicDashboardAndDashboardByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *dashboards.Dashboard, error) {
-	pubdash, err := pd.FindByAccessToken(ctx, accessToken)
+	pubdash, dash, _, err := pd.findPublicDashboardAndDashboardByAccessToken(ctx, accessToken)
+	return pubdash, dash, err
+}
+
+func (pd *PublicDashboardServiceImpl) findPublicDashboardAndDashboardByAccessToken(ctx context.Context, accessToken string) (*PublicDashboard, *dashboards.Dashboard, *PublicDashboardAccessToken, error) {
+	pubdash, token, err := pd.findByAccessToken(ctx, accessToken)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	dash, err := pd.store.FindDashboard(ctx, pubdash.OrgId, pubdash.DashboardUid)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, nil, err
 	}
 
 	if dash == nil {
-		return nil, nil, ErrPublicDashboardNotFound.Errorf("FindPublicDashboardAndDashboardByAccessToken: Dashboard not found accessToken: %s", accessToken)
+		return nil, nil, nil, ErrPublicDashboardNotFound.Errorf("FindPublicDashboardAndDashboardByAccessToken: Dashboard not found accessToken: %s", accessToken)
 	}
 
-	return pubdash, dash, nil
+	return pubdash, dash, token, nil
 }
 
 // Creates and validates the public dashboard and saves it to the database
@@ -176,7 +223,12 @@ func (pd *PublicDashboardServiceImpl) Create(ctx context.Context, u *user.Signed
 	}
 
 	// ensure dashboard exists
-	_, err = pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	dash, err := pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validation.ValidateTemplateVariables(dash.Data, dto.PublicDashboard.TemplateVariables)
 	if err != nil {
 		return nil, err
 	}
@@ -227,11 +279,19 @@ func (pd *PublicDashboardServiceImpl) Update(ctx context.Context, u *user.Signed
 	}
 
 	// validate dashboard exists
-	_, err = pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
+	dash, err := pd.FindDashboard(ctx, u.OrgID, dto.DashboardUid)
 	if err != nil {
 		return nil, err
 	}
 
+	// the allowed values of the template variables are left unchanged when not provided
+	if dto.PublicDashboard.TemplateVariables != nil {
+		err = validation.ValidateTemplateVariables(dash.Data, dto.PublicDashboard.TemplateVariables)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// get existing public dashboard if exists
 	existingPubdash, err := pd.store.Find(ctx, dto.Uid)
 	if err != nil {
@@ -299,7 +359,12 @@ func (pd *PublicDashboardServiceImpl) NewPublicDashboardAccessToken(ctx context.
 		}
 
 		pubdash, _ := pd.store.FindByAccessToken(ctx, accessToken)
-		if pubdash == nil {
+		if pubdash != nil {
+			continue
+		}
+
+		token, _ := pd.store.FindAccessToken(ctx, accessToken)
+		if token == nil {
 			return accessToken, nil
 		}
 	}
@@ -325,11 +390,36 @@ func (pd *PublicDashboardServiceImpl) ExistsEnabledByDashboardUid(ctx context.Co
 }
 
 func (pd *PublicDashboardServiceImpl) ExistsEnabledByAccessToken(ctx context.Context, accessToken string) (bool, error) {
-	return pd.store.ExistsEnabledByAccessToken(ctx, accessToken)
+	exists, err := pd.store.ExistsEnabledByAccessToken(ctx, accessToken)
+	if err != nil || exists {
+		return exists, err
+	}
+
+	// the access token may be a named access token of the public dashboard
+	pubdash, _, err := pd.findByAccessToken(ctx, accessToken)
+	if err != nil {
+		if errors.Is(err, ErrPublicDashboardNotFound) || errors.Is(err, ErrAccessTokenExpired) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return pubdash.IsEnabled, nil
 }
 
 func (pd *PublicDashboardServiceImpl) GetOrgIdByAccessToken(ctx context.Context, accessToken string) (int64, error) {
-	return pd.store.GetOrgIdByAccessToken(ctx, accessToken)
+	orgId, err := pd.store.GetOrgIdByAccessToken(ctx, accessToken)
+	if err != nil || orgId != 0 {
+		return orgId, err
+	}
+
+	// the access token may be a named access token of the public dashboard
+	token, err := pd.store.FindAccessToken(ctx, accessToken)
+	if err != nil || token == nil {
+		return 0, err
+	}
+
+	return token.OrgId, nil
 }
 
 func (pd *PublicDashboardServiceImpl) Delete(ctx context.Context, uid string, dashboardUid string) error {
@@ -486,6 +576,7 @@ func (pd *PublicDashboardServiceImpl) newCreatePublicDashboard(ctx context.Conte
 		AnnotationsEnabled:   annotationsEnabled,
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         &TimeSettings{},
+		TemplateVariables:    dto.PublicDashboard.TemplateVariables,
 		Share:                share,
 		CreatedBy:            dto.UserId,
 		CreatedAt:            now,
@@ -506,12 +597,18 @@ func newUpdatePublicDashboard(dto *SavePublicDashboardDTO, pd *PublicDashboard)
 		share = pd.Share
 	}
 
+	templateVariables := pubdashDTO.TemplateVariables
+	if templateVariables == nil {
+		templateVariables = pd.TemplateVariables
+	}
+
 	return &PublicDashboard{
 		Uid:                  pd.Uid,
 		IsEnabled:            isEnabled,
 		AnnotationsEnabled:   annotationsEnabled,
 		TimeSelectionEnabled: timeSelectionEnabled,
 		TimeSettings:         pd.TimeSettings,
+		TemplateVariables:    templateVariables,
 		Share:                share,
 		UpdatedBy:            dto.UserId,
 		UpdatedAt:            time.Now(),
@@ -388,6 +388,7 @@ func TestGetPublicDashboardForView(t *testing.T) {
 			}
 
 			fakeStore.On("FindByAccessToken", mock.Anything, mock.Anything).Return(test.StoreResp.pd, test.StoreResp.err)
+			fakeStore.On("FindAccessToken", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 			fakeStore.On("FindDashboard", mock.Anything, mock.Anything, mock.Anything).Return(test.StoreResp.d, test.StoreResp.err)
 
 			dashboardFullWithMeta, err := service.GetPublicDashboardForView(context.Background(), test.AccessToken)
@@ -500,6 +501,7 @@ func TestGetPublicDashboard(t *testing.T) {
 			}
 
 			fakeStore.On("FindByAccessToken", mock.Anything, mock.Anything).Return(test.StoreResp.pd, test.StoreResp.err)
+			fakeStore.On("FindAccessToken", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 			fakeStore.On("FindDashboard", mock.Anything, mock.Anything, mock.Anything).Return(test.StoreResp.d, test.StoreResp.err)
 
 			pdc, dash, err := service.FindPublicDashboardAndDashboardByAccessToken(context.Background(), test.AccessToken)
@@ -566,6 +568,7 @@ func TestGetEnabledPublicDashboard(t *testing.T) {
 			}
 
 			fakeStore.On("FindByAccessToken", mock.Anything, mock.Anything).Return(test.StoreResp.pd, test.StoreResp.err)
+			fakeStore.On("FindAccessToken", mock.Anything, mock.Anything).Return(nil, nil).Maybe()
 			fakeStore.On("FindDashboard", mock.Anything, mock.Anything, mock.Anything).Return(test.StoreResp.d, test.StoreResp.err)
 
 			pdc, dash, err := service.FindEnabledPublicDashboardAndDashboardByAccessToken(context.Background(), test.AccessToken)
@@ -1702,6 +1705,8 @@ func TestPublicDashboardServiceImpl_NewPublicDashboardAccessToken(t *testing.T)
 			store := NewFakePublicDashboardStore(t)
 			store.On("FindByAccessToken", mock.Anything, mock.Anything).
 				Return(tt.mockStore.PublicDashboard, tt.mockStore.Err)
+			store.On("FindAccessToken", mock.Anything, mock.Anything).
+				Return(nil, nil).Maybe()
 
 			pd := &PublicDashboardServiceImpl{store: store}
 
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+)
+
+// variableHideVariable hides the variable from the dashboard, see VariableHide in the dashboard schema
+const variableHideVariable = 2
+
+// templateVariable is the value of a variable with allowed values
+type templateVariable struct {
+	values []string
+	// multi is true for the multi-value and include all variables, whose values are escaped as regexes or quoted by
+	// the data sources
+	multi bool
+}
+
+// variableRegex matches the $var, [[var:format]] and ${var:format} syntaxes of the template variables, like the
+// template service of the frontend does
+var variableRegex = regexp.MustCompile(`\$(\w+)|\[\[(\w+?)(?::(\w+))?\]\]|\$\{(\w+)(?::([^\}]+))?\}`)
+
+// withPinnedVariables returns the public dashboard with the variables pinned by the access token as their only allowed
+// value
+func withPinnedVariables(pubdash *PublicDashboard, token *PublicDashboardAccessToken) *PublicDashboard {
+	if token == nil || len(token.Variables) == 0 {
+		return pubdash
+	}
+
+	pinned := *pubdash
+	pinned.TemplateVariables = make(TemplateVariables, len(pubdash.TemplateVariables)+len(token.Variables))
+	for name, values := range pubdash.TemplateVariables {
+		pinned.TemplateVariables[name] = values
+	}
+	for name, value := range token.Variables {
+		pinned.TemplateVariables[name] = []string{value}
+	}
+
+	return &pinned
+}
+
+// applyTemplateVariables turns the variables with allowed values into custom variables offering only these values,
+// since the queries of the variables are not run for public dashboards, and hides the pinned variables
+func applyTemplateVariables(data *simplejson.Json, templateVariables TemplateVariables, pinned map[string]string) {
+	for _, variableObj := range data.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		name := variable.Get("name").MustString()
+		allowed, ok := templateVariables[name]
+		if !ok {
+			continue
+		}
+
+		current := defaultVariableValues(variable, allowed)
+		options := make([]any, 0, len(allowed))
+		escaped := make([]string, 0, len(allowed))
+		for _, value := range allowed {
+			options = append(options, map[string]any{"text": value, "value": value, "selected": slices.Contains(current, value)})
+			escaped = append(escaped, strings.ReplaceAll(value, ",", `\,`))
+		}
+
+		variable.Set("type", "custom")
+		variable.Set("query", strings.Join(escaped, ","))
+		variable.Del("datasource")
+		variable.Del("definition")
+		variable.Del("regex")
+		variable.Set("includeAll", false)
+		variable.Set("options", options)
+		if variable.Get("multi").MustBool() {
+			variable.Set("current", map[string]any{"text": current, "value": current})
+		} else {
+			variable.Set("current", map[string]any{"text": current[0], "value": current[0]})
+		}
+
+		if _, ok := pinned[name]; ok {
+			variable.Set("hide", variableHideVariable)
+		}
+	}
+}
+
+// resolveTemplateVariables returns the values of the variables with allowed values: the ones selected by the viewer,
+// which have been validated, or the default ones
+func resolveTemplateVariables(data *simplejson.Json, templateVariables TemplateVariables, selected map[string][]string) map[string]templateVariable {
+	if len(templateVariables) == 0 {
+		return nil
+	}
+
+	variables := make(map[string]*simplejson.Json)
+	for _, variableObj := range data.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		variables[variable.Get("name").MustString()] = variable
+	}
+
+	values := make(map[string]templateVariable, len(templateVariables))
+	for name, allowed := range templateVariables {
+		variable, ok := variables[name]
+		if !ok {
+			variable = simplejson.New()
+		}
+		multi := variable.Get("multi").MustBool() || variable.Get("includeAll").MustBool()
+
+		if selectedValues, ok := selected[name]; ok {
+			values[name] = templateVariable{values: selectedValues, multi: multi}
+			continue
+		}
+		values[name] = templateVariable{values: defaultVariableValues(variable, allowed), multi: multi}
+	}
+
+	return values
+}
+
+// defaultVariableValues returns the current values of the variable in the dashboard if they are allowed, or the first
+// allowed value
+func defaultVariableValues(variable *simplejson.Json, allowed []string) []string {
+	var current []string
+	switch value := variable.GetPath("current", "value").Interface().(type) {
+	case string:
+		current = []string{value}
+	case []any:
+		for _, item := range value {
+			if s, ok := item.(string); ok {
+				current = append(current, s)
+			}
+		}
+	}
+
+	if len(current) > 0 && !slices.ContainsFunc(current, func(value string) bool { return !slices.Contains(allowed, value) }) {
+		return current
+	}
+
+	if len(allowed) == 0 {
+		return []string{""}
+	}
+	return allowed[:1]
+}
+
+// interpolateQuery replaces the variables with allowed values in the query, formatted for its data source
+func interpolateQuery(query *simplejson.Json, variables map[string]templateVariable) {
+	if len(variables) == 0 {
+		return
+	}
+
+	queryMap, err := query.Map()
+	if err != nil {
+		return
+	}
+
+	datasourceType := query.Get("datasource").Get("type").MustString()
+	for key, value := range queryMap {
+		if key == "datasource" || key == "refId" {
+			continue
+		}
+		queryMap[key] = interpolateValue(value, variables, datasourceType)
+	}
+}
+
+func interpolateValue(value any, variables map[string]templateVariable, datasourceType string) any {
+	switch v := value.(type) {
+	case string:
+		return interpolateString(v, variables, datasourceType)
+	case map[string]any:
+		for key, item := range v {
+			v[key] = interpolateValue(item, variables, datasourceType)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = interpolateValue(item, variables, datasourceType)
+		}
+	}
+	return value
+}
+
+// interpolateString replaces the variables with allowed values in the string. Other variables, like the global
+// variables interpolated by the data sources, are left unchanged.
+func interpolateString(s string, variables map[string]templateVariable, datasourceType string) string {
+	return variableRegex.ReplaceAllStringFunc(s, func(match string) string {
+		groups := variableRegex.FindStringSubmatch(match)
+		name := groups[1] + groups[2] + groups[4]
+		format := groups[3] + groups[5]
+
+		variable, ok := variables[name]
+		if !ok {
+			return match
+		}
+		return formatVariableValues(variable, format, datasourceType)
+	})
+}
+
+// formatVariableValues formats the values of a variable like the template service of the frontend does: with the
+// format of the variable if any, and like the data source interpolates them otherwise. Multiple values are formatted
+// as a glob by the data sources which don't interpolate them.
+func formatVariableValues(variable templateVariable, format string, datasourceType string) string {
+	values := variable.values
+	switch format {
+	case "":
+		switch datasourceType {
+		case datasources.DS_PROMETHEUS:
+			return formatPrometheusValues(variable)
+		case datasources.DS_LOKI:
+			return formatLokiValues(variable)
+		case datasources.DS_MYSQL, datasources.DS_POSTGRES, datasources.DS_MSSQL:
+			return formatSQLValues(variable)
+		}
+		return globValues(values)
+	case "csv", "raw", "text":
+		return strings.Join(values, ",")
+	case "pipe":
+		return strings.Join(values, "|")
+	case "regex":
+		// the regexes of PromQL and LogQL are string literals, in which the backslashes are escaped
+		escape := regexEscape
+		if datasourceType == datasources.DS_PROMETHEUS || datasourceType == datasources.DS_LOKI {
+			escape = prometheusRegexEscape
+		}
+		escaped := make([]string, 0, len(values))
+		for _, value := range values {
+			escaped = append(escaped, escape(value))
+		}
+		if len(escaped) == 1 {
+			return escaped[0]
+		}
+		return "(" + strings.Join(escaped, "|") + ")"
+	case "singlequote":
+		return quoteValues(values, "'", `\'`)
+	case "doublequote":
+		return quoteValues(values, `"`, `\"`)
+	case "sqlstring":
+		return quoteValues(values, "'", "''")
+	case "json":
+		var b []byte
+		if len(values) == 1 {
+			b, _ = json.Marshal(values[0])
+		} else {
+			b, _ = json.Marshal(values)
+		}
+		return string(b)
+	case "percentencode":
+		return url.QueryEscape(globValues(values))
+	default:
+		return globValues(values)
+	}
+}
+
+func globValues(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return "{" + strings.Join(values, ",") + "}"
+}
+
+// formatPrometheusValues formats the values like the Prometheus data source: escaped as a regex alternation when the
+// variable has multiple values, so that they can be used in regex label matchers
+func formatPrometheusValues(variable templateVariable) string {
+	if !variable.multi {
+		return prometheusRegularEscaper.Replace(strings.Join(variable.values, ","))
+	}
+
+	escaped := make([]string, 0, len(variable.values))
+	for _, value := range variable.values {
+		escaped = append(escaped, prometheusRegexEscape(value))
+	}
+	if len(escaped) == 1 {
+		return escaped[0]
+	}
+	return "(" + strings.Join(escaped, "|") + ")"
+}
+
+// formatLokiValues formats the values like the Loki data source, which doesn't group the regex alternation
+func formatLokiValues(variable templateVariable) string {
+	if !variable.multi {
+		return lokiRegularEscaper.Replace(strings.Join(variable.values, ","))
+	}
+
+	escaped := make([]string, 0, len(variable.values))
+	for _, value := range variable.values {
+		escaped = append(escaped, lokiRegularEscaper.Replace(specialRegexEscape(value, lokiRegexMetachars)))
+	}
+	return strings.Join(escaped, "|")
+}
+
+// formatSQLValues formats the values like the SQL data sources: as a list of string literals when the variable has
+// multiple values, so that they can be used in IN clauses
+func formatSQLValues(variable templateVariable) string {
+	if !variable.multi {
+		return strings.ReplaceAll(strings.Join(variable.values, ","), "'", "''")
+	}
+	return quoteValues(variable.values, "'", "''")
+}
+
+var (
+	prometheusRegularEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\\'`)
+	lokiRegularEscaper       = strings.NewReplacer(`'`, `\\'`)
+)
+
+const (
+	prometheusRegexMetachars = `$^*{}[]'+?.()|`
+	lokiRegexMetachars       = `$^*{}[]+?.()|`
+)
+
+// prometheusRegexEscape escapes the regex metacharacters of the value in a PromQL string literal
+func prometheusRegexEscape(value string) string {
+	return specialRegexEscape(value, prometheusRegexMetachars)
+}
+
+// specialRegexEscape escapes the backslashes and the given regex metacharacters of the value in a string literal
+func specialRegexEscape(value string, metachars string) string {
+	var sb strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '\\':
+			sb.WriteString(`\\\\`)
+		case strings.ContainsRune(metachars, r):
+			sb.WriteString(`\\`)
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// regexEscape escapes the regex metacharacters of the value, and the slashes delimiting regexes
+func regexEscape(value string) string {
+	return strings.ReplaceAll(regexp.QuoteMeta(value), "/", `\/`)
+}
+
+func quoteValues(values []string, quote string, escapedQuote string) string {
+	quoted := make([]string, 0, len(values))
+	for _, value := range values {
+		quoted = append(quoted, quote+strings.ReplaceAll(value, quote, escapedQuote)+quote)
+	}
+	return strings.Join(quoted, ",")
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/infra/log"
+	dashboardsDB "github.com/grafana/grafana/pkg/services/dashboards/database"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/services/featuremgmt"
+	"github.com/grafana/grafana/pkg/services/publicdashboards/database"
+	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
+	"github.com/grafana/grafana/pkg/services/quota/quotatest"
+	"github.com/grafana/grafana/pkg/services/tag/tagimpl"
+)
+
+func TestInterpolateString(t *testing.T) {
+	variables := map[string]templateVariable{
+		"region": {values: []string{"eu"}},
+		"host":   {values: []string{"a'1", "b.2"}, multi: true},
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "dollar syntax", query: "region=$region", expected: "region=eu"},
+		{name: "braces syntax", query: "region=${region}", expected: "region=eu"},
+		{name: "brackets syntax with format", query: "host=[[host:csv]]", expected: "host=a'1,b.2"},
+		{name: "multiple values as glob by default", query: "host=$host", expected: "host={a'1,b.2}"},
+		{name: "pipe format", query: "${host:pipe}", expected: "a'1|b.2"},
+		{name: "regex format", query: "${host:regex}", expected: `(a'1|b\.2)`},
+		{name: "singlequote format", query: "${host:singlequote}", expected: `'a\'1','b.2'`},
+		{name: "doublequote format", query: "${host:doublequote}", expected: `"a'1","b.2"`},
+		{name: "sqlstring format", query: "${host:sqlstring}", expected: "'a''1','b.2'"},
+		{name: "json format", query: "${host:json}", expected: `["a'1","b.2"]`},
+		{name: "percentencode format", query: "${region:percentencode}", expected: "eu"},
+		{name: "variables without allowed values are left unchanged", query: "$env $__interval ${__from}", expected: "$env $__interval ${__from}"},
+		{name: "longer variable names are not matched", query: "$regionName", expected: "$regionName"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, interpolateString(tt.query, variables, ""))
+		})
+	}
+}
+
+func TestInterpolateStringForDataSource(t *testing.T) {
+	variables := map[string]templateVariable{
+		"region": {values: []string{"e'u"}},
+		"job":    {values: []string{"api"}, multi: true},
+		"host":   {values: []string{"a'1", `b.2\`}, multi: true},
+	}
+
+	tests := []struct {
+		name           string
+		datasourceType string
+		query          string
+		expected       string
+	}{
+		{name: "prometheus single value", datasourceType: datasources.DS_PROMETHEUS, query: `up{region="$region"}`, expected: `up{region="e\\'u"}`},
+		{name: "prometheus multi-value variable with one value", datasourceType: datasources.DS_PROMETHEUS, query: `up{job=~"$job"}`, expected: `up{job=~"api"}`},
+		{name: "prometheus multiple values as regex", datasourceType: datasources.DS_PROMETHEUS, query: `up{host=~"$host"}`, expected: `up{host=~"(a\\'1|b\\.2\\\\)"}`},
+		{name: "prometheus regex format", datasourceType: datasources.DS_PROMETHEUS, query: `up{host=~"${host:regex}"}`, expected: `up{host=~"(a\\'1|b\\.2\\\\)"}`},
+		{name: "loki multiple values as regex", datasourceType: datasources.DS_LOKI, query: `{host=~"$host"}`, expected: `{host=~"a\\'1|b\\.2\\\\"}`},
+		{name: "sql single value", datasourceType: datasources.DS_MYSQL, query: "WHERE region = '$region'", expected: "WHERE region = 'e''u'"},
+		{name: "sql multiple values as list", datasourceType: datasources.DS_POSTGRES, query: "WHERE host IN ($host)", expected: `WHERE host IN ('a''1','b.2\')`},
+		{name: "sql multi-value variable with one value", datasourceType: datasources.DS_MSSQL, query: "WHERE job IN ($job)", expected: "WHERE job IN ('api')"},
+		{name: "sql explicit format", datasourceType: datasources.DS_MYSQL, query: "WHERE host = '${host:csv}'", expected: `WHERE host = 'a'1,b.2\'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, interpolateString(tt.query, variables, tt.datasourceType))
+		})
+	}
+}
+
+func TestInterpolateQuery(t *testing.T) {
+	query := simplejson.NewFromAny(map[string]any{
+		"datasource": map[string]any{"uid": "$region", "type": datasources.DS_PROMETHEUS},
+		"refId":      "$region",
+		"expr":       `up{region="$region"}`,
+		"filters":    []any{map[string]any{"value": "$region"}},
+		"hide":       false,
+	})
+
+	interpolateQuery(query, map[string]templateVariable{"region": {values: []string{"eu"}}})
+
+	assert.Equal(t, "$region", query.GetPath("datasource", "uid").MustString())
+	assert.Equal(t, "$region", query.Get("refId").MustString())
+	assert.Equal(t, `up{region="eu"}`, query.Get("expr").MustString())
+	assert.Equal(t, "eu", simplejson.NewFromAny(query.Get("filters").MustArray()[0]).Get("value").MustString())
+	assert.False(t, query.Get("hide").MustBool())
+}
+
+func TestResolveTemplateVariables(t *testing.T) {
+	data := simplejson.NewFromAny(map[string]any{
+		"templating": map[string]any{
+			"list": []any{
+				map[string]any{"name": "region", "current": map[string]any{"value": "us"}},
+				map[string]any{"name": "host", "multi": true, "current": map[string]any{"value": []any{"a", "z"}}},
+			},
+		},
+	})
+	templateVariables := TemplateVariables{"region": {"eu", "us"}, "host": {"a", "b"}, "env": {"prod"}}
+
+	t.Run("uses the current values of the dashboard when allowed and the first allowed value otherwise", func(t *testing.T) {
+		values := resolveTemplateVariables(data, templateVariables, nil)
+		assert.Equal(t, map[string]templateVariable{
+			"region": {values: []string{"us"}},
+			"host":   {values: []string{"a"}, multi: true},
+			"env":    {values: []string{"prod"}},
+		}, values)
+	})
+
+	t.Run("uses the selected values", func(t *testing.T) {
+		values := resolveTemplateVariables(data, templateVariables, map[string][]string{"host": {"a", "b"}})
+		assert.Equal(t, templateVariable{values: []string{"a", "b"}, multi: true}, values["host"])
+	})
+}
+
+func TestApplyTemplateVariables(t *testing.T) {
+	data := simplejson.NewFromAny(map[string]any{
+		"templating": map[string]any{
+			"list": []any{
+				map[string]any{
+					"name":       "host",
+					"type":       "query",
+					"multi":      true,
+					"includeAll": true,
+					"datasource": map[string]any{"uid": "ds1"},
+					"definition": "label_values(host)",
+					"query":      "label_values(host)",
+					"current":    map[string]any{"text": []any{"b"}, "value": []any{"b"}},
+				},
+				map[string]any{"name": "region", "type": "custom", "query": "eu,us,ap"},
+				map[string]any{"name": "env", "type": "custom", "query": "prod,dev"},
+			},
+		},
+	})
+
+	applyTemplateVariables(data, TemplateVariables{"host": {"a", "b,c"}, "region": {"us"}}, map[string]string{"region": "us"})
+
+	variables := data.Get("templating").Get("list").MustArray()
+	host := simplejson.NewFromAny(variables[0])
+	assert.Equal(t, "custom", host.Get("type").MustString())
+	assert.Equal(t, `a,b\,c`, host.Get("query").MustString())
+	assert.False(t, host.Get("includeAll").MustBool())
+	_, hasDatasource := host.CheckGet("datasource")
+	assert.False(t, hasDatasource)
+	_, hasDefinition := host.CheckGet("definition")
+	assert.False(t, hasDefinition)
+	assert.Len(t, host.Get("options").MustArray(), 2)
+	// the current value of the dashboard is not allowed
+	assert.Equal(t, []string{"a"}, host.GetPath("current", "value").Interface())
+	_, hidden := host.CheckGet("hide")
+	assert.False(t, hidden)
+
+	region := simplejson.NewFromAny(variables[1])
+	assert.Equal(t, "us", region.GetPath("current", "value").MustString())
+	assert.Equal(t, variableHideVariable, region.Get("hide").MustInt())
+
+	env := simplejson.NewFromAny(variables[2])
+	assert.Equal(t, "prod,dev", env.Get("query").MustString())
+}
+
+func TestSaveTemplateVariables(t *testing.T) {
+	sqlStore := db.InitTestDB(t)
+	dashboardStore, err := dashboardsDB.ProvideDashboardStore(sqlStore, sqlStore.Cfg, featuremgmt.WithFeatures(), tagimpl.ProvideService(sqlStore, sqlStore.Cfg), quotatest.New(false, nil))
+	require.NoError(t, err)
+	publicdashboardStore := database.ProvideStore(sqlStore, sqlStore.Cfg, featuremgmt.WithFeatures())
+	templateVars := []map[string]any{
+		{"name": "region", "type": "custom"},
+		{"name": "interval", "type": "interval"},
+	}
+	dashboard := insertTestDashboard(t, dashboardStore, "testDashie", 1, 0, "", true, templateVars, nil)
+
+	service := &PublicDashboardServiceImpl{
+		log:            log.New("test.logger"),
+		store:          publicdashboardStore,
+		serviceWrapper: ProvideServiceWrapper(publicdashboardStore),
+	}
+
+	isEnabled := true
+	pubdash, err := service.Create(context.Background(), SignedInUser, &SavePublicDashboardDTO{
+		DashboardUid: dashboard.UID,
+		OrgID:        dashboard.OrgID,
+		PublicDashboard: &PublicDashboardDTO{
+			IsEnabled:         &isEnabled,
+			TemplateVariables: TemplateVariables{"region": {"eu", "us"}},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, TemplateVariables{"region": {"eu", "us"}}, pubdash.TemplateVariables)
+
+	t.Run("Update keeps the template variables when not provided", func(t *testing.T) {
+		updated, err := service.Update(context.Background(), SignedInUser, &SavePublicDashboardDTO{
+			Uid:             pubdash.Uid,
+			DashboardUid:    dashboard.UID,
+			OrgID:           dashboard.OrgID,
+			PublicDashboard: &PublicDashboardDTO{},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, TemplateVariables{"region": {"eu", "us"}}, updated.TemplateVariables)
+	})
+
+	t.Run("Update replaces the template variables", func(t *testing.T) {
+		updated, err := service.Update(context.Background(), SignedInUser, &SavePublicDashboardDTO{
+			Uid:             pubdash.Uid,
+			DashboardUid:    dashboard.UID,
+			OrgID:           dashboard.OrgID,
+			PublicDashboard: &PublicDashboardDTO{TemplateVariables: TemplateVariables{"region": {"ap"}}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, TemplateVariables{"region": {"ap"}}, updated.TemplateVariables)
+	})
+
+	t.Run("Update returns an error when a variable is not supported", func(t *testing.T) {
+		_, err := service.Update(context.Background(), SignedInUser, &SavePublicDashboardDTO{
+			Uid:             pubdash.Uid,
+			DashboardUid:    dashboard.UID,
+			OrgID:           dashboard.OrgID,
+			PublicDashboard: &PublicDashboardDTO{TemplateVariables: TemplateVariables{"interval": {"1m"}}},
+		})
+		assert.ErrorIs(t, err, ErrInvalidTemplateVariables)
+	})
+}
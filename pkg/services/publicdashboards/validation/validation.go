@@ -1,7 +1,12 @@
 package validation
 
 import (
+	"net"
+	"slices"
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/grafana/grafana/pkg/tsdb/legacydata"
 	"github.com/grafana/grafana/pkg/util"
@@ -16,6 +21,61 @@ func ValidatePublicDashboard(dto *SavePublicDashboardDTO) error {
 	return nil
 }
 
+// supportedVariableTypes are the types of the variables whose values can be selected by the viewers of a public dashboard
+var supportedVariableTypes = []string{"query", "custom"}
+
+// ValidateTemplateVariables checks that the template variables are query or custom variables of the dashboard and
+// that they have allowed values
+func ValidateTemplateVariables(dashboardData *simplejson.Json, templateVariables TemplateVariables) error {
+	if len(templateVariables) == 0 {
+		return nil
+	}
+
+	variableTypes := make(map[string]string)
+	for _, variableObj := range dashboardData.Get("templating").Get("list").MustArray() {
+		variable := simplejson.NewFromAny(variableObj)
+		variableTypes[variable.Get("name").MustString()] = variable.Get("type").MustString()
+	}
+
+	for name, values := range templateVariables {
+		variableType, ok := variableTypes[name]
+		if !ok {
+			return ErrInvalidTemplateVariables.Errorf("ValidateTemplateVariables: variable %s not found in dashboard", name)
+		}
+
+		if !slices.Contains(supportedVariableTypes, variableType) {
+			return ErrInvalidTemplateVariables.Errorf("ValidateTemplateVariables: variable %s of type %s is not supported", name, variableType)
+		}
+
+		if len(values) == 0 {
+			return ErrInvalidTemplateVariables.Errorf("ValidateTemplateVariables: variable %s has no allowed values", name)
+		}
+	}
+
+	return nil
+}
+
+// maxAccessTokenNameLength is the length of the name column of the dashboard_public_access_token table
+const maxAccessTokenNameLength = 190
+
+func ValidateAccessToken(dto *SavePublicDashboardAccessTokenDTO, now time.Time) error {
+	if dto.AccessToken.Name == "" || len(dto.AccessToken.Name) > maxAccessTokenNameLength {
+		return ErrInvalidAccessTokenName.Errorf("ValidateAccessToken: name should be between 1 and %d characters", maxAccessTokenNameLength)
+	}
+
+	if dto.AccessToken.ExpiresAt != nil && !dto.AccessToken.ExpiresAt.After(now) {
+		return ErrInvalidAccessTokenExpiry.Errorf("ValidateAccessToken: expiry is in the past")
+	}
+
+	for _, allowed := range dto.AccessToken.IPAllowlist {
+		if !IsValidIPOrCIDR(allowed) {
+			return ErrInvalidIPAllowlist.Errorf("ValidateAccessToken: invalid IP or CIDR %s", allowed)
+		}
+	}
+
+	return nil
+}
+
 func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *PublicDashboard) error {
 	if req.IntervalMs < 0 {
 		return ErrInvalidInterval.Errorf("ValidateQueryPublicDashboardRequest: intervalMS should be greater than 0")
@@ -38,6 +98,23 @@ func ValidateQueryPublicDashboardRequest(req PublicDashboardQueryDTO, pd *Public
 		}
 	}
 
+	// the values of the variables without allowed values are ignored, they are not interpolated by the server
+	for name, values := range req.Variables {
+		allowed, ok := pd.TemplateVariables[name]
+		if !ok {
+			continue
+		}
+		if len(values) == 0 {
+			return ErrTemplateVariableValueNotAllowed.Errorf("ValidateQueryPublicDashboardRequest: variable %s has no value", name)
+		}
+
+		for _, value := range values {
+			if !slices.Contains(allowed, value) {
+				return ErrTemplateVariableValueNotAllowed.Errorf("ValidateQueryPublicDashboardRequest: value %s is not allowed for variable %s", value, name)
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -61,3 +138,11 @@ func IsValidShareType(shareType ShareType) bool {
 	}
 	return false
 }
+
+// IsValidIPOrCIDR checks that the value is an IP address or a CIDR range
+func IsValidIPOrCIDR(value string) bool {
+	if _, _, err := net.ParseCIDR(value); err == nil {
+		return true
+	}
+	return net.ParseIP(value) != nil
+}
@@ -1,8 +1,11 @@
 package validation
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	. "github.com/grafana/grafana/pkg/services/publicdashboards/models"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -24,6 +27,107 @@ func TestValidatePublicDashboard(t *testing.T) {
 	})
 }
 
+func TestValidateTemplateVariables(t *testing.T) {
+	dashboardData := simplejson.NewFromAny(map[string]any{
+		"templating": map[string]any{
+			"list": []any{
+				map[string]any{"name": "region", "type": "custom"},
+				map[string]any{"name": "host", "type": "query"},
+				map[string]any{"name": "interval", "type": "interval"},
+			},
+		},
+	})
+
+	tests := []struct {
+		name              string
+		templateVariables TemplateVariables
+		wantErr           error
+	}{
+		{
+			name: "Returns no error when there are no template variables",
+		},
+		{
+			name:              "Returns no error when query and custom variables have allowed values",
+			templateVariables: TemplateVariables{"region": {"eu", "us"}, "host": {"a"}},
+		},
+		{
+			name:              "Returns error when variable is not in the dashboard",
+			templateVariables: TemplateVariables{"env": {"prod"}},
+			wantErr:           ErrInvalidTemplateVariables,
+		},
+		{
+			name:              "Returns error when variable type is not supported",
+			templateVariables: TemplateVariables{"interval": {"1m"}},
+			wantErr:           ErrInvalidTemplateVariables,
+		},
+		{
+			name:              "Returns error when variable has no allowed values",
+			templateVariables: TemplateVariables{"region": {}},
+			wantErr:           ErrInvalidTemplateVariables,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateTemplateVariables(dashboardData, tt.templateVariables)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestValidateAccessToken(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		accessToken PublicDashboardAccessTokenDTO
+		wantErr     error
+	}{
+		{
+			name:        "Returns no error when input is valid",
+			accessToken: PublicDashboardAccessTokenDTO{Name: "customer", ExpiresAt: &future, IPAllowlist: []string{"10.0.0.1", "192.168.0.0/16", "::1"}},
+		},
+		{
+			name:        "Returns error when name is empty",
+			accessToken: PublicDashboardAccessTokenDTO{},
+			wantErr:     ErrInvalidAccessTokenName,
+		},
+		{
+			name:        "Returns error when name is too long",
+			accessToken: PublicDashboardAccessTokenDTO{Name: strings.Repeat("a", 191)},
+			wantErr:     ErrInvalidAccessTokenName,
+		},
+		{
+			name:        "Returns error when expiry is in the past",
+			accessToken: PublicDashboardAccessTokenDTO{Name: "customer", ExpiresAt: &past},
+			wantErr:     ErrInvalidAccessTokenExpiry,
+		},
+		{
+			name:        "Returns error when IP allowlist has an invalid entry",
+			accessToken: PublicDashboardAccessTokenDTO{Name: "customer", IPAllowlist: []string{"10.0.0.1", "10.0.0"}},
+			wantErr:     ErrInvalidIPAllowlist,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			accessToken := tt.accessToken
+			err := ValidateAccessToken(&SavePublicDashboardAccessTokenDTO{AccessToken: &accessToken}, now)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
 func TestValidateQueryPublicDashboardRequest(t *testing.T) {
 	type args struct {
 		req PublicDashboardQueryDTO
@@ -114,6 +218,54 @@ func TestValidateQueryPublicDashboardRequest(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Returns no error when variable values are allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"region": {"eu", "us"}},
+				},
+				pd: &PublicDashboard{
+					TemplateVariables: TemplateVariables{"region": {"eu", "us", "ap"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Returns validation error when variable value is not allowed",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"region": {"eu", "' OR 1=1 --"}},
+				},
+				pd: &PublicDashboard{
+					TemplateVariables: TemplateVariables{"region": {"eu", "us"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Returns no error when variable has no allowed values",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"host": {"a"}},
+				},
+				pd: &PublicDashboard{
+					TemplateVariables: TemplateVariables{"region": {"eu"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "Returns validation error when variable with allowed values has no value",
+			args: args{
+				req: PublicDashboardQueryDTO{
+					Variables: map[string][]string{"region": {}},
+				},
+				pd: &PublicDashboard{
+					TemplateVariables: TemplateVariables{"region": {"eu"}},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "Returns validation error when time range from or to is blank",
 			args: args{
@@ -20,6 +20,7 @@ import (
 	"github.com/grafana/grafana/pkg/services/contexthandler"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/pluginsintegration/plugincontext"
+	"github.com/grafana/grafana/pkg/services/queryrecording"
 	"github.com/grafana/grafana/pkg/services/validations"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/grafanads"
@@ -43,6 +44,7 @@ func ProvideService(
 	pluginRequestValidator validations.PluginRequestValidator,
 	pluginClient plugins.Client,
 	pCtxProvider *plugincontext.Provider,
+	recorder queryrecording.Recorder,
 ) *ServiceImpl {
 	g := &ServiceImpl{
 		cfg:                    cfg,
@@ -53,6 +55,7 @@ func ProvideService(
 		pCtxProvider:           pCtxProvider,
 		log:                    log.New("query_data"),
 		concurrentQueryLimit:   cfg.SectionWithEnvOverrides("query").Key("concurrent_query_limit").MustInt(runtime.NumCPU()),
+		recorder:               recorder,
 	}
 	g.log.Info("Query Service initialization")
 	return g
@@ -76,6 +79,7 @@ type ServiceImpl struct {
 	pCtxProvider           *plugincontext.Provider
 	log                    log.Logger
 	concurrentQueryLimit   int
+	recorder               queryrecording.Recorder
 }
 
 // Run ServiceImpl.
@@ -267,7 +271,11 @@ func (s *ServiceImpl) handleQuerySingleDatasource(ctx context.Context, user iden
 		req.Queries = append(req.Queries, q.query)
 	}
 
-	return s.pluginClient.QueryData(ctx, req)
+	resp, err := s.pluginClient.QueryData(ctx, req)
+	if err == nil && s.recorder != nil {
+		s.recorder.Record(ds, req, resp)
+	}
+	return resp, err
 }
 
 // parseRequest parses a request into parsed queries grouped by datasource uid
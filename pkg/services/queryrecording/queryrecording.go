@@ -0,0 +1,124 @@
+package queryrecording
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+	testdatasource "github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource"
+)
+
+// number of recordings waiting to be written, recordings are dropped when the queue is full
+const queueSize = 100
+
+// Recorder records the responses of data source queries.
+type Recorder interface {
+	Record(ds *datasources.DataSource, req *backend.QueryDataRequest, resp *backend.QueryDataResponse)
+}
+
+var _ Recorder = (*Service)(nil)
+
+// Service writes the responses of data source queries to the recordings
+// directory of the server, to be served by the replay scenario of the
+// TestData data source. Recordings are written in the background.
+type Service struct {
+	path  string
+	queue chan *encodedRecording
+	log   log.Logger
+}
+
+// encodedRecording is a recording encoded when queued, so that the response
+// can be changed by the caller of the query while the recording waits to be
+// written.
+type encodedRecording struct {
+	// recording without its response
+	rec  *testdatasource.Recording
+	data []byte
+}
+
+func ProvideService(cfg *setting.Cfg) *Service {
+	return &Service{
+		path:  RecordingsPath(cfg),
+		queue: make(chan *encodedRecording, queueSize),
+		log:   log.New("query_recording"),
+	}
+}
+
+// RecordingsPath returns the directory of the recordings, recording is
+// disabled when empty.
+func RecordingsPath(cfg *setting.Cfg) string {
+	return cfg.SectionWithEnvOverrides("query").Key("record_responses_path").MustString("")
+}
+
+// IsDisabled returns true when no recordings directory is configured.
+func (s *Service) IsDisabled() bool {
+	return s.path == ""
+}
+
+// Run writes the queued recordings until ctx is done.
+func (s *Service) Run(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case rec := <-s.queue:
+			s.write(rec)
+		}
+	}
+}
+
+// Record queues a recording of the response of each query of req.
+func (s *Service) Record(ds *datasources.DataSource, req *backend.QueryDataRequest, resp *backend.QueryDataResponse) {
+	// responses of the TestData data source are not recorded, they may be replays already
+	if s.IsDisabled() || ds.Type == datasources.DS_TESTDATA {
+		return
+	}
+
+	now := time.Now()
+	for _, q := range req.Queries {
+		dr, ok := resp.Responses[q.RefID]
+		if !ok {
+			continue
+		}
+		rec := &testdatasource.Recording{
+			OrgID:          ds.OrgID,
+			RefID:          q.RefID,
+			QueryHash:      testdatasource.QueryHash(ds.UID, q.JSON),
+			DatasourceUID:  ds.UID,
+			DatasourceType: ds.Type,
+			Query:          q.JSON,
+			From:           q.TimeRange.From,
+			To:             q.TimeRange.To,
+			RecordedAt:     now,
+			Response: backend.QueryDataResponse{
+				Responses: backend.Responses{q.RefID: dr},
+			},
+		}
+		// the response is encoded before Record returns, the caller may change its frames afterwards
+		b, err := json.Marshal(rec)
+		if err != nil {
+			s.log.Warn("Failed to encode query response recording", "refId", q.RefID, "datasourceUid", ds.UID, "error", err)
+			continue
+		}
+		rec.Response = backend.QueryDataResponse{}
+		select {
+		case s.queue <- &encodedRecording{rec: rec, data: b}:
+		default:
+			s.log.Warn("Dropped query response recording, too many recordings are waiting to be written", "refId", q.RefID, "datasourceUid", ds.UID)
+		}
+	}
+}
+
+func (s *Service) write(encoded *encodedRecording) {
+	rec := encoded.rec
+	if err := testdatasource.WriteEncodedRecording(s.path, rec, encoded.data); err != nil {
+		s.log.Warn("Failed to record query response", "refId", rec.RefID, "datasourceUid", rec.DatasourceUID, "error", err)
+		return
+	}
+	s.log.Debug("Recorded query response", "refId", rec.RefID, "datasourceUid", rec.DatasourceUID, "queryHash", rec.QueryHash)
+}
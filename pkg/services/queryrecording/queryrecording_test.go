@@ -0,0 +1,117 @@
+package queryrecording
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/services/datasources"
+	"github.com/grafana/grafana/pkg/setting"
+	testdatasource "github.com/grafana/grafana/pkg/tsdb/grafana-testdata-datasource"
+)
+
+func TestRecord(t *testing.T) {
+	from := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	req := &backend.QueryDataRequest{
+		Queries: []backend.DataQuery{
+			{RefID: "A", TimeRange: backend.TimeRange{From: from, To: to}, JSON: json.RawMessage(`{"refId":"A","expr":"up"}`)},
+			{RefID: "B", TimeRange: backend.TimeRange{From: from, To: to}, JSON: json.RawMessage(`{"refId":"B","expr":"down"}`)},
+		},
+	}
+	resp := &backend.QueryDataResponse{Responses: backend.Responses{
+		"A": {Frames: data.Frames{data.NewFrame("up", data.NewField("value", nil, []float64{1}))}},
+	}}
+
+	newService := func(t *testing.T) *Service {
+		cfg := setting.NewCfg()
+		_, err := cfg.Raw.Section("query").NewKey("record_responses_path", t.TempDir())
+		require.NoError(t, err)
+		return ProvideService(cfg)
+	}
+
+	t.Run("writes a recording for each query with a response in the directory of the data source", func(t *testing.T) {
+		s := newService(t)
+		s.Record(&datasources.DataSource{OrgID: 2, UID: "ds1", Type: "prometheus"}, req, resp)
+		require.Len(t, s.queue, 1)
+		s.write(<-s.queue)
+
+		files, err := filepath.Glob(filepath.Join(s.path, "2", "ds1", "*.json"))
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+
+		b, err := os.ReadFile(files[0])
+		require.NoError(t, err)
+		rec := &testdatasource.Recording{}
+		require.NoError(t, json.Unmarshal(b, rec))
+		require.Equal(t, int64(2), rec.OrgID)
+		require.Equal(t, "A", rec.RefID)
+		require.Equal(t, "ds1", rec.DatasourceUID)
+		require.Equal(t, testdatasource.QueryHash("ds1", req.Queries[0].JSON), rec.QueryHash)
+		require.True(t, to.Equal(rec.To))
+		require.Len(t, rec.Response.Responses["A"].Frames, 1)
+	})
+
+	t.Run("records the response as it was when recorded", func(t *testing.T) {
+		s := newService(t)
+		frame := data.NewFrame("up", data.NewField("value", nil, []float64{1}))
+		frame.Meta = &data.FrameMeta{ExecutedQueryString: "up"}
+		changed := &backend.QueryDataResponse{Responses: backend.Responses{"A": {Frames: data.Frames{frame}}}}
+		s.Record(&datasources.DataSource{OrgID: 1, UID: "ds1", Type: "prometheus"}, req, changed)
+		frame.Meta.ExecutedQueryString = ""
+		s.write(<-s.queue)
+
+		files, err := filepath.Glob(filepath.Join(s.path, "1", "ds1", "*.json"))
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+		b, err := os.ReadFile(files[0])
+		require.NoError(t, err)
+		rec := &testdatasource.Recording{}
+		require.NoError(t, json.Unmarshal(b, rec))
+		require.Equal(t, "up", rec.Response.Responses["A"].Frames[0].Meta.ExecutedQueryString)
+	})
+
+	t.Run("writes the queued recordings in the background", func(t *testing.T) {
+		s := newService(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() { done <- s.Run(ctx) }()
+
+		s.Record(&datasources.DataSource{OrgID: 1, UID: "ds1", Type: "prometheus"}, req, resp)
+		require.Eventually(t, func() bool {
+			files, _ := filepath.Glob(filepath.Join(s.path, "1", "ds1", "*.json"))
+			return len(files) == 1
+		}, time.Second, 10*time.Millisecond)
+
+		cancel()
+		require.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	t.Run("drops recordings when the queue is full", func(t *testing.T) {
+		s := newService(t)
+		for i := 0; i < queueSize+1; i++ {
+			s.Record(&datasources.DataSource{OrgID: 1, UID: "ds1", Type: "prometheus"}, req, resp)
+		}
+		require.Len(t, s.queue, queueSize)
+	})
+
+	t.Run("does not record responses of the TestData data source", func(t *testing.T) {
+		s := newService(t)
+		s.Record(&datasources.DataSource{OrgID: 1, UID: "td", Type: datasources.DS_TESTDATA}, req, resp)
+		require.Empty(t, s.queue)
+	})
+
+	t.Run("does not record responses when no recordings directory is configured", func(t *testing.T) {
+		s := ProvideService(setting.NewCfg())
+		require.True(t, s.IsDisabled())
+		s.Record(&datasources.DataSource{OrgID: 1, UID: "ds1", Type: "prometheus"}, req, resp)
+		require.Empty(t, s.queue)
+	})
+}
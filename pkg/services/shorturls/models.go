@@ -7,13 +7,25 @@ import (
 )
 
 var (
-	ErrShortURLBadRequest   = errutil.BadRequest("shorturl.bad-request")
-	ErrShortURLNotFound     = errutil.NotFound("shorturl.not-found")
-	ErrShortURLAbsolutePath = errutil.ValidationFailed("shorturl.absolute-path", errutil.WithPublicMessage("Path should be relative"))
-	ErrShortURLInvalidPath  = errutil.ValidationFailed("shorturl.invalid-path", errutil.WithPublicMessage("Invalid short URL path"))
-	ErrShortURLInternal     = errutil.Internal("shorturl.internal")
+	ErrShortURLBadRequest    = errutil.BadRequest("shorturl.bad-request")
+	ErrShortURLNotFound      = errutil.NotFound("shorturl.not-found")
+	ErrShortURLAbsolutePath  = errutil.ValidationFailed("shorturl.absolute-path", errutil.WithPublicMessage("Path should be relative"))
+	ErrShortURLInvalidPath   = errutil.ValidationFailed("shorturl.invalid-path", errutil.WithPublicMessage("Invalid short URL path"))
+	ErrShortURLInternal      = errutil.Internal("shorturl.internal")
+	ErrShortURLInvalidSlug   = errutil.ValidationFailed("shorturl.invalid-slug", errutil.WithPublicMessage("Slug should only contain letters, numbers, dashes and underscores, and be at most 40 characters long"))
+	ErrShortURLSlugTaken     = errutil.ValidationFailed("shorturl.slug-taken", errutil.WithPublicMessage("Slug is already used by another short URL"))
+	ErrShortURLInvalidExpiry = errutil.ValidationFailed("shorturl.invalid-expiry", errutil.WithPublicMessage("Expiry should be in the future"))
+	ErrShortURLExpired       = errutil.NotFound("shorturl.expired", errutil.WithPublicMessage("Short URL expired"))
+	ErrShortURLForbidden     = errutil.Forbidden("shorturl.forbidden", errutil.WithPublicMessage("Only the creator of a short URL or users allowed to delete the short URLs of the organization can delete it"))
 )
 
+// ActionDelete is the permission to delete the short URLs created by other
+// users of the organization.
+const ActionDelete = "shorturls:delete"
+
+// MaxSlugLength is the length of the uid column of the short_url table
+const MaxSlugLength = 40
+
 type ShortUrl struct {
 	Id         int64
 	OrgId      int64
@@ -22,6 +34,35 @@ type ShortUrl struct {
 	CreatedBy  int64
 	CreatedAt  int64
 	LastSeenAt int64
+	// ExpiresAt is the unix time the short URL expires at, or 0 when it never expires
+	ExpiresAt  int64
+	VisitCount int64
+}
+
+// IsExpired returns whether the short URL has expired at the given time
+func (s *ShortUrl) IsExpired(now time.Time) bool {
+	return s.ExpiresAt > 0 && s.ExpiresAt <= now.Unix()
+}
+
+type CreateShortURLCommand struct {
+	Path string
+	// Slug is used as the uid of the short URL instead of a random one when set
+	Slug      string
+	ExpiresAt *time.Time
+}
+
+type SearchShortURLsQuery struct {
+	// Query matches the uid or the path of the short URLs
+	Query   string
+	Page    int
+	PerPage int
+}
+
+type SearchShortURLsResult struct {
+	TotalCount int64
+	ShortURLs  []*ShortUrl
+	Page       int
+	PerPage    int
 }
 
 type DeleteShortUrlCommand struct {
@@ -29,3 +70,19 @@ type DeleteShortUrlCommand struct {
 
 	NumDeleted int64
 }
+
+type DeleteExpiredShortURLsCommand struct {
+	Now time.Time
+
+	NumDeleted int64
+}
+
+// RewriteDashboardUIDCommand rewrites the paths of the short URLs pointing to
+// a dashboard whose UID changed
+type RewriteDashboardUIDCommand struct {
+	OrgID  int64
+	OldUID string
+	NewUID string
+
+	NumRewritten int64
+}
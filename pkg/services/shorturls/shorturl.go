@@ -8,7 +8,12 @@ import (
 
 type Service interface {
 	GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*ShortUrl, error)
-	CreateShortURL(ctx context.Context, user *user.SignedInUser, path string) (*ShortUrl, error)
+	CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *CreateShortURLCommand) (*ShortUrl, error)
+	// UpdateLastSeenAt records a visit of the short URL
 	UpdateLastSeenAt(ctx context.Context, shortURL *ShortUrl) error
+	SearchShortURLs(ctx context.Context, user *user.SignedInUser, query *SearchShortURLsQuery) (*SearchShortURLsResult, error)
+	DeleteShortURL(ctx context.Context, user *user.SignedInUser, uid string) error
 	DeleteStaleShortURLs(ctx context.Context, cmd *DeleteShortUrlCommand) error
+	DeleteExpiredShortURLs(ctx context.Context, cmd *DeleteExpiredShortURLsCommand) error
+	RewriteDashboardUID(ctx context.Context, cmd *RewriteDashboardUIDCommand) error
 }
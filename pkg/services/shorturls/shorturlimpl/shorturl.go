@@ -3,39 +3,61 @@ package shorturlimpl
 import (
 	"context"
 	"path"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/util"
 	"github.com/teris-io/shortid"
 )
 
 var getTime = time.Now
 
+const (
+	defaultSearchPerPage = 100
+	maxSearchPerPage     = 1000
+)
+
+// dashboardPathRegex matches the paths of dashboards and of their panels
+var dashboardPathRegex = regexp.MustCompile(`^(d|d-solo)/([^/?#]+)`)
+
 type ShortURLService struct {
-	SQLStore store
+	SQLStore      store
+	AccessControl accesscontrol.AccessControl
 }
 
-func ProvideService(db db.DB) *ShortURLService {
+func ProvideService(db db.DB, ac accesscontrol.AccessControl) *ShortURLService {
 	return &ShortURLService{
 		SQLStore: &sqlStore{
 			db: db,
 		},
+		AccessControl: ac,
 	}
 }
 
 func (s ShortURLService) GetShortURLByUID(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
-	return s.SQLStore.Get(ctx, user, uid)
+	shortURL, err := s.SQLStore.Get(ctx, user, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	if shortURL.IsExpired(getTime()) {
+		return nil, shorturls.ErrShortURLExpired.Errorf("short URL expired")
+	}
+
+	return shortURL, nil
 }
 
 func (s ShortURLService) UpdateLastSeenAt(ctx context.Context, shortURL *shorturls.ShortUrl) error {
 	return s.SQLStore.Update(ctx, shortURL)
 }
 
-func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, relPath string) (*shorturls.ShortUrl, error) {
-	relPath = strings.TrimSpace(relPath)
+func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedInUser, cmd *shorturls.CreateShortURLCommand) (*shorturls.ShortUrl, error) {
+	relPath := strings.TrimSpace(cmd.Path)
 
 	if path.IsAbs(relPath) {
 		return nil, shorturls.ErrShortURLAbsolutePath.Errorf("expected relative path: %s", relPath)
@@ -44,9 +66,23 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedIn
 		return nil, shorturls.ErrShortURLInvalidPath.Errorf("path cannot contain '../': %s", relPath)
 	}
 
-	uid, err := shortid.Generate()
-	if err != nil {
-		return nil, shorturls.ErrShortURLInternal.Errorf("failed to generate uid: %w", err)
+	var expiresAt int64
+	if cmd.ExpiresAt != nil {
+		if !cmd.ExpiresAt.After(getTime()) {
+			return nil, shorturls.ErrShortURLInvalidExpiry.Errorf("expiry is in the past: %s", cmd.ExpiresAt)
+		}
+		expiresAt = cmd.ExpiresAt.Unix()
+	}
+
+	uid := cmd.Slug
+	if uid == "" {
+		var err error
+		uid, err = shortid.Generate()
+		if err != nil {
+			return nil, shorturls.ErrShortURLInternal.Errorf("failed to generate uid: %w", err)
+		}
+	} else if err := s.validateSlug(ctx, user, uid); err != nil {
+		return nil, err
 	}
 
 	now := time.Now().Unix()
@@ -56,6 +92,7 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedIn
 		Path:      relPath,
 		CreatedBy: user.UserID,
 		CreatedAt: now,
+		ExpiresAt: expiresAt,
 	}
 
 	if err := s.SQLStore.Insert(ctx, &shortURL); err != nil {
@@ -65,6 +102,98 @@ func (s ShortURLService) CreateShortURL(ctx context.Context, user *user.SignedIn
 	return &shortURL, nil
 }
 
+// validateSlug checks that the slug is a valid uid not used by another short
+// URL of the organization. The slug of an expired short URL can be reused.
+func (s ShortURLService) validateSlug(ctx context.Context, user *user.SignedInUser, slug string) error {
+	if !util.IsValidShortUID(slug) || len(slug) > shorturls.MaxSlugLength {
+		return shorturls.ErrShortURLInvalidSlug.Errorf("invalid slug: %s", slug)
+	}
+
+	existing, err := s.SQLStore.Get(ctx, user, slug)
+	if err != nil {
+		if shorturls.ErrShortURLNotFound.Is(err) {
+			return nil
+		}
+		return shorturls.ErrShortURLInternal.Errorf("failed to get short URL: %w", err)
+	}
+
+	if !existing.IsExpired(getTime()) {
+		return shorturls.ErrShortURLSlugTaken.Errorf("slug already used: %s", slug)
+	}
+
+	if err := s.SQLStore.DeleteByUID(ctx, user.OrgID, slug); err != nil {
+		return shorturls.ErrShortURLInternal.Errorf("failed to delete expired short URL: %w", err)
+	}
+	return nil
+}
+
+// SearchShortURLs returns the short URLs created by the user, the most recent
+// first.
+func (s ShortURLService) SearchShortURLs(ctx context.Context, user *user.SignedInUser, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error) {
+	if query.Page <= 0 {
+		query.Page = 1
+	}
+	if query.PerPage <= 0 {
+		query.PerPage = defaultSearchPerPage
+	}
+	if query.PerPage > maxSearchPerPage {
+		query.PerPage = maxSearchPerPage
+	}
+
+	result, err := s.SQLStore.Search(ctx, user.OrgID, user.UserID, query)
+	if err != nil {
+		return nil, shorturls.ErrShortURLInternal.Errorf("failed to search short URLs: %w", err)
+	}
+	return result, nil
+}
+
+// DeleteShortURL deletes a short URL of the organization. Only its creator
+// or users with the shorturls:delete permission can delete it.
+func (s ShortURLService) DeleteShortURL(ctx context.Context, user *user.SignedInUser, uid string) error {
+	shortURL, err := s.SQLStore.Get(ctx, user, uid)
+	if err != nil {
+		return err
+	}
+
+	if shortURL.CreatedBy != user.UserID {
+		canDelete, err := s.AccessControl.Evaluate(ctx, user, accesscontrol.EvalPermission(shorturls.ActionDelete))
+		if err != nil {
+			return shorturls.ErrShortURLInternal.Errorf("failed to evaluate the permission to delete short URL: %w", err)
+		}
+		if !canDelete {
+			return shorturls.ErrShortURLForbidden.Errorf("user %d cannot delete short URL %s", user.UserID, uid)
+		}
+	}
+
+	if err := s.SQLStore.DeleteByUID(ctx, user.OrgID, uid); err != nil {
+		return shorturls.ErrShortURLInternal.Errorf("failed to delete short URL: %w", err)
+	}
+	return nil
+}
+
 func (s ShortURLService) DeleteStaleShortURLs(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return s.SQLStore.Delete(ctx, cmd)
 }
+
+func (s ShortURLService) DeleteExpiredShortURLs(ctx context.Context, cmd *shorturls.DeleteExpiredShortURLsCommand) error {
+	return s.SQLStore.DeleteExpired(ctx, cmd)
+}
+
+// RewriteDashboardUID points the short URLs of a dashboard, and of its
+// panels, to the new UID of the dashboard.
+func (s ShortURLService) RewriteDashboardUID(ctx context.Context, cmd *shorturls.RewriteDashboardUIDCommand) error {
+	if !util.IsValidShortUID(cmd.OldUID) || !util.IsValidShortUID(cmd.NewUID) || cmd.OldUID == "" || cmd.NewUID == "" {
+		return shorturls.ErrShortURLBadRequest.Errorf("invalid dashboard UIDs: %q and %q", cmd.OldUID, cmd.NewUID)
+	}
+	return s.SQLStore.RewriteDashboardUID(ctx, cmd)
+}
+
+// rewriteDashboardPath replaces the dashboard UID of the path when it is the
+// path of the dashboard or of one of its panels.
+func rewriteDashboardPath(relPath string, oldUID string, newUID string) (string, bool) {
+	match := dashboardPathRegex.FindStringSubmatchIndex(relPath)
+	if match == nil || relPath[match[4]:match[5]] != oldUID {
+		return relPath, false
+	}
+	return relPath[:match[4]] + newUID + relPath[match[5]:], true
+}
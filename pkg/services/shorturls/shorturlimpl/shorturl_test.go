@@ -8,8 +8,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/grafana/grafana/pkg/infra/db"
+	"github.com/grafana/grafana/pkg/services/accesscontrol/acimpl"
+	"github.com/grafana/grafana/pkg/services/org"
 	"github.com/grafana/grafana/pkg/services/shorturls"
 	"github.com/grafana/grafana/pkg/services/user"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 func TestShortURLService(t *testing.T) {
@@ -21,7 +24,7 @@ func TestShortURLService(t *testing.T) {
 
 		service := ShortURLService{SQLStore: &sqlStore{db: store}}
 
-		newShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+		newShortURL, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: refPath})
 		require.NoError(t, err)
 		require.NotNil(t, newShortURL)
 		require.NotEmpty(t, newShortURL.Uid)
@@ -48,10 +51,11 @@ func TestShortURLService(t *testing.T) {
 			updatedShortURL, err := service.GetShortURLByUID(context.Background(), user, existingShortURL.Uid)
 			require.NoError(t, err)
 			require.Equal(t, expectedTime.Unix(), updatedShortURL.LastSeenAt)
+			require.Equal(t, int64(1), updatedShortURL.VisitCount)
 		})
 
 		t.Run("and stale short urls can be deleted", func(t *testing.T) {
-			staleShortURL, err := service.CreateShortURL(context.Background(), user, refPath)
+			staleShortURL, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: refPath})
 			require.NoError(t, err)
 			require.NotNil(t, staleShortURL)
 			require.NotEmpty(t, staleShortURL.Uid)
@@ -85,3 +89,173 @@ func TestShortURLService(t *testing.T) {
 		require.Nil(t, shortURL)
 	})
 }
+
+func TestShortURLServiceSlugsAndExpiry(t *testing.T) {
+	signedInUser := &user.SignedInUser{UserID: 1, OrgID: 1}
+	service := ShortURLService{SQLStore: &sqlStore{db: db.InitTestDB(t)}}
+
+	t.Run("User can create short URLs with a custom slug", func(t *testing.T) {
+		shortURL, err := service.CreateShortURL(context.Background(), signedInUser, &shorturls.CreateShortURLCommand{Path: "d/abc", Slug: "team-overview"})
+		require.NoError(t, err)
+		require.Equal(t, "team-overview", shortURL.Uid)
+
+		_, err = service.CreateShortURL(context.Background(), signedInUser, &shorturls.CreateShortURLCommand{Path: "d/def", Slug: "team-overview"})
+		require.True(t, shorturls.ErrShortURLSlugTaken.Is(err))
+
+		otherOrgUser := &user.SignedInUser{UserID: 1, OrgID: 2}
+		_, err = service.CreateShortURL(context.Background(), otherOrgUser, &shorturls.CreateShortURLCommand{Path: "d/def", Slug: "team-overview"})
+		require.NoError(t, err)
+	})
+
+	t.Run("User cannot create short URLs with an invalid slug", func(t *testing.T) {
+		for _, slug := range []string{"with space", "with/slash", "a-slug-longer-than-forty-characters-is-invalid"} {
+			_, err := service.CreateShortURL(context.Background(), signedInUser, &shorturls.CreateShortURLCommand{Path: "d/abc", Slug: slug})
+			require.True(t, shorturls.ErrShortURLInvalidSlug.Is(err), slug)
+		}
+	})
+
+	t.Run("User cannot create short URLs expiring in the past", func(t *testing.T) {
+		expiresAt := time.Now().Add(-time.Hour)
+		_, err := service.CreateShortURL(context.Background(), signedInUser, &shorturls.CreateShortURLCommand{Path: "d/abc", ExpiresAt: &expiresAt})
+		require.True(t, shorturls.ErrShortURLInvalidExpiry.Is(err))
+	})
+
+	t.Run("Expired short URLs cannot be looked up and their slug can be reused", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+		shortURL, err := service.CreateShortURL(context.Background(), signedInUser, &shorturls.CreateShortURLCommand{Path: "d/abc", Slug: "release", ExpiresAt: &expiresAt})
+		require.NoError(t, err)
+		require.Equal(t, expiresAt.Unix(), shortURL.ExpiresAt)
+
+		origGetTime := getTime
+		t.Cleanup(func() {
+			getTime = origGetTime
+		})
+		getTime = func() time.Time {
+			return expiresAt.Add(time.Minute)
+		}
+
+		_, err = service.GetShortURLByUID(context.Background(), signedInUser, "release")
+		require.True(t, shorturls.ErrShortURLExpired.Is(err))
+
+		// the stale short URL cleanup keeps short URLs with an expiry
+		cmd := shorturls.DeleteShortUrlCommand{OlderThan: expiresAt}
+		require.NoError(t, service.DeleteStaleShortURLs(context.Background(), &cmd))
+		_, err = service.SQLStore.Get(context.Background(), signedInUser, "release")
+		require.NoError(t, err)
+
+		newExpiresAt := expiresAt.Add(time.Hour)
+		reused, err := service.CreateShortURL(context.Background(), signedInUser, &shorturls.CreateShortURLCommand{Path: "d/def", Slug: "release", ExpiresAt: &newExpiresAt})
+		require.NoError(t, err)
+		require.Equal(t, "d/def", reused.Path)
+	})
+
+	t.Run("Expired short URLs can be deleted", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+		_, err := service.CreateShortURL(context.Background(), signedInUser, &shorturls.CreateShortURLCommand{Path: "d/abc", Slug: "expiring", ExpiresAt: &expiresAt})
+		require.NoError(t, err)
+
+		cmd := shorturls.DeleteExpiredShortURLsCommand{Now: expiresAt}
+		require.NoError(t, service.DeleteExpiredShortURLs(context.Background(), &cmd))
+		require.Equal(t, int64(1), cmd.NumDeleted)
+
+		_, err = service.SQLStore.Get(context.Background(), signedInUser, "expiring")
+		require.True(t, shorturls.ErrShortURLNotFound.Is(err))
+	})
+}
+
+func TestShortURLServiceSearchAndDelete(t *testing.T) {
+	creator := &user.SignedInUser{UserID: 1, OrgID: 1, OrgRole: org.RoleViewer}
+	viewer := &user.SignedInUser{UserID: 2, OrgID: 1, OrgRole: org.RoleViewer}
+	deleter := &user.SignedInUser{UserID: 3, OrgID: 1, OrgRole: org.RoleViewer, Permissions: map[int64]map[string][]string{1: {shorturls.ActionDelete: {}}}}
+	service := ShortURLService{SQLStore: &sqlStore{db: db.InitTestDB(t)}, AccessControl: acimpl.ProvideAccessControl(setting.NewCfg())}
+
+	for _, path := range []string{"d/abc/cpu", "d/abc/memory", "d/def/network"} {
+		_, err := service.CreateShortURL(context.Background(), creator, &shorturls.CreateShortURLCommand{Path: path})
+		require.NoError(t, err)
+	}
+	_, err := service.CreateShortURL(context.Background(), viewer, &shorturls.CreateShortURLCommand{Path: "d/abc/disk"})
+	require.NoError(t, err)
+
+	t.Run("User can search their short URLs", func(t *testing.T) {
+		result, err := service.SearchShortURLs(context.Background(), creator, &shorturls.SearchShortURLsQuery{})
+		require.NoError(t, err)
+		require.Equal(t, int64(3), result.TotalCount)
+		require.Len(t, result.ShortURLs, 3)
+		require.Equal(t, 1, result.Page)
+		require.Equal(t, defaultSearchPerPage, result.PerPage)
+
+		result, err = service.SearchShortURLs(context.Background(), creator, &shorturls.SearchShortURLsQuery{Query: "d/abc", PerPage: 1, Page: 2})
+		require.NoError(t, err)
+		require.Equal(t, int64(2), result.TotalCount)
+		require.Len(t, result.ShortURLs, 1)
+	})
+
+	t.Run("Wildcards of the search query are matched literally", func(t *testing.T) {
+		searcher := &user.SignedInUser{UserID: 4, OrgID: 1, OrgRole: org.RoleViewer}
+		for _, path := range []string{"d/a_c/50%-cpu", "d/abc/50-cpu"} {
+			_, err := service.CreateShortURL(context.Background(), searcher, &shorturls.CreateShortURLCommand{Path: path})
+			require.NoError(t, err)
+		}
+
+		for query, expected := range map[string]int64{"a_c": 1, "50%": 1, "%": 1, "!": 0} {
+			result, err := service.SearchShortURLs(context.Background(), searcher, &shorturls.SearchShortURLsQuery{Query: query})
+			require.NoError(t, err)
+			require.Equal(t, expected, result.TotalCount, query)
+		}
+	})
+
+	t.Run("Only the creator or a user allowed to delete short URLs can delete a short URL", func(t *testing.T) {
+		result, err := service.SearchShortURLs(context.Background(), creator, &shorturls.SearchShortURLsQuery{})
+		require.NoError(t, err)
+
+		err = service.DeleteShortURL(context.Background(), viewer, result.ShortURLs[0].Uid)
+		require.True(t, shorturls.ErrShortURLForbidden.Is(err))
+
+		require.NoError(t, service.DeleteShortURL(context.Background(), creator, result.ShortURLs[0].Uid))
+		require.NoError(t, service.DeleteShortURL(context.Background(), deleter, result.ShortURLs[1].Uid))
+
+		result, err = service.SearchShortURLs(context.Background(), creator, &shorturls.SearchShortURLsQuery{})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), result.TotalCount)
+
+		err = service.DeleteShortURL(context.Background(), creator, "nonexistent")
+		require.True(t, shorturls.ErrShortURLNotFound.Is(err))
+	})
+}
+
+func TestShortURLServiceRewriteDashboardUID(t *testing.T) {
+	user := &user.SignedInUser{UserID: 1, OrgID: 1}
+	service := ShortURLService{SQLStore: &sqlStore{db: db.InitTestDB(t)}}
+
+	paths := map[string]string{
+		"dashboard": "d/old-uid/my-dashboard?orgId=1&from=now-6h",
+		"panel":     "d-solo/old-uid/my-dashboard?orgId=1&panelId=2",
+		"prefix":    "d/old-uid-2/other-dashboard",
+		"explore":   "explore?left=d/old-uid",
+	}
+	for slug, path := range paths {
+		_, err := service.CreateShortURL(context.Background(), user, &shorturls.CreateShortURLCommand{Path: path, Slug: slug})
+		require.NoError(t, err)
+	}
+
+	cmd := shorturls.RewriteDashboardUIDCommand{OrgID: 1, OldUID: "old-uid", NewUID: "new-uid"}
+	require.NoError(t, service.RewriteDashboardUID(context.Background(), &cmd))
+	require.Equal(t, int64(2), cmd.NumRewritten)
+
+	expected := map[string]string{
+		"dashboard": "d/new-uid/my-dashboard?orgId=1&from=now-6h",
+		"panel":     "d-solo/new-uid/my-dashboard?orgId=1&panelId=2",
+		"prefix":    "d/old-uid-2/other-dashboard",
+		"explore":   "explore?left=d/old-uid",
+	}
+	for slug, path := range expected {
+		shortURL, err := service.GetShortURLByUID(context.Background(), user, slug)
+		require.NoError(t, err)
+		require.Equal(t, path, shortURL.Path, slug)
+	}
+
+	t.Run("Invalid UIDs are rejected", func(t *testing.T) {
+		err := service.RewriteDashboardUID(context.Background(), &shorturls.RewriteDashboardUIDCommand{OrgID: 1, OldUID: "old-uid"})
+		require.True(t, shorturls.ErrShortURLBadRequest.Is(err))
+	})
+}
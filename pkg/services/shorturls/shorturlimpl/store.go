@@ -2,6 +2,7 @@ package shorturlimpl
 
 import (
 	"context"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/infra/db"
 	"github.com/grafana/grafana/pkg/services/shorturls"
@@ -13,12 +14,27 @@ type store interface {
 	Update(ctx context.Context, shortURL *shorturls.ShortUrl) error
 	Insert(ctx context.Context, shortURL *shorturls.ShortUrl) error
 	Delete(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error
+	DeleteExpired(ctx context.Context, cmd *shorturls.DeleteExpiredShortURLsCommand) error
+	DeleteByUID(ctx context.Context, orgID int64, uid string) error
+	Search(ctx context.Context, orgID int64, userID int64, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error)
+	RewriteDashboardUID(ctx context.Context, cmd *shorturls.RewriteDashboardUIDCommand) error
 }
 
 type sqlStore struct {
 	db db.DB
 }
 
+// likeEscaper escapes the wildcards of LIKE patterns with likeEscapeChar. A
+// backslash isn't used as it must itself be escaped in MySQL string literals.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+const likeEscapeChar = "!"
+
+// like returns the LIKE condition on column, with the pattern escaped by likeEscaper
+func (s sqlStore) like(column string) string {
+	return column + " " + s.db.GetDialect().LikeStr() + " ? ESCAPE '" + likeEscapeChar + "'"
+}
+
 func (s sqlStore) Get(ctx context.Context, user *user.SignedInUser, uid string) (*shorturls.ShortUrl, error) {
 	var shortURL shorturls.ShortUrl
 	err := s.db.WithDbSession(ctx, func(dbSession *db.Session) error {
@@ -39,13 +55,15 @@ func (s sqlStore) Get(ctx context.Context, user *user.SignedInUser, uid string)
 	return &shortURL, nil
 }
 
+// Update sets the last seen at time of the short URL and counts the visit
 func (s sqlStore) Update(ctx context.Context, shortURL *shorturls.ShortUrl) error {
 	shortURL.LastSeenAt = getTime().Unix()
 	return s.db.WithTransactionalDbSession(ctx, func(dbSession *db.Session) error {
-		_, err := dbSession.ID(shortURL.Id).Update(shortURL)
+		_, err := dbSession.Exec("UPDATE short_url SET last_seen_at = ?, visit_count = visit_count + 1 WHERE id = ?", shortURL.LastSeenAt, shortURL.Id)
 		if err != nil {
 			return err
 		}
+		shortURL.VisitCount++
 		return nil
 	})
 }
@@ -59,7 +77,8 @@ func (s sqlStore) Insert(ctx context.Context, shortURL *shorturls.ShortUrl) erro
 
 func (s sqlStore) Delete(ctx context.Context, cmd *shorturls.DeleteShortUrlCommand) error {
 	return s.db.WithTransactionalDbSession(ctx, func(session *db.Session) error {
-		var rawSql = "DELETE FROM short_url WHERE created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0)"
+		// short URLs with an explicit expiry are kept until they expire
+		var rawSql = "DELETE FROM short_url WHERE created_at <= ? AND (last_seen_at IS NULL OR last_seen_at = 0) AND (expires_at IS NULL OR expires_at = 0)"
 
 		if result, err := session.Exec(rawSql, cmd.OlderThan.Unix()); err != nil {
 			return err
@@ -69,3 +88,80 @@ func (s sqlStore) Delete(ctx context.Context, cmd *shorturls.DeleteShortUrlComma
 		return nil
 	})
 }
+
+func (s sqlStore) DeleteExpired(ctx context.Context, cmd *shorturls.DeleteExpiredShortURLsCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		var rawSql = "DELETE FROM short_url WHERE expires_at > 0 AND expires_at <= ?"
+
+		if result, err := session.Exec(rawSql, cmd.Now.Unix()); err != nil {
+			return err
+		} else if cmd.NumDeleted, err = result.RowsAffected(); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (s sqlStore) DeleteByUID(ctx context.Context, orgID int64, uid string) error {
+	return s.db.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		_, err := session.Exec("DELETE FROM short_url WHERE org_id = ? AND uid = ?", orgID, uid)
+		return err
+	})
+}
+
+func (s sqlStore) Search(ctx context.Context, orgID int64, userID int64, query *shorturls.SearchShortURLsQuery) (*shorturls.SearchShortURLsResult, error) {
+	result := shorturls.SearchShortURLsResult{
+		ShortURLs: make([]*shorturls.ShortUrl, 0),
+		Page:      query.Page,
+		PerPage:   query.PerPage,
+	}
+
+	err := s.db.WithDbSession(ctx, func(dbSession *db.Session) error {
+		whereConditions := []string{"org_id = ?", "created_by = ?"}
+		whereParams := []any{orgID, userID}
+		if query.Query != "" {
+			pattern := "%" + likeEscaper.Replace(query.Query) + "%"
+			whereConditions = append(whereConditions, "("+s.like("uid")+" OR "+s.like("path")+")")
+			whereParams = append(whereParams, pattern, pattern)
+		}
+		where := strings.Join(whereConditions, " AND ")
+
+		count, err := dbSession.Where(where, whereParams...).Count(&shorturls.ShortUrl{})
+		if err != nil {
+			return err
+		}
+		result.TotalCount = count
+
+		offset := query.PerPage * (query.Page - 1)
+		return dbSession.Where(where, whereParams...).Desc("created_at").Limit(query.PerPage, offset).Find(&result.ShortURLs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (s sqlStore) RewriteDashboardUID(ctx context.Context, cmd *shorturls.RewriteDashboardUIDCommand) error {
+	return s.db.WithTransactionalDbSession(ctx, func(session *db.Session) error {
+		var shortURLs []*shorturls.ShortUrl
+		oldUID := likeEscaper.Replace(cmd.OldUID)
+		err := session.Where("org_id = ? AND ("+s.like("path")+" OR "+s.like("path")+")",
+			cmd.OrgID, "d/"+oldUID+"%", "d-solo/"+oldUID+"%").Find(&shortURLs)
+		if err != nil {
+			return err
+		}
+
+		for _, shortURL := range shortURLs {
+			path, ok := rewriteDashboardPath(shortURL.Path, cmd.OldUID, cmd.NewUID)
+			if !ok {
+				continue
+			}
+			if _, err := session.Exec("UPDATE short_url SET path = ? WHERE id = ?", path, shortURL.Id); err != nil {
+				return err
+			}
+			cmd.NumRewritten++
+		}
+		return nil
+	})
+}
@@ -96,4 +96,30 @@ func addPublicDashboardMigration(mg *Migrator) {
 	mg.AddMigration("backfill empty share column fields with default of public", NewRawSQLMigration(
 		"UPDATE dashboard_public SET share='public' WHERE share=''",
 	))
+
+	dashboardPublicAccessTokenV1 := Table{
+		Name: "dashboard_public_access_token",
+		Columns: []*Column{
+			{Name: "uid", Type: DB_NVarchar, Length: 40, IsPrimaryKey: true},
+			{Name: "public_dashboard_uid", Type: DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "access_token", Type: DB_NVarchar, Length: 32, Nullable: false},
+			{Name: "expires_at", Type: DB_DateTime, Nullable: true},
+			{Name: "ip_allowlist", Type: DB_Text, Nullable: true},
+			{Name: "variables", Type: DB_Text, Nullable: true},
+			{Name: "view_count", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "query_count", Type: DB_BigInt, Nullable: false, Default: "0"},
+			{Name: "last_used_at", Type: DB_DateTime, Nullable: true},
+			{Name: "created_by", Type: DB_Int, Nullable: false},
+			{Name: "created_at", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"access_token"}, Type: UniqueIndex},
+			{Cols: []string{"public_dashboard_uid"}},
+		},
+	}
+
+	mg.AddMigration("create dashboard public access token table v1", NewAddTableMigration(dashboardPublicAccessTokenV1))
+	addTableIndicesMigrations(mg, "v1", dashboardPublicAccessTokenV1)
 }
@@ -71,4 +71,8 @@ func addDashboardSnapshotMigrations(mg *Migrator) {
 
 	mg.AddMigration("Change dashboard_encrypted column to MEDIUMBLOB", NewRawSQLMigration("").
 		Mysql("ALTER TABLE dashboard_snapshot MODIFY dashboard_encrypted MEDIUMBLOB;"))
+
+	mg.AddMigration("Add blob_key column to dashboard_snapshot table", NewAddColumnMigration(snapshotV5, &Column{
+		Name: "blob_key", Type: DB_NVarchar, Length: 190, Nullable: true,
+	}))
 }
@@ -61,4 +61,34 @@ func addLibraryElementsMigrations(mg *migrator.Migrator) {
 
 	mg.AddMigration("alter library_element model to mediumtext", migrator.NewRawSQLMigration("").
 		Mysql("ALTER TABLE library_element MODIFY model MEDIUMTEXT NOT NULL;"))
+
+	mg.AddMigration("add element_version column to "+model.LibraryElementConnectionTableName, migrator.NewAddColumnMigration(libraryElementConnectionV1, &migrator.Column{
+		Name: "element_version", Type: migrator.DB_BigInt, Nullable: false, Default: "0",
+	}))
+
+	libraryElementVersionV1 := migrator.Table{
+		Name: model.LibraryElementVersionTableName,
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "element_id", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "version", Type: migrator.DB_BigInt, Nullable: false},
+			{Name: "name", Type: migrator.DB_NVarchar, Length: 150, Nullable: false},
+			{Name: "description", Type: migrator.DB_NVarchar, Length: 2048, Nullable: false},
+			{Name: "model", Type: migrator.DB_MediumText, Nullable: false},
+			{Name: "created", Type: migrator.DB_DateTime, Nullable: false},
+			{Name: "created_by", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"element_id", "version"}, Type: migrator.UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create "+model.LibraryElementVersionTableName+" table v1", migrator.NewAddTableMigration(libraryElementVersionV1))
+	mg.AddMigration("add index "+model.LibraryElementVersionTableName+" element_id-version", migrator.NewAddIndexMigration(libraryElementVersionV1, libraryElementVersionV1.Indices[0]))
+
+	// the history of the existing library elements starts at their current version
+	mg.AddMigration("copy library_element to "+model.LibraryElementVersionTableName, migrator.NewRawSQLMigration(
+		"INSERT INTO "+model.LibraryElementVersionTableName+" (org_id, element_id, version, name, description, model, created, created_by)"+
+			" SELECT org_id, id, version, name, description, model, updated, updated_by FROM library_element"))
 }
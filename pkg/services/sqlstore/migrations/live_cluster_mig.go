@@ -0,0 +1,23 @@
+package migrations
+
+import "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addLiveClusterMigrations(mg *migrator.Migrator) {
+	liveClusterNodeV1 := migrator.Table{
+		Name: "live_cluster_node",
+		Columns: []*migrator.Column{
+			{Name: "id", Type: migrator.DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "node_id", Type: migrator.DB_NVarchar, Length: 40, Nullable: false},
+			{Name: "address", Type: migrator.DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "updated", Type: migrator.DB_BigInt, Nullable: false},
+		},
+		Indices: []*migrator.Index{
+			{Cols: []string{"node_id"}, Type: migrator.UniqueIndex},
+			{Cols: []string{"updated"}},
+		},
+	}
+
+	mg.AddMigration("create live_cluster_node table", migrator.NewAddTableMigration(liveClusterNodeV1))
+	mg.AddMigration("add unique index live_cluster_node.node_id", migrator.NewAddIndexMigration(liveClusterNodeV1, liveClusterNodeV1.Indices[0]))
+	mg.AddMigration("add index live_cluster_node.updated", migrator.NewAddIndexMigration(liveClusterNodeV1, liveClusterNodeV1.Indices[1]))
+}
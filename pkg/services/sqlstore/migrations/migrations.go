@@ -106,6 +106,8 @@ func (*OSSMigrations) AddMigration(mg *Migrator) {
 	ualert.CreatedFoldersMigration(mg)
 
 	dashboardFolderMigrations.AddDashboardFolderMigrations(mg)
+
+	addLiveClusterMigrations(mg)
 }
 
 func addStarMigrations(mg *Migrator) {
@@ -41,6 +41,20 @@ func addPlaylistMigrations(mg *Migrator) {
 	mg.AddMigration("Add playlist column updated_at", NewAddColumnMigration(playlistV2(), &Column{
 		Name: "updated_at", Type: DB_BigInt, Nullable: false, Default: "0",
 	}))
+
+	// Per item display durations, variables and tag ordering, and schedules
+	mg.AddMigration("Add playlist column schedule", NewAddColumnMigration(playlistV2(), &Column{
+		Name: "schedule", Type: DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("Add playlist_item column interval", NewAddColumnMigration(playlistItemV2, &Column{
+		Name: "interval", Type: DB_NVarchar, Length: 255, Nullable: true,
+	}))
+	mg.AddMigration("Add playlist_item column variables", NewAddColumnMigration(playlistItemV2, &Column{
+		Name: "variables", Type: DB_Text, Nullable: true,
+	}))
+	mg.AddMigration("Add playlist_item column sort", NewAddColumnMigration(playlistItemV2, &Column{
+		Name: "sort", Type: DB_NVarchar, Length: 255, Nullable: true,
+	}))
 }
 
 func addPlaylistUIDMigration(mg *Migrator) {
@@ -396,6 +396,7 @@ type Cfg struct {
 	SqlDatasourceMaxOpenConnsDefault    int
 	SqlDatasourceMaxIdleConnsDefault    int
 	SqlDatasourceMaxConnLifetimeDefault int
+	SqlDatasourceAllowedFilePaths       []string
 
 	// Snapshots
 	SnapshotEnabled       bool
@@ -403,9 +404,13 @@ type Cfg struct {
 	ExternalSnapshotName  string
 	ExternalEnabled       bool
 	SnapShotRemoveExpired bool
+	SnapshotStorageURL    string
 
 	SnapshotPublicMode bool
 
+	// Public dashboards
+	PublicDashboardsTrustedProxies []string
+
 	ErrTemplateName string
 
 	Env string
@@ -486,6 +491,9 @@ type Cfg struct {
 	// LiveHAEngineAddress is a connection address for Live HA engine.
 	LiveHAEngineAddress  string
 	LiveHAEnginePassword string
+	// LiveHAClusterAdvertiseAddress is an address other Grafana instances use
+	// to reach this instance when the cluster HA engine is used.
+	LiveHAClusterAdvertiseAddress string
 	// LiveAllowedOrigins is a set of origins accepted by Live. If not provided
 	// then Live uses AppURL as the only allowed origin.
 	LiveAllowedOrigins []string
@@ -1067,6 +1075,8 @@ func (cfg *Cfg) Load(args CommandLineArgs) error {
 		return err
 	}
 
+	cfg.readPublicDashboardsSettings(iniFile)
+
 	if err := readGRPCServerSettings(cfg, iniFile); err != nil {
 		return err
 	}
@@ -1864,10 +1874,16 @@ func readSnapshotsSettings(cfg *Cfg, iniFile *ini.File) error {
 	cfg.ExternalEnabled = snapshots.Key("external_enabled").MustBool(true)
 	cfg.SnapShotRemoveExpired = snapshots.Key("snapshot_remove_expired").MustBool(true)
 	cfg.SnapshotPublicMode = snapshots.Key("public_mode").MustBool(false)
+	cfg.SnapshotStorageURL = valueAsString(snapshots, "storage_url", "")
 
 	return nil
 }
 
+func (cfg *Cfg) readPublicDashboardsSettings(iniFile *ini.File) {
+	publicDashboards := iniFile.Section("public_dashboards")
+	cfg.PublicDashboardsTrustedProxies = util.SplitString(valueAsString(publicDashboards, "trusted_proxies", ""))
+}
+
 func (cfg *Cfg) readServerSettings(iniFile *ini.File) error {
 	server := iniFile.Section("server")
 	var err error
@@ -1965,6 +1981,7 @@ func (cfg *Cfg) readSqlDataSourceSettings() {
 	cfg.SqlDatasourceMaxOpenConnsDefault = sqlDatasources.Key("max_open_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxIdleConnsDefault = sqlDatasources.Key("max_idle_conns_default").MustInt(100)
 	cfg.SqlDatasourceMaxConnLifetimeDefault = sqlDatasources.Key("max_conn_lifetime_default").MustInt(14400)
+	cfg.SqlDatasourceAllowedFilePaths = util.SplitString(sqlDatasources.Key("allowed_file_paths").String())
 }
 
 func GetAllowedOriginGlobs(originPatterns []string) ([]glob.Glob, error) {
@@ -1988,12 +2005,13 @@ func (cfg *Cfg) readLiveSettings(iniFile *ini.File) error {
 	}
 	cfg.LiveHAEngine = section.Key("ha_engine").MustString("")
 	switch cfg.LiveHAEngine {
-	case "", "redis":
+	case "", "redis", "cluster":
 	default:
 		return fmt.Errorf("unsupported live HA engine type: %s", cfg.LiveHAEngine)
 	}
 	cfg.LiveHAEngineAddress = section.Key("ha_engine_address").MustString("127.0.0.1:6379")
 	cfg.LiveHAEnginePassword = section.Key("ha_engine_password").MustString("")
+	cfg.LiveHAClusterAdvertiseAddress = section.Key("ha_cluster_advertise_address").MustString("")
 
 	var originPatterns []string
 	allowedOrigins := section.Key("allowed_origins").MustString("")
@@ -51,8 +51,11 @@ type ConfiguredFields struct {
 // Client represents a client which can interact with elasticsearch api
 type Client interface {
 	GetConfiguredFields() ConfiguredFields
+	GetIndices() []string
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
+	ExecuteSQL(r *SQLRequest) (*SQLResponse, error)
+	CloseSQLCursor(cursor string) error
 }
 
 // NewClient creates a new elasticsearch client
@@ -96,6 +99,20 @@ func (c *baseClientImpl) GetConfiguredFields() ConfiguredFields {
 	return c.configuredFields
 }
 
+// GetIndices returns the indices of the index pattern for the time range of
+// the client, comma separated lists of indices are split.
+func (c *baseClientImpl) GetIndices() []string {
+	indices := make([]string, 0, len(c.indices))
+	for _, index := range c.indices {
+		for _, name := range strings.Split(index, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				indices = append(indices, name)
+			}
+		}
+	}
+	return indices
+}
+
 type multiRequest struct {
 	header   map[string]any
 	body     any
@@ -107,7 +124,7 @@ func (c *baseClientImpl) executeBatchRequest(uriPath, uriQuery string, requests
 	if err != nil {
 		return nil, err
 	}
-	return c.executeRequest(http.MethodPost, uriPath, uriQuery, bytes)
+	return c.executeRequest(http.MethodPost, uriPath, uriQuery, "application/x-ndjson", bytes)
 }
 
 func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte, error) {
@@ -139,7 +156,7 @@ func (c *baseClientImpl) encodeBatchRequests(requests []*multiRequest) ([]byte,
 	return payload.Bytes(), nil
 }
 
-func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body []byte) (*http.Response, error) {
+func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery, contentType string, body []byte) (*http.Response, error) {
 	c.logger.Debug("Sending request to Elasticsearch", "url", c.ds.URL)
 	u, err := url.Parse(c.ds.URL)
 	if err != nil {
@@ -158,7 +175,7 @@ func (c *baseClientImpl) executeRequest(method, uriPath, uriQuery string, body [
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/x-ndjson")
+	req.Header.Set("Content-Type", contentType)
 
 	//nolint:bodyclose
 	resp, err := c.ds.HTTPClient.Do(req)
@@ -270,3 +287,71 @@ func (c *baseClientImpl) getMultiSearchQueryParameters() string {
 func (c *baseClientImpl) MultiSearch() *MultiSearchRequestBuilder {
 	return NewMultiSearchRequestBuilder()
 }
+
+// ExecuteSQL runs an Elasticsearch SQL or OpenSearch PPL query, or requests
+// the next page of an Elasticsearch SQL query when the cursor is set.
+func (c *baseClientImpl) ExecuteSQL(r *SQLRequest) (*SQLResponse, error) {
+	var err error
+	uriPath, uriQuery := "_sql", "format=json"
+	if r.Language == SQLLanguagePPL {
+		uriPath, uriQuery = "_plugins/_ppl", ""
+	}
+	_, span := c.tracer.Start(c.ctx, "datasource.elasticsearch.queryData.executeSQL", trace.WithAttributes(
+		attribute.String("language", string(r.Language)),
+		attribute.String("url", c.ds.URL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	body, err := json.Marshal(r)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	res, err := c.executeRequest(http.MethodPost, uriPath, uriQuery, "application/json", body)
+	if err != nil {
+		c.logger.Error("Error received from Elasticsearch", "error", err, "duration", time.Since(start), "stage", StageDatabaseRequest)
+		return nil, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			c.logger.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	c.logger.Info("Response received from Elasticsearch", "statusCode", res.StatusCode, "contentLength", res.ContentLength, "duration", time.Since(start), "stage", StageDatabaseRequest)
+
+	var sr SQLResponse
+	if err = json.NewDecoder(res.Body).Decode(&sr); err != nil {
+		c.logger.Error("Failed to decode response from Elasticsearch", "error", err, "statusCode", res.StatusCode)
+		return nil, fmt.Errorf("failed to decode %s response with status code %d: %w", r.Language, res.StatusCode, err)
+	}
+	sr.Status = res.StatusCode
+	return &sr, nil
+}
+
+// CloseSQLCursor releases the resources of an Elasticsearch SQL query whose
+// pages are not all requested.
+func (c *baseClientImpl) CloseSQLCursor(cursor string) error {
+	body, err := json.Marshal(map[string]string{"cursor": cursor})
+	if err != nil {
+		return err
+	}
+	res, err := c.executeRequest(http.MethodPost, "_sql/close", "", "application/json", body)
+	if err != nil {
+		return err
+	}
+	if err := res.Body.Close(); err != nil {
+		c.logger.Warn("Failed to close response body", "error", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to close SQL cursor, status code %d", res.StatusCode)
+	}
+	return nil
+}
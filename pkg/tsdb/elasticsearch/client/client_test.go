@@ -113,6 +113,83 @@ func TestClient_ExecuteMultisearch(t *testing.T) {
 	})
 }
 
+func TestClient_ExecuteSQL(t *testing.T) {
+	tt := []struct {
+		name     string
+		language SQLLanguage
+		path     string
+		query    string
+		response string
+	}{
+		{
+			name:     "sql",
+			language: SQLLanguageSQL,
+			path:     "/_sql",
+			query:    "format=json",
+			response: `{"columns": [{"name": "host", "type": "keyword"}], "rows": [["server-1"]], "cursor": "abc"}`,
+		},
+		{
+			name:     "ppl",
+			language: SQLLanguagePPL,
+			path:     "/_plugins/_ppl",
+			query:    "",
+			response: `{"schema": [{"name": "host", "type": "string"}], "datarows": [["server-1"]]}`,
+		},
+	}
+
+	for _, test := range tt {
+		t.Run(test.name, func(t *testing.T) {
+			var request *http.Request
+			var requestBody []byte
+
+			ts := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				request = r
+				buf, err := io.ReadAll(r.Body)
+				require.NoError(t, err)
+				requestBody = buf
+
+				rw.Header().Set("Content-Type", "application/json")
+				_, err = rw.Write([]byte(test.response))
+				require.NoError(t, err)
+			}))
+			t.Cleanup(func() {
+				ts.Close()
+			})
+
+			ds := DatasourceInfo{
+				URL:        ts.URL,
+				HTTPClient: ts.Client(),
+				Database:   "logs-*",
+			}
+			c, err := NewClient(context.Background(), &ds, backend.TimeRange{}, log.New("test", "test"), tracing.InitializeTracerForTest())
+			require.NoError(t, err)
+
+			res, err := c.ExecuteSQL(&SQLRequest{Language: test.language, Query: "SELECT host FROM logs"})
+			require.NoError(t, err)
+
+			require.NotNil(t, request)
+			assert.Equal(t, http.MethodPost, request.Method)
+			assert.Equal(t, test.path, request.URL.Path)
+			assert.Equal(t, test.query, request.URL.RawQuery)
+			assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+
+			jBody, err := simplejson.NewJson(requestBody)
+			require.NoError(t, err)
+			assert.Equal(t, "SELECT host FROM logs", jBody.Get("query").MustString())
+
+			assert.Equal(t, 200, res.Status)
+			if test.language == SQLLanguagePPL {
+				require.Len(t, res.DataRows, 1)
+				require.Len(t, res.Schema, 1)
+			} else {
+				require.Len(t, res.Rows, 1)
+				require.Len(t, res.Columns, 1)
+				assert.Equal(t, "abc", res.Cursor)
+			}
+		})
+	}
+}
+
 func TestClient_Index(t *testing.T) {
 	tt := []struct {
 		name                string
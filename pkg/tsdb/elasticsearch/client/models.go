@@ -38,6 +38,19 @@ func (r *SearchRequest) MarshalJSON() ([]byte, error) {
 	return json.Marshal(root)
 }
 
+// CompositeAgg returns the top level composite aggregation with the given key
+func (r *SearchRequest) CompositeAgg(key string) *CompositeAggregation {
+	for _, agg := range r.Aggs {
+		if agg.Key != key {
+			continue
+		}
+		if composite, ok := agg.Aggregation.Aggregation.(*CompositeAggregation); ok {
+			return composite
+		}
+	}
+	return nil
+}
+
 // SearchResponseHits represents search response hits
 type SearchResponseHits struct {
 	Hits []map[string]interface{}
@@ -61,6 +74,45 @@ type MultiSearchResponse struct {
 	Responses []*SearchResponse `json:"responses"`
 }
 
+// SQLLanguage is the language of a raw query
+type SQLLanguage string
+
+const (
+	// SQLLanguageSQL is the Elasticsearch SQL language
+	SQLLanguageSQL SQLLanguage = "sql"
+	// SQLLanguagePPL is the OpenSearch Piped Processing Language
+	SQLLanguagePPL SQLLanguage = "ppl"
+)
+
+// SQLRequest represents a request of the SQL or PPL API
+type SQLRequest struct {
+	Language  SQLLanguage `json:"-"`
+	Query     string      `json:"query,omitempty"`
+	FetchSize int         `json:"fetch_size,omitempty"`
+	Cursor    string      `json:"cursor,omitempty"`
+	// Filter is a query DSL filter applied to the rows of a SQL query
+	Filter map[string]any `json:"filter,omitempty"`
+}
+
+// SQLColumn represents a column of a SQL or PPL response
+type SQLColumn struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// SQLResponse represents a response of the Elasticsearch SQL API or of the
+// OpenSearch PPL API, which returns the columns in schema and the rows in
+// datarows
+type SQLResponse struct {
+	Status   int                    `json:"-"`
+	Columns  []SQLColumn            `json:"columns"`
+	Rows     [][]interface{}        `json:"rows"`
+	Cursor   string                 `json:"cursor"`
+	Schema   []SQLColumn            `json:"schema"`
+	DataRows [][]interface{}        `json:"datarows"`
+	Error    map[string]interface{} `json:"error"`
+}
+
 // Query represents a query
 type Query struct {
 	Bool *BoolQuery `json:"bool"`
@@ -242,6 +294,25 @@ type TermsAggregation struct {
 	Missing     *string                `json:"missing,omitempty"`
 }
 
+// CompositeAggregation represents a composite aggregation
+type CompositeAggregation struct {
+	Size    int                      `json:"size"`
+	Sources []map[string]interface{} `json:"sources"`
+	After   map[string]interface{}   `json:"after,omitempty"`
+}
+
+// AddTermsSource adds a terms source on a field, named after the field
+func (a *CompositeAggregation) AddTermsSource(field string) {
+	a.Sources = append(a.Sources, map[string]interface{}{
+		field: map[string]interface{}{
+			"terms": map[string]interface{}{
+				"field":          field,
+				"missing_bucket": true,
+			},
+		},
+	})
+}
+
 // NestedAggregation represents a nested aggregation
 type NestedAggregation struct {
 	Path string `json:"path"`
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -19,6 +20,9 @@ import (
 
 const (
 	defaultSize = 500
+	// maxCompositePages limits the number of requests made to get all the
+	// buckets of a composite aggregation
+	maxCompositePages = 100
 )
 
 type elasticsearchDataQuery struct {
@@ -42,13 +46,39 @@ var newElasticsearchDataQuery = func(ctx context.Context, client es.Client, data
 func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 	start := time.Now()
 	e.logger.Debug("Parsing queries", "queriesLength", len(e.dataQueries))
-	queries, err := parseQuery(e.dataQueries, e.logger)
+	parsedQueries, err := parseQuery(e.dataQueries, e.logger)
 	if err != nil {
 		mq, _ := json.Marshal(e.dataQueries)
-		e.logger.Error("Failed to parse queries", "error", err, "queries", string(mq), "queriesLength", len(queries), "duration", time.Since(start), "stage", es.StagePrepareRequest)
+		e.logger.Error("Failed to parse queries", "error", err, "queries", string(mq), "queriesLength", len(parsedQueries), "duration", time.Since(start), "stage", es.StagePrepareRequest)
 		return &backend.QueryDataResponse{}, err
 	}
 
+	// SQL and PPL queries are not part of the multisearch request
+	queries := make([]*Query, 0, len(parsedQueries))
+	sqlQueries := make([]*Query, 0)
+	for _, q := range parsedQueries {
+		if isSQLQuery(q) {
+			sqlQueries = append(sqlQueries, q)
+		} else {
+			queries = append(queries, q)
+		}
+	}
+
+	result := &backend.QueryDataResponse{Responses: backend.Responses{}}
+	if len(queries) > 0 {
+		result, err = e.executeMultisearch(queries, start)
+		if err != nil {
+			return &backend.QueryDataResponse{}, err
+		}
+	}
+
+	for _, q := range sqlQueries {
+		result.Responses[q.RefID] = e.executeSQLQuery(q)
+	}
+	return result, nil
+}
+
+func (e *elasticsearchDataQuery) executeMultisearch(queries []*Query, start time.Time) (*backend.QueryDataResponse, error) {
 	ms := e.client.MultiSearch()
 
 	from := e.dataQueries[0].TimeRange.From.UnixNano() / int64(time.Millisecond)
@@ -74,7 +104,24 @@ func (e *elasticsearchDataQuery) execute() (*backend.QueryDataResponse, error) {
 		return &backend.QueryDataResponse{}, err
 	}
 
-	return parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.logger, e.tracer)
+	truncated, err := e.fetchCompositePages(queries, req, res)
+	if err != nil {
+		return &backend.QueryDataResponse{}, err
+	}
+
+	result, err := parseResponse(e.ctx, res.Responses, queries, e.client.GetConfiguredFields(), e.logger, e.tracer)
+	if err != nil {
+		return result, err
+	}
+	for _, refID := range truncated {
+		for _, frame := range result.Responses[refID].Frames {
+			frame.AppendNotices(data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("Results have been limited to %d pages of the composite aggregation, increase its size to get all buckets", maxCompositePages),
+			})
+		}
+	}
+	return result, nil
 }
 
 func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchRequestBuilder, from, to int64) error {
@@ -103,6 +150,115 @@ func (e *elasticsearchDataQuery) processQuery(q *Query, ms *es.MultiSearchReques
 	return nil
 }
 
+// fetchCompositePages requests the next pages of the composite aggregations,
+// using the after key of the previous page, and appends their buckets to the
+// buckets of the first page. It returns the refIDs of the queries with more
+// pages than maxCompositePages.
+func (e *elasticsearchDataQuery) fetchCompositePages(queries []*Query, req *es.MultiSearchRequest, res *es.MultiSearchResponse) ([]string, error) {
+	var truncated []string
+	// lastPages holds the last received page of each query with a composite aggregation
+	lastPages := make(map[int]*es.SearchResponse)
+	for i, q := range queries {
+		if getCompositeAgg(q) != nil && i < len(res.Responses) && i < len(req.Requests) {
+			lastPages[i] = res.Responses[i]
+		}
+	}
+
+	for page := 1; len(lastPages) > 0; page++ {
+		pageReq := &es.MultiSearchRequest{}
+		next := make([]int, 0, len(lastPages))
+		for i := range queries {
+			lastPage, ok := lastPages[i]
+			if !ok {
+				continue
+			}
+			aggID := getCompositeAgg(queries[i]).ID
+			composite := req.Requests[i].CompositeAgg(aggID)
+			if composite == nil {
+				continue
+			}
+			afterKey, ok := getCompositeAfterKey(lastPage, aggID, composite.Size)
+			if !ok {
+				continue
+			}
+			if page >= maxCompositePages {
+				e.logger.Warn("Composite aggregation has more buckets than can be requested", "refId", queries[i].RefID, "pages", page)
+				truncated = append(truncated, queries[i].RefID)
+				continue
+			}
+			composite.After = afterKey
+			pageReq.Requests = append(pageReq.Requests, req.Requests[i])
+			next = append(next, i)
+		}
+		if len(next) == 0 {
+			return truncated, nil
+		}
+
+		e.logger.Debug("Requesting next page of composite aggregations", "page", page+1, "queriesLength", len(next))
+		pageRes, err := e.client.ExecuteMultisearch(pageReq)
+		if err != nil {
+			return nil, err
+		}
+
+		lastPages = make(map[int]*es.SearchResponse, len(next))
+		for j, i := range next {
+			if j >= len(pageRes.Responses) {
+				break
+			}
+			if appendCompositeBuckets(res.Responses[i], pageRes.Responses[j], getCompositeAgg(queries[i]).ID) {
+				lastPages[i] = pageRes.Responses[j]
+			}
+		}
+	}
+	return truncated, nil
+}
+
+// getCompositeAfterKey returns the key to request the page following the
+// given one. There is no next page when the given page is not full.
+func getCompositeAfterKey(page *es.SearchResponse, aggID string, size int) (map[string]any, bool) {
+	if page == nil || page.Error != nil {
+		return nil, false
+	}
+	agg, ok := page.Aggregations[aggID].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	afterKey, ok := agg["after_key"].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	buckets, _ := agg["buckets"].([]any)
+	return afterKey, len(buckets) >= size
+}
+
+// appendCompositeBuckets appends the buckets of a page to the response of
+// the first page, and returns whether there can be a next page.
+func appendCompositeBuckets(res *es.SearchResponse, page *es.SearchResponse, aggID string) bool {
+	if page == nil {
+		return false
+	}
+	if page.Error != nil {
+		res.Error = page.Error
+		return false
+	}
+	agg, ok := res.Aggregations[aggID].(map[string]any)
+	if !ok {
+		return false
+	}
+	pageAgg, ok := page.Aggregations[aggID].(map[string]any)
+	if !ok {
+		return false
+	}
+
+	buckets, _ := agg["buckets"].([]any)
+	pageBuckets, _ := pageAgg["buckets"].([]any)
+	agg["buckets"] = append(buckets, pageBuckets...)
+	if afterKey, ok := pageAgg["after_key"]; ok {
+		agg["after_key"] = afterKey
+	}
+	return len(pageBuckets) > 0
+}
+
 func setFloatPath(settings *simplejson.Json, path ...string) {
 	if stringValue, err := settings.GetPath(path...).String(); err == nil {
 		if value, err := strconv.ParseFloat(stringValue, 64); err == nil {
@@ -271,6 +427,43 @@ func addNestedAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder
 	return aggBuilder
 }
 
+func addCompositeAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
+	aggBuilder.Composite(bucketAgg.ID, func(a *es.CompositeAggregation, b es.AggBuilder) {
+		a.Size = stringToIntWithDefaultValue(bucketAgg.Settings.Get("size").MustString(), defaultSize)
+		for _, field := range getCompositeSourceFields(bucketAgg) {
+			a.AddTermsSource(field)
+		}
+		aggBuilder = b
+	})
+
+	return aggBuilder
+}
+
+// getCompositeSourceFields returns the fields of the terms sources of a
+// composite aggregation, the field of the aggregation comes first.
+func getCompositeSourceFields(bucketAgg *BucketAgg) []string {
+	fields := make([]string, 0)
+	if bucketAgg.Field != "" {
+		fields = append(fields, bucketAgg.Field)
+	}
+	for _, f := range bucketAgg.Settings.Get("fields").MustArray() {
+		field, ok := f.(string)
+		if ok && field != "" && !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// getCompositeAgg returns the composite aggregation of a query. Composite
+// aggregations can't have a parent aggregation, so it is the first one.
+func getCompositeAgg(query *Query) *BucketAgg {
+	if len(query.BucketAggs) > 0 && query.BucketAggs[0].Type == compositeType {
+		return query.BucketAggs[0]
+	}
+	return nil
+}
+
 func addFiltersAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg) es.AggBuilder {
 	filters := make(map[string]any)
 	for _, filter := range bucketAgg.Settings.Get("filters").MustArray() {
@@ -322,6 +515,17 @@ func isQueryWithError(query *Query) error {
 			return fmt.Errorf("invalid query, missing metrics and aggregations")
 		}
 	}
+	for i, bucketAgg := range query.BucketAggs {
+		if bucketAgg.Type != compositeType {
+			continue
+		}
+		if i > 0 {
+			return fmt.Errorf("invalid query, composite aggregation must be the first bucket aggregation")
+		}
+		if len(getCompositeSourceFields(bucketAgg)) == 0 {
+			return fmt.Errorf("invalid query, composite aggregation has no fields")
+		}
+	}
 	return nil
 }
 
@@ -410,6 +614,8 @@ func processTimeSeriesQuery(q *Query, b *es.SearchRequestBuilder, from, to int64
 			aggBuilder = addGeoHashGridAgg(aggBuilder, bucketAgg)
 		case nestedType:
 			aggBuilder = addNestedAgg(aggBuilder, bucketAgg)
+		case compositeType:
+			aggBuilder = addCompositeAgg(aggBuilder, bucketAgg)
 		}
 	}
 
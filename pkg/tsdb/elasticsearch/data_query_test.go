@@ -3,6 +3,7 @@ package elasticsearch
 import (
 	"context"
 	"encoding/json"
+	"strconv"
 	"testing"
 	"time"
 
@@ -1434,6 +1435,99 @@ func TestExecuteElasticsearchDataQuery(t *testing.T) {
 			require.Error(t, err)
 		}))
 	})
+	t.Run("Test execute composite query", func(t *testing.T) {
+		t.Run("With terms sources", func(t *testing.T) {
+			c := newFakeClient()
+			_, err := executeElasticsearchDataQuery(c, `{
+				"bucketAggs": [
+					{ "type": "composite", "field": "@host", "id": "2", "settings": { "fields": ["@status", "@host"], "size": "100" } },
+					{ "type": "date_histogram", "field": "@timestamp", "id": "3" }
+				],
+				"metrics": [{"type": "count", "id": "1" }]
+			}`, from, to)
+			require.NoError(t, err)
+			sr := c.multisearchRequests[0].Requests[0]
+			firstLevel := sr.Aggs[0]
+			require.Equal(t, firstLevel.Key, "2")
+			require.Equal(t, firstLevel.Aggregation.Type, "composite")
+			compositeAgg := firstLevel.Aggregation.Aggregation.(*es.CompositeAggregation)
+			require.Equal(t, 100, compositeAgg.Size)
+			require.Len(t, compositeAgg.Sources, 2)
+			require.Contains(t, compositeAgg.Sources[0], "@host")
+			require.Contains(t, compositeAgg.Sources[1], "@status")
+			secondLevel := firstLevel.Aggregation.Aggs[0]
+			require.Equal(t, secondLevel.Key, "3")
+		})
+
+		t.Run("Should return error when composite is not the first bucket agg", func(t *testing.T) {
+			c := newFakeClient()
+			_, err := executeElasticsearchDataQuery(c, `{
+				"bucketAggs": [
+					{ "type": "date_histogram", "field": "@timestamp", "id": "2" },
+					{ "type": "composite", "field": "@host", "id": "3" }
+				],
+				"metrics": [{"type": "count", "id": "1" }]
+			}`, from, to)
+			require.Error(t, err)
+		})
+
+		t.Run("Should request all pages using the after key", func(t *testing.T) {
+			c := newFakeClient()
+			page := func(afterKey string, keys ...string) *es.SearchResponse {
+				buckets := make([]any, 0, len(keys))
+				for _, key := range keys {
+					buckets = append(buckets, map[string]any{"key": map[string]any{"@host": key}, "doc_count": float64(1)})
+				}
+				agg := map[string]any{"buckets": buckets}
+				if afterKey != "" {
+					agg["after_key"] = map[string]any{"@host": afterKey}
+				}
+				return &es.SearchResponse{Aggregations: map[string]any{"2": agg}}
+			}
+			c.multiSearchPages = []*es.MultiSearchResponse{
+				{Responses: []*es.SearchResponse{page("b", "a", "b")}},
+				{Responses: []*es.SearchResponse{page("d", "c", "d")}},
+				{Responses: []*es.SearchResponse{page("e", "e")}},
+			}
+			res, err := executeElasticsearchDataQuery(c, `{
+				"bucketAggs": [{ "type": "composite", "field": "@host", "id": "2", "settings": { "size": "2" } }],
+				"metrics": [{"type": "count", "id": "1" }]
+			}`, from, to)
+			require.NoError(t, err)
+			require.Len(t, c.multisearchRequests, 3)
+			require.Equal(t, map[string]any{"@host": "d"}, c.multisearchRequests[2].Requests[0].CompositeAgg("2").After)
+
+			frames := res.Responses[""].Frames
+			require.Len(t, frames, 1)
+			requireFrameLength(t, frames[0], 5)
+			requireStringAt(t, "e", frames[0].Fields[0], 4)
+		})
+
+		t.Run("Should add a notice when there are more pages than can be requested", func(t *testing.T) {
+			c := newFakeClient()
+			// every page is full and has an after key
+			for i := 0; i <= maxCompositePages; i++ {
+				host := strconv.Itoa(i)
+				c.multiSearchPages = append(c.multiSearchPages, &es.MultiSearchResponse{Responses: []*es.SearchResponse{{
+					Aggregations: map[string]any{"2": map[string]any{
+						"buckets":   []any{map[string]any{"key": map[string]any{"@host": host}, "doc_count": 1.0}},
+						"after_key": map[string]any{"@host": host},
+					}},
+				}}})
+			}
+			res, err := executeElasticsearchDataQuery(c, `{
+				"bucketAggs": [{ "type": "composite", "field": "@host", "id": "2", "settings": { "size": "1" } }],
+				"metrics": [{"type": "count", "id": "1" }]
+			}`, from, to)
+			require.NoError(t, err)
+			require.Len(t, c.multisearchRequests, maxCompositePages)
+
+			frames := res.Responses[""].Frames
+			require.Len(t, frames, 1)
+			require.Len(t, frames[0].Meta.Notices, 1)
+			require.Contains(t, frames[0].Meta.Notices[0].Text, "limited to 100 pages")
+		})
+	})
 }
 
 func TestSettingsCasting(t *testing.T) {
@@ -1800,10 +1894,16 @@ func TestSettingsCasting(t *testing.T) {
 
 type fakeClient struct {
 	configuredFields    es.ConfiguredFields
+	indices             []string
 	multiSearchResponse *es.MultiSearchResponse
+	// multiSearchPages are returned in order before multiSearchResponse
+	multiSearchPages    []*es.MultiSearchResponse
 	multiSearchError    error
 	builder             *es.MultiSearchRequestBuilder
 	multisearchRequests []*es.MultiSearchRequest
+	sqlResponses        []*es.SQLResponse
+	sqlRequests         []*es.SQLRequest
+	closedSQLCursors    []string
 }
 
 func newFakeClient() *fakeClient {
@@ -1815,6 +1915,7 @@ func newFakeClient() *fakeClient {
 
 	return &fakeClient{
 		configuredFields:    configuredFields,
+		indices:             []string{"logs-*"},
 		multisearchRequests: make([]*es.MultiSearchRequest, 0),
 		multiSearchResponse: &es.MultiSearchResponse{},
 	}
@@ -1824,11 +1925,35 @@ func (c *fakeClient) GetConfiguredFields() es.ConfiguredFields {
 	return c.configuredFields
 }
 
+func (c *fakeClient) GetIndices() []string {
+	return c.indices
+}
+
 func (c *fakeClient) ExecuteMultisearch(r *es.MultiSearchRequest) (*es.MultiSearchResponse, error) {
 	c.multisearchRequests = append(c.multisearchRequests, r)
+	if len(c.multiSearchPages) > 0 {
+		page := c.multiSearchPages[0]
+		c.multiSearchPages = c.multiSearchPages[1:]
+		return page, c.multiSearchError
+	}
 	return c.multiSearchResponse, c.multiSearchError
 }
 
+func (c *fakeClient) ExecuteSQL(r *es.SQLRequest) (*es.SQLResponse, error) {
+	c.sqlRequests = append(c.sqlRequests, r)
+	if len(c.sqlResponses) == 0 {
+		return &es.SQLResponse{Status: 200}, nil
+	}
+	res := c.sqlResponses[0]
+	c.sqlResponses = c.sqlResponses[1:]
+	return res, nil
+}
+
+func (c *fakeClient) CloseSQLCursor(cursor string) error {
+	c.closedSQLCursors = append(c.closedSQLCursors, cursor)
+	return nil
+}
+
 func (c *fakeClient) MultiSearch() *es.MultiSearchRequestBuilder {
 	c.builder = es.NewMultiSearchRequestBuilder()
 	return c.builder
@@ -11,6 +11,7 @@ package dataquery
 
 // Defines values for BucketAggregationType.
 const (
+	BucketAggregationTypeComposite     BucketAggregationType = "composite"
 	BucketAggregationTypeDateHistogram BucketAggregationType = "date_histogram"
 	BucketAggregationTypeFilters       BucketAggregationType = "filters"
 	BucketAggregationTypeGeohashGrid   BucketAggregationType = "geohash_grid"
@@ -73,6 +74,13 @@ const (
 	PipelineMetricAggregationTypeSerialDiff    PipelineMetricAggregationType = "serial_diff"
 )
 
+// Defines values for QueryLanguage.
+const (
+	QueryLanguageLucene QueryLanguage = "lucene"
+	QueryLanguagePpl    QueryLanguage = "ppl"
+	QueryLanguageSql    QueryLanguage = "sql"
+)
+
 // Defines values for TermsOrder.
 const (
 	TermsOrderAsc  TermsOrder = "asc"
@@ -143,6 +151,23 @@ type BucketScript struct {
 	Type MetricAggregationType `json:"type"`
 }
 
+// Composite defines model for Composite.
+type Composite struct {
+	BucketAggregationWithField
+	Id       string                `json:"id"`
+	Settings *any                  `json:"settings,omitempty"`
+	Type     BucketAggregationType `json:"type"`
+}
+
+// CompositeSettings defines model for CompositeSettings.
+type CompositeSettings struct {
+	// Fields of the terms sources of the composite aggregation
+	Fields []string `json:"fields,omitempty"`
+
+	// Number of buckets requested at a time, all pages are requested
+	Size *string `json:"size,omitempty"`
+}
+
 // Count defines model for Count.
 type Count struct {
 	BaseMetricAggregation
@@ -231,9 +256,12 @@ type ElasticsearchDataQuery struct {
 	// List of metric aggregations
 	Metrics []any `json:"metrics,omitempty"`
 
-	// Lucene query
+	// Lucene query, or raw query in the query language
 	Query *string `json:"query,omitempty"`
 
+	// Language of the query, the query editor model is used for Lucene queries
+	QueryLanguage *QueryLanguage `json:"queryLanguage,omitempty"`
+
 	// Name of time field
 	TimeField *string `json:"timeField,omitempty"`
 }
@@ -495,6 +523,9 @@ type PipelineVariable struct {
 	PipelineAgg string `json:"pipelineAgg"`
 }
 
+// QueryLanguage defines model for QueryLanguage.
+type QueryLanguage string
+
 // Rate defines model for Rate.
 type Rate struct {
 	MetricAggregationWithField
@@ -19,6 +19,8 @@ func parseQuery(tsdbQuery []backend.DataQuery, logger log.Logger) ([]*Query, err
 		// please do not create a new field with that name, to avoid potential problems with old, persisted queries.
 
 		rawQuery := model.Get("query").MustString()
+		// the query is a raw Elasticsearch SQL or OpenSearch PPL query when the language is set
+		queryLanguage := model.Get("queryLanguage").MustString(luceneQueryLanguage)
 		bucketAggs, err := parseBucketAggs(model)
 		if err != nil {
 			logger.Error("Failed to parse bucket aggs in query", "error", err, "model", string(q.JSON))
@@ -35,6 +37,7 @@ func parseQuery(tsdbQuery []backend.DataQuery, logger log.Logger) ([]*Query, err
 
 		queries = append(queries, &Query{
 			RawQuery:      rawQuery,
+			QueryLanguage: queryLanguage,
 			BucketAggs:    bucketAggs,
 			Metrics:       metrics,
 			Alias:         alias,
@@ -37,6 +37,7 @@ const (
 	filtersType     = "filters"
 	termsType       = "terms"
 	geohashGridType = "geohash_grid"
+	compositeType   = "composite"
 	//  Document types
 	rawDocumentType = "raw_document"
 	rawDataType     = "raw_data"
@@ -405,6 +406,17 @@ func processBuckets(aggs map[string]interface{}, target *Query,
 			}
 			continue
 		}
+		if aggDef.Type == compositeType {
+			if depth == maxDepth {
+				err = processCompositeAggregationDocs(esAgg, aggDef, target, queryResult)
+			} else {
+				err = processCompositeBuckets(esAgg, aggDef, target, queryResult, props, depth)
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
 
 		if depth == maxDepth {
 			if aggDef.Type == dateHistType {
@@ -466,6 +478,92 @@ func processBuckets(aggs map[string]interface{}, target *Query,
 	return nil
 }
 
+// processCompositeBuckets processes the child aggregations of each bucket of
+// a composite aggregation, with the values of the bucket key as props
+func processCompositeBuckets(esAgg *simplejson.Json, aggDef *BucketAgg, target *Query,
+	queryResult *backend.DataResponse, props map[string]string, depth int) error {
+	sourceFields := getCompositeSourceFields(aggDef)
+	for _, b := range esAgg.Get("buckets").MustArray() {
+		bucket := simplejson.NewFromAny(b)
+		newProps := make(map[string]string)
+
+		for k, v := range props {
+			newProps[k] = v
+		}
+
+		for _, field := range sourceFields {
+			if key := getCompositeKeyValue(bucket, field); key != nil {
+				newProps[field] = *key
+			}
+		}
+
+		err := processBuckets(bucket.MustMap(), target, queryResult, newProps, depth+1)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// processCompositeAggregationDocs creates a table with a column for each
+// source of the composite aggregation and for each metric
+func processCompositeAggregationDocs(esAgg *simplejson.Json, aggDef *BucketAgg, target *Query,
+	queryResult *backend.DataResponse) error {
+	sourceFields := getCompositeSourceFields(aggDef)
+	fields := make([]*data.Field, 0, len(sourceFields))
+	for _, field := range sourceFields {
+		fields = append(fields, extractDataField(field, new(string)))
+	}
+
+	for _, v := range esAgg.Get("buckets").MustArray() {
+		bucket := simplejson.NewFromAny(v)
+		var values []interface{}
+
+		for i, field := range sourceFields {
+			fields[i].Append(getCompositeKeyValue(bucket, field))
+		}
+
+		for _, metric := range target.Metrics {
+			switch metric.Type {
+			case countType:
+				addMetricValueToFields(&fields, values, getMetricName(metric.Type), castToFloat(bucket.Get("doc_count")))
+			case extendedStatsType:
+				addExtendedStatsToFields(&fields, bucket, metric, values)
+			case percentilesType:
+				addPercentilesToFields(&fields, bucket, metric, values)
+			case topMetricsType:
+				addTopMetricsToFields(&fields, bucket, metric, values)
+			default:
+				addOtherMetricsToFields(&fields, bucket, metric, values, target)
+			}
+		}
+	}
+
+	queryResult.Frames = data.Frames{
+		&data.Frame{
+			Fields: fields,
+		}}
+	return nil
+}
+
+// getCompositeKeyValue returns the value of a source in the key of a
+// composite aggregation bucket, or nil for the bucket of missing values
+func getCompositeKeyValue(bucket *simplejson.Json, field string) *string {
+	key := bucket.GetPath("key", field)
+	if value, err := key.String(); err == nil {
+		return &value
+	}
+	if value, err := key.Float64(); err == nil {
+		formatted := strconv.FormatFloat(value, 'f', -1, 64)
+		return &formatted
+	}
+	if value, err := key.Bool(); err == nil {
+		formatted := strconv.FormatBool(value)
+		return &formatted
+	}
+	return nil
+}
+
 func newTimeSeriesFrame(timeData []time.Time, tags map[string]string, values []*float64) *data.Frame {
 	frame := data.NewFrame("",
 		data.NewField(data.TimeSeriesTimeFieldName, nil, timeData),
@@ -1242,6 +1242,103 @@ func TestProcessBuckets(t *testing.T) {
 			requireFloatAt(t, 369.0, f3, 0)
 			requireFloatAt(t, 200.0, f3, 1)
 		})
+
+		t.Run("Composite agg without date histogram", func(t *testing.T) {
+			query := []byte(`
+	[
+		{
+		  "refId": "A",
+		  "metrics": [{ "type": "count", "id": "1" }],
+		  "bucketAggs": [{ "id": "2", "type": "composite", "field": "host", "settings": { "fields": ["status"] } }]
+		}
+	]
+	`)
+
+			response := []byte(`
+	{
+		"responses": [
+		  {
+			"aggregations": {
+			  "2": {
+				"after_key": { "host": "server-2", "status": 500 },
+				"buckets": [
+				  { "key": { "host": "server-1", "status": 200 }, "doc_count": 369 },
+				  { "key": { "host": null, "status": 404 }, "doc_count": 10 },
+				  { "key": { "host": "server-2", "status": 500 }, "doc_count": 200 }
+				]
+			  }
+			}
+		  }
+		]
+	}
+	`)
+
+			result, err := queryDataTest(query, response)
+			require.NoError(t, err)
+
+			frames := result.response.Responses["A"].Frames
+			require.Len(t, frames, 1)
+
+			frame := frames[0]
+			requireFrameLength(t, frame, 3)
+			require.Len(t, frame.Fields, 3)
+			require.Equal(t, "host", frame.Fields[0].Name)
+			require.Equal(t, "status", frame.Fields[1].Name)
+			require.Equal(t, "Count", frame.Fields[2].Name)
+
+			requireStringAt(t, "server-1", frame.Fields[0], 0)
+			require.Nil(t, frame.Fields[0].At(1))
+			requireStringAt(t, "404", frame.Fields[1], 1)
+			requireFloatAt(t, 200.0, frame.Fields[2], 2)
+		})
+
+		t.Run("Composite agg with date histogram", func(t *testing.T) {
+			query := []byte(`
+	[
+		{
+		  "refId": "A",
+		  "metrics": [{ "type": "count", "id": "1" }],
+		  "bucketAggs": [
+			{ "id": "2", "type": "composite", "field": "host" },
+			{ "id": "3", "type": "date_histogram", "field": "@timestamp" }
+		  ]
+		}
+	]
+	`)
+
+			response := []byte(`
+	{
+		"responses": [
+		  {
+			"aggregations": {
+			  "2": {
+				"buckets": [
+				  {
+					"key": { "host": "server-1" },
+					"3": { "buckets": [{ "doc_count": 1, "key": 1000 }, { "doc_count": 3, "key": 2000 }] }
+				  },
+				  {
+					"key": { "host": "server-2" },
+					"3": { "buckets": [{ "doc_count": 2, "key": 1000 }, { "doc_count": 8, "key": 2000 }] }
+				  }
+				]
+			  }
+			}
+		  }
+		]
+	}
+	`)
+
+			result, err := queryDataTest(query, response)
+			require.NoError(t, err)
+
+			frames := result.response.Responses["A"].Frames
+			require.Len(t, frames, 2)
+			requireTimeSeriesName(t, "server-1", frames[0])
+			requireTimeSeriesName(t, "server-2", frames[1])
+			requireNumberValue(t, 3, frames[0], 1)
+			requireNumberValue(t, 8, frames[1], 1)
+		})
 	})
 
 	t.Run("Top metrics", func(t *testing.T) {
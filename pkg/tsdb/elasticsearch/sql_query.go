@@ -0,0 +1,283 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+const (
+	luceneQueryLanguage = "lucene"
+	sqlQueryLanguage    = "sql"
+	pplQueryLanguage    = "ppl"
+
+	// sqlFetchSize is the number of rows requested per page of an Elasticsearch SQL query
+	sqlFetchSize = 1000
+	// maxSQLRows limits the number of rows returned for a SQL query
+	maxSQLRows = 50000
+)
+
+var (
+	sqlTimeFilterRegex = regexp.MustCompile(`\$__timeFilter\(\s*([^)]*?)\s*\)`)
+	// pplSourceRegex matches the indices of the first command of a PPL query
+	pplSourceRegex = regexp.MustCompile(`(?i)^\s*(?:search\s+)?source\s*=\s*([^|\s]+)`)
+)
+
+func isSQLQuery(query *Query) bool {
+	return query.QueryLanguage == sqlQueryLanguage || query.QueryLanguage == pplQueryLanguage
+}
+
+// executeSQLQuery executes a raw Elasticsearch SQL or OpenSearch PPL query
+// and returns its rows as a table frame
+func (e *elasticsearchDataQuery) executeSQLQuery(q *Query) backend.DataResponse {
+	if strings.TrimSpace(q.RawQuery) == "" {
+		return backend.DataResponse{Error: fmt.Errorf("invalid query, missing %s query", q.QueryLanguage)}
+	}
+
+	language := es.SQLLanguage(q.QueryLanguage)
+	indices := e.client.GetIndices()
+	req := &es.SQLRequest{
+		Language: language,
+		Query:    interpolateSQLMacros(q.RawQuery, language, e.dataQueries[0].TimeRange, indices),
+	}
+	if language == es.SQLLanguageSQL {
+		req.FetchSize = sqlFetchSize
+		req.Filter = indexFilter(indices)
+	} else if err := checkPPLSource(req.Query, indices); err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	res, err := e.client.ExecuteSQL(req)
+	if err != nil {
+		return backend.DataResponse{Error: err}
+	}
+	if err := getErrorFromSQLResponse(res); err != nil {
+		return backend.DataResponse{Error: err}
+	}
+
+	columns, rows := res.Columns, res.Rows
+	if language == es.SQLLanguagePPL {
+		columns, rows = res.Schema, res.DataRows
+	}
+
+	// Elasticsearch SQL returns the next pages of the rows with a cursor
+	cursor := res.Cursor
+	for cursor != "" && len(rows) < maxSQLRows {
+		res, err = e.client.ExecuteSQL(&es.SQLRequest{Language: language, Cursor: cursor})
+		if err != nil {
+			return backend.DataResponse{Error: err}
+		}
+		if err := getErrorFromSQLResponse(res); err != nil {
+			return backend.DataResponse{Error: err}
+		}
+		rows = append(rows, res.Rows...)
+		cursor = res.Cursor
+	}
+
+	if cursor != "" {
+		if err := e.client.CloseSQLCursor(cursor); err != nil {
+			e.logger.Warn("Failed to close SQL cursor", "error", err)
+		}
+	}
+
+	frame := sqlRowsToFrame(q.RefID, columns, rows)
+	if cursor != "" || len(rows) > maxSQLRows {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("Results have been limited to %d rows", maxSQLRows),
+		})
+	}
+
+	return backend.DataResponse{Frames: data.Frames{frame}}
+}
+
+// interpolateSQLMacros replaces the time range and index macros of a SQL or PPL query
+func interpolateSQLMacros(query string, language es.SQLLanguage, timeRange backend.TimeRange, indices []string) string {
+	format := time.RFC3339Nano
+	index := `"` + strings.Join(indices, ",") + `"`
+	if language == es.SQLLanguagePPL {
+		format = time.DateTime
+		index = strings.Join(indices, ",")
+	}
+	query = strings.ReplaceAll(query, "$__index", index)
+
+	from := "'" + timeRange.From.UTC().Format(format) + "'"
+	to := "'" + timeRange.To.UTC().Format(format) + "'"
+
+	query = sqlTimeFilterRegex.ReplaceAllStringFunc(query, func(macro string) string {
+		field := sqlTimeFilterRegex.FindStringSubmatch(macro)[1]
+		return fmt.Sprintf("%s >= %s AND %s <= %s", field, from, field, to)
+	})
+	query = strings.ReplaceAll(query, "$__timeFrom()", from)
+	query = strings.ReplaceAll(query, "$__timeTo()", to)
+	return query
+}
+
+// indexFilter restricts the rows of a SQL query to the indices of the data
+// source, whatever indices the query reads
+func indexFilter(indices []string) map[string]any {
+	if len(indices) == 0 {
+		return nil
+	}
+	should := make([]any, 0, len(indices))
+	for _, index := range indices {
+		should = append(should, map[string]any{
+			"wildcard": map[string]any{"_index": map[string]any{"value": index}},
+		})
+	}
+	return map[string]any{
+		"bool": map[string]any{"should": should, "minimum_should_match": 1},
+	}
+}
+
+// checkPPLSource returns an error unless a PPL query only reads the indices of
+// the data source. PPL has no filter parameter, so the source is checked.
+func checkPPLSource(query string, indices []string) error {
+	if len(indices) == 0 {
+		return nil
+	}
+	match := pplSourceRegex.FindStringSubmatch(query)
+	if match == nil {
+		return errors.New("invalid query, PPL queries must start with source=$__index")
+	}
+	for _, source := range strings.Split(match[1], ",") {
+		if !slices.Contains(indices, strings.Trim(source, "`")) {
+			return fmt.Errorf("invalid query, %s is not an index of the data source, use source=$__index", source)
+		}
+	}
+	return nil
+}
+
+func getErrorFromSQLResponse(res *es.SQLResponse) error {
+	if res.Error == nil && res.Status < http.StatusBadRequest {
+		return nil
+	}
+	reason, _ := res.Error["reason"].(string)
+	// OpenSearch returns the cause of the error in details
+	if details, ok := res.Error["details"].(string); ok && details != "" {
+		if reason == "" {
+			return errors.New(details)
+		}
+		return fmt.Errorf("%s: %s", reason, details)
+	}
+	if reason != "" {
+		return errors.New(reason)
+	}
+	return fmt.Errorf("unexpected status code %d from Elasticsearch", res.Status)
+}
+
+// sqlRowsToFrame converts the rows of a SQL or PPL response to a table frame,
+// using the column types reported by Elasticsearch
+func sqlRowsToFrame(refID string, columns []es.SQLColumn, rows [][]interface{}) *data.Frame {
+	if len(rows) > maxSQLRows {
+		rows = rows[:maxSQLRows]
+	}
+
+	fields := make([]*data.Field, 0, len(columns))
+	for i, column := range columns {
+		var field *data.Field
+		switch sqlColumnFieldType(column.Type) {
+		case data.FieldTypeNullableTime:
+			values := make([]*time.Time, len(rows))
+			for j, row := range rows {
+				values[j] = sqlValueAsTime(getSQLRowValue(row, i))
+			}
+			field = data.NewField(column.Name, nil, values)
+		case data.FieldTypeNullableFloat64:
+			values := make([]*float64, len(rows))
+			for j, row := range rows {
+				if v, ok := getSQLRowValue(row, i).(float64); ok {
+					values[j] = &v
+				}
+			}
+			field = data.NewField(column.Name, nil, values)
+		case data.FieldTypeNullableBool:
+			values := make([]*bool, len(rows))
+			for j, row := range rows {
+				if v, ok := getSQLRowValue(row, i).(bool); ok {
+					values[j] = &v
+				}
+			}
+			field = data.NewField(column.Name, nil, values)
+		default:
+			values := make([]*string, len(rows))
+			for j, row := range rows {
+				values[j] = sqlValueAsString(getSQLRowValue(row, i))
+			}
+			field = data.NewField(column.Name, nil, values)
+		}
+		isFilterable := true
+		field.Config = &data.FieldConfig{Filterable: &isFilterable}
+		fields = append(fields, field)
+	}
+
+	frame := data.NewFrame(refID, fields...)
+	frame.RefID = refID
+	setPreferredVisType(frame, data.VisTypeTable)
+	return frame
+}
+
+func sqlColumnFieldType(columnType string) data.FieldType {
+	switch strings.ToLower(columnType) {
+	case "datetime", "date", "timestamp", "date_nanos":
+		return data.FieldTypeNullableTime
+	case "byte", "short", "integer", "long", "unsigned_long", "double", "float", "half_float", "scaled_float":
+		return data.FieldTypeNullableFloat64
+	case "boolean":
+		return data.FieldTypeNullableBool
+	default:
+		return data.FieldTypeNullableString
+	}
+}
+
+func getSQLRowValue(row []interface{}, i int) interface{} {
+	if i < len(row) {
+		return row[i]
+	}
+	return nil
+}
+
+func sqlValueAsTime(v interface{}) *time.Time {
+	switch value := v.(type) {
+	case string:
+		for _, layout := range []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999", time.DateOnly} {
+			if t, err := time.Parse(layout, value); err == nil {
+				return &t
+			}
+		}
+	case float64:
+		t := time.UnixMilli(int64(value)).UTC()
+		return &t
+	}
+	return nil
+}
+
+func sqlValueAsString(v interface{}) *string {
+	switch value := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return &value
+	case float64, bool:
+		s := fmt.Sprintf("%v", value)
+		return &s
+	default:
+		// objects and arrays are returned as JSON
+		b, err := json.Marshal(value)
+		if err != nil {
+			return nil
+		}
+		s := string(b)
+		return &s
+	}
+}
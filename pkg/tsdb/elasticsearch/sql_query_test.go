@@ -0,0 +1,139 @@
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+func TestExecuteSQLQuery(t *testing.T) {
+	from := time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC)
+	to := time.Date(2018, 5, 15, 17, 55, 0, 0, time.UTC)
+
+	t.Run("Should execute SQL query and request all pages", func(t *testing.T) {
+		c := newFakeClient()
+		c.sqlResponses = []*es.SQLResponse{
+			{
+				Status: 200,
+				Columns: []es.SQLColumn{
+					{Name: "@timestamp", Type: "datetime"},
+					{Name: "host", Type: "keyword"},
+					{Name: "value", Type: "long"},
+				},
+				Rows:   [][]any{{"2018-05-15T17:51:00.000Z", "server-1", float64(10)}},
+				Cursor: "cursor-1",
+			},
+			{
+				Status: 200,
+				Rows:   [][]any{{"2018-05-15T17:52:00.000Z", nil, float64(20)}},
+			},
+		}
+		res, err := executeElasticsearchDataQuery(c, `{
+			"queryLanguage": "sql",
+			"query": "SELECT \"@timestamp\", host, value FROM $__index WHERE $__timeFilter(\"@timestamp\")"
+		}`, from, to)
+		require.NoError(t, err)
+		require.Len(t, c.multisearchRequests, 0)
+		require.Len(t, c.sqlRequests, 2)
+		require.Equal(t, `SELECT "@timestamp", host, value FROM "logs-*" WHERE "@timestamp" >= '2018-05-15T17:50:00Z' AND "@timestamp" <= '2018-05-15T17:55:00Z'`, c.sqlRequests[0].Query)
+		require.Equal(t, sqlFetchSize, c.sqlRequests[0].FetchSize)
+		require.Equal(t, map[string]any{"bool": map[string]any{
+			"should":               []any{map[string]any{"wildcard": map[string]any{"_index": map[string]any{"value": "logs-*"}}}},
+			"minimum_should_match": 1,
+		}}, c.sqlRequests[0].Filter)
+		require.Equal(t, "cursor-1", c.sqlRequests[1].Cursor)
+		require.Empty(t, c.closedSQLCursors)
+
+		dataRes := res.Responses[""]
+		require.NoError(t, dataRes.Error)
+		require.Len(t, dataRes.Frames, 1)
+		frame := dataRes.Frames[0]
+		requireFrameLength(t, frame, 2)
+		require.Equal(t, data.FieldTypeNullableTime, frame.Fields[0].Type())
+		require.Equal(t, data.FieldTypeNullableString, frame.Fields[1].Type())
+		require.Equal(t, data.FieldTypeNullableFloat64, frame.Fields[2].Type())
+		require.Nil(t, frame.Fields[1].At(1))
+		requireFloatAt(t, 20, frame.Fields[2], 1)
+	})
+
+	t.Run("Should execute PPL query", func(t *testing.T) {
+		c := newFakeClient()
+		c.sqlResponses = []*es.SQLResponse{
+			{
+				Status:   200,
+				Schema:   []es.SQLColumn{{Name: "host", Type: "string"}, {Name: "count()", Type: "integer"}},
+				DataRows: [][]any{{"server-1", float64(3)}},
+			},
+		}
+		res, err := executeElasticsearchDataQuery(c, `{
+			"queryLanguage": "ppl",
+			"query": "source=$__index | where @timestamp >= $__timeFrom() | stats count() by host"
+		}`, from, to)
+		require.NoError(t, err)
+		require.Len(t, c.sqlRequests, 1)
+		require.Equal(t, es.SQLLanguagePPL, c.sqlRequests[0].Language)
+		require.Equal(t, "source=logs-* | where @timestamp >= '2018-05-15 17:50:00' | stats count() by host", c.sqlRequests[0].Query)
+		require.Zero(t, c.sqlRequests[0].FetchSize)
+
+		frame := res.Responses[""].Frames[0]
+		requireFrameLength(t, frame, 1)
+		requireStringAt(t, "server-1", frame.Fields[0], 0)
+		requireFloatAt(t, 3, frame.Fields[1], 0)
+	})
+
+	t.Run("Should return error of the response", func(t *testing.T) {
+		c := newFakeClient()
+		c.sqlResponses = []*es.SQLResponse{
+			{
+				Status: 400,
+				Error:  map[string]any{"reason": "Invalid Query", "details": "field [foo] not found"},
+			},
+		}
+		res, err := executeElasticsearchDataQuery(c, `{
+			"queryLanguage": "ppl",
+			"query": "source=logs-* | fields foo"
+		}`, from, to)
+		require.NoError(t, err)
+		require.EqualError(t, res.Responses[""].Error, "Invalid Query: field [foo] not found")
+	})
+
+	t.Run("Should reject PPL queries reading other indices", func(t *testing.T) {
+		for _, query := range []string{"source=secrets | fields foo", "source=logs-*,secrets", "describe secrets"} {
+			c := newFakeClient()
+			res, err := executeElasticsearchDataQuery(c, `{"queryLanguage": "ppl", "query": "`+query+`"}`, from, to)
+			require.NoError(t, err)
+			require.ErrorContains(t, res.Responses[""].Error, "$__index", query)
+			require.Empty(t, c.sqlRequests)
+		}
+	})
+
+	t.Run("Should execute SQL and DSL queries of the same request", func(t *testing.T) {
+		c := newFakeClient()
+		c.multiSearchResponse = &es.MultiSearchResponse{Responses: []*es.SearchResponse{{}}}
+		query := newElasticsearchDataQuery(context.Background(), c, []backend.DataQuery{
+			{RefID: "A", TimeRange: backend.TimeRange{From: from, To: to}, JSON: []byte(`{
+				"bucketAggs": [{ "type": "date_histogram", "field": "@timestamp", "id": "2" }],
+				"metrics": [{"type": "count", "id": "1" }]
+			}`)},
+			{RefID: "B", TimeRange: backend.TimeRange{From: from, To: to}, JSON: []byte(`{
+				"queryLanguage": "sql",
+				"query": "SELECT 1"
+			}`)},
+		}, log.New("test.logger"), tracing.InitializeTracerForTest())
+		res, err := query.execute()
+		require.NoError(t, err)
+		require.Len(t, c.multisearchRequests, 1)
+		require.Len(t, c.multisearchRequests[0].Requests, 1)
+		require.Len(t, c.sqlRequests, 1)
+		require.Contains(t, res.Responses, "A")
+		require.Contains(t, res.Responses, "B")
+	})
+}
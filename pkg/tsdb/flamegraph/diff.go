@@ -0,0 +1,151 @@
+// Package flamegraph builds the flame graph frames shared by the profiling
+// data sources.
+package flamegraph
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Node is a node of a profile tree, the data sources convert their profiles to
+// this tree to build diff frames.
+type Node struct {
+	Name     string
+	Value    int64
+	Self     int64
+	Children []*Node
+}
+
+// DiffNode is a node of the merged tree of two profiles. Value and Self are the
+// values of the baseline profile, ValueRight and SelfRight of the comparison.
+type DiffNode struct {
+	Name       string
+	Value      int64
+	Self       int64
+	ValueRight int64
+	SelfRight  int64
+	Children   []*DiffNode
+
+	childIndex map[string]*DiffNode
+}
+
+// Diff merges the trees of the baseline and comparison profiles. Nodes are
+// matched by their stack, nodes only in one of the profiles have zero values in
+// the other. Either tree can be nil if the profile is empty.
+func Diff(baseline, comparison *Node) *DiffNode {
+	if baseline == nil && comparison == nil {
+		return nil
+	}
+	root := &DiffNode{}
+	if baseline != nil {
+		root.Name = baseline.Name
+		root.add(baseline, false)
+	}
+	if comparison != nil {
+		if root.Name == "" {
+			root.Name = comparison.Name
+		}
+		root.add(comparison, true)
+	}
+	return root
+}
+
+func (d *DiffNode) add(n *Node, right bool) {
+	if right {
+		d.ValueRight += n.Value
+		d.SelfRight += n.Self
+	} else {
+		d.Value += n.Value
+		d.Self += n.Self
+	}
+	for _, c := range n.Children {
+		d.child(c.Name).add(c, right)
+	}
+}
+
+// child returns the child named name, creating it if needed. Siblings with the
+// same name are merged.
+func (d *DiffNode) child(name string) *DiffNode {
+	if d.childIndex == nil {
+		d.childIndex = map[string]*DiffNode{}
+	}
+	if c, ok := d.childIndex[name]; ok {
+		return c
+	}
+	c := &DiffNode{Name: name}
+	d.childIndex[name] = c
+	d.Children = append(d.Children, c)
+	return c
+}
+
+// walk calls fn for each node of the tree depth first, with the level of the node.
+func (d *DiffNode) walk(fn func(n *DiffNode, level int64, ancestors []string)) {
+	var visit func(n *DiffNode, level int64, ancestors []string)
+	visit = func(n *DiffNode, level int64, ancestors []string) {
+		fn(n, level, ancestors)
+		ancestors = append(ancestors, n.Name)
+		for _, c := range n.Children {
+			visit(c, level+1, ancestors)
+		}
+	}
+	visit(d, 0, nil)
+}
+
+// DiffFrame returns the diff flame graph frame of the merged tree in the nested
+// set format of flame graph frames, with the valueRight and selfRight fields of
+// the comparison profile.
+func DiffFrame(tree *DiffNode, unit string) *data.Frame {
+	frame := data.NewFrame("response")
+	frame.Meta = &data.FrameMeta{PreferredVisualization: "flamegraph"}
+
+	levelField := data.NewField("level", nil, []int64{})
+	valueField := data.NewField("value", nil, []int64{})
+	selfField := data.NewField("self", nil, []int64{})
+	labelField := data.NewField("label", nil, []string{})
+	valueRightField := data.NewField("valueRight", nil, []int64{})
+	selfRightField := data.NewField("selfRight", nil, []int64{})
+	for _, f := range []*data.Field{valueField, selfField, valueRightField, selfRightField} {
+		f.Config = &data.FieldConfig{Unit: unit}
+	}
+	frame.Fields = data.Fields{levelField, valueField, selfField, labelField, valueRightField, selfRightField}
+
+	// Tree can be nil if both profiles were empty, we can still send empty frame in that case
+	if tree != nil {
+		tree.walk(func(n *DiffNode, level int64, _ []string) {
+			levelField.Append(level)
+			valueField.Append(n.Value)
+			selfField.Append(n.Self)
+			labelField.Append(n.Name)
+			valueRightField.Append(n.ValueRight)
+			selfRightField.Append(n.SelfRight)
+		})
+	}
+	return frame
+}
+
+// SelectionTimeRange returns the time range of a profile selection. The time
+// range of the query is shifted back by timeShift, from and to are unix
+// milliseconds overriding the start and end.
+func SelectionTimeRange(tr backend.TimeRange, timeShift string, from, to *int64) (backend.TimeRange, error) {
+	if timeShift != "" {
+		shift, err := gtime.ParseDuration(timeShift)
+		if err != nil {
+			return tr, fmt.Errorf("invalid time shift %q: %w", timeShift, err)
+		}
+		tr = backend.TimeRange{From: tr.From.Add(-shift), To: tr.To.Add(-shift)}
+	}
+	if from != nil {
+		tr.From = time.UnixMilli(*from)
+	}
+	if to != nil {
+		tr.To = time.UnixMilli(*to)
+	}
+	if !tr.From.Before(tr.To) {
+		return tr, fmt.Errorf("invalid profile selection: start %s is not before end %s", tr.From, tr.To)
+	}
+	return tr, nil
+}
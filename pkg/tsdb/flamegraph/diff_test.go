@@ -0,0 +1,108 @@
+package flamegraph
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func fieldValues[T any](field *data.Field) []T {
+	values := make([]T, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		values[i] = field.At(i).(T)
+	}
+	return values
+}
+
+func TestDiffFrame(t *testing.T) {
+	baseline := &Node{Name: "total", Value: 100, Children: []*Node{
+		{Name: "main", Value: 100, Children: []*Node{
+			{Name: "parse", Value: 60, Self: 60},
+			{Name: "render", Value: 40, Self: 40},
+		}},
+	}}
+	comparison := &Node{Name: "total", Value: 120, Children: []*Node{
+		{Name: "main", Value: 120, Children: []*Node{
+			{Name: "render", Value: 30, Self: 30},
+			{Name: "compress", Value: 90, Self: 90},
+		}},
+	}}
+
+	frame := DiffFrame(Diff(baseline, comparison), "ns")
+	require.Equal(t, "flamegraph", string(frame.Meta.PreferredVisualization))
+	require.Equal(t, []int64{0, 1, 2, 2, 2}, fieldValues[int64](frame.Fields[0]))
+	require.Equal(t, []string{"total", "main", "parse", "render", "compress"}, fieldValues[string](frame.Fields[3]))
+	require.Equal(t, []int64{100, 100, 60, 40, 0}, fieldValues[int64](frame.Fields[1]))
+	require.Equal(t, []int64{0, 0, 60, 40, 0}, fieldValues[int64](frame.Fields[2]))
+	require.Equal(t, "valueRight", frame.Fields[4].Name)
+	require.Equal(t, []int64{120, 120, 0, 30, 90}, fieldValues[int64](frame.Fields[4]))
+	require.Equal(t, "selfRight", frame.Fields[5].Name)
+	require.Equal(t, []int64{0, 0, 0, 30, 90}, fieldValues[int64](frame.Fields[5]))
+	require.Equal(t, "ns", frame.Fields[1].Config.Unit)
+
+	t.Run("empty profiles", func(t *testing.T) {
+		frame := DiffFrame(Diff(nil, nil), "ns")
+		require.Equal(t, 0, frame.Rows())
+
+		frame = DiffFrame(Diff(nil, comparison), "ns")
+		require.Equal(t, []int64{0, 0, 0, 0}, fieldValues[int64](frame.Fields[1]))
+		require.Equal(t, []int64{120, 120, 30, 90}, fieldValues[int64](frame.Fields[4]))
+	})
+}
+
+func TestTopTableFrame(t *testing.T) {
+	baseline := &Node{Name: "total", Value: 100, Children: []*Node{
+		{Name: "walk", Value: 100, Self: 10, Children: []*Node{
+			{Name: "walk", Value: 90, Self: 50, Children: []*Node{
+				{Name: "alloc", Value: 40, Self: 40},
+			}},
+		}},
+	}}
+	comparison := &Node{Name: "total", Value: 110, Children: []*Node{
+		{Name: "walk", Value: 110, Self: 10, Children: []*Node{
+			{Name: "walk", Value: 100, Self: 90, Children: []*Node{
+				{Name: "alloc", Value: 10, Self: 10},
+			}},
+		}},
+	}}
+
+	frame := TopTableFrame(Diff(baseline, comparison), 2, "bytes")
+	require.Equal(t, 2, frame.Rows())
+	require.Equal(t, []string{"walk", "alloc"}, fieldValues[string](frame.Fields[0]))
+	require.Equal(t, []int64{60, 40}, fieldValues[int64](frame.Fields[1]))
+	require.Equal(t, []int64{100, 10}, fieldValues[int64](frame.Fields[2]))
+	require.Equal(t, []int64{40, -30}, fieldValues[int64](frame.Fields[3]))
+	// the total of the recursive function is counted once
+	require.Equal(t, []int64{100, 40}, fieldValues[int64](frame.Fields[4]))
+	require.Equal(t, []int64{110, 10}, fieldValues[int64](frame.Fields[5]))
+	require.Equal(t, []int64{10, -30}, fieldValues[int64](frame.Fields[6]))
+}
+
+func TestSelectionTimeRange(t *testing.T) {
+	tr := backend.TimeRange{From: time.UnixMilli(100_000_000), To: time.UnixMilli(200_000_000)}
+
+	res, err := SelectionTimeRange(tr, "", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, tr, res)
+
+	res, err = SelectionTimeRange(tr, "1d", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, tr.From.Add(-24*time.Hour), res.From)
+	require.Equal(t, tr.To.Add(-24*time.Hour), res.To)
+
+	from := int64(150_000_000)
+	res, err = SelectionTimeRange(tr, "", &from, nil)
+	require.NoError(t, err)
+	require.Equal(t, time.UnixMilli(from), res.From)
+	require.Equal(t, tr.To, res.To)
+
+	_, err = SelectionTimeRange(tr, "x", nil, nil)
+	require.Error(t, err)
+
+	to := int64(50_000_000)
+	_, err = SelectionTimeRange(tr, "", nil, &to)
+	require.Error(t, err)
+}
@@ -0,0 +1,100 @@
+package flamegraph
+
+import (
+	"sort"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// DefaultTopN is the number of functions in the top table if not set by the query.
+const DefaultTopN = 20
+
+type functionDelta struct {
+	name       string
+	self       int64
+	selfRight  int64
+	total      int64
+	totalRight int64
+}
+
+func (f *functionDelta) selfDelta() int64  { return f.selfRight - f.self }
+func (f *functionDelta) totalDelta() int64 { return f.totalRight - f.total }
+
+// TopTableFrame returns a table of the n functions of the merged tree with the
+// largest changes of self value, with their self and total values in both
+// profiles and the deltas from the baseline to the comparison. The total of a
+// recursive function is only counted once per stack.
+func TopTableFrame(tree *DiffNode, n int, unit string) *data.Frame {
+	functions := map[string]*functionDelta{}
+	if tree != nil {
+		tree.walk(func(node *DiffNode, _ int64, ancestors []string) {
+			f, ok := functions[node.Name]
+			if !ok {
+				f = &functionDelta{name: node.Name}
+				functions[node.Name] = f
+			}
+			f.self += node.Self
+			f.selfRight += node.SelfRight
+			for _, a := range ancestors {
+				if a == node.Name {
+					return
+				}
+			}
+			f.total += node.Value
+			f.totalRight += node.ValueRight
+		})
+	}
+
+	sorted := make([]*functionDelta, 0, len(functions))
+	for _, f := range functions {
+		sorted = append(sorted, f)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if abs(a.selfDelta()) != abs(b.selfDelta()) {
+			return abs(a.selfDelta()) > abs(b.selfDelta())
+		}
+		if abs(a.totalDelta()) != abs(b.totalDelta()) {
+			return abs(a.totalDelta()) > abs(b.totalDelta())
+		}
+		return a.name < b.name
+	})
+	if n <= 0 {
+		n = DefaultTopN
+	}
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+
+	frame := data.NewFrame("top")
+	frame.Meta = &data.FrameMeta{PreferredVisualization: "table"}
+	labelField := data.NewField("label", nil, []string{})
+	selfField := data.NewField("self", nil, []int64{})
+	selfRightField := data.NewField("selfRight", nil, []int64{})
+	selfDeltaField := data.NewField("selfDelta", nil, []int64{})
+	totalField := data.NewField("total", nil, []int64{})
+	totalRightField := data.NewField("totalRight", nil, []int64{})
+	totalDeltaField := data.NewField("totalDelta", nil, []int64{})
+	for _, f := range []*data.Field{selfField, selfRightField, selfDeltaField, totalField, totalRightField, totalDeltaField} {
+		f.Config = &data.FieldConfig{Unit: unit}
+	}
+	frame.Fields = data.Fields{labelField, selfField, selfRightField, selfDeltaField, totalField, totalRightField, totalDeltaField}
+
+	for _, f := range sorted {
+		labelField.Append(f.name)
+		selfField.Append(f.self)
+		selfRightField.Append(f.selfRight)
+		selfDeltaField.Append(f.selfDelta())
+		totalField.Append(f.total)
+		totalRightField.Append(f.totalRight)
+		totalDeltaField.Append(f.totalDelta())
+	}
+	return frame
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
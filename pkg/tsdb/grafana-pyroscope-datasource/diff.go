@@ -0,0 +1,109 @@
+package pyroscope
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/tsdb/flamegraph"
+	"github.com/grafana/grafana/pkg/tsdb/grafana-pyroscope-datasource/kinds/dataquery"
+)
+
+const queryTypeDiff = string(dataquery.PyroscopeQueryTypeDiff)
+
+// queryDiff queries the baseline and comparison profiles of a diff query and returns the diff flame graph frame and
+// the top table of the functions with the largest changes.
+func (d *PyroscopeDatasource) queryDiff(ctx context.Context, qm queryModel, query backend.DataQuery) (data.Frames, error) {
+	var baseline, comparison *dataquery.ProfileSelection
+	topN := flamegraph.DefaultTopN
+	if qm.Diff != nil {
+		baseline, comparison = qm.Diff.Baseline, qm.Diff.Comparison
+		if qm.Diff.TopN != nil {
+			topN = int(*qm.Diff.TopN)
+		}
+	}
+
+	var baselineResp, comparisonResp *ProfileResponse
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		resp, err := d.selectionProfile(gCtx, qm, query, baseline)
+		if err != nil {
+			return fmt.Errorf("baseline profile: %w", err)
+		}
+		baselineResp = resp
+		return nil
+	})
+	g.Go(func() error {
+		resp, err := d.selectionProfile(gCtx, qm, query, comparison)
+		if err != nil {
+			return fmt.Errorf("comparison profile: %w", err)
+		}
+		comparisonResp = resp
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	unit := ""
+	for _, resp := range []*ProfileResponse{baselineResp, comparisonResp} {
+		if resp != nil {
+			unit = resp.Units
+			break
+		}
+	}
+
+	tree := flamegraph.Diff(profileToNode(baselineResp), profileToNode(comparisonResp))
+	return data.Frames{
+		flamegraph.DiffFrame(tree, unit),
+		flamegraph.TopTableFrame(tree, topN, unit),
+	}, nil
+}
+
+// selectionProfile returns the profile of a side of a diff query. The label selector and time range default to the ones
+// of the query.
+func (d *PyroscopeDatasource) selectionProfile(ctx context.Context, qm queryModel, query backend.DataQuery, selection *dataquery.ProfileSelection) (*ProfileResponse, error) {
+	labelSelector := qm.LabelSelector
+	tr := query.TimeRange
+	if selection != nil {
+		if selection.LabelSelector != nil && *selection.LabelSelector != "" {
+			labelSelector = *selection.LabelSelector
+		}
+		timeShift := ""
+		if selection.TimeShift != nil {
+			timeShift = *selection.TimeShift
+		}
+		var err error
+		tr, err = flamegraph.SelectionTimeRange(tr, timeShift, selection.From, selection.To)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Debug("Calling GetProfile for diff", "labelSelector", labelSelector, "from", tr.From, "to", tr.To, "function", logEntrypoint())
+	if len(qm.SpanSelector) > 0 {
+		return d.client.GetSpanProfile(ctx, qm.ProfileTypeId, labelSelector, qm.SpanSelector, tr.From.UnixMilli(), tr.To.UnixMilli(), qm.MaxNodes)
+	}
+	return d.client.GetProfile(ctx, qm.ProfileTypeId, labelSelector, tr.From.UnixMilli(), tr.To.UnixMilli(), qm.MaxNodes)
+}
+
+func profileToNode(resp *ProfileResponse) *flamegraph.Node {
+	if resp == nil || resp.Flamebearer == nil {
+		return nil
+	}
+	return treeToNode(levelsToTree(resp.Flamebearer.Levels, resp.Flamebearer.Names))
+}
+
+func treeToNode(tree *ProfileTree) *flamegraph.Node {
+	if tree == nil {
+		return nil
+	}
+	node := &flamegraph.Node{Name: tree.Name, Value: tree.Value, Self: tree.Self}
+	for _, child := range tree.Nodes {
+		node.Children = append(node.Children, treeToNode(child))
+	}
+	return node
+}
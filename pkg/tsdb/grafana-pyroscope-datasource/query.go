@@ -52,6 +52,19 @@ func (d *PyroscopeDatasource) query(ctx context.Context, pCtx backend.PluginCont
 		return response
 	}
 
+	if query.QueryType == queryTypeDiff {
+		frames, err := d.queryDiff(ctx, qm, query)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			logger.Error("Querying diff", "err", err, "function", logEntrypoint())
+			response.Error = err
+			return response
+		}
+		response.Frames = frames
+		return response
+	}
+
 	responseMutex := sync.Mutex{}
 	g, gCtx := errgroup.WithContext(ctx)
 	if query.QueryType == queryTypeMetrics || query.QueryType == queryTypeBoth {
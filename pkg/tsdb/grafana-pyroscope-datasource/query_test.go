@@ -56,6 +56,28 @@ func Test_query(t *testing.T) {
 		require.Equal(t, "time", resp.Frames[0].Fields[0].Name)
 	})
 
+	t.Run("query diff", func(t *testing.T) {
+		dataQuery := makeDataQuery()
+		dataQuery.QueryType = queryTypeDiff
+		dataQuery.JSON = []byte(`{"profileTypeId":"memory:alloc_objects:count:space:bytes","labelSelector":"{app=\\\"baz\\\"}","diff":{"baseline":{"timeShift":"1h"},"topN":2}}`)
+		resp := ds.query(context.Background(), pCtx, *dataQuery)
+		require.Nil(t, resp.Error)
+		require.Equal(t, 2, len(resp.Frames))
+		require.Equal(t, data.NewField("level", nil, []int64{0, 1, 2}), resp.Frames[0].Fields[0])
+		require.Equal(t, "valueRight", resp.Frames[0].Fields[4].Name)
+		require.Equal(t, []int64{10, 9, 8}, fieldValues[int64](resp.Frames[0].Fields[4]))
+		require.Equal(t, "top", resp.Frames[1].Name)
+		require.Equal(t, 2, resp.Frames[1].Rows())
+	})
+
+	t.Run("query diff with invalid time shift", func(t *testing.T) {
+		dataQuery := makeDataQuery()
+		dataQuery.QueryType = queryTypeDiff
+		dataQuery.JSON = []byte(`{"profileTypeId":"memory:alloc_objects:count:space:bytes","diff":{"comparison":{"timeShift":"soon"}}}`)
+		resp := ds.query(context.Background(), pCtx, *dataQuery)
+		require.Error(t, resp.Error)
+	})
+
 	t.Run("query metrics uses min step", func(t *testing.T) {
 		dataQuery := makeDataQuery()
 		dataQuery.QueryType = queryTypeMetrics
@@ -0,0 +1,204 @@
+package testdatasource
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+const (
+	faultStepData         = "data"
+	faultStepTimeout      = "timeout"
+	faultStepError        = "error"
+	faultStepPartialError = "partial_error"
+	faultStepEmpty        = "empty"
+	faultStepNoFrames     = "no_frames"
+	faultStepLabelChurn   = "label_churn"
+
+	defaultFaultStepDuration = time.Minute
+	defaultFaultTimeout      = 30 * time.Second
+	// maxFaultPoints limits the number of points of the series returned by a step
+	maxFaultPoints = 10000
+)
+
+type faultInjectionQuery struct {
+	// Start is the time the script starts, in RFC3339 format. Without start,
+	// the script repeats from the epoch.
+	Start string      `json:"start"`
+	Steps []faultStep `json:"steps"`
+}
+
+type faultStep struct {
+	Type     string  `json:"type"`
+	Duration string  `json:"duration"`
+	Value    float64 `json:"value"`
+	Message  string  `json:"message"`
+	Timeout  string  `json:"timeout"`
+}
+
+// currentFaultStep returns the index of the step of the script at time t
+func currentFaultStep(script faultInjectionQuery, t time.Time) (int, error) {
+	if len(script.Steps) == 0 {
+		return 0, fmt.Errorf("fault injection script has no steps")
+	}
+
+	durations := make([]time.Duration, len(script.Steps))
+	total := time.Duration(0)
+	for i, step := range script.Steps {
+		durations[i] = defaultFaultStepDuration
+		if step.Duration != "" {
+			d, err := time.ParseDuration(step.Duration)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration of step %d: %w", i, err)
+			}
+			if d <= 0 {
+				return 0, fmt.Errorf("invalid duration of step %d: must be positive", i)
+			}
+			durations[i] = d
+		}
+		total += durations[i]
+	}
+
+	var position time.Duration
+	if script.Start == "" {
+		// the script repeats, based on absolute time to be predictable
+		position = time.Duration(t.UnixNano() % int64(total))
+	} else {
+		start, err := time.Parse(time.RFC3339, script.Start)
+		if err != nil {
+			return 0, fmt.Errorf("invalid start of script: %w", err)
+		}
+		position = t.Sub(start)
+		if position < 0 {
+			return 0, nil
+		}
+		if position >= total {
+			// the last step lasts once the script has ended
+			return len(script.Steps) - 1, nil
+		}
+	}
+
+	for i, d := range durations {
+		if position < d {
+			return i, nil
+		}
+		position -= d
+	}
+	return len(script.Steps) - 1, nil
+}
+
+func (s *Service) handleFaultInjectionScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := GetJSONModel(q.JSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse query json: %v", err)
+		}
+
+		index, err := currentFaultStep(model.Faults, q.TimeRange.To)
+		if err != nil {
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, err.Error())
+			continue
+		}
+		step := model.Faults.Steps[index]
+		executed := fmt.Sprintf("step %d: %s", index, step.Type)
+
+		respD := backend.DataResponse{}
+		switch step.Type {
+		case faultStepData, "":
+			respD.Frames = faultSeries(q, model, step, nil)
+		case faultStepTimeout:
+			timeout := defaultFaultTimeout
+			if step.Timeout != "" {
+				if timeout, err = time.ParseDuration(step.Timeout); err != nil {
+					respD = backend.ErrDataResponse(backend.StatusBadRequest, fmt.Sprintf("invalid timeout of step %d: %v", index, err))
+					break
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(timeout):
+			}
+			respD = backend.ErrDataResponse(backend.StatusTimeout, faultMessage(step, "query timed out"))
+		case faultStepError:
+			respD = backend.ErrDataResponse(backend.StatusInternal, faultMessage(step, "query failed"))
+		case faultStepPartialError:
+			// only the first half of the series is returned along with the error
+			frames := faultSeries(q, model, step, nil)
+			respD = backend.ErrDataResponse(backend.StatusInternal, faultMessage(step, "query partially failed"))
+			respD.Frames = frames[:(len(frames)+1)/2]
+		case faultStepEmpty:
+			for i, frame := range faultSeries(q, model, step, nil) {
+				respD.Frames = append(respD.Frames, data.NewFrame(frame.Name,
+					data.NewField(data.TimeSeriesTimeFieldName, nil, []time.Time{}),
+					data.NewField(data.TimeSeriesValueFieldName, parseLabels(model, i), []float64{}),
+				))
+			}
+		case faultStepNoFrames:
+			respD.Frames = data.Frames{}
+		case faultStepLabelChurn:
+			// every evaluation returns series with a new label set
+			respD.Frames = faultSeries(q, model, step, data.Labels{
+				"churn": strconv.FormatInt(q.TimeRange.To.Unix(), 10),
+			})
+		default:
+			respD = backend.ErrDataResponse(backend.StatusBadRequest, fmt.Sprintf("unknown type of step %d: %s", index, step.Type))
+		}
+
+		for _, frame := range respD.Frames {
+			frame.SetMeta(&data.FrameMeta{ExecutedQueryString: executed})
+		}
+		resp.Responses[q.RefID] = respD
+	}
+
+	return resp, nil
+}
+
+func faultMessage(step faultStep, defaultMessage string) string {
+	if step.Message != "" {
+		return step.Message
+	}
+	return defaultMessage
+}
+
+// faultSeries returns a series with the value of the step for each series of
+// the query, with a point every interval of the query
+func faultSeries(q backend.DataQuery, model JSONModel, step faultStep, extraLabels data.Labels) data.Frames {
+	interval := q.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if span := q.TimeRange.To.Sub(q.TimeRange.From); span/interval > maxFaultPoints {
+		interval = span / maxFaultPoints
+	}
+
+	frames := data.Frames{}
+	for i := 0; i < model.SeriesCount; i++ {
+		labels := parseLabels(model, i)
+		for k, v := range extraLabels {
+			labels[k] = v
+		}
+
+		timeVec := make([]time.Time, 0)
+		floatVec := make([]float64, 0)
+		for t := q.TimeRange.From.Truncate(interval); !t.After(q.TimeRange.To); t = t.Add(interval) {
+			if t.Before(q.TimeRange.From) {
+				continue
+			}
+			timeVec = append(timeVec, t)
+			floatVec = append(floatVec, step.Value)
+		}
+
+		frames = append(frames, data.NewFrame(frameNameForQuery(q, model, i),
+			data.NewField(data.TimeSeriesTimeFieldName, nil, timeVec),
+			data.NewField(data.TimeSeriesValueFieldName, labels, floatVec),
+		))
+	}
+	return frames
+}
@@ -0,0 +1,161 @@
+package testdatasource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrentFaultStep(t *testing.T) {
+	script := faultInjectionQuery{
+		Steps: []faultStep{
+			{Type: faultStepData, Duration: "1m"},
+			{Type: faultStepError, Duration: "2m"},
+			{Type: faultStepEmpty},
+		},
+	}
+	epoch := time.Unix(0, 0)
+
+	t.Run("repeats from the epoch without start", func(t *testing.T) {
+		for _, tc := range []struct {
+			t        time.Time
+			expected int
+		}{
+			{epoch, 0},
+			{epoch.Add(59 * time.Second), 0},
+			{epoch.Add(time.Minute), 1},
+			{epoch.Add(3 * time.Minute), 2},
+			{epoch.Add(4 * time.Minute), 0},
+			{epoch.Add(1000*4*time.Minute + 90*time.Second), 1},
+		} {
+			index, err := currentFaultStep(script, tc.t)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, index, tc.t)
+		}
+	})
+
+	t.Run("runs once with start", func(t *testing.T) {
+		start := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+		script := script
+		script.Start = start.Format(time.RFC3339)
+		for _, tc := range []struct {
+			t        time.Time
+			expected int
+		}{
+			{start.Add(-time.Hour), 0},
+			{start.Add(time.Minute), 1},
+			{start.Add(3 * time.Minute), 2},
+			{start.Add(time.Hour), 2},
+		} {
+			index, err := currentFaultStep(script, tc.t)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, index, tc.t)
+		}
+	})
+
+	t.Run("fails with an invalid script", func(t *testing.T) {
+		_, err := currentFaultStep(faultInjectionQuery{}, epoch)
+		require.Error(t, err)
+		_, err = currentFaultStep(faultInjectionQuery{Steps: []faultStep{{Duration: "-1m"}}}, epoch)
+		require.Error(t, err)
+		_, err = currentFaultStep(faultInjectionQuery{Start: "now", Steps: []faultStep{{}}}, epoch)
+		require.Error(t, err)
+	})
+}
+
+func TestFaultInjectionScenario(t *testing.T) {
+	s := ProvideService()
+	start := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	model := `{
+		"scenarioId": "fault_injection",
+		"seriesCount": 2,
+		"labels": "instance=server-$seriesIndex",
+		"faults": {
+			"start": "2023-06-01T12:00:00Z",
+			"steps": [
+				{ "type": "data", "value": 5 },
+				{ "type": "timeout", "timeout": "10ms", "message": "deadline exceeded" },
+				{ "type": "partial_error" },
+				{ "type": "empty" },
+				{ "type": "no_frames" },
+				{ "type": "label_churn" }
+			]
+		}
+	}`
+	query := func(to time.Time) backend.DataResponse {
+		resp, err := s.handleFaultInjectionScenario(context.Background(), &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				QueryType: string(faultInjectionQueryType),
+				Interval:  time.Minute,
+				TimeRange: backend.TimeRange{From: to.Add(-5 * time.Minute), To: to},
+				JSON:      []byte(model),
+			}},
+		})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+
+	t.Run("data", func(t *testing.T) {
+		res := query(start)
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 2)
+		require.Equal(t, 6, res.Frames[0].Rows())
+		require.Equal(t, 5.0, res.Frames[0].Fields[1].At(0))
+		require.Equal(t, "server-1", res.Frames[1].Fields[1].Labels["instance"])
+		require.Equal(t, "step 0: data", res.Frames[0].Meta.ExecutedQueryString)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		res := query(start.Add(time.Minute))
+		require.EqualError(t, res.Error, "deadline exceeded")
+		require.Equal(t, backend.StatusTimeout, res.Status)
+		require.Empty(t, res.Frames)
+	})
+
+	t.Run("timeout is canceled with the request", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := s.handleFaultInjectionScenario(ctx, &backend.QueryDataRequest{
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				TimeRange: backend.TimeRange{From: start, To: start.Add(time.Minute)},
+				JSON:      []byte(`{"faults":{"steps":[{"type":"timeout","timeout":"1h"}]}}`),
+			}},
+		})
+		require.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("partial error", func(t *testing.T) {
+		res := query(start.Add(2 * time.Minute))
+		require.Error(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		require.Equal(t, 6, res.Frames[0].Rows())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		res := query(start.Add(3 * time.Minute))
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 2)
+		require.Equal(t, 0, res.Frames[0].Rows())
+	})
+
+	t.Run("no frames", func(t *testing.T) {
+		res := query(start.Add(4 * time.Minute))
+		require.NoError(t, res.Error)
+		require.Empty(t, res.Frames)
+	})
+
+	t.Run("label churn", func(t *testing.T) {
+		first := query(start.Add(5 * time.Minute))
+		second := query(start.Add(5*time.Minute + 10*time.Second))
+		require.NoError(t, first.Error)
+		firstLabels := first.Frames[0].Fields[1].Labels
+		secondLabels := second.Frames[0].Fields[1].Labels
+		require.Equal(t, "server-0", firstLabels["instance"])
+		require.NotEqual(t, firstLabels["churn"], secondLabels["churn"])
+	})
+}
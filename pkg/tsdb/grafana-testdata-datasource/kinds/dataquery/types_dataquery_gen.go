@@ -9,6 +9,17 @@
 
 package dataquery
 
+// Defines values for FaultStepType.
+const (
+	FaultStepTypeData         FaultStepType = "data"
+	FaultStepTypeEmpty        FaultStepType = "empty"
+	FaultStepTypeError        FaultStepType = "error"
+	FaultStepTypeLabelChurn   FaultStepType = "label_churn"
+	FaultStepTypeNoFrames     FaultStepType = "no_frames"
+	FaultStepTypePartialError FaultStepType = "partial_error"
+	FaultStepTypeTimeout      FaultStepType = "timeout"
+)
+
 // Defines values for NodesQueryType.
 const (
 	NodesQueryTypeRandom      NodesQueryType = "random"
@@ -39,6 +50,7 @@ const (
 	TestDataQueryTypeCsvMetricValues              TestDataQueryType = "csv_metric_values"
 	TestDataQueryTypeDatapointsOutsideRange       TestDataQueryType = "datapoints_outside_range"
 	TestDataQueryTypeExponentialHeatmapBucketData TestDataQueryType = "exponential_heatmap_bucket_data"
+	TestDataQueryTypeFaultInjection               TestDataQueryType = "fault_injection"
 	TestDataQueryTypeFlameGraph                   TestDataQueryType = "flame_graph"
 	TestDataQueryTypeGrafanaApi                   TestDataQueryType = "grafana_api"
 	TestDataQueryTypeLinearHeatmapBucketData      TestDataQueryType = "linear_heatmap_bucket_data"
@@ -53,6 +65,7 @@ const (
 	TestDataQueryTypeRandomWalkTable              TestDataQueryType = "random_walk_table"
 	TestDataQueryTypeRandomWalkWithError          TestDataQueryType = "random_walk_with_error"
 	TestDataQueryTypeRawFrame                     TestDataQueryType = "raw_frame"
+	TestDataQueryTypeReplay                       TestDataQueryType = "replay"
 	TestDataQueryTypeServerError500               TestDataQueryType = "server_error_500"
 	TestDataQueryTypeSimulation                   TestDataQueryType = "simulation"
 	TestDataQueryTypeSlowQuery                    TestDataQueryType = "slow_query"
@@ -96,6 +109,32 @@ type DataQuery struct {
 	RefId string `json:"refId"`
 }
 
+// FaultInjectionQuery defines model for FaultInjectionQuery.
+type FaultInjectionQuery struct {
+	// Start of the script in RFC3339 format, the script repeats from the epoch when empty
+	Start *string     `json:"start,omitempty"`
+	Steps []FaultStep `json:"steps,omitempty"`
+}
+
+// FaultStep defines model for FaultStep.
+type FaultStep struct {
+	// Duration of the step, defaults to 1m
+	Duration *string `json:"duration,omitempty"`
+
+	// Error message of the step
+	Message *string `json:"message,omitempty"`
+
+	// Time to wait before a timeout step fails, defaults to 30s
+	Timeout *string       `json:"timeout,omitempty"`
+	Type    FaultStepType `json:"type"`
+
+	// Value of the series returned by the step
+	Value *float64 `json:"value,omitempty"`
+}
+
+// FaultStepType defines model for FaultStep.Type.
+type FaultStepType string
+
 // NodesQuery defines model for NodesQuery.
 type NodesQuery struct {
 	Count *int64          `json:"count,omitempty"`
@@ -114,6 +153,18 @@ type PulseWaveQuery struct {
 	TimeStep *int64   `json:"timeStep,omitempty"`
 }
 
+// ReplayQuery defines model for ReplayQuery.
+type ReplayQuery struct {
+	// UID of the data source of the recorded query
+	DatasourceUid string `json:"datasourceUid"`
+
+	// Hash of the recorded query, the most recent recording of the refId is used when empty
+	QueryHash *string `json:"queryHash,omitempty"`
+
+	// RefID of the recorded query, defaults to the refId of the query
+	RefId *string `json:"refId,omitempty"`
+}
+
 // TODO: Should this live here given it's not used in the dataquery?
 type Scenario struct {
 	Description    *string `json:"description,omitempty"`
@@ -161,23 +212,25 @@ type TestDataDataQuery struct {
 	CsvWave     []CSVWave `json:"csvWave,omitempty"`
 
 	// Drop percentage (the chance we will lose a point 0-100)
-	DropPercent     *float64           `json:"dropPercent,omitempty"`
-	ErrorType       *ErrorType         `json:"errorType,omitempty"`
-	FlamegraphDiff  *bool              `json:"flamegraphDiff,omitempty"`
-	Labels          *string            `json:"labels,omitempty"`
-	LevelColumn     *bool              `json:"levelColumn,omitempty"`
-	Lines           *int64             `json:"lines,omitempty"`
-	Nodes           *NodesQuery        `json:"nodes,omitempty"`
-	Points          [][]any            `json:"points,omitempty"`
-	PulseWave       *PulseWaveQuery    `json:"pulseWave,omitempty"`
-	RawFrameContent *string            `json:"rawFrameContent,omitempty"`
-	ScenarioId      *TestDataQueryType `json:"scenarioId,omitempty"`
-	SeriesCount     *int32             `json:"seriesCount,omitempty"`
-	Sim             *SimulationQuery   `json:"sim,omitempty"`
-	SpanCount       *int32             `json:"spanCount,omitempty"`
-	Stream          *StreamingQuery    `json:"stream,omitempty"`
-	StringInput     *string            `json:"stringInput,omitempty"`
-	Usa             *USAQuery          `json:"usa,omitempty"`
+	DropPercent     *float64             `json:"dropPercent,omitempty"`
+	ErrorType       *ErrorType           `json:"errorType,omitempty"`
+	Faults          *FaultInjectionQuery `json:"faults,omitempty"`
+	FlamegraphDiff  *bool                `json:"flamegraphDiff,omitempty"`
+	Labels          *string              `json:"labels,omitempty"`
+	LevelColumn     *bool                `json:"levelColumn,omitempty"`
+	Lines           *int64               `json:"lines,omitempty"`
+	Nodes           *NodesQuery          `json:"nodes,omitempty"`
+	Points          [][]any              `json:"points,omitempty"`
+	PulseWave       *PulseWaveQuery      `json:"pulseWave,omitempty"`
+	RawFrameContent *string              `json:"rawFrameContent,omitempty"`
+	Replay          *ReplayQuery         `json:"replay,omitempty"`
+	ScenarioId      *TestDataQueryType   `json:"scenarioId,omitempty"`
+	SeriesCount     *int32               `json:"seriesCount,omitempty"`
+	Sim             *SimulationQuery     `json:"sim,omitempty"`
+	SpanCount       *int32               `json:"spanCount,omitempty"`
+	Stream          *StreamingQuery      `json:"stream,omitempty"`
+	StringInput     *string              `json:"stringInput,omitempty"`
+	Usa             *USAQuery            `json:"usa,omitempty"`
 }
 
 // ErrorType defines model for TestDataDataQuery.ErrorType.
@@ -0,0 +1,332 @@
+package testdatasource
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Recording is a data source response recorded by the query service,
+// which is served by the replay scenario.
+type Recording struct {
+	OrgID          int64                     `json:"orgId"`
+	RefID          string                    `json:"refId"`
+	QueryHash      string                    `json:"queryHash"`
+	DatasourceUID  string                    `json:"datasourceUid"`
+	DatasourceType string                    `json:"datasourceType"`
+	Query          json.RawMessage           `json:"query"`
+	From           time.Time                 `json:"from"`
+	To             time.Time                 `json:"to"`
+	RecordedAt     time.Time                 `json:"recordedAt"`
+	Response       backend.QueryDataResponse `json:"response"`
+}
+
+// recordingSummary is a recording without its query and response, as listed
+// by the recordings resource
+type recordingSummary struct {
+	RefID          string    `json:"refId"`
+	QueryHash      string    `json:"queryHash"`
+	DatasourceUID  string    `json:"datasourceUid"`
+	DatasourceType string    `json:"datasourceType"`
+	From           time.Time `json:"from"`
+	To             time.Time `json:"to"`
+	RecordedAt     time.Time `json:"recordedAt"`
+}
+
+var errRecordingNotFound = errors.New("recording not found")
+
+var recordingFileNameRegex = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// properties of a query model which change between requests of the same query
+var volatileQueryProperties = []string{
+	"refId",
+	"datasource",
+	"datasourceId",
+	"intervalMs",
+	"interval",
+	"maxDataPoints",
+	"requestId",
+	"utcOffsetSec",
+	"key",
+}
+
+// QueryHash returns the hash identifying the query of a recording. The hash
+// of a query doesn't change with the time range and the interval.
+func QueryHash(datasourceUID string, query json.RawMessage) string {
+	model := map[string]any{}
+	if err := json.Unmarshal(query, &model); err != nil {
+		model = map[string]any{}
+	}
+	for _, key := range volatileQueryProperties {
+		delete(model, key)
+	}
+	// json.Marshal sorts the keys of maps, so equal models have equal hashes
+	b, _ := json.Marshal(model)
+	sum := sha256.Sum256(append([]byte(datasourceUID+"\n"), b...))
+	return hex.EncodeToString(sum[:8])
+}
+
+// WriteRecording writes a recording to the directory of its organization and
+// data source in root, replacing the previous recording of the same query.
+func WriteRecording(root string, rec *Recording) error {
+	b, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	return WriteEncodedRecording(root, rec, b)
+}
+
+// WriteEncodedRecording writes the JSON encoding b of a recording to the
+// directory of its organization and data source in root. Only the
+// organization, data source, refID and query hash of rec are used.
+func WriteEncodedRecording(root string, rec *Recording, b []byte) error {
+	dir := recordingsDir(root, rec.OrgID, rec.DatasourceUID)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so the replay scenario never reads a partial recording
+	file, err := os.CreateTemp(dir, ".recording-*")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(b); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), filepath.Join(dir, recordingFileName(rec.RefID, rec.QueryHash)))
+}
+
+// recordingsDir returns the directory of the recordings of a data source, so
+// that recordings are only replayed in the organization they were recorded in
+func recordingsDir(root string, orgID int64, datasourceUID string) string {
+	return filepath.Join(orgRecordingsDir(root, orgID), recordingFileNameRegex.ReplaceAllString(datasourceUID, "_"))
+}
+
+func orgRecordingsDir(root string, orgID int64) string {
+	return filepath.Join(root, strconv.FormatInt(orgID, 10))
+}
+
+func recordingFileName(refID, queryHash string) string {
+	return fmt.Sprintf("%s-%s.json", recordingFileNameRegex.ReplaceAllString(refID, "_"), queryHash)
+}
+
+func readRecordingFile(path string) (*Recording, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errRecordingNotFound
+		}
+		return nil, err
+	}
+	rec := &Recording{}
+	if err := json.Unmarshal(b, rec); err != nil {
+		return nil, fmt.Errorf("failed to read recording %s: %w", filepath.Base(path), err)
+	}
+	return rec, nil
+}
+
+// readRecording returns the recording of the query with the given refID and
+// hash. Without hash, the most recent recording of the refID is returned.
+func readRecording(dir, refID, queryHash string) (*Recording, error) {
+	if queryHash != "" {
+		if recordingFileNameRegex.MatchString(queryHash) {
+			return nil, errRecordingNotFound
+		}
+		return readRecordingFile(filepath.Join(dir, recordingFileName(refID, queryHash)))
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, recordingFileName(refID, "*")))
+	if err != nil {
+		return nil, err
+	}
+	var latest *Recording
+	for _, path := range paths {
+		rec, err := readRecordingFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if rec.RefID == refID && (latest == nil || rec.RecordedAt.After(latest.RecordedAt)) {
+			latest = rec
+		}
+	}
+	if latest == nil {
+		return nil, errRecordingNotFound
+	}
+	return latest, nil
+}
+
+// listRecordings returns the summaries of the recordings of the data sources
+// of an organization
+func listRecordings(root string, orgID int64) ([]recordingSummary, error) {
+	paths, err := filepath.Glob(filepath.Join(orgRecordingsDir(root, orgID), "*", "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]recordingSummary, 0, len(paths))
+	for _, path := range paths {
+		rec, err := readRecordingFile(path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, recordingSummary{
+			RefID:          rec.RefID,
+			QueryHash:      rec.QueryHash,
+			DatasourceUID:  rec.DatasourceUID,
+			DatasourceType: rec.DatasourceType,
+			From:           rec.From,
+			To:             rec.To,
+			RecordedAt:     rec.RecordedAt,
+		})
+	}
+	return result, nil
+}
+
+// shiftFrames moves the values of the time fields of the frames by offset
+func shiftFrames(frames data.Frames, offset time.Duration) {
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			switch field.Type() {
+			case data.FieldTypeTime:
+				for i := 0; i < field.Len(); i++ {
+					field.Set(i, field.At(i).(time.Time).Add(offset))
+				}
+			case data.FieldTypeNullableTime:
+				for i := 0; i < field.Len(); i++ {
+					if t, ok := field.At(i).(*time.Time); ok && t != nil {
+						shifted := t.Add(offset)
+						field.Set(i, &shifted)
+					}
+				}
+			}
+		}
+	}
+}
+
+type replayQuery struct {
+	DatasourceUID string `json:"datasourceUid"`
+	RefID         string `json:"refId"`
+	QueryHash     string `json:"queryHash"`
+}
+
+func (s *Service) handleReplayScenario(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	resp := backend.NewQueryDataResponse()
+
+	for _, q := range req.Queries {
+		model, err := GetJSONModel(q.JSON)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse query json: %v", err)
+		}
+		if s.recordingsPath == "" {
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, "recording of query responses is not enabled, set record_responses_path in the [query] section of the configuration")
+			continue
+		}
+		if model.Replay.DatasourceUID == "" {
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, "the UID of the recorded data source is required")
+			continue
+		}
+		// the recorded responses are only replayed to users allowed to query the recorded data source
+		canQuery, err := s.canQueryDatasource(ctx, req.PluginContext, model.Replay.DatasourceUID)
+		if err != nil {
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, err.Error())
+			continue
+		}
+		if !canQuery {
+			resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusForbidden, fmt.Sprintf("access denied to the recordings of data source %s", model.Replay.DatasourceUID))
+			continue
+		}
+		dir := recordingsDir(s.recordingsPath, req.PluginContext.OrgID, model.Replay.DatasourceUID)
+
+		refID := model.Replay.RefID
+		if refID == "" {
+			refID = q.RefID
+		}
+		rec, err := readRecording(dir, refID, model.Replay.QueryHash)
+		if err != nil {
+			if errors.Is(err, errRecordingNotFound) {
+				resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusNotFound, fmt.Sprintf("no recording found for query %s", refID))
+			} else {
+				resp.Responses[q.RefID] = backend.ErrDataResponse(backend.StatusInternal, err.Error())
+			}
+			continue
+		}
+
+		// move the recorded data to the end of the requested time range
+		respD := rec.Response.Responses[rec.RefID]
+		shiftFrames(respD.Frames, q.TimeRange.To.Sub(rec.To))
+		for _, frame := range respD.Frames {
+			frame.RefID = q.RefID
+		}
+		resp.Responses[q.RefID] = respD
+	}
+
+	return resp, nil
+}
+
+func (s *Service) canQueryDatasource(ctx context.Context, pCtx backend.PluginContext, uid string) (bool, error) {
+	if s.authorizer == nil {
+		return false, nil
+	}
+	return s.authorizer.CanQueryDatasource(ctx, pCtx, uid)
+}
+
+func (s *Service) getRecordingsHandler(rw http.ResponseWriter, req *http.Request) {
+	ctxLogger := s.logger.FromContext(req.Context())
+
+	result := make([]recordingSummary, 0)
+	if s.recordingsPath != "" {
+		pCtx := httpadapter.PluginConfigFromContext(req.Context())
+		recordings, err := listRecordings(s.recordingsPath, pCtx.OrgID)
+		if err != nil {
+			ctxLogger.Error("Failed to list recordings", "error", err)
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// only the recordings of the data sources the user can query are listed
+		canQuery := map[string]bool{}
+		for _, rec := range recordings {
+			allowed, ok := canQuery[rec.DatasourceUID]
+			if !ok {
+				allowed, err = s.canQueryDatasource(req.Context(), pCtx, rec.DatasourceUID)
+				if err != nil {
+					ctxLogger.Error("Failed to check access to recorded data source", "datasourceUid", rec.DatasourceUID, "error", err)
+					http.Error(rw, err.Error(), http.StatusInternalServerError)
+					return
+				}
+				canQuery[rec.DatasourceUID] = allowed
+			}
+			if allowed {
+				result = append(result, rec)
+			}
+		}
+	}
+
+	bytes, err := json.Marshal(&result)
+	if err != nil {
+		ctxLogger.Error("Failed to marshal response body to JSON", "error", err)
+	}
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	if _, err := rw.Write(bytes); err != nil {
+		ctxLogger.Error("Failed to write response", "error", err)
+	}
+}
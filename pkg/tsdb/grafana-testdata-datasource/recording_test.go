@@ -0,0 +1,141 @@
+package testdatasource
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryHash(t *testing.T) {
+	t.Run("ignores the properties which change between requests", func(t *testing.T) {
+		a := QueryHash("ds1", json.RawMessage(`{"refId":"A","expr":"up","intervalMs":1000,"maxDataPoints":100}`))
+		b := QueryHash("ds1", json.RawMessage(`{"maxDataPoints":200,"expr":"up","refId":"B","intervalMs":15000}`))
+		require.Equal(t, a, b)
+	})
+
+	t.Run("depends on the query and the data source", func(t *testing.T) {
+		a := QueryHash("ds1", json.RawMessage(`{"expr":"up"}`))
+		require.NotEqual(t, a, QueryHash("ds1", json.RawMessage(`{"expr":"down"}`)))
+		require.NotEqual(t, a, QueryHash("ds2", json.RawMessage(`{"expr":"up"}`)))
+	})
+}
+
+func TestReplayScenario(t *testing.T) {
+	dir := t.TempDir()
+	recordedTo := time.Date(2022, 1, 1, 1, 0, 0, 0, time.UTC)
+	write := func(refID, hash string, value float64, recordedAt time.Time) {
+		frame := data.NewFrame("",
+			data.NewField("time", nil, []time.Time{recordedTo.Add(-time.Minute), recordedTo}),
+			data.NewField("value", nil, []float64{value, value}),
+		)
+		err := WriteRecording(dir, &Recording{
+			OrgID:         1,
+			RefID:         refID,
+			QueryHash:     hash,
+			DatasourceUID: "ds1",
+			From:          recordedTo.Add(-time.Hour),
+			To:            recordedTo,
+			RecordedAt:    recordedAt,
+			Response:      backend.QueryDataResponse{Responses: backend.Responses{refID: {Frames: data.Frames{frame}}}},
+		})
+		require.NoError(t, err)
+	}
+	write("A", "aaaa", 1, recordedTo)
+	write("A", "bbbb", 2, recordedTo.Add(time.Hour))
+
+	s := NewService(Settings{RecordingsPath: dir, Authorizer: fakeAuthorizer{"ds1": true, "ds2": true}})
+	to := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	query := func(refID, model string) *backend.QueryDataRequest {
+		return &backend.QueryDataRequest{
+			PluginContext: backend.PluginContext{OrgID: 1},
+			Queries: []backend.DataQuery{{
+				RefID:     refID,
+				QueryType: string(replayQueryType),
+				TimeRange: backend.TimeRange{From: to.Add(-time.Hour), To: to},
+				JSON:      json.RawMessage(model),
+			}},
+		}
+	}
+
+	t.Run("returns the most recent recording of the refId shifted to the requested range", func(t *testing.T) {
+		resp, err := s.handleReplayScenario(context.Background(), query("A", `{"replay":{"datasourceUid":"ds1"}}`))
+		require.NoError(t, err)
+		dr := resp.Responses["A"]
+		require.NoError(t, dr.Error)
+		require.Len(t, dr.Frames, 1)
+		require.Equal(t, "A", dr.Frames[0].RefID)
+		require.Equal(t, to, dr.Frames[0].Fields[0].At(1))
+		require.Equal(t, 2.0, dr.Frames[0].Fields[1].At(0))
+	})
+
+	t.Run("returns the recording of the query hash", func(t *testing.T) {
+		resp, err := s.handleReplayScenario(context.Background(), query("B", `{"replay":{"datasourceUid":"ds1","refId":"A","queryHash":"aaaa"}}`))
+		require.NoError(t, err)
+		dr := resp.Responses["B"]
+		require.NoError(t, dr.Error)
+		require.Equal(t, 1.0, dr.Frames[0].Fields[1].At(0))
+	})
+
+	t.Run("returns an error when there is no recording", func(t *testing.T) {
+		resp, err := s.handleReplayScenario(context.Background(), query("C", `{"replay":{"datasourceUid":"ds1"}}`))
+		require.NoError(t, err)
+		require.Error(t, resp.Responses["C"].Error)
+		require.Equal(t, backend.StatusNotFound, resp.Responses["C"].Status)
+	})
+
+	t.Run("does not return recordings of other organizations", func(t *testing.T) {
+		req := query("A", `{"replay":{"datasourceUid":"ds1"}}`)
+		req.PluginContext.OrgID = 2
+		resp, err := s.handleReplayScenario(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, backend.StatusNotFound, resp.Responses["A"].Status)
+	})
+
+	t.Run("does not return recordings of other data sources", func(t *testing.T) {
+		resp, err := s.handleReplayScenario(context.Background(), query("A", `{"replay":{"datasourceUid":"ds2"}}`))
+		require.NoError(t, err)
+		require.Equal(t, backend.StatusNotFound, resp.Responses["A"].Status)
+	})
+
+	t.Run("returns an error when the data source UID is not set", func(t *testing.T) {
+		resp, err := s.handleReplayScenario(context.Background(), query("A", `{}`))
+		require.NoError(t, err)
+		require.Equal(t, backend.StatusBadRequest, resp.Responses["A"].Status)
+	})
+
+	t.Run("returns an error when the user cannot query the recorded data source", func(t *testing.T) {
+		denied := NewService(Settings{RecordingsPath: dir, Authorizer: fakeAuthorizer{}})
+		resp, err := denied.handleReplayScenario(context.Background(), query("A", `{"replay":{"datasourceUid":"ds1"}}`))
+		require.NoError(t, err)
+		require.Equal(t, backend.StatusForbidden, resp.Responses["A"].Status)
+	})
+
+	t.Run("returns an error when there is no authorizer", func(t *testing.T) {
+		denied := NewService(Settings{RecordingsPath: dir})
+		resp, err := denied.handleReplayScenario(context.Background(), query("A", `{"replay":{"datasourceUid":"ds1"}}`))
+		require.NoError(t, err)
+		require.Equal(t, backend.StatusForbidden, resp.Responses["A"].Status)
+	})
+
+	t.Run("returns an error when recording is not enabled", func(t *testing.T) {
+		resp, err := ProvideService().handleReplayScenario(context.Background(), query("A", `{"replay":{"datasourceUid":"ds1"}}`))
+		require.NoError(t, err)
+		require.Equal(t, backend.StatusBadRequest, resp.Responses["A"].Status)
+	})
+}
+
+// fakeAuthorizer allows querying the data sources with the UIDs set to true
+type fakeAuthorizer map[string]bool
+
+func (a fakeAuthorizer) CanQueryDatasource(_ context.Context, _ backend.PluginContext, uid string) (bool, error) {
+	return a[uid], nil
+}
+
+func (a fakeAuthorizer) CanManageSimulations(_ context.Context, _ backend.PluginContext) (bool, error) {
+	return false, nil
+}
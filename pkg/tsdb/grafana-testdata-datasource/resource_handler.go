@@ -16,11 +16,13 @@ func (s *Service) registerRoutes() *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/", s.testGetHandler)
 	mux.HandleFunc("/scenarios", s.getScenariosHandler)
+	mux.HandleFunc("/recordings", s.getRecordingsHandler)
 	mux.HandleFunc("/stream", s.testStreamHandler)
 	mux.Handle("/test", createJSONHandler(s.logger))
 	mux.Handle("/test/json", createJSONHandler(s.logger))
 	mux.HandleFunc("/boom", s.testPanicHandler)
 	mux.HandleFunc("/sims", s.sims.GetSimulationHandler)
+	mux.HandleFunc("/sims/", s.sims.GetSimulationHandler)
 	mux.HandleFunc("/sim/", s.sims.GetSimulationHandler)
 	return mux
 }
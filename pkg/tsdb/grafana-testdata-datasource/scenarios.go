@@ -47,6 +47,8 @@ const (
 	csvFileQueryType                  queryType = "csv_file"
 	csvContentQueryType               queryType = "csv_content"
 	traceType                         queryType = "trace"
+	replayQueryType                   queryType = "replay"
+	faultInjectionQueryType           queryType = "fault_injection"
 )
 
 type queryType string
@@ -227,6 +229,25 @@ Timestamps will line up evenly on timeStepSeconds (For example, 60 seconds means
 		Name: "Trace",
 	})
 
+	s.registerScenario(&Scenario{
+		ID:      string(replayQueryType),
+		Name:    "Replay",
+		handler: s.handleReplayScenario,
+		Description: `Replay returns a response recorded by the query service, moved to the requested time range.
+Responses are recorded when record_responses_path is set in the [query] section of the configuration.
+The recording is matched by organization, recorded data source UID, refId and, when set, by query hash.
+Without query hash, the most recent recording of the refId is used.`,
+	})
+
+	s.registerScenario(&Scenario{
+		ID:      string(faultInjectionQueryType),
+		Name:    "Fault Injection",
+		handler: s.handleFaultInjectionScenario,
+		Description: `Fault Injection follows a script of steps, selected by the end of the query time range, to test the NoData and Error handling of alert rules.
+A step returns data, times out, fails, fails for some series, returns empty frames, returns no frames, or returns series with a new label set on each evaluation.
+Without start time, the script repeats from the epoch. With a start time, the last step lasts once the script has ended.`,
+	})
+
 	s.queryMux.HandleFunc("", s.handleFallbackScenario)
 }
 
@@ -268,10 +289,12 @@ type JSONModel struct {
 	Alias              string    `json:"alias"`
 	// Cannot specify a type for csvWave since legacy queries
 	// does not follow the same format as the new ones (and there is no migration).
-	CSVWave     any     `json:"csvWave"`
-	CSVContent  string  `json:"csvContent"`
-	CSVFileName string  `json:"csvFileName"`
-	DropPercent float64 `json:"dropPercent"`
+	CSVWave     any                 `json:"csvWave"`
+	CSVContent  string              `json:"csvContent"`
+	CSVFileName string              `json:"csvFileName"`
+	DropPercent float64             `json:"dropPercent"`
+	Replay      replayQuery         `json:"replay"`
+	Faults      faultInjectionQuery `json:"faults"`
 }
 
 type pulseWave struct {
@@ -0,0 +1,368 @@
+package sims
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+var (
+	_ Simulation = (*customSim)(nil)
+
+	customSimTypeRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+	customSimNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// variables available in every expression of a custom simulation
+const (
+	customSimElapsedVar = "t"  // seconds since the simulation started
+	customSimDeltaVar   = "dt" // seconds since the previous tick
+)
+
+// customSimulationDefinition describes a simulation registered by users. The
+// state variables are updated on each tick with expressions using the syntax
+// of math expressions, e.g. `$level + $dt * ($fillRate - $drainRate)`.
+type customSimulationDefinition struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Parameters can be changed while the simulation is running, like the
+	// config of the builtin simulations
+	Parameters map[string]float64         `json:"parameters,omitempty"`
+	State      []customSimulationVariable `json:"state"`
+
+	// Fields of the frames returned by the simulation, the state variables
+	// are returned when empty
+	Fields []customSimulationField `json:"fields,omitempty"`
+}
+
+type customSimulationVariable struct {
+	Name    string  `json:"name"`
+	Initial float64 `json:"initial"`
+	Update  string  `json:"update"`
+}
+
+type customSimulationField struct {
+	Name string `json:"name"`
+	Expr string `json:"expr"`
+	Unit string `json:"unit,omitempty"`
+}
+
+type customSimulationFunc func(args []float64) float64
+
+// newCustomSimulationFunc returns a function of math expressions taking args
+// numbers, the parser checks the number of arguments
+func newCustomSimulationFunc(args int, f customSimulationFunc) parse.Func {
+	argTypes := make([]parse.ReturnType, args)
+	for i := range argTypes {
+		argTypes[i] = parse.TypeVariantSet
+	}
+	return parse.Func{
+		Args:   argTypes,
+		Return: parse.TypeScalar,
+		F:      f,
+	}
+}
+
+func newUnaryCustomSimulationFunc(f func(float64) float64) parse.Func {
+	return newCustomSimulationFunc(1, func(args []float64) float64 { return f(args[0]) })
+}
+
+var customSimulationFuncs = map[string]parse.Func{
+	"abs":   newUnaryCustomSimulationFunc(math.Abs),
+	"sqrt":  newUnaryCustomSimulationFunc(math.Sqrt),
+	"log":   newUnaryCustomSimulationFunc(math.Log),
+	"exp":   newUnaryCustomSimulationFunc(math.Exp),
+	"sin":   newUnaryCustomSimulationFunc(math.Sin),
+	"cos":   newUnaryCustomSimulationFunc(math.Cos),
+	"floor": newUnaryCustomSimulationFunc(math.Floor),
+	"ceil":  newUnaryCustomSimulationFunc(math.Ceil),
+	"round": newUnaryCustomSimulationFunc(math.Round),
+	"min": newCustomSimulationFunc(2, func(args []float64) float64 {
+		return math.Min(args[0], args[1])
+	}),
+	"max": newCustomSimulationFunc(2, func(args []float64) float64 {
+		return math.Max(args[0], args[1])
+	}),
+	"clamp": newCustomSimulationFunc(3, func(args []float64) float64 {
+		return math.Max(args[1], math.Min(args[0], args[2]))
+	}),
+	// random returns a uniform random number in [0, 1)
+	"random": newCustomSimulationFunc(0, func(args []float64) float64 {
+		return rand.Float64()
+	}),
+	// noise returns a normally distributed random number with the given standard deviation
+	"noise": newCustomSimulationFunc(1, func(args []float64) float64 {
+		return rand.NormFloat64() * args[0]
+	}),
+}
+
+type compiledCustomVariable struct {
+	customSimulationVariable
+	update parse.Node
+}
+
+type compiledCustomField struct {
+	customSimulationField
+	expr parse.Node
+}
+
+// compiledCustomSimulation is a validated definition with parsed expressions
+type compiledCustomSimulation struct {
+	def    customSimulationDefinition
+	state  []compiledCustomVariable
+	fields []compiledCustomField
+}
+
+func compileCustomSimulation(def customSimulationDefinition) (*compiledCustomSimulation, error) {
+	if !customSimTypeRegex.MatchString(def.Type) {
+		return nil, fmt.Errorf("invalid simulation type %q, only letters, digits, _ and - are allowed", def.Type)
+	}
+	if def.Name == "" {
+		def.Name = def.Type
+	}
+	if len(def.State) == 0 && len(def.Fields) == 0 {
+		return nil, fmt.Errorf("simulation %s has no state variables and no fields", def.Type)
+	}
+
+	known := map[string]bool{customSimElapsedVar: true, customSimDeltaVar: true}
+	declare := func(kind, name string) error {
+		if !customSimNameRegex.MatchString(name) {
+			return fmt.Errorf("invalid %s name %q", kind, name)
+		}
+		if known[name] {
+			return fmt.Errorf("%s %s is already defined", kind, name)
+		}
+		known[name] = true
+		return nil
+	}
+	for name := range def.Parameters {
+		if err := declare("parameter", name); err != nil {
+			return nil, err
+		}
+	}
+	for _, v := range def.State {
+		if err := declare("state variable", v.Name); err != nil {
+			return nil, err
+		}
+	}
+
+	compile := func(name, text string) (parse.Node, error) {
+		tree, err := parseCustomExpr(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expression of %s: %w", name, err)
+		}
+		for _, v := range tree.VarNames {
+			if !known[v] {
+				return nil, fmt.Errorf("invalid expression of %s: unknown variable $%s", name, v)
+			}
+		}
+		return tree.Root, nil
+	}
+
+	sim := &compiledCustomSimulation{def: def}
+	for _, v := range def.State {
+		c := compiledCustomVariable{customSimulationVariable: v}
+		if v.Update != "" {
+			node, err := compile(v.Name, v.Update)
+			if err != nil {
+				return nil, err
+			}
+			c.update = node
+		}
+		sim.state = append(sim.state, c)
+	}
+
+	fieldNames := map[string]bool{"time": true}
+	for _, f := range def.Fields {
+		if f.Name == "" || fieldNames[f.Name] {
+			return nil, fmt.Errorf("invalid field name %q", f.Name)
+		}
+		fieldNames[f.Name] = true
+		node, err := compile(f.Name, f.Expr)
+		if err != nil {
+			return nil, err
+		}
+		sim.fields = append(sim.fields, compiledCustomField{customSimulationField: f, expr: node})
+	}
+	return sim, nil
+}
+
+type customSim struct {
+	key   simulationKey
+	sim   *compiledCustomSimulation
+	cfg   map[string]float64
+	state customSimState
+}
+
+type customSimState struct {
+	Start  time.Time
+	Time   time.Time
+	Values map[string]float64
+}
+
+func newCustomSim(sim *compiledCustomSimulation, q simulationState) (Simulation, error) {
+	now := time.Now()
+	s := &customSim{
+		key: q.Key,
+		sim: sim,
+		cfg: make(map[string]float64, len(sim.def.Parameters)),
+		state: customSimState{
+			Start:  now,
+			Time:   now,
+			Values: make(map[string]float64, len(sim.state)),
+		},
+	}
+	for name, v := range sim.def.Parameters {
+		s.cfg[name] = v
+	}
+	for _, v := range sim.state {
+		s.state.Values[v.Name] = v.Initial
+	}
+	if q.Config != nil {
+		cfg, err := asStringMap(q.Config)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.SetConfig(cfg); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *customSim) GetState() simulationState {
+	return simulationState{
+		Key:    s.key,
+		Config: s.cfg,
+	}
+}
+
+// SetConfig updates the parameters of the simulation, other values are ignored
+func (s *customSim) SetConfig(vals map[string]any) error {
+	for name, v := range vals {
+		if _, ok := s.sim.def.Parameters[name]; !ok {
+			continue
+		}
+		f, ok := v.(float64)
+		if !ok {
+			return fmt.Errorf("invalid value of parameter %s, expecting a number", name)
+		}
+		s.cfg[name] = f
+	}
+	return nil
+}
+
+func (s *customSim) NewFrame(size int) *data.Frame {
+	frame := data.NewFrame("", data.NewField("time", nil, make([]time.Time, size)))
+	if len(s.sim.fields) == 0 {
+		for _, v := range s.sim.state {
+			frame.Fields = append(frame.Fields, data.NewField(v.Name, nil, make([]float64, size)))
+		}
+		return frame
+	}
+	for _, f := range s.sim.fields {
+		field := data.NewField(f.Name, nil, make([]float64, size))
+		if f.Unit != "" {
+			field.Config = &data.FieldConfig{Unit: f.Unit}
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+	return frame
+}
+
+func (s *customSim) vars(dt float64) map[string]float64 {
+	vars := make(map[string]float64, len(s.cfg)+len(s.state.Values)+2)
+	for name, v := range s.cfg {
+		vars[name] = v
+	}
+	for name, v := range s.state.Values {
+		vars[name] = v
+	}
+	vars[customSimElapsedVar] = s.state.Time.Sub(s.state.Start).Seconds()
+	vars[customSimDeltaVar] = dt
+	return vars
+}
+
+func (s *customSim) GetValues(t time.Time) map[string]any {
+	if t.Before(s.state.Time) {
+		return nil // can not look backwards!
+	}
+	if t.After(s.state.Time) {
+		dt := t.Sub(s.state.Time).Seconds()
+		vars := s.vars(dt)
+		vars[customSimElapsedVar] = t.Sub(s.state.Start).Seconds()
+
+		// every variable is updated from the values of the previous tick
+		next := make(map[string]float64, len(s.state.Values))
+		for _, v := range s.sim.state {
+			next[v.Name] = s.state.Values[v.Name]
+			if v.update == nil {
+				continue
+			}
+			value, err := evalCustomNode(v.update, vars)
+			if err != nil {
+				return nil
+			}
+			next[v.Name] = value
+		}
+		s.state.Values = next
+		s.state.Time = t
+	}
+
+	values := map[string]any{"time": s.state.Time}
+	if len(s.sim.fields) == 0 {
+		for name, v := range s.state.Values {
+			values[name] = v
+		}
+		return values
+	}
+
+	vars := s.vars(0)
+	for _, f := range s.sim.fields {
+		value, err := evalCustomNode(f.expr, vars)
+		if err != nil {
+			return nil
+		}
+		values[f.Name] = value
+	}
+	return values
+}
+
+func (s *customSim) Close() error {
+	return nil
+}
+
+func newCustomSimInfo(sim *compiledCustomSimulation) simulationInfo {
+	names := make([]string, 0, len(sim.def.Parameters))
+	for name := range sim.def.Parameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	df := data.NewFrame("")
+	for _, name := range names {
+		df.Fields = append(df.Fields, data.NewField(name, nil, []float64{sim.def.Parameters[name]}))
+	}
+
+	def := sim.def
+	return simulationInfo{
+		Type:         def.Type,
+		Name:         def.Name,
+		Description:  def.Description,
+		ConfigFields: df,
+		OnlyForward:  true,
+		Custom:       &def,
+		create: func(q simulationState) (Simulation, error) {
+			return newCustomSim(sim, q)
+		},
+	}
+}
@@ -0,0 +1,103 @@
+package sims
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/grafana/grafana/pkg/expr/mathexp/parse"
+)
+
+// parseCustomExpr parses an expression of a custom simulation, which uses the
+// syntax of math expressions: numbers, $variables, functions and the
+// arithmetic, comparison and logical operators.
+func parseCustomExpr(text string) (*parse.Tree, error) {
+	return parse.Parse(text, customSimulationFuncs)
+}
+
+// evalCustomNode evaluates an expression of a custom simulation to a number
+func evalCustomNode(node parse.Node, vars map[string]float64) (float64, error) {
+	switch n := node.(type) {
+	case *parse.ScalarNode:
+		return n.Float64, nil
+	case *parse.VarNode:
+		v, ok := vars[n.Name]
+		if !ok {
+			return 0, fmt.Errorf("unknown variable $%s", n.Name)
+		}
+		return v, nil
+	case *parse.UnaryNode:
+		a, err := evalCustomNode(n.Arg, vars)
+		if err != nil {
+			return 0, err
+		}
+		switch n.OpStr {
+		case "-":
+			return -a, nil
+		case "!":
+			return boolToFloat(a == 0), nil
+		}
+		return 0, fmt.Errorf("unknown unary operator %s", n.OpStr)
+	case *parse.BinaryNode:
+		a, err := evalCustomNode(n.Args[0], vars)
+		if err != nil {
+			return 0, err
+		}
+		b, err := evalCustomNode(n.Args[1], vars)
+		if err != nil {
+			return 0, err
+		}
+		switch n.OpStr {
+		case "+":
+			return a + b, nil
+		case "-":
+			return a - b, nil
+		case "*":
+			return a * b, nil
+		case "/":
+			return a / b, nil
+		case "**":
+			return math.Pow(a, b), nil
+		case "%":
+			return math.Mod(a, b), nil
+		case "==":
+			return boolToFloat(a == b), nil
+		case "!=":
+			return boolToFloat(a != b), nil
+		case ">":
+			return boolToFloat(a > b), nil
+		case ">=":
+			return boolToFloat(a >= b), nil
+		case "<":
+			return boolToFloat(a < b), nil
+		case "<=":
+			return boolToFloat(a <= b), nil
+		case "&&":
+			return boolToFloat(a != 0 && b != 0), nil
+		case "||":
+			return boolToFloat(a != 0 || b != 0), nil
+		}
+		return 0, fmt.Errorf("unknown binary operator %s", n.OpStr)
+	case *parse.FuncNode:
+		f, ok := n.F.F.(customSimulationFunc)
+		if !ok {
+			return 0, fmt.Errorf("unknown function %s", n.Name)
+		}
+		args := make([]float64, len(n.Args))
+		for i, arg := range n.Args {
+			v, err := evalCustomNode(arg, vars)
+			if err != nil {
+				return 0, err
+			}
+			args[i] = v
+		}
+		return f(args), nil
+	}
+	return 0, fmt.Errorf("unsupported expression %s", node.String())
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
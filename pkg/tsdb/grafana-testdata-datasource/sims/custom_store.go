@@ -0,0 +1,100 @@
+package sims
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+var (
+	errSimulationsPathNotSet   = errors.New("simulations_path is not set in the [plugin.grafana-testdata-datasource] section of the configuration")
+	errSimulationNotFound      = errors.New("simulation not found")
+	errManageSimulationsDenied = errors.New("access denied to manage simulations")
+)
+
+// customSimulationsDir returns the directory of the custom simulations of
+// the organization. The simulations are saved in the simulations path of the
+// server configuration, in a directory per organization.
+func (s *SimulationEngine) customSimulationsDir(orgID int64) string {
+	if s.simulationsPath == "" {
+		return ""
+	}
+	return filepath.Join(s.simulationsPath, fmt.Sprintf("org-%d", orgID))
+}
+
+func readCustomSimulationFile(path string) (customSimulationDefinition, error) {
+	def := customSimulationDefinition{}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return def, errSimulationNotFound
+		}
+		return def, err
+	}
+	if err := json.Unmarshal(b, &def); err != nil {
+		return def, fmt.Errorf("failed to read simulation %s: %w", filepath.Base(path), err)
+	}
+	return def, nil
+}
+
+func readCustomSimulation(dir, simType string) (customSimulationDefinition, error) {
+	if !customSimTypeRegex.MatchString(simType) {
+		return customSimulationDefinition{}, errSimulationNotFound
+	}
+	return readCustomSimulationFile(filepath.Join(dir, simType+".json"))
+}
+
+func listCustomSimulations(dir string) ([]customSimulationDefinition, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]customSimulationDefinition, 0, len(paths))
+	for _, path := range paths {
+		def, err := readCustomSimulationFile(path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, def)
+	}
+	return result, nil
+}
+
+func writeCustomSimulation(dir string, def customSimulationDefinition) error {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(def, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so running simulations never read a partial definition
+	file, err := os.CreateTemp(dir, ".simulation-*")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(b); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), filepath.Join(dir, def.Type+".json"))
+}
+
+func deleteCustomSimulation(dir, simType string) error {
+	if !customSimTypeRegex.MatchString(simType) {
+		return errSimulationNotFound
+	}
+	err := os.Remove(filepath.Join(dir, simType+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return errSimulationNotFound
+	}
+	return err
+}
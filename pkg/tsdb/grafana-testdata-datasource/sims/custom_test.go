@@ -0,0 +1,237 @@
+package sims
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/stretchr/testify/require"
+)
+
+func newCounterDefinition() customSimulationDefinition {
+	return customSimulationDefinition{
+		Type:       "counter",
+		Name:       "Counter",
+		Parameters: map[string]float64{"rate": 2},
+		State: []customSimulationVariable{
+			{Name: "count", Initial: 10, Update: "$count + $rate * $dt"},
+			{Name: "ticks", Update: "$ticks + 1"},
+		},
+		Fields: []customSimulationField{
+			{Name: "value", Expr: "clamp($count, 0, 100)", Unit: "short"},
+			{Name: "high", Expr: "$count > 15"},
+		},
+	}
+}
+
+func TestCompileCustomSimulation(t *testing.T) {
+	t.Run("valid definition", func(t *testing.T) {
+		sim, err := compileCustomSimulation(newCounterDefinition())
+		require.NoError(t, err)
+		require.Len(t, sim.state, 2)
+		require.Len(t, sim.fields, 2)
+	})
+
+	for name, update := range map[string]func(def *customSimulationDefinition){
+		"invalid type":           func(def *customSimulationDefinition) { def.Type = "a/b" },
+		"invalid variable name":  func(def *customSimulationDefinition) { def.State[0].Name = "1x" },
+		"duplicate variable":     func(def *customSimulationDefinition) { def.State[1].Name = "rate" },
+		"reserved variable":      func(def *customSimulationDefinition) { def.State[1].Name = "dt" },
+		"unknown variable":       func(def *customSimulationDefinition) { def.State[0].Update = "$count + $speed" },
+		"unknown function":       func(def *customSimulationDefinition) { def.Fields[0].Expr = "avg($count)" },
+		"wrong argument count":   func(def *customSimulationDefinition) { def.Fields[0].Expr = "min($count)" },
+		"invalid syntax":         func(def *customSimulationDefinition) { def.Fields[0].Expr = "$count +" },
+		"duplicate field":        func(def *customSimulationDefinition) { def.Fields[1].Name = "value" },
+		"no state and no fields": func(def *customSimulationDefinition) { def.State, def.Fields = nil, nil },
+	} {
+		t.Run(name, func(t *testing.T) {
+			def := newCounterDefinition()
+			update(&def)
+			_, err := compileCustomSimulation(def)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCustomSimulation(t *testing.T) {
+	sim, err := compileCustomSimulation(newCounterDefinition())
+	require.NoError(t, err)
+	v, err := newCustomSim(sim, simulationState{Config: map[string]any{"rate": 4.0}})
+	require.NoError(t, err)
+
+	start := v.(*customSim).state.Time
+	require.Nil(t, v.GetValues(start.Add(-time.Second)))
+
+	values := v.GetValues(start.Add(2 * time.Second))
+	require.Equal(t, 18.0, values["value"])
+	require.Equal(t, 1.0, values["high"])
+
+	require.NoError(t, v.SetConfig(map[string]any{"rate": -10.0, "unknown": "ignored"}))
+	values = v.GetValues(start.Add(3 * time.Second))
+	require.Equal(t, 8.0, values["value"])
+	require.Equal(t, 0.0, values["high"])
+	require.Equal(t, 2.0, v.(*customSim).state.Values["ticks"])
+
+	require.Error(t, v.SetConfig(map[string]any{"rate": "fast"}))
+
+	frame := v.NewFrame(0)
+	require.Len(t, frame.Fields, 3)
+	require.Equal(t, "short", frame.Fields[1].Config.Unit)
+}
+
+func TestCustomSimulationEngine(t *testing.T) {
+	s, err := NewSimulationEngine(t.TempDir(), fakeAuthorizer{"admin": true})
+	require.NoError(t, err)
+
+	settings := &backend.DataSourceInstanceSettings{UID: "testdata"}
+	org1 := backend.PluginContext{OrgID: 1, DataSourceInstanceSettings: settings, User: &backend.User{Login: "admin"}}
+	org2 := backend.PluginContext{OrgID: 2, DataSourceInstanceSettings: settings, User: &backend.User{Login: "admin"}}
+	viewer := backend.PluginContext{OrgID: 1, DataSourceInstanceSettings: settings, User: &backend.User{Login: "viewer"}}
+
+	handler := httpadapter.New(http.HandlerFunc(s.GetSimulationHandler))
+	call := func(pCtx backend.PluginContext, method, path, body string) *backend.CallResourceResponse {
+		var rsp *backend.CallResourceResponse
+		err := handler.CallResource(context.Background(), &backend.CallResourceRequest{
+			PluginContext: pCtx,
+			Path:          path,
+			Method:        method,
+			URL:           path,
+			Body:          []byte(body),
+		}, callResourceResponseSenderFunc(func(r *backend.CallResourceResponse) error {
+			rsp = r
+			return nil
+		}))
+		require.NoError(t, err)
+		return rsp
+	}
+
+	t.Run("register a simulation", func(t *testing.T) {
+		b, err := json.Marshal(newCounterDefinition())
+		require.NoError(t, err)
+		rw := call(org1, http.MethodPost, "/sims", string(b))
+		require.Equal(t, http.StatusOK, rw.Status, string(rw.Body))
+
+		rw = call(org1, http.MethodPost, "/sims", `{"type":"tank","state":[{"name":"x"}]}`)
+		require.Equal(t, http.StatusBadRequest, rw.Status)
+
+		rw = call(viewer, http.MethodPost, "/sims", string(b))
+		require.Equal(t, http.StatusForbidden, rw.Status)
+	})
+
+	t.Run("list the simulations of the organization", func(t *testing.T) {
+		list := func(pCtx backend.PluginContext) []string {
+			infos, err := s.listSimulations(pCtx)
+			require.NoError(t, err)
+			types := []string{}
+			for _, info := range infos {
+				types = append(types, info.Type)
+			}
+			return types
+		}
+		require.Contains(t, list(org1), "counter")
+		require.Contains(t, list(org1), "flight")
+		require.NotContains(t, list(org2), "counter")
+
+		rw := call(org1, http.MethodGet, "/sims/counter", "")
+		require.Equal(t, http.StatusOK, rw.Status)
+		require.Contains(t, string(rw.Body), `"$count + $rate * $dt"`)
+		require.Equal(t, http.StatusNotFound, call(org2, http.MethodGet, "/sims/counter", "").Status)
+	})
+
+	t.Run("query and stream a simulation", func(t *testing.T) {
+		rsp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: org1,
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				TimeRange: backend.TimeRange{From: time.Now(), To: time.Now().Add(time.Second)},
+				JSON:      json.RawMessage(`{"sim":{"key":{"type":"counter","tick":1},"last":true,"stream":true}}`),
+			}},
+		})
+		require.NoError(t, err)
+		frame := rsp.Responses["A"].Frames[0]
+		require.Equal(t, 1, frame.Rows())
+		require.Equal(t, "ds/testdata/sim/counter/1hz", frame.Meta.Channel)
+
+		sub, err := s.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{
+			PluginContext: org1,
+			Path:          "sim/counter/1hz",
+		})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusOK, sub.Status)
+
+		_, err = s.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{
+			PluginContext: org2,
+			Path:          "sim/counter/1hz",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("updating a simulation restarts it", func(t *testing.T) {
+		before, err := s.getSimFromPath(org1, "counter/1hz")
+		require.NoError(t, err)
+
+		def := newCounterDefinition()
+		def.Parameters["rate"] = 5
+		_, err = s.saveCustomSimulation(org1, def)
+		require.NoError(t, err)
+
+		after, err := s.getSimFromPath(org1, "counter/1hz")
+		require.NoError(t, err)
+		require.NotSame(t, before, after)
+		require.Equal(t, 5.0, after.GetState().Config.(map[string]float64)["rate"])
+	})
+
+	t.Run("delete a simulation", func(t *testing.T) {
+		require.Equal(t, http.StatusForbidden, call(viewer, http.MethodDelete, "/sims/counter", "").Status)
+
+		rw := call(org1, http.MethodDelete, "/sims/counter", "")
+		require.Equal(t, http.StatusOK, rw.Status)
+		_, err := s.getSimFromPath(org1, "counter/1hz")
+		require.Error(t, err)
+		require.Equal(t, http.StatusNotFound, call(org1, http.MethodDelete, "/sims/counter", "").Status)
+	})
+}
+
+// fakeAuthorizer allows the users with the logins set to true to manage simulations
+type fakeAuthorizer map[string]bool
+
+func (a fakeAuthorizer) CanManageSimulations(_ context.Context, pCtx backend.PluginContext) (bool, error) {
+	return a[pCtx.User.Login], nil
+}
+
+type callResourceResponseSenderFunc func(*backend.CallResourceResponse) error
+
+func (fn callResourceResponseSenderFunc) Send(r *backend.CallResourceResponse) error {
+	return fn(r)
+}
+
+func TestCustomSimulationExpressions(t *testing.T) {
+	vars := map[string]float64{"a": 2, "b": 3}
+	for text, expected := range map[string]float64{
+		"1 + 2 * 3":            7,
+		"(1 + 2) * 3":          9,
+		"2 ** 3 ** 2":          64,
+		"-$a ** 2":             4,
+		"$a * -${b}":           -6,
+		"10 % 4 + 1.5e1":       17,
+		"$a < $b && !($b < 1)": 1,
+		"$a >= $b || $a != 2":  0,
+		"max($a, min($b, 1))":  2,
+		"clamp(5, 0, $b)":      3,
+	} {
+		tree, err := parseCustomExpr(text)
+		require.NoError(t, err, text)
+		v, err := evalCustomNode(tree.Root, vars)
+		require.NoError(t, err, text)
+		require.Equal(t, expected, v, text)
+	}
+
+	for _, text := range []string{"", "1 +", "(1", "max(1,)", "$", "1 2", "foo(1)", "1..2"} {
+		_, err := parseCustomExpr(text)
+		require.Error(t, err, text)
+	}
+}
@@ -3,6 +3,7 @@ package sims
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 )
 
@@ -31,6 +33,20 @@ type SimulationEngine struct {
 
 	// safe changes
 	mutex sync.Mutex
+
+	// directory of the custom simulations, custom simulations are disabled when empty
+	simulationsPath string
+
+	// checks the permission to manage the custom simulations, denied when nil
+	authorizer Authorizer
+}
+
+// Authorizer checks the permissions of the user of a request on the custom
+// simulations.
+type Authorizer interface {
+	// CanManageSimulations returns true when the user of the request can
+	// register and delete the custom simulations of the organization.
+	CanManageSimulations(ctx context.Context, pCtx backend.PluginContext) (bool, error)
 }
 
 func (s *SimulationEngine) register(info simulationInfo) error {
@@ -55,11 +71,15 @@ func (s *SimulationEngine) register(info simulationInfo) error {
 
 type simulationInitializer = func() simulationInfo
 
-func NewSimulationEngine() (*SimulationEngine, error) {
+// NewSimulationEngine returns a simulation engine which saves the custom
+// simulations in simulationsPath.
+func NewSimulationEngine(simulationsPath string, authorizer Authorizer) (*SimulationEngine, error) {
 	s := &SimulationEngine{
-		registry: make(map[string]simulationInfo),
-		running:  make(map[string]Simulation),
-		logger:   backend.NewLoggerWith("logger", "tsdb.sims"),
+		registry:        make(map[string]simulationInfo),
+		running:         make(map[string]Simulation),
+		logger:          backend.NewLoggerWith("logger", "tsdb.sims"),
+		simulationsPath: simulationsPath,
+		authorizer:      authorizer,
 	}
 	// Initialize each type
 	initializers := []simulationInitializer{
@@ -78,6 +98,12 @@ func NewSimulationEngine() (*SimulationEngine, error) {
 }
 
 func (s *SimulationEngine) Lookup(info simulationState) (Simulation, error) {
+	return s.lookup(backend.PluginContext{}, info)
+}
+
+// lookup returns the running instance of a simulation, which is either a
+// builtin simulation or a simulation registered in the organization
+func (s *SimulationEngine) lookup(pCtx backend.PluginContext, info simulationState) (Simulation, error) {
 	hz := info.Key.TickHZ
 	if hz < (1 / 60.0) {
 		return nil, fmt.Errorf("frequency is too slow")
@@ -89,18 +115,38 @@ func (s *SimulationEngine) Lookup(info simulationState) (Simulation, error) {
 		return nil, fmt.Errorf("missing simulation type")
 	}
 
-	key := info.Key.String()
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	t, builtin := s.registry[info.Key.Type]
+	key := info.Key.String()
+	if !builtin {
+		// custom simulations of different organizations may share the same type
+		key = customSimRunningKey(pCtx.OrgID, info.Key.Type) + key
+	}
+
 	v, ok := s.running[key]
 	if ok {
 		return v, nil
 	}
 
-	t, ok := s.registry[info.Key.Type]
-	if !ok {
-		return nil, fmt.Errorf("unknown simulation type")
+	if !builtin {
+		dir := s.customSimulationsDir(pCtx.OrgID)
+		if dir == "" {
+			return nil, fmt.Errorf("unknown simulation type")
+		}
+		def, err := readCustomSimulation(dir, info.Key.Type)
+		if err != nil {
+			if errors.Is(err, errSimulationNotFound) {
+				return nil, fmt.Errorf("unknown simulation type")
+			}
+			return nil, err
+		}
+		sim, err := compileCustomSimulation(def)
+		if err != nil {
+			return nil, err
+		}
+		t = newCustomSimInfo(sim)
 	}
 
 	v, err := t.create(info)
@@ -110,6 +156,101 @@ func (s *SimulationEngine) Lookup(info simulationState) (Simulation, error) {
 	return v, err
 }
 
+// canManageSimulations returns true when the user may register and delete the
+// simulations of the organization
+func (s *SimulationEngine) canManageSimulations(ctx context.Context, pCtx backend.PluginContext) (bool, error) {
+	if s.authorizer == nil {
+		return false, nil
+	}
+	return s.authorizer.CanManageSimulations(ctx, pCtx)
+}
+
+func customSimRunningKey(orgID int64, simType string) string {
+	return fmt.Sprintf("org-%d/%s/", orgID, simType)
+}
+
+// saveCustomSimulation validates and saves a simulation of the organization,
+// the running instances of the previous definition are stopped
+func (s *SimulationEngine) saveCustomSimulation(pCtx backend.PluginContext, def customSimulationDefinition) (simulationInfo, error) {
+	dir := s.customSimulationsDir(pCtx.OrgID)
+	if dir == "" {
+		return simulationInfo{}, errSimulationsPathNotSet
+	}
+
+	s.mutex.Lock()
+	_, builtin := s.registry[def.Type]
+	s.mutex.Unlock()
+	if builtin {
+		return simulationInfo{}, fmt.Errorf("simulation type %s is reserved by a builtin simulation", def.Type)
+	}
+
+	sim, err := compileCustomSimulation(def)
+	if err != nil {
+		return simulationInfo{}, err
+	}
+	if err := writeCustomSimulation(dir, sim.def); err != nil {
+		return simulationInfo{}, err
+	}
+	s.stopCustomSimulation(pCtx.OrgID, def.Type)
+	return newCustomSimInfo(sim), nil
+}
+
+func (s *SimulationEngine) deleteCustomSimulation(pCtx backend.PluginContext, simType string) error {
+	dir := s.customSimulationsDir(pCtx.OrgID)
+	if dir == "" {
+		return errSimulationNotFound
+	}
+	if err := deleteCustomSimulation(dir, simType); err != nil {
+		return err
+	}
+	s.stopCustomSimulation(pCtx.OrgID, simType)
+	return nil
+}
+
+func (s *SimulationEngine) stopCustomSimulation(orgID int64, simType string) {
+	prefix := customSimRunningKey(orgID, simType)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for key, sim := range s.running {
+		if strings.HasPrefix(key, prefix) {
+			if err := sim.Close(); err != nil {
+				s.logger.Warn("Failed to close simulation", "key", key, "error", err)
+			}
+			delete(s.running, key)
+		}
+	}
+}
+
+// listSimulations returns the builtin simulations and the simulations of the organization
+func (s *SimulationEngine) listSimulations(pCtx backend.PluginContext) ([]simulationInfo, error) {
+	s.mutex.Lock()
+	result := make([]simulationInfo, 0, len(s.registry))
+	for _, value := range s.registry {
+		result = append(result, value)
+	}
+	s.mutex.Unlock()
+
+	dir := s.customSimulationsDir(pCtx.OrgID)
+	if dir == "" {
+		return result, nil
+	}
+	defs, err := listCustomSimulations(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, def := range defs {
+		sim, err := compileCustomSimulation(def)
+		if err != nil {
+			s.logger.Warn("Invalid custom simulation", "type", def.Type, "error", err)
+			continue
+		}
+		result = append(result, newCustomSimInfo(sim))
+	}
+	return result, nil
+}
+
 type simulationQuery struct {
 	simulationState
 	Last   bool `json:"last"`
@@ -134,7 +275,7 @@ func (s *SimulationEngine) QueryData(ctx context.Context, req *backend.QueryData
 			sq.Key.TickHZ = 10
 		}
 
-		sim, err := s.Lookup(sq.simulationState)
+		sim, err := s.lookup(req.PluginContext, sq.simulationState)
 		if err != nil {
 			return nil, fmt.Errorf("error fetching simulation: %v", err)
 		}
@@ -178,7 +319,7 @@ func (s *SimulationEngine) QueryData(ctx context.Context, req *backend.QueryData
 	return resp, nil
 }
 
-func (s *SimulationEngine) getSimFromPath(path string) (Simulation, error) {
+func (s *SimulationEngine) getSimFromPath(pCtx backend.PluginContext, path string) (Simulation, error) {
 	idx := strings.Index(path, "sim/")
 	if idx >= 0 {
 		path = path[idx+4:]
@@ -208,22 +349,90 @@ func (s *SimulationEngine) getSimFromPath(path string) (Simulation, error) {
 		return nil, fmt.Errorf("path should match: %s", key.String())
 	}
 
-	return s.Lookup(simulationState{
+	return s.lookup(pCtx, simulationState{
 		Key: key,
 	})
 }
 
+// checkManageSimulations writes an error response and returns false when the
+// user can't manage the simulations
+func (s *SimulationEngine) checkManageSimulations(rw http.ResponseWriter, req *http.Request, pCtx backend.PluginContext) bool {
+	allowed, err := s.canManageSimulations(req.Context(), pCtx)
+	if err != nil {
+		s.logger.Error("Failed to check the permission to manage simulations", "error", err)
+		http.Error(rw, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+	if !allowed {
+		http.Error(rw, errManageSimulationsDenied.Error(), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
 func (s *SimulationEngine) GetSimulationHandler(rw http.ResponseWriter, req *http.Request) {
 	var result any
+	pCtx := httpadapter.PluginConfigFromContext(req.Context())
 	path := req.URL.Path
 	if path == "/sims" {
-		v := make([]simulationInfo, 0, len(s.registry))
-		for _, value := range s.registry {
-			v = append(v, value)
+		// With a POST, register a custom simulation
+		if req.Method == http.MethodPost {
+			if !s.checkManageSimulations(rw, req, pCtx) {
+				return
+			}
+			def := customSimulationDefinition{}
+			if err := json.NewDecoder(req.Body).Decode(&def); err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			info, err := s.saveCustomSimulation(pCtx, def)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusBadRequest)
+				return
+			}
+			result = info
+		} else {
+			v, err := s.listSimulations(pCtx)
+			if err != nil {
+				http.Error(rw, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			result = v
+		}
+	} else if strings.HasPrefix(path, "/sims/") {
+		simType := strings.TrimPrefix(path, "/sims/")
+		if req.Method == http.MethodDelete {
+			if !s.checkManageSimulations(rw, req, pCtx) {
+				return
+			}
+			if err := s.deleteCustomSimulation(pCtx, simType); err != nil {
+				status := http.StatusInternalServerError
+				if errors.Is(err, errSimulationNotFound) {
+					status = http.StatusNotFound
+				}
+				http.Error(rw, err.Error(), status)
+				return
+			}
+			result = map[string]any{"message": "Simulation deleted"}
+		} else {
+			var err error
+			dir := s.customSimulationsDir(pCtx.OrgID)
+			if dir == "" {
+				err = errSimulationNotFound
+			} else {
+				result, err = readCustomSimulation(dir, simType)
+			}
+			if err != nil {
+				status := http.StatusInternalServerError
+				if errors.Is(err, errSimulationNotFound) {
+					status = http.StatusNotFound
+				}
+				http.Error(rw, err.Error(), status)
+				return
+			}
 		}
-		result = v
 	} else if strings.HasPrefix(path, "/sim/") {
-		sim, err := s.getSimFromPath(path)
+		sim, err := s.getSimFromPath(pCtx, path)
 		if err != nil {
 			http.Error(rw, err.Error(), http.StatusNotFound)
 			return
@@ -256,7 +465,7 @@ func (s *SimulationEngine) GetSimulationHandler(rw http.ResponseWriter, req *htt
 }
 
 func (s *SimulationEngine) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
-	sim, err := s.getSimFromPath(req.Path) // includes sim
+	sim, err := s.getSimFromPath(req.PluginContext, req.Path) // includes sim
 	if err != nil {
 		return nil, err
 	}
@@ -272,7 +481,7 @@ func (s *SimulationEngine) SubscribeStream(ctx context.Context, req *backend.Sub
 }
 
 func (s *SimulationEngine) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
-	sim, err := s.getSimFromPath(req.Path) // includes sim
+	sim, err := s.getSimFromPath(req.PluginContext, req.Path) // includes sim
 	if err != nil {
 		return err
 	}
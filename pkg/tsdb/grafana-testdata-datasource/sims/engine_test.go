@@ -4,11 +4,12 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/stretchr/testify/require"
 )
 
 func TestCoreSimulationRegistry(t *testing.T) {
-	sims, err := NewSimulationEngine()
+	sims, err := NewSimulationEngine("", nil)
 	require.NoError(t, err)
 	v, err := sims.Lookup(simulationState{
 		Key: simulationKey{
@@ -40,25 +41,25 @@ func TestCoreSimulationRegistry(t *testing.T) {
 	}`, string(cfg))
 
 	path := v.GetState().Key.String()
-	found, err := sims.getSimFromPath("sim/" + path)
+	found, err := sims.getSimFromPath(backend.PluginContext{}, "sim/"+path)
 	require.NoError(t, err)
 	require.Equal(t, v, found)
 
-	found, err = sims.getSimFromPath("/sim/" + path)
+	found, err = sims.getSimFromPath(backend.PluginContext{}, "/sim/"+path)
 	require.NoError(t, err)
 	require.Equal(t, v, found)
 
-	found, err = sims.getSimFromPath(path)
+	found, err = sims.getSimFromPath(backend.PluginContext{}, path)
 	require.NoError(t, err)
 	require.Equal(t, v, found)
 
 	// In valid paths
-	_, err = sims.getSimFromPath("flight/1.00hz")
+	_, err = sims.getSimFromPath(backend.PluginContext{}, "flight/1.00hz")
 	require.Error(t, err)
 
-	_, err = sims.getSimFromPath("flight/1")
+	_, err = sims.getSimFromPath(backend.PluginContext{}, "flight/1")
 	require.Error(t, err)
 
-	_, err = sims.getSimFromPath("flight/1/")
+	_, err = sims.getSimFromPath(backend.PluginContext{}, "flight/1/")
 	require.Error(t, err)
 }
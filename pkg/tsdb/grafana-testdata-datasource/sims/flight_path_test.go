@@ -12,7 +12,7 @@ import (
 )
 
 func TestFlightPathQuery(t *testing.T) {
-	s, err := NewSimulationEngine()
+	s, err := NewSimulationEngine("", nil)
 	require.NoError(t, err)
 
 	t.Run("simple flight", func(t *testing.T) {
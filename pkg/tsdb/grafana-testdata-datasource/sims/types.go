@@ -38,6 +38,9 @@ type simulationInfo struct {
 	OnlyForward  bool        `json:"forward"`
 	ConfigFields *data.Frame `json:"config"`
 
+	// Definition of the simulations registered by users
+	Custom *customSimulationDefinition `json:"custom,omitempty"`
+
 	// Create a simulation instance
 	create func(q simulationState) (Simulation, error)
 }
@@ -13,6 +13,32 @@ import (
 )
 
 func ProvideService() *Service {
+	return NewService(Settings{})
+}
+
+// Settings are the directories of the server used by the TestData data source.
+type Settings struct {
+	// RecordingsPath is the directory of the query responses replayed by the
+	// replay scenario, replays are disabled when empty.
+	RecordingsPath string
+	// SimulationsPath is the directory of the custom simulations, custom
+	// simulations are disabled when empty.
+	SimulationsPath string
+	// Authorizer checks the permissions of the users of the server, replays
+	// and managing custom simulations are denied when nil.
+	Authorizer Authorizer
+}
+
+// Authorizer checks the permissions of the user of a request on the
+// resources of the server.
+type Authorizer interface {
+	sims.Authorizer
+	// CanQueryDatasource returns true when the user of the request can query
+	// the data source with the given UID.
+	CanQueryDatasource(ctx context.Context, pCtx backend.PluginContext, uid string) (bool, error)
+}
+
+func NewService(settings Settings) *Service {
 	s := &Service{
 		queryMux:  datasource.NewQueryTypeMux(),
 		scenarios: map[string]*Scenario{},
@@ -27,11 +53,13 @@ func ProvideService() *Service {
 			data.NewField("Time", nil, make([]time.Time, 1)),
 			data.NewField("Value", nil, make([]float64, 1)),
 		),
-		logger: backend.NewLoggerWith("logger", "tsdb.testdata"),
+		logger:         backend.NewLoggerWith("logger", "tsdb.testdata"),
+		recordingsPath: settings.RecordingsPath,
+		authorizer:     settings.Authorizer,
 	}
 
 	var err error
-	s.sims, err = sims.NewSimulationEngine()
+	s.sims, err = sims.NewSimulationEngine(settings.SimulationsPath, settings.Authorizer)
 	if err != nil {
 		s.logger.Error("Unable to initialize SimulationEngine", "err", err)
 	}
@@ -50,6 +78,8 @@ type Service struct {
 	queryMux        *datasource.QueryTypeMux
 	resourceHandler backend.CallResourceHandler
 	sims            *sims.SimulationEngine
+	recordingsPath  string
+	authorizer      Authorizer
 }
 
 func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
@@ -36,6 +36,12 @@ type Service struct {
 	tracer tracing.Tracer
 }
 
+var (
+	_ backend.QueryDataHandler    = (*Service)(nil)
+	_ backend.CallResourceHandler = (*Service)(nil)
+	_ backend.CheckHealthHandler  = (*Service)(nil)
+)
+
 const (
 	TargetFullModelField = "targetFull"
 	TargetModelField     = "target"
@@ -49,9 +55,21 @@ func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Trace
 }
 
 type datasourceInfo struct {
-	HTTPClient *http.Client
-	URL        string
-	Id         int64
+	HTTPClient   *http.Client
+	URL          string
+	Id           int64
+	RenderFormat string
+}
+
+type jsonData struct {
+	// RenderFormat is the format requested from the render API, json, msgpack or pickle.
+	RenderFormat string `json:"renderFormat"`
+}
+
+// queryTarget associates the series of a target with its query.
+type queryTarget struct {
+	refID string
+	tags  targetTags
 }
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -66,10 +84,24 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jd := jsonData{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jd); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+		if jd.RenderFormat == "" {
+			jd.RenderFormat = renderFormatJSON
+		}
+		if !validRenderFormat(jd.RenderFormat) {
+			return nil, fmt.Errorf("invalid render format %q", jd.RenderFormat)
+		}
+
 		model := datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
-			Id:         settings.ID,
+			HTTPClient:   client,
+			URL:          settings.URL,
+			Id:           settings.ID,
+			RenderFormat: jd.RenderFormat,
 		}
 
 		return model, nil
@@ -109,13 +141,13 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	formData := url.Values{
 		"from":          []string{from},
 		"until":         []string{until},
-		"format":        []string{"json"},
+		"format":        []string{dsInfo.RenderFormat},
 		"maxDataPoints": []string{"500"},
 		"target":        []string{},
 	}
 
 	// Convert datasource query to graphite target request
-	targetList, emptyQueries, origRefIds, err := s.processQueries(logger, req.Queries)
+	targetList, emptyQueries, refTargets, err := s.processQueries(logger, req.Queries)
 	if err != nil {
 		return nil, err
 	}
@@ -169,7 +201,7 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		}
 	}()
 
-	frames, err := s.toDataFrames(logger, res, origRefIds)
+	frames, err := s.toDataFrames(logger, res, dsInfo.RenderFormat, refTargets)
 	if err != nil {
 		span.RecordError(err)
 		span.SetStatus(codes.Error, err.Error())
@@ -196,10 +228,11 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 
 // processQueries converts each datasource query to a graphite query target. It returns the list of
 // targets, a list of invalid queries, and a mapping of formatted refIds (used in the target query)
-// to original query refIds, later used to associate ressponses with the original queries
-func (s *Service) processQueries(logger log.Logger, queries []backend.DataQuery) ([]string, []string, map[string]string, error) {
+// to original query refIds and the tags parsed from the target, later used to associate responses
+// with the original queries
+func (s *Service) processQueries(logger log.Logger, queries []backend.DataQuery) ([]string, []string, map[string]queryTarget, error) {
 	emptyQueries := make([]string, 0)
-	origRefIds := make(map[string]string, 0)
+	refTargets := make(map[string]queryTarget, 0)
 	targets := make([]string, 0)
 
 	for _, query := range queries {
@@ -226,17 +259,17 @@ func (s *Service) processQueries(logger log.Logger, queries []backend.DataQuery)
 		// And the original refId. Since there are no restrictions on refId, we need to format it to make it
 		// easy to find in the response
 		formattedRefId := strings.ReplaceAll(query.RefID, " ", "_")
-		origRefIds[formattedRefId] = query.RefID
+		refTargets[formattedRefId] = queryTarget{refID: query.RefID, tags: newTargetTags(target)}
 		// This will set the alias to `<resolvedSeriesName> <formattedRefId>`
 		// e.g. aliasSub(alias(myquery, "foo"), "(^.*$)", "\1 A") will return "foo A"
 		target = fmt.Sprintf("aliasSub(%s,\"(^.*$)\",\"\\1 %s\")", target, formattedRefId)
 		targets = append(targets, target)
 	}
 
-	return targets, emptyQueries, origRefIds, nil
+	return targets, emptyQueries, refTargets, nil
 }
 
-func (s *Service) parseResponse(logger log.Logger, res *http.Response) ([]TargetResponseDTO, error) {
+func (s *Service) parseResponse(logger log.Logger, res *http.Response, format string) ([]TargetResponseDTO, error) {
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
@@ -252,18 +285,18 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response) ([]Target
 		return nil, fmt.Errorf("request failed, status: %s", res.Status)
 	}
 
-	var data []TargetResponseDTO
-	err = json.Unmarshal(body, &data)
+	format = responseRenderFormat(res.Header.Get("Content-Type"), format)
+	data, err := decodeRenderResponse(format, body)
 	if err != nil {
-		logger.Info("Failed to unmarshal graphite response", "error", err, "status", res.Status, "body", string(body))
+		logger.Info("Failed to decode graphite response", "error", err, "format", format, "status", res.Status)
 		return nil, err
 	}
 
 	return data, nil
 }
 
-func (s *Service) toDataFrames(logger log.Logger, response *http.Response, origRefIds map[string]string) (frames data.Frames, error error) {
-	responseData, err := s.parseResponse(logger, response)
+func (s *Service) toDataFrames(logger log.Logger, response *http.Response, format string, refTargets map[string]queryTarget) (frames data.Frames, error error) {
+	responseData, err := s.parseResponse(logger, response, format)
 	if err != nil {
 		return nil, err
 	}
@@ -279,10 +312,10 @@ func (s *Service) toDataFrames(logger log.Logger, response *http.Response, origR
 		}
 		target := series.Target[:ls]
 		formattedRefId := series.Target[ls+1:]
-		refId, ok := origRefIds[formattedRefId]
+		query, ok := refTargets[formattedRefId]
 		if !ok {
 			logger.Warn("Unable to find refId associated with provided formattedRefId", "formattedRefId", formattedRefId)
-			refId = formattedRefId // fallback - shouldn't happen except for in tests
+			query.refID = formattedRefId // fallback - shouldn't happen except for in tests
 		}
 
 		for _, dataPoint := range series.DataPoints {
@@ -301,10 +334,19 @@ func (s *Service) toDataFrames(logger log.Logger, response *http.Response, origR
 				tags[name] = value
 			case float64:
 				tags[name] = strconv.FormatFloat(value, 'f', -1, 64)
+			case int64:
+				tags[name] = strconv.FormatInt(value, 10)
+			}
+		}
+		// Tags are not returned by all formats and Graphite versions.
+		if len(tags) == 0 {
+			for name, value := range tagsFromName(target) {
+				tags[name] = value
 			}
 		}
+		query.tags.apply(tags, target)
 
-		frames = append(frames, data.NewFrame(refId,
+		frames = append(frames, data.NewFrame(query.refID,
 			data.NewField("time", nil, timeVector),
 			data.NewField("value", tags, values).SetConfig(&data.FieldConfig{DisplayNameFromDS: target})))
 
@@ -0,0 +1,57 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel/codes"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	logger := logger.FromContext(ctx).New("endpoint", "CheckHealth")
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return getHealthCheckMessage(fmt.Errorf("failed to get datasource information: %w", err), logger), err
+	}
+
+	ctx, span := s.tracer.Start(ctx, "datasource.graphite.CheckHealth")
+	defer span.End()
+
+	// Same request the query editor uses to list root metrics.
+	resp, err := resourceProxy.Get(ctx, dsInfo.HTTPClient, dsInfo.URL, "metrics/find?query=*", logger, s.tracer)
+	if err == nil && resp.Status != http.StatusOK {
+		err = fmt.Errorf("request failed, status: %d", resp.Status)
+	}
+	if err == nil {
+		var metrics []json.RawMessage
+		if jsonErr := json.Unmarshal(resp.Body, &metrics); jsonErr != nil {
+			err = fmt.Errorf("invalid metrics response: %w", jsonErr)
+		}
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return getHealthCheckMessage(err, logger), nil
+}
+
+func getHealthCheckMessage(err error, logger log.Logger) *backend.CheckHealthResult {
+	if err == nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusOk,
+			Message: "Data source is working",
+		}
+	}
+
+	logger.Error("Graphite health check failed", "error", err)
+	return &backend.CheckHealthResult{
+		Status:  backend.HealthStatusError,
+		Message: "Unable to connect with Graphite. Please check the server logs for more details.",
+	}
+}
@@ -0,0 +1,411 @@
+package graphite
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+var errPickleTruncated = errors.New("pickle: unexpected end of data")
+
+// pickleList is a list on the unpickler stack. Lists are referenced by pointer
+// so items appended after memoizing are visible through the memo.
+type pickleList struct {
+	items []any
+}
+
+// pickleMark separates the stack items of MARK opcodes.
+type pickleMark struct{}
+
+// unpickler decodes the subset of the pickle protocols 2 to 5 used by the
+// Graphite pickle render format: lists, dicts, tuples, strings, numbers,
+// booleans and None. Opcodes creating objects, like GLOBAL and REDUCE, are
+// not supported, so decoding never runs code.
+type unpickler struct {
+	data  []byte
+	pos   int
+	stack []any
+	memo  map[int]any
+}
+
+// maxPickleDepth limits the nesting of decoded values, memoized lists can
+// reference themselves.
+const maxPickleDepth = 32
+
+func decodePickle(data []byte) (any, error) {
+	u := &unpickler{data: data, memo: map[int]any{}}
+	v, err := u.load()
+	if err != nil {
+		return nil, err
+	}
+	// Memoized values can be referenced many times, the number of values is
+	// limited so small payloads cannot expand to huge results.
+	w := &pickleUnwrapper{budget: 4*len(data) + 1024}
+	return w.unwrap(v, 0)
+}
+
+type pickleUnwrapper struct {
+	budget int
+}
+
+// unwrap replaces pickle lists by slices.
+func (w *pickleUnwrapper) unwrap(v any, depth int) (any, error) {
+	if depth > maxPickleDepth {
+		return nil, errors.New("pickle: maximum nesting depth exceeded")
+	}
+	w.budget--
+	if w.budget < 0 {
+		return nil, errors.New("pickle: too many values")
+	}
+	var items []any
+	switch v := v.(type) {
+	case *pickleList:
+		items = v.items
+	case []any:
+		items = v
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, item := range v {
+			item, err := w.unwrap(item, depth+1)
+			if err != nil {
+				return nil, err
+			}
+			m[k] = item
+		}
+		return m, nil
+	default:
+		return v, nil
+	}
+	res := make([]any, len(items))
+	for i, item := range items {
+		item, err := w.unwrap(item, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		res[i] = item
+	}
+	return res, nil
+}
+
+func (u *unpickler) read(n int) ([]byte, error) {
+	if n < 0 || len(u.data)-u.pos < n {
+		return nil, errPickleTruncated
+	}
+	b := u.data[u.pos : u.pos+n]
+	u.pos += n
+	return b, nil
+}
+
+// readUint reads a little endian unsigned integer of size bytes.
+func (u *unpickler) readUint(size int) (uint64, error) {
+	b, err := u.read(size)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for i := size - 1; i >= 0; i-- {
+		n = n<<8 | uint64(b[i])
+	}
+	return n, nil
+}
+
+// readString reads a string with a length prefix of size bytes.
+func (u *unpickler) readString(size int) (string, error) {
+	n, err := u.readUint(size)
+	if err != nil {
+		return "", err
+	}
+	if n > uint64(len(u.data)-u.pos) {
+		return "", errPickleTruncated
+	}
+	b, err := u.read(int(n))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+func (u *unpickler) push(v any) {
+	u.stack = append(u.stack, v)
+}
+
+func (u *unpickler) pop() (any, error) {
+	if len(u.stack) == 0 {
+		return nil, errors.New("pickle: stack underflow")
+	}
+	v := u.stack[len(u.stack)-1]
+	u.stack = u.stack[:len(u.stack)-1]
+	if _, ok := v.(pickleMark); ok {
+		return nil, errors.New("pickle: unexpected mark")
+	}
+	return v, nil
+}
+
+func (u *unpickler) top() (any, error) {
+	if len(u.stack) == 0 {
+		return nil, errors.New("pickle: stack underflow")
+	}
+	return u.stack[len(u.stack)-1], nil
+}
+
+// popMark returns the items pushed since the last mark.
+func (u *unpickler) popMark() ([]any, error) {
+	for i := len(u.stack) - 1; i >= 0; i-- {
+		if _, ok := u.stack[i].(pickleMark); ok {
+			items := append([]any{}, u.stack[i+1:]...)
+			u.stack = u.stack[:i]
+			return items, nil
+		}
+	}
+	return nil, errors.New("pickle: mark not found")
+}
+
+func (u *unpickler) appendItems(items ...any) error {
+	v, err := u.top()
+	if err != nil {
+		return err
+	}
+	l, ok := v.(*pickleList)
+	if !ok {
+		return fmt.Errorf("pickle: cannot append to %T", v)
+	}
+	l.items = append(l.items, items...)
+	return nil
+}
+
+func (u *unpickler) setItems(items ...any) error {
+	if len(items)%2 != 0 {
+		return errors.New("pickle: odd number of dict items")
+	}
+	v, err := u.top()
+	if err != nil {
+		return err
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return fmt.Errorf("pickle: cannot set items of %T", v)
+	}
+	for i := 0; i < len(items); i += 2 {
+		key, ok := items[i].(string)
+		if !ok {
+			return fmt.Errorf("pickle: unsupported dict key type %T", items[i])
+		}
+		m[key] = items[i+1]
+	}
+	return nil
+}
+
+func (u *unpickler) tuple(n int) error {
+	if len(u.stack) < n {
+		return errors.New("pickle: stack underflow")
+	}
+	items := append([]any{}, u.stack[len(u.stack)-n:]...)
+	u.stack = u.stack[:len(u.stack)-n]
+	u.push(items)
+	return nil
+}
+
+//nolint:gocyclo
+func (u *unpickler) load() (any, error) {
+	for {
+		b, err := u.read(1)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op := b[0]; op {
+		case 0x80: // PROTO
+			if _, err := u.read(1); err != nil {
+				return nil, err
+			}
+		case 0x95: // FRAME
+			if _, err := u.read(8); err != nil {
+				return nil, err
+			}
+		case '.': // STOP
+			return u.pop()
+		case '(': // MARK
+			u.push(pickleMark{})
+		case ']': // EMPTY_LIST
+			u.push(&pickleList{})
+		case '}': // EMPTY_DICT
+			u.push(map[string]any{})
+		case ')': // EMPTY_TUPLE
+			u.push([]any{})
+		case 'l': // LIST
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			u.push(&pickleList{items: items})
+		case 't': // TUPLE
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			u.push(items)
+		case 0x85, 0x86, 0x87: // TUPLE1, TUPLE2, TUPLE3
+			if err := u.tuple(int(op - 0x84)); err != nil {
+				return nil, err
+			}
+		case 'a': // APPEND
+			v, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			if err := u.appendItems(v); err != nil {
+				return nil, err
+			}
+		case 'e': // APPENDS
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			if err := u.appendItems(items...); err != nil {
+				return nil, err
+			}
+		case 's': // SETITEM
+			v, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			k, err := u.pop()
+			if err != nil {
+				return nil, err
+			}
+			if err := u.setItems(k, v); err != nil {
+				return nil, err
+			}
+		case 'u': // SETITEMS
+			items, err := u.popMark()
+			if err != nil {
+				return nil, err
+			}
+			if err := u.setItems(items...); err != nil {
+				return nil, err
+			}
+		case 'N': // NONE
+			u.push(nil)
+		case 0x88: // NEWTRUE
+			u.push(true)
+		case 0x89: // NEWFALSE
+			u.push(false)
+		case 'K', 'M': // BININT1, BININT2
+			size := 1
+			if op == 'M' {
+				size = 2
+			}
+			n, err := u.readUint(size)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int64(n))
+		case 'J': // BININT
+			n, err := u.readUint(4)
+			if err != nil {
+				return nil, err
+			}
+			u.push(int64(int32(n)))
+		case 0x8a, 0x8b: // LONG1, LONG4
+			size := 1
+			if op == 0x8b {
+				size = 4
+			}
+			n, err := u.readUint(size)
+			if err != nil {
+				return nil, err
+			}
+			if n > uint64(len(u.data)-u.pos) {
+				return nil, errPickleTruncated
+			}
+			b, err := u.read(int(n))
+			if err != nil {
+				return nil, err
+			}
+			u.push(decodePickleLong(b))
+		case 'G': // BINFLOAT
+			b, err := u.read(8)
+			if err != nil {
+				return nil, err
+			}
+			u.push(math.Float64frombits(binary.BigEndian.Uint64(b)))
+		case 'X', 'T', 'B': // BINUNICODE, BINSTRING, BINBYTES
+			s, err := u.readString(4)
+			if err != nil {
+				return nil, err
+			}
+			u.push(s)
+		case 0x8c, 'U', 'C': // SHORT_BINUNICODE, SHORT_BINSTRING, SHORT_BINBYTES
+			s, err := u.readString(1)
+			if err != nil {
+				return nil, err
+			}
+			u.push(s)
+		case 0x8d, 0x8e: // BINUNICODE8, BINBYTES8
+			s, err := u.readString(8)
+			if err != nil {
+				return nil, err
+			}
+			u.push(s)
+		case 0x94: // MEMOIZE
+			v, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			u.memo[len(u.memo)] = v
+		case 'q', 'r': // BINPUT, LONG_BINPUT
+			size := 1
+			if op == 'r' {
+				size = 4
+			}
+			idx, err := u.readUint(size)
+			if err != nil {
+				return nil, err
+			}
+			v, err := u.top()
+			if err != nil {
+				return nil, err
+			}
+			u.memo[int(idx)] = v
+		case 'h', 'j': // BINGET, LONG_BINGET
+			size := 1
+			if op == 'j' {
+				size = 4
+			}
+			idx, err := u.readUint(size)
+			if err != nil {
+				return nil, err
+			}
+			v, ok := u.memo[int(idx)]
+			if !ok {
+				return nil, fmt.Errorf("pickle: memo key %d not found", idx)
+			}
+			u.push(v)
+		default:
+			return nil, fmt.Errorf("pickle: unsupported opcode 0x%x", op)
+		}
+	}
+}
+
+// decodePickleLong decodes a little endian two's complement integer. Values
+// not fitting in an int64 are returned as float64.
+func decodePickleLong(b []byte) any {
+	if len(b) == 0 {
+		return int64(0)
+	}
+	be := make([]byte, len(b))
+	for i := range b {
+		be[len(b)-1-i] = b[i]
+	}
+	n := new(big.Int).SetBytes(be)
+	if b[len(b)-1]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	if n.IsInt64() {
+		return n.Int64()
+	}
+	f, _ := new(big.Float).SetInt(n).Float64()
+	return f
+}
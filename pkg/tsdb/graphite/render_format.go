@@ -0,0 +1,127 @@
+package graphite
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"mime"
+
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/tsdb/legacydata"
+)
+
+// Render formats requested from Graphite. msgpack and pickle are faster to
+// decode than JSON for large responses.
+const (
+	renderFormatJSON    = "json"
+	renderFormatMsgpack = "msgpack"
+	renderFormatPickle  = "pickle"
+)
+
+func validRenderFormat(format string) bool {
+	switch format {
+	case renderFormatJSON, renderFormatMsgpack, renderFormatPickle:
+		return true
+	}
+	return false
+}
+
+// responseRenderFormat returns the format of a render response, servers
+// ignoring the requested format usually return JSON.
+func responseRenderFormat(contentType string, requested string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return requested
+	}
+	switch mediaType {
+	case "application/json":
+		return renderFormatJSON
+	case "application/x-msgpack", "application/msgpack":
+		return renderFormatMsgpack
+	case "application/pickle", "application/python-pickle":
+		return renderFormatPickle
+	}
+	return requested
+}
+
+func decodeRenderResponse(format string, body []byte) ([]TargetResponseDTO, error) {
+	var (
+		series any
+		err    error
+	)
+	switch format {
+	case renderFormatMsgpack:
+		series, err = decodeMsgpack(body)
+	case renderFormatPickle:
+		series, err = decodePickle(body)
+	default:
+		var data []TargetResponseDTO
+		if err := json.Unmarshal(body, &data); err != nil {
+			return nil, err
+		}
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return seriesInfoToTargetResponse(series)
+}
+
+// seriesInfoToTargetResponse converts the series of the msgpack and pickle
+// formats, lists of maps with name, start, step, values and optional tags.
+func seriesInfoToTargetResponse(v any) ([]TargetResponseDTO, error) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("invalid render response, expected a list of series but got %T", v)
+	}
+
+	res := make([]TargetResponseDTO, 0, len(list))
+	for _, item := range list {
+		info, ok := item.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid render response, expected a series but got %T", item)
+		}
+		name, ok := info["name"].(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid render response, series without name")
+		}
+		start, ok := toFloat(info["start"])
+		if !ok {
+			return nil, fmt.Errorf("invalid render response, series %q without start", name)
+		}
+		step, ok := toFloat(info["step"])
+		if !ok || step <= 0 {
+			return nil, fmt.Errorf("invalid render response, series %q without step", name)
+		}
+		values, ok := info["values"].([]any)
+		if !ok {
+			return nil, fmt.Errorf("invalid render response, series %q without values", name)
+		}
+
+		points := make(legacydata.DataTimeSeriesPoints, 0, len(values))
+		for i, value := range values {
+			point := legacydata.DataTimePoint{null.FloatFromPtr(nil), null.FloatFrom(start + float64(i)*step)}
+			if f, ok := toFloat(value); ok && !math.IsNaN(f) {
+				point[0] = null.FloatFrom(f)
+			}
+			points = append(points, point)
+		}
+
+		series := TargetResponseDTO{Target: name, DataPoints: points}
+		if tags, ok := info["tags"].(map[string]any); ok {
+			series.Tags = tags
+		}
+		res = append(res, series)
+	}
+	return res, nil
+}
+
+func toFloat(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
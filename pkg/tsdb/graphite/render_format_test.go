@@ -0,0 +1,158 @@
+package graphite
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/httpclient"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+)
+
+// Generated with pickle.dumps(series, protocol=n) in Python 3 from:
+//
+//	[{'name': 'cpu.usage;dc=eu;host=a', 'start': 1000, 'end': 1180, 'step': 60, 'values': [1.5, None, 3],
+//	  'pathExpression': "seriesByTag('name=cpu.usage')", 'valuesPerPoint': 1, 'consolidationFunc': 'average', 'xFilesFactor': 0.0},
+//	 {'name': 'mem A', 'start': 1000, 'end': 1120, 'step': 60, 'values': [2**70, -5], 'tags': {'name': 'mem', 'size': 4}}]
+const (
+	pickleProtocol2 = "gAJdcQAofXEBKFgEAAAAbmFtZXECWBYAAABjcHUudXNhZ2U7ZGM9ZXU7aG9zdD1hcQNYBQAAAHN0YXJ0cQRN6ANYAwAAAGVuZHEFTZwEWAQAAABzdGVwcQZLPFgGAAAAdmFsdWVzcQddcQgoRz/4AAAAAAAATksDZVgOAAAAcGF0aEV4cHJlc3Npb25xCVgdAAAAc2VyaWVzQnlUYWcoJ25hbWU9Y3B1LnVzYWdlJylxClgOAAAAdmFsdWVzUGVyUG9pbnRxC0sBWBEAAABjb25zb2xpZGF0aW9uRnVuY3EMWAcAAABhdmVyYWdlcQ1YDAAAAHhGaWxlc0ZhY3RvcnEORwAAAAAAAAAAdX1xDyhoAlgFAAAAbWVtIEFxEGgETegDaAVNYARoBks8aAddcREoigkAAAAAAAAAAEBK+////2VYBAAAAHRhZ3NxEn1xEyhoAlgDAAAAbWVtcRRYBAAAAHNpemVxFUsEdXVlLg=="
+	pickleProtocol5 = "gAWVJwEAAAAAAABdlCh9lCiMBG5hbWWUjBZjcHUudXNhZ2U7ZGM9ZXU7aG9zdD1hlIwFc3RhcnSUTegDjANlbmSUTZwEjARzdGVwlEs8jAZ2YWx1ZXOUXZQoRz/4AAAAAAAATksDZYwOcGF0aEV4cHJlc3Npb26UjB1zZXJpZXNCeVRhZygnbmFtZT1jcHUudXNhZ2UnKZSMDnZhbHVlc1BlclBvaW50lEsBjBFjb25zb2xpZGF0aW9uRnVuY5SMB2F2ZXJhZ2WUjAx4RmlsZXNGYWN0b3KURwAAAAAAAAAAdX2UKGgCjAVtZW0gQZRoBE3oA2gFTWAEaAZLPGgHXZQoigkAAAAAAAAAAEBK+////2WMBHRhZ3OUfZQoaAKMA21lbZSMBHNpemWUSwR1dWUu"
+)
+
+// MessagePack of:
+//
+//	[{'name': 'cpu.usage;dc=eu;host=a A', 'start': 1000, 'end': 1180, 'step': 60, 'values': [1.5, None, 3]},
+//	 {'name': 'mem B', 'start': 1000, 'end': 1120, 'step': 60, 'values': [-5, 70000], 'tags': {'name': 'mem', 'size': 4}}]
+const msgpackSeries = "koWkbmFtZbhjcHUudXNhZ2U7ZGM9ZXU7aG9zdD1hIEGlc3RhcnTNA+ijZW5kzQScpHN0ZXA8pnZhbHVlc5PLP/gAAAAAAADAA4akbmFtZaVtZW0gQqVzdGFydM0D6KNlbmTNBGCkc3RlcDymdmFsdWVzkvvOAAERcKR0YWdzgqRuYW1lo21lbaRzaXplBA=="
+
+func mustDecodeBase64(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.StdEncoding.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+func TestDecodePickle(t *testing.T) {
+	for name, payload := range map[string]string{"protocol 2": pickleProtocol2, "protocol 5": pickleProtocol5} {
+		t.Run(name, func(t *testing.T) {
+			series, err := decodeRenderResponse(renderFormatPickle, mustDecodeBase64(t, payload))
+			require.NoError(t, err)
+			require.Len(t, series, 2)
+
+			require.Equal(t, "cpu.usage;dc=eu;host=a", series[0].Target)
+			require.Nil(t, series[0].Tags)
+			require.Len(t, series[0].DataPoints, 3)
+			require.Equal(t, 1.5, series[0].DataPoints[0][0].Float64)
+			require.Equal(t, float64(1000), series[0].DataPoints[0][1].Float64)
+			require.False(t, series[0].DataPoints[1][0].Valid)
+			require.Equal(t, float64(1060), series[0].DataPoints[1][1].Float64)
+			require.Equal(t, float64(3), series[0].DataPoints[2][0].Float64)
+
+			require.Equal(t, map[string]any{"name": "mem", "size": int64(4)}, series[1].Tags)
+			require.Equal(t, float64(1<<70), series[1].DataPoints[0][0].Float64)
+			require.Equal(t, float64(-5), series[1].DataPoints[1][0].Float64)
+		})
+	}
+
+	t.Run("rejects opcodes creating objects", func(t *testing.T) {
+		// pickle.dumps([datetime.date(2020, 1, 1)], protocol=2)
+		_, err := decodePickle(mustDecodeBase64(t, "gAJdcQBjZGF0ZXRpbWUKZGF0ZQpxAWNfY29kZWNzCmVuY29kZQpxAlgFAAAAB8OkAQFxA1gGAAAAbGF0aW4xcQSGcQVScQaFcQdScQhhLg=="))
+		require.ErrorContains(t, err, "unsupported opcode 0x63")
+	})
+
+	t.Run("rejects recursive values", func(t *testing.T) {
+		// l = []; l.append(l); pickle.dumps(l, protocol=2)
+		_, err := decodePickle(mustDecodeBase64(t, "gAJdcQBoAGEu"))
+		require.ErrorContains(t, err, "maximum nesting depth exceeded")
+	})
+
+	t.Run("rejects truncated data", func(t *testing.T) {
+		payload := mustDecodeBase64(t, pickleProtocol2)
+		_, err := decodePickle(payload[:len(payload)-10])
+		require.Error(t, err)
+	})
+}
+
+func TestDecodeMsgpack(t *testing.T) {
+	series, err := decodeRenderResponse(renderFormatMsgpack, mustDecodeBase64(t, msgpackSeries))
+	require.NoError(t, err)
+	require.Len(t, series, 2)
+
+	require.Equal(t, "cpu.usage;dc=eu;host=a A", series[0].Target)
+	require.Len(t, series[0].DataPoints, 3)
+	require.Equal(t, 1.5, series[0].DataPoints[0][0].Float64)
+	require.False(t, series[0].DataPoints[1][0].Valid)
+	require.Equal(t, float64(1120), series[0].DataPoints[2][1].Float64)
+
+	require.Equal(t, map[string]any{"name": "mem", "size": int64(4)}, series[1].Tags)
+	require.Equal(t, float64(-5), series[1].DataPoints[0][0].Float64)
+	require.Equal(t, float64(70000), series[1].DataPoints[1][0].Float64)
+
+	t.Run("rejects invalid data", func(t *testing.T) {
+		_, err := decodeMsgpack([]byte{0xdd, 0xff, 0xff, 0xff, 0xff})
+		require.ErrorIs(t, err, errMsgpackTruncated)
+
+		_, err = decodeMsgpack([]byte{0xc1})
+		require.ErrorContains(t, err, "unsupported type")
+
+		_, err = decodeMsgpack([]byte{0x01, 0x02})
+		require.ErrorContains(t, err, "trailing data")
+
+		_, err = decodeRenderResponse(renderFormatMsgpack, []byte{0x91, 0x81, 0xa4, 'n', 'a', 'm', 'e', 0x01})
+		require.ErrorContains(t, err, "series without name")
+	})
+}
+
+func TestRenderFormatSetting(t *testing.T) {
+	factory := newInstanceSettings(httpclient.NewProvider())
+
+	instance, err := factory(context.Background(), backend.DataSourceInstanceSettings{URL: "http://localhost:8080"})
+	require.NoError(t, err)
+	require.Equal(t, renderFormatJSON, instance.(datasourceInfo).RenderFormat)
+
+	instance, err = factory(context.Background(), backend.DataSourceInstanceSettings{JSONData: []byte(`{"renderFormat":"pickle"}`)})
+	require.NoError(t, err)
+	require.Equal(t, renderFormatPickle, instance.(datasourceInfo).RenderFormat)
+
+	_, err = factory(context.Background(), backend.DataSourceInstanceSettings{JSONData: []byte(`{"renderFormat":"csv"}`)})
+	require.ErrorContains(t, err, `invalid render format "csv"`)
+}
+
+func TestResponseRenderFormat(t *testing.T) {
+	require.Equal(t, renderFormatJSON, responseRenderFormat("application/json; charset=utf-8", renderFormatPickle))
+	require.Equal(t, renderFormatPickle, responseRenderFormat("application/pickle", renderFormatJSON))
+	require.Equal(t, renderFormatMsgpack, responseRenderFormat("application/x-msgpack", renderFormatJSON))
+	require.Equal(t, renderFormatMsgpack, responseRenderFormat("", renderFormatMsgpack))
+}
+
+func TestConvertMsgpackResponse(t *testing.T) {
+	service := &Service{}
+	httpResponse := &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"application/x-msgpack"}},
+		Body:       io.NopCloser(strings.NewReader(string(mustDecodeBase64(t, msgpackSeries)))),
+	}
+	refTargets := map[string]queryTarget{
+		"A": {refID: "A", tags: newTargetTags("seriesByTag('name=cpu.usage', 'host=~a.*')")},
+		"B": {refID: "B", tags: newTargetTags("aliasByTags(seriesByTag('name=mem', 'dc=eu'), 'name')")},
+	}
+
+	frames, err := service.toDataFrames(logger, httpResponse, renderFormatJSON, refTargets)
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+
+	require.Equal(t, "A", frames[0].Name)
+	require.Equal(t, data.Labels{"name": "cpu.usage", "dc": "eu", "host": "a"}, frames[0].Fields[1].Labels)
+	require.Equal(t, "cpu.usage;dc=eu;host=a", frames[0].Fields[1].Config.DisplayNameFromDS)
+	require.Equal(t, time.Unix(1060, 0).UTC(), frames[0].Fields[0].At(1))
+
+	require.Equal(t, "B", frames[1].Name)
+	require.Equal(t, data.Labels{"name": "mem", "size": "4", "dc": "eu"}, frames[1].Fields[1].Labels)
+	require.Equal(t, "mem", frames[1].Fields[1].Config.DisplayNameFromDS)
+}
@@ -0,0 +1,34 @@
+package graphite
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/grafana/grafana/pkg/tsdb/httpresource"
+)
+
+// resourceProxy proxies Graphite API endpoints which can be called through CallResource.
+var resourceProxy = httpresource.Proxy{
+	Name: "graphite",
+	Paths: []string{
+		"metrics/find",
+		"metrics/expand",
+		"tags/autoComplete/tags",
+		"tags/autoComplete/values",
+		"functions",
+		"version",
+	},
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+	return resourceProxy.CallResource(ctx, req, sender, dsInfo.HTTPClient, dsInfo.URL, logger, s.tracer,
+		attribute.Int64("datasource_id", dsInfo.Id))
+}
@@ -0,0 +1,145 @@
+package graphite
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type testInstanceManager struct {
+	info datasourceInfo
+}
+
+func (m testInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.info, nil
+}
+
+func (m testInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+func newTestService(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &Service{
+		im:     testInstanceManager{info: datasourceInfo{HTTPClient: server.Client(), URL: server.URL + "/graphite"}},
+		tracer: tracing.InitializeTracerForTest(),
+	}
+}
+
+func TestCallResource(t *testing.T) {
+	t.Run("should proxy allowed resources to graphite", func(t *testing.T) {
+		var requestURL string
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			requestURL = r.URL.String()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`[{"text":"cpu","id":"cpu","expandable":1}]`))
+		})
+
+		sender := &fakeSender{}
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			URL:    "metrics/find?query=cpu.*&from=-1h",
+		}, sender)
+		require.NoError(t, err)
+		assert.Equal(t, "/graphite/metrics/find?query=cpu.*&from=-1h", requestURL)
+		assert.Equal(t, http.StatusOK, sender.resp.Status)
+		assert.JSONEq(t, `[{"text":"cpu","id":"cpu","expandable":1}]`, string(sender.resp.Body))
+	})
+
+	t.Run("should pass client errors through", func(t *testing.T) {
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "tag not found", http.StatusBadRequest)
+		})
+
+		sender := &fakeSender{}
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			URL:    "tags/autoComplete/values?tag=unknown",
+		}, sender)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, sender.resp.Status)
+	})
+
+	t.Run("should return an error for server errors", func(t *testing.T) {
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			URL:    "functions",
+		}, &fakeSender{})
+		require.Error(t, err)
+	})
+
+	t.Run("should reject unknown resources and methods", func(t *testing.T) {
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("unexpected request to graphite")
+		})
+
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			URL:    "render?target=foo",
+		}, &fakeSender{})
+		require.Error(t, err)
+
+		err = s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodPost,
+			URL:    "metrics/find",
+		}, &fakeSender{})
+		require.Error(t, err)
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	t.Run("should do a successful health check", func(t *testing.T) {
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/graphite/metrics/find", r.URL.Path)
+			assert.Equal(t, "*", r.URL.Query().Get("query"))
+			_, _ = w.Write([]byte(`[]`))
+		})
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("should return an error for an unsuccessful health check", func(t *testing.T) {
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		})
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+	})
+
+	t.Run("should return an error for an invalid response", func(t *testing.T) {
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`<html></html>`))
+		})
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+	})
+}
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
@@ -0,0 +1,289 @@
+package graphite
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// targetExpr is a node of a parsed Graphite target, either a function call,
+// a literal or a series path.
+type targetExpr struct {
+	// Func is the name of a function call.
+	Func string
+	Args []*targetExpr
+	// Key is the name of a keyword argument.
+	Key string
+	// Value is a string, number or boolean literal or a series path.
+	Value string
+	// Quoted is true for string literals.
+	Quoted bool
+}
+
+func (e *targetExpr) isNumber() bool {
+	if e.Func != "" || e.Quoted {
+		return false
+	}
+	_, err := strconv.ParseFloat(e.Value, 64)
+	return err == nil
+}
+
+// isLiteral is true for arguments which are not series.
+func (e *targetExpr) isLiteral() bool {
+	return e.Func == "" && (e.Quoted || e.isNumber() || e.Value == "true" || e.Value == "false")
+}
+
+type targetParser struct {
+	s   string
+	pos int
+}
+
+// parseTarget parses a Graphite target like the query editor does.
+func parseTarget(target string) (*targetExpr, error) {
+	p := &targetParser{s: target}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.s) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.s[p.pos], p.pos)
+	}
+	return expr, nil
+}
+
+func (p *targetParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *targetParser) peek() byte {
+	if p.pos < len(p.s) {
+		return p.s[p.pos]
+	}
+	return 0
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func (p *targetParser) parseExpr() (*targetExpr, error) {
+	p.skipSpace()
+	if c := p.peek(); c == '\'' || c == '"' {
+		return p.parseString()
+	}
+
+	start := p.pos
+	for p.pos < len(p.s) && isIdentChar(p.s[p.pos]) {
+		p.pos++
+	}
+	name := p.s[start:p.pos]
+	p.skipSpace()
+	if name != "" && p.peek() == '(' {
+		return p.parseCall(name)
+	}
+
+	p.pos = start
+	return p.parsePath()
+}
+
+func (p *targetParser) parseString() (*targetExpr, error) {
+	quote := p.s[p.pos]
+	p.pos++
+	var sb strings.Builder
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		p.pos++
+		switch {
+		case c == '\\' && p.pos < len(p.s):
+			sb.WriteByte(p.s[p.pos])
+			p.pos++
+		case c == quote:
+			return &targetExpr{Value: sb.String(), Quoted: true}, nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return nil, fmt.Errorf("unterminated string at position %d", p.pos)
+}
+
+// parsePath parses a series path or an unquoted literal. Paths can contain
+// commas in braces, like a.{b,c}.d.
+func (p *targetParser) parsePath() (*targetExpr, error) {
+	start := p.pos
+	depth := 0
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if depth == 0 && (c == ',' || c == ')') {
+			break
+		}
+		switch c {
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+		case '(':
+			return nil, fmt.Errorf("unexpected ( at position %d", p.pos)
+		}
+		p.pos++
+	}
+	value := strings.TrimSpace(p.s[start:p.pos])
+	if value == "" {
+		return nil, fmt.Errorf("missing expression at position %d", start)
+	}
+	return &targetExpr{Value: value}, nil
+}
+
+func (p *targetParser) parseCall(name string) (*targetExpr, error) {
+	// Skip the opening parenthesis.
+	p.pos++
+	expr := &targetExpr{Func: name}
+	p.skipSpace()
+	if p.peek() == ')' {
+		p.pos++
+		return expr, nil
+	}
+
+	for {
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		expr.Args = append(expr.Args, arg)
+
+		p.skipSpace()
+		switch p.peek() {
+		case ',':
+			p.pos++
+		case ')':
+			p.pos++
+			return expr, nil
+		default:
+			return nil, fmt.Errorf("missing ) of %s", name)
+		}
+	}
+}
+
+// parseArg parses a function argument, which can be a keyword argument.
+func (p *targetParser) parseArg() (*targetExpr, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && isIdentChar(p.s[p.pos]) {
+		p.pos++
+	}
+	key := p.s[start:p.pos]
+	p.skipSpace()
+	if key != "" && p.peek() == '=' {
+		p.pos++
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		arg.Key = key
+		return arg, nil
+	}
+	p.pos = start
+	return p.parseExpr()
+}
+
+// targetTags are the tags known from a target. They are added to the series
+// labels when Graphite does not return the tags, like in the msgpack and
+// pickle formats or for series renamed by aliasByTags.
+type targetTags struct {
+	// exact are the tags matched with = by seriesByTag, all series have them.
+	exact map[string]string
+	// alias are the aliasByTags arguments, empty for node arguments.
+	alias []string
+}
+
+func newTargetTags(target string) targetTags {
+	var tt targetTags
+	expr, err := parseTarget(target)
+	if err != nil {
+		return tt
+	}
+
+	// The name of series renamed by aliasByTags are the tag values joined by dots.
+	if expr.Func == "aliasByTags" && len(expr.Args) > 1 {
+		for _, arg := range expr.Args[1:] {
+			if arg.isNumber() {
+				tt.alias = append(tt.alias, "")
+			} else {
+				tt.alias = append(tt.alias, arg.Value)
+			}
+		}
+	}
+
+	// Functions keep the tags of the series they get, unless they combine
+	// series of different queries.
+	for expr.Func != "" && expr.Func != "seriesByTag" && len(expr.Args) > 0 {
+		for _, arg := range expr.Args[1:] {
+			if !arg.isLiteral() {
+				return tt
+			}
+		}
+		expr = expr.Args[0]
+	}
+	if expr.Func != "seriesByTag" {
+		return tt
+	}
+
+	for _, arg := range expr.Args {
+		if !arg.Quoted {
+			continue
+		}
+		key, value, ok := strings.Cut(arg.Value, "=")
+		// Only exact matches, not !=, =~ or !=~.
+		if !ok || key == "" || strings.HasSuffix(key, "!") || strings.HasPrefix(value, "~") {
+			continue
+		}
+		if tt.exact == nil {
+			tt.exact = map[string]string{}
+		}
+		tt.exact[strings.TrimSpace(key)] = value
+	}
+	return tt
+}
+
+// apply adds the tags known from the target to the labels of a series.
+func (tt targetTags) apply(labels map[string]string, name string) {
+	for k, v := range tt.exact {
+		if _, ok := labels[k]; !ok {
+			labels[k] = v
+		}
+	}
+	if len(tt.alias) == 0 {
+		return
+	}
+	parts := strings.Split(name, ".")
+	if len(parts) != len(tt.alias) {
+		return
+	}
+	for i, tag := range tt.alias {
+		if _, ok := labels[tag]; tag != "" && !ok {
+			labels[tag] = parts[i]
+		}
+	}
+}
+
+// tagsFromName parses the tags of a tagged series name like
+// cpu.usage;host=a;dc=eu. The name is returned as the name tag.
+func tagsFromName(name string) map[string]string {
+	// Names of series processed by functions are like scale(cpu;host=a,2).
+	if !strings.Contains(name, ";") || strings.ContainsAny(name, "()") {
+		return nil
+	}
+	parts := strings.Split(name, ";")
+	tags := map[string]string{"name": parts[0]}
+	for _, part := range parts[1:] {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok || k == "" {
+			return nil
+		}
+		tags[k] = v
+	}
+	return tags
+}
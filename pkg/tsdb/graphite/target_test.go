@@ -0,0 +1,82 @@
+package graphite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	t.Run("function calls, literals and paths", func(t *testing.T) {
+		expr, err := parseTarget(`aliasByNode(summarize(app.{a,b}.count, "1h", func='sum', alignToFrom=true), 1, -2)`)
+		require.NoError(t, err)
+		require.Equal(t, &targetExpr{Func: "aliasByNode", Args: []*targetExpr{
+			{Func: "summarize", Args: []*targetExpr{
+				{Value: "app.{a,b}.count"},
+				{Value: "1h", Quoted: true},
+				{Key: "func", Value: "sum", Quoted: true},
+				{Key: "alignToFrom", Value: "true"},
+			}},
+			{Value: "1"},
+			{Value: "-2"},
+		}}, expr)
+	})
+
+	t.Run("escaped quotes", func(t *testing.T) {
+		expr, err := parseTarget(`seriesByTag('name=it\'s')`)
+		require.NoError(t, err)
+		require.Equal(t, "name=it's", expr.Args[0].Value)
+	})
+
+	t.Run("invalid targets", func(t *testing.T) {
+		for _, target := range []string{"sum(a.b", "sum(a.b))", "sum('a)", "sum(a,)", "a(b"} {
+			_, err := parseTarget(target)
+			require.Error(t, err, target)
+		}
+	})
+}
+
+func TestTargetTags(t *testing.T) {
+	t.Run("exact seriesByTag matches", func(t *testing.T) {
+		tt := newTargetTags("scale(seriesByTag('name=cpu', 'dc=eu', 'host=~web.*', 'env!=dev', 'rack!=~a.*'), 2)")
+		require.Equal(t, map[string]string{"name": "cpu", "dc": "eu"}, tt.exact)
+		require.Empty(t, tt.alias)
+	})
+
+	t.Run("functions combining queries", func(t *testing.T) {
+		tt := newTargetTags("divideSeries(seriesByTag('name=a'), seriesByTag('name=b'))")
+		require.Empty(t, tt.exact)
+	})
+
+	t.Run("aliasByTags", func(t *testing.T) {
+		tt := newTargetTags("aliasByTags(seriesByTag('name=cpu'), 'host', 1, 'dc')")
+		require.Equal(t, []string{"host", "", "dc"}, tt.alias)
+
+		labels := map[string]string{}
+		tt.apply(labels, "web1.cpu.eu")
+		require.Equal(t, map[string]string{"name": "cpu", "host": "web1", "dc": "eu"}, labels)
+
+		// Names not matching the arguments are ignored.
+		labels = map[string]string{}
+		tt.apply(labels, "web1.cpu")
+		require.Equal(t, map[string]string{"name": "cpu"}, labels)
+	})
+
+	t.Run("returned tags are kept", func(t *testing.T) {
+		labels := map[string]string{"name": "cpu", "dc": "us"}
+		newTargetTags("seriesByTag('name=cpu', 'dc=eu')").apply(labels, "cpu;dc=us")
+		require.Equal(t, map[string]string{"name": "cpu", "dc": "us"}, labels)
+	})
+
+	t.Run("untagged and invalid targets", func(t *testing.T) {
+		require.Equal(t, targetTags{}, newTargetTags("sumSeries(app.*.count)"))
+		require.Equal(t, targetTags{}, newTargetTags("sumSeries(app.*.count"))
+	})
+}
+
+func TestTagsFromName(t *testing.T) {
+	require.Equal(t, map[string]string{"name": "cpu.usage", "host": "a", "dc": "eu"}, tagsFromName("cpu.usage;host=a;dc=eu"))
+	require.Nil(t, tagsFromName("cpu.usage"))
+	require.Nil(t, tagsFromName("scale(cpu;host=a,2)"))
+	require.Nil(t, tagsFromName("cpu;host"))
+}
@@ -0,0 +1,131 @@
+// Package httpresource implements resource calls of data sources which proxy
+// an allowlist of GET endpoints of their HTTP API.
+package httpresource
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// Response is a response of the data source HTTP API.
+type Response struct {
+	Body        []byte
+	Status      int
+	ContentType string
+}
+
+// Proxy proxies resource calls to the HTTP API of a data source.
+type Proxy struct {
+	// Name of the data source type, used in spans and logs.
+	Name string
+	// Paths lists the endpoints which can be called through CallResource,
+	// relative to Prefix.
+	Paths []string
+	// Prefix is joined to the path of the data source URL.
+	Prefix string
+}
+
+// CallResource sends a GET request to an allowed endpoint and sends the
+// response back.
+func (p Proxy) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender, client *http.Client, baseURL string, plog log.Logger, tracer tracing.Tracer, attrs ...attribute.KeyValue) error {
+	if req.Method != http.MethodGet {
+		plog.Error("Invalid HTTP method", "method", req.Method)
+		return fmt.Errorf("invalid HTTP method: %s", req.Method)
+	}
+	if !p.IsAllowed(req.URL) {
+		plog.Error("Invalid URL", "url", req.URL)
+		return fmt.Errorf("invalid URL: %s", req.URL)
+	}
+
+	attrs = append([]attribute.KeyValue{
+		attribute.String("url", req.URL),
+		attribute.Int64("org_id", req.PluginContext.OrgID),
+	}, attrs...)
+	ctx, span := tracer.Start(ctx, "datasource."+p.Name+".CallResource", trace.WithAttributes(attrs...))
+	defer span.End()
+
+	resp, err := p.Get(ctx, client, baseURL, req.URL, plog, tracer)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		plog.Error("Failed resource call from "+p.Name, "error", err, "url", req.URL)
+		return err
+	}
+	span.SetAttributes(attribute.Int(p.Name+".response.code", resp.Status))
+
+	return sender.Send(&backend.CallResourceResponse{
+		Status: resp.Status,
+		Headers: map[string][]string{
+			"content-type": {resp.ContentType},
+		},
+		Body: resp.Body,
+	})
+}
+
+// IsAllowed returns true when the path of the resource URL is allowed.
+func (p Proxy) IsAllowed(resourceURL string) bool {
+	resourcePath, _, _ := strings.Cut(resourceURL, "?")
+	for _, allowed := range p.Paths {
+		if resourcePath == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Get sends GET request to the data source HTTP API. Server errors are
+// returned as errors, other responses are returned as they are.
+func (p Proxy) Get(ctx context.Context, client *http.Client, baseURL string, resourceURL string, plog log.Logger, tracer tracing.Tracer) (Response, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return Response{}, err
+	}
+	resourcePath, rawQuery, _ := strings.Cut(resourceURL, "?")
+	u.Path = path.Join(u.Path, p.Prefix, resourcePath)
+	u.RawQuery = rawQuery
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to create request: %w", err)
+	}
+	tracer.Inject(ctx, req.Header, trace.SpanFromContext(ctx))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return Response{}, err
+	}
+	defer func() {
+		if err := res.Body.Close(); err != nil {
+			plog.Warn("Failed to close response body", "error", err)
+		}
+	}()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return Response{}, err
+	}
+
+	if res.StatusCode/100 == 5 {
+		plog.Info("Request failed", "status", res.Status, "body", string(body))
+		return Response{}, fmt.Errorf("request failed, status: %s", res.Status)
+	}
+
+	contentType := res.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	return Response{Body: body, Status: res.StatusCode, ContentType: contentType}, nil
+}
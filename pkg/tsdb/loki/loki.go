@@ -24,6 +24,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/services/featuremgmt"
 	ngalertmodels "github.com/grafana/grafana/pkg/services/ngalert/models"
+	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 	"github.com/grafana/grafana/pkg/tsdb/loki/kinds/dataquery"
 )
 
@@ -66,6 +67,8 @@ type datasourceInfo struct {
 	HTTPClient *http.Client
 	URL        string
 
+	splitting querySplitting
+
 	// open streams
 	streams   map[string]data.FrameJSONCache
 	streamsMu sync.RWMutex
@@ -75,6 +78,14 @@ type QueryJSONModel struct {
 	dataquery.LokiDataQuery
 	Direction           *string `json:"direction,omitempty"`
 	SupportingQueryType *string `json:"supportingQueryType"`
+	SplitDuration       *string `json:"splitDuration,omitempty"`
+}
+
+type jsonData struct {
+	// QuerySplitDuration is the duration of the time chunks range queries are
+	// split into, like 1d. Queries are not split when it is empty.
+	QuerySplitDuration    string `json:"querySplitDuration"`
+	QuerySplitConcurrency int    `json:"querySplitConcurrency"`
 }
 
 type ResponseOpts struct {
@@ -100,9 +111,25 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jd := jsonData{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jd); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+
+		splitting := querySplitting{Concurrency: jd.QuerySplitConcurrency}
+		if jd.QuerySplitDuration != "" {
+			splitting.Duration, err = intervalv2.ParseIntervalStringToTimeDuration(jd.QuerySplitDuration)
+			if err != nil {
+				return nil, fmt.Errorf("invalid query split duration: %w", err)
+			}
+		}
+
 		model := &datasourceInfo{
 			HTTPClient: client,
 			URL:        settings.URL,
+			splitting:  splitting,
 			streams:    make(map[string]data.FrameJSONCache),
 		}
 		return model, nil
@@ -209,7 +236,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		resultLock := sync.Mutex{}
 		err = concurrency.ForEachJob(ctx, len(queries), 10, func(ctx context.Context, idx int) error {
 			query := queries[idx]
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeSplitQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog, dsInfo.splitting)
 
 			resultLock.Lock()
 			defer resultLock.Unlock()
@@ -218,7 +245,7 @@ func queryData(ctx context.Context, req *backend.QueryDataRequest, dsInfo *datas
 		})
 	} else {
 		for _, query := range queries {
-			queryRes := executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+			queryRes := executeSplitQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog, dsInfo.splitting)
 			result.Responses[query.RefID] = queryRes
 		}
 	}
@@ -64,6 +64,15 @@ func interpolateVariables(expr string, interval time.Duration, timeRange time.Du
 	return expr
 }
 
+func hasRangeVariable(expr string) bool {
+	for _, v := range []string{varRange, varRangeAlt, varRangeSAlt, varRangeMsAlt} {
+		if strings.Contains(expr, v) {
+			return true
+		}
+	}
+	return false
+}
+
 func parseQueryType(jsonPointerValue *string) (QueryType, error) {
 	if jsonPointerValue == nil {
 		// there are older queries stored in alerting that did not have queryType,
@@ -169,6 +178,14 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 			return nil, err
 		}
 
+		var splitDuration time.Duration
+		if model.SplitDuration != nil && *model.SplitDuration != "" {
+			splitDuration, err = intervalv2.ParseIntervalStringToTimeDuration(*model.SplitDuration)
+			if err != nil {
+				return nil, fmt.Errorf("invalid splitDuration: %w", err)
+			}
+		}
+
 		qs = append(qs, &lokiQuery{
 			Expr:                expr,
 			QueryType:           queryType,
@@ -180,6 +197,8 @@ func parseQuery(queryContext *backend.QueryDataRequest) ([]*lokiQuery, error) {
 			End:                 end,
 			RefID:               query.RefID,
 			SupportingQueryType: supportingQueryType,
+			SplitDuration:       splitDuration,
+			SupportsSplitting:   queryType == QueryTypeRange && !hasRangeVariable(model.Expr),
 		})
 	}
 
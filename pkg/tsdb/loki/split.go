@@ -0,0 +1,314 @@
+package loki
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/dskit/concurrency"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+// querySplitting configures the splitting of range queries into time chunks,
+// like the frontend does, so long queries of alerting and public dashboards
+// do not time out.
+type querySplitting struct {
+	// Duration of the chunks of queries without their own split duration, 0
+	// disables splitting of these queries.
+	Duration time.Duration
+	// Concurrency is the number of chunks queried at the same time.
+	Concurrency int
+}
+
+const defaultQuerySplitConcurrency = 4
+
+// the name of the stat summed up when merging the frames of chunks
+const totalBytesProcessedStat = "Summary: total bytes processed"
+
+// isLogsQuery is true for queries returning log lines, metric queries start
+// with a function or an aggregation.
+func isLogsQuery(expr string) bool {
+	return strings.HasPrefix(strings.TrimSpace(expr), "{")
+}
+
+// splitLogsTimeRange splits a time range into chunks of the given duration.
+// Loki includes the start but not the end of log queries, so chunks can
+// share their boundaries. The potentially smaller chunk is the oldest.
+func splitLogsTimeRange(start, end time.Time, duration time.Duration) [][2]time.Time {
+	if end.Sub(start) <= duration {
+		return [][2]time.Time{{start, end}}
+	}
+
+	var result [][2]time.Time
+	for chunkEnd := end; chunkEnd.After(start); chunkEnd = chunkEnd.Add(-duration) {
+		chunkStart := chunkEnd.Add(-duration)
+		if chunkStart.Before(start) {
+			chunkStart = start
+		}
+		result = append([][2]time.Time{{chunkStart, chunkEnd}}, result...)
+	}
+	return result
+}
+
+// splitMetricTimeRange splits a time range into chunks aligned to the step,
+// compatible with the splitting of the Loki query frontend. Chunks are
+// separated by a step so no sample is returned twice.
+func splitMetricTimeRange(start, end time.Time, step, duration time.Duration) [][2]time.Time {
+	stepMs := step.Milliseconds()
+	if stepMs <= 0 || duration < step {
+		return [][2]time.Time{{start, end}}
+	}
+
+	alignedDuration := duration.Milliseconds() / stepMs * stepMs
+	startMs := start.UnixMilli() - start.UnixMilli()%stepMs
+	endMs := end.UnixMilli()
+	if mod := endMs % stepMs; mod != 0 {
+		endMs += stepMs - mod
+	}
+
+	var result [][2]time.Time
+	for chunkEnd := endMs; chunkEnd > startMs; chunkEnd -= alignedDuration + stepMs {
+		chunkStart := chunkEnd - alignedDuration
+		if chunkStart < startMs {
+			chunkStart = startMs
+		}
+		result = append([][2]time.Time{{time.UnixMilli(chunkStart), time.UnixMilli(chunkEnd)}}, result...)
+	}
+	return result
+}
+
+// executeSplitQuery runs a range query in time chunks and merges the frames
+// of the chunks. Queries not supporting splitting run in a single request.
+func executeSplitQuery(ctx context.Context, query *lokiQuery, req *backend.QueryDataRequest, runInParallel bool, api *LokiAPI, responseOpts ResponseOpts, tracer tracing.Tracer, plog log.Logger, splitting querySplitting) backend.DataResponse {
+	duration := query.SplitDuration
+	if duration <= 0 {
+		duration = splitting.Duration
+	}
+	if duration <= 0 || !query.SupportsSplitting {
+		return executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+	}
+
+	logsQuery := isLogsQuery(query.Expr)
+	var ranges [][2]time.Time
+	if logsQuery {
+		ranges = splitLogsTimeRange(query.Start, query.End, duration)
+	} else {
+		ranges = splitMetricTimeRange(query.Start, query.End, query.Step, duration)
+	}
+	if len(ranges) == 1 {
+		return executeQuery(ctx, query, req, runInParallel, api, responseOpts, tracer, plog)
+	}
+
+	concurrencyLimit := splitting.Concurrency
+	if concurrencyLimit <= 0 {
+		concurrencyLimit = defaultQuerySplitConcurrency
+	}
+
+	plog.Debug("Splitting query", "refId", query.RefID, "chunks", len(ranges), "chunkDuration", duration, "concurrency", concurrencyLimit)
+
+	if !logsQuery {
+		return runChunks(ctx, query, ranges, query.MaxLines, concurrencyLimit, func(ctx context.Context, chunk *lokiQuery) backend.DataResponse {
+			return executeQuery(ctx, chunk, req, runInParallel, api, responseOpts, tracer, plog)
+		})
+	}
+
+	// Log lines are returned in the order of the query direction, so the
+	// chunks are queried in that order, a batch at a time, until there are
+	// enough lines.
+	if query.Direction != DirectionForward {
+		for i, j := 0, len(ranges)-1; i < j; i, j = i+1, j-1 {
+			ranges[i], ranges[j] = ranges[j], ranges[i]
+		}
+	}
+
+	result := backend.DataResponse{}
+	for batchStart := 0; batchStart < len(ranges); batchStart += concurrencyLimit {
+		maxLines := query.MaxLines
+		if maxLines > 0 {
+			maxLines -= countRows(result.Frames)
+			if maxLines <= 0 {
+				break
+			}
+		}
+
+		batchEnd := batchStart + concurrencyLimit
+		if batchEnd > len(ranges) {
+			batchEnd = len(ranges)
+		}
+		res := runChunks(ctx, query, ranges[batchStart:batchEnd], maxLines, concurrencyLimit, func(ctx context.Context, chunk *lokiQuery) backend.DataResponse {
+			return executeQuery(ctx, chunk, req, runInParallel, api, responseOpts, tracer, plog)
+		})
+		if res.Error != nil {
+			return res
+		}
+		result.Frames = mergeFrames(result.Frames, res.Frames)
+	}
+
+	if query.MaxLines > 0 {
+		for _, frame := range result.Frames {
+			truncateFrame(frame, query.MaxLines)
+		}
+	}
+	return result
+}
+
+// runChunks queries the chunks concurrently and merges their frames in the
+// order of the chunks. The first chunk failing cancels the other ones.
+func runChunks(ctx context.Context, query *lokiQuery, ranges [][2]time.Time, maxLines int, concurrencyLimit int, run func(ctx context.Context, chunk *lokiQuery) backend.DataResponse) backend.DataResponse {
+	responses := make([]backend.DataResponse, len(ranges))
+	var mu sync.Mutex
+	err := concurrency.ForEachJob(ctx, len(ranges), concurrencyLimit, func(ctx context.Context, idx int) error {
+		chunk := *query
+		chunk.Start = ranges[idx][0]
+		chunk.End = ranges[idx][1]
+		chunk.MaxLines = maxLines
+		res := run(ctx, &chunk)
+
+		mu.Lock()
+		defer mu.Unlock()
+		responses[idx] = res
+		return res.Error
+	})
+	if err != nil {
+		// return the response of the failed chunk to keep its status
+		for _, res := range responses {
+			if res.Error == err {
+				return backend.DataResponse{Error: res.Error, Status: res.Status}
+			}
+		}
+		return backend.DataResponse{Error: err}
+	}
+
+	result := backend.DataResponse{}
+	for _, res := range responses {
+		result.Frames = mergeFrames(result.Frames, res.Frames)
+	}
+	return result
+}
+
+// mergeFrames appends the rows of the frames of a chunk to the frames of the
+// same series. Rows already returned by another chunk are skipped.
+func mergeFrames(dest data.Frames, source data.Frames) data.Frames {
+	for _, frame := range source {
+		key := frameKey(frame)
+		var target *data.Frame
+		for _, d := range dest {
+			if frameKey(d) == key {
+				target = d
+				break
+			}
+		}
+		if target == nil {
+			dest = append(dest, frame)
+			continue
+		}
+		appendFrame(target, frame)
+	}
+	return dest
+}
+
+// frameKey identifies the frames of the same series.
+func frameKey(frame *data.Frame) string {
+	var sb strings.Builder
+	sb.WriteString(frame.Name)
+	for _, field := range frame.Fields {
+		sb.WriteString("\x00")
+		sb.WriteString(field.Name)
+		sb.WriteString("\x00")
+		sb.WriteString(field.Type().String())
+		sb.WriteString("\x00")
+		sb.WriteString(field.Labels.String())
+	}
+	return sb.String()
+}
+
+// rowKeyField returns the index of the field identifying the rows of a
+// frame, the id of log lines or the time of samples.
+func rowKeyField(frame *data.Frame) int {
+	for i, field := range frame.Fields {
+		if field.Name == "id" && field.Type() == data.FieldTypeString {
+			return i
+		}
+	}
+	for i, field := range frame.Fields {
+		if field.Type() == data.FieldTypeTime {
+			return i
+		}
+	}
+	return -1
+}
+
+func appendFrame(dest *data.Frame, source *data.Frame) {
+	keyIdx := rowKeyField(dest)
+	seen := map[any]bool{}
+	if keyIdx >= 0 {
+		for i := 0; i < dest.Fields[keyIdx].Len(); i++ {
+			seen[rowKey(dest.Fields[keyIdx], i)] = true
+		}
+	}
+
+	for i := 0; i < source.Rows(); i++ {
+		if keyIdx >= 0 {
+			key := rowKey(source.Fields[keyIdx], i)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		dest.AppendRow(source.RowCopy(i)...)
+	}
+
+	if dest.Meta != nil && source.Meta != nil {
+		dest.Meta.Stats = mergeStats(dest.Meta.Stats, source.Meta.Stats)
+	}
+}
+
+func rowKey(field *data.Field, idx int) any {
+	v := field.At(idx)
+	if t, ok := v.(time.Time); ok {
+		return t.UnixNano()
+	}
+	return fmt.Sprint(v)
+}
+
+// mergeStats sums up the total bytes processed by the chunks, the other
+// stats are the ones of the first chunk.
+func mergeStats(dest []data.QueryStat, source []data.QueryStat) []data.QueryStat {
+	for _, s := range source {
+		if s.DisplayName != totalBytesProcessedStat {
+			continue
+		}
+		for i := range dest {
+			if dest[i].DisplayName == totalBytesProcessedStat {
+				dest[i].Value += s.Value
+			}
+		}
+	}
+	return dest
+}
+
+func countRows(frames data.Frames) int {
+	rows := 0
+	for _, frame := range frames {
+		rows += frame.Rows()
+	}
+	return rows
+}
+
+// truncateFrame keeps the first rows of a frame.
+func truncateFrame(frame *data.Frame, rows int) {
+	if frame.Rows() <= rows {
+		return
+	}
+	for _, field := range frame.Fields {
+		for field.Len() > rows {
+			field.Delete(field.Len() - 1)
+		}
+	}
+}
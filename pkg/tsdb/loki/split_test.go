@@ -0,0 +1,265 @@
+package loki
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+func TestSplitTimeRange(t *testing.T) {
+	t.Run("metric queries are split into chunks aligned to the step", func(t *testing.T) {
+		start := time.Date(2022, 2, 6, 14, 10, 3, 0, time.UTC)
+		end := time.Date(2022, 2, 6, 14, 11, 3, 0, time.UTC)
+		at := func(min, sec int) time.Time { return time.Date(2022, 2, 6, 14, min, sec, 0, time.UTC) }
+
+		ranges := splitMetricTimeRange(start, end, 10*time.Second, 25*time.Second)
+		require.Len(t, ranges, 3)
+		for i, expected := range [][2]time.Time{
+			{at(10, 0), at(10, 10)},
+			{at(10, 20), at(10, 40)},
+			{at(10, 50), at(11, 10)},
+		} {
+			require.True(t, expected[0].Equal(ranges[i][0]), "chunk %d start %s", i, ranges[i][0])
+			require.True(t, expected[1].Equal(ranges[i][1]), "chunk %d end %s", i, ranges[i][1])
+		}
+
+		ranges = splitMetricTimeRange(start, end, 10*time.Second, time.Second)
+		require.Equal(t, [][2]time.Time{{start, end}}, ranges)
+	})
+
+	t.Run("logs queries are split into chunks sharing their boundaries", func(t *testing.T) {
+		start := time.Unix(100, 0)
+		end := time.Unix(150, 0)
+
+		ranges := splitLogsTimeRange(start, end, 20*time.Second)
+		require.Equal(t, [][2]time.Time{
+			{time.Unix(100, 0), time.Unix(110, 0)},
+			{time.Unix(110, 0), time.Unix(130, 0)},
+			{time.Unix(130, 0), time.Unix(150, 0)},
+		}, ranges)
+
+		require.Equal(t, [][2]time.Time{{start, end}}, splitLogsTimeRange(start, end, time.Minute))
+	})
+}
+
+// fakeLoki answers range queries with a sample per step or a log line per
+// second of the requested range.
+type fakeLoki struct {
+	mu       sync.Mutex
+	requests [][2]int64
+	// the chunk starting at this second fails, when set
+	failStart int64
+}
+
+func (f *fakeLoki) RoundTrip(req *http.Request) (*http.Response, error) {
+	q := req.URL.Query()
+	start, _ := strconv.ParseInt(q.Get("start"), 10, 64)
+	end, _ := strconv.ParseInt(q.Get("end"), 10, 64)
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	f.mu.Lock()
+	f.requests = append(f.requests, [2]int64{start, end})
+	f.mu.Unlock()
+
+	startS := start / int64(time.Second)
+	endS := end / int64(time.Second)
+	if f.failStart != 0 && startS == f.failStart {
+		return &http.Response{
+			StatusCode: http.StatusBadRequest,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(`{"status":"error","error":"chunk failed"}`)),
+		}, nil
+	}
+	var body string
+	if strings.HasPrefix(q.Get("query"), "{") {
+		var values []string
+		// log queries include the start but not the end
+		for ts := endS - 1; ts >= startS; ts-- {
+			if limit > 0 && len(values) == limit {
+				break
+			}
+			values = append(values, fmt.Sprintf(`["%d", "line %d"]`, ts*int64(time.Second), ts))
+		}
+		body = fmt.Sprintf(`{"status":"success","data":{"resultType":"streams","result":[{"stream":{"app":"a"},"values":[%s]}],
+			"stats":{"summary":{"totalBytesProcessed":100}}}}`, strings.Join(values, ","))
+	} else {
+		step, _ := time.ParseDuration(q.Get("step"))
+		var values []string
+		for ts := startS; ts <= endS; ts += int64(step / time.Second) {
+			values = append(values, fmt.Sprintf(`[%d, "%d"]`, ts, ts))
+		}
+		body = fmt.Sprintf(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"app":"a"},"values":[%s]}]}}`, strings.Join(values, ","))
+	}
+
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+	}, nil
+}
+
+func runSplitQuery(t *testing.T, fake *fakeLoki, query lokiQuery, splitting querySplitting) backend.DataResponse {
+	t.Helper()
+	api := newLokiAPI(&http.Client{Transport: fake}, "http://localhost:3100", log.New("test"), tracing.InitializeTracerForTest())
+	return executeSplitQuery(context.Background(), &query, &backend.QueryDataRequest{}, false, api, ResponseOpts{}, tracing.InitializeTracerForTest(), log.New("test"), splitting)
+}
+
+func TestExecuteSplitQuery(t *testing.T) {
+	splitting := querySplitting{Duration: 10 * time.Second, Concurrency: 2}
+
+	t.Run("metric queries are merged into a single series", func(t *testing.T) {
+		fake := &fakeLoki{}
+		res := runSplitQuery(t, fake, lokiQuery{
+			Expr:              "rate({app=\"a\"}[1m])",
+			QueryType:         QueryTypeRange,
+			Direction:         DirectionBackward,
+			Step:              5 * time.Second,
+			Start:             time.Unix(100, 0),
+			End:               time.Unix(140, 0),
+			RefID:             "A",
+			SupportsSplitting: true,
+		}, splitting)
+		require.NoError(t, res.Error)
+		require.Len(t, fake.requests, 3)
+		require.Len(t, res.Frames, 1)
+
+		frame := res.Frames[0]
+		// a sample every 5s from 100 to 140
+		require.Equal(t, 9, frame.Rows())
+		for i := 1; i < frame.Rows(); i++ {
+			require.True(t, frame.Fields[0].At(i).(time.Time).After(frame.Fields[0].At(i-1).(time.Time)))
+		}
+	})
+
+	t.Run("logs queries stop when there are enough lines", func(t *testing.T) {
+		fake := &fakeLoki{}
+		res := runSplitQuery(t, fake, lokiQuery{
+			Expr:              "{app=\"a\"}",
+			QueryType:         QueryTypeRange,
+			Direction:         DirectionBackward,
+			MaxLines:          25,
+			Start:             time.Unix(100, 0),
+			End:               time.Unix(160, 0),
+			RefID:             "A",
+			SupportsSplitting: true,
+		}, splitting)
+		require.NoError(t, res.Error)
+		// the first batch returns 20 lines, the second one the missing 5
+		require.Len(t, fake.requests, 4)
+		require.Len(t, res.Frames, 1)
+
+		frame := res.Frames[0]
+		require.Equal(t, 25, frame.Rows())
+		lineField, _ := frame.FieldByName("Line")
+		require.Equal(t, "line 159", lineField.At(0))
+		require.Equal(t, "line 135", lineField.At(24))
+		require.Equal(t, float64(400), frame.Meta.Stats[2].Value)
+	})
+
+	t.Run("logs queries in forward direction start with the oldest chunk", func(t *testing.T) {
+		fake := &fakeLoki{}
+		res := runSplitQuery(t, fake, lokiQuery{
+			Expr:              "{app=\"a\"}",
+			QueryType:         QueryTypeRange,
+			Direction:         DirectionForward,
+			MaxLines:          5,
+			Start:             time.Unix(100, 0),
+			End:               time.Unix(160, 0),
+			RefID:             "A",
+			SupportsSplitting: true,
+		}, querySplitting{Duration: 10 * time.Second, Concurrency: 1})
+		require.NoError(t, res.Error)
+		require.Equal(t, [][2]int64{{100 * int64(time.Second), 110 * int64(time.Second)}}, fake.requests)
+		require.Equal(t, 5, res.Frames[0].Rows())
+	})
+
+	t.Run("queries are split by their own split duration", func(t *testing.T) {
+		fake := &fakeLoki{}
+		res := runSplitQuery(t, fake, lokiQuery{
+			Expr:              "rate({app=\"a\"}[1m])",
+			QueryType:         QueryTypeRange,
+			Step:              5 * time.Second,
+			Start:             time.Unix(100, 0),
+			End:               time.Unix(140, 0),
+			RefID:             "A",
+			SupportsSplitting: true,
+			SplitDuration:     20 * time.Second,
+		}, querySplitting{})
+		require.NoError(t, res.Error)
+		require.Len(t, fake.requests, 2)
+	})
+
+	t.Run("a failing chunk fails the query", func(t *testing.T) {
+		fake := &fakeLoki{failStart: 100}
+		res := runSplitQuery(t, fake, lokiQuery{
+			Expr:              "rate({app=\"a\"}[1m])",
+			QueryType:         QueryTypeRange,
+			Step:              5 * time.Second,
+			Start:             time.Unix(100, 0),
+			End:               time.Unix(140, 0),
+			RefID:             "A",
+			SupportsSplitting: true,
+		}, splitting)
+		require.Error(t, res.Error)
+		require.Empty(t, res.Frames)
+	})
+
+	t.Run("chunks are not queried when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		ranges := [][2]time.Time{{time.Unix(100, 0), time.Unix(110, 0)}, {time.Unix(110, 0), time.Unix(120, 0)}}
+		res := runChunks(ctx, &lokiQuery{}, ranges, 0, 2, func(ctx context.Context, chunk *lokiQuery) backend.DataResponse {
+			t.Fatal("chunk queried")
+			return backend.DataResponse{}
+		})
+		require.ErrorIs(t, res.Error, context.Canceled)
+	})
+
+	t.Run("queries not supporting splitting are not split", func(t *testing.T) {
+		fake := &fakeLoki{}
+		res := runSplitQuery(t, fake, lokiQuery{
+			Expr:      "rate({app=\"a\"}[$__range])",
+			QueryType: QueryTypeRange,
+			Step:      5 * time.Second,
+			Start:     time.Unix(100, 0),
+			End:       time.Unix(140, 0),
+			RefID:     "A",
+		}, splitting)
+		require.NoError(t, res.Error)
+		require.Len(t, fake.requests, 1)
+	})
+}
+
+func TestMergeFrames(t *testing.T) {
+	newFrame := func(labels data.Labels, times ...int64) *data.Frame {
+		values := make([]time.Time, len(times))
+		for i, ts := range times {
+			values[i] = time.Unix(ts, 0)
+		}
+		return data.NewFrame("",
+			data.NewField("time", nil, values),
+			data.NewField("value", labels, make([]float64, len(times))),
+		)
+	}
+
+	frames := mergeFrames(
+		data.Frames{newFrame(data.Labels{"app": "a"}, 1, 2)},
+		data.Frames{newFrame(data.Labels{"app": "a"}, 2, 3), newFrame(data.Labels{"app": "b"}, 3)},
+	)
+	require.Len(t, frames, 2)
+	require.Equal(t, 3, frames[0].Rows())
+	require.Equal(t, 1, frames[1].Rows())
+}
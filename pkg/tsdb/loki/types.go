@@ -38,4 +38,10 @@ type lokiQuery struct {
 	End                 time.Time
 	RefID               string
 	SupportingQueryType SupportingQueryType
+	// SplitDuration overrides the duration of the time chunks of the
+	// datasource query splitting.
+	SplitDuration time.Duration
+	// SupportsSplitting is false for instant queries and queries using the
+	// range variables, which would change with the time range of the chunks.
+	SupportsSplitting bool
 }
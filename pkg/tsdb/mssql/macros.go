@@ -13,6 +13,7 @@ import (
 
 const rsIdentifier = `([_a-zA-Z0-9]+)`
 const sExpr = `\$` + rsIdentifier + `\(([^\)]*)\)`
+const localTimeLayout = "2006-01-02T15:04:05"
 
 type msSQLMacroEngine struct {
 	*sqleng.SQLMacroEngineBase
@@ -91,6 +92,42 @@ func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 			return tg + " AS [time]", nil
 		}
 		return "", err
+	case "__timeBucket":
+		if len(args) < 2 {
+			return "", fmt.Errorf("macro %v needs time column and calendar unit", name)
+		}
+		unit, loc, err := sqleng.TimeBucketArgs(query, args[1])
+		if err != nil {
+			return "", err
+		}
+		start, _, err := sqleng.CalendarBuckets(timeRange, unit, loc)
+		if err != nil {
+			return "", err
+		}
+		offsets := sqleng.ZoneOffsets(loc, start, timeRange.To)
+		return toUTC(truncate(toLocal(args[0], offsets), unit), offsets), nil
+	case "__timeBucketAlias":
+		tb, err := m.evaluateMacro(timeRange, query, "__timeBucket", args)
+		if err == nil {
+			return tb + " AS [time]", nil
+		}
+		return "", err
+	case "__timeBucketFill":
+		if args[0] == "" {
+			return "", fmt.Errorf("missing calendar unit argument for macro %v", name)
+		}
+		unit, loc, err := sqleng.TimeBucketArgs(query, args[0])
+		if err != nil {
+			return "", err
+		}
+		start, count, err := sqleng.CalendarBuckets(timeRange, unit, loc)
+		if err != nil {
+			return "", err
+		}
+		offsets := sqleng.ZoneOffsets(loc, start, timeRange.To)
+		bucket := fmt.Sprintf("DATEADD(%s, n, CAST(%s AS datetime2))", unit, timeLiteral(start))
+		return fmt.Sprintf("(SELECT %s AS [time] FROM (SELECT TOP (%d) CAST(ROW_NUMBER() OVER (ORDER BY (SELECT NULL)) - 1 AS int) AS n FROM sys.all_objects a CROSS JOIN sys.all_objects b) AS buckets)",
+			toUTC(bucket, offsets), count), nil
 	case "__unixEpochFilter":
 		if len(args) == 0 {
 			return "", fmt.Errorf("missing time column argument for macro %v", name)
@@ -130,3 +167,33 @@ func (m *msSQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 		return "", fmt.Errorf("unknown macro %q", name)
 	}
 }
+
+// truncate returns the start of the calendar bucket of the time expression expr.
+// Day 0 is Monday 1900-01-01, so weeks start on Monday.
+func truncate(expr string, unit sqleng.CalendarUnit) string {
+	if unit == sqleng.CalendarUnitWeek {
+		return fmt.Sprintf("DATEADD(day, DATEDIFF(day, 0, %s) / 7 * 7, 0)", expr)
+	}
+	return fmt.Sprintf("DATEADD(%s, DATEDIFF(%s, 0, %s), 0)", unit, unit, expr)
+}
+
+// toLocal converts the UTC time expression expr to local time. Offsets are
+// computed by Grafana because SQL Server does not support IANA timezone names.
+func toLocal(expr string, offsets []sqleng.ZoneOffset) string {
+	if len(offsets) == 1 && offsets[0].Offset == 0 {
+		return expr
+	}
+	return fmt.Sprintf("DATEADD(second, %s, %s)", sqleng.ZoneOffsetExpr(expr, offsets, false, timeLiteral), expr)
+}
+
+// toUTC converts the local time expression expr to UTC.
+func toUTC(expr string, offsets []sqleng.ZoneOffset) string {
+	if len(offsets) == 1 && offsets[0].Offset == 0 {
+		return expr
+	}
+	return fmt.Sprintf("DATEADD(second, -(%s), %s)", sqleng.ZoneOffsetExpr(expr, offsets, true, timeLiteral), expr)
+}
+
+func timeLiteral(t time.Time) string {
+	return "'" + t.Format(localTimeLayout) + "'"
+}
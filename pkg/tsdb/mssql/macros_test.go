@@ -256,3 +256,43 @@ func TestMacroEngineConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMacroEngine_TimeBucket(t *testing.T) {
+	engine := &msSQLMacroEngine{}
+	timeRange := backend.TimeRange{
+		From: time.Date(2023, 3, 25, 12, 0, 0, 0, time.UTC),
+		To:   time.Date(2023, 3, 27, 12, 0, 0, 0, time.UTC),
+	}
+	query := &backend.DataQuery{JSON: []byte(`{"timezone":"Europe/Berlin"}`)}
+
+	t.Run("interpolate __timeBucket function in UTC", func(t *testing.T) {
+		sql, err := engine.Interpolate(&backend.DataQuery{}, timeRange, "$__timeBucket(time_column, 'month')")
+		require.NoError(t, err)
+		require.Equal(t, "DATEADD(month, DATEDIFF(month, 0, time_column), 0)", sql)
+
+		sql, err = engine.Interpolate(&backend.DataQuery{}, timeRange, "$__timeBucketAlias(time_column, week)")
+		require.NoError(t, err)
+		require.Equal(t, "DATEADD(day, DATEDIFF(day, 0, time_column) / 7 * 7, 0) AS [time]", sql)
+	})
+
+	t.Run("interpolate __timeBucket function across daylight saving time change", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "$__timeBucket(time_column, 'day')")
+		require.NoError(t, err)
+		local := "DATEADD(day, DATEDIFF(day, 0, DATEADD(second, CASE WHEN time_column < '2023-03-26T01:00:00' THEN 3600 ELSE 7200 END, time_column)), 0)"
+		require.Equal(t, "DATEADD(second, -(CASE WHEN "+local+" < '2023-03-26T02:00:00' THEN 3600 ELSE 7200 END), "+local+")", sql)
+	})
+
+	t.Run("interpolate __timeBucketFill function", func(t *testing.T) {
+		sql, err := engine.Interpolate(&backend.DataQuery{}, timeRange, "FROM $__timeBucketFill('day') b")
+		require.NoError(t, err)
+		require.Equal(t, "FROM (SELECT DATEADD(day, n, CAST('2023-03-25T00:00:00' AS datetime2)) AS [time] FROM "+
+			"(SELECT TOP (3) CAST(ROW_NUMBER() OVER (ORDER BY (SELECT NULL)) - 1 AS int) AS n FROM sys.all_objects a CROSS JOIN sys.all_objects b) AS buckets) b", sql)
+	})
+
+	t.Run("returns error for invalid arguments", func(t *testing.T) {
+		_, err := engine.Interpolate(query, timeRange, "$__timeBucket(time_column, '1d')")
+		require.Error(t, err)
+		_, err = engine.Interpolate(query, timeRange, "$__timeBucketFill()")
+		require.Error(t, err)
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
@@ -14,6 +15,12 @@ import (
 
 const rsIdentifier = `([_a-zA-Z0-9]+)`
 const sExpr = `\$` + rsIdentifier + `\(([^\)]*)\)`
+const localTimeLayout = "2006-01-02 15:04:05"
+
+// maxRecursiveBuckets is the max number of buckets of $__timeBucketFill. The
+// recursive CTE generating them is limited by cte_max_recursion_depth, 1000 by
+// default.
+const maxRecursiveBuckets = 1000
 
 var restrictedRegExp = regexp.MustCompile(`(?im)([\s]*show[\s]+grants|[\s,]session_user\([^\)]*\)|[\s,]current_user(\([^\)]*\))?|[\s,]system_user\([^\)]*\)|[\s,]user\([^\)]*\))([\s,;]|$)`)
 
@@ -102,6 +109,44 @@ func (m *mySQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 			return tg + " AS \"time\"", nil
 		}
 		return "", err
+	case "__timeBucket":
+		if len(args) < 2 {
+			return "", fmt.Errorf("macro %v needs time column and calendar unit", name)
+		}
+		unit, loc, err := sqleng.TimeBucketArgs(query, args[1])
+		if err != nil {
+			return "", err
+		}
+		start, _, err := sqleng.CalendarBuckets(timeRange, unit, loc)
+		if err != nil {
+			return "", err
+		}
+		offsets := sqleng.ZoneOffsets(loc, start, timeRange.To)
+		return toUTC(truncate(toLocal(args[0], offsets), unit), offsets), nil
+	case "__timeBucketAlias":
+		tb, err := m.evaluateMacro(timeRange, query, "__timeBucket", args)
+		if err == nil {
+			return tb + " AS \"time\"", nil
+		}
+		return "", err
+	case "__timeBucketFill":
+		if args[0] == "" {
+			return "", fmt.Errorf("missing calendar unit argument for macro %v", name)
+		}
+		unit, loc, err := sqleng.TimeBucketArgs(query, args[0])
+		if err != nil {
+			return "", err
+		}
+		start, count, err := sqleng.CalendarBuckets(timeRange, unit, loc)
+		if err != nil {
+			return "", err
+		}
+		if count > maxRecursiveBuckets {
+			return "", fmt.Errorf("macro %v supports at most %d buckets, the time range contains %d buckets of one %s", name, maxRecursiveBuckets, count, unit)
+		}
+		offsets := sqleng.ZoneOffsets(loc, start, timeRange.To)
+		return fmt.Sprintf("(WITH RECURSIVE buckets(t) AS (SELECT CAST('%s' AS DATETIME) UNION ALL SELECT t + INTERVAL 1 %s FROM buckets WHERE t < CAST('%s' AS DATETIME)) SELECT %s AS \"time\" FROM buckets)",
+			start.Format(localTimeLayout), strings.ToUpper(string(unit)), unit.Add(start, count-1).Format(localTimeLayout), toUTC("t", offsets)), nil
 	case "__unixEpochFilter":
 		if len(args) == 0 {
 			return "", fmt.Errorf("missing time column argument for macro %v", name)
@@ -141,3 +186,44 @@ func (m *mySQLMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *bac
 		return "", fmt.Errorf("unknown macro %v", name)
 	}
 }
+
+// truncate returns the start of the calendar bucket of the DATETIME expression expr.
+func truncate(expr string, unit sqleng.CalendarUnit) string {
+	switch unit {
+	case sqleng.CalendarUnitMinute:
+		return fmt.Sprintf("CAST(DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:%%i:00') AS DATETIME)", expr)
+	case sqleng.CalendarUnitHour:
+		return fmt.Sprintf("CAST(DATE_FORMAT(%s, '%%Y-%%m-%%d %%H:00:00') AS DATETIME)", expr)
+	case sqleng.CalendarUnitWeek:
+		return fmt.Sprintf("CAST(DATE_SUB(DATE(%s), INTERVAL WEEKDAY(%s) DAY) AS DATETIME)", expr, expr)
+	case sqleng.CalendarUnitMonth:
+		return fmt.Sprintf("CAST(DATE_FORMAT(%s, '%%Y-%%m-01') AS DATETIME)", expr)
+	case sqleng.CalendarUnitQuarter:
+		return fmt.Sprintf("CAST(MAKEDATE(YEAR(%s), 1) + INTERVAL QUARTER(%s)-1 QUARTER AS DATETIME)", expr, expr)
+	case sqleng.CalendarUnitYear:
+		return fmt.Sprintf("CAST(MAKEDATE(YEAR(%s), 1) AS DATETIME)", expr)
+	default:
+		return fmt.Sprintf("CAST(DATE(%s) AS DATETIME)", expr)
+	}
+}
+
+// toLocal converts the UTC DATETIME expression expr to local time. Offsets are
+// computed by Grafana, so MySQL does not need the timezone tables.
+func toLocal(expr string, offsets []sqleng.ZoneOffset) string {
+	if len(offsets) == 1 && offsets[0].Offset == 0 {
+		return expr
+	}
+	return fmt.Sprintf("DATE_ADD(%s, INTERVAL %s SECOND)", expr, sqleng.ZoneOffsetExpr(expr, offsets, false, timeLiteral))
+}
+
+// toUTC converts the local DATETIME expression expr to UTC.
+func toUTC(expr string, offsets []sqleng.ZoneOffset) string {
+	if len(offsets) == 1 && offsets[0].Offset == 0 {
+		return expr
+	}
+	return fmt.Sprintf("DATE_SUB(%s, INTERVAL %s SECOND)", expr, sqleng.ZoneOffsetExpr(expr, offsets, true, timeLiteral))
+}
+
+func timeLiteral(t time.Time) string {
+	return "'" + t.Format(localTimeLayout) + "'"
+}
@@ -223,3 +223,54 @@ func TestMacroEngineConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMacroEngine_TimeBucket(t *testing.T) {
+	engine := &mySQLMacroEngine{logger: log.New("test")}
+	timeRange := backend.TimeRange{
+		From: time.Date(2023, 3, 25, 12, 0, 0, 0, time.UTC),
+		To:   time.Date(2023, 3, 27, 12, 0, 0, 0, time.UTC),
+	}
+	query := &backend.DataQuery{JSON: []byte(`{"timezone":"Europe/Berlin"}`)}
+
+	t.Run("interpolate __timeBucket function in UTC", func(t *testing.T) {
+		for unit, expected := range map[string]string{
+			"minute":  "CAST(DATE_FORMAT(time_column, '%Y-%m-%d %H:%i:00') AS DATETIME)",
+			"hour":    "CAST(DATE_FORMAT(time_column, '%Y-%m-%d %H:00:00') AS DATETIME)",
+			"day":     "CAST(DATE(time_column) AS DATETIME)",
+			"week":    "CAST(DATE_SUB(DATE(time_column), INTERVAL WEEKDAY(time_column) DAY) AS DATETIME)",
+			"month":   "CAST(DATE_FORMAT(time_column, '%Y-%m-01') AS DATETIME)",
+			"quarter": "CAST(MAKEDATE(YEAR(time_column), 1) + INTERVAL QUARTER(time_column)-1 QUARTER AS DATETIME)",
+			"year":    "CAST(MAKEDATE(YEAR(time_column), 1) AS DATETIME)",
+		} {
+			sql, err := engine.Interpolate(&backend.DataQuery{}, timeRange, "$__timeBucket(time_column, '"+unit+"')")
+			require.NoError(t, err)
+			require.Equal(t, expected, sql, unit)
+		}
+	})
+
+	t.Run("interpolate __timeBucket function across daylight saving time change", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "$__timeBucketAlias(time_column, 'day')")
+		require.NoError(t, err)
+		local := "CAST(DATE(DATE_ADD(time_column, INTERVAL CASE WHEN time_column < '2023-03-26 01:00:00' THEN 3600 ELSE 7200 END SECOND)) AS DATETIME)"
+		require.Equal(t, "DATE_SUB("+local+", INTERVAL CASE WHEN "+local+" < '2023-03-26 02:00:00' THEN 3600 ELSE 7200 END SECOND) AS \"time\"", sql)
+	})
+
+	t.Run("interpolate __timeBucketFill function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "FROM $__timeBucketFill('day') b")
+		require.NoError(t, err)
+		require.Equal(t, "FROM (WITH RECURSIVE buckets(t) AS (SELECT CAST('2023-03-25 00:00:00' AS DATETIME) UNION ALL SELECT t + INTERVAL 1 DAY FROM buckets WHERE t < CAST('2023-03-27 00:00:00' AS DATETIME)) "+
+			"SELECT DATE_SUB(t, INTERVAL CASE WHEN t < '2023-03-26 02:00:00' THEN 3600 ELSE 7200 END SECOND) AS \"time\" FROM buckets) b", sql)
+	})
+
+	t.Run("returns error for __timeBucketFill with more buckets than the recursion depth", func(t *testing.T) {
+		_, err := engine.Interpolate(query, timeRange, "FROM $__timeBucketFill('minute') b")
+		require.ErrorContains(t, err, "at most 1000 buckets")
+	})
+
+	t.Run("returns error for invalid arguments", func(t *testing.T) {
+		_, err := engine.Interpolate(query, timeRange, "$__timeBucket(time_column, '1d')")
+		require.Error(t, err)
+		_, err = engine.Interpolate(query, timeRange, "$__timeBucketFill()")
+		require.Error(t, err)
+	})
+}
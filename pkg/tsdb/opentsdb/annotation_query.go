@@ -0,0 +1,54 @@
+package opentsdb
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// annotationQuery is an annotation query, which returns the annotations of
+// the series of a metric or the global annotations.
+type annotationQuery struct {
+	isGlobal bool
+}
+
+func (q *annotationQuery) toFrame(refID string, series []OpenTsdbResponse) *data.Frame {
+	var annotations []OpenTsdbAnnotation
+	// Every series has the global annotations, and series of the same time
+	// series can have the same annotations.
+	seen := map[string]bool{}
+	for _, val := range series {
+		list := val.Annotations
+		if q.isGlobal {
+			list = val.GlobalAnnotations
+		}
+		for _, a := range list {
+			key := fmt.Sprintf("%s/%d/%s", a.Tsuid, a.StartTime, a.Description)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			annotations = append(annotations, a)
+		}
+	}
+	sort.SliceStable(annotations, func(i, j int) bool {
+		return annotations[i].StartTime < annotations[j].StartTime
+	})
+
+	frame := data.NewFrame(refID,
+		data.NewField("time", nil, []time.Time{}),
+		data.NewField("timeEnd", nil, []*time.Time{}),
+		data.NewField("text", nil, []string{}),
+	)
+	for _, a := range annotations {
+		var timeEnd *time.Time
+		if a.EndTime > a.StartTime {
+			t := time.Unix(a.EndTime, 0).UTC()
+			timeEnd = &t
+		}
+		frame.AppendRow(time.Unix(a.StartTime, 0).UTC(), timeEnd, a.Description)
+	}
+	return frame
+}
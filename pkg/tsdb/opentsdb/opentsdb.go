@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -20,26 +21,52 @@ import (
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/httpclient"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/tracing"
 	"github.com/grafana/grafana/pkg/setting"
 )
 
 var logger = log.New("tsdb.opentsdb")
 
 type Service struct {
-	im instancemgmt.InstanceManager
+	im     instancemgmt.InstanceManager
+	tracer tracing.Tracer
 }
 
-func ProvideService(httpClientProvider httpclient.Provider) *Service {
+var (
+	_ backend.QueryDataHandler    = (*Service)(nil)
+	_ backend.CallResourceHandler = (*Service)(nil)
+	_ backend.CheckHealthHandler  = (*Service)(nil)
+)
+
+func ProvideService(httpClientProvider httpclient.Provider, tracer tracing.Tracer) *Service {
 	return &Service{
-		im: datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+		im:     datasource.NewInstanceManager(newInstanceSettings(httpClientProvider)),
+		tracer: tracer,
 	}
 }
 
 type datasourceInfo struct {
-	HTTPClient *http.Client
-	URL        string
+	HTTPClient     *http.Client
+	URL            string
+	TSDBVersion    int
+	TSDBResolution int
+}
+
+type jsonData struct {
+	TSDBVersion    int `json:"tsdbVersion"`
+	TSDBResolution int `json:"tsdbResolution"`
 }
 
+// Values of the tsdbVersion and tsdbResolution settings.
+const (
+	// tsdbVersion22 is OpenTSDB 2.2, which added filters.
+	tsdbVersion22 = 2
+	// tsdbVersion23 is OpenTSDB 2.3, which added showQuery and explicitTags.
+	tsdbVersion23 = 3
+
+	tsdbResolutionMilliseconds = 2
+)
+
 type DsAccess string
 
 func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.InstanceFactoryFunc {
@@ -54,28 +81,122 @@ func newInstanceSettings(httpClientProvider httpclient.Provider) datasource.Inst
 			return nil, err
 		}
 
+		jd := jsonData{}
+		if len(settings.JSONData) > 0 {
+			if err := json.Unmarshal(settings.JSONData, &jd); err != nil {
+				return nil, fmt.Errorf("error reading settings: %w", err)
+			}
+		}
+		if jd.TSDBVersion == 0 {
+			jd.TSDBVersion = 1
+		}
+
 		model := &datasourceInfo{
-			HTTPClient: client,
-			URL:        settings.URL,
+			HTTPClient:     client,
+			URL:            settings.URL,
+			TSDBVersion:    jd.TSDBVersion,
+			TSDBResolution: jd.TSDBResolution,
 		}
 
 		return model, nil
 	}
 }
 
-func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
-	var tsdbQuery OpenTsdbQuery
+// queryTarget is a query of the request and its sub query in the batched
+// OpenTSDB query.
+type queryTarget struct {
+	refID  string
+	metric map[string]any
+	// annotation is set for annotation queries.
+	annotation *annotationQuery
+}
 
+// QueryData runs the queries of a request with the same time range in a single
+// OpenTSDB query, the series are matched to their queries by the index
+// returned with showQuery. OpenTSDB versions before 2.3 don't support
+// showQuery, so every query is run in its own OpenTSDB query.
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
 	logger := logger.FromContext(ctx)
+	result := backend.NewQueryDataResponse()
 
-	q := req.Queries[0]
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
 
-	tsdbQuery.Start = q.TimeRange.From.UnixNano() / int64(time.Millisecond)
-	tsdbQuery.End = q.TimeRange.To.UnixNano() / int64(time.Millisecond)
+	var batches [][]backend.DataQuery
+	if dsInfo.TSDBVersion >= tsdbVersion23 {
+		batches = batchByTimeRange(req.Queries)
+	} else {
+		batches = batchByQuery(req.Queries)
+	}
+	for _, queries := range batches {
+		s.queryBatch(ctx, logger, dsInfo, queries, result)
+	}
 
-	for _, query := range req.Queries {
-		metric := s.buildMetric(query)
-		tsdbQuery.Queries = append(tsdbQuery.Queries, metric)
+	return result, nil
+}
+
+// batchByTimeRange groups queries by their time range, in the order of the
+// first query of each time range, as an OpenTSDB query has a single one.
+func batchByTimeRange(queries []backend.DataQuery) [][]backend.DataQuery {
+	type timeRange struct{ from, to int64 }
+	var batches [][]backend.DataQuery
+	index := map[timeRange]int{}
+	for _, query := range queries {
+		key := timeRange{from: query.TimeRange.From.UnixMilli(), to: query.TimeRange.To.UnixMilli()}
+		i, ok := index[key]
+		if !ok {
+			i = len(batches)
+			index[key] = i
+			batches = append(batches, nil)
+		}
+		batches[i] = append(batches[i], query)
+	}
+	return batches
+}
+
+// batchByQuery puts every query in its own batch, as the series of a batch
+// can't be matched to their queries without showQuery.
+func batchByQuery(queries []backend.DataQuery) [][]backend.DataQuery {
+	batches := make([][]backend.DataQuery, 0, len(queries))
+	for _, query := range queries {
+		batches = append(batches, []backend.DataQuery{query})
+	}
+	return batches
+}
+
+// queryBatch runs queries with the same time range and adds their responses to result.
+func (s *Service) queryBatch(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, queries []backend.DataQuery, result *backend.QueryDataResponse) {
+	q := queries[0]
+	tsdbQuery := OpenTsdbQuery{
+		Start:        q.TimeRange.From.UnixNano() / int64(time.Millisecond),
+		End:          q.TimeRange.To.UnixNano() / int64(time.Millisecond),
+		MsResolution: dsInfo.TSDBResolution == tsdbResolutionMilliseconds,
+		ShowQuery:    dsInfo.TSDBVersion >= tsdbVersion23,
+	}
+
+	targets := make([]*queryTarget, 0, len(queries))
+	for _, query := range queries {
+		target, err := s.buildQueryTarget(query, dsInfo.TSDBVersion)
+		if err != nil {
+			result.Responses[query.RefID] = backend.ErrDataResponse(backend.StatusBadRequest, err.Error())
+			continue
+		}
+		if target == nil {
+			result.Responses[query.RefID] = backend.DataResponse{}
+			continue
+		}
+		if target.annotation != nil && target.annotation.isGlobal {
+			tsdbQuery.GlobalAnnotations = true
+		}
+		targets = append(targets, target)
+		tsdbQuery.Queries = append(tsdbQuery.Queries, target.metric)
+	}
+
+	// No valid queries, save the round trip.
+	if len(targets) == 0 {
+		return
 	}
 
 	// TODO: Don't use global variable
@@ -83,34 +204,31 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 		logger.Debug("OpenTsdb request", "params", tsdbQuery)
 	}
 
-	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	queryResult, err := s.query(ctx, logger, dsInfo, tsdbQuery, targets)
 	if err != nil {
-		return nil, err
+		// All queries of the batch fail together.
+		for _, target := range targets {
+			result.Responses[target.refID] = backend.ErrDataResponse(backend.StatusBadRequest, err.Error())
+		}
+		return
+	}
+	for refID, res := range queryResult.Responses {
+		result.Responses[refID] = res
 	}
+}
 
+func (s *Service) query(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, tsdbQuery OpenTsdbQuery, targets []*queryTarget) (*backend.QueryDataResponse, error) {
 	request, err := s.createRequest(ctx, logger, dsInfo, tsdbQuery)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
 	res, err := dsInfo.HTTPClient.Do(request)
 	if err != nil {
-		return &backend.QueryDataResponse{}, err
-	}
-
-	defer func() {
-		err := res.Body.Close()
-		if err != nil {
-			logger.Warn("failed to close response body", "error", err)
-		}
-	}()
-
-	result, err := s.parseResponse(logger, res)
-	if err != nil {
-		return &backend.QueryDataResponse{}, err
+		return nil, err
 	}
 
-	return result, nil
+	return s.parseResponse(logger, res, targets, tsdbQuery)
 }
 
 func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *datasourceInfo, data OpenTsdbQuery) (*http.Request, error) {
@@ -136,7 +254,7 @@ func (s *Service) createRequest(ctx context.Context, logger log.Logger, dsInfo *
 	return req, nil
 }
 
-func (s *Service) parseResponse(logger log.Logger, res *http.Response) (*backend.QueryDataResponse, error) {
+func (s *Service) parseResponse(logger log.Logger, res *http.Response, targets []*queryTarget, tsdbQuery OpenTsdbQuery) (*backend.QueryDataResponse, error) {
 	resp := backend.NewQueryDataResponse()
 
 	body, err := io.ReadAll(res.Body)
@@ -151,6 +269,10 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response) (*backend
 
 	if res.StatusCode/100 != 2 {
 		logger.Info("Request failed", "status", res.Status, "body", string(body))
+		var tsdbErr OpenTsdbError
+		if err := json.Unmarshal(body, &tsdbErr); err == nil && tsdbErr.Error.Message != "" {
+			return nil, fmt.Errorf("request failed, status: %s, error: %s", res.Status, tsdbErr.Error.Message)
+		}
 		return nil, fmt.Errorf("request failed, status: %s", res.Status)
 	}
 
@@ -161,43 +283,126 @@ func (s *Service) parseResponse(logger log.Logger, res *http.Response) (*backend
 		return nil, err
 	}
 
-	frames := data.Frames{}
+	series := make([][]OpenTsdbResponse, len(targets))
 	for _, val := range responseData {
-		timeVector := make([]time.Time, 0, len(val.DataPoints))
-		values := make([]float64, 0, len(val.DataPoints))
-		name := val.Metric
-		tags := val.Tags
+		idx := matchTarget(val, targets, tsdbQuery.ShowQuery)
+		if idx < 0 {
+			logger.Debug("Series not matching any query", "metric", val.Metric, "tags", val.Tags)
+			continue
+		}
+		series[idx] = append(series[idx], val)
+	}
+
+	for i, target := range targets {
+		if target.annotation != nil {
+			resp.Responses[target.refID] = backend.DataResponse{
+				Frames: data.Frames{target.annotation.toFrame(target.refID, series[i])},
+			}
+			continue
+		}
 
-		for timeString, value := range val.DataPoints {
-			timestamp, err := strconv.ParseInt(timeString, 10, 64)
+		frames := data.Frames{}
+		for _, val := range series[i] {
+			frame, err := seriesToFrame(val, tsdbQuery.MsResolution)
 			if err != nil {
-				logger.Info("Failed to unmarshal opentsdb timestamp", "timestamp", timeString)
+				logger.Info("Failed to unmarshal opentsdb timestamp", "error", err)
 				return nil, err
 			}
-			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
-			values = append(values, value)
+			frames = append(frames, frame)
 		}
-		frames = append(frames, data.NewFrame(name,
-			data.NewField("time", nil, timeVector),
-			data.NewField("value", tags, values)))
+		resp.Responses[target.refID] = backend.DataResponse{Frames: frames}
 	}
-	result := resp.Responses["A"]
-	result.Frames = frames
-	resp.Responses["A"] = result
 	return resp, nil
 }
 
-func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
+func seriesToFrame(val OpenTsdbResponse, msResolution bool) (*data.Frame, error) {
+	timestamps := make([]int64, 0, len(val.DataPoints))
+	for timeString := range val.DataPoints {
+		timestamp, err := strconv.ParseInt(timeString, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp %q: %w", timeString, err)
+		}
+		timestamps = append(timestamps, timestamp)
+	}
+	sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+	timeVector := make([]time.Time, 0, len(timestamps))
+	values := make([]float64, 0, len(timestamps))
+	for _, timestamp := range timestamps {
+		if msResolution {
+			timeVector = append(timeVector, time.UnixMilli(timestamp).UTC())
+		} else {
+			timeVector = append(timeVector, time.Unix(timestamp, 0).UTC())
+		}
+		values = append(values, val.DataPoints[strconv.FormatInt(timestamp, 10)])
+	}
+
+	return data.NewFrame(val.Metric,
+		data.NewField("time", nil, timeVector),
+		data.NewField("value", val.Tags, values)), nil
+}
+
+// matchTarget returns the index of the query of a series, or -1. Only the
+// series of batches with a single query are matched without showQuery.
+func matchTarget(val OpenTsdbResponse, targets []*queryTarget, showQuery bool) int {
+	if showQuery && val.Query != nil {
+		if val.Query.Index >= 0 && val.Query.Index < len(targets) {
+			return val.Query.Index
+		}
+		return -1
+	}
+	if len(targets) == 1 {
+		return 0
+	}
+	return -1
+}
+
+// buildQueryTarget returns the sub query of a query, or nil for incomplete
+// queries which are skipped.
+func (s *Service) buildQueryTarget(query backend.DataQuery, tsdbVersion int) (*queryTarget, error) {
+	model, err := simplejson.NewJson(query.JSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query: %w", err)
+	}
+
+	if model.Get("fromAnnotations").MustBool() {
+		metric := model.Get("target").MustString()
+		if metric == "" {
+			return nil, nil
+		}
+		return &queryTarget{
+			refID:      query.RefID,
+			metric:     map[string]any{"metric": metric, "aggregator": "sum"},
+			annotation: &annotationQuery{isGlobal: model.Get("isGlobal").MustBool()},
+		}, nil
+	}
+
+	metric, err := s.buildMetric(query, tsdbVersion)
+	if err != nil {
+		return nil, err
+	}
+	if metric["metric"] == "" && metric["tsuids"] == nil {
+		return nil, nil
+	}
+	return &queryTarget{refID: query.RefID, metric: metric}, nil
+}
+
+func (s *Service) buildMetric(query backend.DataQuery, tsdbVersion int) (map[string]any, error) {
 	metric := make(map[string]any)
 
 	model, err := simplejson.NewJson(query.JSON)
 	if err != nil {
-		return nil
+		return nil, fmt.Errorf("failed to parse query: %w", err)
 	}
 
-	// Setting metric and aggregator
-	metric["metric"] = model.Get("metric").MustString()
-	metric["aggregator"] = model.Get("aggregator").MustString()
+	// Setting metric or tsuids and aggregator
+	tsuids := model.Get("tsuids").MustStringArray()
+	if len(tsuids) > 0 {
+		metric["tsuids"] = tsuids
+	} else {
+		metric["metric"] = model.Get("metric").MustString()
+	}
+	metric["aggregator"] = model.Get("aggregator").MustString("avg")
 
 	// Setting downsampling options
 	disableDownsampling := model.Get("disableDownsampling").MustBool()
@@ -207,8 +412,8 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 			downsampleInterval = "1m" // default value for blank
 		}
 		downsample := downsampleInterval + "-" + model.Get("downsampleAggregator").MustString()
-		if model.Get("downsampleFillPolicy").MustString() != "none" {
-			metric["downsample"] = downsample + "-" + model.Get("downsampleFillPolicy").MustString()
+		if fillPolicy := model.Get("downsampleFillPolicy").MustString(); fillPolicy != "" && fillPolicy != "none" {
+			metric["downsample"] = downsample + "-" + fillPolicy
 		} else {
 			metric["downsample"] = downsample
 		}
@@ -220,36 +425,62 @@ func (s *Service) buildMetric(query backend.DataQuery) map[string]any {
 		rateOptions := make(map[string]any)
 		rateOptions["counter"] = model.Get("isCounter").MustBool()
 
-		counterMax, counterMaxCheck := model.CheckGet("counterMax")
+		// The query editor saves the counter options as strings.
+		counterMax, counterMaxCheck := jsonNumber(model.Get("counterMax"))
 		if counterMaxCheck {
-			rateOptions["counterMax"] = counterMax.MustFloat64()
+			rateOptions["counterMax"] = counterMax
 		}
 
-		resetValue, resetValueCheck := model.CheckGet("counterResetValue")
+		resetValue, resetValueCheck := jsonNumber(model.Get("counterResetValue"))
 		if resetValueCheck {
-			rateOptions["resetValue"] = resetValue.MustFloat64()
+			rateOptions["resetValue"] = resetValue
 		}
 
-		if !counterMaxCheck && (!resetValueCheck || resetValue.MustFloat64() == 0) {
+		// dropResets is supported since OpenTSDB 2.2.
+		if tsdbVersion >= tsdbVersion22 && !counterMaxCheck && (!resetValueCheck || resetValue == 0) {
 			rateOptions["dropResets"] = true
 		}
 
 		metric["rateOptions"] = rateOptions
 	}
 
-	// Setting tags
-	tags, tagsCheck := model.CheckGet("tags")
-	if tagsCheck && len(tags.MustMap()) > 0 {
-		metric["tags"] = tags.MustMap()
-	}
-
-	// Setting filters
+	// Setting filters, or tags if there are no filters. OpenTSDB converts tags
+	// to filters, a query with both would group by the tags and filters.
 	filters, filtersCheck := model.CheckGet("filters")
 	if filtersCheck && len(filters.MustArray()) > 0 {
+		if tsdbVersion < tsdbVersion22 {
+			return nil, fmt.Errorf("filters require OpenTSDB 2.2 or later")
+		}
 		metric["filters"] = filters.MustArray()
+	} else if tags, tagsCheck := model.CheckGet("tags"); tagsCheck && len(tags.MustMap()) > 0 {
+		metric["tags"] = tags.MustMap()
+	}
+
+	if model.Get("explicitTags").MustBool() {
+		if tsdbVersion < tsdbVersion23 {
+			return nil, fmt.Errorf("explicit tags require OpenTSDB 2.3 or later")
+		}
+		metric["explicitTags"] = true
 	}
 
-	return metric
+	return metric, nil
+}
+
+// jsonNumber returns the value of a number or of a string containing a
+// number. Empty strings are unset values.
+func jsonNumber(j *simplejson.Json) (float64, bool) {
+	if f, err := j.Float64(); err == nil {
+		return f, true
+	}
+	str, err := j.String()
+	if err != nil || strings.TrimSpace(str) == "" {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(strings.TrimSpace(str), 64)
+	if err != nil {
+		return 0, false
+	}
+	return f, true
 }
 
 func (s *Service) getDSInfo(ctx context.Context, pluginCtx backend.PluginContext) (*datasourceInfo, error) {
@@ -2,8 +2,11 @@ package opentsdb
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -13,6 +16,8 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
 )
 
 func TestOpenTsdbExecutor(t *testing.T) {
@@ -33,7 +38,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 	t.Run("Parse response should handle invalid JSON", func(t *testing.T) {
 		response := `{ invalid }`
 
-		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))})
+		result, err := service.parseResponse(logger, &http.Response{Body: io.NopCloser(strings.NewReader(response))}, []*queryTarget{{refID: "A"}}, OpenTsdbQuery{})
 		require.Nil(t, result)
 		require.Error(t, err)
 	})
@@ -63,7 +68,7 @@ func TestOpenTsdbExecutor(t *testing.T) {
 
 		resp := http.Response{Body: io.NopCloser(strings.NewReader(response))}
 		resp.StatusCode = 200
-		result, err := service.parseResponse(logger, &resp)
+		result, err := service.parseResponse(logger, &resp, []*queryTarget{{refID: "A"}}, OpenTsdbQuery{})
 		require.NoError(t, err)
 
 		frame := result.Responses["A"]
@@ -87,7 +92,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query, tsdbVersion23)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -109,7 +115,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query, tsdbVersion23)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 2)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -130,7 +137,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query, tsdbVersion23)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -156,7 +164,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query, tsdbVersion23)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 3)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -187,7 +196,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query, tsdbVersion23)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -222,7 +232,8 @@ func TestOpenTsdbExecutor(t *testing.T) {
 			),
 		}
 
-		metric := service.buildMetric(query)
+		metric, err := service.buildMetric(query, tsdbVersion23)
+		require.NoError(t, err)
 
 		require.Len(t, metric, 5)
 		require.Equal(t, "cpu.average.percent", metric["metric"])
@@ -242,3 +253,187 @@ func TestOpenTsdbExecutor(t *testing.T) {
 		require.Equal(t, float64(60), metricRateOptions["resetValue"])
 	})
 }
+
+func TestBuildMetric(t *testing.T) {
+	service := &Service{}
+
+	t.Run("Build metric with tsuids", func(t *testing.T) {
+		metric, err := service.buildMetric(backend.DataQuery{
+			JSON: []byte(`{"tsuids": ["000001000001000001"], "aggregator": "sum", "disableDownsampling": true}`),
+		}, tsdbVersion23)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"tsuids": []string{"000001000001000001"}, "aggregator": "sum"}, metric)
+	})
+
+	t.Run("Build metric with filters ignores tags", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`{
+				"metric": "cpu",
+				"disableDownsampling": true,
+				"tags": {"env": "prod"},
+				"filters": [{"type": "wildcard", "tagk": "host", "filter": "web*", "groupBy": true}],
+				"explicitTags": true
+			}`),
+		}
+		metric, err := service.buildMetric(query, tsdbVersion23)
+		require.NoError(t, err)
+		require.Nil(t, metric["tags"])
+		require.Len(t, metric["filters"], 1)
+		require.Equal(t, true, metric["explicitTags"])
+
+		_, err = service.buildMetric(query, tsdbVersion22)
+		require.ErrorContains(t, err, "explicit tags require OpenTSDB 2.3")
+
+		_, err = service.buildMetric(query, 1)
+		require.ErrorContains(t, err, "filters require OpenTSDB 2.2")
+	})
+
+	t.Run("Build metric with rate options saved as strings", func(t *testing.T) {
+		query := backend.DataQuery{
+			JSON: []byte(`{
+				"metric": "cpu",
+				"disableDownsampling": true,
+				"shouldComputeRate": true,
+				"isCounter": true,
+				"counterMax": "",
+				"counterResetValue": "60"
+			}`),
+		}
+		metric, err := service.buildMetric(query, tsdbVersion23)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"counter": true, "resetValue": float64(60)}, metric["rateOptions"])
+
+		query.JSON = []byte(`{"metric": "cpu", "disableDownsampling": true, "shouldComputeRate": true, "isCounter": true}`)
+		metric, err = service.buildMetric(query, tsdbVersion22)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"counter": true, "dropResets": true}, metric["rateOptions"])
+
+		metric, err = service.buildMetric(query, 1)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"counter": true}, metric["rateOptions"])
+	})
+}
+
+func newQueryTestService(t *testing.T, tsdbVersion int, handler http.HandlerFunc) *Service {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &Service{
+		im: testInstanceManager{info: &datasourceInfo{
+			HTTPClient:  server.Client(),
+			URL:         server.URL,
+			TSDBVersion: tsdbVersion,
+		}},
+		tracer: tracing.InitializeTracerForTest(),
+	}
+}
+
+func TestQueryData(t *testing.T) {
+	timeRange := backend.TimeRange{From: time.Unix(1000, 0), To: time.Unix(2000, 0)}
+	queries := []backend.DataQuery{
+		{RefID: "A", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "aggregator": "sum", "disableDownsampling": true, "tags": {"host": "a|b"}}`)},
+		{RefID: "B", TimeRange: timeRange, JSON: []byte(`{"metric": "cpu", "aggregator": "sum", "disableDownsampling": true, "tags": {"host": "c"}}`)},
+		{RefID: "C", TimeRange: timeRange, JSON: []byte(`{"fromAnnotations": true, "target": "deploys", "isGlobal": true}`)},
+		{RefID: "D", TimeRange: timeRange, JSON: []byte(`{"aggregator": "sum"}`)},
+	}
+
+	t.Run("should batch queries and match series by index", func(t *testing.T) {
+		var requests []OpenTsdbQuery
+		service := newQueryTestService(t, tsdbVersion23, func(w http.ResponseWriter, r *http.Request) {
+			var q OpenTsdbQuery
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&q))
+			requests = append(requests, q)
+			_, _ = w.Write([]byte(`[
+				{"metric": "cpu", "tags": {"host": "c"}, "dps": {"1020": 3, "1010": 2}, "query": {"index": 1}},
+				{"metric": "cpu", "tags": {"host": "a"}, "dps": {"1010": 1}, "query": {"index": 0}},
+				{"metric": "deploys", "tags": {}, "dps": {"1010": 1}, "query": {"index": 2},
+				 "globalAnnotations": [{"description": "deploy", "startTime": 1015, "endTime": 1030}]}
+			]`))
+		})
+
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{Queries: queries})
+		require.NoError(t, err)
+
+		require.Len(t, requests, 1)
+		require.Len(t, requests[0].Queries, 3)
+		require.True(t, requests[0].ShowQuery)
+		require.True(t, requests[0].GlobalAnnotations)
+		require.Equal(t, int64(1000000), requests[0].Start)
+
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		require.Equal(t, data.Labels{"host": "a"}, resp.Responses["A"].Frames[0].Fields[1].Labels)
+
+		frameB := resp.Responses["B"].Frames[0]
+		require.Equal(t, data.Labels{"host": "c"}, frameB.Fields[1].Labels)
+		require.Equal(t, time.Unix(1010, 0).UTC(), frameB.Fields[0].At(0))
+		require.Equal(t, 3.0, frameB.Fields[1].At(1))
+
+		annotations := resp.Responses["C"].Frames[0]
+		require.Equal(t, 1, annotations.Rows())
+		require.Equal(t, time.Unix(1015, 0).UTC(), annotations.Fields[0].At(0))
+		require.Equal(t, "deploy", annotations.Fields[2].At(0))
+
+		require.Empty(t, resp.Responses["D"].Frames)
+		require.NoError(t, resp.Responses["D"].Error)
+	})
+
+	t.Run("should batch queries by time range", func(t *testing.T) {
+		var requests []OpenTsdbQuery
+		service := newQueryTestService(t, tsdbVersion23, func(w http.ResponseWriter, r *http.Request) {
+			var q OpenTsdbQuery
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&q))
+			requests = append(requests, q)
+			_, _ = w.Write([]byte(`[{"metric": "cpu", "tags": {"host": "a"}, "dps": {"1010": 1}, "query": {"index": 0}}]`))
+		})
+
+		shifted := queries[1]
+		shifted.TimeRange = backend.TimeRange{From: time.Unix(500, 0), To: time.Unix(1500, 0)}
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{queries[0], shifted}})
+		require.NoError(t, err)
+
+		require.Len(t, requests, 2)
+		require.Equal(t, int64(1000000), requests[0].Start)
+		require.Equal(t, int64(2000000), requests[0].End)
+		require.Len(t, requests[0].Queries, 1)
+		require.Equal(t, int64(500000), requests[1].Start)
+		require.Equal(t, int64(1500000), requests[1].End)
+		require.Len(t, requests[1].Queries, 1)
+		require.Len(t, resp.Responses["A"].Frames, 1)
+		require.Len(t, resp.Responses["B"].Frames, 1)
+	})
+
+	t.Run("should not batch queries before OpenTSDB 2.3", func(t *testing.T) {
+		var requests []OpenTsdbQuery
+		service := newQueryTestService(t, tsdbVersion22, func(w http.ResponseWriter, r *http.Request) {
+			var q OpenTsdbQuery
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&q))
+			requests = append(requests, q)
+			tags := q.Queries[0]["tags"].(map[string]any)
+			_, _ = w.Write([]byte(fmt.Sprintf(`[{"metric": "cpu", "tags": {"host": %q}, "dps": {"1010": 1}}]`, tags["host"])))
+		})
+
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{Queries: queries[:2]})
+		require.NoError(t, err)
+		require.Len(t, requests, 2)
+		for _, request := range requests {
+			require.False(t, request.ShowQuery)
+			require.Len(t, request.Queries, 1)
+		}
+		require.Equal(t, data.Labels{"host": "a|b"}, resp.Responses["A"].Frames[0].Fields[1].Labels)
+		require.Equal(t, data.Labels{"host": "c"}, resp.Responses["B"].Frames[0].Fields[1].Labels)
+	})
+
+	t.Run("should return the error for all queries of the batch", func(t *testing.T) {
+		service := newQueryTestService(t, tsdbVersion23, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error": {"code": 400, "message": "No such name for 'metrics': 'cpu'"}}`))
+		})
+
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{Queries: queries})
+		require.NoError(t, err)
+		for _, refID := range []string{"A", "B", "C"} {
+			require.ErrorContains(t, resp.Responses[refID].Error, "No such name for 'metrics': 'cpu'")
+		}
+		require.NoError(t, resp.Responses["D"].Error)
+	})
+}
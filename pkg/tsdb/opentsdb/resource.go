@@ -0,0 +1,32 @@
+package opentsdb
+
+import (
+	"context"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+
+	"github.com/grafana/grafana/pkg/tsdb/httpresource"
+)
+
+// resourceProxy proxies OpenTSDB HTTP API endpoints, relative to /api, which
+// can be called through CallResource.
+var resourceProxy = httpresource.Proxy{
+	Name:   "opentsdb",
+	Prefix: "api",
+	Paths: []string{
+		"suggest",
+		"search/lookup",
+		"aggregators",
+		"config/filters",
+	},
+}
+
+func (s *Service) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
+	logger := logger.FromContext(ctx)
+	dsInfo, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		logger.Error("Failed to get data source info", "error", err)
+		return err
+	}
+	return resourceProxy.CallResource(ctx, req, sender, dsInfo.HTTPClient, dsInfo.URL, logger, s.tracer)
+}
@@ -0,0 +1,120 @@
+package opentsdb
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/tracing"
+)
+
+type testInstanceManager struct {
+	info *datasourceInfo
+}
+
+func (m testInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.info, nil
+}
+
+func (m testInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+func newTestService(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &Service{
+		im:     testInstanceManager{info: &datasourceInfo{HTTPClient: server.Client(), URL: server.URL}},
+		tracer: tracing.InitializeTracerForTest(),
+	}
+}
+
+func TestCallResource(t *testing.T) {
+	t.Run("should proxy allowed resources to opentsdb", func(t *testing.T) {
+		var requestURL string
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			requestURL = r.URL.String()
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`["cpu.user","cpu.system"]`))
+		})
+
+		sender := &fakeSender{}
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			URL:    "suggest?type=metrics&q=cpu&max=1000",
+		}, sender)
+		require.NoError(t, err)
+		assert.Equal(t, "/api/suggest?type=metrics&q=cpu&max=1000", requestURL)
+		assert.Equal(t, http.StatusOK, sender.resp.Status)
+		assert.JSONEq(t, `["cpu.user","cpu.system"]`, string(sender.resp.Body))
+	})
+
+	t.Run("should return an error for server errors", func(t *testing.T) {
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			URL:    "search/lookup?m=cpu&limit=3000",
+		}, &fakeSender{})
+		require.Error(t, err)
+	})
+
+	t.Run("should reject unknown resources and methods", func(t *testing.T) {
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Fatal("unexpected request to opentsdb")
+		})
+
+		err := s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodGet,
+			URL:    "query?start=1h-ago&m=sum:cpu",
+		}, &fakeSender{})
+		require.Error(t, err)
+
+		err = s.CallResource(context.Background(), &backend.CallResourceRequest{
+			Method: http.MethodDelete,
+			URL:    "aggregators",
+		}, &fakeSender{})
+		require.Error(t, err)
+	})
+}
+
+func TestCheckHealth(t *testing.T) {
+	t.Run("should do a successful health check", func(t *testing.T) {
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/aggregators", r.URL.Path)
+			_, _ = w.Write([]byte(`["sum","avg"]`))
+		})
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("should return an error for an unsuccessful health check", func(t *testing.T) {
+		s := newTestService(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, backend.HealthStatusError, res.Status)
+	})
+}
+
+type fakeSender struct {
+	resp *backend.CallResourceResponse
+}
+
+func (s *fakeSender) Send(resp *backend.CallResourceResponse) error {
+	s.resp = resp
+	return nil
+}
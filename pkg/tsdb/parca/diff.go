@@ -0,0 +1,113 @@
+package parca
+
+import (
+	"context"
+	"fmt"
+
+	v1alpha1 "buf.build/gen/go/parca-dev/parca/protocolbuffers/go/parca/query/v1alpha1"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/grafana/grafana/pkg/tsdb/flamegraph"
+	"github.com/grafana/grafana/pkg/tsdb/parca/kinds/dataquery"
+)
+
+const queryTypeDiff = string(dataquery.ParcaQueryTypeDiff)
+
+// queryDiff queries the baseline and comparison profiles of a diff query and returns the diff flame graph frame and
+// the top table of the functions with the largest changes.
+func (d *ParcaDatasource) queryDiff(ctx context.Context, qm queryModel, query backend.DataQuery) (data.Frames, error) {
+	var baseline, comparison *dataquery.ProfileSelection
+	topN := flamegraph.DefaultTopN
+	if qm.Diff != nil {
+		baseline, comparison = qm.Diff.Baseline, qm.Diff.Comparison
+		if qm.Diff.TopN != nil {
+			topN = int(*qm.Diff.TopN)
+		}
+	}
+
+	var baselineGraph, comparisonGraph *v1alpha1.Flamegraph
+	g, gCtx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		fg, err := d.selectionFlamegraph(gCtx, qm, query, baseline)
+		if err != nil {
+			return fmt.Errorf("baseline profile: %w", err)
+		}
+		baselineGraph = fg
+		return nil
+	})
+	g.Go(func() error {
+		fg, err := d.selectionFlamegraph(gCtx, qm, query, comparison)
+		if err != nil {
+			return fmt.Errorf("comparison profile: %w", err)
+		}
+		comparisonGraph = fg
+		return nil
+	})
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	unit := normalizeUnit(baselineGraph.GetUnit())
+	if unit == "" {
+		unit = normalizeUnit(comparisonGraph.GetUnit())
+	}
+
+	tree := flamegraph.Diff(flamegraphToNode(baselineGraph), flamegraphToNode(comparisonGraph))
+	return data.Frames{
+		flamegraph.DiffFrame(tree, unit),
+		flamegraph.TopTableFrame(tree, topN, unit),
+	}, nil
+}
+
+// selectionFlamegraph returns the flame graph of a side of a diff query. The label selector and time range default to
+// the ones of the query.
+func (d *ParcaDatasource) selectionFlamegraph(ctx context.Context, qm queryModel, query backend.DataQuery, selection *dataquery.ProfileSelection) (*v1alpha1.Flamegraph, error) {
+	if selection != nil {
+		if selection.LabelSelector != nil && *selection.LabelSelector != "" {
+			qm.LabelSelector = *selection.LabelSelector
+		}
+		timeShift := ""
+		if selection.TimeShift != nil {
+			timeShift = *selection.TimeShift
+		}
+		tr, err := flamegraph.SelectionTimeRange(query.TimeRange, timeShift, selection.From, selection.To)
+		if err != nil {
+			return nil, err
+		}
+		query.TimeRange = tr
+	}
+
+	logger.FromContext(ctx).Debug("Querying SelectMergeStacktraces() for diff", "labelSelector", qm.LabelSelector, "from", query.TimeRange.From, "to", query.TimeRange.To, "function", logEntrypoint())
+	resp, err := d.client.Query(ctx, makeProfileRequest(qm, query))
+	if err != nil {
+		return nil, err
+	}
+	flameResponse, ok := resp.Msg.Report.(*v1alpha1.QueryResponse_Flamegraph)
+	if !ok {
+		return nil, fmt.Errorf("unknown report type returned from query")
+	}
+	return flameResponse.Flamegraph, nil
+}
+
+func flamegraphToNode(fg *v1alpha1.Flamegraph) *flamegraph.Node {
+	if fg == nil || fg.Root == nil {
+		return nil
+	}
+	root := &flamegraph.Node{Name: "total", Value: fg.Root.Cumulative, Self: fg.Root.Cumulative}
+	for _, child := range fg.Root.Children {
+		root.Self -= child.Cumulative
+		root.Children = append(root.Children, flamegraphNodeToNode(child))
+	}
+	return root
+}
+
+func flamegraphNodeToNode(n *v1alpha1.FlamegraphNode) *flamegraph.Node {
+	node := &flamegraph.Node{Name: nodeName(n), Value: n.Cumulative, Self: n.Cumulative}
+	for _, child := range n.Children {
+		node.Self -= child.Cumulative
+		node.Children = append(node.Children, flamegraphNodeToNode(child))
+	}
+	return node
+}
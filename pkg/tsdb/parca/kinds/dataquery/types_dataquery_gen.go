@@ -12,6 +12,7 @@ package dataquery
 // Defines values for ParcaQueryType.
 const (
 	ParcaQueryTypeBoth    ParcaQueryType = "both"
+	ParcaQueryTypeDiff    ParcaQueryType = "diff"
 	ParcaQueryTypeMetrics ParcaQueryType = "metrics"
 	ParcaQueryTypeProfile ParcaQueryType = "profile"
 )
@@ -54,6 +55,9 @@ type ParcaDataQuery struct {
 	// TODO this shouldn't be unknown but DataSourceRef | null
 	Datasource *any `json:"datasource,omitempty"`
 
+	// Compares two profiles when the query type is diff.
+	Diff *ProfileDiff `json:"diff,omitempty"`
+
 	// Hide true if query is disabled (ie should not be returned to the dashboard)
 	// Note this does not always imply that the query should not be executed since
 	// the results from a hidden query may be used as the input to other queries (SSE etc)
@@ -77,3 +81,30 @@ type ParcaDataQuery struct {
 
 // ParcaQueryType defines model for ParcaQueryType.
 type ParcaQueryType string
+
+// ProfileDiff defines model for ProfileDiff.
+type ProfileDiff struct {
+	// The baseline profile, shown as the left profile of the diff flame graph. Defaults to the profile of the query.
+	Baseline *ProfileSelection `json:"baseline,omitempty"`
+
+	// The comparison profile, shown as the right profile of the diff flame graph. Defaults to the profile of the query.
+	Comparison *ProfileSelection `json:"comparison,omitempty"`
+
+	// Sets the number of functions with the largest changes in the top table.
+	TopN *int64 `json:"topN,omitempty"`
+}
+
+// ProfileSelection defines model for ProfileSelection.
+type ProfileSelection struct {
+	// Start of the profile in milliseconds since epoch, overriding the time range of the query.
+	From *int64 `json:"from,omitempty"`
+
+	// Specifies the label selector of the profile. Defaults to the label selector of the query.
+	LabelSelector *string `json:"labelSelector,omitempty"`
+
+	// Shifts the time range of the query back, for example 1d.
+	TimeShift *string `json:"timeShift,omitempty"`
+
+	// End of the profile in milliseconds since epoch, overriding the time range of the query.
+	To *int64 `json:"to,omitempty"`
+}
@@ -47,6 +47,19 @@ func (d *ParcaDatasource) query(ctx context.Context, pCtx backend.PluginContext,
 		return response
 	}
 
+	if query.QueryType == queryTypeDiff {
+		frames, err := d.queryDiff(ctx, qm, query)
+		if err != nil {
+			response.Error = err
+			ctxLogger.Error("Failed to process query", "error", err, "queryType", query.QueryType, "function", logEntrypoint())
+			span.RecordError(response.Error)
+			span.SetStatus(codes.Error, response.Error.Error())
+			return response
+		}
+		response.Frames = frames
+		return response
+	}
+
 	if query.QueryType == queryTypeMetrics || query.QueryType == queryTypeBoth {
 		seriesResp, err := d.client.QueryRange(ctx, makeMetricRequest(qm, query))
 		if err != nil {
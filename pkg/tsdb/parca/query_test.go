@@ -56,6 +56,28 @@ func Test_query(t *testing.T) {
 		require.Equal(t, 1, len(resp.Frames))
 		require.Equal(t, "time", resp.Frames[0].Fields[0].Name)
 	})
+
+	t.Run("query diff", func(t *testing.T) {
+		dataQuery.QueryType = queryTypeDiff
+		dataQuery.JSON = []byte(`{"profileTypeId":"foo:bar","labelSelector":"{app=\\\"baz\\\"}","diff":{"baseline":{"timeShift":"1d"}}}`)
+		resp := ds.query(context.Background(), backend.PluginContext{}, dataQuery)
+		require.Nil(t, resp.Error)
+		require.Equal(t, 2, len(resp.Frames))
+		require.Equal(t, data.NewField("level", nil, []int64{0, 1, 2, 3}), resp.Frames[0].Fields[0])
+		require.Equal(t, data.NewField("label", nil, []string{"total", "foo", "bar", "baz"}), resp.Frames[0].Fields[3])
+		require.Equal(t, "selfRight", resp.Frames[0].Fields[5].Name)
+		require.Equal(t, []int64{90, 1, 1, 8}, fieldValues[int64](resp.Frames[0].Fields[5]))
+		require.Equal(t, "top", resp.Frames[1].Name)
+		require.Equal(t, 4, resp.Frames[1].Rows())
+	})
+}
+
+func fieldValues[T any](field *data.Field) []T {
+	values := make([]T, field.Len())
+	for i := 0; i < field.Len(); i++ {
+		values[i] = field.At(i).(T)
+	}
+	return values
 }
 
 // This is where the tests for the datasource backend live.
@@ -14,6 +14,7 @@ import (
 
 const rsIdentifier = `([_a-zA-Z0-9]+)`
 const sExpr = `\$` + rsIdentifier + `\(([^\)]*)\)`
+const localTimeLayout = "2006-01-02 15:04:05"
 
 type postgresMacroEngine struct {
 	*sqleng.SQLMacroEngineBase
@@ -119,6 +120,39 @@ func (m *postgresMacroEngine) evaluateMacro(timeRange backend.TimeRange, query *
 			return tg + " AS \"time\"", nil
 		}
 		return "", err
+	case "__timeBucket":
+		if len(args) < 2 {
+			return "", fmt.Errorf("macro %v needs time column and calendar unit", name)
+		}
+		unit, loc, err := sqleng.TimeBucketArgs(query, args[1])
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("date_trunc('%s', %s AT TIME ZONE '%s') AT TIME ZONE '%s'", unit, args[0], loc, loc), nil
+	case "__timeBucketAlias":
+		tb, err := m.evaluateMacro(timeRange, query, "__timeBucket", args)
+		if err == nil {
+			return tb + " AS \"time\"", nil
+		}
+		return "", err
+	case "__timeBucketFill":
+		if args[0] == "" {
+			return "", fmt.Errorf("missing calendar unit argument for macro %v", name)
+		}
+		unit, loc, err := sqleng.TimeBucketArgs(query, args[0])
+		if err != nil {
+			return "", err
+		}
+		start, count, err := sqleng.CalendarBuckets(timeRange, unit, loc)
+		if err != nil {
+			return "", err
+		}
+		step := "1 " + string(unit)
+		if unit == sqleng.CalendarUnitQuarter {
+			step = "3 month"
+		}
+		return fmt.Sprintf("(SELECT t AT TIME ZONE '%s' AS \"time\" FROM generate_series(timestamp '%s', timestamp '%s', interval '%s') AS t)",
+			loc, start.Format(localTimeLayout), unit.Add(start, count-1).Format(localTimeLayout), step), nil
 	case "__unixEpochFilter":
 		if len(args) == 0 {
 			return "", fmt.Errorf("missing time column argument for macro %v", name)
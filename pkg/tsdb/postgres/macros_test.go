@@ -247,3 +247,43 @@ func TestMacroEngineConcurrency(t *testing.T) {
 
 	wg.Wait()
 }
+
+func TestMacroEngine_TimeBucket(t *testing.T) {
+	engine := newPostgresMacroEngine(false)
+	timeRange := backend.TimeRange{
+		From: time.Date(2023, 3, 25, 12, 0, 0, 0, time.UTC),
+		To:   time.Date(2023, 3, 27, 12, 0, 0, 0, time.UTC),
+	}
+	query := &backend.DataQuery{JSON: []byte(`{"timezone":"Europe/Berlin"}`)}
+
+	t.Run("interpolate __timeBucket function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "GROUP BY $__timeBucket(time_column, 'day')")
+		require.NoError(t, err)
+		require.Equal(t, "GROUP BY date_trunc('day', time_column AT TIME ZONE 'Europe/Berlin') AT TIME ZONE 'Europe/Berlin'", sql)
+
+		sql, err = engine.Interpolate(&backend.DataQuery{}, timeRange, "SELECT $__timeBucketAlias(time_column, quarter)")
+		require.NoError(t, err)
+		require.Equal(t, "SELECT date_trunc('quarter', time_column AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS \"time\"", sql)
+	})
+
+	t.Run("interpolate __timeBucketFill function", func(t *testing.T) {
+		sql, err := engine.Interpolate(query, timeRange, "FROM $__timeBucketFill('day') b")
+		require.NoError(t, err)
+		require.Equal(t, "FROM (SELECT t AT TIME ZONE 'Europe/Berlin' AS \"time\" FROM generate_series(timestamp '2023-03-25 00:00:00', timestamp '2023-03-27 00:00:00', interval '1 day') AS t) b", sql)
+
+		sql, err = engine.Interpolate(&backend.DataQuery{}, timeRange, "FROM $__timeBucketFill(quarter) b")
+		require.NoError(t, err)
+		require.Equal(t, "FROM (SELECT t AT TIME ZONE 'UTC' AS \"time\" FROM generate_series(timestamp '2023-01-01 00:00:00', timestamp '2023-01-01 00:00:00', interval '3 month') AS t) b", sql)
+	})
+
+	t.Run("returns error for invalid arguments", func(t *testing.T) {
+		_, err := engine.Interpolate(query, timeRange, "$__timeBucket(time_column)")
+		require.Error(t, err)
+		_, err = engine.Interpolate(query, timeRange, "$__timeBucket(time_column, '1d')")
+		require.Error(t, err)
+		_, err = engine.Interpolate(query, timeRange, "$__timeBucketFill()")
+		require.Error(t, err)
+		_, err = engine.Interpolate(&backend.DataQuery{JSON: []byte(`{"timezone":"Nowhere/City"}`)}, timeRange, "$__timeBucket(time_column, day)")
+		require.Error(t, err)
+	})
+}
@@ -16,6 +16,7 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/sqleng"
 	"github.com/grafana/grafana/pkg/tsdb/sqleng/proxyutil"
@@ -23,11 +24,11 @@ import (
 
 var logger = log.New("tsdb.postgres")
 
-func ProvideService(cfg *setting.Cfg) *Service {
+func ProvideService(cfg *setting.Cfg, cache remotecache.CacheStorage) *Service {
 	s := &Service{
 		tlsManager: newTLSManager(logger, cfg.DataPath),
 	}
-	s.im = datasource.NewInstanceManager(s.newInstanceSettings(cfg))
+	s.im = datasource.NewInstanceManager(s.newInstanceSettings(cfg, cache))
 	return s
 }
 
@@ -53,7 +54,31 @@ func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest)
 	return dsInfo.QueryData(ctx, req)
 }
 
-func (s *Service) newInstanceSettings(cfg *setting.Cfg) datasource.InstanceFactoryFunc {
+func (s *Service) SubscribeStream(ctx context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	dsHandler, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.SubscribeStream(ctx, req)
+}
+
+func (s *Service) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	dsHandler, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return err
+	}
+	return dsHandler.RunStream(ctx, req, sender)
+}
+
+func (s *Service) PublishStream(ctx context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	dsHandler, err := s.getDSInfo(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.PublishStream(ctx, req)
+}
+
+func (s *Service) newInstanceSettings(cfg *setting.Cfg, cache remotecache.CacheStorage) datasource.InstanceFactoryFunc {
 	return func(_ context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
 		logger.Debug("Creating Postgres query endpoint")
 		jsonData := sqleng.JsonData{
@@ -111,6 +136,8 @@ func (s *Service) newInstanceSettings(cfg *setting.Cfg) datasource.InstanceFacto
 			DSInfo:            dsInfo,
 			MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
 			RowLimit:          cfg.DataProxyRowLimit,
+			Cache:             cache,
+			Cursor:            postgresCursor{},
 		}
 
 		queryResultTransformer := postgresQueryResultTransformer{}
@@ -203,6 +230,21 @@ func (s *Service) generateConnectionString(dsInfo sqleng.DataSourceInfo) (string
 	return connStr, nil
 }
 
+// postgresCursor streams query results in chunks with server-side cursors.
+type postgresCursor struct{}
+
+func (postgresCursor) Declare(name string, sql string) string {
+	return fmt.Sprintf("DECLARE %s NO SCROLL CURSOR FOR %s", name, strings.TrimRight(strings.TrimSpace(sql), ";"))
+}
+
+func (postgresCursor) Fetch(name string, count int64) string {
+	return fmt.Sprintf("FETCH FORWARD %d FROM %s", count, name)
+}
+
+func (postgresCursor) Close(name string) string {
+	return "CLOSE " + name
+}
+
 type postgresQueryResultTransformer struct{}
 
 func (t *postgresQueryResultTransformer) TransformQueryError(_ log.Logger, err error) error {
@@ -1411,3 +1411,10 @@ type tlsTestManager struct {
 func (m *tlsTestManager) getTLSSettings(dsInfo sqleng.DataSourceInfo) (tlsSettings, error) {
 	return m.settings, nil
 }
+
+func TestPostgresCursor(t *testing.T) {
+	c := postgresCursor{}
+	require.Equal(t, "DECLARE cur NO SCROLL CURSOR FOR SELECT 1", c.Declare("cur", " SELECT 1; "))
+	require.Equal(t, "FETCH FORWARD 100 FROM cur", c.Fetch("cur", 100))
+	require.Equal(t, "CLOSE cur", c.Close("cur"))
+}
@@ -12,6 +12,18 @@ var (
 		Name:      "prometheus_plugin_backend_request_count",
 		Help:      "The total amount of prometheus backend plugin requests",
 	}, []string{"endpoint", "status", "errorSource"})
+
+	incrementalQueryCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "prometheus_plugin_incremental_query_cache_requests_total",
+		Help:      "The total amount of prometheus incremental query cache lookups",
+	}, []string{"result"})
+
+	incrementalQueryCacheEvictions = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "prometheus_plugin_incremental_query_cache_evictions_total",
+		Help:      "The total amount of results evicted from the prometheus incremental query cache",
+	})
 )
 
 const (
@@ -25,6 +37,12 @@ const (
 	ExternalSource = "external"
 	DatabaseSource = "database"
 	NoneSource     = "none"
+
+	CacheHit  = "hit"
+	CacheMiss = "miss"
+	// CacheMergeFailed is reported when a cached result could not be merged
+	// with the new data and the full range is queried again.
+	CacheMergeFailed = "merge_failed"
 )
 
 func UpdateQueryDataMetrics(err error, resp *backend.QueryDataResponse) {
@@ -38,6 +56,14 @@ func UpdateQueryDataMetrics(err error, resp *backend.QueryDataResponse) {
 	pluginRequestCounter.WithLabelValues(EndpointQueryData, status, errorSource).Inc()
 }
 
+func UpdateIncrementalQueryCacheMetrics(result string) {
+	incrementalQueryCacheRequests.WithLabelValues(result).Inc()
+}
+
+func UpdateIncrementalQueryCacheEvictions() {
+	incrementalQueryCacheEvictions.Inc()
+}
+
 func getErrorSource(err error, resp *backend.QueryDataResponse) string {
 	if err != nil {
 		return PluginSource
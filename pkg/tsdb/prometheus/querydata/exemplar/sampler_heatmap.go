@@ -0,0 +1,68 @@
+package exemplar
+
+import (
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/models"
+)
+
+type heatmapCell struct {
+	ts     time.Time
+	bucket int
+	series string
+}
+
+// HeatmapCellSampler samples the exemplars of native histograms, keeping the
+// exemplar with the highest value of each heatmap cell, which is the step of
+// the exemplar and the histogram bucket of its value.
+type HeatmapCellSampler struct {
+	step   time.Duration
+	bounds []float64
+	cells  map[heatmapCell]models.Exemplar
+}
+
+// NewHeatmapCellSampler returns a sampler of the exemplars of native
+// histograms with the given sorted upper bounds of buckets.
+func NewHeatmapCellSampler(bounds []float64) Sampler {
+	return &HeatmapCellSampler{
+		bounds: bounds,
+		cells:  map[heatmapCell]models.Exemplar{},
+	}
+}
+
+func (e *HeatmapCellSampler) SetStep(step time.Duration) {
+	e.step = step
+}
+
+func (e *HeatmapCellSampler) Add(ex models.Exemplar) {
+	cell := heatmapCell{
+		ts:     models.AlignTimeRange(ex.Timestamp, e.step, 0),
+		bucket: sort.SearchFloat64s(e.bounds, ex.Value),
+		series: data.Labels(ex.SeriesLabels).String(),
+	}
+	if prev, ok := e.cells[cell]; !ok || ex.Value > prev.Value {
+		e.cells[cell] = ex
+	}
+}
+
+func (e *HeatmapCellSampler) Sample() []models.Exemplar {
+	exemplars := make([]models.Exemplar, 0, len(e.cells))
+	for _, ex := range e.cells {
+		exemplars = append(exemplars, ex)
+	}
+	sort.Slice(exemplars, func(i, j int) bool {
+		if !exemplars[i].Timestamp.Equal(exemplars[j].Timestamp) {
+			return exemplars[i].Timestamp.Before(exemplars[j].Timestamp)
+		}
+		return exemplars[i].Value < exemplars[j].Value
+	})
+	return exemplars
+}
+
+func (e *HeatmapCellSampler) Reset() {
+	e.step = 0
+	e.cells = map[heatmapCell]models.Exemplar{}
+}
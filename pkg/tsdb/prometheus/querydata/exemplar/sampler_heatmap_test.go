@@ -0,0 +1,39 @@
+package exemplar_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/models"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/querydata/exemplar"
+)
+
+func TestHeatmapCellSampler(t *testing.T) {
+	sampler := exemplar.NewHeatmapCellSampler([]float64{0.1, 0.2, 0.4})
+	sampler.SetStep(time.Minute)
+
+	add := func(ts int64, value float64, job string) {
+		sampler.Add(models.Exemplar{Timestamp: time.Unix(ts, 0), Value: value, SeriesLabels: map[string]string{"job": job}})
+	}
+	// same cell, the highest value is kept
+	add(0, 0.15, "api")
+	add(30, 0.18, "api")
+	// other bucket
+	add(10, 0.3, "api")
+	// other step
+	add(70, 0.16, "api")
+	// other series
+	add(20, 0.12, "web")
+
+	sampled := sampler.Sample()
+	values := make([]float64, 0, len(sampled))
+	for _, ex := range sampled {
+		values = append(values, ex.Value)
+	}
+	require.Equal(t, []float64{0.3, 0.12, 0.18, 0.16}, values)
+
+	sampler.Reset()
+	require.Empty(t, sampler.Sample())
+}
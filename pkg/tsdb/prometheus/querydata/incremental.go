@@ -0,0 +1,404 @@
+package querydata
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/client"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/instrumentation"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/models"
+)
+
+const (
+	// defaultIncrementalQueryOverlapWindow is the window before the end of a
+	// cached result that is queried again, as recent samples may still change.
+	// It is the same default as in the frontend query cache.
+	defaultIncrementalQueryOverlapWindow = 10 * time.Minute
+	// incrementalQueryCacheSize is the max number of results cached per data source.
+	incrementalQueryCacheSize = 500
+	// incrementalQueryCacheMaxBytes is the max estimated size of the results
+	// cached per data source.
+	incrementalQueryCacheMaxBytes = 64 << 20
+
+	rangeQueryKind    = "range"
+	exemplarQueryKind = "exemplar"
+)
+
+type queryFunc func(ctx context.Context, c *client.Client, q *models.Query, headers map[string]string) backend.DataResponse
+
+// headers of a request which give access to Prometheus as the user
+var incrementalCacheAuthHeaders = []string{
+	backend.OAuthIdentityTokenHeaderName,
+	backend.OAuthIdentityIDTokenHeaderName,
+	backend.CookiesHeaderName,
+	"X-Grafana-Id",
+}
+
+// incrementalCache is a bounded in-memory cache of the results of range and
+// exemplar queries. Results are evicted in least recently used order.
+type incrementalCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	maxBytes   int
+	bytes      int
+	overlap    time.Duration
+	entries    map[string]*list.Element
+	lru        *list.List
+}
+
+type incrementalCacheEntry struct {
+	key string
+	// start and end are the step aligned time range of the cached frames.
+	start  time.Time
+	end    time.Time
+	frames data.Frames
+	// size is the estimated size of the frames in bytes
+	size int
+}
+
+func newIncrementalCache(maxEntries, maxBytes int, overlap time.Duration) *incrementalCache {
+	return &incrementalCache{
+		maxEntries: maxEntries,
+		maxBytes:   maxBytes,
+		overlap:    overlap,
+		entries:    map[string]*list.Element{},
+		lru:        list.New(),
+	}
+}
+
+func (c *incrementalCache) get(key string) (*incrementalCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return el.Value.(*incrementalCacheEntry), true
+}
+
+func (c *incrementalCache) set(entry *incrementalCacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[entry.key]; ok {
+		c.remove(el)
+	}
+	// results larger than the cache are not cached
+	if entry.size > c.maxBytes {
+		return
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+	c.bytes += entry.size
+
+	for c.lru.Len() > c.maxEntries || c.bytes > c.maxBytes {
+		c.remove(c.lru.Back())
+		instrumentation.UpdateIncrementalQueryCacheEvictions()
+	}
+}
+
+func (c *incrementalCache) remove(el *list.Element) {
+	entry := el.Value.(*incrementalCacheEntry)
+	c.lru.Remove(el)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+}
+
+func (c *incrementalCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// incrementalCacheKey identifies the results of a query. The time range is not
+// part of the key, the cached range is extended on each refresh instead.
+// Results are cached per user and forwarded auth headers, as they may give
+// access to different data.
+func incrementalCacheKey(kind string, q *models.Query, user *backend.User, headers map[string]string) string {
+	return strings.Join([]string{
+		kind,
+		incrementalCacheScope(user, headers),
+		q.RefId,
+		q.Expr,
+		q.LegendFormat,
+		q.Step.String(),
+		strconv.FormatInt(q.UtcOffsetSec, 10),
+	}, "\x00")
+}
+
+// incrementalCacheScope returns a hash of the user and of the forwarded auth
+// headers of a request, so that the cache doesn't keep the tokens in its keys
+func incrementalCacheScope(user *backend.User, headers map[string]string) string {
+	req := &backend.QueryDataRequest{Headers: headers}
+	h := sha256.New()
+	if user != nil {
+		_, _ = h.Write([]byte(user.Login))
+	}
+	for _, name := range incrementalCacheAuthHeaders {
+		_, _ = h.Write([]byte("\x00" + name + "\x00" + req.GetHTTPHeader(name)))
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// runQuery runs query, or only the part of its time range that is not cached
+// yet when incremental querying is enabled. Queries of alert rules are never
+// cached, so they are always evaluated against the latest data.
+func (s *QueryData) runQuery(ctx context.Context, c *client.Client, q *models.Query, user *backend.User, headers map[string]string, kind string, query queryFunc) backend.DataResponse {
+	if s.incrementalCache == nil || headers["FromAlert"] == "true" {
+		return query(ctx, c, q, headers)
+	}
+	return s.incrementalQuery(ctx, c, q, user, headers, kind, query)
+}
+
+// incrementalQuery extends the cached result of q with the new tail of its time
+// range. The overlap window before the end of the cached result is queried
+// again and replaces the cached data of that window.
+func (s *QueryData) incrementalQuery(ctx context.Context, c *client.Client, q *models.Query, user *backend.User, headers map[string]string, kind string, query queryFunc) backend.DataResponse {
+	logger := s.log.FromContext(ctx)
+	cache := s.incrementalCache
+	key := incrementalCacheKey(kind, q, user, headers)
+	tr := q.TimeRange()
+
+	entry, ok := cache.get(key)
+	if !ok || tr.Start.Before(entry.start) || tr.Start.After(entry.end) || tr.End.Before(entry.end) {
+		instrumentation.UpdateIncrementalQueryCacheMetrics(instrumentation.CacheMiss)
+		res := query(ctx, c, q, headers)
+		s.storeIncrementalResult(key, tr, res)
+		return res
+	}
+
+	tailStart := models.AlignTimeRange(entry.end.Add(-cache.overlap), q.Step, q.UtcOffsetSec)
+	if tailStart.Before(tr.Start) {
+		tailStart = tr.Start
+	}
+	tail := *q
+	tail.Start = tailStart
+	logger.Debug("Querying tail of cached result", "query", q.Expr, "start", tailStart, "end", q.End)
+
+	res := query(ctx, c, &tail, headers)
+	if res.Error != nil {
+		return res
+	}
+
+	frames, err := mergeFrames(entry.frames, res.Frames, tr.Start, tailStart)
+	if err != nil {
+		logger.Debug("Failed to merge cached result, querying full range", "query", q.Expr, "error", err)
+		instrumentation.UpdateIncrementalQueryCacheMetrics(instrumentation.CacheMergeFailed)
+		res = query(ctx, c, q, headers)
+		s.storeIncrementalResult(key, tr, res)
+		return res
+	}
+
+	instrumentation.UpdateIncrementalQueryCacheMetrics(instrumentation.CacheHit)
+	res.Frames = frames
+	s.storeIncrementalResult(key, tr, res)
+	return res
+}
+
+// storeIncrementalResult caches a copy of the frames of res, so the returned
+// frames can be changed by the caller.
+func (s *QueryData) storeIncrementalResult(key string, tr models.TimeRange, res backend.DataResponse) {
+	if res.Error != nil {
+		return
+	}
+	frames, err := mergeFrames(nil, res.Frames, tr.Start, tr.Start)
+	if err != nil {
+		s.log.Debug("Failed to copy result for incremental query cache", "error", err)
+		return
+	}
+	s.incrementalCache.set(&incrementalCacheEntry{
+		key:    key,
+		start:  tr.Start,
+		end:    tr.End,
+		frames: frames,
+		size:   framesSize(frames),
+	})
+}
+
+// mergeFrames merges the rows of cached frames between start and tailStart with
+// all rows of the fresh frames. Frames are matched by name and labels, fields
+// by name. Fields missing in one of the frames are filled with zero values.
+// Frames without a time field only carry metadata and are taken from fresh if
+// no other frames are left.
+func mergeFrames(cached, fresh data.Frames, start, tailStart time.Time) (data.Frames, error) {
+	freshByKey := make(map[string]*data.Frame, len(fresh))
+	for _, frame := range fresh {
+		if timeFieldIndex(frame) >= 0 {
+			freshByKey[frameKey(frame)] = frame
+		}
+	}
+
+	keepCached := func(t time.Time) bool {
+		return !t.Before(start) && t.Before(tailStart)
+	}
+
+	merged := data.Frames{}
+	matched := map[string]bool{}
+	for _, frame := range cached {
+		if timeFieldIndex(frame) < 0 {
+			continue
+		}
+		key := frameKey(frame)
+		f, err := mergeFrame(frame, freshByKey[key], keepCached)
+		if err != nil {
+			return nil, err
+		}
+		matched[key] = true
+		if f.Rows() > 0 {
+			merged = append(merged, f)
+		}
+	}
+
+	for _, frame := range fresh {
+		if timeFieldIndex(frame) < 0 || matched[frameKey(frame)] {
+			continue
+		}
+		f, err := mergeFrame(frame, nil, func(t time.Time) bool { return !t.Before(start) })
+		if err != nil {
+			return nil, err
+		}
+		merged = append(merged, f)
+	}
+
+	if len(merged) == 0 {
+		return fresh, nil
+	}
+
+	// The executed query of the fresh result is shown in the query inspector
+	if len(fresh) > 0 && fresh[0].Meta != nil && merged[0] != fresh[0] {
+		meta := data.FrameMeta{}
+		if merged[0].Meta != nil {
+			meta = *merged[0].Meta
+		}
+		meta.ExecutedQueryString = fresh[0].Meta.ExecutedQueryString
+		merged[0].Meta = &meta
+	}
+	return merged, nil
+}
+
+// mergeFrame returns a new frame with the rows of base kept by keep followed by
+// all rows of tail, if any.
+func mergeFrame(base, tail *data.Frame, keep func(time.Time) bool) (*data.Frame, error) {
+	frame := data.NewFrame(base.Name)
+	frame.RefID = base.RefID
+	frame.Meta = base.Meta
+	if tail != nil {
+		frame.Meta = tail.Meta
+	}
+
+	fieldIdx := map[string]int{}
+	addFields := func(src *data.Frame) error {
+		for _, f := range src.Fields {
+			if i, ok := fieldIdx[f.Name]; ok {
+				if frame.Fields[i].Type() != f.Type() {
+					return fmt.Errorf("field %s of frame %s changed type from %s to %s", f.Name, base.Name, frame.Fields[i].Type(), f.Type())
+				}
+				continue
+			}
+			// fields added after rows of base are filled with zero values
+			field := data.NewFieldFromFieldType(f.Type(), frame.Rows())
+			field.Name = f.Name
+			field.Labels = f.Labels
+			field.Config = f.Config
+			fieldIdx[f.Name] = len(frame.Fields)
+			frame.Fields = append(frame.Fields, field)
+		}
+		return nil
+	}
+
+	if err := addFields(base); err != nil {
+		return nil, err
+	}
+	appendRows(frame, base, keep)
+	if tail != nil {
+		if err := addFields(tail); err != nil {
+			return nil, err
+		}
+		appendRows(frame, tail, nil)
+	}
+	return frame, nil
+}
+
+// appendRows appends the rows of src at times kept by keep to dst. dst must
+// contain all fields of src.
+func appendRows(dst, src *data.Frame, keep func(time.Time) bool) {
+	ti := timeFieldIndex(src)
+	srcIdx := make(map[string]int, len(src.Fields))
+	for i, f := range src.Fields {
+		srcIdx[f.Name] = i
+	}
+
+	for row := 0; row < src.Rows(); row++ {
+		v, ok := src.Fields[ti].ConcreteAt(row)
+		if !ok {
+			continue
+		}
+		if t, ok := v.(time.Time); !ok || (keep != nil && !keep(t)) {
+			continue
+		}
+		for _, f := range dst.Fields {
+			f.Extend(1)
+			if i, ok := srcIdx[f.Name]; ok {
+				f.Set(f.Len()-1, src.Fields[i].CopyAt(row))
+			}
+		}
+	}
+}
+
+func timeFieldIndex(frame *data.Frame) int {
+	for i, f := range frame.Fields {
+		if f.Type().Time() {
+			return i
+		}
+	}
+	return -1
+}
+
+// frameKey identifies a frame by name and labels. Field names are not part of
+// the key, as exemplar frames get new label fields when new labels are seen.
+func frameKey(frame *data.Frame) string {
+	var sb strings.Builder
+	sb.WriteString(frame.Name)
+	for _, f := range frame.Fields {
+		if len(f.Labels) > 0 {
+			sb.WriteString("\x00")
+			sb.WriteString(f.Labels.String())
+		}
+	}
+	return sb.String()
+}
+
+// framesSize returns the estimated size of the values of frames in bytes
+func framesSize(frames data.Frames) int {
+	size := 0
+	for _, frame := range frames {
+		for _, field := range frame.Fields {
+			switch field.Type() {
+			case data.FieldTypeString:
+				for i := 0; i < field.Len(); i++ {
+					size += len(field.At(i).(string))
+				}
+			case data.FieldTypeNullableString:
+				for i := 0; i < field.Len(); i++ {
+					if v, ok := field.At(i).(*string); ok && v != nil {
+						size += len(*v)
+					}
+				}
+			}
+			// the values of other types are at most 8 bytes, plus a pointer when nullable
+			size += field.Len() * 16
+		}
+	}
+	return size
+}
@@ -0,0 +1,243 @@
+package querydata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/client"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/models"
+	"github.com/grafana/grafana/pkg/tsdb/prometheus/querydata/exemplar"
+)
+
+func minutes(from, to int) []time.Time {
+	times := []time.Time{}
+	for i := from; i <= to; i++ {
+		times = append(times, time.Unix(int64(i*60), 0).UTC())
+	}
+	return times
+}
+
+func seriesFrame(labels data.Labels, times []time.Time) *data.Frame {
+	values := make([]float64, len(times))
+	for i, t := range times {
+		values[i] = float64(t.Unix())
+	}
+	return data.NewFrame("",
+		data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+		data.NewField(data.TimeSeriesValueFieldName, labels, values))
+}
+
+func TestMergeFrames(t *testing.T) {
+	start := time.Unix(60, 0).UTC()
+	tailStart := time.Unix(300, 0).UTC()
+
+	t.Run("cached rows before the tail are merged with the fresh rows", func(t *testing.T) {
+		cached := data.Frames{
+			seriesFrame(data.Labels{"job": "a"}, minutes(0, 8)),
+			seriesFrame(data.Labels{"job": "b"}, minutes(0, 3)),
+		}
+		fresh := data.Frames{
+			seriesFrame(data.Labels{"job": "a"}, minutes(5, 10)),
+			seriesFrame(data.Labels{"job": "c"}, minutes(5, 10)),
+		}
+		fresh[0].Meta = &data.FrameMeta{ExecutedQueryString: "tail"}
+
+		merged, err := mergeFrames(cached, fresh, start, tailStart)
+		require.NoError(t, err)
+		require.Len(t, merged, 3)
+
+		require.Equal(t, data.Labels{"job": "a"}, merged[0].Fields[1].Labels)
+		require.Equal(t, minutes(1, 10), fieldTimes(merged[0]))
+		require.Equal(t, float64(600), merged[0].Fields[1].At(9))
+		require.Equal(t, "tail", merged[0].Meta.ExecutedQueryString)
+
+		// series without new data keep their cached rows
+		require.Equal(t, data.Labels{"job": "b"}, merged[1].Fields[1].Labels)
+		require.Equal(t, minutes(1, 3), fieldTimes(merged[1]))
+
+		require.Equal(t, data.Labels{"job": "c"}, merged[2].Fields[1].Labels)
+		require.Equal(t, minutes(5, 10), fieldTimes(merged[2]))
+	})
+
+	t.Run("exemplar frames with new label fields are merged", func(t *testing.T) {
+		cached := data.NewFrame("exemplar",
+			data.NewField(data.TimeSeriesTimeFieldName, nil, minutes(1, 2)),
+			data.NewField(data.TimeSeriesValueFieldName, nil, []float64{1, 2}),
+			data.NewField("traceID", nil, []string{"a", "b"}))
+		fresh := data.NewFrame("exemplar",
+			data.NewField(data.TimeSeriesTimeFieldName, nil, minutes(6, 6)),
+			data.NewField(data.TimeSeriesValueFieldName, nil, []float64{6}),
+			data.NewField("traceID", nil, []string{"c"}),
+			data.NewField("pod", nil, []string{"p"}))
+
+		merged, err := mergeFrames(data.Frames{cached}, data.Frames{fresh}, start, tailStart)
+		require.NoError(t, err)
+		require.Len(t, merged, 1)
+		require.Len(t, merged[0].Fields, 4)
+		require.Equal(t, 3, merged[0].Rows())
+		require.Equal(t, "c", merged[0].Fields[2].At(2))
+		require.Equal(t, "", merged[0].Fields[3].At(0))
+		require.Equal(t, "p", merged[0].Fields[3].At(2))
+	})
+
+	t.Run("fields changing type can not be merged", func(t *testing.T) {
+		cached := seriesFrame(nil, minutes(1, 2))
+		fresh := data.NewFrame("",
+			data.NewField(data.TimeSeriesTimeFieldName, nil, minutes(6, 6)),
+			data.NewField(data.TimeSeriesValueFieldName, nil, []string{"6"}))
+
+		_, err := mergeFrames(data.Frames{cached}, data.Frames{fresh}, start, tailStart)
+		require.Error(t, err)
+	})
+
+	t.Run("frames without time field are kept if there is no data", func(t *testing.T) {
+		fresh := data.Frames{data.NewFrame("")}
+		merged, err := mergeFrames(nil, fresh, start, tailStart)
+		require.NoError(t, err)
+		require.Equal(t, fresh, merged)
+	})
+}
+
+func fieldTimes(frame *data.Frame) []time.Time {
+	times := make([]time.Time, frame.Rows())
+	for i := range times {
+		times[i] = frame.Fields[0].At(i).(time.Time)
+	}
+	return times
+}
+
+func TestIncrementalCache(t *testing.T) {
+	c := newIncrementalCache(2, 100, time.Minute)
+	c.set(&incrementalCacheEntry{key: "a"})
+	c.set(&incrementalCacheEntry{key: "b"})
+	_, ok := c.get("a")
+	require.True(t, ok)
+
+	// b is the least recently used result
+	c.set(&incrementalCacheEntry{key: "c"})
+	require.Equal(t, 2, c.len())
+	_, ok = c.get("b")
+	require.False(t, ok)
+	_, ok = c.get("a")
+	require.True(t, ok)
+
+	t.Run("results are evicted when the cache is larger than its max size", func(t *testing.T) {
+		c := newIncrementalCache(10, 100, time.Minute)
+		c.set(&incrementalCacheEntry{key: "a", size: 60})
+		c.set(&incrementalCacheEntry{key: "b", size: 30})
+		c.set(&incrementalCacheEntry{key: "b", size: 40})
+		require.Equal(t, 100, c.bytes)
+
+		c.set(&incrementalCacheEntry{key: "c", size: 10})
+		_, ok := c.get("a")
+		require.False(t, ok)
+		require.Equal(t, 50, c.bytes)
+
+		// results larger than the cache are not cached
+		c.set(&incrementalCacheEntry{key: "d", size: 101})
+		_, ok = c.get("d")
+		require.False(t, ok)
+		require.Equal(t, 2, c.len())
+	})
+}
+
+func TestIncrementalCacheKey(t *testing.T) {
+	q := &models.Query{RefId: "A", Expr: "up", Step: time.Minute}
+	alice := &backend.User{Login: "alice"}
+	key := incrementalCacheKey(rangeQueryKind, q, alice, nil)
+
+	require.Equal(t, key, incrementalCacheKey(rangeQueryKind, q, alice, map[string]string{"X-Panel-Id": "2"}))
+	require.NotEqual(t, key, incrementalCacheKey(rangeQueryKind, q, &backend.User{Login: "bob"}, nil))
+	require.NotEqual(t, key, incrementalCacheKey(rangeQueryKind, q, alice, map[string]string{"Authorization": "Bearer token"}))
+	require.NotEqual(t,
+		incrementalCacheKey(rangeQueryKind, q, alice, map[string]string{"http_Authorization": "Bearer a"}),
+		incrementalCacheKey(rangeQueryKind, q, alice, map[string]string{"http_Authorization": "Bearer b"}),
+	)
+	require.NotContains(t, incrementalCacheKey(rangeQueryKind, q, alice, map[string]string{"Authorization": "Bearer token"}), "token")
+}
+
+// rangeServer returns a series with the unix time as value at each step of the
+// requested range and records the requested start times.
+type rangeServer struct {
+	starts []int64
+}
+
+func (s *rangeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	start, _ := strconv.ParseInt(r.URL.Query().Get("start"), 10, 64)
+	end, _ := strconv.ParseInt(r.URL.Query().Get("end"), 10, 64)
+	step, _ := strconv.ParseInt(r.URL.Query().Get("step"), 10, 64)
+	s.starts = append(s.starts, start)
+
+	values := []string{}
+	for t := start; t <= end; t += step {
+		values = append(values, fmt.Sprintf(`[%d,"%d"]`, t, t))
+	}
+	_, _ = fmt.Fprintf(w, `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"job":"api"},"values":[%s]}]}}`, strings.Join(values, ","))
+}
+
+func TestQueryData_incrementalQuery(t *testing.T) {
+	srv := &rangeServer{}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	qd := &QueryData{
+		intervalCalculator: intervalv2.NewCalculator(),
+		tracer:             tracing.DefaultTracer(),
+		log:                log.New(),
+		client:             client.NewClient(http.DefaultClient, http.MethodGet, ts.URL),
+		exemplarSampler:    exemplar.NewNoOpSampler,
+		incrementalCache:   newIncrementalCache(incrementalQueryCacheSize, incrementalQueryCacheMaxBytes, 5*time.Minute),
+	}
+
+	query := func(from, to int64, headers map[string]string) *data.Frame {
+		req := &backend.QueryDataRequest{
+			Headers: headers,
+			Queries: []backend.DataQuery{{
+				RefID:     "A",
+				JSON:      json.RawMessage(`{"expr":"up","range":true,"interval":"1m"}`),
+				TimeRange: backend.TimeRange{From: time.Unix(from, 0), To: time.Unix(to, 0)},
+			}},
+		}
+		res, err := qd.Execute(context.Background(), req)
+		require.NoError(t, err)
+		require.NoError(t, res.Responses["A"].Error)
+		require.Len(t, res.Responses["A"].Frames, 1)
+		return res.Responses["A"].Frames[0]
+	}
+
+	frame := query(0, 3600, nil)
+	require.Equal(t, 61, frame.Rows())
+	require.Equal(t, []int64{0}, srv.starts)
+
+	// only the new tail and the overlap window are queried
+	frame = query(600, 4200, nil)
+	require.Equal(t, []int64{0, 3300}, srv.starts)
+	require.Equal(t, 61, frame.Rows())
+	require.Equal(t, time.Unix(600, 0).UTC(), frame.Fields[0].At(0))
+	require.Equal(t, time.Unix(4200, 0).UTC(), frame.Fields[0].At(60))
+	for i := 0; i < frame.Rows(); i++ {
+		require.Equal(t, float64(600+i*60), frame.Fields[1].At(i))
+	}
+
+	// ranges before the cached result are queried in full
+	query(0, 4200, nil)
+	require.Equal(t, []int64{0, 3300, 0}, srv.starts)
+
+	// alert queries are not cached
+	query(0, 4800, map[string]string{"FromAlert": "true"})
+	require.Equal(t, []int64{0, 3300, 0, 0}, srv.starts)
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
@@ -45,6 +46,9 @@ type QueryData struct {
 	TimeInterval       string
 	enableDataplane    bool
 	exemplarSampler    func() exemplar.Sampler
+	// sampler of the exemplars of native histograms, nil when exemplars are not sampled
+	histogramExemplarSampler func(bounds []float64) exemplar.Sampler
+	incrementalCache         *incrementalCache
 }
 
 func New(
@@ -64,25 +68,50 @@ func New(
 		return nil, err
 	}
 
+	incrementalQuerying, err := maputil.GetBoolOptional(jsonData, "incrementalQuerying")
+	if err != nil {
+		return nil, err
+	}
+
+	var cache *incrementalCache
+	if incrementalQuerying {
+		overlap := defaultIncrementalQueryOverlapWindow
+		overlapWindow, err := maputil.GetStringOptional(jsonData, "incrementalQueryOverlapWindow")
+		if err != nil {
+			return nil, err
+		}
+		if overlapWindow != "" {
+			overlap, err = gtime.ParseDuration(overlapWindow)
+			if err != nil {
+				return nil, fmt.Errorf("invalid incremental query overlap window: %w", err)
+			}
+		}
+		cache = newIncrementalCache(incrementalQueryCacheSize, incrementalQueryCacheMaxBytes, overlap)
+	}
+
 	promClient := client.NewClient(httpClient, httpMethod, settings.URL)
 
 	// standard deviation sampler is the default for backwards compatibility
 	exemplarSampler := exemplar.NewStandardDeviationSampler
+	histogramExemplarSampler := exemplar.NewHeatmapCellSampler
 
 	if features.IsEnabled(featuremgmt.FlagDisablePrometheusExemplarSampling) {
 		exemplarSampler = exemplar.NewNoOpSampler
+		histogramExemplarSampler = nil
 	}
 
 	return &QueryData{
-		intervalCalculator: intervalv2.NewCalculator(),
-		tracer:             tracing.DefaultTracer(),
-		log:                plog,
-		client:             promClient,
-		TimeInterval:       timeInterval,
-		ID:                 settings.ID,
-		URL:                settings.URL,
-		enableDataplane:    features.IsEnabled(featuremgmt.FlagPrometheusDataplane),
-		exemplarSampler:    exemplarSampler,
+		intervalCalculator:       intervalv2.NewCalculator(),
+		tracer:                   tracing.DefaultTracer(),
+		log:                      plog,
+		client:                   promClient,
+		TimeInterval:             timeInterval,
+		ID:                       settings.ID,
+		URL:                      settings.URL,
+		enableDataplane:          features.IsEnabled(featuremgmt.FlagPrometheusDataplane),
+		exemplarSampler:          exemplarSampler,
+		histogramExemplarSampler: histogramExemplarSampler,
+		incrementalCache:         cache,
 	}, nil
 }
 
@@ -97,7 +126,7 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 		if err != nil {
 			return &result, err
 		}
-		r := s.fetch(ctx, s.client, query, req.Headers)
+		r := s.fetch(ctx, s.client, query, req.PluginContext.User, req.Headers)
 		if r == nil {
 			s.log.FromContext(ctx).Debug("Received nil response from runQuery", "query", query.Expr)
 			continue
@@ -108,7 +137,7 @@ func (s *QueryData) Execute(ctx context.Context, req *backend.QueryDataRequest)
 	return &result, nil
 }
 
-func (s *QueryData) fetch(ctx context.Context, client *client.Client, q *models.Query, headers map[string]string) *backend.DataResponse {
+func (s *QueryData) fetch(ctx context.Context, client *client.Client, q *models.Query, user *backend.User, headers map[string]string) *backend.DataResponse {
 	traceCtx, end := s.trace(ctx, q)
 	defer end()
 
@@ -127,7 +156,7 @@ func (s *QueryData) fetch(ctx context.Context, client *client.Client, q *models.
 	}
 
 	if q.RangeQuery {
-		res := s.rangeQuery(traceCtx, client, q, headers)
+		res := s.runQuery(traceCtx, client, q, user, headers, rangeQueryKind, s.rangeQuery)
 		if res.Error != nil {
 			if dr.Error == nil {
 				dr.Error = res.Error
@@ -139,7 +168,13 @@ func (s *QueryData) fetch(ctx context.Context, client *client.Client, q *models.
 	}
 
 	if q.ExemplarQuery {
-		res := s.exemplarQuery(traceCtx, client, q, headers)
+		query := s.exemplarQuery
+		// exemplars of native histograms are aligned to the heatmap cells of the
+		// histograms, so that each cell with exemplars keeps one
+		if bounds := nativeHistogramBounds(dr.Frames); len(bounds) > 0 && s.histogramExemplarSampler != nil {
+			query = s.sampledExemplarQuery(func() exemplar.Sampler { return s.histogramExemplarSampler(bounds) })
+		}
+		res := s.runQuery(traceCtx, client, q, user, headers, exemplarQueryKind, query)
 		if res.Error != nil {
 			// If exemplar query returns error, we want to only log it and
 			// continue with other results processing
@@ -195,20 +230,27 @@ func (s *QueryData) instantQuery(ctx context.Context, c *client.Client, q *model
 }
 
 func (s *QueryData) exemplarQuery(ctx context.Context, c *client.Client, q *models.Query, headers map[string]string) backend.DataResponse {
-	res, err := c.QueryExemplars(ctx, q)
-	if err != nil {
-		return backend.DataResponse{
-			Error: err,
-		}
-	}
+	return s.sampledExemplarQuery(s.exemplarSampler)(ctx, c, q, headers)
+}
 
-	defer func() {
-		err := res.Body.Close()
+// sampledExemplarQuery returns the query of exemplars sampled by the samplers of newSampler
+func (s *QueryData) sampledExemplarQuery(newSampler func() exemplar.Sampler) queryFunc {
+	return func(ctx context.Context, c *client.Client, q *models.Query, headers map[string]string) backend.DataResponse {
+		res, err := c.QueryExemplars(ctx, q)
 		if err != nil {
-			s.log.Warn("Failed to close response body", "error", err)
+			return backend.DataResponse{
+				Error: err,
+			}
 		}
-	}()
-	return s.parseResponse(ctx, q, res)
+
+		defer func() {
+			err := res.Body.Close()
+			if err != nil {
+				s.log.Warn("Failed to close response body", "error", err)
+			}
+		}()
+		return s.parseResponseWithSampler(ctx, q, res, newSampler)
+	}
 }
 
 func (s *QueryData) trace(ctx context.Context, q *models.Query) (context.Context, func()) {
@@ -19,6 +19,10 @@ import (
 )
 
 func (s *QueryData) parseResponse(ctx context.Context, q *models.Query, res *http.Response) backend.DataResponse {
+	return s.parseResponseWithSampler(ctx, q, res, s.exemplarSampler)
+}
+
+func (s *QueryData) parseResponseWithSampler(ctx context.Context, q *models.Query, res *http.Response, newSampler func() exemplar.Sampler) backend.DataResponse {
 	defer func() {
 		if err := res.Body.Close(); err != nil {
 			s.log.FromContext(ctx).Error("Failed to close response body", "err", err)
@@ -47,16 +51,15 @@ func (s *QueryData) parseResponse(ctx context.Context, q *models.Query, res *htt
 	}
 
 	if r.Error == nil {
-		r = s.processExemplars(ctx, q, r)
+		r = s.processExemplars(ctx, q, r, newSampler())
 	}
 
 	return r
 }
 
-func (s *QueryData) processExemplars(ctx context.Context, q *models.Query, dr backend.DataResponse) backend.DataResponse {
+func (s *QueryData) processExemplars(ctx context.Context, q *models.Query, dr backend.DataResponse, sampler exemplar.Sampler) backend.DataResponse {
 	_, endSpan := utils.StartTrace(ctx, s.tracer, "datasource.prometheus.processExemplars")
 	defer endSpan()
-	sampler := s.exemplarSampler()
 	labelTracker := exemplar.NewLabelTracker()
 
 	// we are moving from a multi-frame response returned
@@ -114,6 +117,14 @@ func addMetadataToMultiFrame(q *models.Query, frame *data.Frame, enableDataplane
 	}
 	frame.Fields[0].Config = &data.FieldConfig{Interval: float64(q.Step.Milliseconds())}
 
+	// Native histograms are returned as heatmap cells, where the series labels
+	// are stored on the yMin field. The cell fields keep their names so the
+	// frame can be read as heatmap.
+	if converter.IsNativeHistogramFrame(frame) {
+		frame.Name = getName(q, frame.Fields[1])
+		return
+	}
+
 	customName := getName(q, frame.Fields[1])
 	if customName != "" {
 		frame.Fields[1].Config = &data.FieldConfig{DisplayNameFromDS: customName}
@@ -189,6 +200,32 @@ func getName(q *models.Query, field *data.Field) string {
 	return legend
 }
 
+// nativeHistogramBounds returns the sorted upper bounds of the buckets of the
+// native histograms of frames
+func nativeHistogramBounds(frames data.Frames) []float64 {
+	seen := map[float64]bool{}
+	var bounds []float64
+	for _, frame := range frames {
+		if !converter.IsNativeHistogramFrame(frame) {
+			continue
+		}
+		field, idx := frame.FieldByName("yMax")
+		if idx < 0 {
+			continue
+		}
+		for i := 0; i < field.Len(); i++ {
+			v, err := field.FloatAt(i)
+			if err != nil || seen[v] {
+				continue
+			}
+			seen[v] = true
+			bounds = append(bounds, v)
+		}
+	}
+	sort.Float64s(bounds)
+	return bounds
+}
+
 func isExemplarFrame(frame *data.Frame) bool {
 	rt := models.ResultTypeFromFrame(frame)
 	return rt == models.ResultTypeExemplar
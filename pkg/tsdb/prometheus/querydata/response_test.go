@@ -6,11 +6,13 @@ import (
 	"io"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/models"
 	"github.com/grafana/grafana/pkg/tsdb/prometheus/querydata/exemplar"
+	"github.com/grafana/grafana/pkg/util/converter"
 )
 
 func TestQueryData_parseResponse(t *testing.T) {
@@ -55,4 +57,22 @@ func TestQueryData_parseResponse(t *testing.T) {
 		assert.Error(t, result.Error)
 		assert.Equal(t, result.Error.Error(), "unknown result type: ")
 	})
+
+	t.Run("native histograms are returned as heatmap cells", func(t *testing.T) {
+		resBody := `{"data":{"resultType":"matrix","result":[{"metric":{"__name__":"http_request_duration_seconds","job":"api"},"histograms":[[1649963300,{"count":"3","sum":"0.6","buckets":[[0,"0.1","0.2","1"],[0,"0.2","0.4","2"]]}]]}]},"status":"success"}`
+		res := &http.Response{Body: io.NopCloser(bytes.NewBufferString(resBody))}
+		qd := QueryData{exemplarSampler: exemplar.NewStandardDeviationSampler, enableDataplane: true}
+		result := qd.parseResponse(context.Background(), &models.Query{LegendFormat: "{{job}}", Step: time.Minute}, res)
+		assert.Nil(t, result.Error)
+		assert.Len(t, result.Frames, 1)
+
+		frame := result.Frames[0]
+		assert.Equal(t, converter.NativeHistogramFrameType, frame.Meta.Type)
+		assert.Equal(t, "api", frame.Name)
+		assert.Equal(t, float64(time.Minute.Milliseconds()), frame.Fields[0].Config.Interval)
+		assert.Equal(t, "yMin", frame.Fields[1].Name)
+		assert.Nil(t, frame.Fields[1].Config)
+		assert.Equal(t, 2, frame.Rows())
+		assert.Equal(t, []float64{0.2, 0.4}, nativeHistogramBounds(result.Frames))
+	})
 }
@@ -0,0 +1,213 @@
+package sqleng
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+)
+
+const (
+	// DefaultQueryCacheTTL is used when the query cache is enabled without a TTL.
+	DefaultQueryCacheTTL = time.Minute
+	// DefaultQueryCacheMaxSize is the default max size of one cached query result.
+	DefaultQueryCacheMaxSize = 1024 * 1024
+	// DefaultQueryCacheMaxTotalSize is the default max size of all query
+	// results of a data source cached by a Grafana instance.
+	DefaultQueryCacheMaxTotalSize = 64 * 1024 * 1024
+
+	queryCacheKeyPrefix = "sqleng-query-cache-"
+)
+
+var timeNow = time.Now
+
+var queryCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "grafana",
+	Name:      "sql_datasource_query_cache_requests_total",
+	Help:      "The total amount of SQL data source query cache lookups",
+}, []string{"datasource_uid", "result"})
+
+// queryCache stores results of SQL queries in the remote cache, so identical
+// queries run by multiple panels or Grafana instances are executed once per TTL.
+type queryCache struct {
+	storage      remotecache.CacheStorage
+	ttl          time.Duration
+	maxSize      int
+	maxTotalSize int
+	dsUID        string
+	// version invalidates entries when the data source is updated.
+	version int64
+	log     log.Logger
+
+	// Entries stored by this instance which have not expired yet. All entries
+	// have the same TTL, so they expire in the order they were stored.
+	mu        sync.Mutex
+	entries   []queryCacheEntry
+	totalSize int
+}
+
+type queryCacheEntry struct {
+	expires time.Time
+	size    int
+}
+
+func newQueryCache(storage remotecache.CacheStorage, dsInfo DataSourceInfo, logger log.Logger) *queryCache {
+	if storage == nil || !dsInfo.JsonData.QueryCacheEnabled {
+		return nil
+	}
+	c := &queryCache{
+		storage:      storage,
+		ttl:          DefaultQueryCacheTTL,
+		maxSize:      DefaultQueryCacheMaxSize,
+		maxTotalSize: DefaultQueryCacheMaxTotalSize,
+		dsUID:        dsInfo.UID,
+		version:      dsInfo.Updated.UnixNano(),
+		log:          logger,
+	}
+	if dsInfo.JsonData.QueryCacheTTL > 0 {
+		c.ttl = time.Duration(dsInfo.JsonData.QueryCacheTTL) * time.Second
+	}
+	if dsInfo.JsonData.QueryCacheMaxSizeKb > 0 {
+		c.maxSize = dsInfo.JsonData.QueryCacheMaxSizeKb * 1024
+	}
+	if dsInfo.JsonData.QueryCacheMaxTotalSizeMb > 0 {
+		c.maxTotalSize = dsInfo.JsonData.QueryCacheMaxTotalSizeMb * 1024 * 1024
+	}
+	return c
+}
+
+// roundTimeRange rounds the time range to the cache TTL. Macros expand the
+// time range into the SQL, so without rounding consecutive refreshes would
+// never produce the same query. The end of the range is rounded up, so the
+// newest data is still queried.
+func (c *queryCache) roundTimeRange(tr backend.TimeRange) backend.TimeRange {
+	to := tr.To.Truncate(c.ttl)
+	if to.Before(tr.To) {
+		to = to.Add(c.ttl)
+	}
+	return backend.TimeRange{
+		From: tr.From.Truncate(c.ttl),
+		To:   to,
+	}
+}
+
+// key returns the cache key of the interpolated query. The query model is part
+// of the key because fill and format options are applied after the query.
+func (c *queryCache) key(query backend.DataQuery, queryJson QueryJson, interpolatedQuery string) (string, error) {
+	model, err := json.Marshal(queryJson)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.New()
+	for _, part := range []string{
+		c.dsUID,
+		strconv.FormatInt(c.version, 10),
+		interpolatedQuery,
+		string(model),
+		strconv.FormatInt(query.TimeRange.From.UnixMilli(), 10),
+		strconv.FormatInt(query.TimeRange.To.UnixMilli(), 10),
+		strconv.FormatInt(query.Interval.Milliseconds(), 10),
+		strconv.FormatInt(query.MaxDataPoints, 10),
+	} {
+		// Separator avoids collisions between concatenated parts.
+		_, _ = h.Write([]byte(part))
+		_, _ = h.Write([]byte{0})
+	}
+	return queryCacheKeyPrefix + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// get returns cached frames of key. Cache errors are logged and reported as
+// a miss, the query is executed in that case.
+func (c *queryCache) get(ctx context.Context, key string) (data.Frames, bool) {
+	value, err := c.storage.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, remotecache.ErrCacheItemNotFound) {
+			c.log.Warn("Failed to get query result from cache", "error", err)
+		}
+		queryCacheRequests.WithLabelValues(c.dsUID, "miss").Inc()
+		return nil, false
+	}
+
+	var encoded [][]byte
+	if err := json.Unmarshal(value, &encoded); err != nil {
+		c.log.Warn("Failed to decode cached query result", "error", err)
+		queryCacheRequests.WithLabelValues(c.dsUID, "miss").Inc()
+		return nil, false
+	}
+	frames, err := data.UnmarshalArrowFrames(encoded)
+	if err != nil {
+		c.log.Warn("Failed to decode cached query result", "error", err)
+		queryCacheRequests.WithLabelValues(c.dsUID, "miss").Inc()
+		return nil, false
+	}
+
+	queryCacheRequests.WithLabelValues(c.dsUID, "hit").Inc()
+	return frames, true
+}
+
+// set stores frames in the cache unless the encoded result exceeds the max
+// size, or the results stored by this instance would exceed the max total size.
+func (c *queryCache) set(ctx context.Context, key string, frames data.Frames) {
+	value, err := encodeFrames(frames)
+	if err != nil {
+		c.log.Warn("Failed to encode query result for cache", "error", err)
+		return
+	}
+	if len(value) > c.maxSize {
+		c.log.Debug("Query result too large to cache", "size", len(value), "maxSize", c.maxSize)
+		return
+	}
+	if !c.reserve(len(value)) {
+		c.log.Debug("Query cache is full", "size", len(value), "maxTotalSize", c.maxTotalSize)
+		return
+	}
+	if err := c.storage.Set(ctx, key, value, c.ttl); err != nil {
+		c.log.Warn("Failed to store query result in cache", "error", err)
+	}
+}
+
+// reserve accounts size in the total size of the results stored by this
+// instance, it returns false when the max total size would be exceeded.
+func (c *queryCache) reserve(size int) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := timeNow()
+	expired := 0
+	for _, e := range c.entries {
+		if e.expires.After(now) {
+			break
+		}
+		c.totalSize -= e.size
+		expired++
+	}
+	c.entries = c.entries[expired:]
+
+	if c.totalSize+size > c.maxTotalSize {
+		return false
+	}
+	c.entries = append(c.entries, queryCacheEntry{expires: now.Add(c.ttl), size: size})
+	c.totalSize += size
+	return true
+}
+
+func encodeFrames(frames data.Frames) ([]byte, error) {
+	encoded, err := frames.MarshalArrow()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal frames: %w", err)
+	}
+	return json.Marshal(encoded)
+}
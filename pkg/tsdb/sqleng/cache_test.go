@@ -0,0 +1,132 @@
+package sqleng
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+)
+
+func TestQueryCache(t *testing.T) {
+	dsInfo := DataSourceInfo{
+		UID:      "ds",
+		Updated:  time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		JsonData: JsonData{QueryCacheEnabled: true, QueryCacheTTL: 30},
+	}
+
+	t.Run("is disabled by default", func(t *testing.T) {
+		require.Nil(t, newQueryCache(remotecache.NewFakeCacheStorage(), DataSourceInfo{}, log.NewNopLogger()))
+		require.Nil(t, newQueryCache(nil, dsInfo, log.NewNopLogger()))
+	})
+
+	t.Run("rounds time range to TTL", func(t *testing.T) {
+		c := newQueryCache(remotecache.NewFakeCacheStorage(), dsInfo, log.NewNopLogger())
+		from := time.Date(2023, 1, 1, 10, 0, 12, 0, time.UTC)
+		tr := c.roundTimeRange(backend.TimeRange{From: from, To: from.Add(time.Hour + 45*time.Second)})
+		require.Equal(t, time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC), tr.From)
+		require.Equal(t, time.Date(2023, 1, 1, 11, 1, 0, 0, time.UTC), tr.To)
+
+		rounded := c.roundTimeRange(tr)
+		require.Equal(t, tr, rounded)
+	})
+
+	t.Run("key depends on query, model and data source version", func(t *testing.T) {
+		c := newQueryCache(remotecache.NewFakeCacheStorage(), dsInfo, log.NewNopLogger())
+		query := backend.DataQuery{TimeRange: backend.TimeRange{From: time.UnixMilli(0), To: time.UnixMilli(1000)}}
+		model := QueryJson{RawSql: "SELECT 1", Format: "table"}
+
+		key, err := c.key(query, model, "SELECT 1")
+		require.NoError(t, err)
+		same, err := c.key(query, model, "SELECT 1")
+		require.NoError(t, err)
+		require.Equal(t, key, same)
+
+		otherSQL, err := c.key(query, model, "SELECT 2")
+		require.NoError(t, err)
+		require.NotEqual(t, key, otherSQL)
+
+		otherModel, err := c.key(query, QueryJson{RawSql: "SELECT 1", Format: "time_series"}, "SELECT 1")
+		require.NoError(t, err)
+		require.NotEqual(t, key, otherModel)
+
+		updated := dsInfo
+		updated.Updated = updated.Updated.Add(time.Second)
+		otherVersion, err := newQueryCache(c.storage, updated, log.NewNopLogger()).key(query, model, "SELECT 1")
+		require.NoError(t, err)
+		require.NotEqual(t, key, otherVersion)
+	})
+
+	t.Run("stores and returns frames", func(t *testing.T) {
+		c := newQueryCache(remotecache.NewFakeCacheStorage(), dsInfo, log.NewNopLogger())
+		_, ok := c.get(context.Background(), "key")
+		require.False(t, ok)
+
+		frame := data.NewFrame("",
+			data.NewField("time", nil, []time.Time{time.UnixMilli(1000).UTC()}),
+			data.NewField("value", nil, []*float64{nil}),
+		)
+		frame.SetMeta(&data.FrameMeta{ExecutedQueryString: "SELECT 1"})
+		c.set(context.Background(), "key", data.Frames{frame})
+
+		frames, ok := c.get(context.Background(), "key")
+		require.True(t, ok)
+		require.Len(t, frames, 1)
+		require.Equal(t, 1, frames[0].Rows())
+		require.True(t, time.UnixMilli(1000).Equal(frames[0].Fields[0].At(0).(time.Time)))
+		require.Nil(t, frames[0].Fields[1].At(0))
+		require.Equal(t, "SELECT 1", frames[0].Meta.ExecutedQueryString)
+	})
+
+	t.Run("stores empty frames", func(t *testing.T) {
+		c := newQueryCache(remotecache.NewFakeCacheStorage(), dsInfo, log.NewNopLogger())
+		c.set(context.Background(), "key", data.Frames{data.NewFrame("")})
+		frames, ok := c.get(context.Background(), "key")
+		require.True(t, ok)
+		require.Len(t, frames, 1)
+		require.Equal(t, 0, frames[0].Rows())
+	})
+
+	t.Run("does not store results larger than max size", func(t *testing.T) {
+		limited := dsInfo
+		limited.JsonData.QueryCacheMaxSizeKb = 1
+		c := newQueryCache(remotecache.NewFakeCacheStorage(), limited, log.NewNopLogger())
+
+		values := make([]float64, 1000)
+		c.set(context.Background(), "key", data.Frames{data.NewFrame("", data.NewField("value", nil, values))})
+		_, ok := c.get(context.Background(), "key")
+		require.False(t, ok)
+	})
+
+	t.Run("does not store results exceeding max total size until stored results expire", func(t *testing.T) {
+		now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		origTimeNow := timeNow
+		timeNow = func() time.Time { return now }
+		t.Cleanup(func() { timeNow = origTimeNow })
+
+		c := newQueryCache(remotecache.NewFakeCacheStorage(), dsInfo, log.NewNopLogger())
+		frames := data.Frames{data.NewFrame("", data.NewField("value", nil, make([]float64, 100)))}
+		value, err := encodeFrames(frames)
+		require.NoError(t, err)
+		c.maxTotalSize = 2 * len(value)
+
+		c.set(context.Background(), "a", frames)
+		c.set(context.Background(), "b", frames)
+		c.set(context.Background(), "c", frames)
+		_, ok := c.get(context.Background(), "b")
+		require.True(t, ok)
+		_, ok = c.get(context.Background(), "c")
+		require.False(t, ok)
+
+		now = now.Add(c.ttl)
+		c.set(context.Background(), "c", frames)
+		_, ok = c.get(context.Background(), "c")
+		require.True(t, ok)
+		require.Equal(t, len(value), c.totalSize)
+	})
+}
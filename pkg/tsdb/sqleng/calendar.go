@@ -0,0 +1,192 @@
+package sqleng
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+// MaxCalendarBuckets is the max number of buckets generated by $__timeBucketFill.
+const MaxCalendarBuckets = 100000
+
+// CalendarUnit is a calendar aligned time unit used by the $__timeBucket macros.
+type CalendarUnit string
+
+const (
+	CalendarUnitMinute  CalendarUnit = "minute"
+	CalendarUnitHour    CalendarUnit = "hour"
+	CalendarUnitDay     CalendarUnit = "day"
+	CalendarUnitWeek    CalendarUnit = "week"
+	CalendarUnitMonth   CalendarUnit = "month"
+	CalendarUnitQuarter CalendarUnit = "quarter"
+	CalendarUnitYear    CalendarUnit = "year"
+)
+
+// ParseCalendarUnit parses a macro argument, optionally quoted, into a calendar unit.
+func ParseCalendarUnit(arg string) (CalendarUnit, error) {
+	unit := CalendarUnit(strings.ToLower(strings.Trim(arg, `'"`)))
+	switch unit {
+	case CalendarUnitMinute, CalendarUnitHour, CalendarUnitDay, CalendarUnitWeek,
+		CalendarUnitMonth, CalendarUnitQuarter, CalendarUnitYear:
+		return unit, nil
+	}
+	return "", fmt.Errorf("invalid calendar unit %v, expected one of minute, hour, day, week, month, quarter or year", arg)
+}
+
+// Truncate returns the start of the bucket containing t, in the location of t.
+// Weeks start on Monday.
+func (u CalendarUnit) Truncate(t time.Time) time.Time {
+	y, m, d := t.Date()
+	loc := t.Location()
+	switch u {
+	case CalendarUnitMinute:
+		return time.Date(y, m, d, t.Hour(), t.Minute(), 0, 0, loc)
+	case CalendarUnitHour:
+		return time.Date(y, m, d, t.Hour(), 0, 0, 0, loc)
+	case CalendarUnitWeek:
+		return time.Date(y, m, d-(int(t.Weekday())+6)%7, 0, 0, 0, 0, loc)
+	case CalendarUnitMonth:
+		return time.Date(y, m, 1, 0, 0, 0, 0, loc)
+	case CalendarUnitQuarter:
+		return time.Date(y, m-(m-1)%3, 1, 0, 0, 0, 0, loc)
+	case CalendarUnitYear:
+		return time.Date(y, 1, 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+}
+
+// Add adds n units to t using the calendar of the location of t.
+func (u CalendarUnit) Add(t time.Time, n int) time.Time {
+	switch u {
+	case CalendarUnitMinute:
+		return t.Add(time.Duration(n) * time.Minute)
+	case CalendarUnitHour:
+		return t.Add(time.Duration(n) * time.Hour)
+	case CalendarUnitWeek:
+		return t.AddDate(0, 0, 7*n)
+	case CalendarUnitMonth:
+		return t.AddDate(0, n, 0)
+	case CalendarUnitQuarter:
+		return t.AddDate(0, 3*n, 0)
+	case CalendarUnitYear:
+		return t.AddDate(n, 0, 0)
+	default:
+		return t.AddDate(0, 0, n)
+	}
+}
+
+// QueryTimezone returns the dashboard timezone sent in the query model. Queries
+// without a timezone, or using the browser timezone, are evaluated in UTC.
+func QueryTimezone(query *backend.DataQuery) (*time.Location, error) {
+	if query == nil || len(query.JSON) == 0 {
+		return time.UTC, nil
+	}
+	model := struct {
+		Timezone string `json:"timezone"`
+	}{}
+	if err := json.Unmarshal(query.JSON, &model); err != nil {
+		return nil, fmt.Errorf("error unmarshal query json: %w", err)
+	}
+	switch strings.ToLower(model.Timezone) {
+	case "", "browser", "utc":
+		return time.UTC, nil
+	}
+	// Timezone names end up in the SQL, only allow names of the tz database.
+	if strings.ContainsAny(model.Timezone, `'"\;`) {
+		return nil, fmt.Errorf("invalid timezone %q", model.Timezone)
+	}
+	loc, err := time.LoadLocation(model.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", model.Timezone, err)
+	}
+	return loc, nil
+}
+
+// TimeBucketArgs returns the calendar unit of the unit argument of a calendar
+// time bucket macro and the timezone of the query the buckets are computed in.
+func TimeBucketArgs(query *backend.DataQuery, unitArg string) (CalendarUnit, *time.Location, error) {
+	unit, err := ParseCalendarUnit(unitArg)
+	if err != nil {
+		return "", nil, err
+	}
+	loc, err := QueryTimezone(query)
+	if err != nil {
+		return "", nil, err
+	}
+	return unit, loc, nil
+}
+
+// CalendarBuckets returns the start of the first bucket of the time range in loc
+// and the number of buckets until the end of the time range.
+func CalendarBuckets(timeRange backend.TimeRange, unit CalendarUnit, loc *time.Location) (time.Time, int, error) {
+	start := unit.Truncate(timeRange.From.In(loc))
+	to := timeRange.To.In(loc)
+	count := 0
+	for t := start; !t.After(to); t = unit.Add(start, count) {
+		count++
+		if count > MaxCalendarBuckets {
+			return time.Time{}, 0, fmt.Errorf("time range contains more than %d buckets of one %s", MaxCalendarBuckets, unit)
+		}
+	}
+	return start, count, nil
+}
+
+// ZoneOffset is the UTC offset of a timezone starting at a point in time.
+type ZoneOffset struct {
+	From time.Time
+	// Offset is the offset to UTC in seconds.
+	Offset int
+}
+
+// ZoneOffsets returns the UTC offsets of loc between from and to, so dialects
+// without timezone support can convert between UTC and local time.
+func ZoneOffsets(loc *time.Location, from, to time.Time) []ZoneOffset {
+	_, offset := from.In(loc).Zone()
+	offsets := []ZoneOffset{{From: from, Offset: offset}}
+	// Offsets change at most a few times a year, look for changes day by day
+	// and bisect to the second of the change.
+	for t := from; t.Before(to); {
+		next := t.Add(24 * time.Hour)
+		if _, o := next.In(loc).Zone(); o != offset {
+			lo, hi := t, next
+			for hi.Sub(lo) > time.Second {
+				mid := lo.Add(hi.Sub(lo) / 2)
+				if _, o := mid.In(loc).Zone(); o == offset {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			_, offset = hi.In(loc).Zone()
+			offsets = append(offsets, ZoneOffset{From: hi.Truncate(time.Second), Offset: offset})
+		}
+		t = next
+	}
+	return offsets
+}
+
+// ZoneOffsetExpr returns an SQL expression of the UTC offset in seconds of the
+// time expression expr. If local is set, expr is a local time of the zone,
+// otherwise it is a UTC time. literal formats a time, given as UTC wall clock,
+// as a literal of the dialect.
+func ZoneOffsetExpr(expr string, offsets []ZoneOffset, local bool, literal func(time.Time) string) string {
+	if len(offsets) == 1 {
+		return fmt.Sprintf("%d", offsets[0].Offset)
+	}
+	var b strings.Builder
+	b.WriteString("CASE")
+	for i := 1; i < len(offsets); i++ {
+		boundary := offsets[i].From.UTC()
+		if local {
+			// Wall clock just before the change.
+			boundary = boundary.Add(time.Duration(offsets[i-1].Offset) * time.Second)
+		}
+		fmt.Fprintf(&b, " WHEN %s < %s THEN %d", expr, literal(boundary), offsets[i-1].Offset)
+	}
+	fmt.Fprintf(&b, " ELSE %d END", offsets[len(offsets)-1].Offset)
+	return b.String()
+}
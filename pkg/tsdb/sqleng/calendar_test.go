@@ -0,0 +1,152 @@
+package sqleng
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCalendarUnit(t *testing.T) {
+	for _, arg := range []string{"day", "'day'", `"DAY"`} {
+		unit, err := ParseCalendarUnit(arg)
+		require.NoError(t, err)
+		require.Equal(t, CalendarUnitDay, unit)
+	}
+
+	_, err := ParseCalendarUnit("'1d'")
+	require.Error(t, err)
+}
+
+func TestCalendarUnit_Truncate(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	// Wednesday
+	ts := time.Date(2023, 8, 16, 13, 45, 30, 0, berlin)
+
+	tests := map[CalendarUnit]time.Time{
+		CalendarUnitMinute:  time.Date(2023, 8, 16, 13, 45, 0, 0, berlin),
+		CalendarUnitHour:    time.Date(2023, 8, 16, 13, 0, 0, 0, berlin),
+		CalendarUnitDay:     time.Date(2023, 8, 16, 0, 0, 0, 0, berlin),
+		CalendarUnitWeek:    time.Date(2023, 8, 14, 0, 0, 0, 0, berlin),
+		CalendarUnitMonth:   time.Date(2023, 8, 1, 0, 0, 0, 0, berlin),
+		CalendarUnitQuarter: time.Date(2023, 7, 1, 0, 0, 0, 0, berlin),
+		CalendarUnitYear:    time.Date(2023, 1, 1, 0, 0, 0, 0, berlin),
+	}
+	for unit, expected := range tests {
+		t.Run(string(unit), func(t *testing.T) {
+			require.Equal(t, expected, unit.Truncate(ts))
+		})
+	}
+
+	t.Run("week of a sunday starts on the previous monday", func(t *testing.T) {
+		require.Equal(t, time.Date(2023, 8, 14, 0, 0, 0, 0, berlin), CalendarUnitWeek.Truncate(time.Date(2023, 8, 20, 23, 0, 0, 0, berlin)))
+	})
+}
+
+func TestQueryTimezone(t *testing.T) {
+	t.Run("defaults to UTC", func(t *testing.T) {
+		for _, model := range []string{``, `{}`, `{"timezone":"browser"}`, `{"timezone":"utc"}`} {
+			loc, err := QueryTimezone(&backend.DataQuery{JSON: []byte(model)})
+			require.NoError(t, err)
+			require.Equal(t, time.UTC, loc)
+		}
+	})
+
+	t.Run("loads IANA timezone", func(t *testing.T) {
+		loc, err := QueryTimezone(&backend.DataQuery{JSON: []byte(`{"timezone":"America/New_York"}`)})
+		require.NoError(t, err)
+		require.Equal(t, "America/New_York", loc.String())
+	})
+
+	t.Run("rejects invalid timezone", func(t *testing.T) {
+		for _, tz := range []string{`Mars/Olympus`, `UTC'; DROP TABLE x; --`} {
+			_, err := QueryTimezone(&backend.DataQuery{JSON: []byte(`{"timezone":"` + tz + `"}`)})
+			require.Error(t, err)
+		}
+	})
+}
+
+func TestTimeBucketArgs(t *testing.T) {
+	unit, loc, err := TimeBucketArgs(&backend.DataQuery{JSON: []byte(`{"timezone":"America/New_York"}`)}, "'month'")
+	require.NoError(t, err)
+	require.Equal(t, CalendarUnitMonth, unit)
+	require.Equal(t, "America/New_York", loc.String())
+
+	_, _, err = TimeBucketArgs(&backend.DataQuery{}, "1d")
+	require.Error(t, err)
+
+	_, _, err = TimeBucketArgs(&backend.DataQuery{JSON: []byte(`{"timezone":"Mars/Olympus"}`)}, "day")
+	require.Error(t, err)
+}
+
+func TestCalendarBuckets(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	t.Run("days across daylight saving time change", func(t *testing.T) {
+		tr := backend.TimeRange{
+			From: time.Date(2023, 3, 25, 12, 0, 0, 0, time.UTC),
+			To:   time.Date(2023, 3, 27, 12, 0, 0, 0, time.UTC),
+		}
+		start, count, err := CalendarBuckets(tr, CalendarUnitDay, berlin)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2023, 3, 25, 0, 0, 0, 0, berlin), start)
+		require.Equal(t, 3, count)
+	})
+
+	t.Run("months keep the first day of month", func(t *testing.T) {
+		tr := backend.TimeRange{
+			From: time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC),
+		}
+		start, count, err := CalendarBuckets(tr, CalendarUnitMonth, time.UTC)
+		require.NoError(t, err)
+		require.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), start)
+		require.Equal(t, 4, count)
+		require.Equal(t, time.Date(2023, 4, 1, 0, 0, 0, 0, time.UTC), CalendarUnitMonth.Add(start, count-1))
+	})
+
+	t.Run("limits the number of buckets", func(t *testing.T) {
+		tr := backend.TimeRange{
+			From: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+			To:   time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		}
+		_, _, err := CalendarBuckets(tr, CalendarUnitMinute, time.UTC)
+		require.Error(t, err)
+	})
+}
+
+func TestZoneOffsets(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	t.Run("without change", func(t *testing.T) {
+		from := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+		offsets := ZoneOffsets(berlin, from, from.Add(72*time.Hour))
+		require.Equal(t, []ZoneOffset{{From: from, Offset: 7200}}, offsets)
+	})
+
+	t.Run("with daylight saving time changes", func(t *testing.T) {
+		from := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+		offsets := ZoneOffsets(berlin, from, time.Date(2023, 11, 1, 0, 0, 0, 0, time.UTC))
+		require.Len(t, offsets, 3)
+		require.Equal(t, 3600, offsets[0].Offset)
+		require.True(t, time.Date(2023, 3, 26, 1, 0, 0, 0, time.UTC).Equal(offsets[1].From))
+		require.Equal(t, 7200, offsets[1].Offset)
+		require.True(t, time.Date(2023, 10, 29, 1, 0, 0, 0, time.UTC).Equal(offsets[2].From))
+		require.Equal(t, 3600, offsets[2].Offset)
+	})
+
+	t.Run("offset expression", func(t *testing.T) {
+		literal := func(t time.Time) string { return "'" + t.Format("2006-01-02 15:04:05") + "'" }
+		offsets := []ZoneOffset{
+			{From: time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC), Offset: 3600},
+			{From: time.Date(2023, 3, 26, 1, 0, 0, 0, time.UTC), Offset: 7200},
+		}
+		require.Equal(t, "3600", ZoneOffsetExpr("t", offsets[:1], false, literal))
+		require.Equal(t, "CASE WHEN t < '2023-03-26 01:00:00' THEN 3600 ELSE 7200 END", ZoneOffsetExpr("t", offsets, false, literal))
+		require.Equal(t, "CASE WHEN t < '2023-03-26 02:00:00' THEN 3600 ELSE 7200 END", ZoneOffsetExpr("t", offsets, true, literal))
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"xorm.io/xorm"
 
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb/intervalv2"
 	"github.com/grafana/grafana/pkg/util/errutil"
@@ -46,6 +47,13 @@ type SqlQueryResultTransformer interface {
 	GetConverterList() []sqlutil.StringConverter
 }
 
+// SqlQueryResultDynamicTypes is implemented by a SqlQueryResultTransformer when the
+// driver does not report reliable column types, like for expressions in SQLite.
+// The field types are then detected from the returned values.
+type SqlQueryResultDynamicTypes interface {
+	DynamicTypes() bool
+}
+
 var sqlIntervalCalculator = intervalv2.NewCalculator()
 
 // NewXormEngine is an xorm.Engine factory, that can be stubbed by tests.
@@ -56,26 +64,30 @@ var NewXormEngine = func(driverName string, connectionString string) (*xorm.Engi
 }
 
 type JsonData struct {
-	MaxOpenConns            int    `json:"maxOpenConns"`
-	MaxIdleConns            int    `json:"maxIdleConns"`
-	ConnMaxLifetime         int    `json:"connMaxLifetime"`
-	ConnectionTimeout       int    `json:"connectionTimeout"`
-	Timescaledb             bool   `json:"timescaledb"`
-	Mode                    string `json:"sslmode"`
-	ConfigurationMethod     string `json:"tlsConfigurationMethod"`
-	TlsSkipVerify           bool   `json:"tlsSkipVerify"`
-	RootCertFile            string `json:"sslRootCertFile"`
-	CertFile                string `json:"sslCertFile"`
-	CertKeyFile             string `json:"sslKeyFile"`
-	Timezone                string `json:"timezone"`
-	Encrypt                 string `json:"encrypt"`
-	Servername              string `json:"servername"`
-	TimeInterval            string `json:"timeInterval"`
-	Database                string `json:"database"`
-	SecureDSProxy           bool   `json:"enableSecureSocksProxy"`
-	SecureDSProxyUsername   string `json:"secureSocksProxyUsername"`
-	AllowCleartextPasswords bool   `json:"allowCleartextPasswords"`
-	AuthenticationType      string `json:"authenticationType"`
+	MaxOpenConns             int    `json:"maxOpenConns"`
+	MaxIdleConns             int    `json:"maxIdleConns"`
+	ConnMaxLifetime          int    `json:"connMaxLifetime"`
+	ConnectionTimeout        int    `json:"connectionTimeout"`
+	Timescaledb              bool   `json:"timescaledb"`
+	Mode                     string `json:"sslmode"`
+	ConfigurationMethod      string `json:"tlsConfigurationMethod"`
+	TlsSkipVerify            bool   `json:"tlsSkipVerify"`
+	RootCertFile             string `json:"sslRootCertFile"`
+	CertFile                 string `json:"sslCertFile"`
+	CertKeyFile              string `json:"sslKeyFile"`
+	Timezone                 string `json:"timezone"`
+	Encrypt                  string `json:"encrypt"`
+	Servername               string `json:"servername"`
+	TimeInterval             string `json:"timeInterval"`
+	Database                 string `json:"database"`
+	SecureDSProxy            bool   `json:"enableSecureSocksProxy"`
+	SecureDSProxyUsername    string `json:"secureSocksProxyUsername"`
+	AllowCleartextPasswords  bool   `json:"allowCleartextPasswords"`
+	AuthenticationType       string `json:"authenticationType"`
+	QueryCacheEnabled        bool   `json:"queryCacheEnabled"`
+	QueryCacheTTL            int    `json:"queryCacheTTL"`
+	QueryCacheMaxSizeKb      int    `json:"queryCacheMaxSizeKb"`
+	QueryCacheMaxTotalSizeMb int    `json:"queryCacheMaxTotalSizeMb"`
 }
 
 type DataSourceInfo struct {
@@ -103,6 +115,10 @@ type DataPluginConfiguration struct {
 	TimeColumnNames   []string
 	MetricColumnTypes []string
 	RowLimit          int64
+	// Cursor is optional, used to stream query results with server-side cursors.
+	Cursor SQLCursor
+	// Cache is optional, used to cache query results when enabled for the data source.
+	Cache remotecache.CacheStorage
 }
 
 type DataSourceHandler struct {
@@ -115,6 +131,8 @@ type DataSourceHandler struct {
 	dsInfo                 DataSourceInfo
 	rowLimit               int64
 	userError              string
+	cursor                 SQLCursor
+	cache                  *queryCache
 }
 
 type QueryJson struct {
@@ -155,6 +173,8 @@ func NewQueryDataHandler(cfg *setting.Cfg, config DataPluginConfiguration, query
 		dsInfo:                 config.DSInfo,
 		rowLimit:               config.RowLimit,
 		userError:              cfg.UserFacingDefaultError,
+		cursor:                 config.Cursor,
+		cache:                  newQueryCache(config.Cache, config.DSInfo, log),
 	}
 
 	if len(config.TimeColumnNames) > 0 {
@@ -259,6 +279,9 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		panic("Query model property rawSql should not be empty at this point")
 	}
 
+	if e.cache != nil {
+		query.TimeRange = e.cache.roundTimeRange(query.TimeRange)
+	}
 	timeRange := query.TimeRange
 
 	errAppendDebug := func(frameErr string, err error, query string) {
@@ -285,6 +308,25 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		return
 	}
 
+	var cacheKey string
+	if e.cache != nil {
+		cacheKey, err = e.cache.key(query, queryJson, interpolatedQuery)
+		if err != nil {
+			logger.Warn("Failed to get query cache key", "error", err)
+		} else if frames, ok := e.cache.get(queryContext, cacheKey); ok {
+			queryResult.dataResponse.Frames = frames
+			ch <- queryResult
+			return
+		}
+	}
+	sendFrames := func(frames data.Frames) {
+		if cacheKey != "" {
+			e.cache.set(queryContext, cacheKey, frames)
+		}
+		queryResult.dataResponse.Frames = frames
+		ch <- queryResult
+	}
+
 	session := e.engine.NewSession()
 	defer session.Close()
 	db := session.DB()
@@ -308,11 +350,21 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 
 	// Convert row.Rows to dataframe
 	stringConverters := e.queryResultTransformer.GetConverterList()
-	frame, err := sqlutil.FrameFromRows(rows.Rows, e.rowLimit, sqlutil.ToConverters(stringConverters...)...)
+	converters := sqlutil.ToConverters(stringConverters...)
+	if t, ok := e.queryResultTransformer.(SqlQueryResultDynamicTypes); ok && t.DynamicTypes() {
+		converters = append(converters, sqlutil.Converter{Dynamic: true})
+	}
+	frame, err := sqlutil.FrameFromRows(rows.Rows, e.rowLimit, converters...)
 	if err != nil {
 		errAppendDebug("convert frame from rows error", err, interpolatedQuery)
 		return
 	}
+	// Errors while iterating, like a write to a read-only database, are only
+	// reported by rows.Err.
+	if err := rows.Err(); err != nil {
+		errAppendDebug("db query error", e.TransformQueryError(logger, err), interpolatedQuery)
+		return
+	}
 
 	if frame.Meta == nil {
 		frame.Meta = &data.FrameMeta{}
@@ -326,8 +378,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 	// additionally-needed frame data stays intact and is correctly passed to our visulization.
 	if frame.Rows() == 0 {
 		frame.Fields = []*data.Field{}
-		queryResult.dataResponse.Frames = data.Frames{frame}
-		ch <- queryResult
+		sendFrames(data.Frames{frame})
 		return
 	}
 
@@ -397,8 +448,7 @@ func (e *DataSourceHandler) executeQuery(query backend.DataQuery, wg *sync.WaitG
 		}
 	}
 
-	queryResult.dataResponse.Frames = data.Frames{frame}
-	ch <- queryResult
+	sendFrames(data.Frames{frame})
 }
 
 // Interpolate provides global macros/substitutions for all sql datasources.
@@ -413,6 +463,13 @@ var Interpolate = func(query backend.DataQuery, timeRange backend.TimeRange, tim
 	sql = strings.ReplaceAll(sql, "$__interval", interval.Text)
 	sql = strings.ReplaceAll(sql, "$__unixEpochFrom()", fmt.Sprintf("%d", timeRange.From.UTC().Unix()))
 	sql = strings.ReplaceAll(sql, "$__unixEpochTo()", fmt.Sprintf("%d", timeRange.To.UTC().Unix()))
+	if strings.Contains(sql, "$__timezone") {
+		loc, err := QueryTimezone(&query)
+		if err != nil {
+			return "", err
+		}
+		sql = strings.ReplaceAll(sql, "$__timezone", loc.String())
+	}
 
 	return sql, nil
 }
@@ -54,6 +54,17 @@ func TestSQLEngine(t *testing.T) {
 			require.NoError(t, err)
 			require.Equal(t, fmt.Sprintf("select %d", to.Unix()), sql)
 		})
+
+		t.Run("interpolate $__timezone", func(t *testing.T) {
+			sql, err := Interpolate(query, timeRange, "", "select '$__timezone'")
+			require.NoError(t, err)
+			require.Equal(t, "select 'UTC'", sql)
+
+			tzQuery := backend.DataQuery{JSON: []byte(`{"timezone":"Europe/Berlin"}`)}
+			sql, err = Interpolate(tzQuery, timeRange, "", "select '$__timezone'")
+			require.NoError(t, err)
+			require.Equal(t, "select 'Europe/Berlin'", sql)
+		})
 	})
 
 	t.Run("Given row values with int64 as time columns", func(t *testing.T) {
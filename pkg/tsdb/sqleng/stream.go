@@ -0,0 +1,331 @@
+package sqleng
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	"xorm.io/core"
+)
+
+// StreamPathPrefix is the prefix of stream paths used to stream query results.
+const StreamPathPrefix = "query/"
+
+// StreamPath returns the path of the stream of the query data of a stream
+// subscription to the data source. Subscriptions to the same path share the
+// stream, so the path is the hash of the data source UID and the query data.
+func StreamPath(datasourceUID string, data json.RawMessage) string {
+	h := sha256.New()
+	for _, part := range [][]byte{[]byte(datasourceUID), data} {
+		// Separator avoids collisions between concatenated parts.
+		_, _ = h.Write(part)
+		_, _ = h.Write([]byte{0})
+	}
+	return StreamPathPrefix + hex.EncodeToString(h.Sum(nil))
+}
+
+// DefaultStreamChunkSize is the number of rows sent in one frame when a
+// stream query does not define the chunk size.
+const DefaultStreamChunkSize int64 = 10000
+
+const streamCursorName = "grafana_stream_cursor"
+
+// SQLCursor is implemented by dialects which support server-side cursors. When
+// provided, streamed query results are fetched from the database in chunks
+// instead of being read from a single result set.
+type SQLCursor interface {
+	// Declare returns the statement declaring cursor name for sql.
+	Declare(name string, sql string) string
+	// Fetch returns the statement fetching the next count rows from cursor name.
+	Fetch(name string, count int64) string
+	// Close returns the statement closing cursor name.
+	Close(name string) string
+}
+
+// StreamQuery is the payload of a stream subscription. Rows of the query
+// result are sent as table frames of at most ChunkSize rows, so large results
+// can be retrieved without raising the row limit.
+type StreamQuery struct {
+	QueryJson
+	RefID         string `json:"refId"`
+	ChunkSize     int64  `json:"chunkSize"`
+	From          int64  `json:"from"`
+	To            int64  `json:"to"`
+	IntervalMs    int64  `json:"intervalMs"`
+	MaxDataPoints int64  `json:"maxDataPoints"`
+}
+
+// StreamChunkMeta is stored in the custom meta of every streamed frame.
+type StreamChunkMeta struct {
+	// Chunk is a sequence number of the frame, starting at 0.
+	Chunk int `json:"chunk"`
+	// Done is set on the last frame of the stream. The last frame has no rows.
+	Done bool `json:"done"`
+	// Rows is the number of rows sent so far.
+	Rows int64 `json:"rows"`
+}
+
+func parseStreamQuery(raw json.RawMessage) (*StreamQuery, error) {
+	q := &StreamQuery{}
+	if err := json.Unmarshal(raw, q); err != nil {
+		return nil, fmt.Errorf("error unmarshal stream query: %w", err)
+	}
+	if q.RawSql == "" {
+		return nil, errors.New("stream query has no rawSql")
+	}
+	if q.Format == "" {
+		q.Format = string(dataQueryFormatTable)
+	}
+	if q.Format != string(dataQueryFormatTable) {
+		return nil, fmt.Errorf("streaming is only supported for table format, got %q", q.Format)
+	}
+	if q.ChunkSize <= 0 {
+		q.ChunkSize = DefaultStreamChunkSize
+	}
+	return q, nil
+}
+
+func (q *StreamQuery) dataQuery() (backend.DataQuery, error) {
+	raw, err := json.Marshal(q.QueryJson)
+	if err != nil {
+		return backend.DataQuery{}, err
+	}
+	return backend.DataQuery{
+		RefID:         q.RefID,
+		JSON:          raw,
+		Interval:      time.Duration(q.IntervalMs) * time.Millisecond,
+		MaxDataPoints: q.MaxDataPoints,
+		TimeRange: backend.TimeRange{
+			From: time.UnixMilli(q.From),
+			To:   time.UnixMilli(q.To),
+		},
+	}, nil
+}
+
+// SubscribeStream allows subscribing to query streams with a valid query, on
+// the path of the query.
+func (e *DataSourceHandler) SubscribeStream(_ context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	if req.Path != StreamPath(e.dsInfo.UID, req.Data) {
+		e.log.Debug("Stream path does not match the query", "path", req.Path)
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, nil
+	}
+	if _, err := parseStreamQuery(req.Data); err != nil {
+		e.log.Debug("Invalid stream query", "path", req.Path, "error", err)
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, nil
+	}
+	return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusOK}, nil
+}
+
+// PublishStream is not supported, query streams are read only.
+func (e *DataSourceHandler) PublishStream(_ context.Context, _ *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	return &backend.PublishStreamResponse{Status: backend.PublishStreamStatusPermissionDenied}, nil
+}
+
+// RunStream executes the stream query and sends its result in chunks. Query
+// errors are sent as a frame notice and finish the stream, so the query is
+// not retried.
+func (e *DataSourceHandler) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	logger := e.log.FromContext(ctx)
+	if req.Path != StreamPath(e.dsInfo.UID, req.Data) {
+		return fmt.Errorf("stream path %s does not match the query", req.Path)
+	}
+	sq, err := parseStreamQuery(req.Data)
+	if err != nil {
+		return sendStreamError(sender, "", err)
+	}
+	query, err := sq.dataQuery()
+	if err != nil {
+		return sendStreamError(sender, "", err)
+	}
+
+	interpolatedQuery, err := Interpolate(query, query.TimeRange, e.dsInfo.JsonData.TimeInterval, sq.RawSql)
+	if err == nil {
+		interpolatedQuery, err = e.macroEngine.Interpolate(&query, query.TimeRange, interpolatedQuery)
+	}
+	if err != nil {
+		return sendStreamError(sender, interpolatedQuery, fmt.Errorf("interpolation failed: %w", e.TransformQueryError(logger, err)))
+	}
+
+	chunkSize := sq.ChunkSize
+	if e.rowLimit > 0 && chunkSize > e.rowLimit {
+		chunkSize = e.rowLimit
+	}
+
+	meta := StreamChunkMeta{}
+	var schema *data.Frame
+	send := func(frame *data.Frame) error {
+		meta.Rows += int64(frame.Rows())
+		frame.RefID = sq.RefID
+		frame.SetMeta(&data.FrameMeta{ExecutedQueryString: interpolatedQuery, Custom: meta})
+		if err := sender.SendFrame(frame, data.IncludeAll); err != nil {
+			return err
+		}
+		meta.Chunk++
+		schema = frame.EmptyCopy()
+		return nil
+	}
+
+	err = e.streamQuery(ctx, query, interpolatedQuery, chunkSize, send)
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		logger.Error("Stream query failed", "error", err)
+		return sendStreamError(sender, interpolatedQuery, fmt.Errorf("db query error: %w", e.TransformQueryError(logger, err)))
+	}
+
+	if schema == nil {
+		schema = data.NewFrame("")
+	}
+	meta.Done = true
+	schema.RefID = sq.RefID
+	schema.SetMeta(&data.FrameMeta{ExecutedQueryString: interpolatedQuery, Custom: meta})
+	return sender.SendFrame(schema, data.IncludeAll)
+}
+
+func sendStreamError(sender *backend.StreamSender, executedQuery string, err error) error {
+	frame := data.NewFrame("")
+	frame.SetMeta(&data.FrameMeta{
+		ExecutedQueryString: executedQuery,
+		Custom:              StreamChunkMeta{Done: true},
+	})
+	frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityError, Text: err.Error()})
+	return sender.SendFrame(frame, data.IncludeAll)
+}
+
+// streamQuery executes interpolatedQuery and calls send for every chunk of at
+// most chunkSize rows.
+func (e *DataSourceHandler) streamQuery(ctx context.Context, query backend.DataQuery, interpolatedQuery string, chunkSize int64,
+	send func(*data.Frame) error) error {
+	session := e.engine.NewSession()
+	defer session.Close()
+	db := session.DB()
+
+	if e.cursor != nil {
+		return e.streamQueryWithCursor(ctx, db, query, interpolatedQuery, chunkSize, send)
+	}
+
+	rows, err := db.QueryContext(ctx, interpolatedQuery)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			e.log.Warn("Failed to close rows", "err", err)
+		}
+	}()
+
+	for {
+		// Stop between chunks when the subscription is gone, the driver may
+		// still have buffered rows to return.
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		frame, err := e.readChunk(ctx, query, rows, interpolatedQuery, chunkSize)
+		if err != nil {
+			return err
+		}
+		if frame.Rows() == 0 {
+			return nil
+		}
+		if err := send(frame); err != nil {
+			return err
+		}
+		if int64(frame.Rows()) < chunkSize {
+			return nil
+		}
+	}
+}
+
+// streamQueryWithCursor reads results through a server-side cursor, which
+// requires a transaction for the lifetime of the cursor.
+func (e *DataSourceHandler) streamQueryWithCursor(ctx context.Context, db *core.DB, query backend.DataQuery, interpolatedQuery string,
+	chunkSize int64, send func(*data.Frame) error) error {
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// The transaction is read only, rolling back releases the cursor too.
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			e.log.Warn("Failed to rollback stream transaction", "err", err)
+		}
+	}()
+
+	if _, err := tx.ExecContext(ctx, e.cursor.Declare(streamCursorName, interpolatedQuery)); err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		rows, err := tx.QueryContext(ctx, e.cursor.Fetch(streamCursorName, chunkSize))
+		if err != nil {
+			return err
+		}
+		frame, err := e.readChunk(ctx, query, rows, interpolatedQuery, chunkSize)
+		if closeErr := rows.Close(); closeErr != nil {
+			e.log.Warn("Failed to close rows", "err", closeErr)
+		}
+		if err != nil {
+			return err
+		}
+		if frame.Rows() == 0 {
+			break
+		}
+		if err := send(frame); err != nil {
+			return err
+		}
+		if int64(frame.Rows()) < chunkSize {
+			break
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, e.cursor.Close(streamCursorName)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// readChunk reads at most limit rows into a table frame.
+func (e *DataSourceHandler) readChunk(ctx context.Context, query backend.DataQuery, rows *core.Rows, interpolatedQuery string,
+	limit int64) (*data.Frame, error) {
+	qm, err := e.newProcessCfg(query, ctx, rows, interpolatedQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	stringConverters := e.queryResultTransformer.GetConverterList()
+	scanRow, err := sqlutil.MakeScanRow(qm.columnTypes, qm.columnNames, sqlutil.ToConverters(stringConverters...)...)
+	if err != nil {
+		return nil, err
+	}
+	frame := sqlutil.NewFrame(qm.columnNames, scanRow.Converters...)
+
+	for int64(frame.Rows()) < limit && rows.Next() {
+		r := scanRow.NewScannableRow()
+		if err := rows.Scan(r...); err != nil {
+			return nil, err
+		}
+		if err := sqlutil.Append(frame, r, scanRow.Converters...); err != nil {
+			return nil, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := convertSQLTimeColumnsToEpochMS(frame, qm); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
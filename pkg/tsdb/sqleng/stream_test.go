@@ -0,0 +1,216 @@
+package sqleng
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+)
+
+func TestParseStreamQuery(t *testing.T) {
+	t.Run("defaults to table format and default chunk size", func(t *testing.T) {
+		q, err := parseStreamQuery([]byte(`{"refId":"A","rawSql":"SELECT 1"}`))
+		require.NoError(t, err)
+		require.Equal(t, string(dataQueryFormatTable), q.Format)
+		require.Equal(t, DefaultStreamChunkSize, q.ChunkSize)
+	})
+
+	t.Run("keeps chunk size", func(t *testing.T) {
+		q, err := parseStreamQuery([]byte(`{"rawSql":"SELECT 1","chunkSize":50}`))
+		require.NoError(t, err)
+		require.Equal(t, int64(50), q.ChunkSize)
+	})
+
+	t.Run("requires rawSql", func(t *testing.T) {
+		_, err := parseStreamQuery([]byte(`{"refId":"A"}`))
+		require.Error(t, err)
+	})
+
+	t.Run("rejects time series format", func(t *testing.T) {
+		_, err := parseStreamQuery([]byte(`{"rawSql":"SELECT 1","format":"time_series"}`))
+		require.Error(t, err)
+	})
+
+	t.Run("rejects invalid json", func(t *testing.T) {
+		_, err := parseStreamQuery([]byte(`{`))
+		require.Error(t, err)
+	})
+}
+
+func TestStreamQuery_DataQuery(t *testing.T) {
+	q, err := parseStreamQuery([]byte(`{"refId":"A","rawSql":"SELECT 1","from":1000,"to":2000,"intervalMs":500,"maxDataPoints":10}`))
+	require.NoError(t, err)
+
+	query, err := q.dataQuery()
+	require.NoError(t, err)
+	require.Equal(t, "A", query.RefID)
+	require.Equal(t, 500*time.Millisecond, query.Interval)
+	require.Equal(t, int64(10), query.MaxDataPoints)
+	require.Equal(t, time.UnixMilli(1000), query.TimeRange.From)
+	require.Equal(t, time.UnixMilli(2000), query.TimeRange.To)
+
+	parsed, err := parseStreamQuery(query.JSON)
+	require.NoError(t, err)
+	require.Equal(t, "SELECT 1", parsed.RawSql)
+}
+
+func TestRunStream(t *testing.T) {
+	handler := newTestStreamHandler(t, 25)
+
+	t.Run("sends rows in chunks and a last empty frame", func(t *testing.T) {
+		sender := &testStreamPacketSender{}
+		err := handler.RunStream(context.Background(), newTestRunStreamRequest(`{"refId":"A","rawSql":"SELECT id, value FROM metric ORDER BY id","chunkSize":10}`), backend.NewStreamSender(sender))
+		require.NoError(t, err)
+
+		frames := sender.frames(t)
+		require.Len(t, frames, 4)
+		for i, rows := range []int{10, 10, 5, 0} {
+			require.Equal(t, rows, frames[i].Rows(), "frame %d", i)
+			require.Equal(t, "A", frames[i].RefID)
+		}
+		last := streamChunkMeta(t, frames[3])
+		require.True(t, last.Done)
+		require.Equal(t, int64(25), last.Rows)
+		require.Equal(t, 3, last.Chunk)
+	})
+
+	t.Run("stops when the context is canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		sender := &testStreamPacketSender{onSend: cancel}
+		err := handler.RunStream(ctx, newTestRunStreamRequest(`{"refId":"A","rawSql":"SELECT id, value FROM metric ORDER BY id","chunkSize":10}`), backend.NewStreamSender(sender))
+		require.ErrorIs(t, err, context.Canceled)
+
+		frames := sender.frames(t)
+		require.Len(t, frames, 1)
+		require.Equal(t, 10, frames[0].Rows())
+		require.False(t, streamChunkMeta(t, frames[0]).Done)
+	})
+
+	t.Run("rejects paths of other queries", func(t *testing.T) {
+		sender := &testStreamPacketSender{}
+		err := handler.RunStream(context.Background(), &backend.RunStreamRequest{
+			Path: StreamPathPrefix + "A",
+			Data: []byte(`{"refId":"A","rawSql":"SELECT id, value FROM metric ORDER BY id"}`),
+		}, backend.NewStreamSender(sender))
+		require.Error(t, err)
+		require.Empty(t, sender.packets)
+	})
+
+	t.Run("sends query errors as a frame notice", func(t *testing.T) {
+		sender := &testStreamPacketSender{}
+		err := handler.RunStream(context.Background(), newTestRunStreamRequest(`{"refId":"A","rawSql":"SELECT * FROM unknown"}`), backend.NewStreamSender(sender))
+		require.NoError(t, err)
+
+		frames := sender.frames(t)
+		require.Len(t, frames, 1)
+		require.Len(t, frames[0].Meta.Notices, 1)
+		require.True(t, streamChunkMeta(t, frames[0]).Done)
+	})
+}
+
+func TestSubscribeStream(t *testing.T) {
+	handler := newTestStreamHandler(t, 1)
+	handler.dsInfo.UID = "ds1"
+	query := []byte(`{"refId":"A","rawSql":"SELECT 1"}`)
+
+	t.Run("allows subscribing on the path of the query", func(t *testing.T) {
+		resp, err := handler.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{Path: StreamPath("ds1", query), Data: query})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusOK, resp.Status)
+	})
+
+	t.Run("rejects paths of other queries and data sources", func(t *testing.T) {
+		for _, path := range []string{StreamPathPrefix + "A", StreamPath("ds2", query), StreamPath("ds1", []byte(`{"refId":"A","rawSql":"SELECT 2"}`))} {
+			resp, err := handler.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{Path: path, Data: query})
+			require.NoError(t, err)
+			require.Equal(t, backend.SubscribeStreamStatusNotFound, resp.Status, path)
+		}
+	})
+
+	t.Run("rejects invalid queries", func(t *testing.T) {
+		invalid := []byte(`{"refId":"A"}`)
+		resp, err := handler.SubscribeStream(context.Background(), &backend.SubscribeStreamRequest{Path: StreamPath("ds1", invalid), Data: invalid})
+		require.NoError(t, err)
+		require.Equal(t, backend.SubscribeStreamStatusNotFound, resp.Status)
+	})
+}
+
+// newTestRunStreamRequest returns a request to run the stream of the query
+// data on its path
+func newTestRunStreamRequest(query string) *backend.RunStreamRequest {
+	return &backend.RunStreamRequest{Path: StreamPath("", []byte(query)), Data: []byte(query)}
+}
+
+func newTestStreamHandler(t *testing.T, rows int) *DataSourceHandler {
+	t.Helper()
+	engine, err := NewXormEngine("sqlite3", filepath.Join(t.TempDir(), "stream.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = engine.Close() })
+
+	values := make([]string, 0, rows)
+	for i := 0; i < rows; i++ {
+		values = append(values, fmt.Sprintf("(%d, %d.5)", i, i))
+	}
+	_, err = engine.Exec("CREATE TABLE metric (id INTEGER, value REAL)")
+	require.NoError(t, err)
+	_, err = engine.Exec("INSERT INTO metric (id, value) VALUES " + strings.Join(values, ", "))
+	require.NoError(t, err)
+
+	return &DataSourceHandler{
+		macroEngine:            &testStreamMacroEngine{},
+		queryResultTransformer: &testQueryResultTransformer{},
+		engine:                 engine,
+		timeColumnNames:        []string{"time"},
+		log:                    log.New("test"),
+	}
+}
+
+type testStreamMacroEngine struct{}
+
+func (m *testStreamMacroEngine) Interpolate(_ *backend.DataQuery, _ backend.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
+
+type testStreamPacketSender struct {
+	packets []json.RawMessage
+	onSend  func()
+}
+
+func (s *testStreamPacketSender) Send(packet *backend.StreamPacket) error {
+	s.packets = append(s.packets, packet.Data)
+	if s.onSend != nil {
+		s.onSend()
+	}
+	return nil
+}
+
+func (s *testStreamPacketSender) frames(t *testing.T) []*data.Frame {
+	t.Helper()
+	frames := make([]*data.Frame, 0, len(s.packets))
+	for _, packet := range s.packets {
+		frame := &data.Frame{}
+		require.NoError(t, json.Unmarshal(packet, frame))
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func streamChunkMeta(t *testing.T, frame *data.Frame) StreamChunkMeta {
+	t.Helper()
+	raw, err := json.Marshal(frame.Meta.Custom)
+	require.NoError(t, err)
+	meta := StreamChunkMeta{}
+	require.NoError(t, json.Unmarshal(raw, &meta))
+	return meta
+}
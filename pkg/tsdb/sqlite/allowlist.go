@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var errFileAccessDisabled = errors.New("file access is disabled, configure allowed_file_paths in the [sql_datasources] section")
+
+// pathAllowlist checks file paths against the allowed_file_paths setting.
+// Entries are files, directories or glob patterns.
+type pathAllowlist struct {
+	patterns []string
+}
+
+func newPathAllowlist(entries []string) *pathAllowlist {
+	patterns := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e == "" {
+			continue
+		}
+		patterns = append(patterns, filepath.Clean(e))
+	}
+	return &pathAllowlist{patterns: patterns}
+}
+
+// resolve returns the absolute path of p with symbolic links evaluated, so
+// links cannot be used to escape the allowed paths.
+func resolve(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", err
+	}
+	return resolved, nil
+}
+
+// check returns the resolved path of p, or an error if p is not allowed.
+func (a *pathAllowlist) check(p string) (string, error) {
+	if len(a.patterns) == 0 {
+		return "", errFileAccessDisabled
+	}
+	if p == "" {
+		return "", errors.New("missing file path")
+	}
+	resolved, err := resolve(p)
+	if err != nil {
+		return "", fmt.Errorf("invalid file path %q: %w", p, err)
+	}
+	for _, pattern := range a.patterns {
+		if a.matches(pattern, resolved) {
+			return resolved, nil
+		}
+	}
+	return "", fmt.Errorf("file path %q is not allowed", p)
+}
+
+// duckDBPaths returns the directories and files DuckDB may read when external
+// access is disabled. Glob patterns are expanded to the files matching them
+// when the connection is opened.
+func (a *pathAllowlist) duckDBPaths() (dirs []string, files []string, err error) {
+	for _, pattern := range a.patterns {
+		matches := []string{pattern}
+		if strings.ContainsAny(pattern, "*?[") {
+			if matches, err = filepath.Glob(pattern); err != nil {
+				return nil, nil, fmt.Errorf("invalid file pattern %q: %w", pattern, err)
+			}
+		}
+		for _, m := range matches {
+			resolved, err := resolve(m)
+			if err != nil {
+				// Missing entries cannot be read anyway.
+				continue
+			}
+			info, err := os.Stat(resolved)
+			if err != nil {
+				continue
+			}
+			if info.IsDir() {
+				dirs = append(dirs, resolved+string(filepath.Separator))
+			} else {
+				files = append(files, resolved)
+			}
+		}
+	}
+	return dirs, files, nil
+}
+
+func (a *pathAllowlist) matches(pattern string, resolved string) bool {
+	if ok, err := filepath.Match(pattern, resolved); err == nil && ok {
+		return true
+	}
+	// Directories allow all files below them.
+	dir, err := resolve(pattern)
+	if err != nil {
+		return false
+	}
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return dir == resolved
+	}
+	return strings.HasPrefix(resolved, dir+string(filepath.Separator))
+}
@@ -0,0 +1,74 @@
+package sqlite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathAllowlist(t *testing.T) {
+	dir := t.TempDir()
+	allowed := filepath.Join(dir, "allowed")
+	other := filepath.Join(dir, "other")
+	require.NoError(t, os.Mkdir(allowed, 0o750))
+	require.NoError(t, os.Mkdir(other, 0o750))
+
+	for _, name := range []string{
+		filepath.Join(allowed, "a.db"),
+		filepath.Join(allowed, "b.parquet"),
+		filepath.Join(other, "secret.db"),
+	} {
+		require.NoError(t, os.WriteFile(name, nil, 0o600))
+	}
+	require.NoError(t, os.Symlink(filepath.Join(other, "secret.db"), filepath.Join(allowed, "link.db")))
+
+	t.Run("empty allowlist disables file access", func(t *testing.T) {
+		_, err := newPathAllowlist([]string{""}).check(filepath.Join(allowed, "a.db"))
+		require.ErrorIs(t, err, errFileAccessDisabled)
+	})
+
+	t.Run("directory allows files below it", func(t *testing.T) {
+		a := newPathAllowlist([]string{allowed})
+		p, err := a.check(filepath.Join(allowed, "a.db"))
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(allowed, "a.db"), p)
+
+		_, err = a.check(filepath.Join(other, "secret.db"))
+		require.ErrorContains(t, err, "is not allowed")
+
+		_, err = a.check(filepath.Join(allowed, "..", "other", "secret.db"))
+		require.ErrorContains(t, err, "is not allowed")
+	})
+
+	t.Run("glob pattern", func(t *testing.T) {
+		a := newPathAllowlist([]string{filepath.Join(allowed, "*.parquet")})
+		_, err := a.check(filepath.Join(allowed, "b.parquet"))
+		require.NoError(t, err)
+
+		_, err = a.check(filepath.Join(allowed, "a.db"))
+		require.ErrorContains(t, err, "is not allowed")
+	})
+
+	t.Run("single file", func(t *testing.T) {
+		a := newPathAllowlist([]string{filepath.Join(allowed, "a.db")})
+		_, err := a.check(filepath.Join(allowed, "a.db"))
+		require.NoError(t, err)
+
+		_, err = a.check(filepath.Join(allowed, "b.parquet"))
+		require.ErrorContains(t, err, "is not allowed")
+	})
+
+	t.Run("symbolic links cannot escape allowed paths", func(t *testing.T) {
+		a := newPathAllowlist([]string{allowed})
+		_, err := a.check(filepath.Join(allowed, "link.db"))
+		require.ErrorContains(t, err, "is not allowed")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		a := newPathAllowlist([]string{allowed})
+		_, err := a.check(filepath.Join(allowed, "missing.db"))
+		require.ErrorContains(t, err, "invalid file path")
+	})
+}
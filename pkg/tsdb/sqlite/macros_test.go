@@ -0,0 +1,66 @@
+package sqlite
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMacroEngine(t *testing.T) {
+	from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Minute)
+	timeRange := backend.TimeRange{From: from, To: to}
+	query := &backend.DataQuery{JSON: []byte("{}")}
+
+	t.Run("SQLite", func(t *testing.T) {
+		engine := newSQLiteMacroEngine(dialectSQLite)
+
+		tests := map[string]string{
+			"SELECT $__time(time_column)":                         `SELECT time_column AS "time"`,
+			"SELECT $__timeEpoch(time_column)":                    `SELECT CAST(strftime('%s', time_column) AS INTEGER) AS "time"`,
+			"SELECT * FROM t WHERE $__timeFilter(time_column)":    "SELECT * FROM t WHERE datetime(time_column) BETWEEN '2018-04-12 18:00:00' AND '2018-04-12 18:05:00'",
+			"SELECT $__timeFrom(), $__timeTo()":                   "SELECT '2018-04-12 18:00:00', '2018-04-12 18:05:00'",
+			"SELECT $__timeGroup(time_column, '5m')":              "SELECT CAST(strftime('%s', time_column) AS INTEGER) / 300 * 300",
+			"SELECT $__timeGroupAlias(time_column, '5m')":         `SELECT CAST(strftime('%s', time_column) AS INTEGER) / 300 * 300 AS "time"`,
+			"SELECT * FROM t WHERE $__unixEpochFilter(time)":      "SELECT * FROM t WHERE time >= 1523556000 AND time <= 1523556300",
+			"SELECT $__unixEpochGroupAlias(time, '1h')":           `SELECT CAST(time / 3600 AS BIGINT) * 3600 AS "time"`,
+			"SELECT * FROM t WHERE $__unixEpochNanoFilter(time)":  "SELECT * FROM t WHERE time >= 1523556000000000000 AND time <= 1523556300000000000",
+			"SELECT $__unixEpochNanoFrom(), $__unixEpochNanoTo()": "SELECT 1523556000000000000, 1523556300000000000",
+		}
+		for sql, expected := range tests {
+			res, err := engine.Interpolate(query, timeRange, sql)
+			require.NoError(t, err)
+			require.Equal(t, expected, res)
+		}
+	})
+
+	t.Run("DuckDB", func(t *testing.T) {
+		engine := newSQLiteMacroEngine(dialectDuckDB)
+
+		tests := map[string]string{
+			"SELECT $__timeEpoch(time_column)":                 `SELECT CAST(epoch(time_column) AS BIGINT) AS "time"`,
+			"SELECT * FROM t WHERE $__timeFilter(time_column)": "SELECT * FROM t WHERE time_column BETWEEN TIMESTAMP '2018-04-12 18:00:00' AND TIMESTAMP '2018-04-12 18:05:00'",
+			"SELECT $__timeGroupAlias(time_column, '5m')":      `SELECT time_bucket(INTERVAL '300 seconds', time_column) AS "time"`,
+		}
+		for sql, expected := range tests {
+			res, err := engine.Interpolate(query, timeRange, sql)
+			require.NoError(t, err)
+			require.Equal(t, expected, res)
+		}
+	})
+
+	t.Run("rejects macro errors and write statements", func(t *testing.T) {
+		engine := newSQLiteMacroEngine(dialectSQLite)
+
+		_, err := engine.Interpolate(query, timeRange, "SELECT $__timeGroup(time_column)")
+		require.Error(t, err)
+
+		_, err = engine.Interpolate(query, timeRange, "SELECT $__unknown(x)")
+		require.ErrorContains(t, err, "unknown macro")
+
+		_, err = engine.Interpolate(query, timeRange, "UPDATE t SET x = 1 WHERE $__timeFilter(time)")
+		require.ErrorContains(t, err, "UPDATE statements are not allowed")
+	})
+}
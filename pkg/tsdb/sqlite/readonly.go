@@ -0,0 +1,143 @@
+package sqlite
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// readOnlyStatements are the statements allowed in queries. Files are opened
+// read-only as well, checking the statement gives a better error. Access to
+// other files is restricted by the connection, see duckDBConnectionString.
+var readOnlyStatements = map[string]bool{
+	"SELECT":    true,
+	"WITH":      true,
+	"VALUES":    true,
+	"EXPLAIN":   true,
+	"DESCRIBE":  true,
+	"SHOW":      true,
+	"SUMMARIZE": true,
+	"FROM":      true,
+}
+
+// sqlToken is a part of a query, either a string literal or other SQL.
+type sqlToken struct {
+	text    string
+	literal bool
+}
+
+// tokenize splits sql in string literals and other SQL, dropping comments.
+func tokenize(sql string) ([]sqlToken, error) {
+	var tokens []sqlToken
+	var cur strings.Builder
+	flush := func(literal bool) {
+		tokens = append(tokens, sqlToken{text: cur.String(), literal: literal})
+		cur.Reset()
+	}
+
+	for i := 0; i < len(sql); i++ {
+		c := sql[i]
+		switch {
+		case c == '\'':
+			flush(false)
+			closed := false
+			for i++; i < len(sql); i++ {
+				if sql[i] == '\'' {
+					if i+1 < len(sql) && sql[i+1] == '\'' {
+						cur.WriteByte('\'')
+						i++
+						continue
+					}
+					closed = true
+					break
+				}
+				cur.WriteByte(sql[i])
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			flush(true)
+		case c == '$' && i+1 < len(sql) && sql[i+1] == '$':
+			// Dollar quoted string literal.
+			end := strings.Index(sql[i+2:], "$$")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string literal")
+			}
+			flush(false)
+			cur.WriteString(sql[i+2 : i+2+end])
+			flush(true)
+			i += end + 3
+		case c == '"' || c == '`':
+			end := strings.IndexByte(sql[i+1:], c)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated quoted identifier")
+			}
+			// The identifier is kept unquoted, without separators in names
+			// ending the statement.
+			cur.WriteString(strings.ReplaceAll(sql[i+1:i+1+end], ";", ""))
+			i += end + 1
+		case c == '-' && i+1 < len(sql) && sql[i+1] == '-':
+			end := strings.IndexByte(sql[i:], '\n')
+			if end < 0 {
+				i = len(sql)
+			} else {
+				i += end
+			}
+			cur.WriteByte(' ')
+		case c == '/' && i+1 < len(sql) && sql[i+1] == '*':
+			end := strings.Index(sql[i+2:], "*/")
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 3
+			cur.WriteByte(' ')
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush(false)
+	return tokens, nil
+}
+
+// checkReadOnly returns an error unless sql is a single read-only statement.
+func checkReadOnly(tokens []sqlToken) error {
+	var keyword string
+	for i, t := range tokens {
+		if t.literal {
+			if keyword == "" {
+				return fmt.Errorf("query must start with a statement")
+			}
+			continue
+		}
+		text := t.text
+		if idx := strings.IndexByte(text, ';'); idx >= 0 {
+			if strings.TrimSpace(text[idx+1:]) != "" || !onlyWhitespace(tokens[i+1:]) {
+				return fmt.Errorf("only a single statement is allowed")
+			}
+			text = text[:idx]
+		}
+		if keyword == "" {
+			fields := strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) })
+			if len(fields) == 0 {
+				continue
+			}
+			keyword = strings.ToUpper(fields[0])
+			if !readOnlyStatements[keyword] {
+				return fmt.Errorf("%s statements are not allowed, only read-only queries can be executed", keyword)
+			}
+		}
+	}
+	if keyword == "" {
+		return fmt.Errorf("empty query")
+	}
+	return nil
+}
+
+func onlyWhitespace(tokens []sqlToken) bool {
+	for _, t := range tokens {
+		if t.literal || strings.TrimSpace(t.text) != "" {
+			return false
+		}
+	}
+	return true
+}
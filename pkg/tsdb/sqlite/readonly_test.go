@@ -0,0 +1,47 @@
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckReadOnly(t *testing.T) {
+	tests := []struct {
+		sql string
+		err string
+	}{
+		{sql: "SELECT * FROM metrics"},
+		{sql: "select 1;"},
+		{sql: "  -- comment\n/* comment */ WITH x AS (SELECT 1) SELECT * FROM x"},
+		{sql: "SELECT 'a;b', \"c;d\" FROM t"},
+		{sql: "SELECT 'it''s'"},
+		{sql: "SELECT $$a ; DROP TABLE t$$"},
+		{sql: "FROM metrics"},
+		{sql: "EXPLAIN SELECT 1"},
+		{sql: "INSERT INTO t VALUES (1)", err: "INSERT statements are not allowed"},
+		{sql: "ATTACH DATABASE '/etc/passwd' AS x", err: "ATTACH statements are not allowed"},
+		{sql: "PRAGMA query_only = 0", err: "PRAGMA statements are not allowed"},
+		{sql: "/* SELECT */ DELETE FROM t", err: "DELETE statements are not allowed"},
+		{sql: "SELECT 1; DELETE FROM t", err: "only a single statement is allowed"},
+		{sql: "SELECT 1; 'x'", err: "only a single statement is allowed"},
+		{sql: "SELECT 'x", err: "unterminated string literal"},
+		{sql: "SELECT 1 /* x", err: "unterminated comment"},
+		{sql: "'x' SELECT", err: "query must start with a statement"},
+		{sql: " -- only a comment", err: "empty query"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.sql, func(t *testing.T) {
+			tokens, err := tokenize(tc.sql)
+			if err == nil {
+				err = checkReadOnly(tokens)
+			}
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data/sqlutil"
+	_ "github.com/mattn/go-sqlite3"
+	"xorm.io/core"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb/sqleng"
+)
+
+const (
+	dialectSQLite = "sqlite"
+	dialectDuckDB = "duckdb"
+
+	// duckDBDriverName is the database/sql driver name of DuckDB. The driver
+	// needs cgo and is not part of the default build, DuckDB data sources can
+	// be used when a driver is registered with this name.
+	duckDBDriverName = "duckdb"
+)
+
+var logger = log.New("tsdb.sqlite")
+
+type Service struct {
+	im instancemgmt.InstanceManager
+}
+
+var (
+	_ backend.QueryDataHandler          = (*Service)(nil)
+	_ backend.CheckHealthHandler        = (*Service)(nil)
+	_ sqleng.SqlQueryResultDynamicTypes = (*sqliteQueryResultTransformer)(nil)
+)
+
+func ProvideService(cfg *setting.Cfg, cache remotecache.CacheStorage) *Service {
+	return &Service{
+		im: datasource.NewInstanceManager(newInstanceSettings(cfg, cache)),
+	}
+}
+
+// jsonData is the data source configuration in addition to sqleng.JsonData.
+type jsonData struct {
+	// Dialect is either sqlite or duckdb.
+	Dialect string `json:"dialect"`
+	// Path is the database file. DuckDB data sources without a path use an
+	// in-memory database to query allowed Parquet, CSV or JSON files.
+	Path string `json:"path"`
+}
+
+func newInstanceSettings(cfg *setting.Cfg, cache remotecache.CacheStorage) datasource.InstanceFactoryFunc {
+	return func(_ context.Context, settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+		sqlJsonData := sqleng.JsonData{
+			MaxOpenConns:    cfg.SqlDatasourceMaxOpenConnsDefault,
+			MaxIdleConns:    cfg.SqlDatasourceMaxIdleConnsDefault,
+			ConnMaxLifetime: cfg.SqlDatasourceMaxConnLifetimeDefault,
+		}
+		if err := json.Unmarshal(settings.JSONData, &sqlJsonData); err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+		fileJsonData := jsonData{Dialect: dialectSQLite}
+		if err := json.Unmarshal(settings.JSONData, &fileJsonData); err != nil {
+			return nil, fmt.Errorf("error reading settings: %w", err)
+		}
+
+		allowlist := newPathAllowlist(cfg.SqlDatasourceAllowedFilePaths)
+		driverName, cnnstr, err := connectionString(fileJsonData, allowlist)
+		if err != nil {
+			return nil, err
+		}
+
+		dsInfo := sqleng.DataSourceInfo{
+			JsonData:                sqlJsonData,
+			URL:                     fileJsonData.Path,
+			Database:                fileJsonData.Path,
+			ID:                      settings.ID,
+			Updated:                 settings.Updated,
+			UID:                     settings.UID,
+			DecryptedSecureJSONData: settings.DecryptedSecureJSONData,
+		}
+
+		config := sqleng.DataPluginConfiguration{
+			DriverName:        driverName,
+			ConnectionString:  cnnstr,
+			DSInfo:            dsInfo,
+			MetricColumnTypes: []string{"TEXT", "VARCHAR", "CHAR", "UNKNOWN"},
+			RowLimit:          cfg.DataProxyRowLimit,
+			Cache:             cache,
+		}
+
+		handler, err := sqleng.NewQueryDataHandler(cfg, config, &sqliteQueryResultTransformer{},
+			newSQLiteMacroEngine(fileJsonData.Dialect), logger)
+		if err != nil {
+			logger.Error("Failed opening database", "dialect", fileJsonData.Dialect, "err", err)
+			return nil, err
+		}
+
+		logger.Debug("Successfully opened database", "dialect", fileJsonData.Dialect)
+		return handler, nil
+	}
+}
+
+// connectionString returns the driver and connection string opening the
+// database file read-only.
+func connectionString(jd jsonData, allowlist *pathAllowlist) (string, string, error) {
+	switch jd.Dialect {
+	case dialectSQLite:
+		path, err := allowlist.check(jd.Path)
+		if err != nil {
+			return "", "", err
+		}
+		u := url.URL{Scheme: "file", Path: path, RawQuery: "mode=ro&_query_only=true"}
+		return "sqlite3", u.String(), nil
+	case dialectDuckDB:
+		if err := registerDuckDBDriver(); err != nil {
+			return "", "", err
+		}
+		cnnstr, err := duckDBConnectionString(jd.Path, allowlist)
+		if err != nil {
+			return "", "", err
+		}
+		return duckDBDriverName, cnnstr, nil
+	default:
+		return "", "", fmt.Errorf("unsupported dialect %q", jd.Dialect)
+	}
+}
+
+// duckDBConnectionString returns the connection string of a DuckDB database.
+// DuckDB can read files with table functions, queried paths and statements
+// like SUMMARIZE, so instead of checking queries, external access is disabled
+// and only the allowed directories and files can be read. The configuration
+// is locked so queries cannot change it.
+func duckDBConnectionString(path string, allowlist *pathAllowlist) (string, error) {
+	if len(allowlist.patterns) == 0 {
+		return "", errFileAccessDisabled
+	}
+	params := url.Values{}
+	if path != "" {
+		resolved, err := allowlist.check(path)
+		if err != nil {
+			return "", err
+		}
+		path = resolved
+		params.Set("access_mode", "READ_ONLY")
+	}
+	dirs, files, err := allowlist.duckDBPaths()
+	if err != nil {
+		return "", err
+	}
+	params.Set("enable_external_access", "false")
+	params.Set("allowed_directories", duckDBList(dirs))
+	params.Set("allowed_paths", duckDBList(files))
+	params.Set("lock_configuration", "true")
+	return path + "?" + params.Encode(), nil
+}
+
+// duckDBList formats values as a DuckDB list of string literals.
+func duckDBList(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, "'"+strings.ReplaceAll(v, "'", "''")+"'")
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// registerDuckDBDriver registers the DuckDB driver with xorm. xorm is only
+// used to run raw queries, so the SQLite dialect is used for DuckDB.
+func registerDuckDBDriver() error {
+	sqleng.XormDriverMu.Lock()
+	defer sqleng.XormDriverMu.Unlock()
+
+	if core.QueryDriver(duckDBDriverName) != nil {
+		return nil
+	}
+	db, err := sql.Open(duckDBDriverName, "")
+	if err != nil {
+		return fmt.Errorf("DuckDB is not supported by this Grafana build: %w", err)
+	}
+	d := db.Driver()
+	if err := db.Close(); err != nil {
+		return err
+	}
+	core.RegisterDriver(duckDBDriverName, &duckDBDriver{Driver: d})
+	return nil
+}
+
+type duckDBDriver struct {
+	driver.Driver
+}
+
+var _ core.Driver = (*duckDBDriver)(nil)
+
+func (d *duckDBDriver) Parse(_ string, dataSourceName string) (*core.Uri, error) {
+	dbName, _, _ := strings.Cut(dataSourceName, "?")
+	return &core.Uri{DbType: core.SQLITE, DbName: dbName}, nil
+}
+
+func (s *Service) getDataSourceHandler(ctx context.Context, pluginCtx backend.PluginContext) (*sqleng.DataSourceHandler, error) {
+	i, err := s.im.Get(ctx, pluginCtx)
+	if err != nil {
+		return nil, err
+	}
+	instance := i.(*sqleng.DataSourceHandler)
+	return instance, nil
+}
+
+func (s *Service) QueryData(ctx context.Context, req *backend.QueryDataRequest) (*backend.QueryDataResponse, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return nil, err
+	}
+	return dsHandler.QueryData(ctx, req)
+}
+
+// CheckHealth checks the database file is allowed and can be opened.
+func (s *Service) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+	dsHandler, err := s.getDataSourceHandler(ctx, req.PluginContext)
+	if err != nil {
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: err.Error()}, nil
+	}
+
+	if err := dsHandler.Ping(); err != nil {
+		logger.Error("Health check failed", "error", err)
+		return &backend.CheckHealthResult{Status: backend.HealthStatusError, Message: err.Error()}, nil
+	}
+
+	return &backend.CheckHealthResult{Status: backend.HealthStatusOk, Message: "Database Connection OK"}, nil
+}
+
+type sqliteQueryResultTransformer struct{}
+
+func (t *sqliteQueryResultTransformer) TransformQueryError(_ log.Logger, err error) error {
+	return err
+}
+
+func (t *sqliteQueryResultTransformer) GetConverterList() []sqlutil.StringConverter {
+	return nil
+}
+
+// DynamicTypes is true as SQLite has no column types for expressions.
+func (t *sqliteQueryResultTransformer) DynamicTypes() bool {
+	return true
+}
@@ -0,0 +1,202 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/datasource"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/stretchr/testify/require"
+
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+func createTestDatabase(t *testing.T, dir string) string {
+	t.Helper()
+	path := filepath.Join(dir, "metrics.db")
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, db.Close()) }()
+
+	_, err = db.Exec(`CREATE TABLE metrics (time INTEGER, host TEXT, value REAL)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO metrics VALUES (1000, 'a', 1.5), (1060, 'a', 2.5), (1000, 'b', 3)`)
+	require.NoError(t, err)
+	return path
+}
+
+func newTestService(t *testing.T, allowed ...string) *Service {
+	t.Helper()
+	cfg := setting.NewCfg()
+	cfg.DataProxyRowLimit = 1000
+	cfg.SqlDatasourceAllowedFilePaths = allowed
+	return &Service{im: datasource.NewInstanceManager(newInstanceSettings(cfg, nil))}
+}
+
+func pluginContext(t *testing.T, jd map[string]any) backend.PluginContext {
+	t.Helper()
+	raw, err := json.Marshal(jd)
+	require.NoError(t, err)
+	return backend.PluginContext{DataSourceInstanceSettings: &backend.DataSourceInstanceSettings{ID: 1, UID: "sqlite", JSONData: raw}}
+}
+
+func TestSQLite(t *testing.T) {
+	dir := t.TempDir()
+	path := createTestDatabase(t, dir)
+	s := newTestService(t, filepath.Join(dir, "*.db"))
+	pCtx := pluginContext(t, map[string]any{"path": path})
+
+	query := func(t *testing.T, rawSQL string, format string) backend.DataResponse {
+		t.Helper()
+		model, err := json.Marshal(map[string]any{"rawSql": rawSQL, "format": format})
+		require.NoError(t, err)
+		resp, err := s.QueryData(context.Background(), &backend.QueryDataRequest{
+			PluginContext: pCtx,
+			Queries: []backend.DataQuery{{
+				RefID: "A",
+				JSON:  model,
+				TimeRange: backend.TimeRange{
+					From: time.Unix(0, 0),
+					To:   time.Unix(2000, 0),
+				},
+			}},
+		})
+		require.NoError(t, err)
+		return resp.Responses["A"]
+	}
+
+	t.Run("health check", func(t *testing.T) {
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: pCtx})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusOk, res.Status)
+	})
+
+	t.Run("table query", func(t *testing.T) {
+		res := query(t, "SELECT host, value FROM metrics WHERE $__unixEpochFilter(time) ORDER BY value", "table")
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		require.Equal(t, 3, res.Frames[0].Rows())
+	})
+
+	t.Run("time series query", func(t *testing.T) {
+		res := query(t, "SELECT $__unixEpochGroupAlias(time, '1m'), host AS metric, sum(value) AS value FROM metrics GROUP BY 1, 2 ORDER BY 1", "time_series")
+		require.NoError(t, res.Error)
+		require.Len(t, res.Frames, 1)
+		frame := res.Frames[0]
+		require.Equal(t, data.TimeSeriesTimeFieldName, frame.Fields[0].Name)
+		require.Len(t, frame.Fields, 3)
+	})
+
+	t.Run("rejects write statements", func(t *testing.T) {
+		res := query(t, "DELETE FROM metrics", "table")
+		require.ErrorContains(t, res.Error, "DELETE statements are not allowed")
+
+		res = query(t, "SELECT 1; DROP TABLE metrics", "table")
+		require.ErrorContains(t, res.Error, "only a single statement is allowed")
+	})
+
+	t.Run("opens database read-only", func(t *testing.T) {
+		// WITH passes the statement check, the connection has to refuse the write.
+		res := query(t, "WITH x AS (SELECT 1) INSERT INTO metrics SELECT 1, 'c', 1 FROM x", "table")
+		require.Error(t, res.Error)
+
+		check := query(t, "SELECT count(*) AS c FROM metrics", "table")
+		require.NoError(t, check.Error)
+		require.Equal(t, float64(3), *check.Frames[0].Fields[0].At(0).(*float64))
+	})
+}
+
+func TestSQLite_NotAllowed(t *testing.T) {
+	dir := t.TempDir()
+	path := createTestDatabase(t, dir)
+
+	t.Run("file access disabled by default", func(t *testing.T) {
+		s := newTestService(t)
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: pluginContext(t, map[string]any{"path": path})})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Contains(t, res.Message, "file access is disabled")
+	})
+
+	t.Run("file outside of allowed paths", func(t *testing.T) {
+		s := newTestService(t, filepath.Join(dir, "*.sqlite"))
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: pluginContext(t, map[string]any{"path": path})})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+		require.Contains(t, res.Message, "is not allowed")
+	})
+
+	t.Run("unsupported dialect", func(t *testing.T) {
+		s := newTestService(t, dir)
+		res, err := s.CheckHealth(context.Background(), &backend.CheckHealthRequest{PluginContext: pluginContext(t, map[string]any{"path": path, "dialect": "oracle"})})
+		require.NoError(t, err)
+		require.Equal(t, backend.HealthStatusError, res.Status)
+	})
+}
+
+func TestDuckDBConnectionString(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data")
+	require.NoError(t, os.Mkdir(data, 0o750))
+	for _, name := range []string{"a.parquet", "b.parquet", "c.csv"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o600))
+	}
+	db := filepath.Join(dir, "metrics.duckdb")
+	require.NoError(t, os.WriteFile(db, nil, 0o600))
+
+	parse := func(t *testing.T, cnnstr string) (string, url.Values) {
+		t.Helper()
+		path, query, _ := strings.Cut(cnnstr, "?")
+		params, err := url.ParseQuery(query)
+		require.NoError(t, err)
+		return path, params
+	}
+
+	t.Run("disables external access except for allowed paths", func(t *testing.T) {
+		allowlist := newPathAllowlist([]string{data, filepath.Join(dir, "*.parquet"), filepath.Join(dir, "missing.csv")})
+		cnnstr, err := duckDBConnectionString("", allowlist)
+		require.NoError(t, err)
+
+		path, params := parse(t, cnnstr)
+		require.Empty(t, path)
+		require.Equal(t, "false", params.Get("enable_external_access"))
+		require.Equal(t, "true", params.Get("lock_configuration"))
+		require.Equal(t, "['"+data+string(filepath.Separator)+"']", params.Get("allowed_directories"))
+		require.Equal(t, "['"+filepath.Join(dir, "a.parquet")+"', '"+filepath.Join(dir, "b.parquet")+"']", params.Get("allowed_paths"))
+		require.Empty(t, params.Get("access_mode"))
+	})
+
+	t.Run("opens the database file read-only", func(t *testing.T) {
+		cnnstr, err := duckDBConnectionString(db, newPathAllowlist([]string{db}))
+		require.NoError(t, err)
+
+		path, params := parse(t, cnnstr)
+		require.Equal(t, db, path)
+		require.Equal(t, "READ_ONLY", params.Get("access_mode"))
+		require.Equal(t, "false", params.Get("enable_external_access"))
+		require.Equal(t, "[]", params.Get("allowed_directories"))
+		require.Equal(t, "['"+db+"']", params.Get("allowed_paths"))
+	})
+
+	t.Run("file access disabled by default", func(t *testing.T) {
+		_, err := duckDBConnectionString("", newPathAllowlist(nil))
+		require.ErrorIs(t, err, errFileAccessDisabled)
+	})
+
+	t.Run("database file outside of allowed paths", func(t *testing.T) {
+		_, err := duckDBConnectionString(db, newPathAllowlist([]string{data}))
+		require.ErrorContains(t, err, "is not allowed")
+	})
+
+	t.Run("quotes paths", func(t *testing.T) {
+		require.Equal(t, `['/srv/it''s/']`, duckDBList([]string{"/srv/it's/"}))
+	})
+}
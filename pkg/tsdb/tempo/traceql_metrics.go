@@ -0,0 +1,241 @@
+package tempo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/gtime"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/tracing"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb/tempo/kinds/dataquery"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// metricsResponse is the response of the Tempo metrics query range API.
+type metricsResponse struct {
+	Series []metricsSeries `json:"series"`
+}
+
+type metricsSeries struct {
+	Labels  []metricsLabel  `json:"labels"`
+	Samples []metricsSample `json:"samples"`
+}
+
+type metricsLabel struct {
+	Key   string          `json:"key"`
+	Value metricsAnyValue `json:"value"`
+}
+
+// metricsAnyValue is an OTLP AnyValue, 64 bit integers are encoded as
+// strings in the JSON of protobuf messages.
+type metricsAnyValue struct {
+	StringValue *string      `json:"stringValue,omitempty"`
+	IntValue    *json.Number `json:"intValue,omitempty"`
+	DoubleValue *float64     `json:"doubleValue,omitempty"`
+	BoolValue   *bool        `json:"boolValue,omitempty"`
+}
+
+func (v metricsAnyValue) String() string {
+	switch {
+	case v.StringValue != nil:
+		return *v.StringValue
+	case v.IntValue != nil:
+		return v.IntValue.String()
+	case v.DoubleValue != nil:
+		return strconv.FormatFloat(*v.DoubleValue, 'f', -1, 64)
+	case v.BoolValue != nil:
+		return strconv.FormatBool(*v.BoolValue)
+	}
+	return ""
+}
+
+type metricsSample struct {
+	TimestampMs int64
+	Value       float64
+}
+
+// UnmarshalJSON accepts timestamps encoded as numbers or as strings.
+func (s *metricsSample) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		TimestampMs json.Number `json:"timestampMs"`
+		Value       float64     `json:"value"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	ts, err := raw.TimestampMs.Int64()
+	if err != nil {
+		return fmt.Errorf("invalid sample timestamp %q: %w", raw.TimestampMs, err)
+	}
+	s.TimestampMs = ts
+	s.Value = raw.Value
+	return nil
+}
+
+// getTraceQLMetrics runs a TraceQL metrics query, like
+// { status = error } | rate() by (resource.service.name), and returns a
+// time series per series of the result.
+func (s *Service) getTraceQLMetrics(ctx context.Context, pCtx backend.PluginContext, query backend.DataQuery) (*backend.DataResponse, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+	ctxLogger.Debug("Getting TraceQL metrics", "function", logEntrypoint())
+
+	result := &backend.DataResponse{}
+
+	ctx, span := tracing.DefaultTracer().Start(ctx, "datasource.tempo.getTraceQLMetrics", trace.WithAttributes(
+		attribute.String("queryType", query.QueryType),
+	))
+	defer span.End()
+
+	model := &dataquery.TempoQuery{}
+	err := json.Unmarshal(query.JSON, model)
+	if err != nil {
+		ctxLogger.Error("Failed to unmarshall Tempo query model", "error", err, "function", logEntrypoint())
+		return result, err
+	}
+
+	if model.Query == nil || strings.TrimSpace(*model.Query) == "" {
+		err := fmt.Errorf("TraceQL metrics query is required")
+		ctxLogger.Error("Failed to validate model query", "error", err, "function", logEntrypoint())
+		return result, err
+	}
+
+	step, err := metricsStep(model, query)
+	if err != nil {
+		ctxLogger.Error("Failed to parse step", "error", err, "function", logEntrypoint())
+		return result, err
+	}
+
+	dsInfo, err := s.getDSInfo(ctx, pCtx)
+	if err != nil {
+		ctxLogger.Error("Failed to get datasource information", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	request, err := s.createMetricsRequest(ctx, dsInfo, *model.Query, query.TimeRange, step)
+	if err != nil {
+		ctxLogger.Error("Failed to create request", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return result, err
+	}
+
+	resp, err := dsInfo.HTTPClient.Do(request)
+	if err != nil {
+		ctxLogger.Error("Failed to send request to Tempo", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return result, fmt.Errorf("failed get to tempo: %w", err)
+	}
+
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			ctxLogger.Error("Failed to close response body", "error", err, "function", logEntrypoint())
+		}
+	}()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		ctxLogger.Error("Failed to read response body", "error", err, "function", logEntrypoint())
+		return &backend.DataResponse{}, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		ctxLogger.Error("Failed to get TraceQL metrics", "status", resp.Status, "function", logEntrypoint())
+		result.Error = fmt.Errorf("failed to run TraceQL metrics query: %s Status: %s Body: %s", *model.Query, resp.Status, string(body))
+		span.RecordError(result.Error)
+		span.SetStatus(codes.Error, result.Error.Error())
+		return result, nil
+	}
+
+	var metrics metricsResponse
+	if err := json.Unmarshal(body, &metrics); err != nil {
+		ctxLogger.Error("Failed to unmarshal TraceQL metrics response", "error", err, "function", logEntrypoint())
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return &backend.DataResponse{}, fmt.Errorf("failed to unmarshal TraceQL metrics response: %w", err)
+	}
+
+	result.Frames = metricsToFrames(query.RefID, metrics)
+	ctxLogger.Debug("Successfully got TraceQL metrics", "function", logEntrypoint())
+	return result, nil
+}
+
+// metricsStep returns the step of the query, or the query interval. The step
+// is at least a second, the precision of Tempo metrics.
+func metricsStep(model *dataquery.TempoQuery, query backend.DataQuery) (time.Duration, error) {
+	step := query.Interval
+	if model.Step != nil && *model.Step != "" {
+		var err error
+		step, err = gtime.ParseInterval(*model.Step)
+		if err != nil {
+			return 0, fmt.Errorf("invalid step %q: %w", *model.Step, err)
+		}
+	}
+	if step < time.Second {
+		step = time.Second
+	}
+	return step.Truncate(time.Second), nil
+}
+
+func (s *Service) createMetricsRequest(ctx context.Context, dsInfo *Datasource, query string, timeRange backend.TimeRange, step time.Duration) (*http.Request, error) {
+	ctxLogger := s.logger.FromContext(ctx)
+
+	params := url.Values{}
+	params.Set("q", query)
+	params.Set("start", strconv.FormatInt(timeRange.From.Unix(), 10))
+	params.Set("end", strconv.FormatInt(timeRange.To.Unix(), 10))
+	params.Set("step", fmt.Sprintf("%ds", int64(step/time.Second)))
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/api/metrics/query_range?%s", dsInfo.URL, params.Encode()), nil)
+	if err != nil {
+		ctxLogger.Error("Failed to create request", "error", err, "function", logEntrypoint())
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+func metricsToFrames(refID string, metrics metricsResponse) data.Frames {
+	frames := make(data.Frames, 0, len(metrics.Series))
+	for _, series := range metrics.Series {
+		labels := data.Labels{}
+		for _, label := range series.Labels {
+			labels[label.Key] = label.Value.String()
+		}
+
+		samples := make([]metricsSample, len(series.Samples))
+		copy(samples, series.Samples)
+		sort.Slice(samples, func(i, j int) bool { return samples[i].TimestampMs < samples[j].TimestampMs })
+
+		times := make([]time.Time, len(samples))
+		values := make([]float64, len(samples))
+		for i, sample := range samples {
+			times[i] = time.UnixMilli(sample.TimestampMs).UTC()
+			values[i] = sample.Value
+		}
+
+		frame := data.NewFrame("",
+			data.NewField(data.TimeSeriesTimeFieldName, nil, times),
+			data.NewField(data.TimeSeriesValueFieldName, labels, values),
+		)
+		frame.RefID = refID
+		frame.Meta = &data.FrameMeta{
+			Type:        data.FrameTypeTimeSeriesMulti,
+			TypeVersion: data.FrameTypeVersion{0, 1},
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
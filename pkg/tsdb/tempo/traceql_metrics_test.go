@@ -0,0 +1,118 @@
+package tempo
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/instancemgmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeInstanceManager struct {
+	ds *Datasource
+}
+
+func (m fakeInstanceManager) Get(_ context.Context, _ backend.PluginContext) (instancemgmt.Instance, error) {
+	return m.ds, nil
+}
+
+func (m fakeInstanceManager) Do(_ context.Context, _ backend.PluginContext, _ instancemgmt.InstanceCallbackFunc) error {
+	return nil
+}
+
+// newFakeTempo starts a Tempo HTTP API server answering metrics queries.
+func newFakeTempo(t *testing.T, handler http.HandlerFunc) *Service {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &Service{
+		logger: log.New("tsdb.tempo.test"),
+		im:     fakeInstanceManager{ds: &Datasource{HTTPClient: server.Client(), URL: server.URL}},
+	}
+}
+
+func TestTraceQLMetrics(t *testing.T) {
+	query := backend.DataQuery{
+		RefID:     "A",
+		QueryType: "traceqlMetrics",
+		Interval:  15 * time.Second,
+		TimeRange: backend.TimeRange{From: time.Unix(1700000000, 0), To: time.Unix(1700000060, 0)},
+		JSON:      []byte(`{"query": "{ status = error } | rate() by (resource.service.name)", "step": "30s"}`),
+	}
+
+	t.Run("should return a time series per series", func(t *testing.T) {
+		var params url.Values
+		service := newFakeTempo(t, func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/api/metrics/query_range", r.URL.Path)
+			params = r.URL.Query()
+			_, _ = w.Write([]byte(`{"series": [
+				{
+					"labels": [{"key": "resource.service.name", "value": {"stringValue": "api"}}],
+					"samples": [{"timestampMs": "1700000030000", "value": 2.5}, {"timestampMs": "1700000000000", "value": 1}]
+				},
+				{
+					"labels": [{"key": "p", "value": {"doubleValue": 0.9}}, {"key": "span.http.status_code", "value": {"intValue": "500"}}],
+					"samples": [{"timestampMs": 1700000000000, "value": 0.25}]
+				}
+			]}`))
+		})
+
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{query}})
+		require.NoError(t, err)
+		require.Equal(t, "{ status = error } | rate() by (resource.service.name)", params.Get("q"))
+		require.Equal(t, "1700000000", params.Get("start"))
+		require.Equal(t, "1700000060", params.Get("end"))
+		require.Equal(t, "30s", params.Get("step"))
+
+		frames := resp.Responses["A"].Frames
+		require.Len(t, frames, 2)
+		require.Equal(t, data.Labels{"resource.service.name": "api"}, frames[0].Fields[1].Labels)
+		require.Equal(t, time.UnixMilli(1700000000000).UTC(), frames[0].Fields[0].At(0))
+		require.Equal(t, 1.0, frames[0].Fields[1].At(0))
+		require.Equal(t, 2.5, frames[0].Fields[1].At(1))
+		require.Equal(t, data.FrameTypeTimeSeriesMulti, frames[0].Meta.Type)
+		require.Equal(t, data.Labels{"p": "0.9", "span.http.status_code": "500"}, frames[1].Fields[1].Labels)
+	})
+
+	t.Run("should default the step to the query interval", func(t *testing.T) {
+		var step string
+		service := newFakeTempo(t, func(w http.ResponseWriter, r *http.Request) {
+			step = r.URL.Query().Get("step")
+			_, _ = w.Write([]byte(`{"series": []}`))
+		})
+
+		q := query
+		q.JSON = []byte(`{"query": "{} | count_over_time()"}`)
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{q}})
+		require.NoError(t, err)
+		require.Empty(t, resp.Responses["A"].Frames)
+		require.Equal(t, "15s", step)
+	})
+
+	t.Run("should return errors of Tempo", func(t *testing.T) {
+		service := newFakeTempo(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`invalid TraceQL query`))
+		})
+
+		resp, err := service.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{query}})
+		require.NoError(t, err)
+		require.ErrorContains(t, resp.Responses["A"].Error, "invalid TraceQL query")
+	})
+
+	t.Run("should require a query", func(t *testing.T) {
+		service := newFakeTempo(t, func(w http.ResponseWriter, r *http.Request) {})
+
+		q := query
+		q.JSON = []byte(`{"query": " "}`)
+		_, err := service.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{q}})
+		require.ErrorContains(t, err, "TraceQL metrics query is required")
+	})
+}
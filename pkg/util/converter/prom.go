@@ -22,6 +22,15 @@ type Options struct {
 	Dataplane bool
 }
 
+// NativeHistogramFrameType is the type of the frames of native histograms,
+// where each row is a bucket of the histogram at a time.
+const NativeHistogramFrameType data.FrameType = "heatmap-cells"
+
+// IsNativeHistogramFrame returns true when frame holds a native histogram
+func IsNativeHistogramFrame(frame *data.Frame) bool {
+	return frame != nil && frame.Meta != nil && frame.Meta.Type == NativeHistogramFrameType
+}
+
 func rspErr(e error) backend.DataResponse {
 	return backend.DataResponse{Error: e}
 }
@@ -629,7 +638,8 @@ func readMatrixOrVectorMulti(iter *jsonitere.Iterator, resultType string, opt Op
 			histogram.yMin.Labels = valueField.Labels
 			frame := data.NewFrame(valueField.Name, histogram.time, histogram.yMin, histogram.yMax, histogram.count, histogram.yLayout)
 			frame.Meta = &data.FrameMeta{
-				Type: "heatmap-cells",
+				Type:   NativeHistogramFrameType,
+				Custom: resultTypeToCustomMeta(resultType),
 			}
 			if frame.Name == data.TimeSeriesValueFieldName {
 				frame.Name = "" // only set the name if useful
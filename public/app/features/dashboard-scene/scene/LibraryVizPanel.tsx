@@ -11,14 +11,22 @@ interface LibraryVizPanelState extends SceneObjectState {
   title: string;
   uid: string;
   name: string;
+  // Version of the library panel the dashboard is pinned to, the latest version is displayed when not set.
+  version?: number;
   panel?: VizPanel;
 }
 
 export class LibraryVizPanel extends SceneObjectBase<LibraryVizPanelState> {
   static Component = LibraryPanelRenderer;
 
-  constructor({ uid, title, key, name }: Pick<LibraryVizPanelState, 'uid' | 'title' | 'key' | 'name'>) {
-    super({ uid, title, key, name });
+  constructor({
+    uid,
+    title,
+    key,
+    name,
+    version,
+  }: Pick<LibraryVizPanelState, 'uid' | 'title' | 'key' | 'name' | 'version'>) {
+    super({ uid, title, key, name, version });
 
     this.addActivationHandler(this._onActivate);
   }
@@ -31,7 +39,7 @@ export class LibraryVizPanel extends SceneObjectBase<LibraryVizPanelState> {
     const { title } = this.state;
     let vizPanel = new VizPanel({ title });
     try {
-      const libPanel = await getLibraryPanel(this.state.uid, true);
+      const libPanel = await getLibraryPanel(this.state.uid, true, this.state.version);
       const libPanelModel = new PanelModel(libPanel.model);
       vizPanel.setState({
         options: libPanelModel.options ?? {},
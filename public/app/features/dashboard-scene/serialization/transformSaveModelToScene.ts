@@ -35,6 +35,7 @@ import {
   AdHocFilterSet,
 } from '@grafana/scenes';
 import { DashboardModel, PanelModel } from 'app/features/dashboard/state';
+import { getLibraryPanelRef } from 'app/features/dashboard/state/PanelModel';
 import { DashboardDTO } from 'app/types';
 
 import { DashboardAnnotationsDataLayer } from '../scene/DashboardAnnotationsDataLayer';
@@ -342,6 +343,7 @@ export function buildGridItemForLibPanel(panel: PanelModel) {
       title: panel.title,
       uid: panel.libraryPanel.uid,
       name: panel.libraryPanel.name,
+      version: getLibraryPanelRef(panel).version,
       key: getVizPanelKeyForPanelId(panel.id),
     }),
     y: panel.gridPos.y,
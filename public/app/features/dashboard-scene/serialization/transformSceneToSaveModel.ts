@@ -139,6 +139,7 @@ export function gridItemToPanel(gridItem: SceneGridItemLike, isSnapshot = false)
         libraryPanel: {
           name: gridItem.state.body.state.name,
           uid: gridItem.state.body.state.uid,
+          ...(gridItem.state.body.state.version ? { version: gridItem.state.body.state.version } : {}),
         },
       } as Panel;
     }
@@ -30,13 +30,18 @@ import { NoUpsertPermissionsAlert } from '../ModalAlerts/NoUpsertPermissionsAler
 import { SaveDashboardChangesAlert } from '../ModalAlerts/SaveDashboardChangesAlert';
 import { UnsupportedDataSourcesAlert } from '../ModalAlerts/UnsupportedDataSourcesAlert';
 import { UnsupportedTemplateVariablesAlert } from '../ModalAlerts/UnsupportedTemplateVariablesAlert';
-import { dashboardHasTemplateVariables, generatePublicDashboardUrl } from '../SharePublicDashboardUtils';
+import {
+  generatePublicDashboardUrl,
+  getUnsupportedDashboardTemplateVariables,
+  isSupportedTemplateVariable,
+} from '../SharePublicDashboardUtils';
 import { useGetUnsupportedDataSources } from '../useGetUnsupportedDataSources';
 
 import { Configuration } from './Configuration';
 import { EmailSharingConfiguration } from './EmailSharingConfiguration';
 import { SettingsBar } from './SettingsBar';
 import { SettingsSummary } from './SettingsSummary';
+import { TemplateVariablesConfiguration } from './TemplateVariablesConfiguration';
 
 const selectors = e2eSelectors.pages.ShareDashboardModal.PublicDashboard;
 
@@ -57,6 +62,8 @@ const ConfigPublicDashboard = () => {
   const dashboardState = useSelector((store) => store.dashboard);
   const dashboard = dashboardState.getModel()!;
   const dashboardVariables = dashboard.getVariables();
+  const supportedVariables = dashboardVariables.filter(isSupportedTemplateVariable);
+  const unsupportedVariables = getUnsupportedDashboardTemplateVariables(dashboardVariables);
 
   const { unsupportedDataSources } = useGetUnsupportedDataSources(dashboard);
 
@@ -95,6 +102,24 @@ const ConfigPublicDashboard = () => {
     await handleSubmit((data) => onUpdate(data))();
   };
 
+  const onTemplateVariableChange = (name: string, values: string[]) => {
+    // the variables without allowed values are removed, their value can't be changed by the viewers
+    const templateVariables = { ...publicDashboard?.templateVariables };
+    if (values.length) {
+      templateVariables[name] = values;
+    } else {
+      delete templateVariables[name];
+    }
+
+    update({
+      dashboard,
+      payload: {
+        ...publicDashboard!,
+        templateVariables,
+      },
+    });
+  };
+
   const onDismissDelete = () => {
     showModal(ShareModal, {
       dashboard,
@@ -111,7 +136,9 @@ const ConfigPublicDashboard = () => {
     <div className={styles.configContainer}>
       {hasWritePermissions && dashboard.hasUnsavedChanges() && <SaveDashboardChangesAlert />}
       {!hasWritePermissions && <NoUpsertPermissionsAlert mode="edit" />}
-      {dashboardHasTemplateVariables(dashboardVariables) && <UnsupportedTemplateVariablesAlert />}
+      {!!unsupportedVariables.length && (
+        <UnsupportedTemplateVariablesAlert unsupportedTemplateVariables={unsupportedVariables.join(', ')} />
+      )}
       {!!unsupportedDataSources.length && (
         <UnsupportedDataSourcesAlert unsupportedDataSources={unsupportedDataSources.join(', ')} />
       )}
@@ -180,6 +207,19 @@ const ConfigPublicDashboard = () => {
         </SettingsBar>
       </Field>
 
+      {!!supportedVariables.length && (
+        <Field className={styles.fieldSpace}>
+          <SettingsBar title="Template variables">
+            <TemplateVariablesConfiguration
+              disabled={disableInputs}
+              variables={supportedVariables}
+              templateVariables={publicDashboard?.templateVariables}
+              onChange={onTemplateVariableChange}
+            />
+          </SettingsBar>
+        </Field>
+      )}
+
       <Layout
         orientation={isDesktop ? 0 : 1}
         justify={isDesktop ? 'flex-end' : 'flex-start'}
@@ -0,0 +1,50 @@
+import React from 'react';
+
+import { SelectableValue } from '@grafana/data/src';
+import { selectors as e2eSelectors } from '@grafana/e2e-selectors/src';
+import { Field, FieldSet, MultiSelect, VerticalGroup } from '@grafana/ui/src';
+
+import { getTemplateVariableValues, SupportedTemplateVariable } from '../SharePublicDashboardUtils';
+
+const selectors = e2eSelectors.pages.ShareDashboardModal.PublicDashboard;
+
+export const TemplateVariablesConfiguration = ({
+  disabled,
+  variables,
+  templateVariables,
+  onChange,
+}: {
+  disabled: boolean;
+  variables: SupportedTemplateVariable[];
+  templateVariables?: Record<string, string[]>;
+  onChange: (name: string, values: string[]) => void;
+}) => {
+  return (
+    <FieldSet disabled={disabled} data-testid={selectors.TemplateVariablesConfiguration}>
+      <VerticalGroup spacing="md">
+        {variables.map((variable) => {
+          const options = getTemplateVariableValues(variable).map((value) => ({ label: value, value }));
+          return (
+            <Field
+              key={variable.name}
+              label={variable.label || variable.name}
+              description="Values viewers can select. The variable can't be changed when no value is allowed"
+            >
+              <MultiSelect
+                options={options}
+                value={templateVariables?.[variable.name] ?? []}
+                onChange={(selected: Array<SelectableValue<string>>) =>
+                  onChange(variable.name, selected.map((option) => option.value!))
+                }
+                disabled={disabled}
+                isClearable
+                placeholder="Choose allowed values"
+                aria-label={`Allowed values of ${variable.name}`}
+              />
+            </Field>
+          );
+        })}
+      </VerticalGroup>
+    </FieldSet>
+  );
+};
@@ -14,7 +14,7 @@ import { shareDashboardType } from '../../utils';
 import { NoUpsertPermissionsAlert } from '../ModalAlerts/NoUpsertPermissionsAlert';
 import { UnsupportedDataSourcesAlert } from '../ModalAlerts/UnsupportedDataSourcesAlert';
 import { UnsupportedTemplateVariablesAlert } from '../ModalAlerts/UnsupportedTemplateVariablesAlert';
-import { dashboardHasTemplateVariables } from '../SharePublicDashboardUtils';
+import { getUnsupportedDashboardTemplateVariables } from '../SharePublicDashboardUtils';
 import { useGetUnsupportedDataSources } from '../useGetUnsupportedDataSources';
 
 import { AcknowledgeCheckboxes } from './AcknowledgeCheckboxes';
@@ -34,6 +34,7 @@ const CreatePublicDashboard = ({ isError }: { isError: boolean }) => {
   const dashboard = dashboardState.getModel()!;
 
   const { unsupportedDataSources } = useGetUnsupportedDataSources(dashboard);
+  const unsupportedVariables = getUnsupportedDashboardTemplateVariables(dashboard.getVariables());
   const [createPublicDashboard, { isLoading: isSaveLoading }] = useCreatePublicDashboardMutation();
 
   const disableInputs = !hasWritePermissions || isSaveLoading || isError;
@@ -47,12 +48,16 @@ const CreatePublicDashboard = ({ isError }: { isError: boolean }) => {
     <div className={styles.container}>
       <div>
         <p className={styles.title}>Welcome to public dashboards!</p>
-        <p className={styles.description}>Currently, we don’t support template variables or frontend data sources</p>
+        <p className={styles.description}>
+          Currently, we don’t support frontend data sources, and only support query and custom template variables
+        </p>
       </div>
 
       {!hasWritePermissions && <NoUpsertPermissionsAlert mode="create" />}
 
-      {dashboardHasTemplateVariables(dashboard.getVariables()) && <UnsupportedTemplateVariablesAlert />}
+      {!!unsupportedVariables.length && (
+        <UnsupportedTemplateVariablesAlert unsupportedTemplateVariables={unsupportedVariables.join(', ')} />
+      )}
 
       {!!unsupportedDataSources.length && (
         <UnsupportedDataSourcesAlert unsupportedDataSources={unsupportedDataSources.join(', ')} />
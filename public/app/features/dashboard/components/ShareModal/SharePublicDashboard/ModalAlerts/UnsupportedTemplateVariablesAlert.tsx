@@ -5,13 +5,18 @@ import { Alert } from '@grafana/ui/src';
 
 const selectors = e2eSelectors.pages.ShareDashboardModal.PublicDashboard;
 
-export const UnsupportedTemplateVariablesAlert = () => (
+export const UnsupportedTemplateVariablesAlert = ({
+  unsupportedTemplateVariables,
+}: {
+  unsupportedTemplateVariables: string;
+}) => (
   <Alert
     severity="warning"
-    title="Template variables are not supported"
+    title="Unsupported template variables"
     data-testid={selectors.TemplateVariablesWarningAlert}
     bottomSpacing={0}
   >
-    This public dashboard may not work since it uses template variables
+    Only query and custom template variables are supported in public dashboards. Panels that use these template
+    variables may not function properly: {unsupportedTemplateVariables}.
   </Alert>
 );
@@ -13,6 +13,7 @@ import { backendSrv } from 'app/core/services/backend_srv';
 import { contextSrv } from 'app/core/services/context_srv';
 import { Echo } from 'app/core/services/echo/Echo';
 import { createDashboardModelFixture } from 'app/features/dashboard/state/__fixtures__/dashboardFixtures';
+import { customBuilder } from 'app/features/variables/shared/testing/builders';
 
 import { trackDashboardSharingTypeOpen, trackDashboardSharingActionPerType } from '../analytics';
 import { shareDashboardType } from '../utils';
@@ -107,12 +108,18 @@ const alertTests = () => {
     await renderSharePublicDashboard();
     expect(screen.queryByTestId(selectors.NoUpsertPermissionsWarningAlert)).toBeInTheDocument();
   });
-  it('when dashboard has template variables, warning is shown', async () => {
-    jest.spyOn(sharePublicDashboardUtils, 'dashboardHasTemplateVariables').mockReturnValue(true);
+  it('when dashboard has unsupported template variables, warning is shown', async () => {
+    jest.spyOn(sharePublicDashboardUtils, 'getUnsupportedDashboardTemplateVariables').mockReturnValue(['interval']);
 
     await renderSharePublicDashboard();
     expect(screen.queryByTestId(selectors.TemplateVariablesWarningAlert)).toBeInTheDocument();
   });
+  it('when dashboard has only supported template variables, warning is not shown', async () => {
+    jest.spyOn(sharePublicDashboardUtils, 'getUnsupportedDashboardTemplateVariables').mockReturnValue([]);
+
+    await renderSharePublicDashboard();
+    expect(screen.queryByTestId(selectors.TemplateVariablesWarningAlert)).not.toBeInTheDocument();
+  });
   it('when dashboard has unsupported datasources, warning is shown', async () => {
     const panelModel = {
       targets: [
@@ -314,6 +321,18 @@ describe('SharePublic - Already persisted', () => {
 
     expect(screen.getByTestId(selectors.PauseSwitch)).toBeChecked();
   });
+  it('renders the allowed values of the template variables', async () => {
+    const variable = customBuilder().withId('server').withName('server').withOptions('a', 'b').build();
+    jest.spyOn(mockDashboard, 'getVariables').mockReturnValue([variable]);
+    server.use(getExistentPublicDashboardResponse({ templateVariables: { server: ['a'] } }));
+
+    await renderSharePublicDashboard();
+    await userEvent.click(screen.getByText('Template variables'));
+
+    expect(screen.getByTestId(selectors.TemplateVariablesConfiguration)).toBeInTheDocument();
+    expect(screen.getByLabelText('Allowed values of server')).toBeInTheDocument();
+    expect(screen.getByText('a')).toBeInTheDocument();
+  });
   it('does not render email sharing section', async () => {
     await renderSharePublicDashboard();
 
@@ -3,11 +3,12 @@ import { DataSourceWithBackend } from '@grafana/runtime';
 import { updateConfig } from 'app/core/config';
 import { mockDataSource } from 'app/features/alerting/unified/mocks';
 import { PanelModel } from 'app/features/dashboard/state/PanelModel';
-import { VariableModel } from 'app/features/variables/types';
+import { customBuilder, intervalBuilder, queryBuilder } from 'app/features/variables/shared/testing/builders';
 
 import {
   PublicDashboard,
-  dashboardHasTemplateVariables,
+  getTemplateVariableValues,
+  getUnsupportedDashboardTemplateVariables,
   publicDashboardPersisted,
   generatePublicDashboardUrl,
   getUnsupportedDashboardDatasources,
@@ -36,16 +37,32 @@ jest.mock('@grafana/runtime/src/services/dataSourceSrv', () => {
   };
 });
 
-describe('dashboardHasTemplateVariables', () => {
-  it('false', () => {
-    let variables: VariableModel[] = [];
-    expect(dashboardHasTemplateVariables(variables)).toBe(false);
+describe('getUnsupportedDashboardTemplateVariables', () => {
+  it('returns no variables when there are only query and custom variables', () => {
+    const variables = [
+      queryBuilder().withId('host').withName('host').build(),
+      customBuilder().withId('region').withName('region').build(),
+    ];
+    expect(getUnsupportedDashboardTemplateVariables(variables)).toEqual([]);
   });
 
-  it('true', () => {
-    //@ts-ignore
-    let variables: VariableModel[] = ['a'];
-    expect(dashboardHasTemplateVariables(variables)).toBe(true);
+  it('returns the names of the other variables', () => {
+    const variables = [
+      queryBuilder().withId('host').withName('host').build(),
+      intervalBuilder().withId('interval').withName('interval').build(),
+    ];
+    expect(getUnsupportedDashboardTemplateVariables(variables)).toEqual(['interval']);
+  });
+});
+
+describe('getTemplateVariableValues', () => {
+  it('returns the values of the options without the All option', () => {
+    const variable = customBuilder()
+      .withId('region')
+      .withName('region')
+      .withOptions({ text: 'All', value: '$__all' }, 'eu', 'us')
+      .build();
+    expect(getTemplateVariableValues(variable)).toEqual(['eu', 'us']);
   });
 });
 
@@ -1,7 +1,8 @@
+import { CustomVariableModel, QueryVariableModel, TypedVariableModel } from '@grafana/data';
 import { DataSourceWithBackend } from '@grafana/runtime';
 import { getConfig } from 'app/core/config';
 import { getDatasourceSrv } from 'app/features/plugins/datasource_srv';
-import { VariableModel } from 'app/features/variables/types';
+import { ALL_VARIABLE_VALUE } from 'app/features/variables/constants';
 
 import { PanelModel } from '../../../state';
 import { shareDashboardType } from '../utils';
@@ -26,6 +27,8 @@ export interface PublicDashboard extends PublicDashboardSettings {
   timeSettings?: object;
   share: PublicDashboardShareType;
   recipients?: Array<{ uid: string; recipient: string }>;
+  // values viewers are allowed to select, by template variable name
+  templateVariables?: Record<string, string[]>;
 }
 
 export interface SessionDashboard {
@@ -40,9 +43,33 @@ export interface SessionUser {
   totalDashboards: number;
 }
 
+export type SupportedTemplateVariable = QueryVariableModel | CustomVariableModel;
+
 // Instance methods
-export const dashboardHasTemplateVariables = (variables: VariableModel[]): boolean => {
-  return variables.length > 0;
+export const isSupportedTemplateVariable = (variable: TypedVariableModel): variable is SupportedTemplateVariable => {
+  return variable.type === 'query' || variable.type === 'custom';
+};
+
+/**
+ * Get the names of the template variables whose values can't be selected by the viewers of a public dashboard.
+ */
+export const getUnsupportedDashboardTemplateVariables = (variables: TypedVariableModel[]): string[] => {
+  return variables.filter((variable) => !isSupportedTemplateVariable(variable)).map((variable) => variable.name);
+};
+
+/**
+ * Get the values of a template variable that can be allowed for the viewers of a public dashboard.
+ */
+export const getTemplateVariableValues = (variable: SupportedTemplateVariable): string[] => {
+  const values = new Set<string>();
+  for (const option of variable.options) {
+    for (const value of Array.isArray(option.value) ? option.value : [option.value]) {
+      if (value !== ALL_VARIABLE_VALUE) {
+        values.add(value);
+      }
+    }
+  }
+  return Array.from(values);
 };
 
 export const publicDashboardPersisted = (publicDashboard?: PublicDashboard): boolean => {
@@ -44,7 +44,7 @@ import { getTimeSrv } from '../services/TimeSrv';
 import { mergePanels, PanelMergeInfo } from '../utils/panelMerge';
 
 import { DashboardMigrator } from './DashboardMigrator';
-import { PanelModel, autoMigrateAngular } from './PanelModel';
+import { PanelModel, autoMigrateAngular, getLibraryPanelRef } from './PanelModel';
 import { TimeModel } from './TimeModel';
 import { deleteScopeVars, isOnTheSameGridRow } from './utils';
 
@@ -325,15 +325,12 @@ export class DashboardModel implements TimeModel {
       .map((panel) => {
         // Clean libarary panels on save
         if (panel.libraryPanel) {
-          const { id, title, libraryPanel, gridPos } = panel;
+          const { id, title, gridPos } = panel;
           return {
             id,
             title,
             gridPos,
-            libraryPanel: {
-              uid: libraryPanel.uid,
-              name: libraryPanel.name,
-            },
+            libraryPanel: getLibraryPanelRef(panel),
           };
         }
 
@@ -13,6 +13,7 @@ import {
 import { getPanelPlugin } from '@grafana/data/test/__mocks__/pluginMocks';
 import { mockStandardFieldConfigOptions } from '@grafana/data/test/helpers/fieldConfig';
 import { setTemplateSrv } from '@grafana/runtime';
+import { LibraryPanel } from '@grafana/schema';
 import { queryBuilder } from 'app/features/variables/shared/testing/builders';
 
 import { PanelQueryRunner } from '../../query/state/PanelQueryRunner';
@@ -254,6 +255,28 @@ describe('PanelModel', () => {
       expect(saveModel.panels[1].targets).toBeTruthy();
     });
 
+    it('getSaveModel should keep the pinned versions of libraryPanels in a collapsed row', () => {
+      const loadedPanel = new PanelModel({ ...modelJson, libraryPanel: { uid: 'a', name: 'Loaded' } });
+      loadedPanel.initLibraryPanel(
+        { uid: 'a', name: 'Loaded', version: 5, model: modelJson } as unknown as LibraryPanel,
+        3
+      );
+      const newmodel = new PanelModel({
+        type: 'row',
+        panels: [
+          loadedPanel,
+          { ...modelJson, libraryPanel: { uid: 'b', name: 'Pinned', version: 2 } },
+          { ...modelJson, libraryPanel: { uid: 'c', name: 'Latest' } },
+        ],
+      });
+      const saveModel = newmodel.getSaveModel();
+      expect(saveModel.panels.map((panel: PanelModel) => panel.libraryPanel)).toEqual([
+        { uid: 'a', name: 'Loaded', version: 3 },
+        { uid: 'b', name: 'Pinned', version: 2 },
+        { uid: 'c', name: 'Latest' },
+      ]);
+    });
+
     describe('variables interpolation', () => {
       beforeEach(() => {
         model.scopedVars = {
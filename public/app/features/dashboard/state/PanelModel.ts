@@ -67,6 +67,7 @@ const notPersistedProperties: { [str: string]: boolean } = {
   dataSupport: true,
   key: true,
   isNew: true,
+  libraryPanelVersion: true,
 };
 
 // For angular panels we need to clean up properties when changing type
@@ -178,6 +179,8 @@ export class PanelModel implements DataConfigSource, IPanelModel {
   declare transparent: boolean;
 
   libraryPanel?: LibraryPanelRef | LibraryPanel;
+  // version of the library panel the dashboard is pinned to, saved in the reference to the library panel
+  libraryPanelVersion?: number;
 
   autoMigrateFrom?: string;
 
@@ -314,15 +317,12 @@ export class PanelModel implements DataConfigSource, IPanelModel {
     if (this.type === 'row' && this.panels && this.panels.length > 0) {
       model.panels = this.panels.map((panel) => {
         if (panel.libraryPanel) {
-          const { id, title, libraryPanel, gridPos } = panel;
+          const { id, title, gridPos } = panel;
           return {
             id,
             title,
             gridPos,
-            libraryPanel: {
-              uid: libraryPanel.uid,
-              name: libraryPanel.name,
-            },
+            libraryPanel: getLibraryPanelRef(panel),
           };
         }
 
@@ -673,7 +673,7 @@ export class PanelModel implements DataConfigSource, IPanelModel {
     return this.replaceVariables(this.title, undefined, 'text');
   }
 
-  initLibraryPanel(libPanel: LibraryPanel) {
+  initLibraryPanel(libPanel: LibraryPanel, pinnedVersion?: number) {
     for (const [key, val] of Object.entries(libPanel.model)) {
       switch (key) {
         case 'id':
@@ -684,6 +684,7 @@ export class PanelModel implements DataConfigSource, IPanelModel {
       (this as any)[key] = val; // :grimmice:
     }
     this.libraryPanel = libPanel;
+    this.libraryPanelVersion = pinnedVersion;
   }
 
   unlinkLibraryPanel() {
@@ -693,6 +694,18 @@ export class PanelModel implements DataConfigSource, IPanelModel {
   }
 }
 
+/**
+ * Returns the reference to the library panel of a panel saved in dashboards, with the version the dashboard is
+ * pinned to if any. Panels of collapsed rows keep the reference they were loaded with.
+ */
+export function getLibraryPanelRef(panel: Pick<PanelModel, 'libraryPanel' | 'libraryPanelVersion'>): LibraryPanelRef {
+  const { uid, name } = panel.libraryPanel!;
+  const version =
+    panel.libraryPanel && 'model' in panel.libraryPanel ? panel.libraryPanelVersion : panel.libraryPanel?.version;
+
+  return version ? { uid, name, version } : { uid, name };
+}
+
 function getPluginVersion(plugin: PanelPlugin): string {
   return plugin && plugin.meta.info.version ? plugin.meta.info.version : config.buildInfo.version;
 }
@@ -10,6 +10,7 @@ import {
   LibraryElementDTO,
   LibraryElementKind,
   LibraryElementsSearchResult,
+  LibraryElementVersionDTO,
   PanelModelWithLibraryPanel,
 } from '../types';
 
@@ -48,7 +49,7 @@ export async function getLibraryPanels({
   return result;
 }
 
-export async function getLibraryPanel(uid: string, isHandled = false): Promise<LibraryElementDTO> {
+export async function getLibraryPanel(uid: string, isHandled = false, version?: number): Promise<LibraryElementDTO> {
   const response = await lastValueFrom(
     getBackendSrv().fetch<{ result: LibraryElementDTO }>({
       method: 'GET',
@@ -57,8 +58,22 @@ export async function getLibraryPanel(uid: string, isHandled = false): Promise<L
       showErrorAlert: !isHandled,
     })
   );
+  let { result } = response.data;
+
+  // dashboards pinned to a version of the library panel display the model of that version
+  if (version) {
+    const versionResponse = await lastValueFrom(
+      getBackendSrv().fetch<{ result: LibraryElementVersionDTO }>({
+        method: 'GET',
+        url: `/api/library-elements/${uid}/versions/${version}`,
+        showSuccessAlert: !isHandled,
+        showErrorAlert: !isHandled,
+      })
+    );
+    result = { ...result, model: versionResponse.data.result.model };
+  }
+
   // kinda heavy weight migration process!!!
-  const { result } = response.data;
   const dash = new DashboardModel({
     ...defaultDashboard,
     schemaVersion: 35, // should be saved in the library panel
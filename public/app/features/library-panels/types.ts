@@ -23,6 +23,19 @@ export interface LibraryElementConnectionDTO {
   elementId: number;
   connectionId: number;
   connectionUid: string;
+  elementVersion: number;
+  created: string;
+  createdBy: LibraryElementDTOMetaUser;
+}
+
+export interface LibraryElementVersionDTO {
+  id: number;
+  elementId: number;
+  uid: string;
+  version: number;
+  name: string;
+  description: string;
+  model: LibraryPanel['model'];
   created: string;
   createdBy: LibraryElementDTOMetaUser;
 }
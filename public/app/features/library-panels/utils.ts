@@ -30,6 +30,8 @@ function updatePanelModelWithUpdate(panel: PanelModel, updated: LibraryElementDT
     libraryPanel: updated,
     title: panel.title,
   });
+  // the dashboard follows the library panel it just saved
+  panel.libraryPanelVersion = undefined;
   panel.hasSavedPanelEditChange = true;
   panel.refresh();
 }
@@ -143,10 +143,10 @@ export function changeToLibraryPanel(panel: PanelModel, libraryPanel: LibraryEle
 
 export function loadLibraryPanelAndUpdate(panel: PanelModel): ThunkResult<void> {
   return async (dispatch, getStore) => {
-    const uid = panel.libraryPanel!.uid!;
+    const { uid, version } = panel.libraryPanel!;
     try {
-      const libPanel = await getLibraryPanel(uid, true);
-      panel.initLibraryPanel(libPanel);
+      const libPanel = await getLibraryPanel(uid!, true, version);
+      panel.initLibraryPanel(libPanel, version);
       await dispatch(initPanelState(panel));
       const dashboard = getStore().dashboard.getModel();
 
@@ -5,31 +5,29 @@ import configureMockStore from 'redux-mock-store';
 import { locationService } from '@grafana/runtime';
 import { setStore } from 'app/store/store';
 
-import { DashboardQueryResult } from '../search/service';
-
 import { PlaylistSrv } from './PlaylistSrv';
-import { Playlist, PlaylistItem } from './types';
+import { CurrentPlaylistItem } from './types';
+
+const dashboards = ['aaa', 'bbb'];
+
+// the playlist plays the dashboards in a loop, skipped dashboards are returned for offsets
+let currentIndex = 0;
+const mockGetCurrentPlaylistItem = jest.fn((uid: string, offset = 0): Promise<CurrentPlaylistItem> => {
+  const index = (((currentIndex + offset) % dashboards.length) + dashboards.length) % dashboards.length;
+  return Promise.resolve({
+    playlistUid: uid,
+    index,
+    count: dashboards.length,
+    dashboardUid: dashboards[index],
+    title: dashboards[index],
+    url: `/url/to/${dashboards[index]}?var-host=a&var-host=b`,
+    startedAt: Date.now(),
+    endsAt: Date.now() + 1000,
+  });
+});
 
 jest.mock('./api', () => ({
-  getPlaylistAPI: () => ({
-    getPlaylist: jest.fn().mockReturnValue({
-      interval: '1s',
-      uid: 'xyz',
-      name: 'The display',
-      items: [
-        { type: 'dashboard_by_uid', value: 'aaa' },
-        { type: 'dashboard_by_uid', value: 'bbb' },
-      ],
-    } as Playlist),
-  }),
-  loadDashboards: (items: PlaylistItem[]) => {
-    return Promise.resolve(
-      items.map((v) => ({
-        ...v, // same item with dashboard URLs filled in
-        dashboards: [{ url: `/url/to/${v.value}` } as unknown as DashboardQueryResult],
-      }))
-    );
-  },
+  getCurrentPlaylistItem: (uid: string, offset?: number) => mockGetCurrentPlaylistItem(uid, offset),
 }));
 
 const mockStore = configureMockStore();
@@ -74,8 +72,16 @@ describe('PlaylistSrv', () => {
   let unmockLocation: () => void;
   const initialUrl = 'http://localhost/playlist';
 
+  // plays the next dashboard, as every screen does when the display of the current one ends
+  const playNextDashboard = async () => {
+    currentIndex++;
+    // eslint-disable-next-line
+    await (srv as any).sync();
+  };
+
   beforeEach(() => {
     jest.clearAllMocks();
+    currentIndex = 0;
 
     srv = createPlaylistSrv();
     [hrefMock, unmockLocation] = mockWindowLocation();
@@ -85,14 +91,23 @@ describe('PlaylistSrv', () => {
   });
 
   afterEach(() => {
+    srv.stop();
     unmockLocation();
   });
 
+  it('plays the current dashboard of the playlist with the variables of its item', async () => {
+    await srv.start('foo');
+
+    expect(mockGetCurrentPlaylistItem).toHaveBeenCalledWith('foo', undefined);
+    expect(locationService.getLocation().pathname).toBe('/url/to/aaa');
+    expect(locationService.getSearchObject()['var-host']).toEqual(['a', 'b']);
+  });
+
   it('runs all dashboards in cycle and reloads page after 3 cycles', async () => {
     await srv.start('foo');
 
     for (let i = 0; i < 6; i++) {
-      srv.next();
+      await playNextDashboard();
     }
 
     expect(hrefMock).toHaveBeenCalledTimes(2);
@@ -103,8 +118,8 @@ describe('PlaylistSrv', () => {
     await srv.start('foo');
 
     // 1 complete loop
-    for (let i = 0; i < 3; i++) {
-      srv.next();
+    for (let i = 0; i < 2; i++) {
+      await playNextDashboard();
     }
 
     srv.stop();
@@ -112,13 +127,29 @@ describe('PlaylistSrv', () => {
 
     // Another 2 loops
     for (let i = 0; i < 4; i++) {
-      srv.next();
+      await playNextDashboard();
     }
 
     expect(hrefMock).toHaveBeenCalledTimes(3);
     expect(hrefMock).toHaveBeenLastCalledWith(initialUrl);
   });
 
+  it('skips dashboards until the current one ends', async () => {
+    await srv.start('foo');
+
+    await srv.next();
+    expect(mockGetCurrentPlaylistItem).toHaveBeenLastCalledWith('foo', 1);
+    expect(locationService.getLocation().pathname).toBe('/url/to/bbb');
+
+    await playNextDashboard();
+    expect(mockGetCurrentPlaylistItem).toHaveBeenLastCalledWith('foo', undefined);
+    expect(locationService.getLocation().pathname).toBe('/url/to/bbb');
+
+    await srv.prev();
+    expect(mockGetCurrentPlaylistItem).toHaveBeenLastCalledWith('foo', -1);
+    expect(locationService.getLocation().pathname).toBe('/url/to/aaa');
+  });
+
   it('Should stop playlist when navigating away', async () => {
     await srv.start('foo');
 
@@ -133,7 +164,7 @@ describe('PlaylistSrv', () => {
     // eslint-disable-next-line
     expect((srv as any).validPlaylistUrl).toBe('/url/to/aaa');
 
-    srv.next();
+    await playNextDashboard();
 
     // eslint-disable-next-line
     expect((srv as any).validPlaylistUrl).toBe('/url/to/bbb');
@@ -1,11 +1,11 @@
 import { Location } from 'history';
 import { pickBy } from 'lodash';
 
-import { locationUtil, urlUtil, rangeUtil } from '@grafana/data';
+import { locationUtil, urlUtil } from '@grafana/data';
 import { locationService } from '@grafana/runtime';
 
-import { getPlaylistAPI, loadDashboards } from './api';
-import { PlaylistAPI } from './types';
+import { getCurrentPlaylistItem } from './api';
+import { CurrentPlaylistItem } from './types';
 
 export const queryParamsToPreserve: { [key: string]: boolean } = {
   kiosk: true,
@@ -13,55 +13,39 @@ export const queryParamsToPreserve: { [key: string]: boolean } = {
   orgId: true,
 };
 
+// Minimum time between two requests of the current dashboard, in milliseconds
+const minSyncInterval = 1000;
+// Time before requesting the current dashboard again after an error, in milliseconds
+const retrySyncInterval = 10000;
+
 export class PlaylistSrv {
   private nextTimeoutId: ReturnType<typeof setTimeout> | undefined;
-  private urls: string[] = []; // the URLs we need to load
-  private index = 0;
-  private declare interval: number;
+  private declare playlistUid: string;
   private declare startUrl: string;
   private numberOfLoops = 0;
+  // index of the dashboard played by every screen, to count the loops
+  private index = -1;
+  // dashboards skipped from the one played by every screen
+  private offset = 0;
   private declare validPlaylistUrl: string;
   private locationListenerUnsub?: () => void;
-  private api: PlaylistAPI;
 
   isPlaying = false;
 
   constructor() {
     this.locationUpdated = this.locationUpdated.bind(this);
-    this.api = getPlaylistAPI();
   }
 
-  next() {
-    clearTimeout(this.nextTimeoutId);
-
-    const playedAllDashboards = this.index > this.urls.length - 1;
-    if (playedAllDashboards) {
-      this.numberOfLoops++;
-
-      // This does full reload of the playlist to keep memory in check due to existing leaks but at the same time
-      // we do not want page to flicker after each full loop.
-      if (this.numberOfLoops >= 3) {
-        window.location.href = this.startUrl;
-        return;
-      }
-      this.index = 0;
-    }
-
-    const url = this.urls[this.index];
-    const queryParams = locationService.getSearchObject();
-    const filteredParams = pickBy(queryParams, (value: unknown, key: string) => queryParamsToPreserve[key]);
-    const nextDashboardUrl = locationUtil.stripBaseFromUrl(url);
-
-    this.index++;
-    this.validPlaylistUrl = nextDashboardUrl;
-    this.nextTimeoutId = setTimeout(() => this.next(), this.interval);
-
-    locationService.push(nextDashboardUrl + '?' + urlUtil.toUrlParams(filteredParams));
+  // Skips to the next dashboard until the current one ends on every screen
+  async next() {
+    this.offset++;
+    await this.skip();
   }
 
-  prev() {
-    this.index = Math.max(this.index - 2, 0);
-    this.next();
+  // Skips to the previous dashboard until the current one ends on every screen
+  async prev() {
+    this.offset--;
+    await this.skip();
   }
 
   // Detect url changes not caused by playlist srv and stop playlist
@@ -75,37 +59,14 @@ export class PlaylistSrv {
     this.stop();
 
     this.startUrl = window.location.href;
-    this.index = 0;
+    this.playlistUid = playlistUid;
+    this.index = -1;
     this.isPlaying = true;
 
     // setup location tracking
     this.locationListenerUnsub = locationService.getHistory().listen(this.locationUpdated);
 
-    const urls: string[] = [];
-    let playlist = await this.api.getPlaylist(playlistUid);
-    if (!playlist.items?.length) {
-      // alert
-      return;
-    }
-    this.interval = rangeUtil.intervalToMs(playlist.interval);
-
-    const items = await loadDashboards(playlist.items);
-    for (const item of items) {
-      if (item.dashboards) {
-        for (const dash of item.dashboards) {
-          urls.push(dash.url);
-        }
-      }
-    }
-
-    if (!urls.length) {
-      // alert... not found, etc
-      return;
-    }
-    this.urls = urls;
-    this.isPlaying = true;
-    this.next();
-    return;
+    await this.sync();
   }
 
   stop() {
@@ -113,7 +74,8 @@ export class PlaylistSrv {
       return;
     }
 
-    this.index = 0;
+    this.index = -1;
+    this.offset = 0;
     this.isPlaying = false;
 
     if (this.locationListenerUnsub) {
@@ -128,6 +90,59 @@ export class PlaylistSrv {
       locationService.partial({ kiosk: null });
     }
   }
+
+  // Displays the dashboard displayed by every screen playing the playlist, which is computed by the server,
+  // and requests it again when its display ends.
+  private async sync() {
+    clearTimeout(this.nextTimeoutId);
+    this.offset = 0;
+
+    let current: CurrentPlaylistItem;
+    try {
+      current = await getCurrentPlaylistItem(this.playlistUid);
+    } catch (err) {
+      if (this.isPlaying) {
+        this.nextTimeoutId = setTimeout(() => this.sync(), retrySyncInterval);
+      }
+      return;
+    }
+    if (!this.isPlaying) {
+      return;
+    }
+
+    const looped = this.index >= 0 && current.index <= this.index;
+    this.index = current.index;
+    if (looped) {
+      this.numberOfLoops++;
+
+      // This does full reload of the playlist to keep memory in check due to existing leaks but at the same time
+      // we do not want page to flicker after each full loop.
+      if (this.numberOfLoops >= 3) {
+        window.location.href = this.startUrl;
+        return;
+      }
+    }
+
+    this.show(current);
+    this.nextTimeoutId = setTimeout(() => this.sync(), Math.max(current.endsAt - Date.now(), minSyncInterval));
+  }
+
+  private async skip() {
+    const current = await getCurrentPlaylistItem(this.playlistUid, this.offset);
+    if (this.isPlaying) {
+      this.show(current);
+    }
+  }
+
+  private show(current: CurrentPlaylistItem) {
+    // the url of the dashboard includes the variables of the playlist item
+    const [path, query] = locationUtil.stripBaseFromUrl(current.url).split('?');
+    const queryParams = locationService.getSearchObject();
+    const filteredParams = pickBy(queryParams, (value: unknown, key: string) => queryParamsToPreserve[key]);
+
+    this.validPlaylistUrl = path;
+    locationService.push(urlUtil.renderUrl(path, { ...urlUtil.parseKeyValue(query ?? ''), ...filteredParams }));
+  }
 }
 
 export const playlistSrv = new PlaylistSrv();
@@ -11,7 +11,7 @@ import { dispatch } from 'app/store/store';
 
 import { DashboardQueryResult, getGrafanaSearcher, SearchQuery } from '../search/service';
 
-import { Playlist, PlaylistItem, PlaylistAPI } from './types';
+import { CurrentPlaylistItem, Playlist, PlaylistItem, PlaylistAPI, PlaylistSchedule } from './types';
 
 class LegacyAPI implements PlaylistAPI {
   async getAllPlaylist(): Promise<Playlist[]> {
@@ -51,6 +51,7 @@ interface K8sPlaylist {
     title: string;
     interval: string;
     items: PlaylistItem[];
+    schedule?: PlaylistSchedule;
   };
 }
 
@@ -100,6 +101,7 @@ class K8sAPI implements PlaylistAPI {
         title: playlist.name, // name becomes title
         interval: playlist.interval,
         items: playlist.items ?? [],
+        schedule: playlist.schedule,
       },
     };
   };
@@ -115,6 +117,7 @@ function k8sResourceAsPlaylist(r: K8sPlaylist): Playlist {
     name: spec.title,
     interval: spec.interval,
     items: spec.items,
+    schedule: spec.schedule,
   };
 }
 
@@ -202,6 +205,11 @@ export async function loadDashboards(items: PlaylistItem[]): Promise<PlaylistIte
   });
 }
 
+/** Returns the dashboard every screen playing the playlist displays now, or the one offset dashboards from it */
+export function getCurrentPlaylistItem(uid: string, offset = 0): Promise<CurrentPlaylistItem> {
+  return getBackendSrv().get<CurrentPlaylistItem>(`/api/playlists/${uid}/current`, offset ? { offset } : undefined);
+}
+
 export function getDefaultPlaylist(): Playlist {
   return { items: [], interval: '5m', name: '', uid: '' };
 }
@@ -31,6 +31,11 @@ export interface Playlist {
    * The ordered list of items that the playlist will iterate over.
    */
   items?: PlaylistItem[];
+
+  /**
+   * Schedule plays other playlists at given times of day.
+   */
+  schedule?: PlaylistSchedule;
 }
 
 export interface PlaylistItem {
@@ -56,6 +61,21 @@ export interface PlaylistItem {
    */
   value: string;
 
+  /**
+   * Interval overrides the interval of the playlist for the dashboards of the item.
+   */
+  interval?: string;
+
+  /**
+   * Variables overrides the template variables of the dashboards of the item, by variable name.
+   */
+  variables?: Record<string, string[]>;
+
+  /**
+   * Sort orders the dashboards of dashboard_by_tag items, using the sort options of the search.
+   */
+  sort?: string;
+
   /**
    * Loaded at runtime by the frontend.
    *
@@ -63,3 +83,61 @@ export interface PlaylistItem {
    */
   dashboards?: DashboardQueryResult[];
 }
+
+export interface PlaylistSchedule {
+  /**
+   * Timezone of the entries, as an IANA time zone name. UTC by default.
+   */
+  timezone?: string;
+
+  /**
+   * Entries of the schedule. The first entry matching is played.
+   */
+  entries: PlaylistScheduleEntry[];
+}
+
+export interface PlaylistScheduleEntry {
+  /**
+   * Start time of day, formatted as HH:MM.
+   */
+  start: string;
+
+  /**
+   * End time of day, formatted as HH:MM. Entries ending before they start span midnight.
+   */
+  end: string;
+
+  /**
+   * Weekdays the entry starts on, 0 being Sunday. Every day by default.
+   */
+  weekdays?: number[];
+
+  /**
+   * The playlist played during the entry.
+   */
+  playlistUid: string;
+}
+
+/**
+ * The dashboard every screen playing a playlist displays at a given time.
+ */
+export interface CurrentPlaylistItem {
+  /**
+   * The playlist played, which differs from the requested playlist when scheduled by it.
+   */
+  playlistUid: string;
+  index: number;
+  count: number;
+  dashboardUid: string;
+  title: string;
+  /**
+   * URL of the dashboard, with the variables of the item.
+   */
+  url: string;
+  variables?: Record<string, string[]>;
+  /**
+   * Start and end of the display of the dashboard, in milliseconds.
+   */
+  startedAt: number;
+  endsAt: number;
+}
@@ -126,6 +126,7 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
       datasource: this.getRef(),
       rawSql: this.templateSrv.replace(target.rawSql, scopedVars, this.interpolateVariable),
       format: target.format,
+      timezone: target.timezone,
     };
   }
 
@@ -153,7 +154,8 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
       });
     });
 
-    return super.query(request);
+    const timezone = resolveTimezone(request.timezone);
+    return super.query({ ...request, targets: request.targets.map((target) => ({ ...target, timezone })) });
   }
 
   private checkForDatabaseIssue(request: DataQueryRequest<SQLQuery>) {
@@ -261,3 +263,11 @@ export abstract class SqlDatasource extends DataSourceWithBackend<SQLQuery, SQLO
 interface RunSQLOptions extends LegacyMetricFindQueryOptions {
   refId?: string;
 }
+
+// The backend cannot resolve the browser timezone, send the IANA name instead.
+function resolveTimezone(timezone?: string): string | undefined {
+  if (!timezone || timezone === 'browser') {
+    return Intl.DateTimeFormat().resolvedOptions().timeZone;
+  }
+  return timezone;
+}
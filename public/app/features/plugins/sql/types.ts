@@ -59,6 +59,8 @@ export interface SQLQuery extends DataQuery {
   sql?: SQLExpression;
   editorMode?: EditorMode;
   rawQuery?: boolean;
+  // Timezone of the dashboard, used by the $__timezone and $__timeBucket macros.
+  timezone?: string;
 }
 
 export interface NameValue {
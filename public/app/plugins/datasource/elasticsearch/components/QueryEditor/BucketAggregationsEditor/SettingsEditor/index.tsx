@@ -51,6 +51,47 @@ export const SettingsEditor = ({ bucketAgg }: Props) => {
         </InlineField>
       )}
 
+      {bucketAgg.type === 'composite' && (
+        <>
+          <InlineField
+            label="Fields"
+            tooltip="Comma separated fields grouped in addition to the field of the aggregation"
+            {...inlineFieldProps}
+          >
+            <Input
+              id={`${baseId}-composite-fields`}
+              onBlur={(e) =>
+                dispatch(
+                  changeBucketAggregationSetting({
+                    bucketAgg,
+                    settingName: 'fields',
+                    newValue: e.target.value
+                      .split(',')
+                      .map((field) => field.trim())
+                      .filter((field) => field !== ''),
+                  })
+                )
+              }
+              defaultValue={bucketAgg.settings?.fields?.join(', ')}
+            />
+          </InlineField>
+
+          <InlineField
+            label="Page size"
+            tooltip="Number of buckets requested at a time, all pages are requested"
+            {...inlineFieldProps}
+          >
+            <Input
+              id={`${baseId}-composite-size`}
+              onBlur={(e) =>
+                dispatch(changeBucketAggregationSetting({ bucketAgg, settingName: 'size', newValue: e.target.value }))
+              }
+              defaultValue={bucketAgg.settings?.size || bucketAggregationConfig[bucketAgg.type].defaultSettings?.size}
+            />
+          </InlineField>
+        </>
+      )}
+
       {bucketAgg.type === 'histogram' && (
         <>
           <InlineField label="Interval" {...inlineFieldProps}>
@@ -86,6 +86,13 @@ export const useDescription = (bucketAgg: BucketAggregation): string => {
       return description;
     }
 
+    case 'composite': {
+      const size = bucketAgg.settings?.size || bucketAggregationConfig['composite'].defaultSettings?.size;
+      const fields = bucketAgg.settings?.fields?.filter((field) => field !== '') || [];
+
+      return `Page size: ${size}${fields.length > 0 ? `, Fields: ${fields.join(', ')}` : ''}`;
+    }
+
     default:
       return 'Settings';
   }
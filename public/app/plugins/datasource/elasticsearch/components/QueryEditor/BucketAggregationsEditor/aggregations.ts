@@ -13,6 +13,7 @@ export const BUCKET_AGGREGATION_TYPES: BucketAggregationType[] = [
   'filters',
   'geohash_grid',
   'nested',
+  'composite',
 ];
 
 export const isBucketAggregationType = (s: BucketAggregationType | string): s is BucketAggregationType =>
@@ -53,6 +53,14 @@ export const bucketAggregationConfig: BucketsConfiguration = {
     requiresField: true,
     defaultSettings: {},
   },
+  composite: {
+    label: 'Composite',
+    requiresField: true,
+    defaultSettings: {
+      fields: [],
+      size: '500',
+    },
+  },
 };
 
 export const orderByOptions: Array<SelectableValue<string>> = [
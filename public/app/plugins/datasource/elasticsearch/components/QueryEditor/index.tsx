@@ -2,13 +2,14 @@ import { css } from '@emotion/css';
 import React, { useEffect, useId, useState } from 'react';
 import { SemVer } from 'semver';
 
-import { getDefaultTimeRange, GrafanaTheme2, QueryEditorProps } from '@grafana/data';
-import { Alert, InlineField, InlineLabel, Input, QueryField, useStyles2 } from '@grafana/ui';
+import { getDefaultTimeRange, GrafanaTheme2, QueryEditorProps, SelectableValue } from '@grafana/data';
+import { config } from '@grafana/runtime';
+import { Alert, InlineField, InlineLabel, Input, QueryField, RadioButtonGroup, TextArea, useStyles2 } from '@grafana/ui';
 
 import { ElasticDatasource } from '../../datasource';
 import { useNextId } from '../../hooks/useNextId';
 import { useDispatch } from '../../hooks/useStatelessReducer';
-import { ElasticsearchOptions, ElasticsearchQuery } from '../../types';
+import { ElasticsearchOptions, ElasticsearchQuery, QueryLanguage } from '../../types';
 import { isSupportedVersion, isTimeSeriesQuery, unsupportedVersionMessage } from '../../utils';
 
 import { BucketAggregationsEditor } from './BucketAggregationsEditor';
@@ -16,7 +17,7 @@ import { ElasticsearchProvider } from './ElasticsearchQueryContext';
 import { MetricAggregationsEditor } from './MetricAggregationsEditor';
 import { metricAggregationConfig } from './MetricAggregationsEditor/utils';
 import { QueryTypeSelector } from './QueryTypeSelector';
-import { changeAliasPattern, changeQuery } from './state';
+import { changeAliasPattern, changeQuery, changeQueryLanguage } from './state';
 
 export type ElasticQueryEditorProps = QueryEditorProps<ElasticDatasource, ElasticsearchQuery, ElasticsearchOptions>;
 
@@ -87,12 +88,66 @@ export const ElasticSearchQueryField = ({ value, onChange }: { value?: string; o
   );
 };
 
+const queryLanguageOptions: Array<SelectableValue<QueryLanguage>> = [
+  { value: 'lucene', label: 'Lucene' },
+  { value: 'sql', label: 'SQL', description: 'Elasticsearch SQL, query the index of the data source with $__index' },
+  { value: 'ppl', label: 'PPL', description: 'OpenSearch PPL, queries must start with source=$__index' },
+];
+
+const rawQueryPlaceholders: Partial<Record<QueryLanguage, string>> = {
+  sql: 'SELECT * FROM $__index WHERE $__timeFilter("@timestamp")',
+  ppl: 'source=$__index | where @timestamp >= $__timeFrom() | stats count() by host',
+};
+
+const QueryLanguageSelector = ({ value }: { value: QueryLanguage }) => {
+  const dispatch = useDispatch();
+  const styles = useStyles2(getStyles);
+
+  return (
+    <div className={styles.root}>
+      <InlineLabel width={17}>Query language</InlineLabel>
+      <div className={styles.queryItem}>
+        <RadioButtonGroup<QueryLanguage>
+          options={queryLanguageOptions}
+          value={value}
+          onChange={(language) => dispatch(changeQueryLanguage(language))}
+        />
+      </div>
+    </div>
+  );
+};
+
 const QueryEditorForm = ({ value }: Props) => {
   const dispatch = useDispatch();
   const nextId = useNextId();
   const inputId = useId();
   const styles = useStyles2(getStyles);
 
+  // SQL and PPL queries only run in the backend
+  const showQueryLanguage = config.featureToggles.enableElasticsearchBackendQuerying;
+  const queryLanguage = (showQueryLanguage && value.queryLanguage) || 'lucene';
+
+  if (queryLanguage !== 'lucene') {
+    return (
+      <>
+        <QueryLanguageSelector value={queryLanguage} />
+        <div className={styles.root}>
+          <InlineLabel width={17}>{queryLanguage === 'sql' ? 'SQL Query' : 'PPL Query'}</InlineLabel>
+          <div className={styles.queryItem}>
+            <TextArea
+              key={queryLanguage}
+              aria-label="Raw query"
+              rows={4}
+              placeholder={rawQueryPlaceholders[queryLanguage]}
+              defaultValue={value.query}
+              onBlur={(e) => dispatch(changeQuery(e.currentTarget.value))}
+            />
+          </div>
+        </div>
+      </>
+    );
+  }
+
   const isTimeSeries = isTimeSeriesQuery(value);
 
   const showBucketAggregationsEditor = value.metrics?.every(
@@ -101,6 +156,7 @@ const QueryEditorForm = ({ value }: Props) => {
 
   return (
     <>
+      {showQueryLanguage && <QueryLanguageSelector value={queryLanguage} />}
       <div className={styles.root}>
         <InlineLabel width={17}>Query type</InlineLabel>
         <div className={styles.queryItem}>
@@ -2,7 +2,15 @@ import { reducerTester } from 'test/core/redux/reducerTester';
 
 import { ElasticsearchQuery } from '../../types';
 
-import { aliasPatternReducer, changeAliasPattern, changeQuery, initQuery, queryReducer } from './state';
+import {
+  aliasPatternReducer,
+  changeAliasPattern,
+  changeQuery,
+  changeQueryLanguage,
+  initQuery,
+  queryLanguageReducer,
+  queryReducer,
+} from './state';
 
 describe('Query Reducer', () => {
   describe('On Init', () => {
@@ -35,6 +43,13 @@ describe('Query Reducer', () => {
       .thenStateShouldEqual(expectedQuery);
   });
 
+  it('Should reset `query` when the query language changes', () => {
+    reducerTester<ElasticsearchQuery['query']>()
+      .givenReducer(queryReducer, 'Some lucene query')
+      .whenActionIsDispatched(changeQueryLanguage('sql'))
+      .thenStateShouldEqual('');
+  });
+
   it('Should not change state with other action types', () => {
     const initialState: ElasticsearchQuery['query'] = 'Some lucene query';
 
@@ -64,3 +79,19 @@ describe('Alias Pattern Reducer', () => {
       .thenStateShouldEqual(initialState);
   });
 });
+
+describe('Query Language Reducer', () => {
+  it('Should correctly set `queryLanguage`', () => {
+    reducerTester<ElasticsearchQuery['queryLanguage']>()
+      .givenReducer(queryLanguageReducer, undefined)
+      .whenActionIsDispatched(changeQueryLanguage('ppl'))
+      .thenStateShouldEqual('ppl');
+  });
+
+  it('Should unset `queryLanguage` for lucene', () => {
+    reducerTester<ElasticsearchQuery['queryLanguage']>()
+      .givenReducer(queryLanguageReducer, 'sql')
+      .whenActionIsDispatched(changeQueryLanguage('lucene'))
+      .thenStateShouldEqual(undefined);
+  });
+});
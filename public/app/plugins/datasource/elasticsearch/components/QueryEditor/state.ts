@@ -12,11 +12,18 @@ export const changeQuery = createAction<ElasticsearchQuery['query']>('change_que
 
 export const changeAliasPattern = createAction<ElasticsearchQuery['alias']>('change_alias_pattern');
 
+export const changeQueryLanguage = createAction<ElasticsearchQuery['queryLanguage']>('change_query_language');
+
 export const queryReducer = (prevQuery: ElasticsearchQuery['query'], action: Action) => {
   if (changeQuery.match(action)) {
     return action.payload;
   }
 
+  // Queries of a language are not valid in the other ones
+  if (changeQueryLanguage.match(action)) {
+    return '';
+  }
+
   if (initQuery.match(action)) {
     return prevQuery || '';
   }
@@ -35,3 +42,14 @@ export const aliasPatternReducer = (prevAliasPattern: ElasticsearchQuery['alias'
 
   return prevAliasPattern;
 };
+
+export const queryLanguageReducer = (
+  prevQueryLanguage: ElasticsearchQuery['queryLanguage'],
+  action: Action
+): ElasticsearchQuery['queryLanguage'] => {
+  if (changeQueryLanguage.match(action)) {
+    return action.payload === 'lucene' ? undefined : action.payload;
+  }
+
+  return prevQueryLanguage;
+};
@@ -29,8 +29,10 @@ composableKinds: DataQuery: {
 
 				// Alias pattern
 				alias?: string
-				// Lucene query
+				// Lucene query, or raw query in the query language
 				query?: string
+				// Language of the query, the query editor model is used for Lucene queries
+				queryLanguage?: #QueryLanguage
 				// Name of time field
 				timeField?: string
 				// List of bucket aggregations
@@ -38,10 +40,12 @@ composableKinds: DataQuery: {
 				// List of metric aggregations
 				metrics?: [...#MetricAggregation]
 
-				#BucketAggregation: #DateHistogram | #Histogram | #Terms | #Filters | #GeoHashGrid | #Nested @cuetsy(kind="type")
+				#BucketAggregation: #DateHistogram | #Histogram | #Terms | #Filters | #GeoHashGrid | #Nested | #Composite @cuetsy(kind="type")
 				#MetricAggregation: #Count | #PipelineMetricAggregation | #MetricAggregationWithSettings     @cuetsy(kind="type")
 
-				#BucketAggregationType: "terms" | "filters" | "geohash_grid" | "date_histogram" | "histogram" | "nested" @cuetsy(kind="type")
+				#QueryLanguage: "lucene" | "sql" | "ppl" @cuetsy(kind="type")
+
+				#BucketAggregationType: "terms" | "filters" | "geohash_grid" | "date_histogram" | "histogram" | "nested" | "composite" @cuetsy(kind="type")
 
 				#BaseBucketAggregation: {
 					id:        string
@@ -126,6 +130,19 @@ composableKinds: DataQuery: {
 					precision?: string
 				} @cuetsy(kind="interface")
 
+				#Composite: {
+					#BucketAggregationWithField
+					type:      #BucketAggregationType & "composite"
+					settings?: #CompositeSettings
+				} @cuetsy(kind="interface")
+
+				#CompositeSettings: {
+					// Fields of the terms sources of the composite aggregation
+					fields?: [...string]
+					// Number of buckets requested at a time, all pages are requested
+					size?: string
+				} @cuetsy(kind="interface")
+
 				#PipelineMetricAggregationType: "moving_avg" | "moving_fn" | "derivative" | "serial_diff" | "cumulative_sum" | "bucket_script"                                                                                              @cuetsy(kind="type")
 				#MetricAggregationType:         "count" | "avg" | "sum" | "min" | "max" | "extended_stats" | "percentiles" | "cardinality" | "raw_document" | "raw_data" | "logs" | "rate" | "top_metrics" | #PipelineMetricAggregationType @cuetsy(kind="type")
 
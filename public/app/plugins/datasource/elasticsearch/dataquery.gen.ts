@@ -10,11 +10,13 @@
 
 import * as common from '@grafana/schema';
 
-export type BucketAggregation = (DateHistogram | Histogram | Terms | Filters | GeoHashGrid | Nested);
+export type BucketAggregation = (DateHistogram | Histogram | Terms | Filters | GeoHashGrid | Nested | Composite);
 
 export type MetricAggregation = (Count | PipelineMetricAggregation | MetricAggregationWithSettings);
 
-export type BucketAggregationType = ('terms' | 'filters' | 'geohash_grid' | 'date_histogram' | 'histogram' | 'nested');
+export type QueryLanguage = ('lucene' | 'sql' | 'ppl');
+
+export type BucketAggregationType = ('terms' | 'filters' | 'geohash_grid' | 'date_histogram' | 'histogram' | 'nested' | 'composite');
 
 export interface BaseBucketAggregation {
   id: string;
@@ -115,6 +117,35 @@ export interface GeoHashGridSettings {
   precision?: string;
 }
 
+export interface Composite extends BucketAggregationWithField {
+  settings?: {
+    /**
+     * Fields of the terms sources of the composite aggregation
+     */
+    fields?: Array<string>;
+    /**
+     * Number of buckets requested at a time, all pages are requested
+     */
+    size?: string;
+  };
+  type: 'composite';
+}
+
+export interface CompositeSettings {
+  /**
+   * Fields of the terms sources of the composite aggregation
+   */
+  fields?: Array<string>;
+  /**
+   * Number of buckets requested at a time, all pages are requested
+   */
+  size?: string;
+}
+
+export const defaultCompositeSettings: Partial<CompositeSettings> = {
+  fields: [],
+};
+
 export type PipelineMetricAggregationType = ('moving_avg' | 'moving_fn' | 'derivative' | 'serial_diff' | 'cumulative_sum' | 'bucket_script');
 
 export type MetricAggregationType = ('count' | 'avg' | 'sum' | 'min' | 'max' | 'extended_stats' | 'percentiles' | 'cardinality' | 'raw_document' | 'raw_data' | 'logs' | 'rate' | 'top_metrics' | PipelineMetricAggregationType);
@@ -390,9 +421,13 @@ export interface Elasticsearch extends common.DataQuery {
    */
   metrics?: Array<MetricAggregation>;
   /**
-   * Lucene query
+   * Lucene query, or raw query in the query language
    */
   query?: string;
+  /**
+   * Language of the query, the query editor model is used for Lucene queries
+   */
+  queryLanguage?: QueryLanguage;
   /**
    * Name of time field
    */
@@ -966,6 +966,16 @@ export class ElasticDatasource
     scopedVars: ScopedVars,
     filters?: AdHocVariableFilter[]
   ): ElasticsearchQuery {
+    // SQL and PPL queries are not lucene queries, variables are interpolated as SQL strings and ad hoc
+    // filters can't be added to them
+    if (query.queryLanguage === 'sql' || query.queryLanguage === 'ppl') {
+      return {
+        ...query,
+        datasource: this.getRef(),
+        query: this.templateSrv.replace(query.query || '', scopedVars, 'sqlstring'),
+      };
+    }
+
     // We need a separate interpolation format for lucene queries, therefore we first interpolate any
     // lucene query string and then everything else
     const interpolateBucketAgg = (bucketAgg: BucketAggregation): BucketAggregation => {
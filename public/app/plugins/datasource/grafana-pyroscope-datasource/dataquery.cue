@@ -38,8 +38,28 @@ composableKinds: DataQuery: {
 				// Allows to group the results.
 				groupBy: [...string]
 				// Sets the maximum number of nodes in the flamegraph.
-				maxNodes?:           int64
-				#PyroscopeQueryType: "metrics" | "profile" | *"both" @cuetsy(kind="type")
+				maxNodes?: int64
+				// Compares two profiles when the query type is diff.
+				diff?:               #ProfileDiff
+				#PyroscopeQueryType: "metrics" | "profile" | "diff" | *"both" @cuetsy(kind="type")
+				#ProfileDiff: {
+					// The baseline profile, shown as the left profile of the diff flame graph. Defaults to the profile of the query.
+					baseline?: #ProfileSelection
+					// The comparison profile, shown as the right profile of the diff flame graph. Defaults to the profile of the query.
+					comparison?: #ProfileSelection
+					// Sets the number of functions with the largest changes in the top table.
+					topN?: int64
+				} @cuetsy(kind="interface")
+				#ProfileSelection: {
+					// Specifies the label selector of the profile. Defaults to the label selector of the query.
+					labelSelector?: string
+					// Shifts the time range of the query back, for example 1d.
+					timeShift?: string
+					// Start of the profile in milliseconds since epoch, overriding the time range of the query.
+					from?: int64
+					// End of the profile in milliseconds since epoch, overriding the time range of the query.
+					to?: int64
+				} @cuetsy(kind="interface")
 			}
 		}]
 		lenses: []
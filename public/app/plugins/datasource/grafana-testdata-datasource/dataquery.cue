@@ -43,6 +43,8 @@ composableKinds: DataQuery: {
 				rawFrameContent?:       string
 				seriesCount?:           int32
 				usa?:                   #USAQuery
+				replay?:                #ReplayQuery
+				faults?:                #FaultInjectionQuery
 				errorType?:             "server_panic" | "frontend_exception" | "frontend_observable"
 				spanCount?:             int32
 				points?: [...[...string | int64]]
@@ -52,7 +54,7 @@ composableKinds: DataQuery: {
 
 				flamegraphDiff?: bool
 
-				#TestDataQueryType: "random_walk" | "slow_query" | "random_walk_with_error" | "random_walk_table" | "exponential_heatmap_bucket_data" | "linear_heatmap_bucket_data" | "no_data_points" | "datapoints_outside_range" | "csv_metric_values" | "predictable_pulse" | "predictable_csv_wave" | "streaming_client" | "simulation" | "usa" | "live" | "grafana_api" | "arrow" | "annotations" | "table_static" | "server_error_500" | "logs" | "node_graph" | "flame_graph" | "raw_frame" | "csv_file" | "csv_content" | "trace" | "manual_entry" | "variables-query" @cuetsy(kind="enum", memberNames="RandomWalk|SlowQuery|RandomWalkWithError|RandomWalkTable|ExponentialHeatmapBucketData|LinearHeatmapBucketData|NoDataPoints|DataPointsOutsideRange|CSVMetricValues|PredictablePulse|PredictableCSVWave|StreamingClient|Simulation|USA|Live|GrafanaAPI|Arrow|Annotations|TableStatic|ServerError500|Logs|NodeGraph|FlameGraph|RawFrame|CSVFile|CSVContent|Trace|ManualEntry|VariablesQuery")
+				#TestDataQueryType: "random_walk" | "slow_query" | "random_walk_with_error" | "random_walk_table" | "exponential_heatmap_bucket_data" | "linear_heatmap_bucket_data" | "no_data_points" | "datapoints_outside_range" | "csv_metric_values" | "predictable_pulse" | "predictable_csv_wave" | "streaming_client" | "simulation" | "usa" | "live" | "grafana_api" | "arrow" | "annotations" | "table_static" | "server_error_500" | "logs" | "node_graph" | "flame_graph" | "raw_frame" | "csv_file" | "csv_content" | "trace" | "manual_entry" | "variables-query" | "replay" | "fault_injection" @cuetsy(kind="enum", memberNames="RandomWalk|SlowQuery|RandomWalkWithError|RandomWalkTable|ExponentialHeatmapBucketData|LinearHeatmapBucketData|NoDataPoints|DataPointsOutsideRange|CSVMetricValues|PredictablePulse|PredictableCSVWave|StreamingClient|Simulation|USA|Live|GrafanaAPI|Arrow|Annotations|TableStatic|ServerError500|Logs|NodeGraph|FlameGraph|RawFrame|CSVFile|CSVContent|Trace|ManualEntry|VariablesQuery|Replay|FaultInjection")
 
 				#StreamingQuery: {
 					type:   "signal" | "logs" | "fetch"
@@ -94,6 +96,33 @@ composableKinds: DataQuery: {
 					states?: [...string]
 				} @cuetsy(kind="interface")
 
+				#ReplayQuery: {
+					// UID of the data source of the recorded query
+					datasourceUid: string
+					// RefID of the recorded query, defaults to the refId of the query
+					refId?: string
+					// Hash of the recorded query, the most recent recording of the refId is used when empty
+					queryHash?: string
+				} @cuetsy(kind="interface")
+
+				#FaultInjectionQuery: {
+					// Start of the script in RFC3339 format, the script repeats from the epoch when empty
+					start?: string
+					steps?: [...#FaultStep]
+				} @cuetsy(kind="interface")
+
+				#FaultStep: {
+					type: "data" | "timeout" | "error" | "partial_error" | "empty" | "no_frames" | "label_churn"
+					// Duration of the step, defaults to 1m
+					duration?: string
+					// Value of the series returned by the step
+					value?: float64
+					// Error message of the step
+					message?: string
+					// Time to wait before a timeout step fails, defaults to 30s
+					timeout?: string
+				} @cuetsy(kind="interface")
+
 				#CSVWave: {
 					timeStep?:  int64
 					name?:      string
@@ -18,6 +18,7 @@ export enum TestDataQueryType {
   CSVMetricValues = 'csv_metric_values',
   DataPointsOutsideRange = 'datapoints_outside_range',
   ExponentialHeatmapBucketData = 'exponential_heatmap_bucket_data',
+  FaultInjection = 'fault_injection',
   FlameGraph = 'flame_graph',
   GrafanaAPI = 'grafana_api',
   LinearHeatmapBucketData = 'linear_heatmap_bucket_data',
@@ -32,6 +33,7 @@ export enum TestDataQueryType {
   RandomWalkTable = 'random_walk_table',
   RandomWalkWithError = 'random_walk_with_error',
   RawFrame = 'raw_frame',
+  Replay = 'replay',
   ServerError500 = 'server_error_500',
   Simulation = 'simulation',
   SlowQuery = 'slow_query',
@@ -87,6 +89,53 @@ export const defaultUSAQuery: Partial<USAQuery> = {
   states: [],
 };
 
+export interface ReplayQuery {
+  /**
+   * UID of the data source of the recorded query
+   */
+  datasourceUid: string;
+  /**
+   * Hash of the recorded query, the most recent recording of the refId is used when empty
+   */
+  queryHash?: string;
+  /**
+   * RefID of the recorded query, defaults to the refId of the query
+   */
+  refId?: string;
+}
+
+export interface FaultInjectionQuery {
+  /**
+   * Start of the script in RFC3339 format, the script repeats from the epoch when empty
+   */
+  start?: string;
+  steps?: Array<FaultStep>;
+}
+
+export const defaultFaultInjectionQuery: Partial<FaultInjectionQuery> = {
+  steps: [],
+};
+
+export interface FaultStep {
+  /**
+   * Duration of the step, defaults to 1m
+   */
+  duration?: string;
+  /**
+   * Error message of the step
+   */
+  message?: string;
+  /**
+   * Time to wait before a timeout step fails, defaults to 30s
+   */
+  timeout?: string;
+  type: ('data' | 'timeout' | 'error' | 'partial_error' | 'empty' | 'no_frames' | 'label_churn');
+  /**
+   * Value of the series returned by the step
+   */
+  value?: number;
+}
+
 export interface CSVWave {
   labels?: string;
   name?: string;
@@ -116,6 +165,7 @@ export interface TestData extends common.DataQuery {
    */
   dropPercent?: number;
   errorType?: ('server_panic' | 'frontend_exception' | 'frontend_observable');
+  faults?: FaultInjectionQuery;
   flamegraphDiff?: boolean;
   labels?: string;
   levelColumn?: boolean;
@@ -124,6 +174,7 @@ export interface TestData extends common.DataQuery {
   points?: Array<Array<(string | number)>>;
   pulseWave?: PulseWaveQuery;
   rawFrameContent?: string;
+  replay?: ReplayQuery;
   scenarioId?: TestDataQueryType;
   seriesCount?: number;
   sim?: SimulationQuery;
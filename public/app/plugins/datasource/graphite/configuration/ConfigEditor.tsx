@@ -25,6 +25,12 @@ const graphiteTypes = Object.entries(GraphiteType).map(([label, value]) => ({
   value,
 }));
 
+const renderFormats = [
+  { label: 'JSON', value: 'json' },
+  { label: 'MessagePack', value: 'msgpack' },
+  { label: 'Pickle', value: 'pickle' },
+];
+
 export type Props = DataSourcePluginOptionsEditorProps<GraphiteOptions>;
 
 type State = {
@@ -103,6 +109,18 @@ export class ConfigEditor extends PureComponent<Props, State> {
               />
             </Field>
           )}
+          <Field
+            label="Render format"
+            description="Format requested from the render API by backend queries, like alerting. MessagePack and Pickle are faster to decode than JSON for large responses."
+          >
+            <Select
+              id="render-format"
+              options={renderFormats}
+              value={renderFormats.find((format) => format.value === (options.jsonData.renderFormat ?? 'json'))}
+              width={16}
+              onChange={onUpdateDatasourceJsonDataOptionSelect(this.props, 'renderFormat')}
+            />
+          </Field>
         </FieldSet>
         <MappingsConfiguration
           mappings={(options.jsonData.importConfiguration?.loki?.mappings || []).map(toString)}
@@ -22,6 +22,7 @@ export interface GraphiteOptions extends DataSourceJsonData {
   graphiteVersion: string;
   graphiteType: GraphiteType;
   rollupIndicatorEnabled?: boolean;
+  renderFormat?: GraphiteRenderFormat;
   importConfiguration: GraphiteQueryImportConfiguration;
 }
 
@@ -30,6 +31,8 @@ export enum GraphiteType {
   Metrictank = 'metrictank',
 }
 
+export type GraphiteRenderFormat = 'json' | 'msgpack' | 'pickle';
+
 export interface MetricTankRequestMeta {
   [key: string]: any;
 }
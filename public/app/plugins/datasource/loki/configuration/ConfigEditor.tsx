@@ -36,6 +36,8 @@ const makeJsonUpdater =
 const setMaxLines = makeJsonUpdater('maxLines');
 const setPredefinedOperations = makeJsonUpdater('predefinedOperations');
 const setDerivedFields = makeJsonUpdater('derivedFields');
+const setQuerySplitDuration = makeJsonUpdater('querySplitDuration');
+const setQuerySplitConcurrency = makeJsonUpdater('querySplitConcurrency');
 
 export const ConfigEditor = (props: Props) => {
   const { options, onOptionsChange } = props;
@@ -83,6 +85,10 @@ export const ConfigEditor = (props: Props) => {
           onMaxLinedChange={(value) => onOptionsChange(setMaxLines(options, value))}
           predefinedOperations={options.jsonData.predefinedOperations || ''}
           onPredefinedOperationsChange={updatePredefinedOperations}
+          querySplitDuration={options.jsonData.querySplitDuration || ''}
+          onQuerySplitDurationChange={(value) => onOptionsChange(setQuerySplitDuration(options, value))}
+          querySplitConcurrency={options.jsonData.querySplitConcurrency}
+          onQuerySplitConcurrencyChange={(value) => onOptionsChange(setQuerySplitConcurrency(options, value))}
         />
         <Divider hideLine />
         <DerivedFields
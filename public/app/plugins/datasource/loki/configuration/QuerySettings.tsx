@@ -10,10 +10,23 @@ type Props = {
   onMaxLinedChange: (value: string) => void;
   predefinedOperations: string;
   onPredefinedOperationsChange: (value: string) => void;
+  querySplitDuration: string;
+  onQuerySplitDurationChange: (value: string) => void;
+  querySplitConcurrency?: number;
+  onQuerySplitConcurrencyChange: (value?: number) => void;
 };
 
 export const QuerySettings = (props: Props) => {
-  const { maxLines, onMaxLinedChange, predefinedOperations, onPredefinedOperationsChange } = props;
+  const {
+    maxLines,
+    onMaxLinedChange,
+    predefinedOperations,
+    onPredefinedOperationsChange,
+    querySplitDuration,
+    onQuerySplitDurationChange,
+    querySplitConcurrency,
+    onQuerySplitConcurrencyChange,
+  } = props;
   return (
     <ConfigSubSection
       title="Queries"
@@ -48,6 +61,47 @@ export const QuerySettings = (props: Props) => {
         />
       </InlineField>
 
+      <InlineField
+        label="Backend query splitting"
+        htmlFor="loki_config_querySplitDuration"
+        labelWidth={22}
+        tooltip={
+          <>
+            Split range queries run in the backend, like alerting rules and public dashboard queries, into chunks of
+            this duration, for example 1d. Leave empty to disable splitting.
+          </>
+        }
+      >
+        <Input
+          id="loki_config_querySplitDuration"
+          value={querySplitDuration}
+          onChange={(event: React.FormEvent<HTMLInputElement>) => onQuerySplitDurationChange(event.currentTarget.value)}
+          width={16}
+          placeholder="1d"
+          spellCheck={false}
+        />
+      </InlineField>
+
+      <InlineField
+        label="Split concurrency"
+        htmlFor="loki_config_querySplitConcurrency"
+        labelWidth={22}
+        tooltip={<>Number of chunks of a split query requested at the same time (default: 4).</>}
+      >
+        <Input
+          type="number"
+          id="loki_config_querySplitConcurrency"
+          value={querySplitConcurrency ?? ''}
+          onChange={(event: React.FormEvent<HTMLInputElement>) => {
+            const value = parseInt(event.currentTarget.value, 10);
+            onQuerySplitConcurrencyChange(isNaN(value) ? undefined : value);
+          }}
+          width={16}
+          placeholder="4"
+          spellCheck={false}
+        />
+      </InlineField>
+
       {config.featureToggles.lokiPredefinedOperations && (
         <InlineFieldRow>
           <InlineField
@@ -4,6 +4,8 @@ export interface OpenTsdbQuery extends DataQuery {
   // migrating to react
   // metrics section
   metric?: string;
+  // queries by time series UIDs instead of metric and tags
+  tsuids?: string[];
   aggregator?: string;
   alias?: string;
 
@@ -32,8 +32,28 @@ composableKinds: DataQuery: {
 				// Specifies the query label selectors.
 				labelSelector: string | *"{}"
 				// Specifies the type of profile to query.
-				profileTypeId:   string
-				#ParcaQueryType: "metrics" | "profile" | *"both" @cuetsy(kind="type")
+				profileTypeId: string
+				// Compares two profiles when the query type is diff.
+				diff?:           #ProfileDiff
+				#ParcaQueryType: "metrics" | "profile" | "diff" | *"both" @cuetsy(kind="type")
+				#ProfileDiff: {
+					// The baseline profile, shown as the left profile of the diff flame graph. Defaults to the profile of the query.
+					baseline?: #ProfileSelection
+					// The comparison profile, shown as the right profile of the diff flame graph. Defaults to the profile of the query.
+					comparison?: #ProfileSelection
+					// Sets the number of functions with the largest changes in the top table.
+					topN?: int64
+				} @cuetsy(kind="interface")
+				#ProfileSelection: {
+					// Specifies the label selector of the profile. Defaults to the label selector of the query.
+					labelSelector?: string
+					// Shifts the time range of the query back, for example 1d.
+					timeShift?: string
+					// Start of the profile in milliseconds since epoch, overriding the time range of the query.
+					from?: int64
+					// End of the profile in milliseconds since epoch, overriding the time range of the query.
+					to?: int64
+				} @cuetsy(kind="interface")
 			}
 		}]
 		lenses: []
@@ -10,11 +10,49 @@
 
 import * as common from '@grafana/schema';
 
-export type ParcaQueryType = ('metrics' | 'profile' | 'both');
+export type ParcaQueryType = ('metrics' | 'profile' | 'diff' | 'both');
 
 export const defaultParcaQueryType: ParcaQueryType = 'both';
 
+export interface ProfileDiff {
+  /**
+   * The baseline profile, shown as the left profile of the diff flame graph. Defaults to the profile of the query.
+   */
+  baseline?: ProfileSelection;
+  /**
+   * The comparison profile, shown as the right profile of the diff flame graph. Defaults to the profile of the query.
+   */
+  comparison?: ProfileSelection;
+  /**
+   * Sets the number of functions with the largest changes in the top table.
+   */
+  topN?: number;
+}
+
+export interface ProfileSelection {
+  /**
+   * Start of the profile in milliseconds since epoch, overriding the time range of the query.
+   */
+  from?: number;
+  /**
+   * Specifies the label selector of the profile. Defaults to the label selector of the query.
+   */
+  labelSelector?: string;
+  /**
+   * Shifts the time range of the query back, for example 1d.
+   */
+  timeShift?: string;
+  /**
+   * End of the profile in milliseconds since epoch, overriding the time range of the query.
+   */
+  to?: number;
+}
+
 export interface Parca extends common.DataQuery {
+  /**
+   * Compares two profiles when the query type is diff.
+   */
+  diff?: ProfileDiff;
   /**
    * Specifies the query label selectors.
    */
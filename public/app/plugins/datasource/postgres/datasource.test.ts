@@ -1,4 +1,4 @@
-import { Observable, of } from 'rxjs';
+import { lastValueFrom, Observable, of } from 'rxjs';
 import { TestScheduler } from 'rxjs/testing';
 
 import {
@@ -189,6 +189,21 @@ describe('PostgreSQLDatasource', () => {
 
       runMarbleTest({ options, marble, values, expectedMarble, expectedValues });
     });
+
+    it('should send the timezone of the request with the queries', async () => {
+      const { ds } = setupTestContext({ results: {} });
+      const options = {
+        range: defaultRange,
+        targets: [{ refId: 'A', rawSql: 'select time, metric from grafana_metric', format: QueryFormat.Timeseries }],
+        timezone: 'Europe/Berlin',
+        app: 'Grafana',
+      } as unknown as DataQueryRequest<SQLQuery>;
+
+      await lastValueFrom(ds.query(options));
+
+      expect(fetchMock).toBeCalledTimes(1);
+      expect(fetchMock.mock.calls[0][0].data.queries[0].timezone).toBe('Europe/Berlin');
+    });
   });
 
   describe('When performing a table query', () => {
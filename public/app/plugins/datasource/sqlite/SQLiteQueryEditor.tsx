@@ -0,0 +1,15 @@
+import React from 'react';
+
+import { QueryEditorProps } from '@grafana/data';
+import { SqlQueryEditor } from 'app/features/plugins/sql/components/QueryEditor';
+import { SQLQuery } from 'app/features/plugins/sql/types';
+
+import { SQLiteDatasource } from './datasource';
+import { SQLiteOptions } from './types';
+
+// A data source is a single database file, there is no dataset to select.
+const queryHeaderProps = { isPostgresInstance: true };
+
+export function SQLiteQueryEditor(props: QueryEditorProps<SQLiteDatasource, SQLQuery, SQLiteOptions>) {
+  return <SqlQueryEditor {...props} queryHeaderProps={queryHeaderProps} />;
+}
@@ -0,0 +1,76 @@
+import React from 'react';
+
+import {
+  DataSourcePluginOptionsEditorProps,
+  onUpdateDatasourceJsonDataOption,
+  SelectableValue,
+  updateDatasourcePluginJsonDataOption,
+} from '@grafana/data';
+import { ConfigSection, DataSourceDescription } from '@grafana/experimental';
+import { Field, Input, RadioButtonGroup } from '@grafana/ui';
+import { ConnectionLimits } from 'app/features/plugins/sql/components/configuration/ConnectionLimits';
+import { Divider } from 'app/features/plugins/sql/components/configuration/Divider';
+
+import { SQLiteDialect, SQLiteOptions } from '../types';
+
+const dialectOptions: Array<SelectableValue<SQLiteDialect>> = [
+  { label: 'SQLite', value: SQLiteDialect.SQLite },
+  { label: 'DuckDB', value: SQLiteDialect.DuckDB },
+];
+
+export const ConfigurationEditor = (props: DataSourcePluginOptionsEditorProps<SQLiteOptions>) => {
+  const { options, onOptionsChange } = props;
+  const jsonData = options.jsonData;
+  const dialect = jsonData.dialect ?? SQLiteDialect.SQLite;
+
+  const WIDTH_LONG = 40;
+
+  return (
+    <>
+      <DataSourceDescription
+        dataSourceName="SQLite"
+        docsLink="https://grafana.com/docs/grafana/latest/datasources/sqlite/"
+        hasRequiredFields={dialect === SQLiteDialect.SQLite}
+      />
+
+      <Divider />
+
+      <ConfigSection
+        title="Database file"
+        description="Files are opened read-only and must be allowed by the allowed_file_paths option of the [sql_datasources] section in the Grafana configuration."
+      >
+        <Field label="Dialect">
+          <RadioButtonGroup
+            options={dialectOptions}
+            value={dialect}
+            onChange={(value) => updateDatasourcePluginJsonDataOption(props, 'dialect', value)}
+          />
+        </Field>
+
+        <Field
+          label="Path"
+          required={dialect === SQLiteDialect.SQLite}
+          description={
+            dialect === SQLiteDialect.DuckDB
+              ? 'Leave empty to query Parquet, CSV or JSON files with an in-memory database.'
+              : undefined
+          }
+        >
+          <Input
+            width={WIDTH_LONG}
+            name="path"
+            value={jsonData.path || ''}
+            placeholder="/var/lib/grafana/data/metrics.db"
+            onChange={onUpdateDatasourceJsonDataOption(props, 'path')}
+          />
+        </Field>
+      </ConfigSection>
+
+      <Divider />
+
+      <ConfigSection title="Additional settings">
+        <ConnectionLimits options={options} onOptionsChange={onOptionsChange} />
+      </ConfigSection>
+    </>
+  );
+};
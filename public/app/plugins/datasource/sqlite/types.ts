@@ -0,0 +1,11 @@
+import { SQLOptions } from 'app/features/plugins/sql/types';
+
+export enum SQLiteDialect {
+  SQLite = 'sqlite',
+  DuckDB = 'duckdb',
+}
+
+export interface SQLiteOptions extends SQLOptions {
+  dialect?: SQLiteDialect;
+  path?: string;
+}
@@ -9,6 +9,7 @@ import {
   HorizontalGroup,
   InlineField,
   InlineFieldRow,
+  Input,
   Modal,
   RadioButtonGroup,
   Themeable2,
@@ -88,6 +89,7 @@ class TempoQueryFieldComponent extends React.PureComponent<Props, State> {
     let queryTypeOptions: Array<SelectableValue<TempoQueryType>> = [
       { value: 'traceqlSearch', label: 'Search' },
       { value: 'traceql', label: 'TraceQL' },
+      { value: 'traceqlMetrics', label: 'TraceQL metrics' },
       { value: 'serviceMap', label: 'Service Graph' },
     ];
 
@@ -207,6 +209,32 @@ class TempoQueryFieldComponent extends React.PureComponent<Props, State> {
             onChange={onChange}
           />
         )}
+        {query.queryType === 'traceqlMetrics' && (
+          <>
+            <QueryEditor
+              datasource={this.props.datasource}
+              query={query}
+              onRunQuery={this.props.onRunQuery}
+              onChange={onChange}
+            />
+            <InlineFieldRow>
+              <InlineField
+                label="Step"
+                labelWidth={14}
+                tooltip="Step of the returned series, defaults to the query interval. Use duration format, for example: 30s, 1m"
+              >
+                <Input
+                  id="tempo-metrics-step"
+                  placeholder="auto"
+                  width={16}
+                  value={query.step || ''}
+                  onChange={(e) => onChange({ ...query, step: e.currentTarget.value })}
+                  onBlur={this.props.onRunQuery}
+                />
+              </InlineField>
+            </InlineFieldRow>
+          </>
+        )}
       </>
     );
   }
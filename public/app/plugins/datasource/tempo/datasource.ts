@@ -447,6 +447,17 @@ export class TempoDatasource extends DataSourceWithBackend<TempoQuery, TempoJson
       }
     }
 
+    if (targets.traceqlMetrics?.length) {
+      reportInteraction('grafana_traces_traceql_metrics_queried', {
+        datasourceType: 'tempo',
+        app: options.app ?? '',
+        grafana_version: config.buildInfo.version,
+      });
+
+      // TraceQL metrics queries run in the backend, so they work in alerting as well
+      subQueries.push(super.query({ ...options, targets: targets.traceqlMetrics }));
+    }
+
     if (targets.upload?.length) {
       if (this.uploadedJson) {
         reportInteraction('grafana_traces_json_file_uploaded', {